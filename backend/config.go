@@ -0,0 +1,314 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-upload/backend/internal/collection"
+	"go-upload/backend/internal/tuning"
+	wsmanager "go-upload/backend/internal/websocket"
+)
+
+// DEFAULT_CONFIG_FILE is read when --config is not given (missing file = defaults only)
+const DEFAULT_CONFIG_FILE = "config.json"
+
+// ConfigFile holds named configuration profiles (e.g. dev, staging, prod).
+// Each profile is a partial ServerConfig: only the fields present override the defaults.
+type ConfigFile struct {
+	DefaultProfile string                     `json:"defaultProfile"`
+	Profiles       map[string]json.RawMessage `json:"profiles"`
+}
+
+// loadConfig builds the server configuration in order of precedence:
+// built-in defaults < selected profile < environment variables.
+// The profile comes from the profile argument (--profile), then GO_UPLOAD_PROFILE,
+// then the file's defaultProfile.
+func loadConfig(path, profile string) (*ServerConfig, error) {
+	config := baseConfig()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		if profile != "" {
+			return nil, fmt.Errorf("profile %q requested but config file %s does not exist", profile, path)
+		}
+		applyEnvOverrides(config)
+		return config, nil
+	}
+
+	var file ConfigFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	if profile == "" {
+		profile = os.Getenv("GO_UPLOAD_PROFILE")
+	}
+	if profile == "" {
+		profile = file.DefaultProfile
+	}
+
+	if profile != "" {
+		raw, exists := file.Profiles[profile]
+		if !exists {
+			return nil, fmt.Errorf("unknown profile %q (available: %s)", profile, strings.Join(file.profileNames(), ", "))
+		}
+		if err := json.Unmarshal(raw, config); err != nil {
+			return nil, fmt.Errorf("invalid profile %q: %w", profile, err)
+		}
+		config.Profile = profile
+	}
+
+	applyEnvOverrides(config)
+	return config, nil
+}
+
+// profileNames returns the sorted profile names for error messages
+func (f *ConfigFile) profileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyEnvOverrides applies the legacy environment variables on top of the config
+func applyEnvOverrides(config *ServerConfig) {
+	if env := os.Getenv("MAX_WORKERS"); env != "" {
+		if val, err := strconv.Atoi(env); err == nil {
+			config.MaxWorkers = val
+		}
+	}
+
+	if env := os.Getenv("MAX_CONNECTIONS"); env != "" {
+		if val, err := strconv.Atoi(env); err == nil {
+			config.MaxConnections = val
+		}
+	}
+
+	if env := os.Getenv("MAX_CONCURRENT_COLLECTIONS"); env != "" {
+		if val, err := strconv.Atoi(env); err == nil {
+			config.MaxConcurrentCollections = val
+		}
+	}
+
+	if env := os.Getenv("LOW_MEMORY"); env != "" {
+		if val, err := strconv.ParseBool(env); err == nil {
+			config.LowMemory = val
+		}
+	}
+
+	if env := os.Getenv("DATA_DIR"); env != "" {
+		config.DataDir = env
+	}
+
+	if env := os.Getenv("PORT"); env != "" {
+		config.Port = env
+	}
+
+	if !strings.HasPrefix(config.Port, ":") && !strings.Contains(config.Port, ":") {
+		config.Port = ":" + config.Port
+	}
+}
+
+// resolveConcurrency fills maxWorkers and discoveryWorkers left at 0 with the
+// tuner's recommendation, or with the fixed defaults when tuning is disabled.
+// Configured values are kept; a higher value than recommended is only logged.
+// Reports whether the upload workers were auto-tuned.
+func resolveConcurrency(config *ServerConfig, tuner *tuning.Tuner, disabled bool) bool {
+	if disabled {
+		if config.MaxWorkers <= 0 {
+			config.MaxWorkers = DEFAULT_MAX_WORKERS
+		}
+		if config.DiscoveryWorkers <= 0 {
+			config.DiscoveryWorkers = DISCOVERY_WORKERS
+		}
+		return false
+	}
+
+	resources, recommendation := tuner.Last()
+	reasons := strings.Join(recommendation.Reasons, "; ")
+	autoTuned := config.MaxWorkers <= 0
+	if autoTuned {
+		config.MaxWorkers = recommendation.MaxWorkers
+	} else if config.MaxWorkers > recommendation.MaxWorkers {
+		log.Printf("💡 Suggested maxWorkers: %d (configured: %d; %s)", recommendation.MaxWorkers, config.MaxWorkers, reasons)
+	}
+	if config.DiscoveryWorkers <= 0 {
+		config.DiscoveryWorkers = recommendation.DiscoveryWorkers
+	} else if config.DiscoveryWorkers > recommendation.DiscoveryWorkers {
+		log.Printf("💡 Suggested discoveryWorkers: %d (configured: %d)", recommendation.DiscoveryWorkers, config.DiscoveryWorkers)
+	}
+	log.Printf("⚙️ Detected %d CPUs, %d MiB memory available: %s", resources.CPUs, resources.MemoryAvailable>>20, reasons)
+	return autoTuned
+}
+
+// applyLowMemory caps the worker pools for lowMemory mode and makes the garbage
+// collector return memory sooner. GOGC/GOMEMLIMIT set in the environment win.
+func applyLowMemory(config *ServerConfig) {
+	config.MaxWorkers = min(config.MaxWorkers, LOW_MEMORY_MAX_WORKERS)
+	config.DiscoveryWorkers = min(config.DiscoveryWorkers, LOW_MEMORY_DISCOVERY_WORKERS)
+	config.MaxConcurrentCollections = 1
+
+	if os.Getenv("GOGC") == "" {
+		debug.SetGCPercent(LOW_MEMORY_GC_PERCENT)
+	}
+	// Soft limit at half of the machine: the GC works harder well before the OOM killer
+	if total := tuning.Detect().MemoryTotal; total > 0 && os.Getenv("GOMEMLIMIT") == "" {
+		debug.SetMemoryLimit(int64(total / 2))
+	}
+}
+
+// collectionBatchSize is the number of files queued per collection batch
+func (c *ServerConfig) collectionBatchSize() int {
+	if c.LowMemory {
+		return LOW_MEMORY_COLLECTION_BATCH
+	}
+	return DEFAULT_COLLECTION_BATCH_SIZE
+}
+
+// StateRetention controls the periodic cleanup of finished work kept in memory.
+// Values are Go durations; "0" keeps that kind of entry until restart.
+type StateRetention struct {
+	Interval      string `json:"interval,omitempty"`      // Time between cleanups (default "10m"; "0" disables the cleanup)
+	UploadResults string `json:"uploadResults,omitempty"` // Results and manga titles of batches no longer tracked (default "6h")
+	Batches       string `json:"batches,omitempty"`       // Finished or canceled batches (default "1h")
+	Collections   string `json:"collections,omitempty"`   // Completed, failed or cancelled collections (default "72h")
+	StateFiles    string `json:"stateFiles,omitempty"`    // collection_state files of finished collections on disk (default "720h")
+	MaxStateFiles int    `json:"maxStateFiles,omitempty"` // Finished collection_state files kept, newest first (default 200; -1 = no limit)
+}
+
+// Defaults of StateRetention
+const (
+	DEFAULT_STATE_CLEANUP_INTERVAL   = 10 * time.Minute
+	DEFAULT_UPLOAD_RESULTS_RETENTION = 6 * time.Hour
+	DEFAULT_BATCH_RETENTION          = time.Hour
+	DEFAULT_COLLECTION_RETENTION     = 72 * time.Hour
+	DEFAULT_STATE_FILE_RETENTION     = 30 * 24 * time.Hour
+	DEFAULT_MAX_STATE_FILES          = 200
+)
+
+// statePolicy is StateRetention parsed, with the defaults applied
+type statePolicy struct {
+	Interval      time.Duration
+	UploadResults time.Duration
+	Batches       time.Duration
+	Collections   time.Duration
+	StateFiles    collection.StatePolicy
+}
+
+// statePolicy parses StateRetention; invalid values fall back to the defaults
+func (c *ServerConfig) statePolicy() statePolicy {
+	retention := StateRetention{}
+	if c.StateRetention != nil {
+		retention = *c.StateRetention
+	}
+	maxStateFiles := retention.MaxStateFiles
+	switch {
+	case maxStateFiles == 0:
+		maxStateFiles = DEFAULT_MAX_STATE_FILES
+	case maxStateFiles < 0:
+		maxStateFiles = 0
+	}
+	return statePolicy{
+		Interval:      parseRetention("interval", retention.Interval, DEFAULT_STATE_CLEANUP_INTERVAL),
+		UploadResults: parseRetention("uploadResults", retention.UploadResults, DEFAULT_UPLOAD_RESULTS_RETENTION),
+		Batches:       parseRetention("batches", retention.Batches, DEFAULT_BATCH_RETENTION),
+		Collections:   parseRetention("collections", retention.Collections, DEFAULT_COLLECTION_RETENTION),
+		StateFiles: collection.StatePolicy{
+			MaxAge:   parseRetention("stateFiles", retention.StateFiles, DEFAULT_STATE_FILE_RETENTION),
+			MaxCount: maxStateFiles,
+		},
+	}
+}
+
+func parseRetention(name, value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		log.Printf("⚠️ Invalid stateRetention.%s %q, using %s", name, value, fallback)
+		return fallback
+	}
+	return duration
+}
+
+// maxMessageSize is the largest WebSocket message accepted from a client
+func (c *ServerConfig) maxMessageSize() int64 {
+	if c.MaxMessageSize > 0 {
+		return c.MaxMessageSize
+	}
+	if c.LowMemory {
+		return LOW_MEMORY_MAX_MESSAGE_SIZE
+	}
+	return wsmanager.DefaultMaxMessageSize
+}
+
+// maxFileContentSize is the largest decoded base64 fileContent accepted per file
+func (c *ServerConfig) maxFileContentSize() int64 {
+	if c.MaxFileContentSize > 0 {
+		return c.MaxFileContentSize
+	}
+	if c.LowMemory {
+		return LOW_MEMORY_MAX_FILE_CONTENT
+	}
+	return DEFAULT_MAX_FILE_CONTENT_SIZE
+}
+
+// ReviewConfig enables the chapter review workflow: uploaded chapters go through
+// QC and only approved ones are synced to GitHub
+type ReviewConfig struct {
+	Enabled bool         `json:"enabled"`
+	Users   []ReviewUser `json:"users,omitempty"` // Empty = every connection acts as admin (single-user setups)
+}
+
+// ReviewUser is a team member, identified on a connection with set_reviewer
+type ReviewUser struct {
+	Name  string `json:"name"`
+	Role  string `json:"role"` // uploader, qc or admin
+	Token string `json:"token"`
+}
+
+// reviewEnabled reports whether chapters go through the review workflow
+func (c *ServerConfig) reviewEnabled() bool {
+	return c.Review != nil && c.Review.Enabled
+}
+
+// reviewUser returns the team member with the given token
+func (c *ServerConfig) reviewUser(token string) (ReviewUser, bool) {
+	if c.Review == nil || token == "" {
+		return ReviewUser{}, false
+	}
+	for _, user := range c.Review.Users {
+		if subtle.ConstantTimeCompare([]byte(user.Token), []byte(token)) == 1 {
+			return user, true
+		}
+	}
+	return ReviewUser{}, false
+}
+
+// hostEnabled reports whether an upload host is enabled by the active profile
+// (an empty list enables every registered host)
+func (c *ServerConfig) hostEnabled(host string) bool {
+	if len(c.Hosts) == 0 {
+		return true
+	}
+	for _, enabled := range c.Hosts {
+		if strings.EqualFold(enabled, host) {
+			return true
+		}
+	}
+	return false
+}
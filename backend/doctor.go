@@ -0,0 +1,446 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-upload/backend/internal/discovery"
+	"go-upload/backend/internal/github"
+	"go-upload/backend/internal/hooks"
+	"go-upload/backend/internal/policy"
+	"go-upload/backend/internal/registry"
+	"go-upload/backend/internal/release"
+	"go-upload/backend/internal/selfupdate"
+	"go-upload/backend/internal/signing"
+	"go-upload/backend/internal/upload"
+	"go-upload/backend/uploaders"
+)
+
+// DOCTOR_CHECK_TIMEOUT bounds each dry-run call made by --doctor
+const DOCTOR_CHECK_TIMEOUT = 20 * time.Second
+
+// doctorLevel ranks a finding of the configuration doctor
+type doctorLevel int
+
+const (
+	doctorOK      doctorLevel = iota
+	doctorWarning             // works, but probably not as intended
+	doctorFatal               // would fail later (mid-upload); the server refuses to start
+)
+
+// doctorFinding is the result of one check, with the fix to apply when it failed
+type doctorFinding struct {
+	Level   doctorLevel
+	Check   string
+	Message string
+	Fix     string
+}
+
+// doctorReport collects the findings of a configuration check
+type doctorReport struct {
+	Findings []doctorFinding
+}
+
+// doctorHost is an upload host configured by its own section
+type doctorHost struct {
+	Name    string
+	Section string
+	Build   func() (upload.UploaderInterface, error)
+}
+
+func (r *doctorReport) ok(check, message string) {
+	r.Findings = append(r.Findings, doctorFinding{Level: doctorOK, Check: check, Message: message})
+}
+
+func (r *doctorReport) warn(check, message, fix string) {
+	r.Findings = append(r.Findings, doctorFinding{Level: doctorWarning, Check: check, Message: message, Fix: fix})
+}
+
+func (r *doctorReport) fatal(check, message, fix string) {
+	r.Findings = append(r.Findings, doctorFinding{Level: doctorFatal, Check: check, Message: message, Fix: fix})
+}
+
+// count returns how many findings have the given level
+func (r *doctorReport) count(level doctorLevel) int {
+	count := 0
+	for _, finding := range r.Findings {
+		if finding.Level == level {
+			count++
+		}
+	}
+	return count
+}
+
+// print writes the findings at or above minLevel, each failed one followed by its fix
+func (r *doctorReport) print(w io.Writer, minLevel doctorLevel) {
+	for _, finding := range r.Findings {
+		if finding.Level < minLevel {
+			continue
+		}
+		mark := "✔"
+		switch finding.Level {
+		case doctorWarning:
+			mark = "⚠"
+		case doctorFatal:
+			mark = "✖"
+		}
+		fmt.Fprintf(w, "%s %-18s %s\n", mark, finding.Check, finding.Message)
+		if finding.Fix != "" {
+			fmt.Fprintf(w, "  %-18s fix: %s\n", "", finding.Fix)
+		}
+	}
+	fmt.Fprintf(w, "\n%d fatal problem(s), %d warning(s)\n", r.count(doctorFatal), r.count(doctorWarning))
+}
+
+// runDoctorCommand handles --doctor: checks the configuration with dry-run calls to
+// the hosts, prints every finding with its fix and fails when a problem is fatal
+func runDoctorCommand(config *ServerConfig, configPath string) error {
+	fmt.Printf("go-upload %s doctor: %s", version, configPath)
+	if config.Profile != "" {
+		fmt.Printf(" (profile %s)", config.Profile)
+	}
+	fmt.Print("\n\n")
+
+	report := runDoctor(context.Background(), config, true)
+	report.print(os.Stdout, doctorOK)
+	if fatal := report.count(doctorFatal); fatal > 0 {
+		return fmt.Errorf("%d fatal configuration problem(s)", fatal)
+	}
+	return nil
+}
+
+// runDoctor validates the configuration. Without online only local checks run
+// (used at every startup); online adds dry-run calls to the hosts and GitHub.
+func runDoctor(ctx context.Context, config *ServerConfig, online bool) *doctorReport {
+	report := &doctorReport{}
+	paths := resolveDataPaths(config)
+
+	report.checkPort(config, online)
+	report.checkPaths(config, paths)
+	report.checkIntervals(config)
+	report.checkReview(config)
+	report.checkPipeline(ctx, config, online)
+	report.checkHosts(ctx, config, paths, online)
+	return report
+}
+
+// checkPort validates the listen address; online also reports a port already in use
+func (r *doctorReport) checkPort(config *ServerConfig, online bool) {
+	_, port, err := net.SplitHostPort(config.Port)
+	if err == nil {
+		var number int
+		if number, err = strconv.Atoi(port); err == nil && (number < 0 || number > 65535) {
+			err = fmt.Errorf("port %d out of range", number)
+		}
+	}
+	if err != nil {
+		r.fatal("port", fmt.Sprintf("invalid listen address %q: %v", config.Port, err), `set port (or PORT) to a number such as 8080 or an address such as "127.0.0.1:8080"`)
+		return
+	}
+	if online {
+		listener, err := net.Listen("tcp", config.Port)
+		if err != nil {
+			r.warn("port", fmt.Sprintf("%s is not available: %v", config.Port, err), "stop the other process (or server instance) using it, or choose another port")
+			return
+		}
+		listener.Close()
+	}
+	r.ok("port", "listening on "+config.Port)
+}
+
+// checkPaths makes sure every directory the server writes to is writable (or can be
+// created) and that the inputs it reads exist
+func (r *doctorReport) checkPaths(config *ServerConfig, paths DataPaths) {
+	required := []struct{ check, dir, setting string }{
+		{"data directory", paths.DataDir, "dataDir, --data-dir or DATA_DIR"},
+		{"json output", paths.JSONOutput, "metadataOutput"},
+		{"job state", filepath.Dir(paths.CollectionState), "dataDir"},
+		{"covers", paths.Covers, "dataDir"},
+		{"spool", paths.Spool, "dataDir"},
+	}
+	if config.Backup != nil && config.Backup.Enabled {
+		required = append(required, struct{ check, dir, setting string }{"backup", paths.Backup, "backup.root"})
+	}
+	for _, path := range required {
+		if err := writableDir(path.dir); err != nil {
+			r.fatal(path.check, fmt.Sprintf("%s is not writable: %v", path.dir, err), fmt.Sprintf("fix the permissions of %s or point %s to a writable directory", path.dir, path.setting))
+		} else {
+			r.ok(path.check, path.dir+" is writable")
+		}
+	}
+
+	// On-demand outputs only fail the feature that writes them
+	for _, path := range []struct{ check, dir string }{{"site output", paths.Site}, {"exports", paths.Exports}} {
+		if err := writableDir(path.dir); err != nil {
+			r.warn(path.check, fmt.Sprintf("%s is not writable: %v", path.dir, err), "fix the permissions of "+path.dir)
+		}
+	}
+
+	switch info, err := os.Stat(paths.LibraryRoot); {
+	case err != nil && config.LibraryRoot == LIBRARY_ROOT:
+		r.warn("library", fmt.Sprintf("default library %s does not exist", paths.LibraryRoot), "create it or set libraryRoot to the folder holding your scans")
+	case err != nil:
+		r.fatal("library", fmt.Sprintf("libraryRoot %s: %v", paths.LibraryRoot, err), "set libraryRoot to an existing folder holding your scans (mount it first in containers)")
+	case !info.IsDir():
+		r.fatal("library", paths.LibraryRoot+" is not a directory", "set libraryRoot to the folder holding your scans")
+	default:
+		if _, err := os.ReadDir(paths.LibraryRoot); err != nil {
+			r.fatal("library", fmt.Sprintf("%s cannot be read: %v", paths.LibraryRoot, err), "give the server user read access to "+paths.LibraryRoot)
+		} else {
+			r.ok("library", paths.LibraryRoot+" is readable")
+		}
+	}
+
+	if config.OfflineDBPath != "" {
+		if file, err := os.Open(config.OfflineDBPath); err != nil {
+			r.fatal("offline db", fmt.Sprintf("cannot read %s: %v", config.OfflineDBPath, err), "check the --offline-db path or remove the flag")
+		} else {
+			file.Close()
+			r.ok("offline db", config.OfflineDBPath+" is readable")
+		}
+	}
+	if config.PluginDir != "" && !dirExists(config.PluginDir) {
+		r.warn("plugins", fmt.Sprintf("pluginDir %s does not exist", config.PluginDir), "create it or remove pluginDir to use <dataDir>/plugins")
+	}
+	if _, err := discovery.ParseSymlinkMode(config.Symlinks); err != nil {
+		r.warn("library", err.Error()+"; symlinks are followed", `set symlinks to "follow" or "ignore"`)
+	}
+}
+
+// writableDir reports whether files can be created in dir, or in the closest
+// existing parent when dir does not exist yet (the server creates it)
+func writableDir(dir string) error {
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", existing)
+			}
+			break
+		}
+		parent := filepath.Dir(existing)
+		if !os.IsNotExist(err) || parent == existing {
+			return err
+		}
+		existing = parent
+	}
+
+	probe, err := os.CreateTemp(existing, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// checkIntervals reports durations that the server would ignore
+func (r *doctorReport) checkIntervals(config *ServerConfig) {
+	intervals := []struct{ setting, value string }{
+		{"statusRefreshInterval", config.StatusRefreshInterval},
+		{"mirrorHealthInterval", config.MirrorHealthInterval},
+		{"signedUrlRefreshInterval", config.SignedURLRefreshInterval},
+	}
+	if retention := config.StateRetention; retention != nil {
+		intervals = append(intervals, []struct{ setting, value string }{
+			{"stateRetention.interval", retention.Interval},
+			{"stateRetention.uploadResults", retention.UploadResults},
+			{"stateRetention.batches", retention.Batches},
+			{"stateRetention.collections", retention.Collections},
+			{"stateRetention.stateFiles", retention.StateFiles},
+		}...)
+	}
+	for _, interval := range intervals {
+		if interval.value == "" {
+			continue
+		}
+		if duration, err := time.ParseDuration(interval.value); err != nil || duration < 0 {
+			r.warn("intervals", fmt.Sprintf("%s %q is not a valid duration and is ignored", interval.setting, interval.value), `use a Go duration such as "30m", "6h" or "24h"`)
+		}
+	}
+}
+
+// checkReview validates the team of the review workflow
+func (r *doctorReport) checkReview(config *ServerConfig) {
+	if !config.reviewEnabled() {
+		return
+	}
+	tokens := make(map[string]string)
+	problems := 0
+	for i, user := range config.Review.Users {
+		label := user.Name
+		if label == "" {
+			label = fmt.Sprintf("review.users[%d]", i)
+			r.fatal("review", label+" has no name", "give every review user a name (shown in the chapter history)")
+			problems++
+		}
+		if _, err := registry.ParseRole(user.Role); err != nil {
+			r.fatal("review", fmt.Sprintf("%s: %v", label, err), "set role to uploader, qc or admin")
+			problems++
+		}
+		switch {
+		case user.Token == "":
+			r.fatal("review", label+" has no token", "generate a random token (e.g. openssl rand -hex 16) for set_reviewer")
+			problems++
+		case tokens[user.Token] != "":
+			r.fatal("review", fmt.Sprintf("%s and %s share a token", tokens[user.Token], label), "give every review user its own token")
+			problems++
+		case len(user.Token) < 16:
+			r.warn("review", label+" has a short token", "use at least 16 random characters")
+		}
+		tokens[user.Token] = label
+	}
+	if problems == 0 {
+		if len(config.Review.Users) == 0 {
+			r.ok("review", "enabled without users: every connection acts as admin")
+		} else {
+			r.ok("review", fmt.Sprintf("%d review user(s)", len(config.Review.Users)))
+		}
+	}
+}
+
+// checkPipeline validates the sections the server would otherwise disable or replace
+// with defaults at startup; online also checks the update token
+func (r *doctorReport) checkPipeline(ctx context.Context, config *ServerConfig, online bool) {
+	if _, err := hooks.New(config.Hooks); err != nil {
+		r.fatal("hooks", err.Error(), "fix or remove the hook; while one is invalid no hook runs")
+	} else if len(config.Hooks) > 0 {
+		r.ok("hooks", fmt.Sprintf("%d hook(s)", len(config.Hooks)))
+	}
+	if config.Policy != nil {
+		if _, err := policy.New(*config.Policy); err != nil {
+			r.fatal("policy", err.Error(), "fix the policy section; an invalid policy would fall back to the defaults")
+		} else {
+			r.ok("policy", "content policy is valid")
+		}
+	}
+	if config.Release != nil {
+		if _, err := release.NewBuilder(*config.Release); err != nil {
+			r.warn("release", err.Error(), "fix the custom templates; the built-in ones are used meanwhile")
+		}
+	}
+	if signer, err := signing.New(config.Signing); err != nil {
+		r.fatal("signing", err.Error(), "fix the signing section (the key may also come from JSON_SIGNING_KEY); JSONs would be synced unsigned")
+	} else if signer != nil {
+		r.ok("signing", fmt.Sprintf("%s signatures, key %s; public key %s", signer.Mode(), signer.KeyID(), signer.PublicKey()))
+	}
+	if config.Update == nil {
+		return
+	}
+	if _, err := selfupdate.New(*config.Update, version); err != nil {
+		r.warn("update", err.Error(), "fix the update section; check_update and apply_update are disabled meanwhile")
+		return
+	}
+	if online && config.Update.Token != "" {
+		if err := github.NewGitHubService().ValidateToken(config.Update.Token); err != nil {
+			r.warn("update", "update.token: "+err.Error(), "create a new GitHub token with read access to "+config.Update.Repo)
+		} else {
+			r.ok("update", "update.token accepted by GitHub")
+		}
+	}
+}
+
+// configuredHosts lists the hosts configured by their own section
+func configuredHosts(config *ServerConfig) []doctorHost {
+	var hosts []doctorHost
+	if config.Tus != nil {
+		hosts = append(hosts, doctorHost{"tus", "tus", func() (upload.UploaderInterface, error) { return uploaders.NewTusUploader(*config.Tus) }})
+	}
+	if config.S3 != nil {
+		hosts = append(hosts, doctorHost{"s3", "s3", func() (upload.UploaderInterface, error) { return uploaders.NewS3Uploader(*config.S3) }})
+	}
+	if config.R2 != nil {
+		hosts = append(hosts, doctorHost{"r2", "r2", func() (upload.UploaderInterface, error) { return uploaders.NewR2Uploader(*config.R2) }})
+	}
+	if config.CloudflareImages != nil {
+		hosts = append(hosts, doctorHost{"cfimages", "cloudflareImages", func() (upload.UploaderInterface, error) {
+			return uploaders.NewCloudflareImagesUploader(*config.CloudflareImages)
+		}})
+	}
+	if config.WebDAV != nil {
+		hosts = append(hosts, doctorHost{"webdav", "webdav", func() (upload.UploaderInterface, error) { return uploaders.NewWebDAVUploader(*config.WebDAV) }})
+	}
+	if config.Imgur != nil {
+		hosts = append(hosts, doctorHost{"imgur", "imgur", func() (upload.UploaderInterface, error) { return uploaders.NewImgurUploader(*config.Imgur) }})
+	}
+	if config.ImgBB != nil {
+		hosts = append(hosts, doctorHost{"imgbb", "imgbb", func() (upload.UploaderInterface, error) { return uploaders.NewImgBBUploader(*config.ImgBB) }})
+	}
+	if config.Pixeldrain != nil {
+		hosts = append(hosts, doctorHost{"pixeldrain", "pixeldrain", func() (upload.UploaderInterface, error) {
+			return uploaders.NewPixeldrainUploader(*config.Pixeldrain)
+		}})
+	}
+	if config.ImgChest != nil {
+		hosts = append(hosts, doctorHost{"imgchest", "imgchest", func() (upload.UploaderInterface, error) { return uploaders.NewImgChestUploader(*config.ImgChest) }})
+	}
+	if config.Gofile != nil {
+		hosts = append(hosts, doctorHost{"gofile", "gofile", func() (upload.UploaderInterface, error) { return uploaders.NewGofileUploader(*config.Gofile) }})
+	}
+	if config.Telegraph != nil {
+		hosts = append(hosts, doctorHost{"telegraph", "telegraph", func() (upload.UploaderInterface, error) { return uploaders.NewTelegraphUploader(*config.Telegraph) }})
+	}
+	if config.Remote != nil {
+		hosts = append(hosts, doctorHost{config.Remote.Protocol, "remote", func() (upload.UploaderInterface, error) {
+			return uploaders.NewRemoteUploader(*config.Remote)
+		}})
+	}
+	return hosts
+}
+
+// checkHosts builds every configured host as the server would; online also makes
+// a dry-run call with its credentials
+func (r *doctorReport) checkHosts(ctx context.Context, config *ServerConfig, paths DataPaths, online bool) {
+	configured := make(map[string]bool)
+	for _, host := range configuredHosts(config) {
+		configured[host.Name] = true
+		check := "host " + host.Name
+		if !config.hostEnabled(host.Name) {
+			r.warn(check, fmt.Sprintf("%s is configured but not listed in hosts", host.Section), fmt.Sprintf("add %q to hosts or remove the %s section", host.Name, host.Section))
+			continue
+		}
+		uploader, err := host.Build()
+		if err != nil {
+			r.fatal(check, err.Error(), fmt.Sprintf("fix the %s section (credentials may also come from the environment)", host.Section))
+			continue
+		}
+		checker, canCheck := uploader.(upload.CredentialChecker)
+		if !online || !canCheck {
+			r.ok(check, "configuration is valid")
+			continue
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, DOCTOR_CHECK_TIMEOUT)
+		err = checker.CheckCredentials(checkCtx)
+		cancel()
+		if closer, ok := uploader.(io.Closer); ok {
+			closer.Close()
+		}
+		if err != nil {
+			r.fatal(check, err.Error(), fmt.Sprintf("check the address and credentials in the %s section and that this machine can reach the host", host.Section))
+		} else {
+			r.ok(check, "credentials accepted (dry run)")
+		}
+	}
+
+	builtin := map[string]bool{"catbox": true, "tus": true, "s3": true, "r2": true, "cfimages": true, "webdav": true, "imgur": true, "imgbb": true, "pixeldrain": true, "imgchest": true, "gofile": true, "telegraph": true,
+		uploaders.RemoteSFTP: true, uploaders.RemoteFTP: true, uploaders.RemoteFTPS: true}
+	for _, name := range config.Hosts {
+		name = strings.ToLower(name)
+		switch {
+		case name == "catbox":
+			r.ok("host catbox", "anonymous uploads, no credentials needed")
+		case configured[name]:
+		case builtin[name]:
+			r.fatal("host "+name, name+" is listed in hosts but not configured", fmt.Sprintf("add its section to the configuration or remove %q from hosts", name))
+		default:
+			r.warn("host "+name, name+" is not a built-in host", fmt.Sprintf("it must be provided by a plugin in %s; check the spelling otherwise", paths.Plugins))
+		}
+	}
+}
@@ -8,6 +8,7 @@ require (
 	github.com/gorilla/websocket v1.5.3
 	github.com/shurcooL/graphql v0.0.0-20230722043721-ed46e5a46466
 	github.com/wabarc/go-catbox v0.1.0
+	golang.org/x/crypto v0.40.0
 	golang.org/x/text v0.28.0
 )
 
@@ -15,5 +16,6 @@ require (
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/wabarc/helper v0.0.0-20210718171053-59c70d0b20c2 // indirect
 	golang.org/x/net v0.42.0 // indirect
+	golang.org/x/sys v0.34.0 // indirect
 	mvdan.cc/xurls/v2 v2.2.0 // indirect
 )
@@ -0,0 +1,248 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"go-upload/backend/internal/metadata"
+)
+
+// monthLayout é a chave dos capítulos por mês ("2024-01")
+const monthLayout = "2006-01"
+
+// HostUsage resume os arquivos hospedados em um host
+type HostUsage struct {
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"`
+}
+
+// Stats são as estatísticas de lançamento de uma obra ou de um grupo
+type Stats struct {
+	Chapters            int                   `json:"chapters"`
+	Pages               int                   `json:"pages"`
+	AvgPagesPerChapter  float64               `json:"avgPagesPerChapter"`
+	ChaptersPerMonth    map[string]int        `json:"chaptersPerMonth"` // "2006-01" -> capítulos lançados
+	AvgChaptersPerMonth float64               `json:"avgChaptersPerMonth"`
+	FirstRelease        *time.Time            `json:"firstRelease,omitempty"`
+	LastRelease         *time.Time            `json:"lastRelease,omitempty"`
+	Hosts               map[string]*HostUsage `json:"hosts"` // bytes por host, do histórico de uploads
+}
+
+// SeriesStats são as estatísticas de uma obra
+type SeriesStats struct {
+	MangaID string   `json:"mangaId"`
+	Title   string   `json:"title"`
+	Groups  []string `json:"groups"`
+	Stats
+}
+
+// GroupStats são as estatísticas de um grupo de scan
+type GroupStats struct {
+	Group  string   `json:"group"`
+	Series []string `json:"series"`
+	Stats
+}
+
+// Report agrega as estatísticas por obra e por grupo
+type Report struct {
+	GeneratedAt time.Time             `json:"generatedAt"`
+	Series      []*SeriesStats        `json:"series"`
+	Groups      []*GroupStats         `json:"groups"`
+	Hosts       map[string]*HostUsage `json:"hosts"`
+	Skipped     map[string]string     `json:"skipped,omitempty"` // JSONs que não puderam ser lidos
+}
+
+// Compute gera o relatório a partir dos JSONs da biblioteca (capítulos, datas e
+// páginas) e do histórico de uploads (bytes por host). extraDirs acrescenta
+// outros diretórios de JSONs (ex.: obras arquivadas).
+func Compute(jsonDir string, records []Record, extraDirs ...string) (*Report, error) {
+	var files []string
+	for _, dir := range append([]string{jsonDir}, extraDirs...) {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list JSON files: %v", err)
+		}
+		files = append(files, matches...)
+	}
+
+	series := make(map[string]*SeriesStats)
+	groups := make(map[string]*GroupStats)
+	report := &Report{
+		GeneratedAt: time.Now(),
+		Hosts:       make(map[string]*HostUsage),
+		Skipped:     make(map[string]string),
+	}
+
+	for _, file := range files {
+		mangaID := metadata.LockKey(file)
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			report.Skipped[mangaID] = err.Error()
+			continue
+		}
+		var manga metadata.MangaJSON
+		if err := json.Unmarshal(raw, &manga); err != nil {
+			report.Skipped[mangaID] = err.Error()
+			continue
+		}
+
+		entry := seriesEntry(series, mangaID)
+		entry.Title = manga.Title
+		for _, chapter := range manga.Chapters {
+			released, _ := time.Parse(time.RFC3339, chapter.LastUpdated)
+
+			// A obra conta o capítulo uma vez, com as páginas da maior versão
+			pages := 0
+			for group, urls := range chapter.Groups {
+				if len(urls) > pages {
+					pages = len(urls)
+				}
+				groupStats := groupEntry(groups, group)
+				groupStats.addChapter(released, len(urls))
+				groupStats.Series = appendUnique(groupStats.Series, mangaID)
+				entry.Groups = appendUnique(entry.Groups, group)
+			}
+			entry.addChapter(released, pages)
+		}
+	}
+
+	for _, record := range records {
+		addHostUsage(report.Hosts, record)
+		addHostUsage(seriesEntry(series, record.MangaID).Hosts, record)
+		if record.Group != "" {
+			addHostUsage(groupEntry(groups, record.Group).Hosts, record)
+		}
+	}
+
+	for _, entry := range series {
+		entry.finalize()
+		sort.Strings(entry.Groups)
+		report.Series = append(report.Series, entry)
+	}
+	for _, entry := range groups {
+		entry.finalize()
+		sort.Strings(entry.Series)
+		report.Groups = append(report.Groups, entry)
+	}
+	sort.Slice(report.Series, func(i, j int) bool {
+		return report.Series[i].MangaID < report.Series[j].MangaID
+	})
+	sort.Slice(report.Groups, func(i, j int) bool {
+		return report.Groups[i].Group < report.Groups[j].Group
+	})
+
+	return report, nil
+}
+
+// Filter restringe o relatório a uma obra e/ou a um grupo (vazio = todos)
+func (r *Report) Filter(mangaID, group string) *Report {
+	filtered := *r
+	if mangaID != "" {
+		filtered.Series = nil
+		for _, entry := range r.Series {
+			if entry.MangaID == mangaID {
+				filtered.Series = append(filtered.Series, entry)
+			}
+		}
+	}
+	if group != "" {
+		filtered.Groups = nil
+		for _, entry := range r.Groups {
+			if entry.Group == group {
+				filtered.Groups = append(filtered.Groups, entry)
+			}
+		}
+	}
+	return &filtered
+}
+
+// seriesEntry retorna (criando se necessário) as estatísticas de uma obra
+func seriesEntry(series map[string]*SeriesStats, mangaID string) *SeriesStats {
+	entry, exists := series[mangaID]
+	if !exists {
+		entry = &SeriesStats{MangaID: mangaID, Groups: []string{}, Stats: newStats()}
+		series[mangaID] = entry
+	}
+	return entry
+}
+
+// groupEntry retorna (criando se necessário) as estatísticas de um grupo
+func groupEntry(groups map[string]*GroupStats, group string) *GroupStats {
+	entry, exists := groups[group]
+	if !exists {
+		entry = &GroupStats{Group: group, Series: []string{}, Stats: newStats()}
+		groups[group] = entry
+	}
+	return entry
+}
+
+func newStats() Stats {
+	return Stats{
+		ChaptersPerMonth: make(map[string]int),
+		Hosts:            make(map[string]*HostUsage),
+	}
+}
+
+// addChapter contabiliza um capítulo lançado (data zero = sem data no JSON)
+func (s *Stats) addChapter(released time.Time, pages int) {
+	s.Chapters++
+	s.Pages += pages
+	if released.IsZero() {
+		return
+	}
+
+	s.ChaptersPerMonth[released.Format(monthLayout)]++
+	if s.FirstRelease == nil || released.Before(*s.FirstRelease) {
+		first := released
+		s.FirstRelease = &first
+	}
+	if s.LastRelease == nil || released.After(*s.LastRelease) {
+		last := released
+		s.LastRelease = &last
+	}
+}
+
+// finalize calcula as médias; a cadência considera todos os meses entre o
+// primeiro e o último lançamento, inclusive os meses sem capítulos
+func (s *Stats) finalize() {
+	if s.Chapters > 0 {
+		s.AvgPagesPerChapter = float64(s.Pages) / float64(s.Chapters)
+	}
+	if s.FirstRelease == nil {
+		return
+	}
+
+	months := (s.LastRelease.Year()-s.FirstRelease.Year())*12 + int(s.LastRelease.Month()-s.FirstRelease.Month()) + 1
+	dated := 0
+	for _, count := range s.ChaptersPerMonth {
+		dated += count
+	}
+	s.AvgChaptersPerMonth = float64(dated) / float64(months)
+}
+
+func addHostUsage(hosts map[string]*HostUsage, record Record) {
+	host := record.Host
+	if host == "" {
+		host = "unknown"
+	}
+	usage, exists := hosts[host]
+	if !exists {
+		usage = &HostUsage{}
+		hosts[host] = usage
+	}
+	usage.Files++
+	usage.Bytes += record.Bytes
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,202 @@
+package analytics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Record é um upload bem-sucedido registrado no histórico
+type Record struct {
+	Time    time.Time `json:"time"`
+	MangaID string    `json:"mangaId"` // nome do JSON da obra sem extensão
+	Chapter string    `json:"chapter,omitempty"`
+	Group   string    `json:"group,omitempty"`
+	Host    string    `json:"host"`
+	Bytes   int64     `json:"bytes"`
+	URL     string    `json:"url,omitempty"`
+	File    string    `json:"file,omitempty"`   // nome do arquivo enviado (cópia no backup)
+	SHA256  string    `json:"sha256,omitempty"` // hash do conteúdo enviado, gravado com o backup ligado
+
+	// Recibo do host (upload.Receipt): identificadores para apagar ou migrar o
+	// arquivo depois e para chamados de suporte ao host
+	HostFileID  string `json:"hostFileId,omitempty"`
+	DeleteToken string `json:"deleteToken,omitempty"`
+	DeleteURL   string `json:"deleteUrl,omitempty"`
+	VersionID   string `json:"versionId,omitempty"`
+
+	// Publicação em duas fases: o upload terminou mas o capítulo ainda não foi
+	// escrito no JSON. Page e Title permitem montar o JSON na publicação.
+	Unpublished bool   `json:"unpublished,omitempty"`
+	Page        int    `json:"page,omitempty"`
+	Title       string `json:"title,omitempty"`
+}
+
+// History é o histórico de uploads, gravado em JSON Lines (um registro por linha)
+type History struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewHistory cria o histórico de uploads no arquivo informado
+func NewHistory(path string) *History {
+	return &History{path: path}
+}
+
+// Append acrescenta registros ao final do histórico
+func (h *History) Append(records ...Record) error {
+	if h == nil || len(records) == 0 {
+		return nil
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %v", err)
+	}
+	file, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open upload history: %v", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, record := range records {
+		if record.Time.IsZero() {
+			record.Time = time.Now()
+		}
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to write upload history: %v", err)
+		}
+	}
+	return nil
+}
+
+// Load lê todos os registros do histórico. Linhas inválidas (ex.: gravação
+// interrompida) são ignoradas.
+func (h *History) Load() ([]Record, error) {
+	if h == nil {
+		return nil, nil
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.load()
+}
+
+// load lê o arquivo do histórico (chamador deve ter o lock)
+func (h *History) load() ([]Record, error) {
+	file, err := os.Open(h.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open upload history: %v", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record Record
+		if json.Unmarshal(scanner.Bytes(), &record) == nil {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return records, fmt.Errorf("failed to read upload history: %v", err)
+	}
+	return records, nil
+}
+
+// Remove reescreve o histórico sem os registros para os quais drop retorna
+// true e informa quantos foram removidos
+func (h *History) Remove(drop func(Record) bool) (int, error) {
+	if h == nil {
+		return 0, nil
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	records, err := h.load()
+	if err != nil {
+		return 0, err
+	}
+
+	var kept []Record
+	for _, record := range records {
+		if !drop(record) {
+			kept = append(kept, record)
+		}
+	}
+	removed := len(records) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := h.rewrite(kept); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// Update aplica update a cada registro e reescreve o histórico se algum
+// mudou (update retorna true); informa quantos registros mudaram
+func (h *History) Update(update func(*Record) bool) (int, error) {
+	if h == nil {
+		return 0, nil
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	records, err := h.load()
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for i := range records {
+		if update(&records[i]) {
+			updated++
+		}
+	}
+	if updated == 0 {
+		return 0, nil
+	}
+	if err := h.rewrite(records); err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+// rewrite substitui o arquivo do histórico por records (chamador deve ter o lock)
+func (h *History) rewrite(records []Record) error {
+	tmpPath := h.path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to rewrite upload history: %v", err)
+	}
+	encoder := json.NewEncoder(file)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			file.Close()
+			os.Remove(tmpPath)
+			return fmt.Errorf("failed to rewrite upload history: %v", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to rewrite upload history: %v", err)
+	}
+	if err := os.Rename(tmpPath, h.path); err != nil {
+		return fmt.Errorf("failed to rewrite upload history: %v", err)
+	}
+	return nil
+}
@@ -31,6 +31,8 @@ type AniListService struct {
 	retryHandler   *RetryHandler
 	errorHandler   *ErrorHandler
 	configManager  *ConfigManager
+	coalescer      *SearchCoalescer
+	offlineDB      *OfflineDatabase
 }
 
 // Logger interface para logs estruturados
@@ -143,6 +145,7 @@ type AniListCache struct {
 	detailsCache  map[string]*CacheEntry
 	mutex         sync.RWMutex
 	ttl           time.Duration
+	negativeTTL   time.Duration // TTL menor para buscas sem resultados
 	persistPath   string
 	logger        Logger
 	cleanupTicker *time.Ticker
@@ -155,6 +158,7 @@ func NewAniListCache(ttl time.Duration, persistPath string, logger Logger) *AniL
 		searchCache:  make(map[string]*CacheEntry),
 		detailsCache: make(map[string]*CacheEntry),
 		ttl:          ttl,
+		negativeTTL:  defaultNegativeTTL(ttl),
 		persistPath:  persistPath,
 		logger:       logger,
 		stopCleanup:  make(chan bool),
@@ -176,6 +180,26 @@ func NewAniListCache(ttl time.Duration, persistPath string, logger Logger) *AniL
 	return cache
 }
 
+// defaultNegativeTTL retorna o TTL padrão para resultados vazios (5 minutos, limitado ao TTL principal)
+func defaultNegativeTTL(ttl time.Duration) time.Duration {
+	negativeTTL := 5 * time.Minute
+	if ttl > 0 && ttl < negativeTTL {
+		return ttl
+	}
+	return negativeTTL
+}
+
+// SetNegativeTTL define por quanto tempo buscas sem resultados ficam em cache
+func (c *AniListCache) SetNegativeTTL(ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if ttl > c.ttl {
+		ttl = c.ttl
+	}
+	c.negativeTTL = ttl
+}
+
 // generateCacheKey gera uma chave única para o cache baseada nos parâmetros
 func (c *AniListCache) generateCacheKey(prefix string, params ...interface{}) string {
 	key := prefix
@@ -217,16 +241,26 @@ func (c *AniListCache) SetSearchResult(query string, page, perPage int, result *
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 	
+	// Buscas sem resultados (cache negativo) expiram mais cedo, já que o
+	// mangá pode ser cadastrado na AniList a qualquer momento
+	ttl := c.ttl
+	if result == nil || len(result.Results) == 0 {
+		if c.negativeTTL <= 0 {
+			return
+		}
+		ttl = c.negativeTTL
+	}
+	
 	key := c.generateCacheKey("search", query, page, perPage)
 	entry := &CacheEntry{
 		Data:      result,
-		ExpiresAt: time.Now().Add(c.ttl),
+		ExpiresAt: time.Now().Add(ttl),
 		CreatedAt: time.Now(),
 		Key:       key,
 	}
 	
 	c.searchCache[key] = entry
-	c.logger.Debug("Cache set for search", "key", key, "ttl", c.ttl.String())
+	c.logger.Debug("Cache set for search", "key", key, "ttl", ttl.String())
 	
 	// Salvar no disco se configurado
 	if c.persistPath != "" {
@@ -332,6 +366,7 @@ func (c *AniListCache) GetStats() map[string]interface{} {
 		"search_entries":  len(c.searchCache),
 		"details_entries": len(c.detailsCache),
 		"ttl":            c.ttl.String(),
+		"negative_ttl":   c.negativeTTL.String(),
 		"persist_path":   c.persistPath,
 	}
 }
@@ -464,6 +499,8 @@ type SearchResult struct {
 	HasNextPage bool         `json:"has_next_page"`
 	Query       string       `json:"query"`
 	TimeMS      int64        `json:"time_ms"`
+	Source      string       `json:"source,omitempty"` // "offline" quando vindo do banco local
+	LocalCovers map[int]string `json:"local_covers,omitempty"` // ID -> /covers/{hash} para capas já em cache
 }
 
 // NewAniListService cria uma nova instância do serviço AniList
@@ -535,6 +572,9 @@ func NewAniListServiceOptimized(logger Logger, cacheTTL time.Duration, cachePers
 	}
 	configManager := NewConfigManager(dataDir)
 	
+	// Banco de metadados offline (dump + base local) no mesmo diretório
+	offlineDB := NewOfflineDatabase(dataDir, logger)
+	
 	logger.Info("AniList service initialized with optimizations", 
 		"rate_limit", "90 req/min",
 		"cache_ttl", cacheTTL.String(),
@@ -544,7 +584,8 @@ func NewAniListServiceOptimized(logger Logger, cacheTTL time.Duration, cachePers
 		"connection_pooling", true,
 		"retry_enabled", true,
 		"error_handling", true,
-		"config_enabled", true)
+		"config_enabled", true,
+		"offline_entries", offlineDB.Size())
 	
 	service := &AniListService{
 		client:         client,
@@ -557,6 +598,8 @@ func NewAniListServiceOptimized(logger Logger, cacheTTL time.Duration, cachePers
 		retryHandler:   retryHandler,
 		errorHandler:   errorHandler,
 		configManager:  configManager,
+		coalescer:      NewSearchCoalescer(),
+		offlineDB:      offlineDB,
 	}
 	
 	// Atualizar tamanho do cache nas métricas
@@ -636,6 +679,7 @@ type MangaDetailed struct {
 	Staff       Staff         `graphql:"staff"`
 	ExternalLinks []ExternalLink `graphql:"externalLinks"`
 	Tags        []Tag         `graphql:"tags"`
+	IsAdult     bool          `graphql:"isAdult"`
 }
 
 type Title struct {
@@ -810,13 +854,66 @@ func (s *AniListService) SearchMangaSimple(ctx context.Context, search string) (
 	return s.SearchMangaWithRetry(ctx, search, 1, 10)
 }
 
-// SearchMangaWithRetry busca mangás com retry automático e tratamento de erros robusto
+// SearchMangaWithRetry busca mangás com retry automático e tratamento de erros robusto.
+// Buscas idênticas simultâneas são agrupadas em uma única chamada à API.
 func (s *AniListService) SearchMangaWithRetry(ctx context.Context, search string, page, perPage int) (*SearchResult, error) {
 	// Verificar se a integração está habilitada
 	if !s.IsIntegrationEnabled() {
 		return nil, fmt.Errorf("integração AniList está desabilitada")
 	}
 
+	// Modo offline: usar apenas o banco local, sem acessar a rede
+	if s.IsOfflineMode() {
+		return s.withLocalCovers(s.offlineDB.Search(search, page, perPage)), nil
+	}
+
+	result, err, shared := s.coalescer.Do(ctx, searchKey(search, page, perPage), func(ctx context.Context) (*SearchResult, error) {
+		return s.searchMangaWithRetry(ctx, search, page, perPage)
+	})
+	if shared {
+		s.metrics.RecordCoalescedRequest()
+		s.logger.Debug("Search coalesced with in-flight request", "query", search)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withLocalCovers(result), nil
+}
+
+// withLocalCovers retorna uma cópia do resultado com as URLs locais das capas já em cache,
+// evitando hotlink repetido à CDN da AniList. O resultado original (compartilhado pelo
+// cache e pelo coalescer) não é modificado.
+func (s *AniListService) withLocalCovers(result *SearchResult) *SearchResult {
+	if s.imageLoader == nil || result == nil {
+		return result
+	}
+	
+	localCovers := make(map[int]string)
+	for _, manga := range result.Results {
+		if localURL := s.imageLoader.LocalURL(extractImageURL(manga.CoverImage)); localURL != "" {
+			localCovers[manga.ID] = localURL
+		}
+	}
+	if len(localCovers) == 0 {
+		return result
+	}
+	
+	annotated := *result
+	annotated.LocalCovers = localCovers
+	return &annotated
+}
+
+// CoverFilePath resolve um nome de /covers/{hash} para o arquivo da capa em cache
+func (s *AniListService) CoverFilePath(name string) (string, bool) {
+	if s.imageLoader == nil {
+		return "", false
+	}
+	return s.imageLoader.CoverPath(name)
+}
+
+// searchMangaWithRetry executa a busca com retry e tradução de erros
+func (s *AniListService) searchMangaWithRetry(ctx context.Context, search string, page, perPage int) (*SearchResult, error) {
 	// Contexto com informações para error handling
 	errorContext := map[string]interface{}{
 		"operation": "search_manga",
@@ -840,6 +937,13 @@ func (s *AniListService) SearchMangaWithRetry(ctx context.Context, search string
 	if retryErr != nil {
 		// Verificar se é erro de circuit breaker
 		if strings.Contains(retryErr.Error(), "circuit breaker is open") {
+			// Usar o banco offline como fallback quando disponível
+			if s.offlineDB.Size() > 0 {
+				s.logger.Info("Circuit breaker open, using offline metadata database",
+					"query", search)
+				return s.offlineDB.Search(search, page, perPage), nil
+			}
+			
 			// Retornar uma resposta vazia em vez de erro para permitir fallback
 			s.logger.Info("Circuit breaker open, returning empty results for fallback", 
 				"query", search)
@@ -884,6 +988,14 @@ func (s *AniListService) GetMangaDetailsWithRetry(ctx context.Context, id int) (
 		"manga_id":   id,
 	}
 
+	// Modo offline: usar apenas o banco local, sem acessar a rede
+	if s.IsOfflineMode() {
+		if entry, found := s.offlineDB.Get(id); found {
+			return &MangaDetailsQuery{Media: entry.ToDetailed()}, nil
+		}
+		return nil, s.errorHandler.CreateLocalizedError("OFFLINE_NOT_FOUND", "offline_not_found", SeverityWarning)
+	}
+
 	var result *MangaDetailsQuery
 	var detailsErr error
 
@@ -899,19 +1011,21 @@ func (s *AniListService) GetMangaDetailsWithRetry(ctx context.Context, id int) (
 	if retryErr != nil {
 		// Verificar se é erro de circuit breaker
 		if strings.Contains(retryErr.Error(), "circuit breaker is open") {
-			// Para detalhes, não podemos fazer fallback, então retornar erro informativo
+			// Tentar o banco offline antes de desistir
+			if entry, found := s.offlineDB.Get(id); found {
+				s.logger.Info("Circuit breaker open, using offline metadata database",
+					"manga_id", id)
+				return &MangaDetailsQuery{Media: entry.ToDetailed()}, nil
+			}
+			
+			// Sem entrada offline, retornar erro informativo
 			s.logger.Info("Circuit breaker open, manga details unavailable", 
 				"manga_id", id)
 			
-			friendlyErr := s.errorHandler.CreateUserFriendlyMessage(
+			friendlyErr := s.errorHandler.CreateLocalizedError(
 				"SERVICE_UNAVAILABLE",
-				"A integração com AniList está temporariamente indisponível. Tente novamente em alguns minutos.",
+				"details_unavailable",
 				SeverityWarning,
-				[]string{
-					"A funcionalidade será restaurada automaticamente",
-					"Tente novamente em 10-15 minutos",
-					"Use a entrada manual para este manga",
-				},
 			)
 			return nil, friendlyErr
 		}
@@ -988,6 +1102,9 @@ func (s *AniListService) GetMangaDetails(ctx context.Context, id int) (*MangaDet
 	// Armazenar no cache
 	s.cache.SetMangaDetails(id, &query)
 	
+	// Guardar na base offline local para uso futuro sem rede
+	s.offlineDB.Upsert(OfflineEntryFromDetails(query.Media))
+	
 	s.logger.Info("Manga details retrieved successfully",
 		"id", id,
 		"title", mapTitle(query.Media.Title),
@@ -1578,6 +1695,11 @@ func (s *AniListService) GetServiceStatus() map[string]interface{} {
 		status["config"] = s.configManager.GetStats()
 	}
 
+	// Adicionar estatísticas do banco offline
+	if s.offlineDB != nil {
+		status["offline"] = s.offlineDB.GetStats()
+	}
+
 	return status
 }
 
@@ -1673,4 +1795,65 @@ func (s *AniListService) ResetConfig() error {
 	
 	s.logger.Info("Resetting AniList configuration to defaults")
 	return s.configManager.Reset()
-}
\ No newline at end of file
+}
+
+// SaveConfigProfile salva a configuração atual como um perfil nomeado
+func (s *AniListService) SaveConfigProfile(name string) (*ConfigProfile, error) {
+	if s.configManager == nil {
+		return nil, fmt.Errorf("config manager não inicializado")
+	}
+	
+	s.logger.Info("Saving AniList configuration profile", "profile", name)
+	return s.configManager.SaveProfile(name)
+}
+
+// ApplyConfigProfile substitui a configuração pela de um perfil salvo
+func (s *AniListService) ApplyConfigProfile(name string) (*AniListConfig, error) {
+	if s.configManager == nil {
+		return nil, fmt.Errorf("config manager não inicializado")
+	}
+	
+	s.logger.Info("Applying AniList configuration profile", "profile", name)
+	return s.configManager.ApplyProfile(name)
+}
+
+// ListConfigProfiles retorna os perfis de configuração salvos
+func (s *AniListService) ListConfigProfiles() ([]ConfigProfile, error) {
+	if s.configManager == nil {
+		return []ConfigProfile{}, nil
+	}
+	return s.configManager.ListProfiles()
+}
+
+// DeleteConfigProfile remove um perfil de configuração
+func (s *AniListService) DeleteConfigProfile(name string) error {
+	if s.configManager == nil {
+		return fmt.Errorf("config manager não inicializado")
+	}
+	
+	s.logger.Info("Deleting AniList configuration profile", "profile", name)
+	return s.configManager.DeleteProfile(name)
+}
+
+// IsOfflineMode retorna se as buscas devem usar apenas o banco offline
+func (s *AniListService) IsOfflineMode() bool {
+	if s.configManager == nil || s.offlineDB == nil {
+		return false
+	}
+	return s.configManager.IsOfflineMode()
+}
+
+// SetOfflineDumpPath define o arquivo de dump usado pelo banco offline
+func (s *AniListService) SetOfflineDumpPath(path string) error {
+	return s.offlineDB.SetDumpPath(path)
+}
+
+// ImportOfflineDump baixa ou copia um dump de metadados para o banco offline
+func (s *AniListService) ImportOfflineDump(ctx context.Context, source string) error {
+	return s.offlineDB.Import(ctx, source)
+}
+
+// GetOfflineStats retorna estatísticas do banco offline
+func (s *AniListService) GetOfflineStats() map[string]interface{} {
+	return s.offlineDB.GetStats()
+}
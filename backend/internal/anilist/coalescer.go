@@ -0,0 +1,82 @@
+package anilist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// coalescedSearchTimeout limita uma busca agrupada, que não é cancelada junto
+// com o chamador que a iniciou
+const coalescedSearchTimeout = 2 * time.Minute
+
+// searchCall representa uma busca em andamento compartilhada entre chamadores
+type searchCall struct {
+	done   chan struct{}
+	result *SearchResult
+	err    error
+}
+
+// SearchCoalescer agrupa buscas idênticas simultâneas em uma única chamada à API
+// (mesmo padrão do singleflight), evitando gastar rate limit com requests duplicados
+type SearchCoalescer struct {
+	mutex sync.Mutex
+	calls map[string]*searchCall
+}
+
+// NewSearchCoalescer cria um novo agrupador de buscas
+func NewSearchCoalescer() *SearchCoalescer {
+	return &SearchCoalescer{
+		calls: make(map[string]*searchCall),
+	}
+}
+
+// searchKey normaliza os parâmetros de busca em uma chave de agrupamento
+func searchKey(query string, page, perPage int) string {
+	return fmt.Sprintf("%s:%d:%d", strings.ToLower(strings.TrimSpace(query)), page, perPage)
+}
+
+// Do executa fn uma única vez por chave; chamadas concorrentes com a mesma chave
+// aguardam e recebem o mesmo resultado. shared indica se o resultado foi reaproveitado.
+// fn roda desvinculada do cancelamento de ctx (só herda os valores), para que o
+// primeiro chamador desistir não derrube a busca dos demais; cada chamador espera
+// apenas enquanto o próprio ctx estiver ativo.
+func (sc *SearchCoalescer) Do(ctx context.Context, key string, fn func(ctx context.Context) (*SearchResult, error)) (result *SearchResult, err error, shared bool) {
+	sc.mutex.Lock()
+	call, shared := sc.calls[key]
+	if !shared {
+		call = &searchCall{done: make(chan struct{})}
+		sc.calls[key] = call
+		go sc.run(context.WithoutCancel(ctx), key, call, fn)
+	}
+	sc.mutex.Unlock()
+
+	select {
+	case <-call.done:
+		return call.result, call.err, shared
+	case <-ctx.Done():
+		return nil, ctx.Err(), shared
+	}
+}
+
+// run executa a busca agrupada e libera a chave ao terminar
+func (sc *SearchCoalescer) run(ctx context.Context, key string, call *searchCall, fn func(ctx context.Context) (*SearchResult, error)) {
+	ctx, cancel := context.WithTimeout(ctx, coalescedSearchTimeout)
+	defer cancel()
+
+	call.result, call.err = fn(ctx)
+
+	sc.mutex.Lock()
+	delete(sc.calls, key)
+	sc.mutex.Unlock()
+	close(call.done)
+}
+
+// InFlight retorna quantas buscas distintas estão em andamento
+func (sc *SearchCoalescer) InFlight() int {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	return len(sc.calls)
+}
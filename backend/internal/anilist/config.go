@@ -38,6 +38,7 @@ type AniListConfig struct {
 	AutoSearch        bool               `json:"auto_search"`        // Busca automática ao digitar
 	CacheEnabled      bool               `json:"cache_enabled"`      // Cache local habilitado
 	PreferAniList     bool               `json:"prefer_anilist"`     // Preferir dados da AniList sobre manuais
+	OfflineMode       bool               `json:"offline_mode"`       // Usar apenas o banco de metadados local
 	
 	// Metadados
 	Version           string             `json:"version"`            // Versão da configuração
@@ -53,6 +54,7 @@ func GetDefaultConfig() *AniListConfig {
 		AutoSearch:        true,
 		CacheEnabled:      true,
 		PreferAniList:     false,
+		OfflineMode:       false,
 		Version:           "1.0",
 		LastUpdated:       "",
 	}
@@ -63,6 +65,7 @@ type ConfigManager struct {
 	config     *AniListConfig
 	configPath string
 	mutex      sync.RWMutex
+	profilesMu sync.Mutex // protege o arquivo de perfis (profiles.go)
 }
 
 // NewConfigManager cria um novo gerenciador de configurações
@@ -215,6 +218,12 @@ func (cm *ConfigManager) UpdateField(field string, value interface{}) error {
 		} else {
 			return fmt.Errorf("valor inválido para 'prefer_anilist': esperado bool")
 		}
+	case "offline_mode":
+		if v, ok := value.(bool); ok {
+			cm.config.OfflineMode = v
+		} else {
+			return fmt.Errorf("valor inválido para 'offline_mode': esperado bool")
+		}
 	default:
 		return fmt.Errorf("campo desconhecido: %s", field)
 	}
@@ -287,6 +296,13 @@ func (cm *ConfigManager) ShouldPreferAniList() bool {
 	return cm.config.PreferAniList
 }
 
+// IsOfflineMode retorna se o modo offline está habilitado
+func (cm *ConfigManager) IsOfflineMode() bool {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+	return cm.config.OfflineMode
+}
+
 // GetConfigPath retorna o caminho do arquivo de configuração
 func (cm *ConfigManager) GetConfigPath() string {
 	return cm.configPath
@@ -315,6 +331,7 @@ func (cm *ConfigManager) GetStats() map[string]interface{} {
 		"auto_search":        cm.config.AutoSearch,
 		"cache_enabled":      cm.config.CacheEnabled,
 		"prefer_anilist":     cm.config.PreferAniList,
+		"offline_mode":       cm.config.OfflineMode,
 		"config_file":        cm.configPath,
 	}
 }
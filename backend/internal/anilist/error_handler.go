@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"go-upload/backend/internal/i18n"
 )
 
 // ErrorHandler gerencia tradução e tratamento de erros amigáveis
@@ -22,6 +24,9 @@ type FriendlyError struct {
 	RetryAfter      *time.Duration         `json:"retry_after,omitempty"`
 	Context         map[string]interface{} `json:"context,omitempty"`
 	Timestamp       time.Time              `json:"timestamp"`
+
+	// Chave no catálogo i18n (padrão: derivada de ErrorCode)
+	messageKey      string
 }
 
 // ErrorSeverity níveis de severidade dos erros
@@ -77,14 +82,9 @@ func (eh *ErrorHandler) analyzeAndTranslate(friendlyErr *FriendlyError) {
 
 	// Erros de conectividade de rede
 	if eh.isNetworkConnectivityError(errStr) {
-		friendlyErr.UserMessage = "Não foi possível conectar com a AniList. Verifique sua conexão com a internet."
 		friendlyErr.ErrorCode = "NETWORK_CONNECTIVITY"
 		friendlyErr.Severity = SeverityWarning
-		friendlyErr.Suggestions = []string{
-			"Verifique sua conexão com a internet",
-			"Tente novamente em alguns instantes",
-			"Use a entrada manual de metadados como alternativa",
-		}
+		friendlyErr.localize(i18n.DefaultLocale)
 		retryAfter := 30 * time.Second
 		friendlyErr.RetryAfter = &retryAfter
 		return
@@ -92,14 +92,9 @@ func (eh *ErrorHandler) analyzeAndTranslate(friendlyErr *FriendlyError) {
 
 	// Erros de timeout
 	if eh.isTimeoutError(errStr) {
-		friendlyErr.UserMessage = "A busca na AniList está demorando mais que o esperado."
 		friendlyErr.ErrorCode = "SEARCH_TIMEOUT"
 		friendlyErr.Severity = SeverityWarning
-		friendlyErr.Suggestions = []string{
-			"Tente uma busca com termos mais específicos",
-			"Aguarde alguns minutos e tente novamente",
-			"Use a entrada manual se precisar continuar",
-		}
+		friendlyErr.localize(i18n.DefaultLocale)
 		retryAfter := 60 * time.Second
 		friendlyErr.RetryAfter = &retryAfter
 		return
@@ -107,14 +102,9 @@ func (eh *ErrorHandler) analyzeAndTranslate(friendlyErr *FriendlyError) {
 
 	// Erros de rate limiting
 	if eh.isRateLimitError(errStr) {
-		friendlyErr.UserMessage = "Muitas buscas foram feitas recentemente. Aguarde um momento antes de tentar novamente."
 		friendlyErr.ErrorCode = "RATE_LIMITED"
 		friendlyErr.Severity = SeverityInfo
-		friendlyErr.Suggestions = []string{
-			"Aguarde 1-2 minutos antes de fazer nova busca",
-			"Use o cache de resultados anteriores",
-			"Continue com entrada manual dos metadados",
-		}
+		friendlyErr.localize(i18n.DefaultLocale)
 		retryAfter := 90 * time.Second
 		friendlyErr.RetryAfter = &retryAfter
 		return
@@ -122,14 +112,9 @@ func (eh *ErrorHandler) analyzeAndTranslate(friendlyErr *FriendlyError) {
 
 	// Erros de servidor da AniList (5xx)
 	if eh.isServerError(errStr) {
-		friendlyErr.UserMessage = "A AniList está temporariamente indisponível. Tente novamente em alguns minutos."
 		friendlyErr.ErrorCode = "ANILIST_SERVER_ERROR"
 		friendlyErr.Severity = SeverityError
-		friendlyErr.Suggestions = []string{
-			"Aguarde alguns minutos e tente novamente",
-			"Verifique o status da AniList em suas redes sociais",
-			"Use a entrada manual para não perder tempo",
-		}
+		friendlyErr.localize(i18n.DefaultLocale)
 		retryAfter := 5 * time.Minute
 		friendlyErr.RetryAfter = &retryAfter
 		return
@@ -137,28 +122,17 @@ func (eh *ErrorHandler) analyzeAndTranslate(friendlyErr *FriendlyError) {
 
 	// Erros de busca sem resultados
 	if eh.isNoResultsError(errStr) {
-		friendlyErr.UserMessage = "Nenhum manga foi encontrado com esse nome na AniList."
 		friendlyErr.ErrorCode = "NO_SEARCH_RESULTS"
 		friendlyErr.Severity = SeverityInfo
-		friendlyErr.Suggestions = []string{
-			"Tente buscar com o nome em inglês ou japonês",
-			"Verifique a ortografia do nome",
-			"Use termos mais genéricos (ex: só o nome principal)",
-			"Preencha os metadados manualmente",
-		}
+		friendlyErr.localize(i18n.DefaultLocale)
 		return
 	}
 
 	// Erros de autorização/autenticação
 	if eh.isAuthError(errStr) {
-		friendlyErr.UserMessage = "Problema de autorização com a AniList. O serviço pode estar temporariamente indisponível."
 		friendlyErr.ErrorCode = "AUTHORIZATION_ERROR"
 		friendlyErr.Severity = SeverityError
-		friendlyErr.Suggestions = []string{
-			"Tente novamente em alguns minutos",
-			"Entre em contato com o suporte se o problema persistir",
-			"Use a entrada manual como alternativa",
-		}
+		friendlyErr.localize(i18n.DefaultLocale)
 		retryAfter := 10 * time.Minute
 		friendlyErr.RetryAfter = &retryAfter
 		return
@@ -166,42 +140,26 @@ func (eh *ErrorHandler) analyzeAndTranslate(friendlyErr *FriendlyError) {
 
 	// Erros de parsing/formato de dados
 	if eh.isDataFormatError(errStr) {
-		friendlyErr.UserMessage = "Os dados retornados pela AniList estão em formato inesperado."
 		friendlyErr.ErrorCode = "DATA_FORMAT_ERROR"
 		friendlyErr.Severity = SeverityError
-		friendlyErr.Suggestions = []string{
-			"Tente buscar outro manga para verificar se o problema persiste",
-			"Reporte este problema aos desenvolvedores",
-			"Use a entrada manual para este manga específico",
-		}
+		friendlyErr.localize(i18n.DefaultLocale)
 		return
 	}
 
 	// Erros de circuit breaker (API indisponível)
 	if eh.isCircuitBreakerError(errStr) {
-		friendlyErr.UserMessage = "A integração com AniList foi temporariamente desabilitada devido a problemas recorrentes."
 		friendlyErr.ErrorCode = "SERVICE_UNAVAILABLE"
 		friendlyErr.Severity = SeverityWarning
-		friendlyErr.Suggestions = []string{
-			"A funcionalidade será restaurada automaticamente quando o serviço estabilizar",
-			"Use a entrada manual de metadados",
-			"Tente novamente em 10-15 minutos",
-		}
+		friendlyErr.localize(i18n.DefaultLocale)
 		retryAfter := 15 * time.Minute
 		friendlyErr.RetryAfter = &retryAfter
 		return
 	}
 
 	// Erro genérico/desconhecido
-	friendlyErr.UserMessage = "Ocorreu um erro inesperado ao buscar na AniList."
 	friendlyErr.ErrorCode = "UNKNOWN_ERROR"
 	friendlyErr.Severity = SeverityError
-	friendlyErr.Suggestions = []string{
-		"Tente novamente em alguns instantes",
-		"Verifique sua conexão com a internet",
-		"Use a entrada manual de metadados",
-		"Entre em contato com o suporte se o problema persistir",
-	}
+	friendlyErr.localize(i18n.DefaultLocale)
 }
 
 // isNetworkConnectivityError verifica erros de conectividade
@@ -349,6 +307,21 @@ func (eh *ErrorHandler) isCircuitBreakerError(errStr string) bool {
 	return false
 }
 
+// CreateLocalizedError cria um erro amigável cujo texto vem do catálogo i18n
+// (chaves anilist.error.<messageKey> e anilist.error.<messageKey>.suggestions)
+func (eh *ErrorHandler) CreateLocalizedError(code, messageKey string, severity ErrorSeverity) *FriendlyError {
+	friendlyErr := &FriendlyError{
+		ErrorCode:  code,
+		Severity:   severity,
+		Timestamp:  time.Now(),
+		Context:    make(map[string]interface{}),
+		messageKey: messageKey,
+	}
+	friendlyErr.localize(i18n.DefaultLocale)
+
+	return friendlyErr
+}
+
 // CreateUserFriendlyMessage cria mensagem amigável customizada
 func (eh *ErrorHandler) CreateUserFriendlyMessage(code, message string, severity ErrorSeverity, suggestions []string) *FriendlyError {
 	return &FriendlyError{
@@ -363,17 +336,38 @@ func (eh *ErrorHandler) CreateUserFriendlyMessage(code, message string, severity
 
 // GetRecoveryMessage retorna mensagem de recuperação quando serviço volta ao normal
 func (eh *ErrorHandler) GetRecoveryMessage() *FriendlyError {
-	return &FriendlyError{
-		UserMessage: "A integração com AniList foi restaurada e está funcionando normalmente.",
-		ErrorCode:   "SERVICE_RECOVERED",
-		Severity:    SeverityInfo,
-		Suggestions: []string{
-			"Agora você pode fazer buscas na AniList normalmente",
-			"Os resultados em cache ainda estão disponíveis",
-		},
-		Timestamp: time.Now(),
-		Context:   make(map[string]interface{}),
+	return eh.CreateLocalizedError("SERVICE_RECOVERED", "", SeverityInfo)
+}
+
+// catalogKey retorna a chave do erro no catálogo i18n
+func (fe *FriendlyError) catalogKey() string {
+	key := fe.messageKey
+	if key == "" {
+		key = strings.ToLower(fe.ErrorCode)
+	}
+	return "anilist.error." + key
+}
+
+// localize preenche mensagem e sugestões no idioma informado
+func (fe *FriendlyError) localize(locale i18n.Locale) {
+	key := fe.catalogKey()
+	fe.UserMessage = i18n.T(locale, key)
+	fe.Suggestions = i18n.Lines(locale, key+".suggestions")
+}
+
+// Localized retorna uma cópia do erro com mensagem e sugestões no idioma do cliente.
+// Erros criados com texto livre (CreateUserFriendlyMessage) são retornados sem alteração.
+func (fe *FriendlyError) Localized(locale i18n.Locale) *FriendlyError {
+	if fe == nil {
+		return nil
+	}
+	if fe.messageKey == "" && i18n.T(locale, fe.catalogKey()) == fe.catalogKey() {
+		return fe
 	}
+
+	localized := *fe
+	localized.localize(locale)
+	return &localized
 }
 
 // Error implementa interface error
@@ -9,11 +9,18 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 )
 
+// CoverURLPrefix é o caminho HTTP onde as capas em cache são servidas
+const CoverURLPrefix = "/covers/"
+
+// coverNamePattern valida nomes de capa recebidos via HTTP (hash MD5 + extensão)
+var coverNamePattern = regexp.MustCompile(`^[0-9a-f]{32}\.(jpg|jpeg|png|webp)$`)
+
 // ImageLoader gerencia o carregamento lazy de imagens da AniList
 type ImageLoader struct {
 	cacheDir     string
@@ -270,6 +277,30 @@ func (il *ImageLoader) getFromCache(url string) string {
 	return ""
 }
 
+// LocalURL retorna a URL local (/covers/{hash}) de uma imagem já em cache, ou vazio
+func (il *ImageLoader) LocalURL(url string) string {
+	if url == "" || il.getFromCache(url) == "" {
+		return ""
+	}
+	return CoverURLPrefix + strings.TrimPrefix(il.generateFileName(url), "anilist_")
+}
+
+// CoverPath resolve o nome recebido em /covers/{hash} para o arquivo em cache.
+// Apenas nomes no formato gerado por generateFileName são aceitos (evita path traversal).
+func (il *ImageLoader) CoverPath(name string) (string, bool) {
+	if !coverNamePattern.MatchString(name) {
+		return "", false
+	}
+	
+	localPath := filepath.Join(il.cacheDir, "anilist_"+name)
+	if _, err := os.Stat(localPath); err != nil {
+		return "", false
+	}
+	
+	il.updateAccessTime(localPath)
+	return localPath, true
+}
+
 // updateAccessTime atualiza o tempo de acesso de um arquivo
 func (il *ImageLoader) updateAccessTime(path string) {
 	// Simples touch no arquivo para indicar uso recente
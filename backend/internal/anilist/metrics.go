@@ -30,6 +30,7 @@ type PerformanceMetrics struct {
 	// Status da API
 	APIErrors           int64     `json:"api_errors"`
 	RateLimitHits       int64     `json:"rate_limit_hits"`
+	CoalescedRequests   int64     `json:"coalesced_requests"`
 	LastAPICall         time.Time `json:"last_api_call"`
 	
 	// Estatísticas de cache
@@ -130,6 +131,14 @@ func (pm *PerformanceMetrics) RecordRateLimitHit() {
 	pm.RateLimitHits++
 }
 
+// RecordCoalescedRequest registra uma busca atendida por uma chamada já em andamento
+func (pm *PerformanceMetrics) RecordCoalescedRequest() {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	
+	pm.CoalescedRequests++
+}
+
 // UpdateCacheSize atualiza o tamanho do cache
 func (pm *PerformanceMetrics) UpdateCacheSize(size int) {
 	pm.mutex.Lock()
@@ -175,6 +184,7 @@ func (pm *PerformanceMetrics) GetSummary() map[string]interface{} {
 		"average_details_ms":    snapshot.AverageDetailsTime,
 		"api_errors":           snapshot.APIErrors,
 		"rate_limit_hits":      snapshot.RateLimitHits,
+		"coalesced_requests":   snapshot.CoalescedRequests,
 		"data_transferred_mb":  snapshot.DataTransferredKB / 1024,
 		"cache_size":           snapshot.CacheSize,
 	}
@@ -0,0 +1,591 @@
+package anilist
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// SourceOffline identifica resultados vindos do banco de metadados local
+const SourceOffline = "offline"
+
+// maxOfflineDumpDownload limita o tamanho de um dump baixado por Import
+const maxOfflineDumpDownload = 1 << 30
+
+// OfflineEntry representa um mangá no banco de metadados local.
+// O formato é compartilhado entre dumps baixados (AniList/MangaDex) e a base mantida pelo usuário.
+type OfflineEntry struct {
+	ID           int      `json:"id"`
+	Source       string   `json:"source,omitempty"` // "anilist", "mangadex" ou "local"
+	TitleRomaji  string   `json:"title_romaji,omitempty"`
+	TitleEnglish string   `json:"title_english,omitempty"`
+	TitleNative  string   `json:"title_native,omitempty"`
+	Synonyms     []string `json:"synonyms,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Status       string   `json:"status,omitempty"`
+	Format       string   `json:"format,omitempty"`
+	Chapters     *int     `json:"chapters,omitempty"`
+	Volumes      *int     `json:"volumes,omitempty"`
+	Genres       []string `json:"genres,omitempty"`
+	Authors      []string `json:"authors,omitempty"`
+	Artists      []string `json:"artists,omitempty"`
+	CoverImage   string   `json:"cover_image,omitempty"`
+	BannerImage  string   `json:"banner_image,omitempty"`
+	MeanScore    *int     `json:"mean_score,omitempty"`
+	Popularity   int      `json:"popularity,omitempty"`
+}
+
+// key identifica a entrada independentemente da origem do dump
+func (e *OfflineEntry) key() string {
+	source := e.Source
+	if source == "" {
+		source = "anilist"
+	}
+	return fmt.Sprintf("%s:%d", source, e.ID)
+}
+
+// titles retorna todos os títulos conhecidos da entrada
+func (e *OfflineEntry) titles() []string {
+	titles := []string{e.TitleEnglish, e.TitleRomaji, e.TitleNative}
+	return append(titles, e.Synonyms...)
+}
+
+// title converte os títulos para a estrutura da AniList
+func (e *OfflineEntry) title() Title {
+	return Title{
+		Romaji:  optionalString(e.TitleRomaji),
+		English: optionalString(e.TitleEnglish),
+		Native:  optionalString(e.TitleNative),
+	}
+}
+
+// staff converte autores e artistas em arestas de staff compatíveis com extractStaffRole
+func (e *OfflineEntry) staff() Staff {
+	var staff Staff
+	for _, author := range e.Authors {
+		edge := StaffEdge{Role: "Story"}
+		edge.Node.Name.Full = author
+		staff.Edges = append(staff.Edges, edge)
+	}
+	for _, artist := range e.Artists {
+		edge := StaffEdge{Role: "Art"}
+		edge.Node.Name.Full = artist
+		staff.Edges = append(staff.Edges, edge)
+	}
+	return staff
+}
+
+// ToBasic converte a entrada para o formato de resultado de busca
+func (e *OfflineEntry) ToBasic() MangaBasic {
+	return MangaBasic{
+		ID:          e.ID,
+		Title:       e.title(),
+		Description: optionalString(e.Description),
+		Status:      e.Status,
+		Chapters:    e.Chapters,
+		Volumes:     e.Volumes,
+		Genres:      e.Genres,
+		Synonyms:    e.Synonyms,
+		MeanScore:   e.MeanScore,
+		Popularity:  e.Popularity,
+		CoverImage:  Image{Large: optionalString(e.CoverImage)},
+		Staff:       e.staff(),
+	}
+}
+
+// ToDetailed converte a entrada para o formato de detalhes completos
+func (e *OfflineEntry) ToDetailed() MangaDetailed {
+	return MangaDetailed{
+		ID:          e.ID,
+		Title:       e.title(),
+		Description: optionalString(e.Description),
+		Status:      e.Status,
+		Format:      optionalString(e.Format),
+		Chapters:    e.Chapters,
+		Volumes:     e.Volumes,
+		Genres:      e.Genres,
+		Synonyms:    e.Synonyms,
+		MeanScore:   e.MeanScore,
+		Popularity:  e.Popularity,
+		CoverImage:  Image{Large: optionalString(e.CoverImage)},
+		BannerImage: optionalString(e.BannerImage),
+		Staff:       e.staff(),
+	}
+}
+
+// OfflineEntryFromDetails cria uma entrada local a partir de detalhes obtidos da API
+func OfflineEntryFromDetails(manga MangaDetailed) OfflineEntry {
+	entry := OfflineEntry{
+		ID:          manga.ID,
+		Source:      "anilist",
+		Description: derefString(manga.Description),
+		Status:      manga.Status,
+		Format:      derefString(manga.Format),
+		Chapters:    manga.Chapters,
+		Volumes:     manga.Volumes,
+		Genres:      manga.Genres,
+		Synonyms:    manga.Synonyms,
+		MeanScore:   manga.MeanScore,
+		Popularity:  manga.Popularity,
+		CoverImage:  mapCoverImage(manga.CoverImage),
+		BannerImage: derefString(manga.BannerImage),
+	}
+	entry.TitleRomaji = derefString(manga.Title.Romaji)
+	entry.TitleEnglish = derefString(manga.Title.English)
+	entry.TitleNative = derefString(manga.Title.Native)
+
+	for _, edge := range manga.Staff.Edges {
+		if edge.Node.Name.Full == "" {
+			continue
+		}
+		if matchesRole(edge.Role, "Story") {
+			entry.Authors = append(entry.Authors, edge.Node.Name.Full)
+		} else if matchesRole(edge.Role, "Art") {
+			entry.Artists = append(entry.Artists, edge.Node.Name.Full)
+		}
+	}
+
+	return entry
+}
+
+// OfflineDatabase mantém em memória o dump baixado e a base local do usuário,
+// permitindo busca de títulos e preenchimento de metadados sem acesso à rede
+type OfflineDatabase struct {
+	dumpPath   string // dump em uso (somente leitura)
+	importPath string // cópia gerenciada no diretório de dados, a única que Import substitui
+	localPath  string // entradas mantidas pelo usuário ou aprendidas da API
+	entries    map[string]*OfflineEntry
+	local      map[string]*OfflineEntry
+	loadedAt   time.Time
+	mutex      sync.RWMutex
+	saveMutex  sync.Mutex
+	logger     Logger
+}
+
+// NewOfflineDatabase cria o banco offline e carrega os arquivos existentes
+func NewOfflineDatabase(dataDir string, logger Logger) *OfflineDatabase {
+	importPath := filepath.Join(dataDir, "anilist_offline_dump.jsonl")
+	db := &OfflineDatabase{
+		dumpPath:   importPath,
+		importPath: importPath,
+		localPath:  filepath.Join(dataDir, "anilist_offline_local.json"),
+		entries:    make(map[string]*OfflineEntry),
+		local:      make(map[string]*OfflineEntry),
+		logger:     logger,
+	}
+
+	if err := db.Reload(); err != nil {
+		logger.Warn("Failed to load offline metadata database", "error", err)
+	}
+
+	return db
+}
+
+// SetDumpPath altera o arquivo de dump usado e recarrega o banco. O arquivo é
+// apenas lido: um Import posterior grava no diretório de dados e passa a usá-lo.
+func (db *OfflineDatabase) SetDumpPath(path string) error {
+	db.mutex.Lock()
+	db.dumpPath = path
+	db.mutex.Unlock()
+
+	return db.Reload()
+}
+
+// Reload relê o dump e a base local do disco
+func (db *OfflineDatabase) Reload() error {
+	db.mutex.RLock()
+	dumpPath, localPath := db.dumpPath, db.localPath
+	db.mutex.RUnlock()
+
+	entries, err := readOfflineEntries(dumpPath)
+	if err != nil {
+		return fmt.Errorf("erro ao ler dump offline: %w", err)
+	}
+	local, err := readOfflineEntries(localPath)
+	if err != nil {
+		return fmt.Errorf("erro ao ler base offline local: %w", err)
+	}
+
+	db.mutex.Lock()
+	db.entries = entries
+	db.local = local
+	db.loadedAt = time.Now()
+	db.mutex.Unlock()
+
+	db.logger.Info("Offline metadata database loaded",
+		"dump_entries", len(entries),
+		"local_entries", len(local),
+		"dump_path", dumpPath)
+
+	return nil
+}
+
+// readOfflineEntries aceita tanto um array JSON quanto JSON Lines (formato comum de dumps grandes)
+func readOfflineEntries(path string) (map[string]*OfflineEntry, error) {
+	entries := make(map[string]*OfflineEntry)
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	decoder := json.NewDecoder(reader)
+
+	// Detectar array JSON pelo primeiro caractere não vazio
+	first, err := peekNonSpace(reader)
+	if err == io.EOF {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if first == '[' {
+		var list []*OfflineEntry
+		if err := decoder.Decode(&list); err != nil {
+			return nil, err
+		}
+		for _, entry := range list {
+			if entry == nil {
+				continue // `null` no array
+			}
+			entries[entry.key()] = entry
+		}
+		return entries, nil
+	}
+
+	for {
+		var entry *OfflineEntry
+		if err := decoder.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			continue // linha `null`
+		}
+		entries[entry.key()] = entry
+	}
+
+	return entries, nil
+}
+
+// peekNonSpace descarta espaços iniciais e retorna o próximo byte sem consumi-lo
+func peekNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !unicode.IsSpace(rune(b[0])) {
+			return b[0], nil
+		}
+		reader.Discard(1)
+	}
+}
+
+// Import substitui o dump offline a partir de uma URL (http/https) ou caminho local.
+// O dump é gravado no diretório de dados e passa a ser o dump em uso; um arquivo
+// do usuário configurado com SetDumpPath (--offline-db) nunca é sobrescrito.
+func (db *OfflineDatabase) Import(ctx context.Context, source string) error {
+	dumpPath := db.importPath
+
+	var reader io.ReadCloser
+	var limit int64 // 0 = sem limite
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+		if err != nil {
+			return fmt.Errorf("URL de dump inválida: %w", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("erro ao baixar dump: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("erro ao baixar dump: status %d", resp.StatusCode)
+		}
+		reader = resp.Body
+		limit = maxOfflineDumpDownload
+	} else {
+		file, err := os.Open(source)
+		if err != nil {
+			return fmt.Errorf("erro ao abrir dump: %w", err)
+		}
+		reader = file
+	}
+	defer reader.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dumpPath), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório do dump: %w", err)
+	}
+
+	// Escrever em arquivo temporário e renomear para não corromper o dump atual
+	tmpPath := dumpPath + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo temporário: %w", err)
+	}
+	var src io.Reader = reader
+	if limit > 0 {
+		src = io.LimitReader(reader, limit+1)
+	}
+	written, err := io.Copy(tmp, src)
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("erro ao gravar dump: %w", err)
+	}
+	if limit > 0 && written > limit {
+		os.Remove(tmpPath)
+		return fmt.Errorf("dump maior que o limite de %d MB", limit>>20)
+	}
+
+	// Validar antes de substituir
+	if _, err := readOfflineEntries(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("dump em formato inválido: %w", err)
+	}
+	if err := os.Rename(tmpPath, dumpPath); err != nil {
+		return fmt.Errorf("erro ao substituir dump: %w", err)
+	}
+
+	return db.SetDumpPath(dumpPath)
+}
+
+// Upsert adiciona ou atualiza uma entrada na base local e persiste em disco
+func (db *OfflineDatabase) Upsert(entry OfflineEntry) {
+	db.mutex.Lock()
+	db.local[entry.key()] = &entry
+	db.mutex.Unlock()
+
+	go db.saveLocal()
+}
+
+// saveLocal grava a base local do usuário
+func (db *OfflineDatabase) saveLocal() {
+	db.saveMutex.Lock()
+	defer db.saveMutex.Unlock()
+
+	db.mutex.RLock()
+	list := make([]*OfflineEntry, 0, len(db.local))
+	for _, entry := range db.local {
+		list = append(list, entry)
+	}
+	localPath := db.localPath
+	db.mutex.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].key() < list[j].key() })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		db.logger.Error("Failed to marshal offline local database", "error", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		db.logger.Error("Failed to create offline database directory", "error", err)
+		return
+	}
+	if err := os.WriteFile(localPath, data, 0644); err != nil {
+		db.logger.Error("Failed to save offline local database", "error", err)
+	}
+}
+
+// Size retorna o número total de entradas disponíveis
+func (db *OfflineDatabase) Size() int {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	return len(db.entries) + len(db.local)
+}
+
+// Get busca uma entrada pelo ID da AniList (a base local tem prioridade sobre o dump)
+func (db *OfflineDatabase) Get(id int) (*OfflineEntry, bool) {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	key := fmt.Sprintf("anilist:%d", id)
+	if entry, exists := db.local[key]; exists {
+		return entry, true
+	}
+	entry, exists := db.entries[key]
+	return entry, exists
+}
+
+// scoredEntry associa uma entrada à sua pontuação de similaridade
+type scoredEntry struct {
+	entry *OfflineEntry
+	score float64
+}
+
+// Search busca títulos por similaridade e retorna resultados paginados
+func (db *OfflineDatabase) Search(query string, page, perPage int) *SearchResult {
+	startTime := time.Now()
+	normalizedQuery := normalizeTitle(query)
+
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 10
+	}
+
+	db.mutex.RLock()
+	var matches []scoredEntry
+	seen := make(map[string]bool)
+	for _, source := range []map[string]*OfflineEntry{db.local, db.entries} {
+		for key, entry := range source {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if score := titleScore(normalizedQuery, entry); score >= 0.3 {
+				matches = append(matches, scoredEntry{entry: entry, score: score})
+			}
+		}
+	}
+	db.mutex.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].entry.Popularity > matches[j].entry.Popularity
+	})
+
+	lastPage := (len(matches) + perPage - 1) / perPage
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	results := []MangaBasic{}
+	start := (page - 1) * perPage
+	for i := start; i < len(matches) && i < start+perPage; i++ {
+		results = append(results, matches[i].entry.ToBasic())
+	}
+
+	return &SearchResult{
+		Results:     results,
+		Total:       len(matches),
+		CurrentPage: page,
+		LastPage:    lastPage,
+		HasNextPage: page < lastPage,
+		Query:       query,
+		TimeMS:      time.Since(startTime).Milliseconds(),
+		Source:      SourceOffline,
+	}
+}
+
+// GetStats retorna estatísticas do banco offline
+func (db *OfflineDatabase) GetStats() map[string]interface{} {
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+
+	return map[string]interface{}{
+		"dump_entries":  len(db.entries),
+		"local_entries": len(db.local),
+		"dump_path":     db.dumpPath,
+		"local_path":    db.localPath,
+		"loaded_at":     db.loadedAt,
+	}
+}
+
+// titleScore calcula a melhor similaridade entre a busca e os títulos da entrada
+func titleScore(normalizedQuery string, entry *OfflineEntry) float64 {
+	if normalizedQuery == "" {
+		return 0
+	}
+
+	best := 0.0
+	for _, title := range entry.titles() {
+		normalized := normalizeTitle(title)
+		if normalized == "" {
+			continue
+		}
+
+		var score float64
+		switch {
+		case normalized == normalizedQuery:
+			score = 1.0
+		case strings.HasPrefix(normalized, normalizedQuery):
+			score = 0.9
+		case strings.Contains(normalized, normalizedQuery):
+			score = 0.8
+		default:
+			score = tokenOverlap(normalizedQuery, normalized) * 0.7
+		}
+
+		if score > best {
+			best = score
+		}
+	}
+
+	return best
+}
+
+// tokenOverlap calcula a similaridade de Jaccard entre as palavras dos títulos
+func tokenOverlap(a, b string) float64 {
+	tokensA := strings.Fields(a)
+	tokensB := make(map[string]bool)
+	for _, token := range strings.Fields(b) {
+		tokensB[token] = true
+	}
+
+	common := 0
+	union := len(tokensB)
+	for _, token := range tokensA {
+		if tokensB[token] {
+			common++
+		} else {
+			union++
+		}
+	}
+
+	if union == 0 {
+		return 0
+	}
+	return float64(common) / float64(union)
+}
+
+// normalizeTitle remove pontuação e diferenças de caixa para comparação
+func normalizeTitle(title string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		} else {
+			builder.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+// optionalString retorna nil para strings vazias
+func optionalString(value string) *string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return &value
+}
+
+// derefString retorna o valor do ponteiro ou string vazia
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
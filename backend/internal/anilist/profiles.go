@@ -0,0 +1,158 @@
+package anilist
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxProfileNameLength limita o nome de um perfil de configuração
+const maxProfileNameLength = 64
+
+// ConfigProfile é um preset nomeado da configuração da AniList (idioma, modo
+// de preenchimento, cache...), útil para alternar entre catálogos em Romaji e
+// em inglês sem reconfigurar campo a campo
+type ConfigProfile struct {
+	Name    string        `json:"name"`
+	Config  AniListConfig `json:"config"`
+	SavedAt time.Time     `json:"saved_at"`
+}
+
+// profilesPath é o arquivo dos perfis, ao lado do arquivo de configuração
+func (cm *ConfigManager) profilesPath() string {
+	return filepath.Join(filepath.Dir(cm.configPath), "anilist_profiles.json")
+}
+
+// loadProfiles lê os perfis salvos; o caller deve ter cm.profilesMu
+func (cm *ConfigManager) loadProfiles() (map[string]*ConfigProfile, error) {
+	profiles := make(map[string]*ConfigProfile)
+	data, err := os.ReadFile(cm.profilesPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return profiles, nil
+		}
+		return nil, fmt.Errorf("erro ao ler perfis: %w", err)
+	}
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar perfis: %w", err)
+	}
+	return profiles, nil
+}
+
+// saveProfiles grava os perfis de forma atômica; o caller deve ter cm.profilesMu
+func (cm *ConfigManager) saveProfiles(profiles map[string]*ConfigProfile) error {
+	path := cm.profilesPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de perfis: %w", err)
+	}
+	data, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao codificar perfis: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("erro ao salvar perfis: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("erro ao salvar perfis: %w", err)
+	}
+	return nil
+}
+
+// normalizeProfileName valida o nome de um perfil
+func normalizeProfileName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("nome do perfil vazio")
+	}
+	if len([]rune(name)) > maxProfileNameLength {
+		return "", fmt.Errorf("nome do perfil muito longo (máximo %d caracteres)", maxProfileNameLength)
+	}
+	return name, nil
+}
+
+// SaveProfile salva a configuração atual como o perfil name, substituindo um
+// perfil de mesmo nome
+func (cm *ConfigManager) SaveProfile(name string) (*ConfigProfile, error) {
+	name, err := normalizeProfileName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	cm.profilesMu.Lock()
+	defer cm.profilesMu.Unlock()
+
+	profiles, err := cm.loadProfiles()
+	if err != nil {
+		return nil, err
+	}
+	profile := &ConfigProfile{Name: name, Config: *cm.Get(), SavedAt: time.Now()}
+	profile.Config.Version, profile.Config.LastUpdated = "", ""
+	profiles[name] = profile
+	if err := cm.saveProfiles(profiles); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// ApplyProfile substitui a configuração atual pela do perfil name
+func (cm *ConfigManager) ApplyProfile(name string) (*AniListConfig, error) {
+	name = strings.TrimSpace(name)
+
+	cm.profilesMu.Lock()
+	profiles, err := cm.loadProfiles()
+	cm.profilesMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	profile, exists := profiles[name]
+	if !exists {
+		return nil, fmt.Errorf("perfil não encontrado: %s", name)
+	}
+
+	config := profile.Config
+	if err := cm.Update(&config); err != nil {
+		return nil, err
+	}
+	return cm.Get(), nil
+}
+
+// ListProfiles retorna os perfis salvos em ordem alfabética
+func (cm *ConfigManager) ListProfiles() ([]ConfigProfile, error) {
+	cm.profilesMu.Lock()
+	profiles, err := cm.loadProfiles()
+	cm.profilesMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]ConfigProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		list = append(list, *profile)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// DeleteProfile remove o perfil name
+func (cm *ConfigManager) DeleteProfile(name string) error {
+	name = strings.TrimSpace(name)
+
+	cm.profilesMu.Lock()
+	defer cm.profilesMu.Unlock()
+
+	profiles, err := cm.loadProfiles()
+	if err != nil {
+		return err
+	}
+	if _, exists := profiles[name]; !exists {
+		return fmt.Errorf("perfil não encontrado: %s", name)
+	}
+	delete(profiles, name)
+	return cm.saveProfiles(profiles)
+}
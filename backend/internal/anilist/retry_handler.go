@@ -3,12 +3,12 @@ package anilist
 import (
 	"context"
 	"fmt"
-	"math"
-	"math/rand"
 	"net"
 	"net/url"
 	"strings"
 	"time"
+
+	"go-upload/backend/internal/retry"
 )
 
 // RetryHandler gerencia tentativas de retry com backoff exponencial
@@ -99,9 +99,22 @@ func (rh *RetryHandler) ExecuteWithRetry(ctx context.Context, operation func() e
 		return fmt.Errorf("circuit breaker is open for operation: %s", operationName)
 	}
 
-	var lastErr error
-	
-	for attempt := 1; attempt <= rh.maxRetries; attempt++ {
+	var lastErr *RetryableError
+	retrier := &retry.Retrier{
+		Policy: rh.policy(),
+		Name:   "anilist",
+		Classify: func(err error) (bool, time.Duration) {
+			return lastErr.Retryable, lastErr.RetryAfter
+		},
+		OnRetry: func(attempt int, err error, delay time.Duration) {
+			rh.logger.Debug("Waiting before retry", 
+				"operation", operationName,
+				"attempt", attempt,
+				"delay", delay.String())
+		},
+	}
+
+	attempts, err := retrier.Do(ctx, func(attempt int) error {
 		rh.logger.Debug("Executing operation with retry", 
 			"operation", operationName,
 			"attempt", attempt,
@@ -109,65 +122,48 @@ func (rh *RetryHandler) ExecuteWithRetry(ctx context.Context, operation func() e
 
 		// Executar operação
 		err := operation()
-
 		if err == nil {
-			// Sucesso - notificar circuit breaker
-			rh.circuitBreaker.RecordSuccess()
-			if attempt > 1 {
-				rh.logger.Info("Operation succeeded after retry", 
-					"operation", operationName,
-					"successful_attempt", attempt)
-			}
 			return nil
 		}
 
 		// Classificar erro
-		retryableErr := rh.classifyError(err, attempt, rh.maxRetries)
-		lastErr = retryableErr
+		lastErr = rh.classifyError(err, attempt, rh.maxRetries)
 
 		// Log do erro
 		rh.logger.Warn("Operation failed", 
 			"operation", operationName,
 			"attempt", attempt,
 			"error", err.Error(),
-			"error_type", rh.getErrorTypeName(retryableErr.Type),
-			"retryable", retryableErr.Retryable)
-
-		// Verificar se deve fazer retry
-		if !retryableErr.Retryable || attempt >= rh.maxRetries {
-			// Notificar circuit breaker sobre falha
-			rh.circuitBreaker.RecordFailure()
-			break
-		}
+			"error_type", rh.getErrorTypeName(lastErr.Type),
+			"retryable", lastErr.Retryable)
+		return err
+	})
 
-		// Verificar cancelamento do contexto
-		if ctx.Err() != nil {
-			rh.logger.Debug("Context cancelled, stopping retries", 
+	if err == nil {
+		// Sucesso - notificar circuit breaker
+		rh.circuitBreaker.RecordSuccess()
+		if attempts > 1 {
+			rh.logger.Info("Operation succeeded after retry", 
 				"operation", operationName,
-				"attempt", attempt)
-			return ctx.Err()
+				"successful_attempt", attempts)
 		}
+		return nil
+	}
 
-		// Calcular delay para próxima tentativa
-		delay := rh.calculateDelay(attempt, retryableErr.RetryAfter)
-		
-		rh.logger.Debug("Waiting before retry", 
+	// Contexto cancelado durante a espera: não conta como falha do serviço
+	if ctxErr := ctx.Err(); ctxErr != nil && err == ctxErr {
+		rh.logger.Debug("Context cancelled, stopping retries", 
 			"operation", operationName,
-			"attempt", attempt,
-			"delay", delay.String())
-
-		// Aguardar antes da próxima tentativa
-		select {
-		case <-time.After(delay):
-			// Continuar para próxima tentativa
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+			"attempt", attempts)
+		return ctxErr
 	}
 
+	// Notificar circuit breaker sobre falha
+	rh.circuitBreaker.RecordFailure()
+
 	rh.logger.Error("Operation failed after all retries", 
 		"operation", operationName,
-		"attempts", rh.maxRetries,
+		"attempts", attempts,
 		"final_error", lastErr.Error())
 
 	return lastErr
@@ -250,34 +246,19 @@ func (rh *RetryHandler) classifyError(err error, attempt, maxAttempts int) *Retr
 	return retryableErr
 }
 
-// calculateDelay calcula o delay para a próxima tentativa
-func (rh *RetryHandler) calculateDelay(attempt int, retryAfter time.Duration) time.Duration {
-	// Se há um retry-after específico, usar ele
-	if retryAfter > 0 {
-		return retryAfter
+// policy converte a configuração do handler na política compartilhada de retry
+// (maxRetries conta a tentativa original)
+func (rh *RetryHandler) policy() retry.Policy {
+	policy := retry.Policy{
+		MaxRetries: rh.maxRetries - 1,
+		BaseDelay:  rh.baseDelay,
+		MaxDelay:   rh.maxDelay,
+		Factor:     rh.backoffFactor,
 	}
-
-	// Calcular backoff exponencial
-	delay := float64(rh.baseDelay) * math.Pow(rh.backoffFactor, float64(attempt-1))
-	
-	// Aplicar limite máximo
-	if time.Duration(delay) > rh.maxDelay {
-		delay = float64(rh.maxDelay)
-	}
-
-	// Adicionar jitter se habilitado (±25% do delay)
 	if rh.jitterEnabled {
-		jitterRange := delay * 0.25
-		jitter := (rand.Float64() - 0.5) * 2 * jitterRange
-		delay += jitter
-		
-		// Garantir que o delay não seja negativo
-		if delay < 0 {
-			delay = float64(rh.baseDelay)
-		}
+		policy.Jitter = retry.DefaultJitter
 	}
-
-	return time.Duration(delay)
+	return policy
 }
 
 // isNetworkError verifica se o erro é relacionado à rede
@@ -0,0 +1,553 @@
+package autofill
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/anilist"
+	"go-upload/backend/internal/mangaid"
+	"go-upload/backend/internal/metadata"
+	"go-upload/backend/internal/overrides"
+	"go-upload/backend/internal/registry"
+)
+
+// Status representa o estado do job de preenchimento
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusCanceled  Status = "canceled"
+	StatusFailed    Status = "failed"
+)
+
+// fillableFields são os campos do JSON preenchidos quando estão vazios
+var fillableFields = []string{"description", "author", "artist", "cover", "status"}
+
+// requiredFields determinam se uma obra precisa de preenchimento
+var requiredFields = []string{"author", "cover", "description"}
+
+// Candidate é uma sugestão da AniList para uma obra que precisa de revisão
+type Candidate struct {
+	AniListID int     `json:"anilistId"`
+	Title     string  `json:"title"`
+	Score     float64 `json:"score"`
+}
+
+// ReviewItem é uma obra sem correspondência de alta confiança
+type ReviewItem struct {
+	MangaID    string      `json:"mangaId"`
+	Title      string      `json:"title"`
+	Reason     string      `json:"reason"`
+	Candidates []Candidate `json:"candidates,omitempty"`
+}
+
+// Job guarda o progresso do preenchimento (persistido para permitir retomar)
+type Job struct {
+	ID        string            `json:"id"`
+	Status    Status            `json:"status"`
+	Total     int               `json:"total"`
+	Processed int               `json:"processed"`
+	Applied   []string          `json:"applied"`
+	Review    []ReviewItem      `json:"review"`
+	Failed    map[string]string `json:"failed"`
+	Done      map[string]bool   `json:"done"`
+	StartedAt time.Time         `json:"startedAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// Progress é enviado a cada obra processada
+type Progress struct {
+	JobID     string `json:"jobId"`
+	MangaID   string `json:"mangaId"`
+	Outcome   string `json:"outcome"` // "applied", "review" ou "failed"
+	Processed int    `json:"processed"`
+	Total     int    `json:"total"`
+	Applied   int    `json:"applied"`
+	Review    int    `json:"review"`
+}
+
+// Config define os parâmetros do preenchimento
+type Config struct {
+	JSONDir       string               // diretório dos JSONs da biblioteca
+	StatePath     string               // arquivo de estado para retomar
+	Throttle      time.Duration        // intervalo mínimo entre chamadas ao provedor
+	MinConfidence float64              // similaridade mínima para aplicar automaticamente
+	Locks         *metadata.FieldLocks // campos travados nunca são preenchidos (nil = nenhum)
+	Overrides     *overrides.Store     // pasta -> título/ID canônico, consultado antes de tudo (nil = nenhum)
+	Lock          sync.Locker          // serializa a escrita com as edições de metadados (opcional)
+}
+
+// Filler percorre a biblioteca preenchendo metadados ausentes via AniList
+type Filler struct {
+	config   Config
+	service  *anilist.AniListService
+	registry *registry.Registry
+
+	mutex  sync.Mutex
+	job    *Job
+	cancel context.CancelFunc
+}
+
+// NewFiller cria um novo preenchedor de metadados
+func NewFiller(config Config, service *anilist.AniListService, idRegistry *registry.Registry) *Filler {
+	if config.Throttle <= 0 {
+		config.Throttle = 2 * time.Second
+	}
+	if config.MinConfidence <= 0 {
+		config.MinConfidence = 0.9
+	}
+
+	f := &Filler{
+		config:   config,
+		service:  service,
+		registry: idRegistry,
+	}
+
+	// Carregar job anterior (se houver) para permitir retomar
+	if data, err := os.ReadFile(config.StatePath); err == nil {
+		var job Job
+		if json.Unmarshal(data, &job) == nil {
+			if job.Status == StatusRunning {
+				job.Status = StatusCanceled // processo anterior foi interrompido
+			}
+			f.job = &job
+		}
+	}
+
+	return f
+}
+
+// Start inicia o job em background. Com resume, obras já processadas pelo job
+// anterior são puladas e a lista de revisão é mantida.
+func (f *Filler) Start(resume bool, onProgress func(Progress), onDone func(*Job)) (*Job, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.job != nil && f.job.Status == StatusRunning {
+		return nil, fmt.Errorf("preenchimento já em andamento: %s", f.job.ID)
+	}
+
+	job := &Job{
+		ID:        fmt.Sprintf("autofill_%d", time.Now().UnixNano()),
+		Status:    StatusRunning,
+		Applied:   []string{},
+		Review:    []ReviewItem{},
+		Failed:    make(map[string]string),
+		Done:      make(map[string]bool),
+		StartedAt: time.Now(),
+	}
+	if resume && f.job != nil && f.job.Status != StatusCompleted {
+		job = f.job
+		job.Status = StatusRunning
+		job.Error = ""
+		if job.Done == nil {
+			job.Done = make(map[string]bool)
+		}
+		// Falhas (rede, rate limit) são tentadas novamente ao retomar
+		for mangaID := range job.Failed {
+			delete(job.Done, mangaID)
+			job.Processed--
+		}
+		job.Failed = make(map[string]string)
+	}
+	f.job = job
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+
+	go f.run(ctx, job, onProgress, onDone)
+
+	return f.snapshot(), nil
+}
+
+// Cancel interrompe o job atual (o estado fica salvo para retomar)
+func (f *Filler) Cancel() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.cancel == nil || f.job == nil || f.job.Status != StatusRunning {
+		return false
+	}
+	f.cancel()
+	return true
+}
+
+// Status retorna uma cópia do job atual ou nil
+func (f *Filler) Status() *Job {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.snapshot()
+}
+
+// snapshot copia o job atual (chamador deve ter o lock)
+func (f *Filler) snapshot() *Job {
+	if f.job == nil {
+		return nil
+	}
+
+	job := *f.job
+	job.Applied = append([]string{}, f.job.Applied...)
+	job.Review = append([]ReviewItem{}, f.job.Review...)
+	job.Failed = make(map[string]string, len(f.job.Failed))
+	for k, v := range f.job.Failed {
+		job.Failed[k] = v
+	}
+	job.Done = nil // interno, não exposto aos clientes
+	return &job
+}
+
+// run executa o preenchimento obra por obra
+func (f *Filler) run(ctx context.Context, job *Job, onProgress func(Progress), onDone func(*Job)) {
+	pending, err := f.findIncomplete()
+
+	f.mutex.Lock()
+	if err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+	} else {
+		remaining := 0
+		for _, mangaID := range pending {
+			if !job.Done[mangaID] {
+				remaining++
+			}
+		}
+		job.Total = job.Processed + remaining
+	}
+	f.mutex.Unlock()
+
+	var lastCall time.Time
+	for _, mangaID := range pending {
+		if err != nil {
+			break
+		}
+
+		f.mutex.Lock()
+		done := job.Done[mangaID]
+		f.mutex.Unlock()
+		if done {
+			continue
+		}
+
+		// Throttle entre chamadas ao provedor
+		if wait := f.config.Throttle - time.Since(lastCall); wait > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(wait):
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		lastCall = time.Now()
+
+		outcome, review, fillErr := f.processManga(ctx, mangaID)
+		if ctx.Err() != nil {
+			break // cancelado no meio da busca: não marcar como processado
+		}
+
+		f.mutex.Lock()
+		switch {
+		case fillErr != nil:
+			outcome = "failed"
+			job.Failed[mangaID] = fillErr.Error()
+		case review != nil:
+			job.Review = append(job.Review, *review)
+		default:
+			job.Applied = append(job.Applied, mangaID)
+		}
+		job.Done[mangaID] = true
+		job.Processed++
+		job.UpdatedAt = time.Now()
+		progress := Progress{
+			JobID:     job.ID,
+			MangaID:   mangaID,
+			Outcome:   outcome,
+			Processed: job.Processed,
+			Total:     job.Total,
+			Applied:   len(job.Applied),
+			Review:    len(job.Review),
+		}
+		f.saveState()
+		f.mutex.Unlock()
+
+		if onProgress != nil {
+			onProgress(progress)
+		}
+	}
+
+	f.mutex.Lock()
+	if job.Status == StatusRunning {
+		if ctx.Err() != nil {
+			job.Status = StatusCanceled
+		} else {
+			job.Status = StatusCompleted
+		}
+	}
+	job.UpdatedAt = time.Now()
+	f.saveState()
+	f.cancel = nil
+	result := f.snapshot()
+	f.mutex.Unlock()
+
+	if onDone != nil {
+		onDone(result)
+	}
+}
+
+// findIncomplete lista as obras cujo JSON não tem autor, capa ou descrição
+func (f *Filler) findIncomplete() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(f.config.JSONDir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar JSONs: %w", err)
+	}
+	sort.Strings(files)
+
+	var pending []string
+	for _, file := range files {
+		data, err := readMangaJSON(file)
+		if err != nil {
+			continue
+		}
+		mangaID := strings.TrimSuffix(filepath.Base(file), ".json")
+		for _, field := range requiredFields {
+			if f.config.Locks.IsLocked(mangaID, field) {
+				continue
+			}
+			if value, _ := data[field].(string); strings.TrimSpace(value) == "" {
+				pending = append(pending, mangaID)
+				break
+			}
+		}
+	}
+
+	return pending, nil
+}
+
+// processManga busca a obra na AniList e aplica a correspondência se a confiança for alta
+func (f *Filler) processManga(ctx context.Context, mangaID string) (string, *ReviewItem, error) {
+	jsonPath := mangaid.Path(f.config.JSONDir, mangaID)
+	data, err := readMangaJSON(jsonPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	title, _ := data["title"].(string)
+	if strings.TrimSpace(title) == "" {
+		title = mangaID
+	}
+
+	// Override do usuário vem primeiro: ID fixo ou título canônico para a busca
+	anilistID := 0
+	query := title
+	if override, found := f.config.Overrides.Lookup(mangaID); found {
+		anilistID = override.AniListID
+		if override.Title != "" {
+			query = override.Title
+		}
+	}
+
+	// Obra já vinculada no registro: usar o ID diretamente
+	if entry, found := f.registry.Get(mangaID); found && anilistID == 0 {
+		anilistID = entry.AniListID
+	}
+
+	if anilistID == 0 {
+		result, err := f.service.SearchMangaWithRetry(ctx, query, 1, 5)
+		if err != nil {
+			return "", nil, err
+		}
+
+		candidates := rankCandidates(query, result.Results)
+		if len(candidates) == 0 {
+			return "review", &ReviewItem{MangaID: mangaID, Title: title, Reason: "no_results"}, nil
+		}
+
+		best := candidates[0]
+		ambiguous := len(candidates) > 1 && candidates[1].Score > best.Score-0.1
+		if best.Score < f.config.MinConfidence || ambiguous {
+			reason := "low_confidence"
+			if ambiguous {
+				reason = "ambiguous"
+			}
+			return "review", &ReviewItem{MangaID: mangaID, Title: title, Reason: reason, Candidates: candidates}, nil
+		}
+		anilistID = best.AniListID
+	}
+
+	details, err := f.service.GetMangaDetailsWithRetry(ctx, anilistID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	meta := f.config.Locks.Filter(mangaID, anilist.MapAniListToMangaMetadata(details.Media))
+	if err := f.applyLocked(jsonPath, meta); err != nil {
+		return "", nil, err
+	}
+	if err := f.registry.Link(mangaID, title, anilistID); err != nil {
+		return "", nil, err
+	}
+
+	return "applied", nil, nil
+}
+
+// saveState persiste o job atual (chamador deve ter o lock)
+func (f *Filler) saveState() {
+	if f.config.StatePath == "" || f.job == nil {
+		return
+	}
+
+	data, err := json.MarshalIndent(f.job, "", "  ")
+	if err != nil {
+		return
+	}
+	os.MkdirAll(filepath.Dir(f.config.StatePath), 0755)
+	os.WriteFile(f.config.StatePath, data, 0644)
+}
+
+// rankCandidates ordena os resultados pela similaridade com o título local
+func rankCandidates(title string, results []anilist.MangaBasic) []Candidate {
+	normalized := registry.NormalizeTitle(title)
+
+	var candidates []Candidate
+	for _, manga := range results {
+		titles := append([]string{}, manga.Synonyms...)
+		for _, t := range []*string{manga.Title.English, manga.Title.Romaji, manga.Title.Native} {
+			if t != nil {
+				titles = append(titles, *t)
+			}
+		}
+
+		best := 0.0
+		for _, t := range titles {
+			if score := similarity(normalized, registry.NormalizeTitle(t)); score > best {
+				best = score
+			}
+		}
+
+		candidates = append(candidates, Candidate{
+			AniListID: manga.ID,
+			Title:     anilist.MapAniListBasicToMangaMetadata(manga).Title,
+			Score:     best,
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	return candidates
+}
+
+// similarity compara títulos normalizados (1.0 = idênticos, Jaccard de palavras caso contrário)
+func similarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1.0
+	}
+
+	wordsB := make(map[string]bool)
+	for _, word := range strings.Fields(b) {
+		wordsB[word] = true
+	}
+
+	common, union := 0, len(wordsB)
+	for _, word := range strings.Fields(a) {
+		if wordsB[word] {
+			common++
+		} else {
+			union++
+		}
+	}
+	return float64(common) / float64(union)
+}
+
+// readMangaJSON lê o JSON de uma obra como mapa genérico
+func readMangaJSON(path string) (map[string]interface{}, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("JSON inválido em %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// applyLocked aplica os metadados segurando a trava compartilhada com os outros
+// escritores do JSON, para que a leitura e a escrita não intercalem com eles
+func (f *Filler) applyLocked(path string, meta metadata.MangaMetadata) error {
+	if f.config.Lock != nil {
+		f.config.Lock.Lock()
+		defer f.config.Lock.Unlock()
+	}
+	return applyMetadata(path, meta)
+}
+
+// applyMetadata preenche apenas campos vazios do JSON. Os valores são substituídos
+// no texto original para preservar a ordem dos campos (como em save_metadata);
+// se algum campo não existir no arquivo, o JSON é regenerado.
+func applyMetadata(path string, meta metadata.MangaMetadata) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	values := map[string]string{
+		"description": meta.Description,
+		"author":      meta.Author,
+		"artist":      meta.Artist,
+		"cover":       meta.Cover,
+		"status":      meta.Status,
+	}
+
+	text := string(raw)
+	inPlace := true
+	changed := false
+	for _, field := range fillableFields {
+		newValue := values[field]
+		if current, _ := data[field].(string); strings.TrimSpace(current) != "" || newValue == "" {
+			continue
+		}
+		data[field] = newValue
+		changed = true
+
+		encoded, _ := json.Marshal(newValue)
+		pattern := regexp.MustCompile(fmt.Sprintf(`("%s"\s*:\s*)("(?:[^"\\]|\\.)*"|null)`, field))
+		loc := pattern.FindStringSubmatchIndex(text)
+		if loc == nil {
+			inPlace = false
+			continue
+		}
+		text = text[:loc[4]] + string(encoded) + text[loc[5]:]
+	}
+	if !changed {
+		return nil
+	}
+
+	var check map[string]interface{}
+	if !inPlace || json.Unmarshal([]byte(text), &check) != nil {
+		output, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		text = string(output)
+	}
+
+	return os.WriteFile(path, []byte(text), 0644)
+}
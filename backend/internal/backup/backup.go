@@ -0,0 +1,411 @@
+// Package backup mantém uma cópia local de tudo o que foi enviado, organizada
+// em <raiz>/<obra>/<capítulo>/<arquivo>, como caminho de recuperação que não
+// depende de nenhum host de imagens. O SHA-256 de cada cópia vai para o
+// histórico de uploads, que serve de referência para a verificação; arquivos
+// sem cópia (enviados antes do backup, importados) podem ser baixados dos hosts.
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"go-upload/backend/internal/analytics"
+)
+
+// Config é a seção "backup" da configuração
+type Config struct {
+	Enabled bool   `json:"enabled"`        // copia cada arquivo enviado com sucesso
+	Root    string `json:"root,omitempty"` // padrão: <dataDir>/backup
+}
+
+// Padrões da verificação
+const (
+	DefaultWorkers = 4
+	MaxFetchSize   = 100 << 20
+)
+
+// Store é a árvore de cópias. Os métodos aceitam Store nil (backup desligado).
+type Store struct {
+	root string
+}
+
+// New cria o backup em root
+func New(root string) *Store {
+	return &Store{root: root}
+}
+
+// Root retorna a pasta do backup
+func (s *Store) Root() string {
+	if s == nil {
+		return ""
+	}
+	return s.root
+}
+
+// Path retorna onde fica a cópia de um arquivo
+func (s *Store) Path(mangaID, chapter, fileName string) string {
+	return filepath.Join(s.root, safeName(mangaID), safeName(chapter), safeName(fileName))
+}
+
+// Save copia src para o backup e retorna o SHA-256 do conteúdo. Uma cópia
+// idêntica já existente é mantida; com o backup desligado retorna "".
+func (s *Store) Save(mangaID, chapter, fileName, src string) (string, error) {
+	if s == nil {
+		return "", nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", filepath.Base(src), err)
+	}
+	defer in.Close()
+	return s.write(s.Path(mangaID, chapter, fileName), in, "")
+}
+
+// write grava r em dest (arquivo temporário + rename) e retorna o SHA-256. Com
+// expected, um conteúdo diferente é descartado e dest não é tocado.
+func (s *Store) write(dest string, r io.Reader, expected string) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".backup-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create backup file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(r, MaxFetchSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write backup of %s: %v", filepath.Base(dest), err)
+	}
+	if size > MaxFetchSize {
+		return "", fmt.Errorf("%s is larger than %d bytes", filepath.Base(dest), MaxFetchSize)
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if expected != "" && sum != expected {
+		return sum, fmt.Errorf("content hash %s does not match the upload history (%s)", short(sum), short(expected))
+	}
+	if current, err := HashFile(dest); err == nil && current == sum {
+		return sum, nil
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return "", fmt.Errorf("failed to write backup of %s: %v", filepath.Base(dest), err)
+	}
+	return sum, nil
+}
+
+// HashFile calcula o SHA-256 de um arquivo
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Problemas encontrados pela verificação
+const (
+	ProblemMissing     = "missing"      // sem cópia local
+	ProblemMismatch    = "mismatch"     // cópia com hash diferente do histórico
+	ProblemFetchFailed = "fetch_failed" // nenhum host devolveu o conteúdo esperado
+)
+
+// Issue é um arquivo do histórico sem cópia íntegra no backup
+type Issue struct {
+	MangaID string `json:"mangaId"`
+	Chapter string `json:"chapter,omitempty"`
+	File    string `json:"file"`
+	Problem string `json:"problem"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report resume uma verificação do backup
+type Report struct {
+	Root     string  `json:"root"`
+	Files    int     `json:"files"`    // arquivos distintos no histórico
+	Verified int     `json:"verified"` // cópias com o hash do histórico
+	Unhashed int     `json:"unhashed"` // cópias presentes, mas sem hash no histórico para comparar
+	Missing  int     `json:"missing"`  // sem cópia (e não baixados)
+	Mismatch int     `json:"mismatch"` // cópias divergentes (e não corrigidas)
+	Fetched  int     `json:"fetched"`  // baixados dos hosts nesta verificação
+	Bytes    int64   `json:"bytes"`    // tamanho das cópias íntegras
+	Issues   []Issue `json:"issues,omitempty"`
+}
+
+// VerifyOptions controla a verificação
+type VerifyOptions struct {
+	MangaID string       // só esta obra ("" = todas)
+	Fetch   bool         // baixa dos hosts as cópias ausentes ou divergentes
+	Client  *http.Client // usado com Fetch (padrão http.DefaultClient)
+	Workers int          // arquivos verificados em paralelo (padrão DefaultWorkers)
+}
+
+// Progress é chamado a cada arquivo verificado, uma chamada por vez
+type Progress func(done, total int)
+
+// entry é um arquivo do histórico: o envio principal e os espelhos
+type entry struct {
+	mangaID, chapter, file string
+	hash                   string
+	urls                   []string
+}
+
+// Verify compara o backup com o histórico de uploads: cada arquivo enviado
+// deve ter uma cópia com o SHA-256 registrado. Com Fetch, cópias ausentes ou
+// divergentes são baixadas das URLs do histórico (principal e espelhos) e só
+// entram no backup se o conteúdo bater com o hash registrado, quando houver.
+func (s *Store) Verify(ctx context.Context, records []analytics.Record, options VerifyOptions, progress Progress) (*Report, error) {
+	if s == nil {
+		return nil, fmt.Errorf("backup is not configured")
+	}
+	entries := collect(records, options.MangaID)
+	report := &Report{Root: s.root, Files: len(entries)}
+
+	client := options.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	var mu sync.Mutex
+	var done int
+	jobs := make(chan *entry)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range jobs {
+				outcome := s.check(ctx, client, e, options.Fetch)
+				mu.Lock()
+				outcome.apply(report, e)
+				done++
+				if progress != nil {
+					progress(done, len(entries))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, e := range entries {
+		select {
+		case jobs <- e:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	sort.Slice(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.MangaID != b.MangaID {
+			return a.MangaID < b.MangaID
+		}
+		if a.Chapter != b.Chapter {
+			return a.Chapter < b.Chapter
+		}
+		return a.File < b.File
+	})
+	return report, nil
+}
+
+// outcome é o resultado da verificação de um arquivo
+type outcome struct {
+	status  string // "verified", "unhashed" ou um Problem*
+	fetched bool
+	size    int64
+	err     error
+}
+
+func (o outcome) apply(report *Report, e *entry) {
+	if o.fetched {
+		report.Fetched++
+	}
+	switch o.status {
+	case "verified":
+		report.Verified++
+		report.Bytes += o.size
+		return
+	case "unhashed":
+		report.Unhashed++
+		report.Bytes += o.size
+		return
+	case ProblemMissing:
+		report.Missing++
+	case ProblemMismatch:
+		report.Mismatch++
+	case ProblemFetchFailed:
+		report.Missing++
+	}
+	issue := Issue{MangaID: e.mangaID, Chapter: e.chapter, File: e.file, Problem: o.status}
+	if o.err != nil {
+		issue.Error = o.err.Error()
+	}
+	report.Issues = append(report.Issues, issue)
+}
+
+// check verifica (e, com fetch, repara) a cópia de um arquivo
+func (s *Store) check(ctx context.Context, client *http.Client, e *entry, fetch bool) outcome {
+	dest := s.Path(e.mangaID, e.chapter, e.file)
+	problem := ProblemMissing
+	if info, err := os.Stat(dest); err == nil {
+		sum, err := HashFile(dest)
+		switch {
+		case err != nil:
+			return outcome{status: ProblemMismatch, err: err}
+		case e.hash == "":
+			return outcome{status: "unhashed", size: info.Size()}
+		case sum == e.hash:
+			return outcome{status: "verified", size: info.Size()}
+		}
+		problem = ProblemMismatch
+	}
+	if !fetch || len(e.urls) == 0 {
+		return outcome{status: problem}
+	}
+
+	var lastErr error
+	for _, pageURL := range e.urls {
+		if ctx.Err() != nil {
+			return outcome{status: problem, err: ctx.Err()}
+		}
+		if _, err := s.fetch(ctx, client, pageURL, dest, e.hash); err != nil {
+			lastErr = fmt.Errorf("%s: %v", hostOf(pageURL), err)
+			continue
+		}
+		status := "verified"
+		if e.hash == "" {
+			status = "unhashed"
+		}
+		var size int64
+		if info, err := os.Stat(dest); err == nil {
+			size = info.Size()
+		}
+		return outcome{status: status, fetched: true, size: size}
+	}
+	return outcome{status: ProblemFetchFailed, err: lastErr}
+}
+
+// fetch baixa uma URL para dest, exigindo o hash esperado quando conhecido
+func (s *Store) fetch(ctx context.Context, client *http.Client, pageURL, dest, expected string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return s.write(dest, resp.Body, expected)
+}
+
+// collect agrupa o histórico por arquivo: os registros do host principal e dos
+// espelhos de uma mesma página viram uma entrada, com todas as URLs
+func collect(records []analytics.Record, mangaID string) []*entry {
+	byKey := make(map[string]*entry)
+	var entries []*entry
+	for _, record := range records {
+		if mangaID != "" && record.MangaID != mangaID {
+			continue
+		}
+		file := record.File
+		if file == "" {
+			file = urlFileName(record.URL)
+		}
+		if file == "" {
+			continue
+		}
+		key := record.MangaID + "\x00" + record.Chapter + "\x00" + file
+		e, exists := byKey[key]
+		if !exists {
+			e = &entry{mangaID: record.MangaID, chapter: record.Chapter, file: file}
+			byKey[key] = e
+			entries = append(entries, e)
+		}
+		if record.SHA256 != "" {
+			e.hash = record.SHA256 // o envio mais recente vale
+		}
+		if record.URL != "" && !contains(e.urls, record.URL) {
+			e.urls = append(e.urls, record.URL)
+		}
+	}
+	return entries
+}
+
+// urlFileName é o nome do arquivo no fim da URL (registros sem nome local)
+func urlFileName(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	name := path.Base(parsed.Path)
+	if name == "." || name == "/" {
+		return ""
+	}
+	return name
+}
+
+// safeName impede que um componente saia da pasta do backup
+func safeName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
+
+func hostOf(rawURL string) string {
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.Host != "" {
+		return parsed.Host
+	}
+	return rawURL
+}
+
+func short(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
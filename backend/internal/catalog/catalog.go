@@ -0,0 +1,206 @@
+// Package catalog monta o index.json agregado da biblioteca: uma entrada por
+// obra (título, capa, último capítulo e caminho do JSON) para leitores e
+// frontends descobrirem o catálogo sem baixar todos os JSONs.
+//
+// O índice é regenerado sob demanda e reaproveita as entradas dos JSONs que não
+// mudaram desde a última leitura, como os feeds RSS.
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/metadata"
+)
+
+// FileName é o nome do índice, ao lado dos JSONs das obras no repositório
+const FileName = "index.json"
+
+// Index é o conteúdo do index.json
+type Index struct {
+	LastUpdated string   `json:"last_updated"` // capítulo mais recente da biblioteca (Unix); não muda se nada foi publicado
+	Count       int      `json:"count"`
+	Series      []Series `json:"series"`
+}
+
+// Series é a entrada de uma obra no índice
+type Series struct {
+	ID            string         `json:"id"`
+	Title         string         `json:"title"`
+	Cover         string         `json:"cover"`
+	Status        string         `json:"status"`
+	NSFW          bool           `json:"nsfw,omitempty"`
+	ChapterCount  int            `json:"chapter_count"`
+	LatestChapter *LatestChapter `json:"latest_chapter,omitempty"`
+	LastUpdated   string         `json:"last_updated"`
+	Path          string         `json:"path"` // relativo ao index.json
+}
+
+// LatestChapter é o capítulo de maior número de uma obra
+type LatestChapter struct {
+	Number      string `json:"number"`
+	Title       string `json:"title"`
+	Volume      string `json:"volume"`
+	LastUpdated string `json:"last_updated"`
+}
+
+// cachedSeries guarda a entrada de um JSON até ele ser modificado
+type cachedSeries struct {
+	modTime time.Time
+	series  Series
+}
+
+// Generator monta o índice a partir dos JSONs de jsonDir
+type Generator struct {
+	jsonDir string
+
+	mutex sync.Mutex
+	cache map[string]*cachedSeries
+}
+
+// NewGenerator cria o gerador do índice
+func NewGenerator(jsonDir string) *Generator {
+	return &Generator{jsonDir: jsonDir, cache: make(map[string]*cachedSeries)}
+}
+
+// Build monta o índice atual. JSONs inválidos ficam de fora em vez de derrubar o catálogo.
+func (g *Generator) Build() (*Index, error) {
+	files, err := filepath.Glob(filepath.Join(g.jsonDir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list JSONs: %v", err)
+	}
+
+	index := &Index{Series: []Series{}}
+	var latest int64
+	seen := make(map[string]bool)
+	for _, file := range files {
+		if filepath.Base(file) == FileName {
+			continue
+		}
+		seen[file] = true
+		series, err := g.load(file)
+		if err != nil {
+			continue
+		}
+		index.Series = append(index.Series, series)
+		if updated, err := strconv.ParseInt(series.LastUpdated, 10, 64); err == nil && updated > latest {
+			latest = updated
+		}
+	}
+	g.prune(seen)
+
+	sort.Slice(index.Series, func(i, j int) bool {
+		return index.Series[i].ID < index.Series[j].ID
+	})
+	index.Count = len(index.Series)
+	if latest > 0 {
+		index.LastUpdated = strconv.FormatInt(latest, 10)
+	}
+	return index, nil
+}
+
+// JSON monta o índice serializado, com a data do capítulo mais recente para Last-Modified
+func (g *Generator) JSON() ([]byte, time.Time, error) {
+	index, err := g.Build()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to encode index: %v", err)
+	}
+	var updated time.Time
+	if seconds, err := strconv.ParseInt(index.LastUpdated, 10, 64); err == nil {
+		updated = time.Unix(seconds, 0).UTC()
+	}
+	return append(data, '\n'), updated, nil
+}
+
+// load retorna a entrada de um JSON, relendo-o apenas se foi modificado
+func (g *Generator) load(path string) (Series, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Series{}, err
+	}
+
+	g.mutex.Lock()
+	entry, cached := g.cache[path]
+	g.mutex.Unlock()
+	if cached && entry.modTime.Equal(info.ModTime()) {
+		return entry.series, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Series{}, err
+	}
+	var manga metadata.MangaJSON
+	if err := json.Unmarshal(raw, &manga); err != nil {
+		return Series{}, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	series := newSeries(filepath.Base(path), manga)
+	g.mutex.Lock()
+	g.cache[path] = &cachedSeries{modTime: info.ModTime(), series: series}
+	g.mutex.Unlock()
+	return series, nil
+}
+
+// prune descarta do cache os JSONs que não existem mais (removidos ou arquivados)
+func (g *Generator) prune(seen map[string]bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	for path := range g.cache {
+		if !seen[path] {
+			delete(g.cache, path)
+		}
+	}
+}
+
+// newSeries resume um JSON de obra em uma entrada do índice
+func newSeries(fileName string, manga metadata.MangaJSON) Series {
+	series := Series{
+		ID:           strings.TrimSuffix(fileName, ".json"),
+		Title:        manga.Title,
+		Cover:        manga.Cover,
+		Status:       manga.Status,
+		NSFW:         manga.NSFW,
+		ChapterCount: len(manga.Chapters),
+		Path:         fileName,
+	}
+
+	var latestNumber float64
+	var latestUpdate int64
+	for key, chapter := range manga.Chapters {
+		if updated, err := strconv.ParseInt(chapter.LastUpdated, 10, 64); err == nil && updated > latestUpdate {
+			latestUpdate = updated
+		}
+
+		// Chaves não numéricas ("extra") só valem se não houver capítulo numerado
+		number, err := strconv.ParseFloat(key, 64)
+		if err != nil {
+			number = -1
+		}
+		if series.LatestChapter != nil && (number < latestNumber || (number == latestNumber && key < series.LatestChapter.Number)) {
+			continue
+		}
+		latestNumber = number
+		series.LatestChapter = &LatestChapter{
+			Number:      key,
+			Title:       chapter.Title,
+			Volume:      chapter.Volume,
+			LastUpdated: chapter.LastUpdated,
+		}
+	}
+	if latestUpdate > 0 {
+		series.LastUpdated = strconv.FormatInt(latestUpdate, 10)
+	}
+	return series
+}
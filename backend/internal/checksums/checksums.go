@@ -0,0 +1,277 @@
+// Package checksums monta o checksums.json de cada capítulo (arquivo →
+// SHA-256 → URL hospedada) a partir do histórico de uploads, para que quem
+// consome o conteúdo possa conferir as páginas hospedadas contra os originais.
+// O manifesto pode ser gravado na pasta do capítulo na biblioteca e/ou
+// enviado ao GitHub junto com os JSONs das obras.
+package checksums
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/analytics"
+	"go-upload/backend/internal/metadata"
+)
+
+// FileName é o nome do manifesto gravado na pasta de cada capítulo
+const FileName = "checksums.json"
+
+// GitHubFolder é a pasta dos manifestos no repositório, ao lado dos JSONs
+const GitHubFolder = "checksums"
+
+// Algorithm é o hash usado nos manifestos
+const Algorithm = "sha256"
+
+// Config escolhe onde os manifestos são gravados
+type Config struct {
+	Library bool `json:"library,omitempty"` // checksums.json na pasta de cada capítulo da biblioteca
+	GitHub  bool `json:"github,omitempty"`  // checksums/<obra>/<capítulo>.json no repositório dos JSONs
+}
+
+// Enabled informa se algum destino está ligado (os uploads passam a ser hasheados)
+func (c *Config) Enabled() bool {
+	return c != nil && (c.Library || c.GitHub)
+}
+
+// File é uma página do manifesto. SHA256 é o hash do conteúdo enviado (após
+// remoção de metadados/otimização), o mesmo que o host serve.
+type File struct {
+	File    string            `json:"file"`
+	SHA256  string            `json:"sha256"`
+	Size    int64             `json:"size,omitempty"`
+	URL     string            `json:"url"`
+	Mirrors map[string]string `json:"mirrors,omitempty"` // host espelho -> URL
+}
+
+// Manifest é o checksums.json de um capítulo
+type Manifest struct {
+	MangaID   string    `json:"mangaId"`
+	Chapter   string    `json:"chapter"`
+	Algorithm string    `json:"algorithm"`
+	UpdatedAt time.Time `json:"updatedAt"` // upload mais recente do capítulo
+	Files     []File    `json:"files"`
+}
+
+// Build monta o manifesto do capítulo chapter (chave do JSON) a partir do
+// histórico. Reenvios de um arquivo substituem os anteriores; uploads sem hash
+// (feitos com os manifestos desligados) ficam de fora. Retorna nil se nenhuma
+// página tiver hash.
+func Build(mangaID, chapter string, records []analytics.Record) *Manifest {
+	files := make(map[string]*File)
+	var updatedAt time.Time
+	for _, record := range records {
+		if record.MangaID != mangaID || record.File == "" || record.SHA256 == "" || metadata.ChapterKey(record.Chapter) != chapter {
+			continue
+		}
+		if record.Time.After(updatedAt) {
+			updatedAt = record.Time
+		}
+		_, mirrorHost, mirror := metadata.ParseMirrorGroup(record.Group)
+		file, exists := files[record.File]
+		if !exists || (!mirror && file.SHA256 != record.SHA256) {
+			// Primeiro registro do arquivo ou conteúdo novo: os espelhos antigos não valem mais
+			file = &File{File: record.File, SHA256: record.SHA256}
+			files[record.File] = file
+		}
+		if mirror {
+			if file.SHA256 == record.SHA256 {
+				if file.Mirrors == nil {
+					file.Mirrors = make(map[string]string)
+				}
+				file.Mirrors[mirrorHost] = record.URL
+			}
+			continue
+		}
+		file.URL = record.URL
+		file.Size = record.Bytes
+	}
+
+	manifest := &Manifest{MangaID: mangaID, Chapter: chapter, Algorithm: Algorithm, UpdatedAt: updatedAt, Files: []File{}}
+	for _, file := range files {
+		if file.URL != "" {
+			manifest.Files = append(manifest.Files, *file)
+		}
+	}
+	if len(manifest.Files) == 0 {
+		return nil
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].File < manifest.Files[j].File })
+	return manifest
+}
+
+// Chapter é um capítulo enviado cujo manifesto deve ser gravado na biblioteca
+type Chapter struct {
+	MangaID string
+	Chapter string // ID do capítulo no upload
+	Dir     string // pasta do capítulo na biblioteca
+}
+
+// Writer grava os manifestos dos capítulos enviados por cada lote quando o
+// lote termina. Um Writer nil não faz nada.
+type Writer struct {
+	config  Config
+	history *analytics.History
+
+	mutex   sync.Mutex
+	pending map[string]map[string]Chapter // lote -> pasta -> capítulo
+}
+
+// NewWriter cria o gravador de manifestos; nil se os manifestos estão desligados
+func NewWriter(config *Config, history *analytics.History) *Writer {
+	if !config.Enabled() {
+		return nil
+	}
+	return &Writer{config: *config, history: history, pending: make(map[string]map[string]Chapter)}
+}
+
+// Track anota um capítulo enviado pelo lote batchID. Capítulos fora da
+// biblioteca (Dir vazio ou inexistente) não têm onde receber o manifesto.
+func (w *Writer) Track(batchID string, chapter Chapter) {
+	if w == nil || !w.config.Library || chapter.Dir == "" {
+		return
+	}
+	if info, err := os.Stat(chapter.Dir); err != nil || !info.IsDir() {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.pending[batchID] == nil {
+		w.pending[batchID] = make(map[string]Chapter)
+	}
+	w.pending[batchID][chapter.Dir] = chapter
+}
+
+// Flush grava os manifestos dos capítulos anotados para o lote e retorna as
+// pastas atualizadas
+func (w *Writer) Flush(batchID string) ([]string, error) {
+	if w == nil {
+		return nil, nil
+	}
+	w.mutex.Lock()
+	chapters := w.pending[batchID]
+	delete(w.pending, batchID)
+	w.mutex.Unlock()
+	if len(chapters) == 0 {
+		return nil, nil
+	}
+
+	list := make([]Chapter, 0, len(chapters))
+	for _, chapter := range chapters {
+		list = append(list, chapter)
+	}
+	return w.Write(list...)
+}
+
+// Write grava o checksums.json de cada capítulo na sua pasta da biblioteca e
+// retorna as pastas atualizadas
+func (w *Writer) Write(chapters ...Chapter) ([]string, error) {
+	if w == nil || !w.config.Library || len(chapters) == 0 {
+		return nil, nil
+	}
+	records, err := w.history.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload history: %v", err)
+	}
+
+	var written []string
+	var firstErr error
+	for _, chapter := range chapters {
+		manifest := Build(chapter.MangaID, metadata.ChapterKey(chapter.Chapter), records)
+		if manifest == nil {
+			continue
+		}
+		if err := writeManifest(filepath.Join(chapter.Dir, FileName), manifest); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		written = append(written, chapter.Dir)
+	}
+	sort.Strings(written)
+	return written, firstErr
+}
+
+// GitHubFiles monta os manifestos das obras mangaIDs para o repositório
+// (checksums/<obra>/<capítulo>.json -> conteúdo). Capítulos ainda não
+// publicados ou recusados por publishable ficam de fora.
+func (w *Writer) GitHubFiles(mangaIDs []string, publishable func(mangaID, chapter string) bool) (map[string]string, error) {
+	if w == nil || !w.config.GitHub || len(mangaIDs) == 0 {
+		return nil, nil
+	}
+	records, err := w.history.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload history: %v", err)
+	}
+
+	wanted := make(map[string]bool, len(mangaIDs))
+	for _, mangaID := range mangaIDs {
+		wanted[mangaID] = true
+	}
+	chapters := make(map[[2]string]bool)
+	unpublished := make(map[[2]string]bool)
+	for _, record := range records {
+		if !wanted[record.MangaID] || record.Chapter == "" {
+			continue
+		}
+		key := [2]string{record.MangaID, metadata.ChapterKey(record.Chapter)}
+		chapters[key] = true
+		if record.Unpublished {
+			unpublished[key] = true
+		}
+	}
+
+	files := make(map[string]string)
+	for key := range chapters {
+		if unpublished[key] || (publishable != nil && !publishable(key[0], key[1])) {
+			continue
+		}
+		manifest := Build(key[0], key[1], records)
+		if manifest == nil {
+			continue
+		}
+		data, err := encode(manifest)
+		if err != nil {
+			return nil, err
+		}
+		files[GitHubFolder+"/"+key[0]+"/"+key[1]+".json"] = string(data)
+	}
+	return files, nil
+}
+
+// encode serializa o manifesto; o mesmo histórico gera sempre o mesmo conteúdo,
+// então capítulos sem uploads novos não geram commits
+func encode(manifest *Manifest) ([]byte, error) {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode checksums: %v", err)
+	}
+	return data, nil
+}
+
+// writeManifest grava o manifesto de forma atômica; um manifesto igual ao
+// existente não é regravado
+func writeManifest(path string, manifest *Manifest) error {
+	data, err := encode(manifest)
+	if err != nil {
+		return err
+	}
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return nil
+}
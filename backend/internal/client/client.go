@@ -0,0 +1,224 @@
+// Package client é um cliente Go do protocolo WebSocket do servidor: envia
+// ações com requestId, devolve a resposta correspondente e entrega os eventos
+// difundidos (progresso, resultados, mudanças de host) em um canal. Usado por
+// ferramentas de linha de comando como o "top".
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultURL é o endereço do servidor local
+const DefaultURL = "ws://localhost:8080/ws"
+
+// EventBuffer é quantos eventos esperam a leitura antes de serem descartados
+const EventBuffer = 256
+
+// Response é uma resposta ou evento do servidor, com Data ainda em JSON
+type Response struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	ErrorCode string          `json:"errorCode,omitempty"`
+	RequestID string          `json:"requestId,omitempty"`
+	File      string          `json:"file,omitempty"`
+	URL       string          `json:"url,omitempty"`
+}
+
+// Decode converte Data para v
+func (r *Response) Decode(v interface{}) error {
+	if len(r.Data) == 0 {
+		return fmt.Errorf("response %q has no data", r.Status)
+	}
+	return json.Unmarshal(r.Data, v)
+}
+
+// RemoteError é uma resposta "error" do servidor
+type RemoteError struct {
+	Code    string
+	Message string
+}
+
+func (e *RemoteError) Error() string {
+	if e.Code == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, e.Code)
+}
+
+// Client é uma conexão com o servidor
+type Client struct {
+	conn    *websocket.Conn
+	events  chan Response
+	pending map[string]chan Response
+	nextID  int64
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	done    chan struct{}
+	err     error // motivo do fim da conexão (protegido por mu)
+}
+
+// Dial conecta ao servidor. rawURL aceita ws(s):// ou http(s):// e, sem
+// caminho, usa /ws.
+func Dial(ctx context.Context, rawURL string) (*Client, error) {
+	endpoint, err := normalizeURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, endpoint, http.Header{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %v", endpoint, err)
+	}
+
+	c := &Client{
+		conn:    conn,
+		events:  make(chan Response, EventBuffer),
+		pending: make(map[string]chan Response),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+// normalizeURL completa o endereço do servidor
+func normalizeURL(rawURL string) (string, error) {
+	if rawURL == "" {
+		rawURL = DefaultURL
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "ws://" + rawURL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("invalid server URL: %q", rawURL)
+	}
+	switch parsed.Scheme {
+	case "http":
+		parsed.Scheme = "ws"
+	case "https":
+		parsed.Scheme = "wss"
+	case "ws", "wss":
+	default:
+		return "", fmt.Errorf("unsupported server URL scheme %q", parsed.Scheme)
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		parsed.Path = "/ws"
+	}
+	return parsed.String(), nil
+}
+
+// Request envia uma ação e espera a primeira resposta com o mesmo requestId.
+// O requestId também vai em data, onde parte dos handlers o lê. Uma resposta
+// "error" vira *RemoteError.
+func (c *Client) Request(ctx context.Context, action string, data map[string]interface{}) (*Response, error) {
+	id := fmt.Sprintf("cli-%d", atomic.AddInt64(&c.nextID, 1))
+	reply := make(chan Response, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		err := c.err
+		c.mu.Unlock()
+		return nil, err
+	}
+	c.pending[id] = reply
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	payload := map[string]interface{}{"requestId": id}
+	for key, value := range data {
+		payload[key] = value
+	}
+	message := map[string]interface{}{"action": action, "requestId": id, "data": payload}
+	c.writeMu.Lock()
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	err := c.conn.WriteJSON(message)
+	c.writeMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to send %s: %v", action, err)
+	}
+
+	select {
+	case response := <-reply:
+		if response.Status == "error" {
+			return &response, &RemoteError{Code: response.ErrorCode, Message: response.Error}
+		}
+		return &response, nil
+	case <-c.done:
+		return nil, c.Err()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Events entrega as mensagens sem requestId conhecido (difusões do servidor).
+// Eventos não lidos a tempo são descartados para não travar as respostas.
+func (c *Client) Events() <-chan Response {
+	return c.events
+}
+
+// Done é fechado quando a conexão termina
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err retorna o motivo do fim da conexão (nil enquanto ativa)
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close encerra a conexão
+func (c *Client) Close() error {
+	c.writeMu.Lock()
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	c.writeMu.Unlock()
+	return c.conn.Close()
+}
+
+func (c *Client) readLoop() {
+	defer close(c.events)
+	for {
+		var response Response
+		if err := c.conn.ReadJSON(&response); err != nil {
+			c.mu.Lock()
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				c.err = fmt.Errorf("connection closed")
+			} else {
+				c.err = fmt.Errorf("connection lost: %v", err)
+			}
+			c.mu.Unlock()
+			close(c.done)
+			return
+		}
+
+		c.mu.Lock()
+		reply, waiting := c.pending[response.RequestID]
+		if waiting {
+			delete(c.pending, response.RequestID)
+		}
+		c.mu.Unlock()
+		if waiting {
+			reply <- response
+			continue
+		}
+
+		select {
+		case c.events <- response:
+		default:
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Package clock abstrai o tempo dos subsistemas periódicos (métricas, polling
+// da geração de JSON, reposição dos rate limiters) para que testes usem um
+// relógio manual (Fake) e o modo de depuração --fast-timers acelere os timers.
+package clock
+
+import (
+	"time"
+)
+
+// Clock fornece hora atual, tickers e esperas
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	NewTicker(d time.Duration) Ticker
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+// Ticker é o equivalente de time.Ticker para um Clock
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real é o relógio do sistema
+func Real() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time { return t.ticker.C }
+func (t realTicker) Stop()               { t.ticker.Stop() }
+
+// MinScaledInterval é o menor intervalo de um relógio acelerado, para que
+// intervalos curtos não virem laços ocupados
+const MinScaledInterval = 10 * time.Millisecond
+
+// Scaled é o relógio do sistema com intervalos multiplicados por factor
+// (0.1 = timers 10x mais rápidos). A hora atual continua a real.
+func Scaled(factor float64) Clock {
+	if factor <= 0 || factor == 1 {
+		return Real()
+	}
+	return scaledClock{factor: factor}
+}
+
+type scaledClock struct {
+	realClock
+	factor float64
+}
+
+func (c scaledClock) scale(d time.Duration) time.Duration {
+	scaled := time.Duration(float64(d) * c.factor)
+	if scaled < MinScaledInterval {
+		scaled = MinScaledInterval
+	}
+	return scaled
+}
+
+func (c scaledClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(c.scale(d))}
+}
+
+func (c scaledClock) After(d time.Duration) <-chan time.Time {
+	return time.After(c.scale(d))
+}
+
+func (c scaledClock) Sleep(d time.Duration) {
+	time.Sleep(c.scale(d))
+}
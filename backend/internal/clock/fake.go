@@ -0,0 +1,138 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake é um relógio manual: o tempo só anda com Advance, e tickers, After e
+// Sleep disparam na ordem dos seus prazos. Para testes determinísticos.
+type Fake struct {
+	now     time.Time
+	waiters []*fakeWaiter
+	changed chan struct{} // fechado e recriado a cada novo waiter
+	mu      sync.Mutex
+}
+
+type fakeWaiter struct {
+	at     time.Time
+	period time.Duration // > 0 para tickers
+	ch     chan time.Time
+	fake   *Fake
+}
+
+// NewFake cria um relógio parado em start
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start, changed: make(chan struct{})}
+}
+
+// Now retorna a hora do relógio
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since retorna o tempo do relógio decorrido desde t
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// NewTicker cria um ticker que dispara a cada d de tempo do relógio
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return f.addWaiter(d, d)
+}
+
+// After dispara uma vez depois de d de tempo do relógio
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.addWaiter(d, 0).ch
+}
+
+// Sleep bloqueia até Advance passar d
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// Advance anda o relógio d e dispara, em ordem, tudo que vence até lá. Os
+// tickers não acumulam disparos: um ticker atrasado perde os intermediários,
+// como time.Ticker.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	target := f.now.Add(d)
+	for {
+		sort.Slice(f.waiters, func(i, j int) bool { return f.waiters[i].at.Before(f.waiters[j].at) })
+		if len(f.waiters) == 0 || f.waiters[0].at.After(target) {
+			break
+		}
+		waiter := f.waiters[0]
+		f.now = waiter.at
+		select {
+		case waiter.ch <- f.now:
+		default:
+		}
+		if waiter.period > 0 {
+			waiter.at = waiter.at.Add(waiter.period)
+		} else {
+			f.waiters = f.waiters[1:]
+		}
+	}
+	f.now = target
+	f.mu.Unlock()
+}
+
+// Waiters retorna quantos tickers e esperas estão pendentes
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil espera até haver pelo menos n tickers ou esperas pendentes, para
+// que o teste avance o relógio só depois de a goroutine testada estar esperando
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		if len(f.waiters) >= n {
+			f.mu.Unlock()
+			return
+		}
+		changed := f.changed
+		f.mu.Unlock()
+		<-changed
+	}
+}
+
+func (f *Fake) addWaiter(d, period time.Duration) *fakeWaiter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	waiter := &fakeWaiter{
+		at:     f.now.Add(d),
+		period: period,
+		ch:     make(chan time.Time, 1),
+		fake:   f,
+	}
+	f.waiters = append(f.waiters, waiter)
+	close(f.changed)
+	f.changed = make(chan struct{})
+	return waiter
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.ch
+}
+
+func (w *fakeWaiter) Stop() {
+	f := w.fake
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, waiter := range f.waiters {
+		if waiter == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			break
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package collection
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ResourceBudget limita os recursos que uma coleção pode consumir
+type ResourceBudget struct {
+	MaxWorkers      int   `json:"maxWorkers,omitempty"`      // Uploads simultâneos da coleção (0 = sem limite)
+	MaxBandwidthBPS int64 `json:"maxBandwidthBps,omitempty"` // Bytes por segundo enviados pela coleção (0 = sem limite)
+	MaxSpoolBytes   int64 `json:"maxSpoolBytes,omitempty"`   // Bytes de arquivos em trânsito ao mesmo tempo (0 = sem limite)
+}
+
+// budgetEnforcer aplica um ResourceBudget aos uploads de uma coleção
+type budgetEnforcer struct {
+	budget ResourceBudget
+
+	workers chan struct{}
+
+	spoolUsed int64
+	spoolCond *sync.Cond
+	spoolMu   sync.Mutex
+
+	nextSlot  time.Time
+	bandwidth sync.Mutex
+}
+
+// newBudgetEnforcer cria o controlador de orçamento; retorna nil quando não há limites
+func newBudgetEnforcer(budget *ResourceBudget) *budgetEnforcer {
+	if budget == nil || (budget.MaxWorkers <= 0 && budget.MaxBandwidthBPS <= 0 && budget.MaxSpoolBytes <= 0) {
+		return nil
+	}
+
+	be := &budgetEnforcer{budget: *budget}
+	if budget.MaxWorkers > 0 {
+		be.workers = make(chan struct{}, budget.MaxWorkers)
+	}
+	be.spoolCond = sync.NewCond(&be.spoolMu)
+
+	return be
+}
+
+// acquire reserva um worker, espaço de spool e banda para um arquivo de tamanho size.
+// É chamado antes de o arquivo entrar no worker pool; a função retornada libera os
+// recursos quando o arquivo termina e deve sempre ser chamada.
+func (be *budgetEnforcer) acquire(ctx context.Context, size int64) (func(), error) {
+	if be == nil {
+		return func() {}, nil
+	}
+
+	if be.workers != nil {
+		select {
+		case be.workers <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	releaseWorker := func() {
+		if be.workers != nil {
+			<-be.workers
+		}
+	}
+
+	reserved, err := be.reserveSpool(ctx, size)
+	if err != nil {
+		releaseWorker()
+		return nil, err
+	}
+
+	if err := be.waitBandwidth(ctx, size); err != nil {
+		be.releaseSpool(reserved)
+		releaseWorker()
+		return nil, err
+	}
+
+	return func() {
+		be.releaseSpool(reserved)
+		releaseWorker()
+	}, nil
+}
+
+// reserveSpool aguarda até haver espaço de spool para o arquivo.
+// Arquivos maiores que o limite são aceitos sozinhos para não travar a coleção.
+func (be *budgetEnforcer) reserveSpool(ctx context.Context, size int64) (int64, error) {
+	if be.budget.MaxSpoolBytes <= 0 || size <= 0 {
+		return 0, nil
+	}
+
+	// Acorda os que aguardam quando o contexto é cancelado
+	stop := context.AfterFunc(ctx, func() {
+		be.spoolMu.Lock()
+		be.spoolCond.Broadcast()
+		be.spoolMu.Unlock()
+	})
+	defer stop()
+
+	be.spoolMu.Lock()
+	defer be.spoolMu.Unlock()
+
+	for be.spoolUsed > 0 && be.spoolUsed+size > be.budget.MaxSpoolBytes {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		be.spoolCond.Wait()
+	}
+
+	be.spoolUsed += size
+	return size, nil
+}
+
+// releaseSpool devolve espaço de spool reservado
+func (be *budgetEnforcer) releaseSpool(size int64) {
+	if size == 0 {
+		return
+	}
+
+	be.spoolMu.Lock()
+	be.spoolUsed -= size
+	be.spoolCond.Broadcast()
+	be.spoolMu.Unlock()
+}
+
+// waitBandwidth espaça os uploads para que a média não ultrapasse MaxBandwidthBPS
+func (be *budgetEnforcer) waitBandwidth(ctx context.Context, size int64) error {
+	if be.budget.MaxBandwidthBPS <= 0 || size <= 0 {
+		return nil
+	}
+
+	cost := time.Duration(float64(size) / float64(be.budget.MaxBandwidthBPS) * float64(time.Second))
+
+	be.bandwidth.Lock()
+	now := time.Now()
+	if be.nextSlot.Before(now) {
+		be.nextSlot = now
+	}
+	start := be.nextSlot
+	be.nextSlot = be.nextSlot.Add(cost)
+	be.bandwidth.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stats retorna o uso atual do orçamento
+func (be *budgetEnforcer) stats() map[string]interface{} {
+	if be == nil {
+		return nil
+	}
+
+	be.spoolMu.Lock()
+	spoolUsed := be.spoolUsed
+	be.spoolMu.Unlock()
+
+	activeWorkers := 0
+	if be.workers != nil {
+		activeWorkers = len(be.workers)
+	}
+
+	return map[string]interface{}{
+		"budget":         be.budget,
+		"active_workers": activeWorkers,
+		"spool_used":     spoolUsed,
+	}
+}
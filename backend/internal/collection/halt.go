@@ -0,0 +1,82 @@
+package collection
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrProcessorHalted é retornado quando uma parada de emergência interrompe o processamento
+var ErrProcessorHalted = errors.New("collection processor halted by emergency stop")
+
+// haltContext retorna o contexto cancelado pela parada de emergência atual
+func (cp *CollectionProcessor) haltContext() context.Context {
+	cp.haltMu.RLock()
+	defer cp.haltMu.RUnlock()
+
+	if cp.haltCtx == nil {
+		return cp.ctx
+	}
+	return cp.haltCtx
+}
+
+// IsHalted informa se o processador está parado por emergência
+func (cp *CollectionProcessor) IsHalted() bool {
+	return cp.haltContext().Err() != nil
+}
+
+// Halt interrompe imediatamente todas as coleções em execução ou na fila.
+// Nenhum novo arquivo é enviado; o progresso já feito é salvo e os jobs ficam
+// como pausados para poderem ser retomados depois. Retorna os IDs afetados.
+func (cp *CollectionProcessor) Halt() []string {
+	cp.haltMu.Lock()
+	if cp.haltCancel != nil {
+		cp.haltCancel()
+	}
+	cp.haltMu.Unlock()
+
+	cp.mutex.RLock()
+	jobs := make([]*CollectionJob, 0, len(cp.collections))
+	for _, job := range cp.collections {
+		jobs = append(jobs, job)
+	}
+	cp.mutex.RUnlock()
+
+	halted := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		job.mutex.Lock()
+		active := job.Status == StatusPending || job.Status == StatusRunning
+		if active {
+			job.Status = StatusPaused
+		}
+		job.mutex.Unlock()
+
+		if !active {
+			continue
+		}
+		halted = append(halted, job.ID)
+
+		// Jobs ainda na fila são retirados; os em execução param sozinhos
+		if cp.queue.Remove(job.ID) {
+			if cp.config.EnablePersistence {
+				cp.saveJobState(job)
+			}
+			job.journal.close()
+			if job.OnComplete != nil {
+				go job.OnComplete(ErrProcessorHalted)
+			}
+		}
+	}
+
+	return halted
+}
+
+// Unhalt libera o processador para aceitar novos uploads após uma parada de emergência
+func (cp *CollectionProcessor) Unhalt() {
+	cp.haltMu.Lock()
+	defer cp.haltMu.Unlock()
+
+	if cp.haltCtx != nil && cp.haltCtx.Err() == nil {
+		return
+	}
+	cp.haltCtx, cp.haltCancel = context.WithCancel(cp.ctx)
+}
@@ -0,0 +1,194 @@
+package collection
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Eventos registrados no journal de arquivos
+const (
+	JournalStart    = "start"
+	JournalComplete = "complete"
+	JournalFail     = "fail"
+)
+
+// JournalEntry representa uma linha do journal de arquivos
+type JournalEntry struct {
+	Event string    `json:"event"`
+	Path  string    `json:"path"`
+	URL   string    `json:"url,omitempty"`
+	Error string    `json:"error,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// fileJournal é um write-ahead log append-only dos uploads de uma coleção.
+// Cada início e conclusão de arquivo é gravado e sincronizado em disco antes de
+// seguir, para que após um crash seja possível saber quais arquivos já têm URL.
+type fileJournal struct {
+	path string
+	file *os.File
+	mu   sync.Mutex
+
+	// Último evento de cada arquivo encontrado ao abrir o journal
+	recovered map[string]JournalEntry
+}
+
+// journalPath retorna o caminho do journal de um job
+func (cp *CollectionProcessor) journalPath(jobID string) string {
+	return fmt.Sprintf("%s_%s.journal", cp.config.StateFilePath, jobID)
+}
+
+// openFileJournal abre (ou cria) o journal e carrega o estado gravado anteriormente
+func openFileJournal(path string) (*fileJournal, error) {
+	recovered, err := readJournal(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %v", err)
+	}
+
+	return &fileJournal{
+		path:      path,
+		file:      file,
+		recovered: recovered,
+	}, nil
+}
+
+// readJournal lê o journal e retorna o último evento de cada arquivo.
+// Uma linha final truncada (crash durante a escrita) é ignorada.
+func readJournal(path string) (map[string]JournalEntry, error) {
+	recovered := make(map[string]JournalEntry)
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return recovered, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Path == "" {
+			continue
+		}
+		recovered[entry.Path] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %v", err)
+	}
+
+	return recovered, nil
+}
+
+// record grava um evento e força a escrita em disco
+func (j *fileJournal) record(event, path, url, errMsg string) {
+	if j == nil {
+		return
+	}
+
+	data, err := json.Marshal(JournalEntry{
+		Event: event,
+		Path:  path,
+		URL:   url,
+		Error: errMsg,
+		Time:  time.Now(),
+	})
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return
+	}
+	if _, err := j.file.Write(data); err != nil {
+		fmt.Printf("Failed to write journal %s: %v\n", j.path, err)
+		return
+	}
+	j.file.Sync()
+}
+
+// completedURL retorna a URL de um arquivo que o journal registra como concluído
+func (j *fileJournal) completedURL(path string) (string, bool) {
+	if j == nil {
+		return "", false
+	}
+
+	entry, ok := j.recovered[path]
+	if !ok || entry.Event != JournalComplete || entry.URL == "" {
+		return "", false
+	}
+	return entry.URL, true
+}
+
+// summary descreve o que foi recuperado do journal ao abrir
+func (j *fileJournal) summary() map[string]interface{} {
+	if j == nil {
+		return nil
+	}
+
+	completed := 0
+	failed := 0
+	unknown := make([]string, 0)
+	for path, entry := range j.recovered {
+		switch entry.Event {
+		case JournalComplete:
+			completed++
+		case JournalFail:
+			failed++
+		case JournalStart:
+			// Iniciado mas sem conclusão: o upload pode ou não ter acontecido
+			unknown = append(unknown, path)
+		}
+	}
+	sort.Strings(unknown)
+
+	return map[string]interface{}{
+		"path":      j.path,
+		"completed": completed,
+		"failed":    failed,
+		"unknown":   unknown,
+	}
+}
+
+// close fecha o arquivo do journal
+func (j *fileJournal) close() {
+	if j == nil {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file != nil {
+		j.file.Close()
+		j.file = nil
+	}
+}
+
+// remove fecha e apaga o journal; chamado quando a coleção termina sem falhas
+// e não há mais nada a recuperar
+func (j *fileJournal) remove() {
+	if j == nil {
+		return
+	}
+
+	j.close()
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("Failed to remove journal %s: %v\n", j.path, err)
+	}
+}
@@ -0,0 +1,123 @@
+package collection
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// JobSummary resume uma coleção para listagens, sem a árvore de obras e arquivos
+type JobSummary struct {
+	ID                string              `json:"id"`
+	Name              string              `json:"name"`
+	BasePath          string              `json:"basePath"`
+	Host              string              `json:"host"`
+	Status            JobStatus           `json:"status"`
+	StartTime         time.Time           `json:"startTime"`
+	EndTime           *time.Time          `json:"endTime,omitempty"`
+	QueuePosition     int                 `json:"queuePosition,omitempty"` // > 0 enquanto aguarda slot
+	Progress          *CollectionProgress `json:"progress"`
+	LastProcessedFile string              `json:"lastProcessedFile,omitempty"`
+}
+
+// ListJobs retorna todas as coleções conhecidas pelo processador, das mais
+// recentes às mais antigas
+func (cp *CollectionProcessor) ListJobs() []JobSummary {
+	cp.mutex.RLock()
+	jobs := make([]*CollectionJob, 0, len(cp.collections))
+	for _, job := range cp.collections {
+		jobs = append(jobs, job)
+	}
+	cp.mutex.RUnlock()
+
+	summaries := make([]JobSummary, 0, len(jobs))
+	for _, job := range jobs {
+		summaries = append(summaries, cp.summarize(job))
+	}
+
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].StartTime.After(summaries[j].StartTime) })
+	return summaries
+}
+
+// Summary retorna o resumo de uma coleção
+func (cp *CollectionProcessor) Summary(jobID string) (JobSummary, bool) {
+	cp.mutex.RLock()
+	job, exists := cp.collections[jobID]
+	cp.mutex.RUnlock()
+	if !exists {
+		return JobSummary{}, false
+	}
+	return cp.summarize(job), true
+}
+
+// summarize monta o resumo de um job
+func (cp *CollectionProcessor) summarize(job *CollectionJob) JobSummary {
+	job.mutex.RLock()
+	summary := JobSummary{
+		ID:                job.ID,
+		Name:              job.Name,
+		BasePath:          job.BasePath,
+		Host:              job.Host,
+		Status:            job.Status,
+		StartTime:         job.StartTime,
+		LastProcessedFile: job.LastProcessedFile,
+	}
+	switch job.Status {
+	case StatusCompleted, StatusFailed, StatusCancelled, StatusPaused:
+		summary.EndTime = job.EstimatedEndTime
+	}
+	job.mutex.RUnlock()
+
+	summary.Progress = job.progress()
+	summary.QueuePosition = cp.queue.Position(job.ID)
+	return summary
+}
+
+// finished informa se a coleção terminou e quando (StartTime se o fim não foi registrado)
+func (job *CollectionJob) finished() (time.Time, bool) {
+	job.mutex.RLock()
+	defer job.mutex.RUnlock()
+	switch job.Status {
+	case StatusCompleted, StatusFailed, StatusCancelled:
+		if job.EstimatedEndTime != nil {
+			return *job.EstimatedEndTime, true
+		}
+		return job.StartTime, true
+	}
+	return time.Time{}, false
+}
+
+// RemoveJob tira uma coleção terminada (concluída, com falha ou cancelada) da
+// memória. Coleções em andamento, na fila ou pausadas continuam.
+func (cp *CollectionProcessor) RemoveJob(jobID string) error {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	job, exists := cp.collections[jobID]
+	if !exists {
+		return fmt.Errorf("collection not found: %s", jobID)
+	}
+	if _, done := job.finished(); !done {
+		return fmt.Errorf("collection %s has not finished", jobID)
+	}
+	delete(cp.collections, jobID)
+	return nil
+}
+
+// PurgeJobs tira da memória as coleções terminadas há mais de olderThan e
+// retorna seus IDs
+func (cp *CollectionProcessor) PurgeJobs(olderThan time.Duration) []string {
+	cutoff := time.Now().Add(-olderThan)
+
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+
+	purged := []string{}
+	for id, job := range cp.collections {
+		if finishedAt, done := job.finished(); done && finishedAt.Before(cutoff) {
+			delete(cp.collections, id)
+			purged = append(purged, id)
+		}
+	}
+	sort.Strings(purged)
+	return purged
+}
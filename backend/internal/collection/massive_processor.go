@@ -3,14 +3,28 @@ package collection
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"go-upload/backend/internal/backup"
+	"go-upload/backend/internal/joblog"
+	"go-upload/backend/internal/mangaid"
+	"go-upload/backend/internal/optimize"
+	"go-upload/backend/internal/phash"
+	"go-upload/backend/internal/quality"
+	"go-upload/backend/internal/retry"
+	"go-upload/backend/internal/scrub"
+	"go-upload/backend/internal/sysfiles"
+	"go-upload/backend/internal/upload"
+	"go-upload/backend/internal/urlcheck"
+	"go-upload/backend/internal/urlrules"
 	"go-upload/backend/internal/workstealing"
 	"go-upload/backend/uploaders"
 )
@@ -26,16 +40,35 @@ type CollectionProcessor struct {
 	
 	// State management
 	collections    map[string]*CollectionJob
+	queue          *CollectionQueue
 	mutex          sync.RWMutex
 	
 	// Progress tracking
 	progressChan   chan *ProgressUpdate
+	fileUploaded   FileUploadedHook
+	transferHook   TransferHook
+	beforeChapter  ChapterHook
+	fileCheck      FileCheck
+	jobLog         *joblog.Store // Log por coleção consultado pelos clientes (nil = sem captura)
+	optimizer      *optimize.Optimizer // Otimização sem perdas das coleções com Optimize
+	spoolDir       string              // Cópias temporárias (sem metadados, otimizadas); vazio = diretório temporário do sistema
+	backup         *backup.Store       // Cópia local de cada arquivo enviado (nil = sem backup)
+	hashUploads    bool                // SHA-256 de cada arquivo enviado mesmo sem backup (manifestos de checksums)
+	urlRewriter    *urlrules.Rewriter  // Regras por host aplicadas às URLs retornadas (nil = sem regras)
+	urlValidator   *urlcheck.Validator // Checagem das URLs retornadas (nil = sem checagem)
+	quality        *quality.Analyzer   // Relatório de qualidade por capítulo (nil = desabilitado)
 	
 	// Lifecycle
 	ctx            context.Context
 	cancel         context.CancelFunc
 	wg             sync.WaitGroup
 	
+	// Emergency stop
+	haltCtx        context.Context
+	haltCancel     context.CancelFunc
+	haltMu         sync.RWMutex
+	shuttingDown   int32
+	
 	// Metrics
 	totalFiles     int64
 	processedFiles int64
@@ -43,17 +76,39 @@ type CollectionProcessor struct {
 	startTime      time.Time
 }
 
+// FileUploadedHook é chamado a cada arquivo enviado com sucesso (ex.: histórico de uploads)
+type FileUploadedHook func(job *CollectionJob, obra *ObraJob, chapter *ChapterJob, file *FileJob)
+
+// TransferHook é chamado a cada envio de bytes ao host (retried = reenvio)
+type TransferHook func(bytes int64, retried bool)
+
+// ChapterHook é chamado antes de enviar cada capítulo; um erro recusa o capítulo,
+// que fica como falho sem que os demais sejam afetados
+type ChapterHook func(job *CollectionJob, obra *ObraJob, chapter *ChapterJob) error
+
+// FileCheck é chamado antes de enviar cada arquivo; um erro recusa o arquivo,
+// que fica como falho (ex.: fora da política de conteúdo)
+type FileCheck func(job *CollectionJob, obra *ObraJob, chapter *ChapterJob, file *FileJob) error
+
 // ProcessorConfig configura o processador de coleções
 type ProcessorConfig struct {
 	MaxConcurrency   int           `json:"maxConcurrency"`
 	BatchSize        int           `json:"batchSize"`
 	RetryAttempts    int           `json:"retryAttempts"`
 	RetryDelay       time.Duration `json:"retryDelay"`
+	RetryBudget      int           `json:"retryBudget,omitempty"` // Retries por coleção (0 = um por arquivo, mínimo 10; -1 = sem limite)
 	ProgressInterval time.Duration `json:"progressInterval"`
 	EnablePersistence bool         `json:"enablePersistence"`
 	StateFilePath    string        `json:"stateFilePath"`
 	ResumeFrom       string        `json:"resumeFrom"`
 	SkipExisting     bool          `json:"skipExisting"`
+	MaxConcurrentCollections int   `json:"maxConcurrentCollections"`
+	Budget           *ResourceBudget `json:"budget,omitempty"`
+	KeepMetadata     bool          `json:"keepMetadata,omitempty"` // Não remove EXIF/XMP/miniaturas antes do envio
+	Optimize         bool          `json:"optimize,omitempty"`     // Recomprime PNG/JPEG sem perdas antes do envio
+	SkipDuplicatePages bool        `json:"skipDuplicatePages,omitempty"` // Pula páginas visualmente idênticas a outra do mesmo capítulo
+	AutoRemovePages  bool          `json:"autoRemovePages,omitempty"` // Não envia páginas em branco (relatório de qualidade) nem idênticas à anterior
+	IncludeHidden    bool          `json:"includeHidden,omitempty"` // Não ignora arquivos de sistema e ocultos (.DS_Store, Thumbs.db, dotfiles)
 }
 
 // CollectionJob representa um job de processamento de coleção
@@ -74,6 +129,12 @@ type CollectionJob struct {
 	TotalFiles       int                    `json:"totalFiles"`
 	UploadedFiles    int                    `json:"uploadedFiles"`
 	FailedFiles      int                    `json:"failedFiles"`
+	MetadataBytesRemoved int64              `json:"metadataBytesRemoved"` // EXIF/XMP removidos antes do envio
+	OptimizationBytesSaved int64            `json:"optimizationBytesSaved"` // Economia da otimização sem perdas
+	SkippedDuplicates int64                 `json:"skippedDuplicates"` // Páginas repetidas puladas (SkipDuplicatePages)
+	RemovedPages     int64                  `json:"removedPages"` // Páginas removidas (AutoRemovePages)
+	BytesTransferred int64                  `json:"bytesTransferred"` // Bytes enviados ao host, incluindo retries
+	RetryBytes       int64                  `json:"retryBytes"`       // ...dos quais reenviados por retries
 	
 	// Performance metrics
 	CurrentSpeed     float64                `json:"currentSpeed"` // files per minute
@@ -92,9 +153,28 @@ type CollectionJob struct {
 	
 	// State
 	LastProcessedFile string                `json:"lastProcessedFile"`
+	budget           *budgetEnforcer        `json:"-"`
+	retryBudget      *retry.Budget          `json:"-"`
+	journal          *fileJournal           `json:"-"`
+	quality          *quality.Report        `json:"-"`
 	mutex            sync.RWMutex           `json:"-"`
 }
 
+// BudgetStats retorna o uso atual do orçamento de recursos do job (nil se não houver limites)
+func (job *CollectionJob) BudgetStats() map[string]interface{} {
+	return job.budget.stats()
+}
+
+// JournalSummary retorna o que foi recuperado do journal de arquivos (nil se desabilitado)
+func (job *CollectionJob) JournalSummary() map[string]interface{} {
+	return job.journal.summary()
+}
+
+// QualityReport retorna o relatório de qualidade dos capítulos já analisados (nil se desabilitado)
+func (job *CollectionJob) QualityReport() *quality.Report {
+	return job.quality
+}
+
 // ObraJob representa o processamento de uma obra
 type ObraJob struct {
 	Name            string            `json:"name"`
@@ -129,16 +209,18 @@ type ChapterJob struct {
 
 // FileJob representa o processamento de um arquivo
 type FileJob struct {
-	Name      string        `json:"name"`
-	Path      string        `json:"path"`
-	Status    JobStatus     `json:"status"`
-	URL       string        `json:"url,omitempty"`
-	Size      int64         `json:"size"`
-	StartTime time.Time     `json:"startTime"`
-	EndTime   *time.Time    `json:"endTime,omitempty"`
-	Duration  time.Duration `json:"duration"`
-	Retries   int           `json:"retries"`
-	Error     string        `json:"error,omitempty"`
+	Name      string          `json:"name"`
+	Path      string          `json:"path"`
+	Status    JobStatus       `json:"status"`
+	URL       string          `json:"url,omitempty"`
+	Size      int64           `json:"size"`
+	StartTime time.Time       `json:"startTime"`
+	EndTime   *time.Time      `json:"endTime,omitempty"`
+	Duration  time.Duration   `json:"duration"`
+	Retries   int             `json:"retries"`
+	Error     string          `json:"error,omitempty"`
+	SHA256    string          `json:"sha256,omitempty"`  // hash do arquivo enviado (com o backup ou SetHashUploads ligados)
+	Receipt   *upload.Receipt `json:"receipt,omitempty"` // identificadores devolvidos pelo host (ex.: nome do arquivo no Catbox)
 }
 
 // JobStatus representa os possíveis status de um job
@@ -151,6 +233,7 @@ const (
 	StatusFailed     JobStatus = "failed"
 	StatusCancelled  JobStatus = "cancelled"
 	StatusPaused     JobStatus = "paused"
+	StatusSkipped    JobStatus = "skipped" // arquivo não enviado de propósito (ex.: página duplicada)
 )
 
 // ProgressUpdate representa uma atualização de progresso
@@ -177,6 +260,12 @@ type CollectionProgress struct {
 	AverageSpeed      float64       `json:"averageSpeed"`
 	ETA               string        `json:"eta"`
 	Percentage        float64       `json:"percentage"`
+	MetadataBytesRemoved int64      `json:"metadataBytesRemoved"`
+	OptimizationBytesSaved int64    `json:"optimizationBytesSaved"`
+	SkippedDuplicates int64         `json:"skippedDuplicates"`
+	RemovedPages      int64         `json:"removedPages"`
+	BytesTransferred  int64         `json:"bytesTransferred"`
+	RetryBytes        int64         `json:"retryBytes"`
 }
 
 // NewCollectionProcessor cria um novo processador de coleções
@@ -206,11 +295,13 @@ func NewCollectionProcessor(config *ProcessorConfig) *CollectionProcessor {
 		uploader:     uploader,
 		config:       config,
 		collections:  make(map[string]*CollectionJob),
+		queue:        NewCollectionQueue(config.MaxConcurrentCollections),
 		progressChan: make(chan *ProgressUpdate, 1000),
 		ctx:          ctx,
 		cancel:       cancel,
 		startTime:    time.Now(),
 	}
+	processor.haltCtx, processor.haltCancel = context.WithCancel(ctx)
 	
 	return processor
 }
@@ -236,6 +327,10 @@ func (cp *CollectionProcessor) ProcessCollection(request *CollectionRequest) (*C
 		return nil, fmt.Errorf("invalid request: %v", err)
 	}
 	
+	if cp.IsHalted() {
+		return nil, ErrProcessorHalted
+	}
+	
 	// Cria job
 	job := &CollectionJob{
 		ID:        request.ID,
@@ -248,6 +343,12 @@ func (cp *CollectionProcessor) ProcessCollection(request *CollectionRequest) (*C
 		OnProgress: request.OnProgress,
 		OnComplete: request.OnComplete,
 	}
+	if request.Options != nil {
+		job.budget = newBudgetEnforcer(request.Options.Budget)
+	}
+	if cp.quality != nil {
+		job.quality = quality.NewReport()
+	}
 	
 	// Registra job
 	cp.mutex.Lock()
@@ -259,19 +360,46 @@ func (cp *CollectionProcessor) ProcessCollection(request *CollectionRequest) (*C
 		if err := cp.loadJobState(job); err != nil {
 			// Log erro mas continua
 			fmt.Printf("Failed to load job state: %v\n", err)
+			cp.jobLog.Add(job.ID, joblog.Warn, "failed to load previous job state: %v", err)
+		}
+		
+		// Journal de arquivos para recuperação após crash
+		if cp.config.StateFilePath != "" {
+			journal, err := openFileJournal(cp.journalPath(job.ID))
+			if err != nil {
+				fmt.Printf("Failed to open file journal: %v\n", err)
+				cp.jobLog.Add(job.ID, joblog.Warn, "failed to open file journal, crash recovery disabled: %v", err)
+			} else {
+				job.journal = journal
+			}
+		}
+		
+		// Persiste desde o enfileiramento para que um reinício possa retomar o job
+		if err := cp.saveJobState(job); err != nil {
+			fmt.Printf("Failed to save job state: %v\n", err)
 		}
 	}
 	
-	// Inicia processamento em background
-	cp.wg.Add(1)
-	go func() {
-		defer cp.wg.Done()
-		cp.processCollectionAsync(job)
-	}()
+	cp.jobLog.Add(job.ID, joblog.Info, "collection %q queued: %s -> %s", job.Name, job.BasePath, job.Host)
+	
+	// Enfileira e inicia processamento quando houver slot livre
+	cp.queue.Enqueue(job, request.Priority)
+	cp.dispatchQueued()
 	
 	return job, nil
 }
 
+// dispatchQueued inicia em background os jobs da fila que cabem nos slots livres
+func (cp *CollectionProcessor) dispatchQueued() {
+	for _, job := range cp.queue.Next() {
+		cp.wg.Add(1)
+		go func(j *CollectionJob) {
+			defer cp.wg.Done()
+			cp.processCollectionAsync(j)
+		}(job)
+	}
+}
+
 // processCollectionAsync processa uma coleção de forma assíncrona
 func (cp *CollectionProcessor) processCollectionAsync(job *CollectionJob) {
 	defer func() {
@@ -287,10 +415,22 @@ func (cp *CollectionProcessor) processCollectionAsync(job *CollectionJob) {
 	job.mutex.Unlock()
 	
 	// Descobre estrutura da coleção
+	cp.jobLog.Add(job.ID, joblog.Info, "collection started")
 	if err := cp.discoverCollectionStructure(job); err != nil {
 		cp.completeJob(job, err)
 		return
 	}
+	job.mutex.Lock()
+	cp.jobLog.Add(job.ID, joblog.Info, "discovered %d obras, %d chapters, %d files", job.TotalObras, job.TotalChapters, job.TotalFiles)
+	retryBudget := cp.config.RetryBudget
+	if job.Options != nil && job.Options.RetryBudget != 0 {
+		retryBudget = job.Options.RetryBudget
+	}
+	job.retryBudget = retry.BudgetFor(job.TotalFiles, retryBudget)
+	job.mutex.Unlock()
+	
+	// Restaura arquivos já enviados antes de um crash
+	cp.applyJournal(job)
 	
 	// Processa todas as obras
 	if err := cp.processObras(job); err != nil {
@@ -316,7 +456,7 @@ func (cp *CollectionProcessor) discoverCollectionStructure(job *CollectionJob) e
 	defer job.mutex.Unlock()
 	
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if !entry.IsDir() || cp.skipSystemFile(job, filepath.Join(basePath, entry.Name())) {
 			continue
 		}
 		
@@ -328,7 +468,7 @@ func (cp *CollectionProcessor) discoverCollectionStructure(job *CollectionJob) e
 		}
 		
 		// Descobre capítulos
-		if err := cp.discoverObraStructure(obra); err != nil {
+		if err := cp.discoverObraStructure(job, obra); err != nil {
 			// Log erro mas continua com outras obras
 			fmt.Printf("Failed to discover obra %s: %v\n", obra.Name, err)
 			continue
@@ -344,14 +484,14 @@ func (cp *CollectionProcessor) discoverCollectionStructure(job *CollectionJob) e
 }
 
 // discoverObraStructure descobre a estrutura de uma obra
-func (cp *CollectionProcessor) discoverObraStructure(obra *ObraJob) error {
+func (cp *CollectionProcessor) discoverObraStructure(job *CollectionJob, obra *ObraJob) error {
 	entries, err := os.ReadDir(obra.Path)
 	if err != nil {
 		return fmt.Errorf("failed to read obra directory: %v", err)
 	}
 	
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if !entry.IsDir() || cp.skipSystemFile(job, filepath.Join(obra.Path, entry.Name())) {
 			continue
 		}
 		
@@ -363,7 +503,7 @@ func (cp *CollectionProcessor) discoverObraStructure(obra *ObraJob) error {
 		}
 		
 		// Descobre arquivos
-		if err := cp.discoverChapterFiles(chapter); err != nil {
+		if err := cp.discoverChapterFiles(job, chapter); err != nil {
 			// Log erro mas continua
 			fmt.Printf("Failed to discover chapter %s: %v\n", chapter.Name, err)
 			continue
@@ -377,8 +517,17 @@ func (cp *CollectionProcessor) discoverObraStructure(obra *ObraJob) error {
 	return nil
 }
 
+// skipSystemFile informa se um arquivo ou pasta de sistema/oculto deve ser ignorado
+func (cp *CollectionProcessor) skipSystemFile(job *CollectionJob, path string) bool {
+	includeHidden := cp.config.IncludeHidden
+	if job.Options != nil {
+		includeHidden = job.Options.IncludeHidden
+	}
+	return !includeHidden && sysfiles.Skip(path)
+}
+
 // discoverChapterFiles descobre os arquivos de um capítulo
-func (cp *CollectionProcessor) discoverChapterFiles(chapter *ChapterJob) error {
+func (cp *CollectionProcessor) discoverChapterFiles(job *CollectionJob, chapter *ChapterJob) error {
 	entries, err := os.ReadDir(chapter.Path)
 	if err != nil {
 		return fmt.Errorf("failed to read chapter directory: %v", err)
@@ -405,6 +554,9 @@ func (cp *CollectionProcessor) discoverChapterFiles(chapter *ChapterJob) error {
 		}
 		
 		filePath := filepath.Join(chapter.Path, entry.Name())
+		if cp.skipSystemFile(job, filePath) {
+			continue
+		}
 		
 		// Obtém tamanho do arquivo
 		info, err := entry.Info()
@@ -432,12 +584,48 @@ func (cp *CollectionProcessor) discoverChapterFiles(chapter *ChapterJob) error {
 	return nil
 }
 
+// applyJournal marca como concluídos os arquivos que o journal registra com URL.
+// Eles são pulados no processamento, então já entram nos contadores aqui; o
+// journal substitui os contadores do estado salvo, que incluem falhas que
+// serão tentadas de novo.
+func (cp *CollectionProcessor) applyJournal(job *CollectionJob) {
+	if job.journal == nil {
+		return
+	}
+	
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	
+	restored := 0
+	for _, obra := range job.Obras {
+		for _, chapter := range obra.Chapters {
+			for _, file := range chapter.Files {
+				if url, ok := job.journal.completedURL(file.Path); ok {
+					file.URL = url
+					file.Status = StatusCompleted
+					chapter.UploadedFiles++
+					obra.UploadedFiles++
+					restored++
+				}
+			}
+		}
+	}
+	job.UploadedFiles = restored
+	job.FailedFiles = 0
+	if restored > 0 {
+		cp.jobLog.Add(job.ID, joblog.Info, "restored %d uploaded files from the journal", restored)
+	}
+}
+
 // processObras processa todas as obras da coleção
 func (cp *CollectionProcessor) processObras(job *CollectionJob) error {
+	haltCtx := cp.haltContext()
 	for _, obra := range job.Obras {
 		select {
 		case <-cp.ctx.Done():
 			return cp.ctx.Err()
+		case <-haltCtx.Done():
+			return ErrProcessorHalted
 		default:
 		}
 		
@@ -447,6 +635,11 @@ func (cp *CollectionProcessor) processObras(job *CollectionJob) error {
 		}
 		
 		if err := cp.processObra(job, obra); err != nil {
+			if errors.Is(err, ErrProcessorHalted) {
+				obra.Status = StatusPaused
+				return err
+			}
+			
 			// Log erro mas continua com outras obras
 			fmt.Printf("Failed to process obra %s: %v\n", obra.Name, err)
 			obra.Error = err.Error()
@@ -480,6 +673,10 @@ func (cp *CollectionProcessor) processObra(job *CollectionJob, obra *ObraJob) er
 		if err := cp.processChapterBatch(job, obra, batch); err != nil {
 			return err
 		}
+		
+		if cp.IsHalted() {
+			return ErrProcessorHalted
+		}
 	}
 	
 	// Completa obra
@@ -504,6 +701,9 @@ func (cp *CollectionProcessor) processChapterBatch(job *CollectionJob, obra *Obr
 	semaphore := make(chan struct{}, cp.config.MaxConcurrency)
 	
 	for _, chapter := range chapters {
+		if cp.IsHalted() {
+			break
+		}
 		if cp.shouldSkipChapter(job, chapter) {
 			continue
 		}
@@ -517,6 +717,10 @@ func (cp *CollectionProcessor) processChapterBatch(job *CollectionJob, obra *Obr
 			defer func() { <-semaphore }()
 			
 			if err := cp.processChapter(job, obra, ch); err != nil {
+				if errors.Is(err, ErrProcessorHalted) {
+					ch.Status = StatusPaused
+					return
+				}
 				fmt.Printf("Failed to process chapter %s: %v\n", ch.Name, err)
 				ch.Error = err.Error()
 				ch.Status = StatusFailed
@@ -535,11 +739,61 @@ func (cp *CollectionProcessor) processChapter(job *CollectionJob, obra *ObraJob,
 	chapter.StartTime = time.Now()
 	chapter.mutex.Unlock()
 	
+	if cp.beforeChapter != nil {
+		if err := cp.beforeChapter(job, obra, chapter); err != nil {
+			cp.jobLog.Add(job.ID, joblog.Warn, "chapter %s/%s rejected: %v", obra.Name, chapter.Name, err)
+			return fmt.Errorf("chapter rejected: %w", err)
+		}
+	}
+	
+	// Pré-processamento: problemas de QC ficam no relatório antes da publicação
+	var report *quality.ChapterReport
+	if job.quality != nil {
+		report = cp.checkQuality(job, obra, chapter)
+	}
+	var removed map[*FileJob]quality.Removal
+	if job.Options != nil && job.Options.AutoRemovePages {
+		removed = cp.removablePages(chapter, report)
+	}
+	if report != nil {
+		job.quality.Add(*report)
+	}
+	
+	var duplicates map[*FileJob]string
+	if job.Options != nil && job.Options.SkipDuplicatePages {
+		duplicates = cp.findDuplicatePages(chapter)
+	}
+	
 	// Submete arquivos para o worker pool com prioridades
 	for i, file := range chapter.Files {
+		if cp.IsHalted() {
+			break
+		}
 		if cp.shouldSkipFile(job, file) {
 			continue
 		}
+		if removal, ok := removed[file]; ok {
+			file.Status = StatusSkipped
+			atomic.AddInt64(&job.RemovedPages, 1)
+			if removal.Reason == quality.RemovedDuplicate {
+				cp.jobLog.Add(job.ID, joblog.Info, "%s/%s/%s removed: identical to previous page %s", obra.Name, chapter.Name, file.Name, removal.Original)
+			} else {
+				cp.jobLog.Add(job.ID, joblog.Info, "%s/%s/%s removed: blank page", obra.Name, chapter.Name, file.Name)
+			}
+			continue
+		}
+		if original, ok := duplicates[file]; ok {
+			file.Status = StatusSkipped
+			atomic.AddInt64(&job.SkippedDuplicates, 1)
+			cp.jobLog.Add(job.ID, joblog.Info, "%s/%s/%s skipped: same page as %s", obra.Name, chapter.Name, file.Name, original)
+			continue
+		}
+		if cp.fileCheck != nil {
+			if err := cp.fileCheck(job, obra, chapter, file); err != nil {
+				cp.rejectFile(job, obra, chapter, file, err)
+				continue
+			}
+		}
 		
 		// Determina prioridade baseada na posição do arquivo
 		priority := workstealing.PriorityNormal
@@ -547,15 +801,28 @@ func (cp *CollectionProcessor) processChapter(job *CollectionJob, obra *ObraJob,
 			priority = workstealing.PriorityHigh // Primeiros arquivos têm prioridade alta
 		}
 		
+		// O orçamento da coleção é reservado antes de entrar no worker pool, que é
+		// compartilhado: nenhum worker fica parado esperando o limite de outra coleção.
+		// A reserva vale para todas as tentativas do arquivo.
+		release, err := job.budget.acquire(cp.haltContext(), file.Size)
+		if err != nil {
+			break // Parada de emergência: o arquivo fica pendente para ser retomado
+		}
+		complete := cp.createFileCompleteCallback(job, obra, chapter, file)
+		
 		task := &workstealing.Task{
 			ID:         fmt.Sprintf("%s_%s_%s_%s", job.ID, obra.Name, chapter.Name, file.Name),
 			Priority:   priority,
-			MaxRetries: cp.config.RetryAttempts,
+			Retry:      cp.fileRetrier(job),
 			Execute:    cp.createFileUploadTask(job, obra, chapter, file),
-			OnComplete: cp.createFileCompleteCallback(job, obra, chapter, file),
+			OnComplete: func(err error) {
+				release()
+				complete(err)
+			},
 		}
 		
 		if err := cp.workerPool.Submit(task); err != nil {
+			release()
 			return fmt.Errorf("failed to submit file task: %v", err)
 		}
 	}
@@ -563,6 +830,11 @@ func (cp *CollectionProcessor) processChapter(job *CollectionJob, obra *ObraJob,
 	// Aguarda todos os arquivos serem processados
 	cp.waitForChapterCompletion(chapter)
 	
+	// Parada de emergência: capítulo fica incompleto para ser retomado
+	if cp.IsHalted() {
+		return ErrProcessorHalted
+	}
+	
 	// Completa capítulo
 	chapter.mutex.Lock()
 	chapter.Status = StatusCompleted
@@ -585,21 +857,86 @@ func (cp *CollectionProcessor) processChapter(job *CollectionJob, obra *ObraJob,
 
 // createFileUploadTask cria uma task para upload de arquivo
 func (cp *CollectionProcessor) createFileUploadTask(job *CollectionJob, obra *ObraJob, chapter *ChapterJob, file *FileJob) func() error {
+	attempts := 0
 	return func() error {
+		// Parada de emergência: arquivo fica pendente para ser retomado
+		haltCtx := cp.haltContext()
+		if haltCtx.Err() != nil {
+			file.Status = StatusPaused
+			return nil
+		}
+		
 		file.StartTime = time.Now()
 		file.Status = StatusRunning
+		job.journal.record(JournalStart, file.Path, "", "")
 		
-		// Faz upload
-		url, err := cp.uploader.Upload(file.Path)
+		// Faz upload (sem EXIF/XMP, a menos que a coleção peça para mantê-los)
+		uploadPath := file.Path
+		if job.Options == nil || !job.Options.KeepMetadata {
+			scrubbed, removed, err := scrub.File(file.Path, cp.spoolDir)
+			if err != nil {
+				cp.jobLog.Add(job.ID, joblog.Warn, "%s/%s/%s: metadata not removed: %v", obra.Name, chapter.Name, file.Name, err)
+			} else if removed > 0 {
+				defer os.Remove(scrubbed)
+				uploadPath = scrubbed
+				atomic.AddInt64(&job.MetadataBytesRemoved, removed)
+			}
+		}
+		if job.Options != nil && job.Options.Optimize {
+			optimized, saved, err := cp.optimizer.File(uploadPath, cp.spoolDir)
+			if err != nil {
+				cp.jobLog.Add(job.ID, joblog.Warn, "%s/%s/%s: optimization skipped: %v", obra.Name, chapter.Name, file.Name, err)
+			} else if saved > 0 {
+				defer os.Remove(optimized)
+				uploadPath = optimized
+				atomic.AddInt64(&job.OptimizationBytesSaved, saved)
+			}
+		}
+		attempts++
+		if attempts > 1 {
+			file.Retries++
+		}
+		cp.countTransfer(job, uploadPath, attempts > 1)
+		url, receipt, err := cp.uploader.UploadWithReceipt(uploadPath, upload.UploadDestination{Ctx: haltCtx})
+		if err == nil {
+			host := cp.uploader.GetName()
+			url = cp.urlRewriter.Rewrite(host, url)
+			if checkErr := cp.urlValidator.Check(haltCtx, host, url); checkErr != nil {
+				err = retry.Permanent(fmt.Errorf("%s returned an unusable URL %q: %v", host, url, checkErr))
+			}
+		}
+		if err != nil && haltCtx.Err() != nil {
+			// Envio interrompido pela parada de emergência: retomado depois
+			file.Status = StatusPaused
+			return nil
+		}
 		if err != nil {
+			job.journal.record(JournalFail, file.Path, "", err.Error())
 			file.Status = StatusFailed
 			file.Error = err.Error()
 			atomic.AddInt64(&cp.failedFiles, 1)
+			cp.jobLog.Add(job.ID, joblog.Error, "%s/%s/%s: upload failed: %v", obra.Name, chapter.Name, file.Name, err)
 			return err
 		}
 		
 		// Sucesso
+		job.journal.record(JournalComplete, file.Path, url, "")
 		file.URL = url
+		if !receipt.Empty() {
+			file.Receipt = &receipt
+		}
+		if sum, err := cp.backup.Save(mangaid.Normalize(obra.Name), chapter.Name, file.Name, uploadPath); err != nil {
+			cp.jobLog.Add(job.ID, joblog.Warn, "%s/%s/%s: backup failed: %v", obra.Name, chapter.Name, file.Name, err)
+		} else {
+			file.SHA256 = sum
+		}
+		if file.SHA256 == "" && cp.hashUploads {
+			if sum, err := backup.HashFile(uploadPath); err != nil {
+				cp.jobLog.Add(job.ID, joblog.Warn, "%s/%s/%s: checksum skipped: %v", obra.Name, chapter.Name, file.Name, err)
+			} else {
+				file.SHA256 = sum
+			}
+		}
 		file.Status = StatusCompleted
 		endTime := time.Now()
 		file.EndTime = &endTime
@@ -607,13 +944,149 @@ func (cp *CollectionProcessor) createFileUploadTask(job *CollectionJob, obra *Ob
 		
 		atomic.AddInt64(&cp.processedFiles, 1)
 		
+		if cp.fileUploaded != nil {
+			cp.fileUploaded(job, obra, chapter, file)
+		}
+		
 		return nil
 	}
 }
 
+// countTransfer soma o arquivo enviado ao tráfego da coleção e às métricas
+func (cp *CollectionProcessor) countTransfer(job *CollectionJob, path string, retried bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	atomic.AddInt64(&job.BytesTransferred, info.Size())
+	if retried {
+		atomic.AddInt64(&job.RetryBytes, info.Size())
+	}
+	if cp.transferHook != nil {
+		cp.transferHook(info.Size(), retried)
+	}
+}
+
+// fileRetrier monta a política de retry do envio de um arquivo: backoff
+// exponencial a partir de RetryDelay, com o orçamento da coleção
+func (cp *CollectionProcessor) fileRetrier(job *CollectionJob) *retry.Retrier {
+	return &retry.Retrier{
+		Policy: retry.Policy{
+			MaxRetries: cp.config.RetryAttempts,
+			BaseDelay:  cp.config.RetryDelay,
+			Jitter:     retry.DefaultJitter,
+		},
+		Name:   "collection",
+		Budget: job.retryBudget,
+	}
+}
+
+// findDuplicatePages retorna as páginas do capítulo idênticas (distância 0 no
+// hash perceptual) a uma página anterior, com o nome da original
+func (cp *CollectionProcessor) findDuplicatePages(chapter *ChapterJob) map[*FileJob]string {
+	paths := make([]string, len(chapter.Files))
+	for i, file := range chapter.Files {
+		paths[i] = file.Path
+	}
+	hashes := phash.Files(paths, 0)
+	
+	var pages []phash.Page
+	files := make(map[string]*FileJob, len(chapter.Files))
+	for _, file := range chapter.Files {
+		if hash, ok := hashes[file.Path]; ok {
+			pages = append(pages, phash.Page{Chapter: chapter.Path, File: file.Path, Hash: hash})
+			files[file.Path] = file
+		}
+	}
+	
+	duplicates := make(map[*FileJob]string)
+	for _, duplicate := range phash.Find(pages, 0) {
+		if duplicate.Scope == phash.ScopeChapter {
+			duplicates[files[duplicate.File]] = filepath.Base(duplicate.Original)
+		}
+	}
+	return duplicates
+}
+
+// checkQuality analisa as páginas do capítulo e registra no log os problemas
+// encontrados. O relatório volta para quem chama incluí-lo no do job depois da
+// remoção automática de páginas.
+func (cp *CollectionProcessor) checkQuality(job *CollectionJob, obra *ObraJob, chapter *ChapterJob) *quality.ChapterReport {
+	paths := make([]string, len(chapter.Files))
+	for i, file := range chapter.Files {
+		paths[i] = file.Path
+	}
+	report := cp.quality.Chapter(obra.Name+"/"+chapter.Name, paths)
+	if len(report.Issues) > 0 {
+		cp.jobLog.Add(job.ID, joblog.Warn, "%s/%s quality: %s", obra.Name, chapter.Name, strings.Join(report.Issues, ", "))
+	}
+	return &report
+}
+
+// removablePages escolhe as páginas que AutoRemovePages deixa de fora do envio:
+// as em branco do relatório de qualidade e as idênticas (distância 0 no hash
+// perceptual) à página imediatamente anterior. Sem relatório (qualidade
+// desabilitada) só as duplicatas são removidas. Os arquivos locais não são
+// apagados; as remoções ficam em report.Removed para auditoria.
+func (cp *CollectionProcessor) removablePages(chapter *ChapterJob, report *quality.ChapterReport) map[*FileJob]quality.Removal {
+	blank := make(map[string]bool)
+	if report != nil {
+		for _, name := range report.BlankPages {
+			blank[name] = true
+		}
+	}
+	
+	paths := make([]string, len(chapter.Files))
+	for i, file := range chapter.Files {
+		paths[i] = file.Path
+	}
+	hashes := phash.Files(paths, 0)
+	
+	removed := make(map[*FileJob]quality.Removal)
+	for i, file := range chapter.Files {
+		name := filepath.Base(file.Path)
+		if blank[name] {
+			removed[file] = quality.Removal{File: name, Reason: quality.RemovedBlank}
+			continue
+		}
+		if i == 0 {
+			continue
+		}
+		previous := chapter.Files[i-1]
+		hash, ok := hashes[file.Path]
+		previousHash, previousOk := hashes[previous.Path]
+		if ok && previousOk && phash.Distance(hash, previousHash) == 0 {
+			removed[file] = quality.Removal{File: name, Reason: quality.RemovedDuplicate, Original: filepath.Base(previous.Path)}
+		}
+	}
+	
+	if report != nil {
+		for _, file := range chapter.Files {
+			if removal, ok := removed[file]; ok {
+				report.Removed = append(report.Removed, removal)
+			}
+		}
+	}
+	return removed
+}
+
+// rejectFile marca como falho um arquivo recusado antes do envio
+func (cp *CollectionProcessor) rejectFile(job *CollectionJob, obra *ObraJob, chapter *ChapterJob, file *FileJob, err error) {
+	file.Status = StatusFailed
+	file.Error = err.Error()
+	atomic.AddInt64(&cp.failedFiles, 1)
+	cp.jobLog.Add(job.ID, joblog.Warn, "%s/%s/%s rejected: %v", obra.Name, chapter.Name, file.Name, err)
+	cp.createFileCompleteCallback(job, obra, chapter, file)(err)
+}
+
 // createFileCompleteCallback cria callback de conclusão de arquivo
 func (cp *CollectionProcessor) createFileCompleteCallback(job *CollectionJob, obra *ObraJob, chapter *ChapterJob, file *FileJob) func(error) {
 	return func(err error) {
+		// Arquivos interrompidos pela parada de emergência não contam como processados
+		if file.Status == StatusPaused {
+			return
+		}
+		
 		if err == nil {
 			chapter.UploadedFiles++
 			obra.UploadedFiles++
@@ -639,12 +1112,13 @@ func (cp *CollectionProcessor) waitForChapterCompletion(chapter *ChapterJob) {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 	
+	haltCtx := cp.haltContext()
 	for {
 		select {
 		case <-ticker.C:
 			completed := 0
 			for _, file := range chapter.Files {
-				if file.Status == StatusCompleted || file.Status == StatusFailed {
+				if file.Status == StatusCompleted || file.Status == StatusFailed || file.Status == StatusSkipped {
 					completed++
 				}
 			}
@@ -655,6 +1129,8 @@ func (cp *CollectionProcessor) waitForChapterCompletion(chapter *ChapterJob) {
 			
 		case <-cp.ctx.Done():
 			return
+		case <-haltCtx.Done():
+			return
 		}
 	}
 }
@@ -691,6 +1167,11 @@ func (cp *CollectionProcessor) shouldSkipChapter(job *CollectionJob, chapter *Ch
 
 // shouldSkipFile verifica se deve pular um arquivo
 func (cp *CollectionProcessor) shouldSkipFile(job *CollectionJob, file *FileJob) bool {
+	// Arquivos concluídos segundo o journal nunca são reenviados
+	if _, ok := job.journal.completedURL(file.Path); ok {
+		return true
+	}
+	
 	if job.Options == nil {
 		return false
 	}
@@ -705,6 +1186,26 @@ func (cp *CollectionProcessor) shouldSkipFile(job *CollectionJob, file *FileJob)
 
 // sendProgressUpdate envia atualização de progresso
 func (cp *CollectionProcessor) sendProgressUpdate(job *CollectionJob, updateType, item string) {
+	progress := job.progress()
+	
+	update := &ProgressUpdate{
+		CollectionID: job.ID,
+		Type:         updateType,
+		Status:       "progress",
+		Progress:     progress,
+		CurrentFile:  item,
+		Timestamp:    time.Now(),
+	}
+	
+	select {
+	case cp.progressChan <- update:
+	default:
+		// Canal cheio, ignora update
+	}
+}
+
+// progress calcula contadores, velocidade, ETA e porcentagem do job
+func (job *CollectionJob) progress() *CollectionProgress {
 	job.mutex.RLock()
 	progress := &CollectionProgress{
 		TotalObras:        job.TotalObras,
@@ -714,6 +1215,12 @@ func (cp *CollectionProcessor) sendProgressUpdate(job *CollectionJob, updateType
 		TotalFiles:        job.TotalFiles,
 		UploadedFiles:     job.UploadedFiles,
 		FailedFiles:       job.FailedFiles,
+		MetadataBytesRemoved: atomic.LoadInt64(&job.MetadataBytesRemoved),
+		OptimizationBytesSaved: atomic.LoadInt64(&job.OptimizationBytesSaved),
+		SkippedDuplicates: atomic.LoadInt64(&job.SkippedDuplicates),
+		RemovedPages:      atomic.LoadInt64(&job.RemovedPages),
+		BytesTransferred:  atomic.LoadInt64(&job.BytesTransferred),
+		RetryBytes:        atomic.LoadInt64(&job.RetryBytes),
 	}
 	job.mutex.RUnlock()
 	
@@ -732,25 +1239,12 @@ func (cp *CollectionProcessor) sendProgressUpdate(job *CollectionJob, updateType
 		}
 	}
 	
-	// Calcula porcentagem
+	// Calcula porcentagem (páginas duplicadas puladas e removidas contam como concluídas)
 	if progress.TotalFiles > 0 {
-		progress.Percentage = float64(progress.UploadedFiles) / float64(progress.TotalFiles) * 100
+		progress.Percentage = float64(int64(progress.UploadedFiles)+progress.SkippedDuplicates+progress.RemovedPages) / float64(progress.TotalFiles) * 100
 	}
 	
-	update := &ProgressUpdate{
-		CollectionID: job.ID,
-		Type:         updateType,
-		Status:       "progress",
-		Progress:     progress,
-		CurrentFile:  item,
-		Timestamp:    time.Now(),
-	}
-	
-	select {
-	case cp.progressChan <- update:
-	default:
-		// Canal cheio, ignora update
-	}
+	return progress
 }
 
 // progressProcessor processa atualizações de progresso
@@ -776,15 +1270,28 @@ func (cp *CollectionProcessor) progressProcessor() {
 // completeJob completa um job
 func (cp *CollectionProcessor) completeJob(job *CollectionJob, err error) {
 	job.mutex.Lock()
-	if err != nil {
+	if errors.Is(err, ErrProcessorHalted) && cp.isShuttingDown() {
+		// Interrompido pelo desligamento: continua pendente para ser retomado no próximo início
+		job.Status = StatusPending
+	} else if errors.Is(err, ErrProcessorHalted) {
+		job.Status = StatusPaused
+	} else if err != nil {
 		job.Status = StatusFailed
 	} else {
 		job.Status = StatusCompleted
 	}
 	endTime := time.Now()
 	job.EstimatedEndTime = &endTime
+	status := job.Status
+	uploaded, failed := job.UploadedFiles, job.FailedFiles
 	job.mutex.Unlock()
 	
+	if err != nil && status != StatusPaused && status != StatusPending {
+		cp.jobLog.Add(job.ID, joblog.Error, "collection %s: %v (%d files uploaded, %d failed)", status, err, uploaded, failed)
+	} else {
+		cp.jobLog.Add(job.ID, joblog.Info, "collection %s: %d files uploaded, %d failed", status, uploaded, failed)
+	}
+	
 	// Callback de conclusão
 	if job.OnComplete != nil {
 		go job.OnComplete(err)
@@ -794,6 +1301,16 @@ func (cp *CollectionProcessor) completeJob(job *CollectionJob, err error) {
 	if cp.config.EnablePersistence {
 		cp.saveJobState(job)
 	}
+	if status == StatusCompleted && failed == 0 {
+		// Nada a recuperar: o journal só cresceria entre execuções
+		job.journal.remove()
+	} else {
+		job.journal.close()
+	}
+	
+	// Libera slot e inicia próxima coleção da fila
+	cp.queue.Done(job.ID)
+	cp.dispatchQueued()
 }
 
 // loadJobState carrega estado de um job
@@ -884,10 +1401,104 @@ func (cp *CollectionProcessor) CancelJob(jobID string) error {
 	job.mutex.Lock()
 	job.Status = StatusCancelled
 	job.mutex.Unlock()
+	cp.jobLog.Add(jobID, joblog.Warn, "collection canceled")
+	
+	// Jobs ainda na fila nunca chegam a executar
+	if cp.queue.Remove(jobID) {
+		job.journal.close()
+		if job.OnComplete != nil {
+			go job.OnComplete(fmt.Errorf("collection cancelled while queued"))
+		}
+	}
 	
 	return nil
 }
 
+// GetQueue retorna o estado atual da fila de coleções
+func (cp *CollectionProcessor) GetQueue() *QueueSnapshot {
+	return cp.queue.Snapshot()
+}
+
+// ReorderQueue redefine a ordem de execução das coleções pendentes
+func (cp *CollectionProcessor) ReorderQueue(order []string) error {
+	return cp.queue.Reorder(order)
+}
+
+// SetJobLog registra onde guardar o log de cada coleção (etapas, falhas e conclusão)
+func (cp *CollectionProcessor) SetJobLog(store *joblog.Store) {
+	cp.jobLog = store
+}
+
+// SetFileUploadedHook registra uma função chamada a cada arquivo enviado com sucesso
+func (cp *CollectionProcessor) SetFileUploadedHook(hook FileUploadedHook) {
+	cp.fileUploaded = hook
+}
+
+// SetTransferHook registra uma função chamada a cada envio de bytes ao host
+func (cp *CollectionProcessor) SetTransferHook(hook TransferHook) {
+	cp.transferHook = hook
+}
+
+// SetChapterHook registra uma função chamada antes do envio de cada capítulo
+func (cp *CollectionProcessor) SetChapterHook(hook ChapterHook) {
+	cp.beforeChapter = hook
+}
+
+// SetSpoolDir define onde ficam as cópias temporárias dos arquivos das coleções
+// (o mesmo spool dos lotes, já criado por eles)
+func (cp *CollectionProcessor) SetSpoolDir(dir string) {
+	cp.spoolDir = dir
+}
+
+// SetOptimizer registra o otimizador usado pelas coleções com Optimize
+func (cp *CollectionProcessor) SetOptimizer(optimizer *optimize.Optimizer) {
+	cp.optimizer = optimizer
+}
+
+// SetBackup registra onde copiar cada arquivo enviado com sucesso
+func (cp *CollectionProcessor) SetBackup(store *backup.Store) {
+	cp.backup = store
+}
+
+// SetHashUploads faz cada arquivo enviado com sucesso guardar o SHA-256 do
+// conteúdo enviado, mesmo com o backup desligado
+func (cp *CollectionProcessor) SetHashUploads(enabled bool) {
+	cp.hashUploads = enabled
+}
+
+// SetURLRewriter registra as regras aplicadas às URLs retornadas pelo host;
+// o journal guarda a URL já reescrita
+func (cp *CollectionProcessor) SetURLRewriter(rewriter *urlrules.Rewriter) {
+	cp.urlRewriter = rewriter
+}
+
+// SetURLValidator registra a checagem das URLs retornadas; uma URL recusada
+// marca o arquivo como falho, sem novas tentativas
+func (cp *CollectionProcessor) SetURLValidator(validator *urlcheck.Validator) {
+	cp.urlValidator = validator
+}
+
+// SetQualityAnalyzer registra o analisador do relatório de qualidade dos capítulos
+func (cp *CollectionProcessor) SetQualityAnalyzer(analyzer *quality.Analyzer) {
+	cp.quality = analyzer
+}
+
+// SetFileCheck registra uma função chamada antes do envio de cada arquivo
+func (cp *CollectionProcessor) SetFileCheck(check FileCheck) {
+	cp.fileCheck = check
+}
+
+// SetMaxConcurrentCollections altera quantas coleções podem executar ao mesmo tempo
+func (cp *CollectionProcessor) SetMaxConcurrentCollections(maxConcurrent int) {
+	cp.queue.SetMaxConcurrent(maxConcurrent)
+	cp.dispatchQueued()
+}
+
+// GetQueuePosition retorna a posição de uma coleção na fila (0 se não estiver aguardando)
+func (cp *CollectionProcessor) GetQueuePosition(jobID string) int {
+	return cp.queue.Position(jobID)
+}
+
 // GetMetrics retorna métricas do processador
 func (cp *CollectionProcessor) GetMetrics() map[string]interface{} {
 	total := atomic.LoadInt64(&cp.totalFiles)
@@ -939,6 +1550,7 @@ type CollectionRequest struct {
 	BasePath       string                    `json:"basePath"`
 	Host           string                    `json:"host"`
 	Options        *ProcessorConfig          `json:"options,omitempty"`
+	Priority       int                       `json:"priority,omitempty"`
 	OnProgress     func(*ProgressUpdate)     `json:"-"`
 	OnComplete     func(error)               `json:"-"`
 }
\ No newline at end of file
@@ -0,0 +1,202 @@
+package collection
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// QueueEntry representa uma coleção aguardando execução na fila
+type QueueEntry struct {
+	CollectionID   string    `json:"collectionId"`
+	CollectionName string    `json:"collectionName"`
+	Priority       int       `json:"priority"`
+	Position       int       `json:"position"`
+	EnqueuedAt     time.Time `json:"enqueuedAt"`
+}
+
+// QueueSnapshot representa o estado atual da fila de coleções
+type QueueSnapshot struct {
+	MaxConcurrent int          `json:"maxConcurrent"`
+	Running       []string     `json:"running"`
+	Queued        []QueueEntry `json:"queued"`
+}
+
+// queuedCollection mantém um job aguardando slot de execução
+type queuedCollection struct {
+	job        *CollectionJob
+	priority   int
+	enqueuedAt time.Time
+}
+
+// CollectionQueue controla quantas coleções executam simultaneamente e a ordem das pendentes
+type CollectionQueue struct {
+	maxConcurrent int
+	running       map[string]*CollectionJob
+	pending       []*queuedCollection
+	mutex         sync.Mutex
+}
+
+// NewCollectionQueue cria uma nova fila de coleções
+func NewCollectionQueue(maxConcurrent int) *CollectionQueue {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1 // Execução sequencial por padrão
+	}
+
+	return &CollectionQueue{
+		maxConcurrent: maxConcurrent,
+		running:       make(map[string]*CollectionJob),
+		pending:       make([]*queuedCollection, 0),
+	}
+}
+
+// Enqueue adiciona um job na fila respeitando a prioridade (maior primeiro, depois FIFO)
+func (q *CollectionQueue) Enqueue(job *CollectionJob, priority int) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.pending = append(q.pending, &queuedCollection{
+		job:        job,
+		priority:   priority,
+		enqueuedAt: time.Now(),
+	})
+
+	sort.SliceStable(q.pending, func(i, j int) bool {
+		return q.pending[i].priority > q.pending[j].priority
+	})
+}
+
+// Next retira da fila os jobs que cabem nos slots livres e os marca como em execução
+func (q *CollectionQueue) Next() []*CollectionJob {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var ready []*CollectionJob
+	for len(q.running) < q.maxConcurrent && len(q.pending) > 0 {
+		entry := q.pending[0]
+		q.pending = q.pending[1:]
+		q.running[entry.job.ID] = entry.job
+		ready = append(ready, entry.job)
+	}
+
+	return ready
+}
+
+// Done libera o slot ocupado por um job
+func (q *CollectionQueue) Done(jobID string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	delete(q.running, jobID)
+}
+
+// IsRunning informa se o job ocupa um slot de execução
+func (q *CollectionQueue) IsRunning(jobID string) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	_, running := q.running[jobID]
+	return running
+}
+
+// Remove retira um job pendente da fila, retornando false se ele não estiver aguardando
+func (q *CollectionQueue) Remove(jobID string) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, entry := range q.pending {
+		if entry.job.ID == jobID {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reorder redefine a ordem dos jobs pendentes; IDs omitidos mantêm a ordem relativa no final
+func (q *CollectionQueue) Reorder(order []string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	byID := make(map[string]*queuedCollection, len(q.pending))
+	for _, entry := range q.pending {
+		byID[entry.job.ID] = entry
+	}
+
+	reordered := make([]*queuedCollection, 0, len(q.pending))
+	seen := make(map[string]bool, len(order))
+	for _, id := range order {
+		entry, exists := byID[id]
+		if !exists {
+			return fmt.Errorf("collection not queued: %s", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("duplicate collection in order: %s", id)
+		}
+		seen[id] = true
+		reordered = append(reordered, entry)
+	}
+
+	for _, entry := range q.pending {
+		if !seen[entry.job.ID] {
+			reordered = append(reordered, entry)
+		}
+	}
+
+	q.pending = reordered
+	return nil
+}
+
+// SetMaxConcurrent altera o número máximo de coleções simultâneas
+func (q *CollectionQueue) SetMaxConcurrent(maxConcurrent int) {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+
+	q.mutex.Lock()
+	q.maxConcurrent = maxConcurrent
+	q.mutex.Unlock()
+}
+
+// Position retorna a posição (1-based) de um job pendente, ou 0 se não estiver na fila
+func (q *CollectionQueue) Position(jobID string) int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, entry := range q.pending {
+		if entry.job.ID == jobID {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// Snapshot retorna uma cópia do estado da fila
+func (q *CollectionQueue) Snapshot() *QueueSnapshot {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	snapshot := &QueueSnapshot{
+		MaxConcurrent: q.maxConcurrent,
+		Running:       make([]string, 0, len(q.running)),
+		Queued:        make([]QueueEntry, 0, len(q.pending)),
+	}
+
+	for id := range q.running {
+		snapshot.Running = append(snapshot.Running, id)
+	}
+	sort.Strings(snapshot.Running)
+
+	for i, entry := range q.pending {
+		snapshot.Queued = append(snapshot.Queued, QueueEntry{
+			CollectionID:   entry.job.ID,
+			CollectionName: entry.job.Name,
+			Priority:       entry.priority,
+			Position:       i + 1,
+			EnqueuedAt:     entry.enqueuedAt,
+		})
+	}
+
+	return snapshot
+}
@@ -0,0 +1,108 @@
+package collection
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// isShuttingDown informa se o processador está sendo desligado (não é uma parada de emergência)
+func (cp *CollectionProcessor) isShuttingDown() bool {
+	return atomic.LoadInt32(&cp.shuttingDown) == 1
+}
+
+// Shutdown interrompe as coleções para um reinício seguro. Diferente de Halt, os jobs
+// não ficam pausados: o estado é salvo como pendente para que ResumableJobs os
+// retome no próximo início. Deve ser chamado antes de Stop.
+func (cp *CollectionProcessor) Shutdown() []string {
+	atomic.StoreInt32(&cp.shuttingDown, 1)
+
+	cp.haltMu.Lock()
+	if cp.haltCancel != nil {
+		cp.haltCancel()
+	}
+	cp.haltMu.Unlock()
+
+	cp.mutex.RLock()
+	jobs := make([]*CollectionJob, 0, len(cp.collections))
+	for _, job := range cp.collections {
+		jobs = append(jobs, job)
+	}
+	cp.mutex.RUnlock()
+
+	interrupted := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		job.mutex.RLock()
+		active := job.Status == StatusPending || job.Status == StatusRunning
+		job.mutex.RUnlock()
+
+		if !active {
+			continue
+		}
+		interrupted = append(interrupted, job.ID)
+
+		// Jobs na fila são salvos aqui; os em execução são salvos por completeJob
+		if cp.queue.Remove(job.ID) {
+			if cp.config.EnablePersistence {
+				cp.saveJobState(job)
+			}
+			job.journal.close()
+		}
+	}
+
+	return interrupted
+}
+
+// ResumableJobs lista os jobs persistidos que não terminaram: interrompidos por
+// desligamento ou crash (pendentes) e pausados por parada de emergência. Um job
+// pausado nesta execução entra assim que termina de parar; enquanto ainda ocupa
+// um slot, fica de fora para não ser enviado em dobro.
+func (cp *CollectionProcessor) ResumableJobs() []*CollectionJob {
+	if cp.config.StateFilePath == "" {
+		return nil
+	}
+
+	files, err := filepath.Glob(cp.config.StateFilePath + "_*.json")
+	if err != nil {
+		return nil
+	}
+
+	var jobs []*CollectionJob
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		var job CollectionJob
+		if err := json.Unmarshal(data, &job); err != nil || job.ID == "" {
+			continue
+		}
+		if !strings.HasSuffix(file, "_"+job.ID+".json") {
+			continue
+		}
+		if job.Status != StatusPending && job.Status != StatusRunning && job.Status != StatusPaused {
+			continue
+		}
+
+		cp.mutex.RLock()
+		loadedJob, loaded := cp.collections[job.ID]
+		cp.mutex.RUnlock()
+		if loaded {
+			loadedJob.mutex.RLock()
+			paused := loadedJob.Status == StatusPaused
+			loadedJob.mutex.RUnlock()
+			if !paused || cp.queue.IsRunning(job.ID) {
+				continue
+			}
+		}
+
+		jobs = append(jobs, &job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].StartTime.Before(jobs[j].StartTime) })
+	return jobs
+}
@@ -0,0 +1,200 @@
+package collection
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StateFile descreve o estado salvo de uma coleção (<prefixo>_<id>.json) e seu journal
+type StateFile struct {
+	JobID     string    `json:"jobId"`
+	Name      string    `json:"name,omitempty"`
+	Status    JobStatus `json:"status,omitempty"` // vazio se o estado não pôde ser lido (ou só há journal)
+	Files     []string  `json:"files"`
+	Bytes     int64     `json:"bytes"`
+	ModTime   time.Time `json:"modTime"`
+	Protected bool      `json:"protected"` // retomável, pausado ou ainda em memória: nunca removido pela limpeza
+}
+
+// StateInventory resume os estados salvos em disco
+type StateInventory struct {
+	Count      int         `json:"count"`
+	Protected  int         `json:"protected"`
+	TotalBytes int64       `json:"totalBytes"`
+	Oldest     *time.Time  `json:"oldest,omitempty"`
+	Files      []StateFile `json:"files,omitempty"`
+}
+
+// StatePolicy define quais estados de coleções terminadas a limpeza remove.
+// Zero em um campo desativa aquele critério.
+type StatePolicy struct {
+	MaxAge   time.Duration // remove estados sem alteração há mais que isso
+	MaxCount int           // mantém só os mais recentes entre os removíveis
+}
+
+// StateCleanup é o resultado de CleanupStateFiles
+type StateCleanup struct {
+	DryRun     bool        `json:"dryRun"`
+	Removed    []StateFile `json:"removed"`
+	FreedBytes int64       `json:"freedBytes"`
+	Kept       int         `json:"kept"`
+	Errors     []string    `json:"errors,omitempty"`
+}
+
+// StateFiles lista os estados salvos em disco, dos mais recentes aos mais antigos
+func (cp *CollectionProcessor) StateFiles() ([]StateFile, error) {
+	if cp.config.StateFilePath == "" {
+		return []StateFile{}, nil
+	}
+
+	states, err := filepath.Glob(cp.config.StateFilePath + "_*.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection states: %v", err)
+	}
+	journals, err := filepath.Glob(cp.config.StateFilePath + "_*.journal")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection journals: %v", err)
+	}
+
+	prefix := filepath.Base(cp.config.StateFilePath) + "_"
+	byJob := make(map[string]*StateFile)
+	for _, path := range append(states, journals...) {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		name := filepath.Base(path)
+		jobID := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".json"), ".journal")
+		state := byJob[jobID]
+		if state == nil {
+			state = &StateFile{JobID: jobID}
+			byJob[jobID] = state
+		}
+		state.Files = append(state.Files, path)
+		state.Bytes += info.Size()
+		if info.ModTime().After(state.ModTime) {
+			state.ModTime = info.ModTime()
+		}
+		if strings.HasSuffix(name, ".json") {
+			cp.readStateHeader(path, state)
+		}
+	}
+
+	files := make([]StateFile, 0, len(byJob))
+	for _, state := range byJob {
+		sort.Strings(state.Files)
+		state.Protected = cp.stateProtected(state)
+		files = append(files, *state)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime.After(files[j].ModTime) })
+	return files, nil
+}
+
+// StateInventory resume os estados salvos; withFiles inclui a lista completa
+func (cp *CollectionProcessor) StateInventory(withFiles bool) (*StateInventory, error) {
+	files, err := cp.StateFiles()
+	if err != nil {
+		return nil, err
+	}
+	inventory := &StateInventory{Count: len(files)}
+	for _, file := range files {
+		inventory.TotalBytes += file.Bytes
+		if file.Protected {
+			inventory.Protected++
+		}
+		if inventory.Oldest == nil || file.ModTime.Before(*inventory.Oldest) {
+			oldest := file.ModTime
+			inventory.Oldest = &oldest
+		}
+	}
+	if withFiles {
+		inventory.Files = files
+	}
+	return inventory, nil
+}
+
+// CleanupStateFiles remove os estados (e journals) de coleções terminadas que
+// passaram de policy.MaxAge ou excedem policy.MaxCount. Estados retomáveis,
+// pausados ou de jobs em memória nunca são removidos. Em dryRun nada é apagado.
+func (cp *CollectionProcessor) CleanupStateFiles(policy StatePolicy, dryRun bool) (*StateCleanup, error) {
+	files, err := cp.StateFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	cleanup := &StateCleanup{DryRun: dryRun, Removed: []StateFile{}}
+	cutoff := time.Now().Add(-policy.MaxAge)
+	removable := 0
+	for _, file := range files {
+		if file.Protected {
+			cleanup.Kept++
+			continue
+		}
+		removable++
+		expired := policy.MaxAge > 0 && file.ModTime.Before(cutoff)
+		excess := policy.MaxCount > 0 && removable > policy.MaxCount
+		if !expired && !excess {
+			cleanup.Kept++
+			continue
+		}
+
+		if !dryRun {
+			failed := false
+			for _, path := range file.Files {
+				if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+					cleanup.Errors = append(cleanup.Errors, fmt.Sprintf("failed to remove %s: %v", filepath.Base(path), err))
+					failed = true
+				}
+			}
+			if failed {
+				cleanup.Kept++
+				continue
+			}
+		}
+		cleanup.Removed = append(cleanup.Removed, file)
+		cleanup.FreedBytes += file.Bytes
+	}
+	return cleanup, nil
+}
+
+// readStateHeader lê nome e status do estado salvo; arquivos ilegíveis ficam sem status
+func (cp *CollectionProcessor) readStateHeader(path string, state *StateFile) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var header struct {
+		ID     string    `json:"id"`
+		Name   string    `json:"name"`
+		Status JobStatus `json:"status"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil || header.ID != state.JobID {
+		return
+	}
+	state.Name = header.Name
+	state.Status = header.Status
+}
+
+// stateProtected informa se o estado ainda pode ser usado: retomado no próximo
+// início (pendente ou em execução), pausado pela parada de emergência ou de um
+// job carregado que ainda não terminou
+func (cp *CollectionProcessor) stateProtected(state *StateFile) bool {
+	switch state.Status {
+	case StatusPending, StatusRunning, StatusPaused:
+		return true
+	}
+
+	cp.mutex.RLock()
+	job, loaded := cp.collections[state.JobID]
+	cp.mutex.RUnlock()
+	if !loaded {
+		return false
+	}
+	_, done := job.finished()
+	return !done
+}
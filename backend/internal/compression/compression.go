@@ -0,0 +1,235 @@
+// Package compression comprime as respostas HTTP (gzip ou deflate, conforme o
+// Accept-Encoding) e define os parâmetros do permessage-deflate do WebSocket.
+//
+// Respostas pequenas não compensam o custo: o corpo é mantido em buffer até
+// atingir Threshold e só então se decide comprimir. Upgrades de WebSocket,
+// respostas parciais (Range) e conteúdo já comprimido (imagens) passam direto.
+package compression
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultThreshold é o tamanho mínimo (bytes) de uma resposta ou mensagem comprimida
+const DefaultThreshold = 1024
+
+// Config é a seção "compression" da configuração
+type Config struct {
+	DisableHTTP      bool `json:"disableHttp,omitempty"`      // respostas HTTP sem gzip/deflate
+	DisableWebSocket bool `json:"disableWebSocket,omitempty"` // não negocia permessage-deflate
+	Threshold        int  `json:"threshold,omitempty"`        // bytes; padrão 1024
+	Level            int  `json:"level,omitempty"`            // 1 (rápido) a 9 (menor); 0 = padrão de cada protocolo
+}
+
+// Normalize aplica os padrões e corrige valores fora da faixa
+func (c Config) Normalize() Config {
+	if c.Threshold <= 0 {
+		c.Threshold = DefaultThreshold
+	}
+	if c.Level < 0 || c.Level > flate.BestCompression {
+		c.Level = 0
+	}
+	return c
+}
+
+// Handler comprime as respostas de next para clientes que aceitam gzip ou deflate
+func Handler(next http.Handler, config Config) http.Handler {
+	config = config.Normalize()
+	if config.DisableHTTP {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Conexões WebSocket são sequestradas (Hijack) e comprimem por mensagem
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiate(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &responseWriter{ResponseWriter: w, config: config, encoding: encoding, status: http.StatusOK}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiate escolhe gzip ou deflate a partir do Accept-Encoding (vazio = sem compressão)
+func negotiate(header string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				continue
+			}
+		}
+		accepted[name] = true
+	}
+	switch {
+	case accepted["gzip"]:
+		return "gzip"
+	case accepted["deflate"]:
+		return "deflate"
+	}
+	return ""
+}
+
+// compressible indica se vale comprimir o tipo de conteúdo: texto e formatos
+// estruturados sim; imagens, vídeos e arquivos compactados não
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/javascript", "application/xml",
+		"application/rss+xml", "application/atom+xml", "image/svg+xml":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+}
+
+// encoder é o gzip.Writer ou flate.Writer da resposta
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// responseWriter acumula o início da resposta até saber se ela vale a compressão
+type responseWriter struct {
+	http.ResponseWriter
+	config   Config
+	encoding string
+	status   int
+	buffer   []byte
+	decided  bool
+	encoder  encoder
+}
+
+// WriteHeader adia o status até a decisão, que pode mudar os cabeçalhos
+func (w *responseWriter) WriteHeader(status int) {
+	if w.decided {
+		return
+	}
+	w.status = status
+	// Respostas sem corpo (1xx, 204, 304) não têm o que esperar
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		w.decide(false)
+	}
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buffer = append(w.buffer, data...)
+		if len(w.buffer) < w.config.Threshold {
+			return len(data), nil
+		}
+		if err := w.decide(w.eligible()); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// eligible verifica se a resposta pode ser comprimida
+func (w *responseWriter) eligible() bool {
+	header := w.Header()
+	if w.status != http.StatusOK || header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buffer)
+	}
+	return compressible(contentType)
+}
+
+// decide envia os cabeçalhos e o que estava em buffer, comprimido ou não
+func (w *responseWriter) decide(compress bool) error {
+	w.decided = true
+	header := w.Header()
+	if compress {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", w.encoding)
+		// A representação comprimida tem outros bytes: o ETag forte vira fraco
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+
+		var err error
+		level := w.config.Level
+		if level == 0 {
+			level = flate.DefaultCompression
+		}
+		if w.encoding == "gzip" {
+			w.encoder, err = gzip.NewWriterLevel(w.ResponseWriter, level)
+		} else {
+			w.encoder, err = flate.NewWriter(w.ResponseWriter, level)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create %s writer: %v", w.encoding, err)
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buffered := w.buffer
+	w.buffer = nil
+	if len(buffered) == 0 {
+		return nil
+	}
+	_, err := w.Write(buffered)
+	return err
+}
+
+// Close encerra a resposta: corpos abaixo do limite saem sem compressão
+func (w *responseWriter) Close() error {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+	if w.encoder != nil {
+		return w.encoder.Close()
+	}
+	return nil
+}
+
+// Flush envia o que já foi escrito (streams longos não esperam o limite)
+func (w *responseWriter) Flush() {
+	if !w.decided {
+		w.decide(w.eligible())
+	}
+	if w.encoder != nil {
+		w.encoder.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack repassa o sequestro da conexão para handlers que precisam dele
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
@@ -0,0 +1,200 @@
+// Package cubari importa obras já publicadas no cubari.moe: resolve o link do
+// leitor para o JSON da obra (gist/raw do GitHub), baixa e converte para o
+// formato dos JSONs da biblioteca.
+package cubari
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"go-upload/backend/internal/metadata"
+)
+
+// MaxJSONSize limita o tamanho do JSON baixado
+const MaxJSONSize = 20 << 20
+
+// Series é uma obra lida do cubari.moe
+type Series struct {
+	SourceURL string              `json:"sourceUrl"` // link informado
+	JSONURL   string              `json:"jsonUrl"`   // JSON efetivamente baixado
+	Manga     *metadata.MangaJSON `json:"-"`
+	// Grupos que apontam para um proxy do cubari (imgur, mangadex...) em vez de
+	// uma lista de páginas; não têm URLs para importar
+	SkippedGroups []string `json:"skippedGroups,omitempty"`
+}
+
+// ResolveURL retorna o endereço do JSON de uma obra. Aceita links do leitor
+// (https://cubari.moe/read/gist/<código>/...) e URLs diretas do JSON.
+func ResolveURL(link string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("invalid cubari link: %q", link)
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	if host != "cubari.moe" {
+		return parsed.String(), nil
+	}
+
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "read" || parts[1] != "gist" {
+		return "", fmt.Errorf("unsupported cubari link %q: expected https://cubari.moe/read/gist/<code>/", link)
+	}
+	return decodeGist(parts[2])
+}
+
+// decodeGist converte o código de um link gist do cubari (base64 de
+// "raw/<usuário>/<repo>/<branch>/<arquivo>" ou "gist/<usuário>/<id>/raw/...")
+func decodeGist(code string) (string, error) {
+	code = strings.TrimRight(code, "=")
+	decoded, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil {
+		decoded, err = base64.RawStdEncoding.DecodeString(code)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unsupported cubari gist code %q (short links are no longer resolvable)", code)
+	}
+
+	path := string(decoded)
+	switch {
+	case strings.HasPrefix(path, "raw/"):
+		return "https://raw.githubusercontent.com/" + strings.TrimPrefix(path, "raw/"), nil
+	case strings.HasPrefix(path, "gist/"):
+		return "https://gist.githubusercontent.com/" + strings.TrimPrefix(path, "gist/"), nil
+	case strings.HasPrefix(path, "https://"), strings.HasPrefix(path, "http://"):
+		return path, nil
+	}
+	return "", fmt.Errorf("unsupported cubari gist source %q", path)
+}
+
+// Fetch baixa e converte a obra de um link do cubari
+func Fetch(ctx context.Context, client *http.Client, link string) (*Series, error) {
+	jsonURL, err := ResolveURL(link)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jsonURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cubari JSON URL: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %v", jsonURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: HTTP %d", jsonURL, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxJSONSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", jsonURL, err)
+	}
+	if len(data) > MaxJSONSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", jsonURL, MaxJSONSize)
+	}
+
+	series, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	series.SourceURL = link
+	series.JSONURL = jsonURL
+	return series, nil
+}
+
+// Parse converte um JSON no formato do cubari. Números em volume e
+// last_updated são aceitos, e grupos de proxy (texto em vez de lista) são
+// listados em SkippedGroups.
+func Parse(data []byte) (*Series, error) {
+	var raw struct {
+		Title       flexString `json:"title"`
+		Description flexString `json:"description"`
+		Artist      flexString `json:"artist"`
+		Author      flexString `json:"author"`
+		Cover       flexString `json:"cover"`
+		Status      flexString `json:"status"`
+		Chapters    map[string]struct {
+			Title       flexString                 `json:"title"`
+			Volume      flexString                 `json:"volume"`
+			LastUpdated flexString                 `json:"last_updated"`
+			Groups      map[string]json.RawMessage `json:"groups"`
+		} `json:"chapters"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid cubari JSON: %v", err)
+	}
+	if len(raw.Chapters) == 0 {
+		return nil, fmt.Errorf("cubari JSON has no chapters")
+	}
+
+	manga := &metadata.MangaJSON{
+		Title:       string(raw.Title),
+		Description: string(raw.Description),
+		Artist:      string(raw.Artist),
+		Author:      string(raw.Author),
+		Cover:       string(raw.Cover),
+		Status:      string(raw.Status),
+		Chapters:    make(map[string]metadata.Chapter),
+	}
+	series := &Series{Manga: manga}
+	skipped := make(map[string]bool)
+	for key, chapter := range raw.Chapters {
+		groups := make(map[string][]string)
+		for group, value := range chapter.Groups {
+			var pages []string
+			if err := json.Unmarshal(value, &pages); err != nil || len(pages) == 0 {
+				skipped[group] = true
+				continue
+			}
+			groups[group] = pages
+		}
+		if len(groups) == 0 {
+			continue
+		}
+		manga.Chapters[key] = metadata.Chapter{
+			Title:       string(chapter.Title),
+			Volume:      string(chapter.Volume),
+			LastUpdated: string(chapter.LastUpdated),
+			Groups:      groups,
+		}
+	}
+	if len(manga.Chapters) == 0 {
+		return nil, fmt.Errorf("cubari JSON has no chapters with page lists (proxy chapters cannot be imported)")
+	}
+	for group := range skipped {
+		series.SkippedGroups = append(series.SkippedGroups, group)
+	}
+	sort.Strings(series.SkippedGroups)
+	return series, nil
+}
+
+// flexString aceita texto, número ou null
+type flexString string
+
+func (s *flexString) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*s = ""
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		*s = flexString(text)
+		return nil
+	}
+	*s = flexString(data)
+	return nil
+}
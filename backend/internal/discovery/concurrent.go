@@ -8,6 +8,8 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+
+	"go-upload/backend/internal/sysfiles"
 )
 
 // LibraryNode representa um nó na árvore da biblioteca
@@ -26,6 +28,7 @@ type HierarchyMetadata struct {
 	TotalLevels  int               `json:"totalLevels"`
 	LevelMap     map[string]string `json:"levelMap"`
 	Stats        HierarchyStats    `json:"stats"`
+	Symlinks     []SymlinkEntry    `json:"symlinks,omitempty"` // links simbólicos encontrados
 }
 
 // HierarchyStats contém estatísticas sobre a biblioteca
@@ -47,9 +50,11 @@ type ProgressCallback func(processed, total int, currentPath string)
 
 // ConcurrentDiscoverer realiza descoberta de estrutura paralela
 type ConcurrentDiscoverer struct {
-	maxWorkers int
-	ctx        context.Context
-	cancel     context.CancelFunc
+	maxWorkers    int
+	symlinks      SymlinkMode
+	includeHidden bool // não ignorar arquivos de sistema e ocultos
+	ctx           context.Context
+	cancel        context.CancelFunc
 }
 
 // NewConcurrentDiscoverer cria um novo descobridor concorrente
@@ -62,15 +67,30 @@ func NewConcurrentDiscoverer(maxWorkers int) *ConcurrentDiscoverer {
 	
 	return &ConcurrentDiscoverer{
 		maxWorkers: maxWorkers,
+		symlinks:   SymlinkFollow,
 		ctx:        ctx,
 		cancel:     cancel,
 	}
 }
 
+// SetIncludeHidden inclui arquivos de sistema e ocultos (.DS_Store, Thumbs.db,
+// dotfiles...), normalmente ignorados
+func (cd *ConcurrentDiscoverer) SetIncludeHidden(include bool) {
+	cd.includeHidden = include
+}
+
+// skip informa se uma entrada de diretório deve ser ignorada
+func (cd *ConcurrentDiscoverer) skip(path string) bool {
+	return !cd.includeHidden && sysfiles.Skip(path)
+}
+
 // directoryJob representa um trabalho de processamento de diretório
 type directoryJob struct {
 	path     string
+	realPath string // caminho com os links resolvidos (detecção de ciclos)
+	symlink  string // destino, se a pasta foi alcançada por um link
 	depth    int
+	walk     *walkState
 	parentCh chan<- directoryResult
 }
 
@@ -79,7 +99,7 @@ type directoryResult struct {
 	path     string
 	node     LibraryNode
 	files    []string
-	subdirs  []string
+	subdirs  []directoryJob
 	depth    int
 	err      error
 }
@@ -94,17 +114,28 @@ func (cd *ConcurrentDiscoverer) DiscoverFirstLevel(startPath string, progressCb
 	tree := make(LibraryNode)
 	processedCount := 0
 	totalCount := 0
+	walk := newWalkState(cd.symlinks, startPath)
 
-	// Contar diretórios primeiro
+	// Contar diretórios primeiro (links seguidos contam como diretórios)
+	isDir := make(map[string]bool)
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if cd.skip(filepath.Join(startPath, entry.Name())) {
+			continue
+		}
+		if entry.Type()&os.ModeSymlink != 0 {
+			follow, _, dir := walk.resolve(filepath.Join(startPath, entry.Name()), walk.realRoot)
+			isDir[entry.Name()] = follow && dir
+		} else {
+			isDir[entry.Name()] = entry.IsDir()
+		}
+		if isDir[entry.Name()] {
 			totalCount++
 		}
 	}
 
 	// Processar apenas diretórios do primeiro nível
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if isDir[entry.Name()] {
 			dirPath := filepath.Join(startPath, entry.Name())
 			
 			// Verificar se é um diretório de manga (contém subdiretórios de capítulos)
@@ -115,7 +146,7 @@ func (cd *ConcurrentDiscoverer) DiscoverFirstLevel(startPath string, progressCb
 
 			hasSubdirs := false
 			for _, subEntry := range subEntries {
-				if subEntry.IsDir() {
+				if subEntry.IsDir() && !cd.skip(filepath.Join(dirPath, subEntry.Name())) {
 					hasSubdirs = true
 					break
 				}
@@ -147,6 +178,7 @@ func (cd *ConcurrentDiscoverer) DiscoverFirstLevel(startPath string, progressCb
 			TotalImages:      0, // Não contamos aqui para performance
 			TotalChapters:    0,
 		},
+		Symlinks: walk.entries(),
 	}
 
 	return &DiscoveryResult{
@@ -168,25 +200,16 @@ func (cd *ConcurrentDiscoverer) DiscoverStructure(startPath string, progressCb P
 		go cd.worker(jobs, results, &wg)
 	}
 
-	// Goroutine para coletar resultados
+	// Resultados são coletados pelo loop abaixo, que também enfileira os subdiretórios
 	resultMap := make(map[string]directoryResult)
-	var resultWg sync.WaitGroup
-	resultWg.Add(1)
-	
-	go func() {
-		defer resultWg.Done()
-		for result := range results {
-			if result.err != nil {
-				continue // Log error but continue processing
-			}
-			resultMap[result.path] = result
-		}
-	}()
 
 	// Descobrir estrutura inicial
+	walk := newWalkState(cd.symlinks, startPath)
 	initialJob := directoryJob{
 		path:     startPath,
+		realPath: walk.realRoot,
 		depth:    0,
+		walk:     walk,
 		parentCh: results,
 	}
 	
@@ -209,8 +232,6 @@ func (cd *ConcurrentDiscoverer) DiscoverStructure(startPath string, progressCb P
 			case <-cd.ctx.Done():
 				close(jobs)
 				wg.Wait()
-				close(results)
-				resultWg.Wait()
 				return nil, cd.ctx.Err()
 			}
 		}
@@ -233,19 +254,14 @@ func (cd *ConcurrentDiscoverer) DiscoverStructure(startPath string, progressCb P
 				
 				// Adicionar subdiretórios à fila de trabalhos
 				for _, subdir := range result.subdirs {
-					pendingJobs = append(pendingJobs, directoryJob{
-						path:     subdir,
-						depth:    result.depth + 1,
-						parentCh: results,
-					})
+					subdir.parentCh = results
+					pendingJobs = append(pendingJobs, subdir)
 					totalEstimate++
 				}
 				
 			case <-cd.ctx.Done():
 				close(jobs)
 				wg.Wait()
-				close(results)
-				resultWg.Wait()
 				return nil, cd.ctx.Err()
 			}
 		}
@@ -254,8 +270,6 @@ func (cd *ConcurrentDiscoverer) DiscoverStructure(startPath string, progressCb P
 	// Fechar canais e aguardar conclusão
 	close(jobs)
 	wg.Wait()
-	close(results)
-	resultWg.Wait()
 
 	// Construir árvore final
 	tree, err := cd.buildTree(startPath, resultMap)
@@ -265,6 +279,7 @@ func (cd *ConcurrentDiscoverer) DiscoverStructure(startPath string, progressCb P
 
 	// Analisar hierarquia
 	metadata := cd.analyzeHierarchy(tree)
+	metadata.Symlinks = walk.entries()
 
 	return &DiscoveryResult{
 		Tree:     tree,
@@ -304,11 +319,34 @@ func (cd *ConcurrentDiscoverer) processDirectory(job directoryJob) directoryResu
 	}
 
 	var files []string
-	var subdirs []string
+	var subdirs []directoryJob
 
 	for _, entry := range entries {
-		if entry.IsDir() {
-			subdirs = append(subdirs, filepath.Join(job.path, entry.Name()))
+		path := filepath.Join(job.path, entry.Name())
+		if cd.skip(path) {
+			continue
+		}
+		dirJob := directoryJob{
+			path:     path,
+			realPath: filepath.Join(job.realPath, entry.Name()),
+			depth:    job.depth + 1,
+			walk:     job.walk,
+		}
+		isDir := entry.IsDir()
+
+		// Links só entram se o modo permitir e não formarem ciclo ou duplicata
+		if entry.Type()&os.ModeSymlink != 0 {
+			follow, target, dir := job.walk.resolve(path, job.realPath)
+			if !follow {
+				continue
+			}
+			isDir = dir
+			dirJob.realPath = target
+			dirJob.symlink = target
+		}
+
+		if isDir {
+			subdirs = append(subdirs, dirJob)
 		} else if SupportedExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
 			files = append(files, entry.Name())
 		}
@@ -318,6 +356,9 @@ func (cd *ConcurrentDiscoverer) processDirectory(job directoryJob) directoryResu
 	if len(files) > 0 {
 		node["_files"] = files
 	}
+	if job.symlink != "" {
+		node["_symlink"] = job.symlink
+	}
 
 	return directoryResult{
 		path:    job.path,
@@ -363,8 +404,13 @@ func (cd *ConcurrentDiscoverer) buildTree(startPath string, resultMap map[string
 			}
 		}
 		
-		// Adicionar nó atual
+		// Adicionar nó atual, preservando subdiretórios que chegaram antes dele
 		dirName := parts[len(parts)-1]
+		if existing, ok := currentNode[dirName].(LibraryNode); ok {
+			for k, v := range existing {
+				result.node[k] = v
+			}
+		}
 		currentNode[dirName] = result.node
 	}
 	
@@ -458,6 +504,23 @@ func (cd *ConcurrentDiscoverer) calculateStats(node LibraryNode, stats *Hierarch
 	}
 }
 
+// FilePaths retorna os caminhos completos de todas as imagens de uma árvore descoberta
+func FilePaths(root string, node LibraryNode) []string {
+	var paths []string
+	for key, value := range node {
+		if key == "_files" {
+			if files, ok := value.([]string); ok {
+				for _, file := range files {
+					paths = append(paths, filepath.Join(root, file))
+				}
+			}
+		} else if subNode, ok := value.(LibraryNode); ok {
+			paths = append(paths, FilePaths(filepath.Join(root, key), subNode)...)
+		}
+	}
+	return paths
+}
+
 // Close cancela operações em andamento
 func (cd *ConcurrentDiscoverer) Close() {
 	cd.cancel()
@@ -0,0 +1,184 @@
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SymlinkMode define o que a descoberta faz com links simbólicos da biblioteca
+type SymlinkMode string
+
+const (
+	SymlinkFollow SymlinkMode = "follow" // segue links para pastas e imagens, com detecção de ciclos
+	SymlinkIgnore SymlinkMode = "ignore" // pula os links (apenas reportados nos metadados)
+)
+
+// Situação de um link encontrado na descoberta
+const (
+	SymlinkFollowed  = "followed"  // conteúdo incluído na árvore
+	SymlinkIgnored   = "ignored"   // modo ignore
+	SymlinkCycle     = "cycle"     // aponta para uma pasta acima dele (recursão infinita)
+	SymlinkDuplicate = "duplicate" // destino já descoberto por outro caminho (contaria em dobro)
+	SymlinkBroken    = "broken"    // destino inexistente ou inacessível
+)
+
+// SymlinkEntry é um link simbólico encontrado na descoberta
+type SymlinkEntry struct {
+	Path   string `json:"path"`             // caminho relativo à pasta descoberta
+	Target string `json:"target,omitempty"` // destino resolvido
+	Dir    bool   `json:"dir"`              // destino é uma pasta
+	Status string `json:"status"`
+}
+
+// ParseSymlinkMode valida o modo de links simbólicos; vazio = follow
+func ParseSymlinkMode(value string) (SymlinkMode, error) {
+	switch mode := SymlinkMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return SymlinkFollow, nil
+	case SymlinkFollow, SymlinkIgnore:
+		return mode, nil
+	}
+	return SymlinkFollow, fmt.Errorf("invalid symlink mode %q (use follow or ignore)", value)
+}
+
+// SetSymlinkMode define o tratamento de links simbólicos (chamar antes de descobrir)
+func (cd *ConcurrentDiscoverer) SetSymlinkMode(mode SymlinkMode) {
+	if mode == "" {
+		mode = SymlinkFollow
+	}
+	cd.symlinks = mode
+}
+
+// walkState acompanha os links de uma descoberta: as pastas já percorridas (pelo
+// caminho real) e os links encontrados
+type walkState struct {
+	mode     SymlinkMode
+	root     string // pasta descoberta, como pedida
+	realRoot string // pasta descoberta com os links resolvidos
+
+	mutex    sync.Mutex
+	followed []string // caminhos reais das árvores já percorridas (raiz e links seguidos)
+	symlinks []SymlinkEntry
+}
+
+// newWalkState prepara o acompanhamento de links de uma descoberta em root
+func newWalkState(mode SymlinkMode, root string) *walkState {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		realRoot = root
+	}
+	if absolute, err := filepath.Abs(realRoot); err == nil {
+		realRoot = absolute
+	}
+	return &walkState{
+		mode:     mode,
+		root:     root,
+		realRoot: realRoot,
+		followed: []string{realRoot},
+	}
+}
+
+// visit marca a árvore em realPath como percorrida; false se ela já está
+// dentro de uma árvore percorrida (seria contada em dobro)
+func (ws *walkState) visit(realPath string) bool {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+	if ws.coveredLocked(realPath) {
+		return false
+	}
+	ws.followed = append(ws.followed, realPath)
+	return true
+}
+
+// covered informa se realPath está dentro de uma árvore já percorrida
+func (ws *walkState) covered(realPath string) bool {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+	return ws.coveredLocked(realPath)
+}
+
+// coveredLocked é covered com o lock já adquirido
+func (ws *walkState) coveredLocked(realPath string) bool {
+	for _, followed := range ws.followed {
+		if within(realPath, followed) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve examina o link path, dentro da pasta cujo caminho real é realParent, e
+// informa se ele deve ser seguido, o destino resolvido e se é uma pasta
+func (ws *walkState) resolve(path, realParent string) (follow bool, target string, dir bool) {
+	entry := SymlinkEntry{Path: ws.relative(path)}
+	defer func() { ws.record(entry) }()
+
+	target, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		target, err = filepath.Abs(target)
+	}
+	var info os.FileInfo
+	if err == nil {
+		info, err = os.Stat(target)
+	}
+	if err != nil {
+		if raw, readErr := os.Readlink(path); readErr == nil {
+			entry.Target = raw
+		}
+		entry.Status = SymlinkBroken
+		return false, "", false
+	}
+	entry.Target = target
+	entry.Dir = info.IsDir()
+
+	switch {
+	case ws.mode == SymlinkIgnore:
+		entry.Status = SymlinkIgnored
+	case !entry.Dir && ws.covered(target):
+		// A imagem de destino já entra pelo próprio caminho
+		entry.Status = SymlinkDuplicate
+	case !entry.Dir:
+		entry.Status = SymlinkFollowed
+	case within(realParent, target):
+		entry.Status = SymlinkCycle
+	case !ws.visit(target):
+		// Pastas da própria biblioteca são descobertas pelo caminho real
+		entry.Status = SymlinkDuplicate
+	default:
+		entry.Status = SymlinkFollowed
+	}
+	return entry.Status == SymlinkFollowed, target, entry.Dir
+}
+
+// record guarda um link encontrado
+func (ws *walkState) record(entry SymlinkEntry) {
+	ws.mutex.Lock()
+	ws.symlinks = append(ws.symlinks, entry)
+	ws.mutex.Unlock()
+}
+
+// relative retorna path relativo à pasta descoberta
+func (ws *walkState) relative(path string) string {
+	if relative, err := filepath.Rel(ws.root, path); err == nil {
+		return filepath.ToSlash(relative)
+	}
+	return path
+}
+
+// entries retorna os links encontrados, ordenados pelo caminho
+func (ws *walkState) entries() []SymlinkEntry {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+	entries := append([]SymlinkEntry(nil), ws.symlinks...)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries
+}
+
+// within informa se path é dir ou está dentro dele
+func within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(os.PathSeparator))+string(os.PathSeparator))
+}
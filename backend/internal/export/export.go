@@ -0,0 +1,408 @@
+// Package export baixa as páginas hospedadas de um capítulo (URLs do JSON da
+// obra) para uma pasta local ou um arquivo CBZ, para reedição, arquivamento ou
+// migração quando os arquivos originais se perderam.
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-upload/backend/internal/metadata"
+	"go-upload/backend/internal/retry"
+)
+
+// Formatos de exportação
+const (
+	FormatFolder = "folder"
+	FormatCBZ    = "cbz"
+)
+
+// Padrões do download
+const (
+	DefaultConcurrency = 4
+	MaxPageSize        = 100 << 20
+)
+
+// Chapter identifica as páginas de um capítulo no JSON da obra
+type Chapter struct {
+	Key   string   `json:"key"`   // chave do capítulo no JSON ("012")
+	Title string   `json:"title"` // título do capítulo
+	Group string   `json:"group"` // grupo cujas URLs são baixadas
+	Pages []string `json:"-"`
+	// Mirrors são as URLs dos espelhos do grupo, na mesma ordem das páginas,
+	// tentadas quando o host principal falha
+	Mirrors [][]string `json:"-"`
+}
+
+// PageError é uma página que não pôde ser baixada de nenhum host
+type PageError struct {
+	Page  int    `json:"page"` // 1 = primeira página
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// Result descreve uma exportação concluída
+type Result struct {
+	Path     string      `json:"path"`
+	Format   string      `json:"format"`
+	Pages    int         `json:"pages"` // páginas gravadas
+	Total    int         `json:"total"`
+	Bytes    int64       `json:"bytes"`
+	Mirrored int         `json:"mirrored,omitempty"` // páginas baixadas de um espelho
+	Failed   []PageError `json:"failed,omitempty"`
+	Duration string      `json:"duration"`
+}
+
+// Progress é chamado a cada página terminada
+type Progress func(done, total int)
+
+// LoadChapter lê o capítulo do JSON de uma obra. chapter aceita a chave do JSON
+// ("012") ou o número ("12"); group vazio escolhe o grupo principal (o primeiro,
+// em ordem alfabética, que não é espelho de outro).
+func LoadChapter(jsonPath, chapter, group string) (*Chapter, error) {
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON file: %v", err)
+	}
+	var manga metadata.MangaJSON
+	if err := json.Unmarshal(data, &manga); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	key, found := chapterKey(manga.Chapters, chapter)
+	if !found {
+		return nil, fmt.Errorf("chapter %s not found in %s", chapter, filepath.Base(jsonPath))
+	}
+	entry := manga.Chapters[key]
+
+	if group == "" {
+		group = mainGroup(entry)
+	}
+	pages, exists := entry.Groups[group]
+	if !exists || len(pages) == 0 {
+		return nil, fmt.Errorf("chapter %s has no pages for group %q", key, group)
+	}
+
+	result := &Chapter{Key: key, Title: entry.Title, Group: group, Pages: pages}
+	for _, name := range metadata.MirrorSets(entry)[group] {
+		if name != group && len(entry.Groups[name]) == len(pages) {
+			result.Mirrors = append(result.Mirrors, entry.Groups[name])
+		}
+	}
+	return result, nil
+}
+
+// chapterKey encontra a chave do capítulo, com ou sem zeros à esquerda
+func chapterKey(chapters map[string]metadata.Chapter, chapter string) (string, bool) {
+	if _, exists := chapters[chapter]; exists {
+		return chapter, true
+	}
+	trimmed := strings.TrimLeft(chapter, "0")
+	for key := range chapters {
+		if strings.TrimLeft(key, "0") == trimmed {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// mainGroup escolhe o grupo principal de um capítulo
+func mainGroup(chapter metadata.Chapter) string {
+	names := make([]string, 0, len(chapter.Groups))
+	for name := range chapter.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, _, mirror := metadata.ParseMirrorGroup(name); !mirror {
+			return name
+		}
+	}
+	if len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// Exporter baixa capítulos com concorrência limitada
+type Exporter struct {
+	Client      *http.Client
+	Concurrency int // downloads simultâneos (padrão DefaultConcurrency)
+	Retry       retry.Policy
+}
+
+// NewExporter cria um exportador com timeout por página e retries com backoff
+func NewExporter() *Exporter {
+	return &Exporter{
+		Client:      &http.Client{Timeout: 2 * time.Minute},
+		Concurrency: DefaultConcurrency,
+		Retry: retry.Policy{
+			MaxRetries: 2,
+			BaseDelay:  time.Second,
+			Jitter:     retry.DefaultJitter,
+		},
+	}
+}
+
+// Export baixa as páginas do capítulo para dest: uma pasta (FormatFolder) ou
+// um arquivo .cbz (FormatCBZ). Páginas são nomeadas 001.jpg, 002.png...; um
+// CBZ só é gravado se todas as páginas forem baixadas, uma pasta fica com as
+// que deram certo. Um destino existente só é substituído com overwrite.
+func (e *Exporter) Export(ctx context.Context, chapter *Chapter, format, dest string, overwrite bool, progress Progress) (*Result, error) {
+	start := time.Now()
+	if format == "" {
+		format = FormatFolder
+	}
+	if format != FormatFolder && format != FormatCBZ {
+		return nil, fmt.Errorf("unsupported export format %q (use %s or %s)", format, FormatFolder, FormatCBZ)
+	}
+	if _, err := os.Stat(dest); err == nil && !overwrite {
+		return nil, fmt.Errorf("%s already exists", dest)
+	}
+
+	// As páginas vão para uma pasta temporária ao lado do destino e só então
+	// viram a pasta final ou são empacotadas
+	parent := filepath.Dir(dest)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %v", err)
+	}
+	work, err := os.MkdirTemp(parent, ".export-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %v", err)
+	}
+	defer os.RemoveAll(work)
+
+	result := &Result{Path: dest, Format: format, Total: len(chapter.Pages)}
+	files := e.download(ctx, chapter, work, result, progress)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return result, fmt.Errorf("no page of chapter %s could be downloaded", chapter.Key)
+	}
+
+	switch format {
+	case FormatCBZ:
+		if len(result.Failed) > 0 {
+			return result, fmt.Errorf("%d of %d pages failed to download", len(result.Failed), result.Total)
+		}
+		if err := writeCBZ(dest, work, files, overwrite); err != nil {
+			return result, err
+		}
+	default:
+		if overwrite {
+			if err := os.RemoveAll(dest); err != nil {
+				return result, fmt.Errorf("failed to replace %s: %v", dest, err)
+			}
+		}
+		if err := os.Rename(work, dest); err != nil {
+			return result, fmt.Errorf("failed to move pages to %s: %v", dest, err)
+		}
+	}
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	return result, nil
+}
+
+// download baixa as páginas em paralelo e retorna os arquivos gravados em ordem
+func (e *Exporter) download(ctx context.Context, chapter *Chapter, dir string, result *Result, progress Progress) []string {
+	concurrency := e.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	total := len(chapter.Pages)
+	width := max(3, len(fmt.Sprint(total)))
+
+	files := make([]string, total)
+	sizes := make([]int64, total)
+	errs := make([]error, total)
+	mirrored := make([]bool, total)
+	var done int64
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				name := fmt.Sprintf("%0*d", width, i+1)
+				sources := []string{chapter.Pages[i]}
+				for _, mirror := range chapter.Mirrors {
+					sources = append(sources, mirror[i])
+				}
+				for n, source := range sources {
+					files[i], sizes[i], errs[i] = e.fetchPage(ctx, source, dir, name)
+					if errs[i] == nil {
+						mirrored[i] = n > 0
+						break
+					}
+				}
+				if progress != nil {
+					progress(int(atomic.AddInt64(&done, 1)), total)
+				}
+			}
+		}()
+	}
+	for i := range chapter.Pages {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	var written []string
+	for i := range chapter.Pages {
+		if errs[i] != nil || files[i] == "" {
+			if errs[i] != nil {
+				result.Failed = append(result.Failed, PageError{Page: i + 1, URL: chapter.Pages[i], Error: errs[i].Error()})
+			}
+			continue
+		}
+		written = append(written, files[i])
+		result.Pages++
+		result.Bytes += sizes[i]
+		if mirrored[i] {
+			result.Mirrored++
+		}
+	}
+	return written
+}
+
+// fetchPage baixa uma página com retries e a grava como dir/name.<ext>
+func (e *Exporter) fetchPage(ctx context.Context, pageURL, dir, name string) (string, int64, error) {
+	retrier := &retry.Retrier{Policy: e.Retry, Name: "export"}
+	var file string
+	var size int64
+	_, err := retrier.Do(ctx, func(int) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+		if err != nil {
+			return retry.Permanent(fmt.Errorf("invalid page URL: %v", err))
+		}
+		resp, err := e.Client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			err := fmt.Errorf("HTTP %d", resp.StatusCode)
+			if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+				return retry.Permanent(err)
+			}
+			return err
+		}
+
+		file = filepath.Join(dir, name+pageExtension(pageURL, resp.Header.Get("Content-Type")))
+		out, err := os.Create(file)
+		if err != nil {
+			return retry.Permanent(fmt.Errorf("failed to create %s: %v", filepath.Base(file), err))
+		}
+		size, err = io.Copy(out, io.LimitReader(resp.Body, MaxPageSize+1))
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil && size > MaxPageSize {
+			err = retry.Permanent(fmt.Errorf("page is larger than %d bytes", MaxPageSize))
+		}
+		if err != nil {
+			os.Remove(file)
+		}
+		return err
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return file, size, nil
+}
+
+// pageExtension escolhe a extensão pela URL ou, sem ela, pelo Content-Type
+func pageExtension(pageURL, contentType string) string {
+	ext := strings.ToLower(path.Ext(strings.SplitN(strings.SplitN(pageURL, "?", 2)[0], "#", 2)[0]))
+	switch ext {
+	case ".jpg", ".jpeg", ".png", ".webp", ".gif", ".avif":
+		return ext
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch mediaType {
+		case "image/jpeg":
+			return ".jpg"
+		case "image/png":
+			return ".png"
+		case "image/webp":
+			return ".webp"
+		case "image/gif":
+			return ".gif"
+		case "image/avif":
+			return ".avif"
+		}
+	}
+	return ".jpg"
+}
+
+// writeCBZ empacota as páginas sem recompressão (imagens já são comprimidas)
+func writeCBZ(dest, dir string, files []string, overwrite bool) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".export-*.cbz")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", dest, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	archive := zip.NewWriter(tmp)
+	for _, file := range files {
+		if err := addToZip(archive, file); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %v", dest, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %v", dest, err)
+	}
+
+	if overwrite {
+		os.Remove(dest)
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return fmt.Errorf("failed to write %s: %v", dest, err)
+	}
+	return nil
+}
+
+func addToZip(archive *zip.Writer, file string) error {
+	in, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to read page %s: %v", filepath.Base(file), err)
+	}
+	defer in.Close()
+
+	header := &zip.FileHeader{Name: filepath.Base(file), Method: zip.Store}
+	header.Modified = time.Now()
+	out, err := archive.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to add page %s: %v", filepath.Base(file), err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		return fmt.Errorf("failed to add page %s: %v", filepath.Base(file), err)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/metadata"
+)
+
+// ErrNotFound é retornado quando a obra pedida não tem JSON
+var ErrNotFound = errors.New("obra não encontrada")
+
+// Config define o conteúdo dos feeds
+type Config struct {
+	Title     string `json:"title,omitempty"`     // Título do feed geral (padrão "Lançamentos")
+	SiteURL   string `json:"siteUrl,omitempty"`   // Link do canal (site do grupo)
+	ReaderURL string `json:"readerUrl,omitempty"` // Link de cada capítulo; aceita {manga} e {chapter}
+	MaxItems  int    `json:"maxItems,omitempty"`  // Itens por feed (padrão 50)
+	JSONDir   string `json:"-"`                   // diretório dos JSONs da biblioteca
+}
+
+// Item é um capítulo publicado
+type Item struct {
+	MangaID   string
+	Manga     string
+	Chapter   string
+	Title     string
+	Link      string
+	Pages     int
+	Groups    []string
+	Published time.Time
+}
+
+// cachedFile guarda os itens de um JSON até ele ser modificado
+type cachedFile struct {
+	modTime time.Time
+	manga   metadata.MangaJSON
+	items   []Item
+}
+
+// Generator monta os feeds RSS a partir dos JSONs, relendo só os arquivos alterados
+type Generator struct {
+	config Config
+
+	mutex sync.Mutex
+	cache map[string]*cachedFile
+}
+
+// NewGenerator cria o gerador de feeds
+func NewGenerator(config Config) *Generator {
+	if config.Title == "" {
+		config.Title = "Lançamentos"
+	}
+	if config.MaxItems <= 0 {
+		config.MaxItems = 50
+	}
+	return &Generator{config: config, cache: make(map[string]*cachedFile)}
+}
+
+// Library gera o feed com os capítulos mais recentes de toda a biblioteca
+func (g *Generator) Library() ([]byte, time.Time, error) {
+	files, err := filepath.Glob(filepath.Join(g.config.JSONDir, "*.json"))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var items []Item
+	seen := make(map[string]bool)
+	for _, file := range files {
+		seen[file] = true
+		entry, err := g.load(file)
+		if err != nil {
+			continue // JSON inválido não derruba o feed inteiro
+		}
+		items = append(items, entry.items...)
+	}
+	g.prune(seen)
+
+	channel := rssChannel{
+		Title:       g.config.Title,
+		Link:        g.config.SiteURL,
+		Description: g.config.Title,
+	}
+	return g.render(channel, items)
+}
+
+// Manga gera o feed de uma obra (ErrNotFound se não houver JSON)
+func (g *Generator) Manga(mangaID string) ([]byte, time.Time, error) {
+	if mangaID == "" || mangaID != filepath.Base(mangaID) || strings.HasPrefix(mangaID, ".") {
+		return nil, time.Time{}, ErrNotFound
+	}
+	entry, err := g.load(filepath.Join(g.config.JSONDir, mangaID+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, time.Time{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	channel := rssChannel{
+		Title:       entry.manga.Title,
+		Link:        g.config.SiteURL,
+		Description: entry.manga.Description,
+	}
+	if channel.Description == "" {
+		channel.Description = entry.manga.Title
+	}
+	if entry.manga.Cover != "" {
+		channel.Image = &rssImage{URL: entry.manga.Cover, Title: entry.manga.Title, Link: g.config.SiteURL}
+	}
+	return g.render(channel, entry.items)
+}
+
+// load retorna os itens de um JSON, relendo-o apenas se foi modificado
+func (g *Generator) load(path string) (*cachedFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mutex.Lock()
+	entry, cached := g.cache[path]
+	g.mutex.Unlock()
+	if cached && entry.modTime.Equal(info.ModTime()) {
+		return entry, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manga metadata.MangaJSON
+	if err := json.Unmarshal(raw, &manga); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	entry = &cachedFile{modTime: info.ModTime(), manga: manga, items: g.items(metadata.LockKey(path), manga)}
+	g.mutex.Lock()
+	g.cache[path] = entry
+	g.mutex.Unlock()
+	return entry, nil
+}
+
+// prune descarta do cache os JSONs que não existem mais
+func (g *Generator) prune(seen map[string]bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	for path := range g.cache {
+		if !seen[path] {
+			delete(g.cache, path)
+		}
+	}
+}
+
+// items converte os capítulos de uma obra em itens do feed
+func (g *Generator) items(mangaID string, manga metadata.MangaJSON) []Item {
+	items := make([]Item, 0, len(manga.Chapters))
+	for key, chapter := range manga.Chapters {
+		seconds, err := strconv.ParseInt(chapter.LastUpdated, 10, 64)
+		if err != nil || seconds <= 0 {
+			continue
+		}
+
+		item := Item{
+			MangaID:   mangaID,
+			Manga:     manga.Title,
+			Chapter:   key,
+			Title:     fmt.Sprintf("%s — Capítulo %s", manga.Title, key),
+			Published: time.Unix(seconds, 0).UTC(),
+		}
+		if chapter.Title != "" {
+			item.Title += ": " + chapter.Title
+		}
+
+		groups := make([]string, 0, len(chapter.Groups))
+		for group := range chapter.Groups {
+			groups = append(groups, group)
+		}
+		sort.Strings(groups)
+		for _, group := range groups {
+			if _, _, mirror := metadata.ParseMirrorGroup(group); mirror {
+				continue
+			}
+			item.Groups = append(item.Groups, group)
+			if pages := len(chapter.Groups[group]); pages > item.Pages {
+				item.Pages = pages
+			}
+			if item.Link == "" && len(chapter.Groups[group]) > 0 {
+				item.Link = chapter.Groups[group][0]
+			}
+		}
+
+		if g.config.ReaderURL != "" {
+			item.Link = strings.NewReplacer(
+				"{manga}", url.PathEscape(mangaID),
+				"{chapter}", url.PathEscape(key),
+			).Replace(g.config.ReaderURL)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// render ordena os itens do mais recente ao mais antigo e gera o RSS 2.0;
+// retorna também a data do item mais recente (Last-Modified)
+func (g *Generator) render(channel rssChannel, items []Item) ([]byte, time.Time, error) {
+	items = append([]Item(nil), items...) // os itens em cache são compartilhados entre requisições
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].Published.Equal(items[j].Published) {
+			return items[i].Published.After(items[j].Published)
+		}
+		return items[i].Title < items[j].Title
+	})
+	if len(items) > g.config.MaxItems {
+		items = items[:g.config.MaxItems]
+	}
+
+	var updated time.Time
+	if len(items) > 0 {
+		updated = items[0].Published
+		channel.LastBuildDate = updated.Format(time.RFC1123Z)
+	}
+	for _, item := range items {
+		description := fmt.Sprintf("%d páginas", item.Pages)
+		if item.Pages == 1 {
+			description = "1 página"
+		}
+		if len(item.Groups) > 0 {
+			description += " — " + strings.Join(item.Groups, ", ")
+		}
+		channel.Items = append(channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: description,
+			Category:    item.Manga,
+			GUID:        rssGUID{Value: item.MangaID + "/" + item.Chapter, IsPermaLink: false},
+			PubDate:     item.Published.Format(time.RFC1123Z),
+		})
+	}
+
+	body, err := xml.MarshalIndent(rssDocument{Version: "2.0", Channel: channel}, "", "  ")
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return append([]byte(xml.Header), body...), updated, nil
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Image         *rssImage `xml:"image,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssImage struct {
+	URL   string `xml:"url"`
+	Title string `xml:"title"`
+	Link  string `xml:"link"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	Description string  `xml:"description"`
+	Category    string  `xml:"category,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
@@ -0,0 +1,244 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// BootstrapOptions configures the repository created by Bootstrap
+type BootstrapOptions struct {
+	Private     bool   // visibility of a newly created repository
+	Description string // repository description; a default one when empty
+}
+
+// BootstrapResult reports what Bootstrap had to create
+type BootstrapResult struct {
+	Repo          string   `json:"repo"`
+	Branch        string   `json:"branch"`
+	Folder        string   `json:"folder"`
+	RepoCreated   bool     `json:"repoCreated"`
+	BranchCreated bool     `json:"branchCreated"`
+	Created       []string `json:"created"`  // files written by the bootstrap
+	Existing      []string `json:"existing"` // files already present, left untouched
+	URL           string   `json:"url"`
+}
+
+// Bootstrap prepares a repository for the first github_upload: it creates the
+// repository (under the token's user or organization), the branch and the
+// folder with an empty index.json and a README. Anything that already exists is
+// kept as is, so running it on a configured repository changes nothing.
+func (g *GitHubService) Bootstrap(token, repo, branch, folder string, options BootstrapOptions) (*BootstrapResult, error) {
+	if token == "" || repo == "" {
+		return nil, fmt.Errorf("token and repo are required")
+	}
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("repo must be in the owner/name format")
+	}
+	if branch == "" {
+		branch = "main"
+	}
+	folder = repoPath("", folder)
+	result := &BootstrapResult{
+		Repo:     repo,
+		Branch:   branch,
+		Folder:   folder,
+		Created:  []string{},
+		Existing: []string{},
+		URL:      fmt.Sprintf("https://github.com/%s", repo),
+	}
+
+	var repoInfo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	status, err := g.apiRequest("GET", "/repos/"+repo, token, nil, &repoInfo)
+	if err != nil {
+		return nil, err
+	}
+	switch status {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		if err := g.createRepository(token, owner, name, options, &repoInfo); err != nil {
+			return nil, err
+		}
+		result.RepoCreated = true
+	default:
+		return nil, fmt.Errorf("GitHub API error: %d %s", status, http.StatusText(status))
+	}
+
+	created, err := g.ensureBranch(token, repo, branch, repoInfo.DefaultBranch)
+	if err != nil {
+		return nil, err
+	}
+	result.BranchCreated = created
+
+	// GitHub has no empty folders: index.json is what creates the folder
+	files := []struct{ path, content string }{
+		{repoPath("", "README.md"), bootstrapReadme(name, folder)},
+		{repoPath(folder, "index.json"), bootstrapIndex()},
+	}
+	for _, file := range files {
+		_, existingSHA, err := g.getFile(token, repo, branch, file.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from GitHub: %v", file.path, err)
+		}
+		// The README generated by auto_init in a repository created just now is replaced
+		if existingSHA != "" && !(result.RepoCreated && file.path == "README.md") {
+			result.Existing = append(result.Existing, file.path)
+			continue
+		}
+		if _, err := g.putFile(token, repo, branch, file.path, file.content, "Bootstrap manga catalog: "+file.path, existingSHA); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %v", file.path, err)
+		}
+		result.Created = append(result.Created, file.path)
+	}
+	return result, nil
+}
+
+// createRepository creates owner/name under the authenticated user or, when owner
+// is someone else, under the organization owner. auto_init gives the repository
+// a first commit so branches can be created from it.
+func (g *GitHubService) createRepository(token, owner, name string, options BootstrapOptions, repoInfo interface{}) error {
+	var user struct {
+		Login string `json:"login"`
+	}
+	status, err := g.apiRequest("GET", "/user", token, nil, &user)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("invalid or expired token")
+	}
+
+	endpoint := "/user/repos"
+	if !strings.EqualFold(owner, user.Login) {
+		endpoint = "/orgs/" + owner + "/repos"
+	}
+	description := options.Description
+	if description == "" {
+		description = "Manga catalog published by Manga-Uploader"
+	}
+	body := map[string]interface{}{
+		"name":        name,
+		"description": description,
+		"private":     options.Private,
+		"auto_init":   true,
+	}
+	status, err = g.apiRequest("POST", endpoint, token, body, repoInfo)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusCreated {
+		return fmt.Errorf("failed to create repository %s/%s: %d %s", owner, name, status, http.StatusText(status))
+	}
+	return nil
+}
+
+// ensureBranch creates branch from the head of the default branch when missing.
+// An empty repository has no head to branch from: the first file written creates it.
+func (g *GitHubService) ensureBranch(token, repo, branch, defaultBranch string) (bool, error) {
+	status, err := g.apiRequest("GET", "/repos/"+repo+"/branches/"+branch, token, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	if status == http.StatusOK {
+		return false, nil
+	}
+	if status != http.StatusNotFound {
+		return false, fmt.Errorf("GitHub API error: %d %s", status, http.StatusText(status))
+	}
+
+	var ref struct {
+		Object struct {
+			SHA string `json:"sha"`
+		} `json:"object"`
+	}
+	status, err = g.apiRequest("GET", "/repos/"+repo+"/git/ref/heads/"+defaultBranch, token, nil, &ref)
+	if err != nil {
+		return false, err
+	}
+	if status == http.StatusConflict || status == http.StatusNotFound {
+		return true, nil
+	}
+	if status != http.StatusOK {
+		return false, fmt.Errorf("failed to read branch %s: %d %s", defaultBranch, status, http.StatusText(status))
+	}
+
+	body := map[string]string{"ref": "refs/heads/" + branch, "sha": ref.Object.SHA}
+	status, err = g.apiRequest("POST", "/repos/"+repo+"/git/refs", token, body, nil)
+	if err != nil {
+		return false, err
+	}
+	if status != http.StatusCreated {
+		return false, fmt.Errorf("failed to create branch %s: %d %s", branch, status, http.StatusText(status))
+	}
+	return true, nil
+}
+
+// apiRequest sends a request to the GitHub API and decodes successful responses into
+// out. Non-2xx statuses are returned for the caller to interpret, not as errors.
+func (g *GitHubService) apiRequest(method, endpoint, token string, body, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal request data: %v", err)
+		}
+		reader = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequest(method, g.baseURL+endpoint, reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "Manga-Uploader/1.0")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode response: %v", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// bootstrapIndex is the empty catalog index written by Bootstrap, in the format
+// github_upload keeps up to date
+func bootstrapIndex() string {
+	return "{\n  \"last_updated\": \"\",\n  \"count\": 0,\n  \"series\": []\n}\n"
+}
+
+// bootstrapReadme describes the catalog layout for visitors of the repository
+func bootstrapReadme(name, folder string) string {
+	location := "the repository root"
+	indexPath := "index.json"
+	if folder != "" {
+		location = "`" + folder + "/`"
+		indexPath = folder + "/index.json"
+	}
+	return fmt.Sprintf(`# %s
+
+Manga catalog published with Manga-Uploader.
+
+Each series is a JSON file in %s listing its chapters and the hosted URLs
+of their pages. `+"`%s`"+` lists every series (title, cover, latest
+chapter and path to its JSON) and is the entry point for readers.
+
+Files in this repository are updated by the uploader; manual edits may be
+overwritten by the next sync.
+`, name, location, indexPath)
+}
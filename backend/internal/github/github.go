@@ -2,11 +2,14 @@ package github
 
 import (
 	"bytes"
+	"crypto/sha1"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
@@ -199,16 +202,85 @@ func (g *GitHubService) UploadJSONFiles(token, repo, branch, folder string, json
 	}, nil
 }
 
+// FileProgress is called after each file handled by UploadFiles
+type FileProgress func(done, total int, filePath string, skipped bool)
+
+// UploadFiles publishes a set of files (relative path -> content) under folder,
+// skipping files whose content is already identical on the branch. Used for
+// sites served by GitHub Pages, where most files don't change between pushes.
+func (g *GitHubService) UploadFiles(token, repo, branch, folder, message string, files map[string]string, progress FileProgress) (*CommitResponse, error) {
+	if token == "" || repo == "" {
+		return nil, fmt.Errorf("token and repo are required")
+	}
+	if branch == "" {
+		branch = "main"
+	}
+
+	paths := make([]string, 0, len(files))
+	for name := range files {
+		paths = append(paths, name)
+	}
+	sort.Strings(paths)
+
+	var lastCommitSHA string
+	uploaded := 0
+	for i, name := range paths {
+		filePath := name
+		if folder != "" {
+			filePath = folder + "/" + name
+		}
+		filePath = strings.Trim(strings.ReplaceAll(filePath, "\\", "/"), "/")
+		content := files[name]
+
+		existingSHA, _ := g.getFileSHA(token, repo, branch, filePath)
+		skipped := existingSHA != "" && existingSHA == gitBlobSHA(content)
+		if !skipped {
+			commitSHA, err := g.putFile(token, repo, branch, filePath, content, fmt.Sprintf("%s: %s", message, name), existingSHA)
+			if err != nil {
+				return nil, fmt.Errorf("failed to upload %s: %v", name, err)
+			}
+			lastCommitSHA = commitSHA
+			uploaded++
+		}
+		if progress != nil {
+			progress(i+1, len(paths), filePath, skipped)
+		}
+	}
+
+	response := &CommitResponse{
+		SHA:     lastCommitSHA,
+		Message: fmt.Sprintf("Successfully uploaded %d of %d files (%d unchanged)", uploaded, len(paths), len(paths)-uploaded),
+	}
+	if lastCommitSHA != "" {
+		response.URL = fmt.Sprintf("https://github.com/%s/commits/%s", repo, lastCommitSHA)
+	}
+	return response, nil
+}
+
+// gitBlobSHA computes the blob SHA GitHub reports for a file's content
+func gitBlobSHA(content string) string {
+	hash := sha1.New()
+	fmt.Fprintf(hash, "blob %d\x00", len(content))
+	hash.Write([]byte(content))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
 // uploadSingleFile uploads a single file to GitHub
 func (g *GitHubService) uploadSingleFile(token, repo, branch, filePath, content, message string) (string, error) {
-	url := fmt.Sprintf("%s/repos/%s/contents/%s", g.baseURL, repo, filePath)
-
 	// Check if file exists to get SHA for update
 	var existingSHA string
 	if sha, err := g.getFileSHA(token, repo, branch, filePath); err == nil {
 		existingSHA = sha
 	}
 
+	return g.putFile(token, repo, branch, filePath, content, message, existingSHA)
+}
+
+// putFile creates or updates a file through the contents API; existingSHA is
+// required by GitHub when the file already exists
+func (g *GitHubService) putFile(token, repo, branch, filePath, content, message, existingSHA string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/contents/%s", g.baseURL, repo, filePath)
+
 	// Encode content to base64 as required by GitHub API
 	encodedContent := base64.StdEncoding.EncodeToString([]byte(content))
 	
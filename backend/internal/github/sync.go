@@ -0,0 +1,350 @@
+package github
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMessageTemplate is the per-file commit message used when no template is given
+const DefaultMessageTemplate = "Update {file} via Manga-Uploader"
+
+// DefaultChangelogPath is where the changelog lives when none is configured
+const DefaultChangelogPath = "CHANGELOG.md"
+
+// maxTitlesInMessage caps {titles} so large syncs keep a readable commit subject
+const maxTitlesInMessage = 5
+
+// SyncOptions customizes the commits made by SyncJSONFiles
+type SyncOptions struct {
+	// MessageTemplate is expanded for every commit. Variables: {count} series
+	// changed, {titles} their titles, {chapters} chapters added, {date}, and the
+	// per-file {file}, {title} and {added}.
+	MessageTemplate string
+	Changelog       bool   // prepend a summary of this sync to the changelog file
+	ChangelogPath   string // repository path of the changelog; DefaultChangelogPath when empty
+
+	// ExtraFiles are written next to the JSONs when their content changed, without
+	// being diffed or counted as series (e.g. the catalog index.json)
+	ExtraFiles map[string]string
+}
+
+// SeriesChange is what a sync changed in one series JSON, from the diff against the repository
+type SeriesChange struct {
+	File          string   `json:"file"`
+	Title         string   `json:"title"`
+	New           bool     `json:"new"`           // the JSON did not exist in the repository
+	AddedChapters []string `json:"addedChapters"` // chapter keys missing from the repository version
+}
+
+// SyncResult summarizes a JSON sync
+type SyncResult struct {
+	*CommitResponse
+	Changes          []SeriesChange `json:"changes"`
+	Unchanged        int            `json:"unchanged"` // files already identical on the branch
+	ExtraUpdated     []string       `json:"extraUpdated"`
+	ChangelogUpdated bool           `json:"changelogUpdated"`
+}
+
+// seriesJSON is the part of a series JSON the diff needs
+type seriesJSON struct {
+	Title    string                     `json:"title"`
+	Chapters map[string]json.RawMessage `json:"chapters"`
+}
+
+// SyncJSONFiles uploads series JSONs like UploadJSONFiles, but first diffs each one
+// against the repository: identical files are skipped, commit messages are
+// rendered from options.MessageTemplate and, when enabled, the chapters added
+// are recorded in the changelog.
+func (g *GitHubService) SyncJSONFiles(token, repo, branch, folder string, jsonFiles map[string]string, options SyncOptions) (*SyncResult, error) {
+	if token == "" || repo == "" {
+		return nil, fmt.Errorf("token and repo are required")
+	}
+	if branch == "" {
+		branch = "main"
+	}
+	template := options.MessageTemplate
+	if template == "" {
+		template = DefaultMessageTemplate
+	}
+
+	filenames := make([]string, 0, len(jsonFiles))
+	for filename := range jsonFiles {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	// First pass: diff everything so {count} and {titles} describe the whole sync
+	type pendingFile struct {
+		path        string
+		existingSHA string
+		change      SeriesChange
+	}
+	var pending []pendingFile
+	result := &SyncResult{Changes: []SeriesChange{}, ExtraUpdated: []string{}}
+	for _, filename := range filenames {
+		filePath := repoPath(folder, filename)
+		content := jsonFiles[filename]
+
+		remote, existingSHA, err := g.getFile(token, repo, branch, filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from GitHub: %v", filePath, err)
+		}
+		if existingSHA != "" && existingSHA == gitBlobSHA(content) {
+			result.Unchanged++
+			continue
+		}
+
+		change := diffSeries(filename, remote, content, existingSHA != "" && remote == "")
+		change.New = existingSHA == ""
+		pending = append(pending, pendingFile{path: filePath, existingSHA: existingSHA, change: change})
+		result.Changes = append(result.Changes, change)
+	}
+
+	vars := syncVariables(result.Changes)
+	var lastCommitSHA string
+	for _, file := range pending {
+		message := renderMessage(template, vars, file.change)
+		commitSHA, err := g.putFile(token, repo, branch, file.path, jsonFiles[file.change.File], message, file.existingSHA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload %s: %v", file.change.File, err)
+		}
+		lastCommitSHA = commitSHA
+	}
+
+	extraNames := make([]string, 0, len(options.ExtraFiles))
+	for name := range options.ExtraFiles {
+		extraNames = append(extraNames, name)
+	}
+	sort.Strings(extraNames)
+	for _, name := range extraNames {
+		filePath := repoPath(folder, name)
+		content := options.ExtraFiles[name]
+		existingSHA, _ := g.getFileSHA(token, repo, branch, filePath)
+		if existingSHA != "" && existingSHA == gitBlobSHA(content) {
+			continue
+		}
+		message := renderMessage(template, vars, SeriesChange{File: name, Title: name})
+		commitSHA, err := g.putFile(token, repo, branch, filePath, content, message, existingSHA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload %s: %v", name, err)
+		}
+		lastCommitSHA = commitSHA
+		result.ExtraUpdated = append(result.ExtraUpdated, name)
+	}
+
+	if options.Changelog && len(result.Changes) > 0 {
+		changelogPath := options.ChangelogPath
+		if changelogPath == "" {
+			changelogPath = DefaultChangelogPath
+		}
+		changelogPath = repoPath("", changelogPath)
+
+		existing, existingSHA, err := g.getFile(token, repo, branch, changelogPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from GitHub: %v", changelogPath, err)
+		}
+		if existingSHA != "" && existing == "" {
+			// Rewriting it from scratch would drop the history the API did not return
+			return nil, fmt.Errorf("%s is too large to update through the contents API", changelogPath)
+		}
+		changelog := PrependChangelog(existing, time.Now(), result.Changes)
+		message := renderMessage(template, vars, SeriesChange{File: changelogPath, Title: changelogPath})
+		commitSHA, err := g.putFile(token, repo, branch, changelogPath, changelog, message, existingSHA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update %s: %v", changelogPath, err)
+		}
+		lastCommitSHA = commitSHA
+		result.ChangelogUpdated = true
+	}
+
+	result.CommitResponse = &CommitResponse{
+		SHA:     lastCommitSHA,
+		Message: fmt.Sprintf("Successfully uploaded %d JSON files (%d unchanged)", len(pending), result.Unchanged),
+	}
+	if lastCommitSHA != "" {
+		result.URL = fmt.Sprintf("https://github.com/%s/commits/%s", repo, lastCommitSHA)
+	}
+	return result, nil
+}
+
+// repoPath joins folder and name into a GitHub path (forward slashes, no leading slash)
+func repoPath(folder, name string) string {
+	filePath := name
+	if folder != "" {
+		filePath = folder + "/" + name
+	}
+	return strings.Trim(strings.ReplaceAll(filePath, "\\", "/"), "/")
+}
+
+// diffSeries lists the chapters of the local JSON that the repository version lacks.
+// An unreadable repository version (remoteUnknown: too large for the contents API,
+// or invalid) yields no added chapters rather than reporting every chapter as new.
+func diffSeries(filename, remote, local string, remoteUnknown bool) SeriesChange {
+	change := SeriesChange{File: filename, Title: strings.TrimSuffix(filename, ".json"), AddedChapters: []string{}}
+
+	var localSeries seriesJSON
+	if err := json.Unmarshal([]byte(local), &localSeries); err != nil {
+		return change
+	}
+	if localSeries.Title != "" {
+		change.Title = localSeries.Title
+	}
+	if remoteUnknown {
+		return change
+	}
+
+	var remoteSeries seriesJSON
+	if remote != "" {
+		if err := json.Unmarshal([]byte(remote), &remoteSeries); err != nil {
+			return change
+		}
+	}
+	for key := range localSeries.Chapters {
+		if _, exists := remoteSeries.Chapters[key]; !exists {
+			change.AddedChapters = append(change.AddedChapters, key)
+		}
+	}
+	sortChapterKeys(change.AddedChapters)
+	return change
+}
+
+// sortChapterKeys orders chapter keys numerically ("2" before "10"), falling back to text
+func sortChapterKeys(keys []string) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.ParseFloat(keys[i], 64)
+		b, errB := strconv.ParseFloat(keys[j], 64)
+		if errA == nil && errB == nil && a != b {
+			return a < b
+		}
+		if (errA == nil) != (errB == nil) {
+			return errA == nil
+		}
+		return keys[i] < keys[j]
+	})
+}
+
+// syncVariables computes the template variables shared by every commit of a sync
+func syncVariables(changes []SeriesChange) map[string]string {
+	titles := make([]string, 0, len(changes))
+	chapters := 0
+	for _, change := range changes {
+		titles = append(titles, change.Title)
+		chapters += len(change.AddedChapters)
+	}
+	if len(titles) > maxTitlesInMessage {
+		titles = append(titles[:maxTitlesInMessage], fmt.Sprintf("and %d more", len(changes)-maxTitlesInMessage))
+	}
+	return map[string]string{
+		"{count}":    strconv.Itoa(len(changes)),
+		"{titles}":   strings.Join(titles, ", "),
+		"{chapters}": strconv.Itoa(chapters),
+		"{date}":     time.Now().Format("2006-01-02"),
+	}
+}
+
+// renderMessage expands a commit message template for one file
+func renderMessage(template string, vars map[string]string, change SeriesChange) string {
+	pairs := make([]string, 0, 2*(len(vars)+3))
+	for name, value := range vars {
+		pairs = append(pairs, name, value)
+	}
+	pairs = append(pairs,
+		"{file}", change.File,
+		"{title}", change.Title,
+		"{added}", strings.Join(change.AddedChapters, ", "),
+	)
+	return strings.TrimSpace(strings.NewReplacer(pairs...).Replace(template))
+}
+
+// PrependChangelog adds a dated section listing the chapters added by a sync to the
+// top of an existing changelog (or starts a new one), below its "# " heading
+func PrependChangelog(existing string, date time.Time, changes []SeriesChange) string {
+	var section strings.Builder
+	fmt.Fprintf(&section, "## %s\n\n", date.Format("2006-01-02 15:04"))
+	for _, change := range changes {
+		switch {
+		case len(change.AddedChapters) > 0 && change.New:
+			fmt.Fprintf(&section, "- **%s** (new series): chapters %s\n", change.Title, strings.Join(change.AddedChapters, ", "))
+		case len(change.AddedChapters) > 0:
+			fmt.Fprintf(&section, "- **%s**: chapters %s\n", change.Title, strings.Join(change.AddedChapters, ", "))
+		case change.New:
+			fmt.Fprintf(&section, "- **%s**: new series\n", change.Title)
+		default:
+			fmt.Fprintf(&section, "- **%s**: metadata updated\n", change.Title)
+		}
+	}
+	section.WriteString("\n")
+
+	existing = strings.TrimLeft(existing, "\n")
+	if !strings.HasPrefix(existing, "# ") {
+		return "# Changelog\n\n" + section.String() + existing
+	}
+	heading, rest, _ := strings.Cut(existing, "\n")
+	return heading + "\n\n" + section.String() + strings.TrimLeft(rest, "\n")
+}
+
+// DownloadFile returns the content of name inside folder in the repository, the
+// same path SyncJSONFiles writes to. A missing file returns an empty string.
+func (g *GitHubService) DownloadFile(token, repo, branch, folder, name string) (string, error) {
+	content, sha, err := g.getFile(token, repo, branch, repoPath(folder, name))
+	if err != nil {
+		return "", err
+	}
+	if content == "" && sha != "" {
+		return "", fmt.Errorf("%s is too large for the contents API", name)
+	}
+	return content, nil
+}
+
+// getFile reads a file through the contents API, returning its content and SHA.
+// A missing file is not an error: both are empty.
+func (g *GitHubService) getFile(token, repo, branch, filePath string) (string, string, error) {
+	url := fmt.Sprintf("%s/repos/%s/contents/%s?ref=%s", g.baseURL, repo, filePath, branch)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "Manga-Uploader/1.0")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("GitHub API error: %s", resp.Status)
+	}
+
+	var response struct {
+		SHA      string `json:"sha"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", "", err
+	}
+
+	// Files over 1 MB come without content ("encoding": "none"): only the SHA is usable
+	if response.Encoding != "base64" {
+		return "", response.SHA, nil
+	}
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(response.Content, "\n", ""))
+	if err != nil {
+		return "", response.SHA, nil
+	}
+	return string(content), response.SHA, nil
+}
@@ -0,0 +1,253 @@
+// Package hooks executa comandos de shell e webhooks configurados em torno
+// dos estágios do pipeline (descoberta, envio de capítulos, geração de JSON).
+//
+// Cada hook recebe o evento em JSON no stdin (comandos) ou no corpo de um
+// POST (webhooks), e os campos principais também em variáveis de ambiente
+// GO_UPLOAD_*. Nos estágios before_*, uma saída diferente de zero ou uma
+// resposta fora de 2xx interrompe o estágio (ex.: capítulo recusado por uma
+// checagem de typesetting); nos estágios after_*, a falha é apenas relatada.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stage identifica o ponto do pipeline em que o hook roda
+type Stage string
+
+const (
+	BeforeDiscovery     Stage = "before_discovery"
+	AfterDiscovery      Stage = "after_discovery"
+	BeforeChapterUpload Stage = "before_chapter_upload"
+	AfterJSON           Stage = "after_json"
+)
+
+// stages lista os estágios aceitos na configuração
+var stages = map[Stage]bool{
+	BeforeDiscovery:     true,
+	AfterDiscovery:      true,
+	BeforeChapterUpload: true,
+	AfterJSON:           true,
+}
+
+// defaultTimeout é o tempo máximo de um hook sem timeout configurado
+const defaultTimeout = 60 * time.Second
+
+// maxOutput é quanto da saída de um comando com falha vai na mensagem de erro
+const maxOutput = 2048
+
+// Hook é um comando ou webhook associado a um estágio
+type Hook struct {
+	Name         string            `json:"name,omitempty"`
+	Stage        Stage             `json:"stage"`
+	Command      string            `json:"command,omitempty"` // executado com sh -c (cmd /C no Windows)
+	URL          string            `json:"url,omitempty"`     // webhook que recebe o evento por POST
+	Headers      map[string]string `json:"headers,omitempty"` // cabeçalhos extras do webhook
+	Timeout      string            `json:"timeout,omitempty"` // ex. "2m"; padrão 60s
+	IgnoreErrors bool              `json:"ignoreErrors,omitempty"`
+
+	timeout time.Duration
+}
+
+// label identifica o hook nas mensagens de erro e no log
+func (h *Hook) label() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	if h.URL != "" {
+		return h.URL
+	}
+	return h.Command
+}
+
+// Event é o contexto entregue ao hook
+type Event struct {
+	Stage    Stage           `json:"stage"`
+	Time     time.Time       `json:"time"`
+	Path     string          `json:"path,omitempty"` // diretório descoberto ou do capítulo
+	Manga    string          `json:"manga,omitempty"`
+	Chapter  string          `json:"chapter,omitempty"`
+	Host     string          `json:"host,omitempty"`
+	JobID    string          `json:"jobId,omitempty"` // lote ou coleção
+	Files    []string        `json:"files,omitempty"`
+	JSONPath string          `json:"jsonPath,omitempty"`
+	JSON     json.RawMessage `json:"json,omitempty"` // conteúdo do JSON gerado (after_json)
+	Data     interface{}     `json:"data,omitempty"` // detalhes do estágio (ex.: estatísticas da descoberta)
+}
+
+// env retorna as variáveis GO_UPLOAD_* do evento
+func (e *Event) env() []string {
+	return []string{
+		"GO_UPLOAD_STAGE=" + string(e.Stage),
+		"GO_UPLOAD_PATH=" + e.Path,
+		"GO_UPLOAD_MANGA=" + e.Manga,
+		"GO_UPLOAD_CHAPTER=" + e.Chapter,
+		"GO_UPLOAD_HOST=" + e.Host,
+		"GO_UPLOAD_JOB_ID=" + e.JobID,
+		"GO_UPLOAD_FILE_COUNT=" + strconv.Itoa(len(e.Files)),
+		"GO_UPLOAD_JSON_PATH=" + e.JSONPath,
+	}
+}
+
+// Runner executa os hooks configurados. Os métodos aceitam Runner nil (sem hooks).
+type Runner struct {
+	hooks  map[Stage][]*Hook
+	client *http.Client
+}
+
+// New valida os hooks e agrupa por estágio
+func New(hooks []Hook) (*Runner, error) {
+	runner := &Runner{hooks: make(map[Stage][]*Hook), client: &http.Client{}}
+	for i := range hooks {
+		hook := hooks[i]
+		if !stages[hook.Stage] {
+			return nil, fmt.Errorf("hook %d: unknown stage %q", i, hook.Stage)
+		}
+		if (hook.Command == "") == (hook.URL == "") {
+			return nil, fmt.Errorf("hook %d (%s): exactly one of command or url is required", i, hook.Stage)
+		}
+		hook.timeout = defaultTimeout
+		if hook.Timeout != "" {
+			timeout, err := time.ParseDuration(hook.Timeout)
+			if err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("hook %d (%s): invalid timeout %q", i, hook.Stage, hook.Timeout)
+			}
+			hook.timeout = timeout
+		}
+		runner.hooks[hook.Stage] = append(runner.hooks[hook.Stage], &hook)
+	}
+	return runner, nil
+}
+
+// Has informa se há hooks para o estágio
+func (r *Runner) Has(stage Stage) bool {
+	return r != nil && len(r.hooks[stage]) > 0
+}
+
+// Run executa, em ordem, os hooks do estágio do evento. Retorna o erro do
+// primeiro hook que falhar (os marcados com ignoreErrors não interrompem).
+func (r *Runner) Run(ctx context.Context, event Event) error {
+	if !r.Has(event.Stage) {
+		return nil
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode hook event: %w", err)
+	}
+
+	var ignored []string
+	for _, hook := range r.hooks[event.Stage] {
+		hookCtx, cancel := context.WithTimeout(ctx, hook.timeout)
+		if hook.URL != "" {
+			err = r.post(hookCtx, hook, payload)
+		} else {
+			err = runCommand(hookCtx, hook, payload, event.env())
+		}
+		cancel()
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("hook %q (%s) failed: %w", hook.label(), event.Stage, err)
+		if !hook.IgnoreErrors {
+			return err
+		}
+		ignored = append(ignored, err.Error())
+	}
+	if len(ignored) > 0 {
+		return &IgnoredErrors{Messages: ignored}
+	}
+	return nil
+}
+
+// IgnoredErrors reúne falhas de hooks com ignoreErrors; o estágio continua
+type IgnoredErrors struct {
+	Messages []string
+}
+
+func (e *IgnoredErrors) Error() string {
+	return strings.Join(e.Messages, "; ")
+}
+
+// Blocking informa se o erro de Run deve interromper o estágio
+func Blocking(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ignored := err.(*IgnoredErrors)
+	return !ignored
+}
+
+// runCommand executa o comando do hook com o evento no stdin
+func runCommand(ctx context.Context, hook *Hook, payload []byte, env []string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", hook.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	}
+	cmd.Env = append(os.Environ(), env...)
+	// Filhos do shell que herdaram a saída não seguram o hook além do timeout
+	cmd.WaitDelay = time.Second
+	cmd.Stdin = bytes.NewReader(payload)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out after %s", hook.timeout)
+		}
+		if text := tail(output.String()); text != "" {
+			return fmt.Errorf("%v: %s", err, text)
+		}
+		return err
+	}
+	return nil
+}
+
+// post envia o evento ao webhook; qualquer status fora de 2xx é falha
+func (r *Runner) post(ctx context.Context, hook *Hook, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-upload-hooks")
+	for name, value := range hook.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxOutput))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// tail retorna o final da saída, que costuma trazer a mensagem de erro
+func tail(output string) string {
+	output = strings.TrimSpace(output)
+	if len(output) > maxOutput {
+		output = "..." + output[len(output)-maxOutput:]
+	}
+	return output
+}
@@ -0,0 +1,139 @@
+package i18n
+
+// catalogEn contém as mensagens em inglês
+var catalogEn = map[string]string{
+	MsgFieldRequired:       "%s is required",
+	MsgInvalidPayload:      "Invalid payload format",
+	MsgInvalidRequestData:  "Invalid request data format",
+	MsgNotImplemented:      "%s functionality not yet implemented",
+	MsgLocaleChanged:       "Language changed to English",
+	MsgUnsupportedEncoding: "Unsupported encoding: %s (use json or msgpack)",
+	MsgReadOnly:            "%s is not available: this server is a read-only mirror",
+
+	MsgPathNotFound:           "Path does not exist: %s",
+	MsgDiscoveryFailed:        "Failed to discover structure: %v",
+	MsgLibraryDiscoveryFailed: "Failed to discover library: %v",
+
+	MsgMissingMetadata:     "Missing metadata in payload",
+	MsgMissingMangaPath:    "Missing mangaID or mangaPath in payload",
+	MsgMissingMangaName:    "Missing mangaID or mangaName in payload",
+	MsgJSONDirFailed:       "Failed to create JSON directory: %v",
+	MsgJSONMarshalFailed:   "Failed to marshal updated JSON: %v",
+	MsgJSONGenerateFailed:  "Failed to generate ordered JSON: %v",
+	MsgMetadataWriteFailed: "Failed to write metadata file: %v",
+	MsgJSONNotFound:        "JSON file not found for filename: %s",
+	MsgJSONParseFailed:     "Failed to parse JSON file: %v",
+	MsgFieldLocksFailed:    "Failed to update locked fields: %v",
+	MsgLinkSeriesFailed:    "Failed to link related series: %v",
+	MsgJSONScanFailed:      "Failed to check JSON files: %v",
+	MsgJSONMigrationFailed: "Failed to migrate JSON filenames: %v",
+	MsgMetadataConflict:    "Metadata for %s was changed by another client; review the changes before saving",
+
+	MsgUploadsDisabled:           "Uploads are disabled (emergency stop or safe mode); send resume_uploads to re-enable",
+	MsgMirrorHostUnavailable:     "Mirror host is not available: %s",
+	MsgCollectionStartFailed:     "Failed to start collection processing: %v",
+	MsgCollectionNotFound:        "Collection not found",
+	MsgEstimateFailed:            "Failed to estimate the collection upload: %v",
+	MsgShareLinkFailed:           "Failed to create share link: %v",
+	MsgShareLinksDisabled:        "Share links are unavailable (the signing key could not be loaded)",
+	MsgStateCleanupFailed:        "Failed to clean up collection states: %v",
+	MsgAutoFillRunning:           "Library metadata auto-fill is already running",
+	MsgStatusRefreshRunning:      "Manga status refresh is already running",
+	MsgMirrorHealthRunning:       "Mirror health check is already running",
+	MsgMirrorHealthMissing:       "No mirror health check has finished yet; run check_mirror_health first",
+	MsgMirrorFailoverFailed:      "Failed to rewrite mirrors of %s: %v",
+	MsgSignedURLsRunning:         "Signed URL refresh is already running",
+	MsgSignedURLsDisabled:        "No upload host is configured with signed URLs",
+	MsgAnalyticsFailed:           "Failed to compute upload analytics: %v",
+	MsgReleasePostEmpty:          "No uploaded files found for batch %s",
+	MsgReleasePostFailed:         "Failed to build the release post: %v",
+	MsgPublishNothing:            "No unpublished uploads for %s chapter %s",
+	MsgPublishFailed:             "Failed to publish the chapter: %v",
+	MsgReviewDisabled:            "The chapter review workflow is disabled (set review.enabled in the config)",
+	MsgReviewerUnknown:           "Unknown reviewer token",
+	MsgReviewNotIdentified:       "Identify yourself with set_reviewer before changing chapter status",
+	MsgReviewFailed:              "Failed to change the chapter status: %v",
+	MsgNotifyBatchFailed:         "Batch %s finished with %s of %s files failed",
+	MsgNotifyCollectionCompleted: "Collection %s completed",
+	MsgNotifyCollectionFailed:    "Collection %s failed: %s",
+	MsgNotifyTakedown:            "%s of %s checked pages are no longer available on %s",
+	MsgNotifyApprovalPending:     "%s chapter %s is waiting for QC approval",
+	MsgNotifyReviewReturned:      "%s chapter %s was returned by QC",
+	MsgSiteRunning:               "Static site generation is already running",
+	MsgSiteFailed:                "Failed to generate the static site: %v",
+	MsgUpdateCheckFailed:         "Failed to check for updates: %v",
+	MsgUpdateFailed:              "Failed to apply the update: %v",
+	MsgUpdateRunning:             "An update is already being applied",
+	MsgUpToDate:                  "Already running the latest version (%s)",
+	MsgUpdateBusy:                "%d batch(es) still uploading; wait for them or send force to update anyway",
+	MsgJobNotFound:               "Job %s not found or already expired",
+	MsgJobClaimDenied:            "Invalid claim token for job %s",
+
+	MsgSearchQueryRequired:      "Search query is required",
+	MsgAniListIDRequired:        "AniList ID is required",
+	MsgAniListNotInitialized:    "AniList service not initialized",
+	MsgAniListSearchUnexpected:  "Unexpected error while searching AniList. Try again or use manual entry.",
+	MsgAniListDetailsUnexpected: "Unexpected error while fetching AniList details. Try again or use manual entry.",
+	MsgAniListUnexpectedHints:   "Try again in a few moments\nUse manual metadata entry",
+	MsgOfflineImportFailed:      "Failed to import offline metadata dump: %v",
+	MsgCubariImportFailed:       "Failed to import the Cubari series: %v",
+	MsgExportFailed:             "Failed to export the chapter: %v",
+	MsgBackupFailed:             "Failed to verify the backup: %v",
+	MsgProviderNotFound:         "Unknown metadata provider: %s",
+	MsgProviderIDRequired:       "The provider ID of the series is required",
+	MsgPluginFailed:             "Plugin %s failed: %v",
+	MsgHookRejected:             "Rejected by a pipeline hook: %v",
+	MsgPolicyRejected:           "All %d files were rejected by the content policy",
+	MsgSystemFilesOnly:          "All %d files are hidden or system files (.DS_Store, Thumbs.db, desktop.ini...); set includeHidden to upload them",
+	MsgFileContentTooLarge:      "%s is %d bytes; the limit per file is %d bytes",
+	MsgFileContentInvalid:       "Invalid file content: %v",
+	MsgPolicyCheckFailed:        "Failed to check the content policy: %v",
+	MsgIntegrityRunning:         "Integrity scan or repair is already running",
+	MsgIntegrityMissing:         "No integrity scan has finished yet; run scan_integrity first",
+	MsgArchiveFailed:            "Failed to archive %s: %v",
+	MsgUnarchiveFailed:          "Failed to unarchive %s: %v",
+	MsgLibrarySearchFailed:      "Failed to search the library: %v",
+	MsgConfigMissing:            "Missing or invalid config data",
+	MsgConfigGetFailed:          "Failed to get current config",
+	MsgConfigUpdateFailed:       "Failed to update config: %v",
+	MsgConfigResetFailed:        "Failed to reset config: %v",
+	MsgAniListProfileFailed:     "AniList configuration profile error: %v",
+
+	MsgGitHubInvalidFolders:     "Invalid GitHub folders request format",
+	MsgGitHubInvalidUpload:      "Invalid GitHub upload request format",
+	MsgGitHubCredentialsMissing: "GitHub token and repository are required",
+	MsgGitHubListFailed:         "Failed to list GitHub folders: %v",
+	MsgGitHubNoWorksSelected:    "No works selected for GitHub upload",
+	MsgGitHubInvalidFilter:      "Invalid selection filter: %v",
+	MsgGitHubFilterFailed:       "Failed to resolve the selection filter: %v",
+	MsgGitHubFilterNoMatch:      "No works match the selection filter",
+	MsgGitHubNoJSONFiles:        "No JSON files found to upload",
+	MsgGitHubUploadFailed:       "Failed to upload to GitHub: %v",
+	MsgGitHubBootstrapFailed:    "Failed to prepare the GitHub repository: %v",
+
+	// AniList friendly errors (anilist.ErrorHandler)
+	"anilist.error.network_connectivity":             "Could not connect to AniList. Check your internet connection.",
+	"anilist.error.network_connectivity.suggestions": "Check your internet connection\nTry again in a few moments\nUse manual metadata entry instead",
+	"anilist.error.search_timeout":                   "The AniList search is taking longer than expected.",
+	"anilist.error.search_timeout.suggestions":       "Try a search with more specific terms\nWait a few minutes and try again\nUse manual entry if you need to continue",
+	"anilist.error.rate_limited":                     "Too many searches were made recently. Wait a moment before trying again.",
+	"anilist.error.rate_limited.suggestions":         "Wait 1-2 minutes before searching again\nUse cached results from previous searches\nContinue with manual metadata entry",
+	"anilist.error.anilist_server_error":             "AniList is temporarily unavailable. Try again in a few minutes.",
+	"anilist.error.anilist_server_error.suggestions": "Wait a few minutes and try again\nCheck AniList's status on their social media\nUse manual entry to avoid losing time",
+	"anilist.error.no_search_results":                "No manga with that name was found on AniList.",
+	"anilist.error.no_search_results.suggestions":    "Try searching with the English or Japanese name\nCheck the spelling of the name\nUse more generic terms (e.g. only the main title)\nFill in the metadata manually",
+	"anilist.error.authorization_error":              "Authorization problem with AniList. The service may be temporarily unavailable.",
+	"anilist.error.authorization_error.suggestions":  "Try again in a few minutes\nContact support if the problem persists\nUse manual entry instead",
+	"anilist.error.data_format_error":                "The data returned by AniList is in an unexpected format.",
+	"anilist.error.data_format_error.suggestions":    "Search for another manga to check whether the problem persists\nReport this problem to the developers\nUse manual entry for this specific manga",
+	"anilist.error.service_unavailable":              "The AniList integration was temporarily disabled due to recurring problems.",
+	"anilist.error.service_unavailable.suggestions":  "The feature will be restored automatically once the service stabilizes\nUse manual metadata entry\nTry again in 10-15 minutes",
+	"anilist.error.details_unavailable":              "The AniList integration is temporarily unavailable. Try again in a few minutes.",
+	"anilist.error.details_unavailable.suggestions":  "The feature will be restored automatically\nTry again in 10-15 minutes\nUse manual entry for this manga",
+	"anilist.error.offline_not_found":                "This title is not in the offline metadata database.",
+	"anilist.error.offline_not_found.suggestions":    "Disable offline mode to search AniList\nImport an updated metadata dump\nUse manual entry for this manga",
+	"anilist.error.unknown_error":                    "An unexpected error occurred while searching AniList.",
+	"anilist.error.unknown_error.suggestions":        "Try again in a few moments\nCheck your internet connection\nUse manual metadata entry\nContact support if the problem persists",
+	"anilist.error.service_recovered":                "The AniList integration has been restored and is working normally.",
+	"anilist.error.service_recovered.suggestions":    "You can now search AniList normally\nCached results are still available",
+}
@@ -0,0 +1,139 @@
+package i18n
+
+// catalogEs contém as mensagens em espanhol
+var catalogEs = map[string]string{
+	MsgFieldRequired:       "%s es obligatorio",
+	MsgInvalidPayload:      "Formato de payload inválido",
+	MsgInvalidRequestData:  "Formato de datos de la solicitud inválido",
+	MsgNotImplemented:      "Funcionalidad %s aún no implementada",
+	MsgLocaleChanged:       "Idioma cambiado a español",
+	MsgUnsupportedEncoding: "Codificación no soportada: %s (use json o msgpack)",
+	MsgReadOnly:            "%s no está disponible: este servidor es un espejo de solo lectura",
+
+	MsgPathNotFound:           "La ruta no existe: %s",
+	MsgDiscoveryFailed:        "Error al descubrir la estructura: %v",
+	MsgLibraryDiscoveryFailed: "Error al descubrir la biblioteca: %v",
+
+	MsgMissingMetadata:     "Faltan metadatos en el payload",
+	MsgMissingMangaPath:    "Falta mangaID o mangaPath en el payload",
+	MsgMissingMangaName:    "Falta mangaID o mangaName en el payload",
+	MsgJSONDirFailed:       "Error al crear el directorio de JSON: %v",
+	MsgJSONMarshalFailed:   "Error al serializar el JSON actualizado: %v",
+	MsgJSONGenerateFailed:  "Error al generar el JSON ordenado: %v",
+	MsgMetadataWriteFailed: "Error al escribir el archivo de metadatos: %v",
+	MsgJSONNotFound:        "Archivo JSON no encontrado: %s",
+	MsgJSONParseFailed:     "Error al leer el archivo JSON: %v",
+	MsgFieldLocksFailed:    "Error al actualizar los campos bloqueados: %v",
+	MsgLinkSeriesFailed:    "Error al vincular las series relacionadas: %v",
+	MsgJSONScanFailed:      "Error al verificar los archivos JSON: %v",
+	MsgJSONMigrationFailed: "Error al migrar los nombres de los archivos JSON: %v",
+	MsgMetadataConflict:    "Los metadatos de %s fueron modificados por otro cliente; revisa los cambios antes de guardar",
+
+	MsgUploadsDisabled:           "Las subidas están desactivadas (parada de emergencia o modo seguro); envíe resume_uploads para reactivarlas",
+	MsgMirrorHostUnavailable:     "El host espejo no está disponible: %s",
+	MsgCollectionStartFailed:     "Error al iniciar el procesamiento de la colección: %v",
+	MsgCollectionNotFound:        "Colección no encontrada",
+	MsgEstimateFailed:            "Error al estimar el envío de la colección: %v",
+	MsgShareLinkFailed:           "Error al crear el enlace compartido: %v",
+	MsgShareLinksDisabled:        "Los enlaces compartidos no están disponibles (no se pudo cargar la clave de firma)",
+	MsgStateCleanupFailed:        "Error al limpiar los estados de colecciones: %v",
+	MsgAutoFillRunning:           "El autocompletado de metadatos ya está en curso",
+	MsgStatusRefreshRunning:      "La actualización de estado de las obras ya está en curso",
+	MsgMirrorHealthRunning:       "La verificación de espejos ya está en curso",
+	MsgMirrorHealthMissing:       "Aún no se ha completado ninguna verificación de espejos; ejecuta check_mirror_health primero",
+	MsgMirrorFailoverFailed:      "Error al reescribir los espejos de %s: %v",
+	MsgSignedURLsRunning:         "La renovación de URLs firmadas ya está en curso",
+	MsgSignedURLsDisabled:        "Ningún host de subida está configurado con URLs firmadas",
+	MsgAnalyticsFailed:           "Error al calcular las estadísticas de uploads: %v",
+	MsgReleasePostEmpty:          "No se encontraron archivos subidos para el lote %s",
+	MsgReleasePostFailed:         "Error al generar la publicación del lanzamiento: %v",
+	MsgPublishNothing:            "No hay subidas sin publicar para %s capítulo %s",
+	MsgPublishFailed:             "Error al publicar el capítulo: %v",
+	MsgReviewDisabled:            "El flujo de revisión de capítulos está desactivado (active review.enabled en la configuración)",
+	MsgReviewerUnknown:           "Token de revisor desconocido",
+	MsgReviewNotIdentified:       "Identifíquese con set_reviewer antes de cambiar el estado de un capítulo",
+	MsgReviewFailed:              "Error al cambiar el estado del capítulo: %v",
+	MsgNotifyBatchFailed:         "El lote %s terminó con %s de %s archivos fallidos",
+	MsgNotifyCollectionCompleted: "Colección %s completada",
+	MsgNotifyCollectionFailed:    "La colección %s falló: %s",
+	MsgNotifyTakedown:            "%s de %s páginas verificadas ya no están disponibles en %s",
+	MsgNotifyApprovalPending:     "%s capítulo %s espera la aprobación de QC",
+	MsgNotifyReviewReturned:      "%s capítulo %s fue devuelto por QC",
+	MsgSiteRunning:               "La generación del sitio estático ya está en curso",
+	MsgSiteFailed:                "Error al generar el sitio estático: %v",
+	MsgUpdateCheckFailed:         "Error al buscar actualizaciones: %v",
+	MsgUpdateFailed:              "Error al aplicar la actualización: %v",
+	MsgUpdateRunning:             "Ya se está aplicando una actualización",
+	MsgUpToDate:                  "Ya se está ejecutando la última versión (%s)",
+	MsgUpdateBusy:                "%d lote(s) aún en subida; espera a que terminen o envía force para actualizar de todos modos",
+	MsgJobNotFound:               "Trabajo %s no encontrado o ya expirado",
+	MsgJobClaimDenied:            "Token de propiedad inválido para el trabajo %s",
+
+	MsgSearchQueryRequired:      "El término de búsqueda es obligatorio",
+	MsgAniListIDRequired:        "El ID de AniList es obligatorio",
+	MsgAniListNotInitialized:    "Servicio de AniList no inicializado",
+	MsgAniListSearchUnexpected:  "Error inesperado al buscar en AniList. Inténtelo de nuevo o use la entrada manual.",
+	MsgAniListDetailsUnexpected: "Error inesperado al obtener detalles de AniList. Inténtelo de nuevo o use la entrada manual.",
+	MsgAniListUnexpectedHints:   "Inténtelo de nuevo en unos instantes\nUse la entrada manual de metadatos",
+	MsgOfflineImportFailed:      "Error al importar el dump de metadatos offline: %v",
+	MsgCubariImportFailed:       "Error al importar la serie de Cubari: %v",
+	MsgExportFailed:             "Error al exportar el capítulo: %v",
+	MsgBackupFailed:             "Error al verificar la copia de seguridad: %v",
+	MsgProviderNotFound:         "Fuente de metadatos desconocida: %s",
+	MsgProviderIDRequired:       "Se requiere el ID de la obra en la fuente",
+	MsgPluginFailed:             "El plugin %s falló: %v",
+	MsgHookRejected:             "Rechazado por un hook del pipeline: %v",
+	MsgPolicyRejected:           "Los %d archivos fueron rechazados por la política de contenido",
+	MsgSystemFilesOnly:          "Los %d archivos son ocultos o de sistema (.DS_Store, Thumbs.db, desktop.ini...); activa includeHidden para enviarlos",
+	MsgFileContentTooLarge:      "%s tiene %d bytes; el límite por archivo es %d bytes",
+	MsgFileContentInvalid:       "Contenido de archivo inválido: %v",
+	MsgPolicyCheckFailed:        "Error al verificar la política de contenido: %v",
+	MsgIntegrityRunning:         "La verificación o reparación de integridad ya está en curso",
+	MsgIntegrityMissing:         "Aún no se ha completado ninguna verificación de integridad; ejecuta scan_integrity primero",
+	MsgArchiveFailed:            "Error al archivar %s: %v",
+	MsgUnarchiveFailed:          "Error al desarchivar %s: %v",
+	MsgLibrarySearchFailed:      "Error al buscar en la biblioteca: %v",
+	MsgConfigMissing:            "Datos de configuración ausentes o inválidos",
+	MsgConfigGetFailed:          "Error al obtener la configuración actual",
+	MsgConfigUpdateFailed:       "Error al actualizar la configuración: %v",
+	MsgConfigResetFailed:        "Error al restablecer la configuración: %v",
+	MsgAniListProfileFailed:     "Error en el perfil de configuración de AniList: %v",
+
+	MsgGitHubInvalidFolders:     "Formato inválido de la solicitud de carpetas de GitHub",
+	MsgGitHubInvalidUpload:      "Formato inválido de la solicitud de subida a GitHub",
+	MsgGitHubCredentialsMissing: "El token y el repositorio de GitHub son obligatorios",
+	MsgGitHubListFailed:         "Error al listar carpetas de GitHub: %v",
+	MsgGitHubNoWorksSelected:    "Ninguna obra seleccionada para subir a GitHub",
+	MsgGitHubInvalidFilter:      "Filtro de selección inválido: %v",
+	MsgGitHubFilterFailed:       "Error al resolver el filtro de selección: %v",
+	MsgGitHubFilterNoMatch:      "Ninguna obra coincide con el filtro de selección",
+	MsgGitHubNoJSONFiles:        "No se encontraron archivos JSON para subir",
+	MsgGitHubUploadFailed:       "Error al subir a GitHub: %v",
+	MsgGitHubBootstrapFailed:    "Error al preparar el repositorio de GitHub: %v",
+
+	// Errores amigables de AniList (anilist.ErrorHandler)
+	"anilist.error.network_connectivity":             "No fue posible conectar con AniList. Verifique su conexión a internet.",
+	"anilist.error.network_connectivity.suggestions": "Verifique su conexión a internet\nInténtelo de nuevo en unos instantes\nUse la entrada manual de metadatos como alternativa",
+	"anilist.error.search_timeout":                   "La búsqueda en AniList está tardando más de lo esperado.",
+	"anilist.error.search_timeout.suggestions":       "Intente una búsqueda con términos más específicos\nEspere unos minutos e inténtelo de nuevo\nUse la entrada manual si necesita continuar",
+	"anilist.error.rate_limited":                     "Se hicieron demasiadas búsquedas recientemente. Espere un momento antes de intentarlo de nuevo.",
+	"anilist.error.rate_limited.suggestions":         "Espere 1-2 minutos antes de buscar de nuevo\nUse la caché de resultados anteriores\nContinúe con la entrada manual de metadatos",
+	"anilist.error.anilist_server_error":             "AniList no está disponible temporalmente. Inténtelo de nuevo en unos minutos.",
+	"anilist.error.anilist_server_error.suggestions": "Espere unos minutos e inténtelo de nuevo\nConsulte el estado de AniList en sus redes sociales\nUse la entrada manual para no perder tiempo",
+	"anilist.error.no_search_results":                "No se encontró ningún manga con ese nombre en AniList.",
+	"anilist.error.no_search_results.suggestions":    "Intente buscar con el nombre en inglés o japonés\nVerifique la ortografía del nombre\nUse términos más genéricos (ej: solo el nombre principal)\nComplete los metadatos manualmente",
+	"anilist.error.authorization_error":              "Problema de autorización con AniList. Es posible que el servicio no esté disponible temporalmente.",
+	"anilist.error.authorization_error.suggestions":  "Inténtelo de nuevo en unos minutos\nContacte con soporte si el problema persiste\nUse la entrada manual como alternativa",
+	"anilist.error.data_format_error":                "Los datos devueltos por AniList tienen un formato inesperado.",
+	"anilist.error.data_format_error.suggestions":    "Busque otro manga para comprobar si el problema persiste\nInforme este problema a los desarrolladores\nUse la entrada manual para este manga",
+	"anilist.error.service_unavailable":              "La integración con AniList se desactivó temporalmente debido a problemas recurrentes.",
+	"anilist.error.service_unavailable.suggestions":  "La funcionalidad se restaurará automáticamente cuando el servicio se estabilice\nUse la entrada manual de metadatos\nInténtelo de nuevo en 10-15 minutos",
+	"anilist.error.details_unavailable":              "La integración con AniList no está disponible temporalmente. Inténtelo de nuevo en unos minutos.",
+	"anilist.error.details_unavailable.suggestions":  "La funcionalidad se restaurará automáticamente\nInténtelo de nuevo en 10-15 minutos\nUse la entrada manual para este manga",
+	"anilist.error.offline_not_found":                "La obra no está en la base de metadatos offline.",
+	"anilist.error.offline_not_found.suggestions":    "Desactive el modo offline para buscar en AniList\nImporte un dump de metadatos actualizado\nUse la entrada manual para este manga",
+	"anilist.error.unknown_error":                    "Ocurrió un error inesperado al buscar en AniList.",
+	"anilist.error.unknown_error.suggestions":        "Inténtelo de nuevo en unos instantes\nVerifique su conexión a internet\nUse la entrada manual de metadatos\nContacte con soporte si el problema persiste",
+	"anilist.error.service_recovered":                "La integración con AniList se restauró y funciona con normalidad.",
+	"anilist.error.service_recovered.suggestions":    "Ya puede buscar en AniList con normalidad\nLos resultados en caché siguen disponibles",
+}
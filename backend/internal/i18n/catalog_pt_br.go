@@ -0,0 +1,139 @@
+package i18n
+
+// catalogPtBR contém as mensagens em português do Brasil (idioma padrão)
+var catalogPtBR = map[string]string{
+	MsgFieldRequired:       "%s é obrigatório",
+	MsgInvalidPayload:      "Formato de payload inválido",
+	MsgInvalidRequestData:  "Formato dos dados da requisição inválido",
+	MsgNotImplemented:      "Funcionalidade %s ainda não implementada",
+	MsgLocaleChanged:       "Idioma alterado para português (Brasil)",
+	MsgUnsupportedEncoding: "Codificação não suportada: %s (use json ou msgpack)",
+	MsgReadOnly:            "%s não está disponível: este servidor é um espelho somente leitura",
+
+	MsgPathNotFound:           "Caminho não existe: %s",
+	MsgDiscoveryFailed:        "Falha ao descobrir estrutura: %v",
+	MsgLibraryDiscoveryFailed: "Falha ao descobrir biblioteca: %v",
+
+	MsgMissingMetadata:     "Metadados ausentes no payload",
+	MsgMissingMangaPath:    "mangaID ou mangaPath ausente no payload",
+	MsgMissingMangaName:    "mangaID ou mangaName ausente no payload",
+	MsgJSONDirFailed:       "Falha ao criar diretório de JSON: %v",
+	MsgJSONMarshalFailed:   "Falha ao serializar JSON atualizado: %v",
+	MsgJSONGenerateFailed:  "Falha ao gerar JSON ordenado: %v",
+	MsgMetadataWriteFailed: "Falha ao gravar arquivo de metadados: %v",
+	MsgJSONNotFound:        "Arquivo JSON não encontrado: %s",
+	MsgJSONParseFailed:     "Falha ao ler arquivo JSON: %v",
+	MsgFieldLocksFailed:    "Falha ao atualizar campos travados: %v",
+	MsgLinkSeriesFailed:    "Falha ao vincular séries relacionadas: %v",
+	MsgJSONScanFailed:      "Falha ao verificar os arquivos JSON: %v",
+	MsgJSONMigrationFailed: "Falha ao migrar os nomes dos arquivos JSON: %v",
+	MsgMetadataConflict:    "Os metadados de %s foram alterados por outro cliente; revise as mudanças antes de salvar",
+
+	MsgUploadsDisabled:           "Uploads desabilitados (parada de emergência ou modo seguro); envie resume_uploads para reativar",
+	MsgMirrorHostUnavailable:     "Host espelho indisponível: %s",
+	MsgCollectionStartFailed:     "Falha ao iniciar processamento da coleção: %v",
+	MsgCollectionNotFound:        "Coleção não encontrada",
+	MsgEstimateFailed:            "Falha ao estimar o envio da coleção: %v",
+	MsgShareLinkFailed:           "Falha ao criar o link compartilhado: %v",
+	MsgShareLinksDisabled:        "Links compartilhados indisponíveis (não foi possível carregar a chave de assinatura)",
+	MsgStateCleanupFailed:        "Falha ao limpar os estados de coleções: %v",
+	MsgAutoFillRunning:           "O preenchimento automático de metadados já está em andamento",
+	MsgStatusRefreshRunning:      "A atualização de status das obras já está em andamento",
+	MsgMirrorHealthRunning:       "A verificação de espelhos já está em andamento",
+	MsgMirrorHealthMissing:       "Nenhuma verificação de espelhos foi concluída ainda; use check_mirror_health primeiro",
+	MsgMirrorFailoverFailed:      "Falha ao reescrever os espelhos de %s: %v",
+	MsgSignedURLsRunning:         "A renovação das URLs assinadas já está em andamento",
+	MsgSignedURLsDisabled:        "Nenhum host de upload está configurado com URLs assinadas",
+	MsgAnalyticsFailed:           "Falha ao calcular as estatísticas de uploads: %v",
+	MsgReleasePostEmpty:          "Nenhum arquivo enviado encontrado para o lote %s",
+	MsgReleasePostFailed:         "Falha ao gerar o post de lançamento: %v",
+	MsgPublishNothing:            "Nenhum upload não publicado para %s capítulo %s",
+	MsgPublishFailed:             "Falha ao publicar o capítulo: %v",
+	MsgReviewDisabled:            "O fluxo de revisão de capítulos está desativado (ative review.enabled na configuração)",
+	MsgReviewerUnknown:           "Token de revisor desconhecido",
+	MsgReviewNotIdentified:       "Identifique-se com set_reviewer antes de mudar o estado de um capítulo",
+	MsgReviewFailed:              "Falha ao mudar o estado do capítulo: %v",
+	MsgNotifyBatchFailed:         "O lote %s terminou com %s de %s arquivos com falha",
+	MsgNotifyCollectionCompleted: "Coleção %s concluída",
+	MsgNotifyCollectionFailed:    "A coleção %s falhou: %s",
+	MsgNotifyTakedown:            "%s de %s páginas verificadas não estão mais disponíveis em %s",
+	MsgNotifyApprovalPending:     "%s capítulo %s aguarda aprovação do QC",
+	MsgNotifyReviewReturned:      "%s capítulo %s foi devolvido pelo QC",
+	MsgSiteRunning:               "A geração do site estático já está em andamento",
+	MsgSiteFailed:                "Falha ao gerar o site estático: %v",
+	MsgUpdateCheckFailed:         "Falha ao verificar atualizações: %v",
+	MsgUpdateFailed:              "Falha ao aplicar a atualização: %v",
+	MsgUpdateRunning:             "Uma atualização já está sendo aplicada",
+	MsgUpToDate:                  "Já está na versão mais recente (%s)",
+	MsgUpdateBusy:                "%d lote(s) ainda em envio; aguarde ou envie force para atualizar mesmo assim",
+	MsgJobNotFound:               "Job %s não encontrado ou já expirado",
+	MsgJobClaimDenied:            "Token de posse inválido para o job %s",
+
+	MsgSearchQueryRequired:      "O termo de busca é obrigatório",
+	MsgAniListIDRequired:        "O ID da AniList é obrigatório",
+	MsgAniListNotInitialized:    "Serviço AniList não inicializado",
+	MsgAniListSearchUnexpected:  "Erro inesperado ao buscar na AniList. Tente novamente ou use a entrada manual.",
+	MsgAniListDetailsUnexpected: "Erro inesperado ao obter detalhes da AniList. Tente novamente ou use a entrada manual.",
+	MsgAniListUnexpectedHints:   "Tente novamente em alguns instantes\nUse a entrada manual de metadados",
+	MsgOfflineImportFailed:      "Falha ao importar dump de metadados offline: %v",
+	MsgCubariImportFailed:       "Falha ao importar a obra do Cubari: %v",
+	MsgExportFailed:             "Falha ao exportar o capítulo: %v",
+	MsgBackupFailed:             "Falha ao verificar o backup: %v",
+	MsgProviderNotFound:         "Fonte de metadados desconhecida: %s",
+	MsgProviderIDRequired:       "O ID da obra na fonte é obrigatório",
+	MsgPluginFailed:             "O plugin %s falhou: %v",
+	MsgHookRejected:             "Recusado por um hook do pipeline: %v",
+	MsgPolicyRejected:           "Todos os %d arquivos foram recusados pela política de conteúdo",
+	MsgSystemFilesOnly:          "Todos os %d arquivos são ocultos ou de sistema (.DS_Store, Thumbs.db, desktop.ini...); ative includeHidden para enviá-los",
+	MsgFileContentTooLarge:      "%s tem %d bytes; o limite por arquivo é %d bytes",
+	MsgFileContentInvalid:       "Conteúdo de arquivo inválido: %v",
+	MsgPolicyCheckFailed:        "Falha ao verificar a política de conteúdo: %v",
+	MsgIntegrityRunning:         "A verificação ou o reparo de integridade já está em andamento",
+	MsgIntegrityMissing:         "Nenhuma verificação de integridade foi concluída ainda; use scan_integrity primeiro",
+	MsgArchiveFailed:            "Falha ao arquivar %s: %v",
+	MsgUnarchiveFailed:          "Falha ao desarquivar %s: %v",
+	MsgLibrarySearchFailed:      "Falha ao buscar na biblioteca: %v",
+	MsgConfigMissing:            "Dados de configuração ausentes ou inválidos",
+	MsgConfigGetFailed:          "Falha ao obter configuração atual",
+	MsgConfigUpdateFailed:       "Falha ao atualizar configuração: %v",
+	MsgConfigResetFailed:        "Falha ao restaurar configuração: %v",
+	MsgAniListProfileFailed:     "Erro no perfil de configuração da AniList: %v",
+
+	MsgGitHubInvalidFolders:     "Formato inválido da requisição de pastas do GitHub",
+	MsgGitHubInvalidUpload:      "Formato inválido da requisição de upload para o GitHub",
+	MsgGitHubCredentialsMissing: "Token e repositório do GitHub são obrigatórios",
+	MsgGitHubListFailed:         "Falha ao listar pastas do GitHub: %v",
+	MsgGitHubNoWorksSelected:    "Nenhuma obra selecionada para upload no GitHub",
+	MsgGitHubInvalidFilter:      "Filtro de seleção inválido: %v",
+	MsgGitHubFilterFailed:       "Falha ao resolver o filtro de seleção: %v",
+	MsgGitHubFilterNoMatch:      "Nenhuma obra corresponde ao filtro de seleção",
+	MsgGitHubNoJSONFiles:        "Nenhum arquivo JSON encontrado para upload",
+	MsgGitHubUploadFailed:       "Falha ao enviar para o GitHub: %v",
+	MsgGitHubBootstrapFailed:    "Falha ao preparar o repositório do GitHub: %v",
+
+	// Erros amigáveis da AniList (anilist.ErrorHandler)
+	"anilist.error.network_connectivity":             "Não foi possível conectar com a AniList. Verifique sua conexão com a internet.",
+	"anilist.error.network_connectivity.suggestions": "Verifique sua conexão com a internet\nTente novamente em alguns instantes\nUse a entrada manual de metadados como alternativa",
+	"anilist.error.search_timeout":                   "A busca na AniList está demorando mais que o esperado.",
+	"anilist.error.search_timeout.suggestions":       "Tente uma busca com termos mais específicos\nAguarde alguns minutos e tente novamente\nUse a entrada manual se precisar continuar",
+	"anilist.error.rate_limited":                     "Muitas buscas foram feitas recentemente. Aguarde um momento antes de tentar novamente.",
+	"anilist.error.rate_limited.suggestions":         "Aguarde 1-2 minutos antes de fazer nova busca\nUse o cache de resultados anteriores\nContinue com entrada manual dos metadados",
+	"anilist.error.anilist_server_error":             "A AniList está temporariamente indisponível. Tente novamente em alguns minutos.",
+	"anilist.error.anilist_server_error.suggestions": "Aguarde alguns minutos e tente novamente\nVerifique o status da AniList em suas redes sociais\nUse a entrada manual para não perder tempo",
+	"anilist.error.no_search_results":                "Nenhum manga foi encontrado com esse nome na AniList.",
+	"anilist.error.no_search_results.suggestions":    "Tente buscar com o nome em inglês ou japonês\nVerifique a ortografia do nome\nUse termos mais genéricos (ex: só o nome principal)\nPreencha os metadados manualmente",
+	"anilist.error.authorization_error":              "Problema de autorização com a AniList. O serviço pode estar temporariamente indisponível.",
+	"anilist.error.authorization_error.suggestions":  "Tente novamente em alguns minutos\nEntre em contato com o suporte se o problema persistir\nUse a entrada manual como alternativa",
+	"anilist.error.data_format_error":                "Os dados retornados pela AniList estão em formato inesperado.",
+	"anilist.error.data_format_error.suggestions":    "Tente buscar outro manga para verificar se o problema persiste\nReporte este problema aos desenvolvedores\nUse a entrada manual para este manga específico",
+	"anilist.error.service_unavailable":              "A integração com AniList foi temporariamente desabilitada devido a problemas recorrentes.",
+	"anilist.error.service_unavailable.suggestions":  "A funcionalidade será restaurada automaticamente quando o serviço estabilizar\nUse a entrada manual de metadados\nTente novamente em 10-15 minutos",
+	"anilist.error.details_unavailable":              "A integração com AniList está temporariamente indisponível. Tente novamente em alguns minutos.",
+	"anilist.error.details_unavailable.suggestions":  "A funcionalidade será restaurada automaticamente\nTente novamente em 10-15 minutos\nUse a entrada manual para este manga",
+	"anilist.error.offline_not_found":                "A obra não está no banco de metadados offline.",
+	"anilist.error.offline_not_found.suggestions":    "Desative o modo offline para buscar na AniList\nImporte um dump de metadados atualizado\nUse a entrada manual para este manga",
+	"anilist.error.unknown_error":                    "Ocorreu um erro inesperado ao buscar na AniList.",
+	"anilist.error.unknown_error.suggestions":        "Tente novamente em alguns instantes\nVerifique sua conexão com a internet\nUse a entrada manual de metadados\nEntre em contato com o suporte se o problema persistir",
+	"anilist.error.service_recovered":                "A integração com AniList foi restaurada e está funcionando normalmente.",
+	"anilist.error.service_recovered.suggestions":    "Agora você pode fazer buscas na AniList normalmente\nOs resultados em cache ainda estão disponíveis",
+}
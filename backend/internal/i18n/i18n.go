@@ -0,0 +1,79 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Locale identifica um idioma suportado pelas mensagens do servidor
+type Locale string
+
+const (
+	PtBR Locale = "pt-BR"
+	En   Locale = "en"
+	Es   Locale = "es"
+
+	// DefaultLocale é usado quando o cliente não informa idioma
+	DefaultLocale = PtBR
+)
+
+// catalogs reúne as mensagens de cada idioma (ver catalog_*.go)
+var catalogs = map[Locale]map[string]string{
+	PtBR: catalogPtBR,
+	En:   catalogEn,
+	Es:   catalogEs,
+}
+
+// SupportedLocales retorna os idiomas disponíveis
+func SupportedLocales() []Locale {
+	return []Locale{PtBR, En, Es}
+}
+
+// ParseLocale normaliza um identificador de idioma ("pt", "en-US", "es_ES",
+// cabeçalho Accept-Language...) para um Locale suportado
+func ParseLocale(value string) Locale {
+	for _, part := range strings.Split(value, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		tag = strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+		if tag == "" {
+			continue
+		}
+
+		switch strings.SplitN(tag, "-", 2)[0] {
+		case "pt":
+			return PtBR
+		case "en":
+			return En
+		case "es":
+			return Es
+		}
+	}
+
+	return DefaultLocale
+}
+
+// T retorna a mensagem traduzida para a chave, formatada com args.
+// Cai para o idioma padrão e, por último, para a própria chave.
+func T(locale Locale, key string, args ...interface{}) string {
+	message, ok := catalogs[locale][key]
+	if !ok {
+		message, ok = catalogs[DefaultLocale][key]
+	}
+	if !ok {
+		message = key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Lines retorna uma mensagem de várias linhas (ex: lista de sugestões) como slice
+func Lines(locale Locale, key string) []string {
+	message := T(locale, key)
+	if message == key {
+		return nil
+	}
+	return strings.Split(message, "\n")
+}
@@ -0,0 +1,119 @@
+package i18n
+
+// Chaves das mensagens enviadas aos clientes
+const (
+	// Requisições
+	MsgFieldRequired       = "request.field_required"
+	MsgInvalidPayload      = "request.invalid_payload"
+	MsgInvalidRequestData  = "request.invalid_data"
+	MsgNotImplemented      = "request.not_implemented"
+	MsgLocaleChanged       = "request.locale_changed"
+	MsgUnsupportedEncoding = "request.unsupported_encoding"
+	MsgReadOnly            = "request.read_only"
+
+	// Descoberta
+	MsgPathNotFound           = "discovery.path_not_found"
+	MsgDiscoveryFailed        = "discovery.failed"
+	MsgLibraryDiscoveryFailed = "discovery.library_failed"
+
+	// Metadados JSON
+	MsgMissingMetadata     = "metadata.missing_metadata"
+	MsgMissingMangaPath    = "metadata.missing_manga_path"
+	MsgMissingMangaName    = "metadata.missing_manga_name"
+	MsgJSONDirFailed       = "metadata.json_dir_failed"
+	MsgJSONMarshalFailed   = "metadata.json_marshal_failed"
+	MsgJSONGenerateFailed  = "metadata.json_generate_failed"
+	MsgMetadataWriteFailed = "metadata.write_failed"
+	MsgJSONNotFound        = "metadata.json_not_found"
+	MsgJSONParseFailed     = "metadata.json_parse_failed"
+	MsgMetadataConflict    = "metadata.conflict"
+	MsgFieldLocksFailed    = "metadata.field_locks_failed"
+	MsgLinkSeriesFailed    = "metadata.link_series_failed"
+	MsgJSONScanFailed      = "metadata.json_scan_failed"
+	MsgJSONMigrationFailed = "metadata.json_migration_failed"
+
+	// Uploads e coleções
+	MsgUploadsDisabled           = "upload.disabled"
+	MsgMirrorHostUnavailable     = "upload.mirror_host_unavailable"
+	MsgCollectionStartFailed     = "collection.start_failed"
+	MsgCollectionNotFound        = "collection.not_found"
+	MsgEstimateFailed            = "collection.estimate_failed"
+	MsgShareLinkFailed           = "share.link_failed"
+	MsgShareLinksDisabled        = "share.disabled"
+	MsgStateCleanupFailed        = "state.cleanup_failed"
+	MsgAutoFillRunning           = "autofill.already_running"
+	MsgStatusRefreshRunning      = "status_refresh.already_running"
+	MsgMirrorHealthRunning       = "mirror_health.already_running"
+	MsgMirrorHealthMissing       = "mirror_health.not_checked"
+	MsgMirrorFailoverFailed      = "mirror_health.failover_failed"
+	MsgSignedURLsRunning         = "signed_urls.already_running"
+	MsgSignedURLsDisabled        = "signed_urls.disabled"
+	MsgAnalyticsFailed           = "analytics.failed"
+	MsgReleasePostEmpty          = "release_post.empty"
+	MsgReleasePostFailed         = "release_post.failed"
+	MsgPublishNothing            = "publish.nothing"
+	MsgPublishFailed             = "publish.failed"
+	MsgReviewDisabled            = "review.disabled"
+	MsgReviewerUnknown           = "review.reviewer_unknown"
+	MsgReviewNotIdentified       = "review.not_identified"
+	MsgReviewFailed              = "review.failed"
+	MsgNotifyBatchFailed         = "notify.batch_failed"
+	MsgNotifyCollectionCompleted = "notify.collection_completed"
+	MsgNotifyCollectionFailed    = "notify.collection_failed"
+	MsgNotifyTakedown            = "notify.takedown"
+	MsgNotifyApprovalPending     = "notify.approval_pending"
+	MsgNotifyReviewReturned      = "notify.review_returned"
+	MsgSiteRunning               = "site.already_running"
+	MsgSiteFailed                = "site.failed"
+	MsgUpdateCheckFailed         = "update.check_failed"
+	MsgUpdateFailed              = "update.failed"
+	MsgUpdateRunning             = "update.already_running"
+	MsgUpToDate                  = "update.up_to_date"
+	MsgUpdateBusy                = "update.uploads_running"
+	MsgJobNotFound               = "job.not_found"
+	MsgJobClaimDenied            = "job.claim_denied"
+
+	// AniList
+	MsgSearchQueryRequired      = "anilist.search_query_required"
+	MsgAniListIDRequired        = "anilist.id_required"
+	MsgAniListNotInitialized    = "anilist.not_initialized"
+	MsgAniListSearchUnexpected  = "anilist.search_unexpected"
+	MsgAniListDetailsUnexpected = "anilist.details_unexpected"
+	MsgAniListUnexpectedHints   = "anilist.unexpected.suggestions"
+	MsgOfflineImportFailed      = "anilist.offline_import_failed"
+	MsgCubariImportFailed       = "import.cubari_failed"
+	MsgExportFailed             = "export.failed"
+	MsgBackupFailed             = "backup.verify_failed"
+	MsgProviderNotFound         = "metadata_provider.not_found"
+	MsgProviderIDRequired       = "metadata_provider.id_required"
+	MsgPluginFailed             = "plugin.failed"
+	MsgHookRejected             = "hook.rejected"
+	MsgPolicyRejected           = "policy.rejected"
+	MsgSystemFilesOnly          = "upload.system_files_only"
+	MsgFileContentTooLarge      = "upload.file_content_too_large"
+	MsgFileContentInvalid       = "upload.file_content_invalid"
+	MsgPolicyCheckFailed        = "policy.check_failed"
+	MsgIntegrityRunning         = "integrity.already_running"
+	MsgIntegrityMissing         = "integrity.not_scanned"
+	MsgArchiveFailed            = "archive.failed"
+	MsgUnarchiveFailed          = "archive.unarchive_failed"
+	MsgLibrarySearchFailed      = "library.search_failed"
+	MsgConfigMissing            = "config.missing"
+	MsgConfigGetFailed          = "config.get_failed"
+	MsgConfigUpdateFailed       = "config.update_failed"
+	MsgConfigResetFailed        = "config.reset_failed"
+	MsgAniListProfileFailed     = "anilist.profile_failed"
+
+	// GitHub
+	MsgGitHubInvalidFolders     = "github.invalid_folders_request"
+	MsgGitHubInvalidUpload      = "github.invalid_upload_request"
+	MsgGitHubCredentialsMissing = "github.credentials_missing"
+	MsgGitHubListFailed         = "github.list_failed"
+	MsgGitHubNoWorksSelected    = "github.no_works_selected"
+	MsgGitHubInvalidFilter      = "github.invalid_filter"
+	MsgGitHubFilterFailed       = "github.filter_failed"
+	MsgGitHubFilterNoMatch      = "github.filter_no_match"
+	MsgGitHubNoJSONFiles        = "github.no_json_files"
+	MsgGitHubUploadFailed       = "github.upload_failed"
+	MsgGitHubBootstrapFailed    = "github.bootstrap_failed"
+)
@@ -0,0 +1,501 @@
+// Package integrity cruza as quatro fontes de verdade da biblioteca — pastas
+// em disco, registro de IDs, JSONs gerados e histórico de uploads — e lista as
+// inconsistências encontradas. Cada problema traz um ID estável e, quando há
+// uma correção segura, a ação de reparo automático correspondente.
+package integrity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/analytics"
+	"go-upload/backend/internal/discovery"
+	"go-upload/backend/internal/mangaid"
+	"go-upload/backend/internal/metadata"
+	"go-upload/backend/internal/registry"
+)
+
+// ErrRunning é retornado quando já existe uma verificação ou reparo em andamento
+var ErrRunning = errors.New("verificação de integridade já em andamento")
+
+// ErrNoReport é retornado por Repair antes da primeira verificação
+var ErrNoReport = errors.New("nenhuma verificação de integridade concluída")
+
+// Tipos de problema
+const (
+	KindInvalidJSON        = "invalid_json"         // JSON ilegível
+	KindEmptyChapter       = "empty_chapter"        // capítulo do JSON sem páginas
+	KindEmptyChapterFolder = "empty_chapter_folder" // pasta de capítulo sem imagens
+	KindMissingJSON        = "missing_json"         // obra enviada (histórico) ainda na biblioteca, sem JSON
+	KindOrphanHistory      = "orphan_history"       // histórico de uma obra cujo JSON e pasta sumiram
+	KindOrphanRegistry     = "orphan_registry"      // registro de uma obra cujo JSON e pasta sumiram
+	KindUnregistered       = "unregistered_json"    // JSON sem entrada no registro de IDs
+)
+
+// Ações de reparo automático
+const (
+	RepairRemoveChapter  = "remove_chapter"        // apaga o capítulo do JSON (fica na lixeira)
+	RepairPruneHistory   = "prune_history"         // apaga as linhas do histórico da obra
+	RepairRemoveRegistry = "remove_registry_entry" // apaga a entrada do registro
+	RepairRegister       = "register_json"         // registra a obra com o título do JSON
+)
+
+// RepairMode identifica os capítulos removidos pelo reparo na lixeira do JSON
+const RepairMode = "integrity_repair"
+
+// Severidades
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue é uma inconsistência encontrada na biblioteca
+type Issue struct {
+	ID       string `json:"id"` // estável entre verificações: kind:mangaId[:capítulo]
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"`
+	MangaID  string `json:"mangaId,omitempty"`
+	Chapter  string `json:"chapter,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Detail   string `json:"detail"`
+	Repair   string `json:"repair,omitempty"` // vazio = exige intervenção manual
+}
+
+// Report é o resultado de uma verificação
+type Report struct {
+	StartedAt       time.Time `json:"startedAt"`
+	FinishedAt      time.Time `json:"finishedAt"`
+	JSONs           int       `json:"jsons"`
+	Folders         int       `json:"folders"`
+	RegistryEntries int       `json:"registryEntries"`
+	HistoryRecords  int       `json:"historyRecords"`
+	Issues          []Issue   `json:"issues"`
+	Fixable         int       `json:"fixable"`
+	Error           string    `json:"error,omitempty"` // falha que interrompeu a verificação
+}
+
+// RepairResult resume um reparo
+type RepairResult struct {
+	Repaired []string          `json:"repaired"`
+	Failed   map[string]string `json:"failed,omitempty"`  // ID do problema -> erro
+	Skipped  []string          `json:"skipped,omitempty"` // IDs desconhecidos ou sem reparo automático
+}
+
+// Config define onde ficam a biblioteca e os JSONs
+type Config struct {
+	LibraryRoot string      // pastas das obras (vazio = não verifica o disco)
+	JSONDir     string      // diretório dos JSONs da biblioteca
+	Lock        sync.Locker // serializa os reparos com as edições de metadados (opcional)
+}
+
+// Scanner verifica e repara a integridade da biblioteca
+type Scanner struct {
+	config    Config
+	generator *metadata.JSONGenerator
+	registry  *registry.Registry
+	history   *analytics.History
+
+	mutex   sync.Mutex
+	running bool
+	last    *Report
+}
+
+// NewScanner cria o verificador de integridade
+func NewScanner(config Config, generator *metadata.JSONGenerator, idRegistry *registry.Registry, history *analytics.History) *Scanner {
+	return &Scanner{
+		config:    config,
+		generator: generator,
+		registry:  idRegistry,
+		history:   history,
+	}
+}
+
+// LastReport retorna o relatório da última verificação (nil se nunca executou)
+func (s *Scanner) LastReport() *Report {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.last
+}
+
+// Trigger inicia uma verificação em background (ErrRunning se já houver uma)
+func (s *Scanner) Trigger(onReport func(*Report)) error {
+	if !s.begin() {
+		return ErrRunning
+	}
+
+	go func() {
+		report := s.scan()
+		s.finish(report)
+		if onReport != nil {
+			onReport(report)
+		}
+	}()
+	return nil
+}
+
+// Repair aplica o reparo automático dos problemas informados (todos os
+// reparáveis quando ids está vazio), usando o relatório da última verificação
+func (s *Scanner) Repair(ids []string) (*RepairResult, error) {
+	if !s.begin() {
+		return nil, ErrRunning
+	}
+	defer s.finish(nil)
+
+	report := s.LastReport()
+	if report == nil {
+		return nil, ErrNoReport
+	}
+
+	byID := make(map[string]Issue, len(report.Issues))
+	for _, issue := range report.Issues {
+		byID[issue.ID] = issue
+	}
+	if len(ids) == 0 {
+		for _, issue := range report.Issues {
+			if issue.Repair != "" {
+				ids = append(ids, issue.ID)
+			}
+		}
+	}
+
+	if s.config.Lock != nil {
+		s.config.Lock.Lock()
+		defer s.config.Lock.Unlock()
+	}
+
+	result := &RepairResult{Repaired: []string{}, Failed: make(map[string]string)}
+	for _, id := range ids {
+		issue, known := byID[id]
+		if !known || issue.Repair == "" {
+			result.Skipped = append(result.Skipped, id)
+			continue
+		}
+		if err := s.repair(issue); err != nil {
+			result.Failed[id] = err.Error()
+			continue
+		}
+		result.Repaired = append(result.Repaired, id)
+	}
+	s.forget(result.Repaired)
+	return result, nil
+}
+
+// forget tira do último relatório os problemas já reparados
+func (s *Scanner) forget(ids []string) {
+	repaired := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		repaired[id] = true
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.last == nil || len(repaired) == 0 {
+		return
+	}
+	report := *s.last
+	report.Issues = []Issue{}
+	report.Fixable = 0
+	for _, issue := range s.last.Issues {
+		if repaired[issue.ID] {
+			continue
+		}
+		report.Issues = append(report.Issues, issue)
+		if issue.Repair != "" {
+			report.Fixable++
+		}
+	}
+	s.last = &report
+}
+
+// repair executa a ação de reparo de um problema
+func (s *Scanner) repair(issue Issue) error {
+	switch issue.Repair {
+	case RepairRemoveChapter:
+		_, err := s.generator.RemoveChapters(issue.Path, []string{issue.Chapter}, RepairMode)
+		return err
+	case RepairPruneHistory:
+		_, err := s.history.Remove(func(record analytics.Record) bool {
+			return record.MangaID == issue.MangaID
+		})
+		return err
+	case RepairRemoveRegistry:
+		return s.registry.Remove(issue.MangaID)
+	case RepairRegister:
+		raw, err := os.ReadFile(issue.Path)
+		if err != nil {
+			return err
+		}
+		var manga metadata.MangaJSON
+		if err := json.Unmarshal(raw, &manga); err != nil {
+			return fmt.Errorf("failed to parse JSON: %v", err)
+		}
+		return s.registry.Link(issue.MangaID, manga.Title, 0)
+	}
+	return fmt.Errorf("unknown repair action %q", issue.Repair)
+}
+
+// begin marca uma execução como em andamento; false se já havia uma
+func (s *Scanner) begin() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.running {
+		return false
+	}
+	s.running = true
+	return true
+}
+
+// finish encerra a execução, guardando o relatório quando houver
+func (s *Scanner) finish(report *Report) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.running = false
+	if report != nil {
+		s.last = report
+	}
+}
+
+// scan cruza disco, registro, JSONs e histórico
+func (s *Scanner) scan() *Report {
+	report := &Report{StartedAt: time.Now(), Issues: []Issue{}}
+	defer func() {
+		sort.Slice(report.Issues, func(i, j int) bool { return report.Issues[i].ID < report.Issues[j].ID })
+		for _, issue := range report.Issues {
+			if issue.Repair != "" {
+				report.Fixable++
+			}
+		}
+		report.FinishedAt = time.Now()
+	}()
+
+	jsons, invalid, err := s.scanJSONs(report)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	folders, err := s.scanFolders(report)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	// Registro de IDs
+	registered := make(map[string]bool)
+	for _, entry := range s.registry.Entries() {
+		report.RegistryEntries++
+		registered[entry.MangaID] = true
+		// Obras arquivadas não têm JSON no diretório de propósito
+		if entry.Archived || jsons[entry.MangaID] != "" || folders[entry.MangaID] != "" {
+			continue
+		}
+		report.Issues = append(report.Issues, Issue{
+			ID:       issueID(KindOrphanRegistry, entry.MangaID, ""),
+			Kind:     KindOrphanRegistry,
+			Severity: SeverityWarning,
+			MangaID:  entry.MangaID,
+			Detail:   fmt.Sprintf("registry entry %q has no JSON and no library folder", entry.Title),
+			Repair:   RepairRemoveRegistry,
+		})
+	}
+	for mangaID, jsonPath := range jsons {
+		if registered[mangaID] || invalid[mangaID] {
+			continue
+		}
+		report.Issues = append(report.Issues, Issue{
+			ID:       issueID(KindUnregistered, mangaID, ""),
+			Kind:     KindUnregistered,
+			Severity: SeverityWarning,
+			MangaID:  mangaID,
+			Path:     jsonPath,
+			Detail:   "JSON is not in the ID registry",
+			Repair:   RepairRegister,
+		})
+	}
+
+	// Histórico de uploads
+	records, err := s.history.Load()
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	report.HistoryRecords = len(records)
+	uploads := make(map[string]int)
+	for _, record := range records {
+		uploads[record.MangaID]++
+	}
+	for mangaID, count := range uploads {
+		if jsons[mangaID] != "" || s.registry.IsArchived(mangaID) {
+			continue
+		}
+		if folder := folders[mangaID]; folder != "" {
+			report.Issues = append(report.Issues, Issue{
+				ID:       issueID(KindMissingJSON, mangaID, ""),
+				Kind:     KindMissingJSON,
+				Severity: SeverityError,
+				MangaID:  mangaID,
+				Path:     folder,
+				Detail:   fmt.Sprintf("%d uploaded files but no JSON; regenerate it by uploading the series again", count),
+			})
+			continue
+		}
+		report.Issues = append(report.Issues, Issue{
+			ID:       issueID(KindOrphanHistory, mangaID, ""),
+			Kind:     KindOrphanHistory,
+			Severity: SeverityWarning,
+			MangaID:  mangaID,
+			Detail:   fmt.Sprintf("%d history records point at a deleted JSON", count),
+			Repair:   RepairPruneHistory,
+		})
+	}
+	return report
+}
+
+// scanJSONs lê os JSONs e retorna o caminho de cada obra por ID, além das
+// obras cujo JSON não pôde ser lido
+func (s *Scanner) scanJSONs(report *Report) (map[string]string, map[string]bool, error) {
+	files, err := filepath.Glob(filepath.Join(s.config.JSONDir, "*.json"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list JSON files: %v", err)
+	}
+
+	jsons := make(map[string]string, len(files))
+	invalid := make(map[string]bool)
+	for _, file := range files {
+		mangaID := metadata.LockKey(file)
+		jsons[mangaID] = file
+		report.JSONs++
+
+		raw, err := os.ReadFile(file)
+		var manga metadata.MangaJSON
+		if err == nil {
+			err = json.Unmarshal(raw, &manga)
+		}
+		if err != nil {
+			invalid[mangaID] = true
+			report.Issues = append(report.Issues, Issue{
+				ID:       issueID(KindInvalidJSON, mangaID, ""),
+				Kind:     KindInvalidJSON,
+				Severity: SeverityError,
+				MangaID:  mangaID,
+				Path:     file,
+				Detail:   err.Error(),
+			})
+			continue
+		}
+
+		for key, chapter := range manga.Chapters {
+			if chapterPages(chapter) > 0 {
+				continue
+			}
+			report.Issues = append(report.Issues, Issue{
+				ID:       issueID(KindEmptyChapter, mangaID, key),
+				Kind:     KindEmptyChapter,
+				Severity: SeverityError,
+				MangaID:  mangaID,
+				Chapter:  key,
+				Path:     file,
+				Detail:   fmt.Sprintf("chapter %q has no pages", chapter.Title),
+				Repair:   RepairRemoveChapter,
+			})
+		}
+	}
+	return jsons, invalid, nil
+}
+
+// scanFolders percorre a biblioteca: retorna as pastas por ID de obra (nome
+// sanitizado, como nos JSONs) e relata pastas finais sem nenhuma imagem
+func (s *Scanner) scanFolders(report *Report) (map[string]string, error) {
+	folders := make(map[string]string)
+	if s.config.LibraryRoot == "" {
+		return folders, nil
+	}
+	if _, err := os.Stat(s.config.LibraryRoot); err != nil {
+		if os.IsNotExist(err) {
+			return folders, nil
+		}
+		return nil, fmt.Errorf("failed to read library: %v", err)
+	}
+
+	subdirs := make(map[string]int)
+	images := make(map[string]int)
+	err := filepath.WalkDir(s.config.LibraryRoot, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == s.config.LibraryRoot {
+			return nil
+		}
+		if entry.IsDir() {
+			if strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			report.Folders++
+			subdirs[filepath.Dir(path)]++
+			if _, seen := subdirs[path]; !seen {
+				subdirs[path] = 0
+			}
+			mangaID := mangaid.Normalize(entry.Name())
+			if _, exists := folders[mangaID]; !exists {
+				folders[mangaID] = path
+			}
+			return nil
+		}
+		if discovery.SupportedExtensions[strings.ToLower(filepath.Ext(path))] {
+			images[filepath.Dir(path)]++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk library: %v", err)
+	}
+
+	// Pastas sem subpastas são capítulos (ou obras vazias): precisam de imagens
+	for dir, count := range subdirs {
+		if count > 0 || images[dir] > 0 {
+			continue
+		}
+		rel, _ := filepath.Rel(s.config.LibraryRoot, dir)
+		mangaID := ""
+		if parent := filepath.Dir(rel); parent != "." {
+			mangaID = mangaid.Normalize(filepath.Base(parent))
+		}
+		report.Issues = append(report.Issues, Issue{
+			ID:       issueID(KindEmptyChapterFolder, mangaID, rel),
+			Kind:     KindEmptyChapterFolder,
+			Severity: SeverityWarning,
+			MangaID:  mangaID,
+			Chapter:  filepath.Base(rel),
+			Path:     dir,
+			Detail:   "folder has no supported images",
+		})
+	}
+	return folders, nil
+}
+
+// chapterPages retorna o número de páginas do maior grupo do capítulo
+func chapterPages(chapter metadata.Chapter) int {
+	pages := 0
+	for _, urls := range chapter.Groups {
+		if len(urls) > pages {
+			pages = len(urls)
+		}
+	}
+	return pages
+}
+
+func issueID(kind, mangaID, chapter string) string {
+	if chapter == "" {
+		return kind + ":" + mangaID
+	}
+	return kind + ":" + mangaID + ":" + chapter
+}
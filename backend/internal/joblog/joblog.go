@@ -0,0 +1,128 @@
+package joblog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Level é a severidade de uma linha de log
+type Level string
+
+const (
+	Info  Level = "info"
+	Warn  Level = "warn"
+	Error Level = "error"
+)
+
+// rank ordena os níveis para o filtro de Get
+var rank = map[Level]int{Info: 0, Warn: 1, Error: 2}
+
+// Entry é uma linha de log de um job
+type Entry struct {
+	Seq     int64     `json:"seq"`
+	Time    time.Time `json:"time"`
+	Level   Level     `json:"level"`
+	Message string    `json:"message"`
+}
+
+// Log é o trecho do log de um job devolvido por Get
+type Log struct {
+	JobID   string  `json:"jobId"`
+	Entries []Entry `json:"entries"`
+	LastSeq int64   `json:"lastSeq"`
+	Dropped int64   `json:"dropped"` // linhas antigas descartadas pelo limite do buffer
+}
+
+// ring guarda as últimas linhas de um job; cresce sob demanda até max
+type ring struct {
+	entries []Entry
+	max     int
+	start   int // linha mais antiga quando o buffer está cheio
+	lastSeq int64
+}
+
+func (r *ring) add(entry Entry) {
+	r.lastSeq++
+	entry.Seq = r.lastSeq
+	if len(r.entries) < r.max {
+		r.entries = append(r.entries, entry)
+		return
+	}
+	r.entries[r.start] = entry
+	r.start = (r.start + 1) % r.max
+}
+
+// Store mantém um buffer circular de linhas por lote/coleção. Os métodos
+// aceitam Store nil, para que os processadores funcionem sem captura.
+type Store struct {
+	maxLines int
+	maxJobs  int
+
+	mu    sync.Mutex
+	jobs  map[string]*ring
+	order []string // jobs por ordem de criação, para descartar os mais antigos
+}
+
+// NewStore cria o armazenamento com até maxLines linhas por job e maxJobs jobs
+func NewStore(maxLines, maxJobs int) *Store {
+	if maxLines <= 0 {
+		maxLines = 1000
+	}
+	if maxJobs <= 0 {
+		maxJobs = 200
+	}
+	return &Store{maxLines: maxLines, maxJobs: maxJobs, jobs: make(map[string]*ring)}
+}
+
+// Add registra uma linha no log do job
+func (s *Store) Add(jobID string, level Level, format string, args ...interface{}) {
+	if s == nil || jobID == "" {
+		return
+	}
+	entry := Entry{Time: time.Now(), Level: level, Message: fmt.Sprintf(format, args...)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buffer, exists := s.jobs[jobID]
+	if !exists {
+		if len(s.order) >= s.maxJobs {
+			delete(s.jobs, s.order[0])
+			s.order = s.order[1:]
+		}
+		buffer = &ring{max: s.maxLines}
+		s.jobs[jobID] = buffer
+		s.order = append(s.order, jobID)
+	}
+	buffer.add(entry)
+}
+
+// Get retorna as linhas do job com número maior que since e severidade a
+// partir de minLevel ("" = todas); false se o job não tem log
+func (s *Store) Get(jobID string, since int64, minLevel Level) (*Log, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buffer, exists := s.jobs[jobID]
+	if !exists {
+		return nil, false
+	}
+	log := &Log{
+		JobID:   jobID,
+		Entries: []Entry{},
+		LastSeq: buffer.lastSeq,
+		Dropped: buffer.lastSeq - int64(len(buffer.entries)),
+	}
+	for i := range buffer.entries {
+		entry := buffer.entries[(buffer.start+i)%len(buffer.entries)]
+		if entry.Seq <= since || rank[entry.Level] < rank[minLevel] {
+			continue
+		}
+		log.Entries = append(log.Entries, entry)
+	}
+	return log, true
+}
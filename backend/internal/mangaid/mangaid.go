@@ -0,0 +1,133 @@
+// Package mangaid resolve o ID de uma obra (nome da pasta, às vezes com o
+// prefixo "auto-" dos IDs gerados pelo frontend) para o nome do seu JSON. Todo
+// o servidor usa as mesmas regras: metadados, geração de JSON, registro de IDs,
+// travas de campos, histórico de uploads e sincronização com o GitHub.
+//
+// Regras, aplicadas nesta ordem:
+//
+//	"auto-Solo Leveling"    → "Solo_Leveling"     prefixo "auto-" removido
+//	"One Piece: Film Red"   → "One_Piece_Film_Red" <>:"/\|?* removidos
+//	"Ação  Total"           → "Ação_Total"         espaços viram "_", acentos mantidos
+//	"__Berserk__"           → "Berserk"            "_" repetidos colapsados e aparados
+//
+// Versões anteriores tinham uma segunda regra em save_metadata, load_metadata e
+// github_upload, que trocava acentos e caracteres inválidos por "_" (e, no
+// github_upload, mantinha o prefixo "auto-"). Resolve ainda encontra os JSONs
+// criados por ela, para que nada salvo antes fique órfão.
+package mangaid
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// AutoPrefix é o prefixo dos IDs gerados automaticamente pelo frontend
+const AutoPrefix = "auto-"
+
+var (
+	invalidChars  = regexp.MustCompile(`[<>:"/\\|?*]`)
+	underscoreRun = regexp.MustCompile(`_+`)
+)
+
+// StripPrefix remove o prefixo "auto-" de um ID
+func StripPrefix(id string) string {
+	return strings.TrimPrefix(id, AutoPrefix)
+}
+
+// Sanitize aplica as regras de nome de arquivo a um nome, sem tratar o prefixo "auto-"
+func Sanitize(name string) string {
+	sanitized := invalidChars.ReplaceAllString(name, "")
+	sanitized = strings.ReplaceAll(sanitized, " ", "_")
+	sanitized = underscoreRun.ReplaceAllString(sanitized, "_")
+	return strings.Trim(sanitized, "_")
+}
+
+// Normalize retorna o ID estável de uma obra: o nome do JSON sem extensão, usado
+// também como chave do registro, das travas e do histórico
+func Normalize(id string) string {
+	return Sanitize(StripPrefix(id))
+}
+
+// FileName retorna o nome do JSON de uma obra pela regra atual
+func FileName(id string) string {
+	return Normalize(id) + ".json"
+}
+
+// Resolve retorna o caminho do JSON de uma obra em dir. Se o JSON da regra atual
+// não existe mas um criado pela regra antiga existe, retorna o antigo e legacy
+// é verdadeiro; se nenhum existe, retorna o caminho da regra atual.
+func Resolve(dir, id string) (path string, legacy bool) {
+	path = filepath.Join(dir, FileName(id))
+	if _, err := os.Stat(path); err == nil {
+		return path, false
+	}
+	for _, name := range legacyNames(id) {
+		candidate := filepath.Join(dir, name+".json")
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, true
+		}
+	}
+	return path, false
+}
+
+// Path é Resolve sem a indicação de arquivo legado
+func Path(dir, id string) string {
+	path, _ := Resolve(dir, id)
+	return path
+}
+
+// legacyNames lista os nomes (sem extensão) que a regra antiga pode ter gerado
+// para id, do mais provável para o menos, sem repetir o da regra atual
+func legacyNames(id string) []string {
+	current := Normalize(id)
+	seen := map[string]bool{current: true}
+	var names []string
+	for _, name := range []string{legacySanitize(StripPrefix(id)), legacySanitize(id), Sanitize(id)} {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// legacyAccents são as trocas de acentos da regra antiga
+var legacyAccents = strings.NewReplacer(
+	"ç", "c", "Ç", "C",
+	"ã", "a", "Ã", "A",
+	"à", "a", "À", "A",
+	"á", "a", "Á", "A",
+	"â", "a", "Â", "A",
+	"ä", "a", "Ä", "A",
+	"é", "e", "É", "E",
+	"è", "e", "È", "E",
+	"ê", "e", "Ê", "E",
+	"ë", "e", "Ë", "E",
+	"í", "i", "Í", "I",
+	"ì", "i", "Ì", "I",
+	"î", "i", "Î", "I",
+	"ï", "i", "Ï", "I",
+	"ó", "o", "Ó", "O",
+	"ò", "o", "Ò", "O",
+	"ô", "o", "Ô", "O",
+	"õ", "o", "Õ", "O",
+	"ö", "o", "Ö", "O",
+	"ú", "u", "Ú", "U",
+	"ù", "u", "Ù", "U",
+	"û", "u", "Û", "U",
+	"ü", "u", "Ü", "U",
+	"ñ", "n", "Ñ", "N",
+)
+
+// legacySanitize reproduz a regra antiga: acentos comuns trocados pela letra sem
+// acento e caracteres inválidos trocados por "_" em vez de removidos
+func legacySanitize(name string) string {
+	sanitized := legacyAccents.Replace(name)
+	sanitized = strings.ReplaceAll(sanitized, " ", "_")
+	sanitized = invalidChars.ReplaceAllString(sanitized, "_")
+	sanitized = underscoreRun.ReplaceAllString(sanitized, "_")
+	return strings.Trim(sanitized, "_")
+}
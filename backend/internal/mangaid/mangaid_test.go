@@ -0,0 +1,159 @@
+package mangaid
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"auto-Solo Leveling", "Solo_Leveling"},
+		{"One Piece: Film Red", "One_Piece_Film_Red"},
+		{"Ação  Total", "Ação_Total"},
+		{"__Berserk__", "Berserk"},
+		{" Vagabond ", "Vagabond"},
+		{`a<b>c"d/e\f|g?h*i`, "abcdefghi"},
+		{"auto-", ""},
+		{"Solo_Leveling", "Solo_Leveling"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.id); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestLegacyNames(t *testing.T) {
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{"Ação Total", []string{"Acao_Total"}},
+		{"auto-Solo Leveling", []string{"auto-Solo_Leveling"}},
+		{"auto-Ação: Total", []string{"Acao_Total", "auto-Acao_Total", "auto-Ação_Total"}},
+		{"One Piece: Film Red", nil}, // a regra antiga gera o mesmo nome
+		{"Berserk", nil},
+	}
+	for _, tt := range tests {
+		if got := legacyNames(tt.id); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("legacyNames(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+// writeJSONs cria JSONs vazios em dir
+func writeJSONs(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      []string
+		id         string
+		wantFile   string
+		wantLegacy bool
+	}{
+		{"current", []string{"Ação_Total.json"}, "Ação Total", "Ação_Total.json", false},
+		{"legacy accents", []string{"Acao_Total.json"}, "Ação Total", "Acao_Total.json", true},
+		{"current wins over legacy", []string{"Acao_Total.json", "Ação_Total.json"}, "Ação Total", "Ação_Total.json", false},
+		{"legacy auto prefix", []string{"auto-Solo_Leveling.json"}, "auto-Solo Leveling", "auto-Solo_Leveling.json", true},
+		{"missing", nil, "auto-Berserk", "Berserk.json", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeJSONs(t, dir, tt.files...)
+
+			path, legacy := Resolve(dir, tt.id)
+			if path != filepath.Join(dir, tt.wantFile) || legacy != tt.wantLegacy {
+				t.Errorf("Resolve(%q) = %q, %v; want %q, %v", tt.id, filepath.Base(path), legacy, tt.wantFile, tt.wantLegacy)
+			}
+		})
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	files := []string{
+		"Acao_Total.json",         // pasta "Ação Total", regra antiga
+		"auto-Solo_Leveling.json", // github_upload antigo, com prefixo
+		"Berserk.json",            // já na regra atual
+		"Acao_Mix.json",           // as duas regras geraram arquivo para "Ação Mix"
+		"Ação_Mix.json",
+	}
+	ids := []string{"Ação Total", "Ação Mix", "Berserk"}
+
+	wantRenamed := []Rename{
+		{From: "Acao_Total.json", To: "Ação_Total.json"},
+		{From: "auto-Solo_Leveling.json", To: "Solo_Leveling.json"},
+	}
+	wantCollisions := []Collision{
+		{Files: []string{"Acao_Mix.json"}, Target: "Ação_Mix.json", Reason: CollisionTargetExists},
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		dir := t.TempDir()
+		writeJSONs(t, dir, files...)
+
+		migration, err := Migrate(dir, ids, dryRun)
+		if err != nil {
+			t.Fatalf("Migrate(dryRun=%v): %v", dryRun, err)
+		}
+		if migration.DryRun != dryRun || migration.Scanned != len(files) {
+			t.Errorf("dryRun=%v: DryRun = %v, Scanned = %d", dryRun, migration.DryRun, migration.Scanned)
+		}
+		if !reflect.DeepEqual(migration.Renamed, wantRenamed) {
+			t.Errorf("dryRun=%v: Renamed = %+v, want %+v", dryRun, migration.Renamed, wantRenamed)
+		}
+		if !reflect.DeepEqual(migration.Collisions, wantCollisions) {
+			t.Errorf("dryRun=%v: Collisions = %+v, want %+v", dryRun, migration.Collisions, wantCollisions)
+		}
+
+		// Em dryRun nada muda no disco; senão os antigos viram os nomes atuais
+		for _, rename := range wantRenamed {
+			_, fromErr := os.Stat(filepath.Join(dir, rename.From))
+			_, toErr := os.Stat(filepath.Join(dir, rename.To))
+			if dryRun && (fromErr != nil || toErr == nil) {
+				t.Errorf("dry run touched %s", rename.From)
+			}
+			if !dryRun && (fromErr == nil || toErr != nil) {
+				t.Errorf("%s was not renamed to %s", rename.From, rename.To)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, "Acao_Mix.json")); err != nil {
+			t.Errorf("dryRun=%v: colliding file was moved: %v", dryRun, err)
+		}
+	}
+}
+
+func TestMigrateAmbiguous(t *testing.T) {
+	dir := t.TempDir()
+	// A regra antiga gerou os dois nomes para "auto-Ação Total" (com e sem o
+	// prefixo); o JSON com prefixo também corresponde ao sem prefixo
+	writeJSONs(t, dir, "Acao_Total.json", "auto-Acao_Total.json")
+
+	migration, err := Migrate(dir, []string{"auto-Ação Total"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migration.Renamed) != 0 {
+		t.Errorf("Renamed = %+v, want none", migration.Renamed)
+	}
+	want := []Collision{
+		{Files: []string{"auto-Acao_Total.json"}, Target: "Acao_Total.json", Reason: CollisionTargetExists},
+		{Files: []string{"Acao_Total.json", "auto-Acao_Total.json"}, Target: "Ação_Total.json", Reason: CollisionAmbiguous},
+	}
+	if !reflect.DeepEqual(migration.Collisions, want) {
+		t.Errorf("Collisions = %+v, want %+v", migration.Collisions, want)
+	}
+}
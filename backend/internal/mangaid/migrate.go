@@ -0,0 +1,134 @@
+package mangaid
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Motivos de uma colisão que a migração não resolve sozinha
+const (
+	CollisionTargetExists = "target_exists" // já existe um JSON com o nome atual
+	CollisionAmbiguous    = "ambiguous"     // mais de um JSON antigo vira o mesmo nome
+	CollisionSharedSource = "shared_source" // o mesmo JSON antigo serve a mais de uma obra
+)
+
+// Rename é um JSON renomeado para o nome da regra atual
+type Rename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Collision é um JSON antigo que ficou com o nome que tinha
+type Collision struct {
+	Files  []string `json:"files"`  // JSONs antigos envolvidos
+	Target string   `json:"target"` // nome que eles teriam pela regra atual
+	Reason string   `json:"reason"`
+}
+
+// Migration é o resultado de Migrate
+type Migration struct {
+	DryRun     bool        `json:"dryRun"`
+	Scanned    int         `json:"scanned"`
+	Renamed    []Rename    `json:"renamed"`
+	Collisions []Collision `json:"collisions"`
+}
+
+// Migrate renomeia para a regra atual os JSONs de dir criados pela regra antiga.
+// O nome antigo perdeu os acentos, então não dá para reconstruir o atual a partir
+// dele: ids são os nomes das pastas das obras na biblioteca, somados aos nomes
+// dos próprios JSONs (que resolvem o prefixo "auto-"). Em dryRun nada é renomeado.
+//
+// Um JSON não é renomeado quando o nome atual já existe (as duas regras geraram
+// arquivos para a mesma obra), quando dois JSONs antigos viram o mesmo nome ou
+// quando um JSON antigo corresponde a mais de uma obra; esses casos voltam em
+// Collisions para serem resolvidos à mão.
+func Migrate(dir string, ids []string, dryRun bool) (*Migration, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list JSONs: %v", err)
+	}
+	migration := &Migration{DryRun: dryRun, Scanned: len(files), Renamed: []Rename{}, Collisions: []Collision{}}
+
+	// JSONs que já têm o nome atual de alguma pasta não são antigos, mesmo que
+	// coincidam com o nome antigo de outra ("Acao Total" e "Ação Total")
+	owned := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		owned[FileName(id)] = true
+	}
+
+	existing := make(map[string]bool, len(files))
+	candidates := append([]string{}, ids...)
+	for _, file := range files {
+		name := filepath.Base(file)
+		existing[name] = true
+		candidates = append(candidates, strings.TrimSuffix(name, ".json"))
+	}
+
+	// nome atual → JSONs antigos que correspondem a ele
+	sources := make(map[string]map[string]bool)
+	targets := make(map[string]map[string]bool)
+	for _, id := range candidates {
+		target := FileName(id)
+		if target == ".json" {
+			continue
+		}
+		for _, legacy := range legacyNames(id) {
+			source := legacy + ".json"
+			if !existing[source] || owned[source] || source == target {
+				continue
+			}
+			if sources[target] == nil {
+				sources[target] = make(map[string]bool)
+			}
+			sources[target][source] = true
+			if targets[source] == nil {
+				targets[source] = make(map[string]bool)
+			}
+			targets[source][target] = true
+		}
+	}
+
+	names := make([]string, 0, len(sources))
+	for target := range sources {
+		names = append(names, target)
+	}
+	sort.Strings(names)
+
+	for _, target := range names {
+		files := sortedKeys(sources[target])
+		reason := ""
+		switch {
+		case existing[target]:
+			reason = CollisionTargetExists
+		case len(files) > 1:
+			reason = CollisionAmbiguous
+		case len(targets[files[0]]) > 1:
+			reason = CollisionSharedSource
+		}
+		if reason != "" {
+			migration.Collisions = append(migration.Collisions, Collision{Files: files, Target: target, Reason: reason})
+			continue
+		}
+
+		if !dryRun {
+			if err := os.Rename(filepath.Join(dir, files[0]), filepath.Join(dir, target)); err != nil {
+				return migration, fmt.Errorf("failed to rename %s: %v", files[0], err)
+			}
+		}
+		existing[target] = true
+		migration.Renamed = append(migration.Renamed, Rename{From: files[0], To: target})
+	}
+	return migration, nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,60 @@
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ArchiveDirName é o subdiretório (dentro do diretório de JSONs) com os JSONs
+// das obras arquivadas. Como fica fora de "*.json", feeds, site, GitHub e
+// estatísticas deixam de ver a obra sem que nada seja apagado.
+const ArchiveDirName = ".archive"
+
+// ArchivePath retorna onde fica o JSON de uma obra enquanto arquivada
+func ArchivePath(jsonPath string) string {
+	return filepath.Join(filepath.Dir(jsonPath), ArchiveDirName, filepath.Base(jsonPath))
+}
+
+// ArchiveJSON move o JSON de uma obra para o arquivo. Obras sem JSON (ainda
+// não enviadas) não são erro: retorna false.
+func ArchiveJSON(jsonPath string) (bool, error) {
+	if _, err := os.Stat(jsonPath); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	archivePath := ArchivePath(jsonPath)
+	if _, err := os.Stat(archivePath); err == nil {
+		return false, fmt.Errorf("archived JSON already exists: %s", archivePath)
+	}
+	if err := os.MkdirAll(filepath.Dir(archivePath), 0755); err != nil {
+		return false, fmt.Errorf("failed to create archive directory: %v", err)
+	}
+	if err := os.Rename(jsonPath, archivePath); err != nil {
+		return false, fmt.Errorf("failed to archive JSON: %v", err)
+	}
+	return true, nil
+}
+
+// UnarchiveJSON devolve o JSON arquivado ao diretório de JSONs. Um JSON criado
+// depois do arquivamento (novo envio da obra) nunca é sobrescrito.
+func UnarchiveJSON(jsonPath string) (bool, error) {
+	archivePath := ArchivePath(jsonPath)
+	if _, err := os.Stat(archivePath); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if _, err := os.Stat(jsonPath); err == nil {
+		return false, fmt.Errorf("JSON already exists outside the archive: %s", jsonPath)
+	}
+	if err := os.Rename(archivePath, jsonPath); err != nil {
+		return false, fmt.Errorf("failed to restore archived JSON: %v", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,158 @@
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+)
+
+// ImportResult descreve o que ImportJSON aplicou (ou aplicaria, em dryRun)
+type ImportResult struct {
+	Path     string   `json:"path"`
+	Created  bool     `json:"created"`            // o JSON da obra não existia
+	Added    []string `json:"added"`              // capítulos novos
+	Merged   []string `json:"merged,omitempty"`   // capítulos existentes que ganharam grupos
+	Skipped  []string `json:"skipped,omitempty"`  // capítulos já presentes, mantidos como estavam
+	Replaced int      `json:"replaced,omitempty"` // capítulos anteriores enviados à lixeira (modo replace)
+	Pages    int      `json:"pages"`              // URLs de páginas importadas
+	Fields   []string `json:"fields,omitempty"`   // metadados preenchidos
+
+	// Imported contém só o que entrou no JSON: capítulos novos e, nos
+	// existentes, os grupos novos (para o histórico de uploads)
+	Imported map[string]Chapter `json:"-"`
+}
+
+// ImportJSON incorpora ao JSON em jsonPath a obra publicada em outro leitor.
+// No modo "add" (padrão) entram os capítulos e grupos ausentes; capítulos já
+// publicados localmente não são alterados. No modo "replace" os capítulos
+// importados substituem todos os atuais (os anteriores vão para a lixeira).
+// Metadados só preenchem campos vazios (em "replace", sobrescrevem), sempre
+// respeitando as travas de campos.
+func (jg *JSONGenerator) ImportJSON(jsonPath string, imported *MangaJSON, mode string, dryRun bool) (*ImportResult, error) {
+	var manga MangaJSON
+	result := &ImportResult{Path: jsonPath, Added: []string{}, Imported: make(map[string]Chapter)}
+	if _, err := os.Stat(jsonPath); err == nil {
+		loaded, err := jg.quarantine.Check(jsonPath)
+		if err != nil {
+			return nil, err
+		}
+		manga = *loaded
+	} else if jg.quarantine.Blocks(jsonPath) {
+		return nil, fmt.Errorf("%s is quarantined as corrupted: restore it or delete the %s file first", filepath.Base(jsonPath), CorruptSuffix)
+	} else {
+		result.Created = true
+	}
+	if manga.Chapters == nil {
+		manga.Chapters = make(map[string]Chapter)
+	}
+
+	result.Fields = jg.importFields(&manga, imported, LockKey(jsonPath), mode == "replace")
+
+	previous := manga.Chapters
+	if mode == "replace" {
+		manga.Chapters = make(map[string]Chapter)
+	}
+	for key, chapter := range imported.Chapters {
+		index := jg.formatChapterIndex(key)
+		existing, exists := manga.Chapters[index]
+		if mode == "replace" {
+			if kept, same := previous[index]; same && reflect.DeepEqual(kept.Groups, chapter.Groups) {
+				manga.Chapters[index] = kept
+				result.Skipped = append(result.Skipped, index)
+				continue
+			}
+		}
+		if !exists {
+			manga.Chapters[index] = chapter
+			result.Added = append(result.Added, index)
+			result.Imported[index] = chapter
+			result.Pages += countPages(chapter.Groups)
+			continue
+		}
+
+		newGroups := make(map[string][]string)
+		for group, urls := range chapter.Groups {
+			if _, present := existing.Groups[group]; !present {
+				newGroups[group] = urls
+			}
+		}
+		if len(newGroups) == 0 {
+			result.Skipped = append(result.Skipped, index)
+			continue
+		}
+		if existing.Groups == nil {
+			existing.Groups = make(map[string][]string)
+		}
+		for group, urls := range newGroups {
+			existing.Groups[group] = urls
+		}
+		manga.Chapters[index] = existing
+		merged := chapter
+		merged.Groups = newGroups
+		result.Merged = append(result.Merged, index)
+		result.Imported[index] = merged
+		result.Pages += countPages(newGroups)
+	}
+	sort.Strings(result.Added)
+	sort.Strings(result.Merged)
+	sort.Strings(result.Skipped)
+
+	if dryRun {
+		if mode == "replace" {
+			result.Replaced = len(discardedChapters(previous, manga.Chapters))
+		}
+		return result, nil
+	}
+	if mode == "replace" {
+		discarded := discardedChapters(previous, manga.Chapters)
+		if err := moveToTrash(jsonPath, "import", discarded); err != nil {
+			return nil, fmt.Errorf("failed to preserve replaced chapters: %v", err)
+		}
+		result.Replaced = len(discarded)
+	}
+	if err := os.MkdirAll(filepath.Dir(jsonPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create json directory: %v", err)
+	}
+	if err := jg.saveJSONFile(jsonPath, manga); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// importFields copia os metadados da obra importada e retorna os campos alterados
+func (jg *JSONGenerator) importFields(manga, imported *MangaJSON, lockKey string, overwrite bool) []string {
+	meta := jg.fieldLocks.Filter(lockKey, MangaMetadata{
+		Title:       imported.Title,
+		Description: imported.Description,
+		Artist:      imported.Artist,
+		Author:      imported.Author,
+		Cover:       imported.Cover,
+		Status:      imported.Status,
+	})
+
+	var fields []string
+	set := func(name string, target *string, value string) {
+		if value == "" || *target == value || (*target != "" && !overwrite) {
+			return
+		}
+		*target = value
+		fields = append(fields, name)
+	}
+	set("title", &manga.Title, meta.Title)
+	set("description", &manga.Description, meta.Description)
+	set("artist", &manga.Artist, meta.Artist)
+	set("author", &manga.Author, meta.Author)
+	set("cover", &manga.Cover, meta.Cover)
+	set("status", &manga.Status, meta.Status)
+	return fields
+}
+
+func countPages(groups map[string][]string) int {
+	pages := 0
+	for _, urls := range groups {
+		pages += len(urls)
+	}
+	return pages
+}
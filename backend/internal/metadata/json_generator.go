@@ -10,6 +10,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"go-upload/backend/internal/mangaid"
 )
 
 // MangaJSON representa a estrutura de JSON de uma obra individual
@@ -20,6 +22,8 @@ type MangaJSON struct {
 	Author      string              `json:"author"`
 	Cover       string              `json:"cover"`
 	Status      string              `json:"status"`
+	NSFW        bool                `json:"nsfw,omitempty"`
+	Related     []RelatedSeries     `json:"related,omitempty"`
 	Chapters    map[string]Chapter  `json:"chapters"`
 }
 
@@ -29,6 +33,10 @@ type Chapter struct {
 	Volume      string                    `json:"volume"` 
 	LastUpdated string                    `json:"last_updated"`
 	Groups      map[string][]string       `json:"groups"`
+	Language    string                    `json:"language,omitempty"`          // Idioma do capítulo (BCP 47); grupos "<grupo> [<idioma>]" têm idioma próprio
+	Languages   []string                  `json:"languages,omitempty"`         // Idiomas disponíveis, quando há mais de um
+	Version     int                       `json:"version,omitempty"`           // Revisão atual ("ch 12 v2" → 2); 0 = primeira publicação
+	PreviousVersions []ChapterVersion     `json:"previous_versions,omitempty"` // URLs das versões substituídas, mais recente primeiro
 }
 
 // UploadedFile representa um arquivo que foi feito upload
@@ -39,7 +47,9 @@ type UploadedFile struct {
 	ChapterTitle string // Título personalizado do capítulo (ex: "O andar de testes")
 	FileName     string
 	URL          string
-	PageIndex    int // Índice da página (0, 1, 2, ...)
+	PageIndex    int               // Índice da página (0, 1, 2, ...)
+	Mirrors      map[string]string // Espelhamento: host -> URL da mesma página
+	Language     string            // Idioma do lançamento (vazio = sem idioma); vira o grupo "<grupo> [<idioma>]"
 }
 
 // MangaMetadata representa metadados básicos de uma obra
@@ -51,12 +61,16 @@ type MangaMetadata struct {
 	Author      string
 	Cover       string
 	Status      string
+	NSFW        bool // marcado pela política de conteúdo a partir dos gêneros/tags do provedor
 }
 
 // JSONGenerator gera JSONs individuais para cada obra
 type JSONGenerator struct {
 	libraryRoot string
 	groupName   string
+	jsonDir     string
+	fieldLocks  *FieldLocks
+	quarantine  *Quarantine // JSONs corrompidos são reparados ou isolados (nil = só erro)
 }
 
 // NewJSONGenerator cria um novo gerador de JSONs
@@ -68,6 +82,24 @@ func NewJSONGenerator(libraryRoot, groupName string) *JSONGenerator {
 	return &JSONGenerator{
 		libraryRoot: libraryRoot,
 		groupName:   groupName,
+		jsonDir:     "json",
+	}
+}
+
+// SetFieldLocks define as travas de campos respeitadas por UpdateExistingJSON
+func (jg *JSONGenerator) SetFieldLocks(locks *FieldLocks) {
+	jg.fieldLocks = locks
+}
+
+// GroupName retorna o grupo usado nos capítulos gerados
+func (jg *JSONGenerator) GroupName() string {
+	return jg.groupName
+}
+
+// SetJSONDir define o diretório onde os JSONs individuais são gravados
+func (jg *JSONGenerator) SetJSONDir(dir string) {
+	if dir != "" {
+		jg.jsonDir = dir
 	}
 }
 
@@ -93,14 +125,14 @@ func (jg *JSONGenerator) GenerateIndividualJSONs(uploadedFiles []UploadedFile, m
 
 // generateSingleMangaJSON gera o JSON individual de uma obra
 func (jg *JSONGenerator) generateSingleMangaJSON(mangaID string, files []UploadedFile, metadata MangaMetadata) (string, error) {
-	// Usar diretório json/ para compatibilidade com frontend
-	jsonDir := "json"
+	// Diretório json/ (configurável) para compatibilidade com frontend
+	jsonDir := jg.jsonDir
 	if err := os.MkdirAll(jsonDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create json directory: %v", err)
 	}
 	
-	// Agrupar arquivos por capítulo
-	chapterFiles := jg.groupFilesByChapter(files)
+	// Agrupar arquivos por capítulo (relançamentos "v2" são aplicados por último)
+	chapterFiles, revisions := splitRevisions(jg.groupFilesByChapter(files))
 	
 	// Construir estrutura de capítulos
 	chapters := make(map[string]Chapter)
@@ -111,10 +143,6 @@ func (jg *JSONGenerator) generateSingleMangaJSON(mangaID string, files []Uploade
 		
 		// Ordenar URLs por índice numérico das páginas (não alfabético)
 		sortedFiles := jg.sortFilesByPageIndex(chapterFileList)
-		var urls []string
-		for _, file := range sortedFiles {
-			urls = append(urls, file.URL)
-		}
 		
 		// Estimar volume baseado no número do capítulo
 		volume := jg.estimateVolume(chapterID)
@@ -126,9 +154,7 @@ func (jg *JSONGenerator) generateSingleMangaJSON(mangaID string, files []Uploade
 			Title:       chapterTitle,
 			Volume:      volume,
 			LastUpdated: fmt.Sprintf("%d", time.Now().Unix()),
-			Groups: map[string][]string{
-				jg.groupName: urls,
-			},
+			Groups:      jg.chapterGroups(sortedFiles),
 		}
 	}
 	
@@ -140,17 +166,17 @@ func (jg *JSONGenerator) generateSingleMangaJSON(mangaID string, files []Uploade
 		Author:      metadata.Author,
 		Cover:       metadata.Cover,
 		Status:      metadata.Status,
+		NSFW:        metadata.NSFW,
 		Chapters:    chapters,
 	}
+	jg.applyRevisions(&mangaJSON, revisions)
 	
 	// Salvar JSON no arquivo usando mangaID como identificador único
-	// Extract folder name from mangaID (remove "auto-" prefix if present)
-	folderName := mangaID
-	if strings.HasPrefix(mangaID, "auto-") {
-		folderName = strings.TrimPrefix(mangaID, "auto-")
+	// (JSONs criados pela regra antiga de nomes continuam sendo atualizados)
+	jsonPath := mangaid.Path(jsonDir, mangaID)
+	if jg.quarantine.Blocks(jsonPath) {
+		return "", fmt.Errorf("%s is quarantined as corrupted: restore it or delete the %s file first", filepath.Base(jsonPath), CorruptSuffix)
 	}
-	sanitizedFolderName := jg.SanitizeFilename(folderName)
-	jsonPath := filepath.Join(jsonDir, fmt.Sprintf("%s.json", sanitizedFolderName))
 	if err := jg.saveJSONFile(jsonPath, mangaJSON); err != nil {
 		return "", fmt.Errorf("failed to save JSON file: %v", err)
 	}
@@ -250,6 +276,15 @@ func (jg *JSONGenerator) buildOrderedJSON(data MangaJSON) string {
 	result.WriteString(fmt.Sprintf("  \"author\": %s,\n", string(authorJSON)))
 	result.WriteString(fmt.Sprintf("  \"cover\": %s,\n", string(coverJSON)))
 	result.WriteString(fmt.Sprintf("  \"status\": %s,\n", string(statusJSON)))
+	if data.NSFW {
+		result.WriteString("  \"nsfw\": true,\n")
+	}
+	
+	// Séries relacionadas (temporadas, spin-offs), apenas quando houver vínculos
+	if len(data.Related) > 0 {
+		relatedJSON, _ := json.MarshalIndent(data.Related, "  ", "  ")
+		result.WriteString(fmt.Sprintf("  \"related\": %s,\n", string(relatedJSON)))
+	}
 	
 	// Seção chapters
 	result.WriteString("  \"chapters\": {\n")
@@ -273,6 +308,19 @@ func (jg *JSONGenerator) buildOrderedJSON(data MangaJSON) string {
 			
 			result.WriteString(fmt.Sprintf("      \"title\": %s,\n", string(titleChapterJSON)))
 			result.WriteString(fmt.Sprintf("      \"volume\": %s,\n", string(volumeJSON)))
+			if language, languages := chapterLanguages(chapter); language != "" || len(languages) > 0 {
+				if language != "" {
+					languageJSON, _ := json.Marshal(language)
+					result.WriteString(fmt.Sprintf("      \"language\": %s,\n", string(languageJSON)))
+				}
+				if len(languages) > 0 {
+					languagesJSON, _ := json.Marshal(languages)
+					result.WriteString(fmt.Sprintf("      \"languages\": %s,\n", string(languagesJSON)))
+				}
+			}
+			if chapter.Version > 0 {
+				result.WriteString(fmt.Sprintf("      \"version\": %d,\n", chapter.Version))
+			}
 			result.WriteString(fmt.Sprintf("      \"last_updated\": \"%s\",\n", chapter.LastUpdated))
 			result.WriteString("      \"groups\": {\n")
 			
@@ -296,7 +344,14 @@ func (jg *JSONGenerator) buildOrderedJSON(data MangaJSON) string {
 				result.WriteString("\n")
 			}
 			
-			result.WriteString("      }\n")
+			result.WriteString("      }")
+			
+			// Versões substituídas por relançamentos
+			if len(chapter.PreviousVersions) > 0 {
+				previousJSON, _ := json.MarshalIndent(chapter.PreviousVersions, "      ", "  ")
+				result.WriteString(fmt.Sprintf(",\n      \"previous_versions\": %s", string(previousJSON)))
+			}
+			result.WriteString("\n")
 			result.WriteString("    }")
 			
 			if i < len(chapterKeys)-1 {
@@ -316,9 +371,16 @@ func (jg *JSONGenerator) buildOrderedJSON(data MangaJSON) string {
 func (jg *JSONGenerator) UpdateExistingJSON(jsonPath string, newFiles []UploadedFile, updateMode string, mangaMetadata ...MangaMetadata) error {
 	var existingData MangaJSON
 	
-	// Tentar carregar JSON existente
-	if data, err := os.ReadFile(jsonPath); err == nil {
-		json.Unmarshal(data, &existingData)
+	// Carregar JSON existente: um arquivo corrompido interrompe a atualização em vez
+	// de ser sobrescrito só com os capítulos novos
+	if _, err := os.Stat(jsonPath); err == nil {
+		loaded, err := jg.quarantine.Check(jsonPath)
+		if err != nil {
+			return err
+		}
+		existingData = *loaded
+	} else if jg.quarantine.Blocks(jsonPath) {
+		return fmt.Errorf("%s is quarantined as corrupted: restore it or delete the %s file first", filepath.Base(jsonPath), CorruptSuffix)
 	}
 	
 	// Se não existe, criar estrutura vazia
@@ -328,7 +390,8 @@ func (jg *JSONGenerator) UpdateExistingJSON(jsonPath string, newFiles []Uploaded
 	
 	// Atualizar metadados base se fornecidos, mas sempre preservar metadados existentes
 	if len(mangaMetadata) > 0 {
-		metadata := mangaMetadata[0]
+		// Campos travados pelo usuário nunca são sobrescritos
+		metadata := jg.fieldLocks.Filter(LockKey(jsonPath), mangaMetadata[0])
 		// Atualizar apenas se novos valores não estiverem vazios, senão manter existentes
 		if metadata.Title != "" {
 			existingData.Title = metadata.Title
@@ -348,17 +411,27 @@ func (jg *JSONGenerator) UpdateExistingJSON(jsonPath string, newFiles []Uploaded
 		if metadata.Status != "" {
 			existingData.Status = metadata.Status
 		}
+		if metadata.NSFW {
+			existingData.NSFW = true
+		}
 	}
 	// Nota: Se não há metadados fornecidos, os existentes são automaticamente preservados
 	
-	// Agrupar novos arquivos por capítulo
-	newChapterFiles := jg.groupFilesByChapter(newFiles)
+	// Agrupar novos arquivos por capítulo; relançamentos ("ch 12 v2") substituem
+	// a versão anterior do capítulo em qualquer modo, em vez de criar outra chave
+	newChapterFiles, revisions := splitRevisions(jg.groupFilesByChapter(newFiles))
 	
 	switch updateMode {
 	case "replace":
-		// Substituir todos os capítulos
+		// Substituir todos os capítulos (os removidos/alterados vão para a lixeira)
+		previousChapters := existingData.Chapters
 		existingData.Chapters = make(map[string]Chapter)
 		jg.addChaptersToJSON(&existingData, newChapterFiles)
+		jg.applyRevisions(&existingData, revisions)
+		
+		if err := moveToTrash(jsonPath, updateMode, discardedChapters(previousChapters, existingData.Chapters)); err != nil {
+			return fmt.Errorf("failed to preserve replaced chapters: %v", err)
+		}
 		
 	case "add":
 		// Adicionar apenas novos capítulos, manter existentes
@@ -372,6 +445,9 @@ func (jg *JSONGenerator) UpdateExistingJSON(jsonPath string, newFiles []Uploaded
 		// Modo padrão é smart
 		jg.smartMergeChapters(&existingData, newChapterFiles)
 	}
+	if updateMode != "replace" {
+		jg.applyRevisions(&existingData, revisions)
+	}
 	
 	// Atualizar timestamp
 	for chapterIndex, chapter := range existingData.Chapters {
@@ -389,20 +465,13 @@ func (jg *JSONGenerator) addChaptersToJSON(mangaJSON *MangaJSON, chapterFiles ma
 		chapterIndex := jg.formatChapterIndex(chapterID)
 		
 		sortedFiles := jg.sortFilesByPageIndex(files)
-		var urls []string
-		for _, file := range sortedFiles {
-			urls = append(urls, file.URL)
-		}
-		
 		chapterTitle := jg.getChapterTitle(chapterID, files)
 		
 		mangaJSON.Chapters[chapterIndex] = Chapter{
 			Title:       chapterTitle,
 			Volume:      jg.estimateVolume(chapterID),
 			LastUpdated: fmt.Sprintf("%d", time.Now().Unix()),
-			Groups: map[string][]string{
-				jg.groupName: urls,
-			},
+			Groups:      jg.chapterGroups(sortedFiles),
 		}
 	}
 }
@@ -420,20 +489,13 @@ func (jg *JSONGenerator) addOnlyNewChapters(mangaJSON *MangaJSON, chapterFiles m
 		
 		// Capítulo não existe, adicionar
 		sortedFiles := jg.sortFilesByPageIndex(files)
-		var urls []string
-		for _, file := range sortedFiles {
-			urls = append(urls, file.URL)
-		}
-		
 		chapterTitle := jg.getChapterTitle(chapterID, files)
 		
 		mangaJSON.Chapters[chapterIndex] = Chapter{
 			Title:       chapterTitle,
 			Volume:      jg.estimateVolume(chapterID),
 			LastUpdated: fmt.Sprintf("%d", time.Now().Unix()),
-			Groups: map[string][]string{
-				jg.groupName: urls,
-			},
+			Groups:      jg.chapterGroups(sortedFiles),
 		}
 	}
 }
@@ -444,10 +506,7 @@ func (jg *JSONGenerator) smartMergeChapters(mangaJSON *MangaJSON, newChapterFile
 		chapterIndex := jg.formatChapterIndex(chapterID)
 		
 		sortedFiles := jg.sortFilesByPageIndex(files)
-		var urls []string
-		for _, file := range sortedFiles {
-			urls = append(urls, file.URL)
-		}
+		groups := jg.chapterGroups(sortedFiles)
 		
 		// Se capítulo já existe, fazer merge inteligente. Se não, adicionar.
 		if existingChapter, exists := mangaJSON.Chapters[chapterIndex]; exists {
@@ -456,13 +515,11 @@ func (jg *JSONGenerator) smartMergeChapters(mangaJSON *MangaJSON, newChapterFile
 				existingChapter.Groups = make(map[string][]string)
 			}
 			
-			// Obter URLs existentes do grupo
-			existingURLs := existingChapter.Groups[jg.groupName]
-			
-			// Fazer merge inteligente: combinar URLs existentes + novas, removendo duplicatas
-			mergedURLs := jg.smartMergeURLs(existingURLs, urls)
-			
-			existingChapter.Groups[jg.groupName] = mergedURLs
+			// Fazer merge inteligente por grupo (principal e espelhos): combinar URLs
+			// existentes + novas, removendo duplicatas
+			for group, urls := range groups {
+				existingChapter.Groups[group] = jg.smartMergeURLs(existingChapter.Groups[group], urls)
+			}
 			existingChapter.LastUpdated = fmt.Sprintf("%d", time.Now().Unix())
 			mangaJSON.Chapters[chapterIndex] = existingChapter
 		} else {
@@ -473,12 +530,47 @@ func (jg *JSONGenerator) smartMergeChapters(mangaJSON *MangaJSON, newChapterFile
 				Title:       chapterTitle,
 				Volume:      jg.estimateVolume(chapterID),
 				LastUpdated: fmt.Sprintf("%d", time.Now().Unix()),
-				Groups: map[string][]string{
-					jg.groupName: urls,
-				},
+				Groups:      groups,
+			}
+		}
+	}
+}
+
+// MirrorGroupName retorna o grupo que guarda as URLs de um host espelho
+func MirrorGroupName(group, host string) string {
+	return fmt.Sprintf("%s (%s)", group, host)
+}
+
+// chapterGroups monta os grupos de um capítulo a partir das páginas já ordenadas.
+// Cada host espelho vira um grupo próprio, incluído apenas se tiver todas as páginas,
+// para que leitores possam alternar de host quando um deles sair do ar. Páginas
+// com idioma ficam no grupo do idioma ("<grupo> [pt-BR]").
+func (jg *JSONGenerator) chapterGroups(sortedFiles []UploadedFile) map[string][]string {
+	byLanguage := make(map[string][]UploadedFile)
+	for _, file := range sortedFiles {
+		byLanguage[file.Language] = append(byLanguage[file.Language], file)
+	}
+	
+	groups := make(map[string][]string)
+	for language, files := range byLanguage {
+		group := LanguageGroupName(jg.groupName, language)
+		urls := make([]string, 0, len(files))
+		mirrorURLs := make(map[string][]string)
+		for _, file := range files {
+			urls = append(urls, file.URL)
+			for host, url := range file.Mirrors {
+				mirrorURLs[host] = append(mirrorURLs[host], url)
+			}
+		}
+		
+		groups[group] = urls
+		for host, hostURLs := range mirrorURLs {
+			if len(hostURLs) == len(files) {
+				groups[MirrorGroupName(group, host)] = hostURLs
 			}
 		}
 	}
+	return groups
 }
 
 // smartMergeURLs faz merge inteligente de URLs, removendo duplicatas e preservando ordem
@@ -556,22 +648,9 @@ func (jg *JSONGenerator) sortFilesByPageIndex(files []UploadedFile) []UploadedFi
 }
 
 // SanitizeFilename sanitiza nome de arquivo removendo caracteres inválidos (função pública)
+// Mesmas regras de mangaid.Sanitize; IDs com prefixo "auto-" usam mangaid.Normalize
 func (jg *JSONGenerator) SanitizeFilename(filename string) string {
-	// Remover caracteres especiais e substituir espaços por underscores
-	reg := regexp.MustCompile(`[<>:"/\\|?*]`)
-	sanitized := reg.ReplaceAllString(filename, "")
-	
-	// Substituir espaços por underscores
-	sanitized = strings.ReplaceAll(sanitized, " ", "_")
-	
-	// Remover múltiplos underscores consecutivos
-	reg2 := regexp.MustCompile(`_+`)
-	sanitized = reg2.ReplaceAllString(sanitized, "_")
-	
-	// Remover underscores no início e fim
-	sanitized = strings.Trim(sanitized, "_")
-	
-	return sanitized
+	return mangaid.Sanitize(filename)
 }
 
 // ExtractPageIndex extrai o índice numérico da página do nome do arquivo (função pública)
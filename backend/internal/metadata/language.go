@@ -0,0 +1,114 @@
+package metadata
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// languagePattern reconhece códigos de idioma BCP 47 simples ("en", "pt-BR", "zh-Hant")
+var languagePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// languageGroupPattern reconhece grupos de idioma no formato "<grupo> [<idioma>]"
+var languageGroupPattern = regexp.MustCompile(`^(.+) \[([A-Za-z]{2,3}(?:-[A-Za-z0-9]{2,8})*)\]$`)
+
+// NormalizeLanguage valida um código de idioma e padroniza a caixa ("pt_br" →
+// "pt-BR", "EN" → "en"). Vazio continua vazio (capítulo sem idioma).
+func NormalizeLanguage(code string) (string, error) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), "_", "-")
+	if code == "" {
+		return "", nil
+	}
+	if !languagePattern.MatchString(code) {
+		return "", fmt.Errorf("invalid language code %q (use BCP 47, e.g. en or pt-BR)", code)
+	}
+
+	parts := strings.Split(code, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			parts[i] = strings.ToUpper(parts[i]) // região
+		case 4:
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:]) // escrita
+		default:
+			parts[i] = strings.ToLower(parts[i])
+		}
+	}
+	return strings.Join(parts, "-"), nil
+}
+
+// LanguageGroupName retorna o grupo que guarda as páginas de um idioma, para
+// que lançamentos em idiomas diferentes do mesmo capítulo fiquem lado a lado
+func LanguageGroupName(group, language string) string {
+	if language == "" {
+		return group
+	}
+	return fmt.Sprintf("%s [%s]", group, language)
+}
+
+// GroupLanguage retorna o idioma de um grupo (ou do grupo base de um espelho);
+// vazio para grupos sem idioma
+func GroupLanguage(name string) string {
+	if base, _, ok := ParseMirrorGroup(name); ok {
+		name = base
+	}
+	if match := languageGroupPattern.FindStringSubmatch(name); match != nil {
+		return match[2]
+	}
+	return ""
+}
+
+// chapterLanguages calcula os campos de idioma de um capítulo a partir dos
+// grupos. language é o idioma dos grupos sem idioma próprio; quando todos os
+// grupos têm o mesmo idioma, ele vira o idioma do capítulo. languages lista
+// os idiomas quando o capítulo tem mais de um.
+func chapterLanguages(chapter Chapter) (language string, languages []string) {
+	language = chapter.Language
+	tagged := make(map[string]bool)
+	untagged := false
+	for name := range chapter.Groups {
+		if lang := GroupLanguage(name); lang != "" {
+			tagged[lang] = true
+		} else {
+			untagged = true
+		}
+	}
+	if !untagged && len(tagged) == 1 {
+		for lang := range tagged {
+			return lang, nil
+		}
+	}
+
+	if untagged && language != "" {
+		tagged[language] = true
+	}
+	if len(tagged) > 1 {
+		for lang := range tagged {
+			languages = append(languages, lang)
+		}
+		sort.Strings(languages)
+	}
+	return language, languages
+}
+
+// replaceLanguageGroups troca os grupos dos idiomas presentes em groups,
+// mantendo os grupos dos demais idiomas do capítulo
+func replaceLanguageGroups(existing, groups map[string][]string) map[string][]string {
+	replaced := make(map[string]bool)
+	for name := range groups {
+		replaced[GroupLanguage(name)] = true
+	}
+
+	merged := make(map[string][]string, len(groups))
+	for name, urls := range existing {
+		if !replaced[GroupLanguage(name)] {
+			merged[name] = urls
+		}
+	}
+	for name, urls := range groups {
+		merged[name] = urls
+	}
+	return merged
+}
@@ -0,0 +1,162 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LockableFields são os campos de metadados que podem ser travados contra sobrescrita
+var LockableFields = []string{"title", "description", "artist", "author", "cover", "status"}
+
+// FieldLocks guarda, por obra, os campos curados manualmente que preenchimentos
+// automáticos (AniList, merges) não podem sobrescrever
+type FieldLocks struct {
+	path      string
+	locks     map[string][]string // mangaID (nome do JSON sem extensão) -> campos travados
+	mutex     sync.RWMutex
+	saveMutex sync.Mutex
+}
+
+// NewFieldLocks cria o armazenamento de travas e carrega o arquivo existente, se houver
+func NewFieldLocks(path string) (*FieldLocks, error) {
+	fl := &FieldLocks{
+		path:  path,
+		locks: make(map[string][]string),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fl, nil
+		}
+		return fl, fmt.Errorf("failed to read field locks: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &fl.locks); err != nil {
+		return fl, fmt.Errorf("failed to parse field locks: %v", err)
+	}
+	return fl, nil
+}
+
+// LockKey retorna a chave de trava de um JSON de obra (nome do arquivo sem extensão)
+func LockKey(jsonPath string) string {
+	return strings.TrimSuffix(filepath.Base(jsonPath), ".json")
+}
+
+// Locked retorna os campos travados de uma obra
+func (fl *FieldLocks) Locked(mangaID string) []string {
+	if fl == nil {
+		return nil
+	}
+
+	fl.mutex.RLock()
+	defer fl.mutex.RUnlock()
+	return append([]string(nil), fl.locks[mangaID]...)
+}
+
+// IsLocked informa se um campo de uma obra está travado
+func (fl *FieldLocks) IsLocked(mangaID, field string) bool {
+	for _, locked := range fl.Locked(mangaID) {
+		if locked == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Set trava (locked=true) ou destrava os campos informados e retorna os campos travados
+func (fl *FieldLocks) Set(mangaID string, fields []string, locked bool) ([]string, error) {
+	if mangaID == "" {
+		return nil, fmt.Errorf("mangaId is required")
+	}
+	for _, field := range fields {
+		if !isLockableField(field) {
+			return nil, fmt.Errorf("field %q cannot be locked (lockable: %s)", field, strings.Join(LockableFields, ", "))
+		}
+	}
+
+	fl.mutex.Lock()
+	current := make(map[string]bool)
+	for _, field := range fl.locks[mangaID] {
+		current[field] = true
+	}
+	for _, field := range fields {
+		current[field] = locked
+	}
+
+	var result []string
+	for field, isLocked := range current {
+		if isLocked {
+			result = append(result, field)
+		}
+	}
+	sort.Strings(result)
+
+	if len(result) == 0 {
+		delete(fl.locks, mangaID)
+	} else {
+		fl.locks[mangaID] = result
+	}
+	fl.mutex.Unlock()
+
+	return result, fl.save()
+}
+
+// Filter retorna os metadados sem os valores dos campos travados, para que
+// atualizações automáticas mantenham os valores curados
+func (fl *FieldLocks) Filter(mangaID string, meta MangaMetadata) MangaMetadata {
+	for _, field := range fl.Locked(mangaID) {
+		switch field {
+		case "title":
+			meta.Title = ""
+		case "description":
+			meta.Description = ""
+		case "artist":
+			meta.Artist = ""
+		case "author":
+			meta.Author = ""
+		case "cover":
+			meta.Cover = ""
+		case "status":
+			meta.Status = ""
+		}
+	}
+	return meta
+}
+
+// save grava as travas em disco de forma atômica
+func (fl *FieldLocks) save() error {
+	fl.saveMutex.Lock()
+	defer fl.saveMutex.Unlock()
+
+	fl.mutex.RLock()
+	data, err := json.MarshalIndent(fl.locks, "", "  ")
+	fl.mutex.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode field locks: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fl.path), 0755); err != nil {
+		return fmt.Errorf("failed to create field locks directory: %v", err)
+	}
+	tmpPath := fl.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write field locks: %v", err)
+	}
+	return os.Rename(tmpPath, fl.path)
+}
+
+// isLockableField informa se o campo pode ser travado
+func isLockableField(field string) bool {
+	for _, lockable := range LockableFields {
+		if lockable == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+)
+
+// mirrorGroupPattern reconhece grupos espelho no formato "<grupo> (<host>)"
+var mirrorGroupPattern = regexp.MustCompile(`^(.+) \(([^()]+)\)$`)
+
+// MirrorFailoverMode identifica as reescritas de espelhos na lixeira do JSON
+const MirrorFailoverMode = "mirror_failover"
+
+// HostHealth é a disponibilidade medida de um host de imagens (chave: hostname das URLs)
+type HostHealth struct {
+	Score float64 // 0 (fora do ar) a 1 (todas as URLs disponíveis)
+	Label string  // nome do uploader usado nos grupos espelho (vazio = desconhecido)
+}
+
+// MirrorChange descreve a reescrita de um capítulo espelhado
+type MirrorChange struct {
+	Chapter  string   `json:"chapter"`
+	Group    string   `json:"group"`
+	FromHost string   `json:"fromHost,omitempty"` // host que deixou o grupo principal
+	ToHost   string   `json:"toHost,omitempty"`   // host promovido ao grupo principal
+	Dropped  []string `json:"dropped,omitempty"`  // grupos espelho removidos
+}
+
+// MirrorFailoverResult resume a reescrita de um JSON
+type MirrorFailoverResult struct {
+	MangaID string         `json:"mangaId"`
+	Changes []MirrorChange `json:"changes"`
+}
+
+// ParseMirrorGroup separa um grupo espelho em grupo base e host
+func ParseMirrorGroup(name string) (group, host string, ok bool) {
+	match := mirrorGroupPattern.FindStringSubmatch(name)
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], match[2], true
+}
+
+// URLHost retorna o hostname de uma URL de página (vazio se inválida)
+func URLHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// MirrorSets agrupa os grupos de um capítulo por grupo base: o principal seguido
+// dos seus espelhos. Grupos sem espelho não aparecem no resultado; grupos com o
+// link de uma página de leitura (IsPageLinkGroup) não contam como espelhos.
+func MirrorSets(chapter Chapter) map[string][]string {
+	sets := make(map[string][]string)
+	for name := range chapter.Groups {
+		base, _, ok := ParseMirrorGroup(name)
+		if !ok || IsPageLinkGroup(name) {
+			continue
+		}
+		if _, exists := chapter.Groups[base]; exists {
+			sets[base] = append(sets[base], name)
+		}
+	}
+	for base, mirrors := range sets {
+		sort.Strings(mirrors)
+		sets[base] = append([]string{base}, mirrors...)
+	}
+	return sets
+}
+
+// FailoverMirrors reescreve um JSON para que o grupo principal de cada capítulo
+// espelhado aponte para o host mais saudável; o host substituído vira um grupo
+// espelho. Com dropBelow > 0, espelhos com nota abaixo do limite são removidos
+// (e guardados na lixeira do JSON). Hosts sem medição nunca são alterados.
+func (jg *JSONGenerator) FailoverMirrors(jsonPath string, health map[string]HostHealth, dropBelow float64) (*MirrorFailoverResult, error) {
+	raw, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+	var manga MangaJSON
+	if err := json.Unmarshal(raw, &manga); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	result := &MirrorFailoverResult{MangaID: LockKey(jsonPath), Changes: []MirrorChange{}}
+	trashed := make(map[string]Chapter)
+
+	chapterKeys := make([]string, 0, len(manga.Chapters))
+	for key := range manga.Chapters {
+		chapterKeys = append(chapterKeys, key)
+	}
+	sort.Strings(chapterKeys)
+
+	for _, key := range chapterKeys {
+		chapter := manga.Chapters[key]
+		removed := make(map[string][]string)
+
+		for base, members := range MirrorSets(chapter) {
+			change := MirrorChange{Chapter: key, Group: base}
+
+			// Promove o host com a maior nota, se for melhor que o atual
+			primaryHost := groupHost(chapter.Groups[base])
+			primary, primaryKnown := health[primaryHost]
+			best, bestScore := "", primary.Score
+			for _, name := range members[1:] {
+				if mirror, known := health[groupHost(chapter.Groups[name])]; known && primaryKnown && mirror.Score > bestScore {
+					best, bestScore = name, mirror.Score
+				}
+			}
+			if best != "" {
+				displaced := MirrorGroupName(base, hostLabel(primaryHost, health))
+				change.FromHost = primaryHost
+				change.ToHost = groupHost(chapter.Groups[best])
+
+				oldURLs := chapter.Groups[base]
+				chapter.Groups[base] = chapter.Groups[best]
+				delete(chapter.Groups, best)
+				if existing, exists := chapter.Groups[displaced]; exists {
+					removed[displaced] = existing
+				}
+				chapter.Groups[displaced] = oldURLs
+				members = MirrorSets(chapter)[base]
+			}
+
+			// Remove espelhos quebrados; o grupo principal sempre fica
+			if dropBelow > 0 {
+				for _, name := range members[1:] {
+					if mirror, known := health[groupHost(chapter.Groups[name])]; known && mirror.Score < dropBelow {
+						removed[name] = chapter.Groups[name]
+						delete(chapter.Groups, name)
+						change.Dropped = append(change.Dropped, name)
+					}
+				}
+			}
+
+			if change.ToHost != "" || len(change.Dropped) > 0 {
+				result.Changes = append(result.Changes, change)
+			}
+		}
+
+		if len(removed) > 0 {
+			trashed[key] = Chapter{
+				Title:       chapter.Title,
+				Volume:      chapter.Volume,
+				LastUpdated: chapter.LastUpdated,
+				Groups:      removed,
+			}
+		}
+		manga.Chapters[key] = chapter
+	}
+
+	if len(result.Changes) == 0 {
+		return result, nil
+	}
+	if err := moveToTrash(jsonPath, MirrorFailoverMode, trashed); err != nil {
+		return nil, fmt.Errorf("failed to preserve dropped mirrors: %v", err)
+	}
+	if err := jg.saveJSONFile(jsonPath, manga); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// groupHost retorna o hostname das páginas de um grupo (a primeira URL válida)
+func groupHost(urls []string) string {
+	for _, pageURL := range urls {
+		if host := URLHost(pageURL); host != "" {
+			return host
+		}
+	}
+	return ""
+}
+
+// hostLabel retorna o nome do grupo espelho para um hostname
+func hostLabel(host string, health map[string]HostHealth) string {
+	if label := health[host].Label; label != "" {
+		return label
+	}
+	if host == "" {
+		return "original"
+	}
+	return host
+}
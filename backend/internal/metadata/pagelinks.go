@@ -0,0 +1,73 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// TelegraphHost é o host dos grupos que guardam o link da página do capítulo no
+// Telegraph ("<grupo> (telegra.ph)") em vez das URLs das imagens
+const TelegraphHost = "telegra.ph"
+
+// IsPageLinkGroup informa se o grupo guarda o link de uma página de leitura
+// externa em vez das imagens. Esses grupos não são espelhos: não entram em
+// MirrorSets, na troca de host principal nem na exportação.
+func IsPageLinkGroup(name string) bool {
+	_, host, ok := ParseMirrorGroup(name)
+	return ok && host == TelegraphHost
+}
+
+// SetChapterGroups grava grupos em capítulos existentes do JSON (capítulo ->
+// grupo -> URLs) e o salva se algo mudou. Capítulos ausentes são ignorados.
+// Retorna quantos grupos foram gravados.
+func (jg *JSONGenerator) SetChapterGroups(jsonPath string, groups map[string]map[string][]string) (int, error) {
+	raw, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return 0, err
+	}
+	var manga MangaJSON
+	if err := json.Unmarshal(raw, &manga); err != nil {
+		return 0, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	changed := 0
+	for key, chapterGroups := range groups {
+		chapter, exists := manga.Chapters[key]
+		if !exists {
+			continue
+		}
+		if chapter.Groups == nil {
+			chapter.Groups = make(map[string][]string)
+		}
+		for name, urls := range chapterGroups {
+			if equalURLs(chapter.Groups[name], urls) {
+				continue
+			}
+			chapter.Groups[name] = urls
+			changed++
+		}
+		manga.Chapters[key] = chapter
+	}
+
+	if changed == 0 {
+		return 0, nil
+	}
+	if err := jg.saveJSONFile(jsonPath, manga); err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+// equalURLs compara duas listas de URLs na ordem
+func equalURLs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,320 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CorruptSuffix é acrescentado aos JSONs que não puderam ser lidos nem reparados.
+// Fora do padrão *.json, eles deixam de aparecer na biblioteca e nas sincronizações.
+const CorruptSuffix = ".corrupt"
+
+// maxCorruptEntries limita o relatório (as entradas mais antigas saem)
+const maxCorruptEntries = 200
+
+// Ações registradas no relatório de JSONs corrompidos
+const (
+	CorruptRepaired    = "repaired"    // lido com o parser tolerante e regravado
+	CorruptQuarantined = "quarantined" // renomeado para .corrupt, aguardando restauração
+	CorruptRestored    = "restored"    // recriado a partir de uma cópia válida
+)
+
+// CorruptFile é uma entrada do relatório de JSONs corrompidos
+type CorruptFile struct {
+	File         string     `json:"file"`                  // nome do JSON na pasta de saída
+	Quarantined  string     `json:"quarantined,omitempty"` // nome do arquivo .corrupt
+	Action       string     `json:"action"`
+	Error        string     `json:"error"`
+	DetectedAt   time.Time  `json:"detectedAt"`
+	RestoredAt   *time.Time `json:"restoredAt,omitempty"`
+	RestoredFrom string     `json:"restoredFrom,omitempty"` // origem da cópia (ex.: "github:owner/repo")
+}
+
+// Quarantine lê os JSONs de obra de forma tolerante: defeitos comuns (BOM, bytes
+// nulos de uma gravação interrompida, vírgulas sobrando, lixo após o objeto) são
+// reparados e o arquivo é regravado; o que não tem conserto é renomeado para
+// .corrupt em vez de ser ignorado ou sobrescrito. Tudo fica no relatório.
+type Quarantine struct {
+	jsonDir    string
+	reportPath string
+	save       func(path string, data MangaJSON) error
+
+	mutex     sync.Mutex
+	saveMutex sync.Mutex
+	entries   []CorruptFile // mais antiga primeiro
+}
+
+// EnableQuarantine ativa a quarentena nos JSONs do gerador e a retorna. O
+// relatório é carregado de reportPath e mantido nele.
+func (jg *JSONGenerator) EnableQuarantine(reportPath string) (*Quarantine, error) {
+	q := &Quarantine{jsonDir: jg.jsonDir, reportPath: reportPath, save: jg.saveJSONFile}
+	jg.quarantine = q
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return q, nil
+		}
+		return q, fmt.Errorf("failed to read corrupt JSON report: %v", err)
+	}
+	if err := json.Unmarshal(data, &q.entries); err != nil {
+		return q, fmt.Errorf("failed to parse corrupt JSON report: %v", err)
+	}
+	return q, nil
+}
+
+// parseLenient lê um JSON de obra e, se a leitura estrita falhar, tenta de novo
+// depois de reparar o conteúdo. Retorna o erro da leitura estrita e o da final.
+func parseLenient(data []byte) (*MangaJSON, error, error) {
+	var manga MangaJSON
+	strictErr := json.Unmarshal(data, &manga)
+	if strictErr == nil {
+		return &manga, nil, nil
+	}
+
+	manga = MangaJSON{}
+	decoder := json.NewDecoder(bytes.NewReader(repairJSON(data)))
+	if err := decoder.Decode(&manga); err != nil {
+		return nil, strictErr, err
+	}
+	return &manga, strictErr, nil
+}
+
+// repairJSON remove o BOM, os bytes nulos e as vírgulas antes de } ou ] fora de
+// strings. O que vier depois do primeiro objeto é descartado pelo Decoder.
+func repairJSON(data []byte) []byte {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	data = bytes.ReplaceAll(data, []byte{0}, nil)
+
+	repaired := make([]byte, 0, len(data))
+	inString, escaped := false, false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			repaired = append(repaired, c)
+			continue
+		}
+		if c == '"' {
+			inString = true
+		}
+		if c == ',' {
+			next := bytes.TrimLeft(data[i+1:], " \t\r\n")
+			if len(next) > 0 && (next[0] == '}' || next[0] == ']') {
+				continue
+			}
+		}
+		repaired = append(repaired, c)
+	}
+	return repaired
+}
+
+// Check lê um JSON de obra: reparável, é regravado e retornado; irrecuperável,
+// vai para a quarentena e um erro é retornado. Sem quarentena (nil), o JSON
+// corrompido só gera o erro.
+func (q *Quarantine) Check(path string) (*MangaJSON, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON file: %v", err)
+	}
+
+	manga, strictErr, err := parseLenient(data)
+	if err == nil {
+		if strictErr != nil && q != nil {
+			if saveErr := q.save(path, *manga); saveErr != nil {
+				return nil, saveErr
+			}
+			q.record(CorruptFile{File: filepath.Base(path), Action: CorruptRepaired, Error: strictErr.Error()})
+		}
+		return manga, nil
+	}
+	if q == nil {
+		return nil, fmt.Errorf("%s is corrupted: %v", filepath.Base(path), err)
+	}
+
+	quarantined, renameErr := q.isolate(path)
+	if renameErr != nil {
+		return nil, fmt.Errorf("%s is corrupted (%v) and could not be quarantined: %v", filepath.Base(path), err, renameErr)
+	}
+	q.record(CorruptFile{
+		File:        filepath.Base(path),
+		Quarantined: filepath.Base(quarantined),
+		Action:      CorruptQuarantined,
+		Error:       err.Error(),
+	})
+	return nil, fmt.Errorf("%s is corrupted and was moved to %s: %v", filepath.Base(path), filepath.Base(quarantined), err)
+}
+
+// Scan verifica todos os JSONs da pasta de saída e retorna as entradas novas
+// do relatório (reparos e quarentenas)
+func (q *Quarantine) Scan() ([]CorruptFile, error) {
+	files, err := filepath.Glob(filepath.Join(q.jsonDir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list JSONs: %v", err)
+	}
+
+	q.mutex.Lock()
+	before := len(q.entries)
+	q.mutex.Unlock()
+
+	for _, path := range files {
+		q.Check(path)
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	found := []CorruptFile{}
+	if len(q.entries) > before {
+		found = append(found, q.entries[before:]...)
+	}
+	return found, nil
+}
+
+// Report retorna o relatório, mais recente primeiro
+func (q *Quarantine) Report() []CorruptFile {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	report := make([]CorruptFile, 0, len(q.entries))
+	for i := len(q.entries) - 1; i >= 0; i-- {
+		report = append(report, q.entries[i])
+	}
+	return report
+}
+
+// Pending retorna os JSONs em quarentena que ainda não foram restaurados. Apagar
+// o arquivo .corrupt desiste da restauração e libera o JSON para ser recriado.
+func (q *Quarantine) Pending() []CorruptFile {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	pending := []CorruptFile{}
+	for _, entry := range q.entries {
+		if q.isPending(entry) {
+			pending = append(pending, entry)
+		}
+	}
+	return pending
+}
+
+// Blocks informa se o JSON está em quarentena aguardando restauração: criá-lo de
+// novo agora perderia os capítulos que só existem na cópia de segurança
+func (q *Quarantine) Blocks(path string) bool {
+	if q == nil {
+		return false
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	name := filepath.Base(path)
+	for _, entry := range q.entries {
+		if entry.File == name && q.isPending(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPending verifica se a entrada está em quarentena, com o .corrupt presente e
+// sem JSON recriado; chamado com o mutex travado
+func (q *Quarantine) isPending(entry CorruptFile) bool {
+	if entry.Action != CorruptQuarantined {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(q.jsonDir, entry.File)); err == nil {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(q.jsonDir, entry.Quarantined))
+	return err == nil
+}
+
+// Restore recria um JSON em quarentena a partir de uma cópia válida (source
+// identifica a origem no relatório). A cópia é validada antes de ser gravada.
+func (q *Quarantine) Restore(file string, content []byte, source string) error {
+	name := filepath.Base(file)
+	var manga MangaJSON
+	if err := json.Unmarshal(content, &manga); err != nil {
+		return fmt.Errorf("backup of %s is not valid JSON: %v", name, err)
+	}
+
+	path := filepath.Join(q.jsonDir, name)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", name, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to restore %s: %v", name, err)
+	}
+
+	q.mutex.Lock()
+	now := time.Now()
+	for i := len(q.entries) - 1; i >= 0; i-- {
+		if q.entries[i].File == name && q.entries[i].Action == CorruptQuarantined {
+			q.entries[i].Action = CorruptRestored
+			q.entries[i].RestoredAt = &now
+			q.entries[i].RestoredFrom = source
+			break
+		}
+	}
+	q.mutex.Unlock()
+	return q.persist()
+}
+
+// isolate renomeia o JSON para .corrupt sem sobrescrever quarentenas anteriores
+func (q *Quarantine) isolate(path string) (string, error) {
+	target := path + CorruptSuffix
+	if _, err := os.Stat(target); err == nil {
+		target = fmt.Sprintf("%s.%d%s", path, time.Now().Unix(), CorruptSuffix)
+	}
+	if err := os.Rename(path, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// record adiciona uma entrada ao relatório e o grava
+func (q *Quarantine) record(entry CorruptFile) {
+	entry.DetectedAt = time.Now()
+	q.mutex.Lock()
+	q.entries = append(q.entries, entry)
+	if len(q.entries) > maxCorruptEntries {
+		q.entries = q.entries[len(q.entries)-maxCorruptEntries:]
+	}
+	q.mutex.Unlock()
+	q.persist()
+}
+
+// persist grava o relatório de forma atômica
+func (q *Quarantine) persist() error {
+	q.saveMutex.Lock()
+	defer q.saveMutex.Unlock()
+
+	q.mutex.Lock()
+	data, err := json.MarshalIndent(q.entries, "", "  ")
+	q.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode corrupt JSON report: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(q.reportPath), 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %v", err)
+	}
+	tmpPath := q.reportPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write corrupt JSON report: %v", err)
+	}
+	return os.Rename(tmpPath, q.reportPath)
+}
@@ -0,0 +1,202 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Tipos de relação entre séries (ordem de leitura, temporadas, spin-offs)
+const (
+	RelationSequel      = "sequel"
+	RelationPrequel     = "prequel"
+	RelationSeason      = "season"
+	RelationSpinOff     = "spin_off"
+	RelationSideStory   = "side_story"
+	RelationParent      = "parent"
+	RelationAlternative = "alternative"
+)
+
+// inverseRelations define a relação recíproca gravada na outra obra
+var inverseRelations = map[string]string{
+	RelationSequel:      RelationPrequel,
+	RelationPrequel:     RelationSequel,
+	RelationSeason:      RelationSeason,
+	RelationSpinOff:     RelationParent,
+	RelationSideStory:   RelationParent,
+	RelationParent:      RelationSpinOff,
+	RelationAlternative: RelationAlternative,
+}
+
+// RelatedSeries é um vínculo da obra com outra série (bloco "related" do JSON)
+type RelatedSeries struct {
+	Relation  string `json:"relation"`
+	ID        string `json:"id,omitempty"` // obra local (nome do JSON sem extensão)
+	Title     string `json:"title,omitempty"`
+	AniListID int    `json:"anilistId,omitempty"`
+	URL       string `json:"url,omitempty"` // link externo (leitor, JSON publicado)
+}
+
+// relatedKeyPattern localiza a chave "related" no JSON
+var relatedKeyPattern = regexp.MustCompile(`"related"\s*:\s*`)
+
+// chaptersKeyPattern localiza a chave "chapters", antes da qual o bloco é inserido
+var chaptersKeyPattern = regexp.MustCompile(`"chapters"\s*:`)
+
+// InverseRelation retorna a relação recíproca (vazia se desconhecida)
+func InverseRelation(relation string) string {
+	return inverseRelations[relation]
+}
+
+// Validate verifica se o vínculo tem relação conhecida e identifica uma série
+func (r RelatedSeries) Validate() error {
+	if _, known := inverseRelations[r.Relation]; !known {
+		return fmt.Errorf("unknown relation %q", r.Relation)
+	}
+	if r.ID == "" && r.AniListID == 0 && r.URL == "" {
+		return fmt.Errorf("related series needs an id, anilistId or url")
+	}
+	return nil
+}
+
+// sameSeries informa se dois vínculos apontam para a mesma série
+func (r RelatedSeries) sameSeries(other RelatedSeries) bool {
+	switch {
+	case r.ID != "" && other.ID != "":
+		return r.ID == other.ID
+	case r.AniListID != 0 && other.AniListID != 0:
+		return r.AniListID == other.AniListID
+	default:
+		return r.URL != "" && r.URL == other.URL
+	}
+}
+
+// LinkSeries adiciona (ou atualiza) um vínculo no JSON da obra. Com unlink, remove o vínculo.
+// Os demais campos do arquivo são preservados. Retorna a lista de vínculos resultante.
+func LinkSeries(jsonPath string, link RelatedSeries, unlink bool) ([]RelatedSeries, error) {
+	if !unlink {
+		if err := link.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	raw, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON: %v", err)
+	}
+
+	var current MangaJSON
+	if err := json.Unmarshal(raw, &current); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	related := make([]RelatedSeries, 0, len(current.Related)+1)
+	replaced := false
+	for _, existing := range current.Related {
+		if existing.sameSeries(link) {
+			if !unlink && !replaced {
+				related = append(related, link)
+				replaced = true
+			}
+			continue
+		}
+		related = append(related, existing)
+	}
+	if !unlink && !replaced {
+		related = append(related, link)
+	}
+
+	text, err := setRelatedBlock(string(raw), related)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(jsonPath, []byte(text), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write JSON: %v", err)
+	}
+	return related, nil
+}
+
+// setRelatedBlock grava o bloco "related" no texto do JSON preservando o restante.
+// Sem vínculos o bloco é removido; se a edição no texto falhar, o JSON é regenerado.
+func setRelatedBlock(text string, related []RelatedSeries) (string, error) {
+	value, err := json.MarshalIndent(related, "  ", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode related series: %v", err)
+	}
+
+	updated := text
+	if loc := relatedKeyPattern.FindStringIndex(text); loc != nil {
+		end := matchingBracket(text, loc[1])
+		if end < 0 {
+			updated = ""
+		} else if len(related) == 0 {
+			// Remove a linha da chave com o valor e a vírgula seguinte
+			start := loc[0]
+			if lineStart := strings.LastIndex(text[:start], "\n") + 1; strings.TrimSpace(text[lineStart:start]) == "" {
+				start = lineStart
+			}
+			rest := strings.TrimPrefix(strings.TrimLeft(text[end+1:], " \t"), ",")
+			rest = strings.TrimPrefix(strings.TrimLeft(rest, " \t\r"), "\n")
+			updated = text[:start] + rest
+		} else {
+			updated = text[:loc[1]] + string(value) + text[end+1:]
+		}
+	} else if len(related) > 0 {
+		if loc := chaptersKeyPattern.FindStringIndex(text); loc != nil {
+			updated = text[:loc[0]] + `"related": ` + string(value) + ",\n  " + text[loc[0]:]
+		} else {
+			updated = ""
+		}
+	}
+
+	var check map[string]interface{}
+	if updated != "" && json.Unmarshal([]byte(updated), &check) == nil {
+		return updated, nil
+	}
+
+	// Fallback: regenerar preservando todos os campos (a ordem pode mudar)
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &data); err != nil {
+		return "", fmt.Errorf("failed to parse JSON: %v", err)
+	}
+	if len(related) == 0 {
+		delete(data, "related")
+	} else {
+		data["related"] = related
+	}
+	output, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JSON: %v", err)
+	}
+	return string(output), nil
+}
+
+// matchingBracket retorna o índice do ']' que fecha o array iniciado em start (-1 se inválido)
+func matchingBracket(text string, start int) int {
+	if start >= len(text) || text[start] != '[' {
+		return -1
+	}
+
+	depth := 0
+	inString := false
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,114 @@
+package metadata
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// resultSpoolMaxAge é por quanto tempo os resultados de um lote ficam em disco
+// (o suficiente para gerar o post de lançamento depois de um reinício)
+const resultSpoolMaxAge = 24 * time.Hour
+
+// ResultSpool guarda os arquivos enviados de cada lote em disco, um JSON por
+// linha, em vez de acumulá-los na memória até a geração dos JSONs das obras.
+// Usado no modo lowMemory.
+type ResultSpool struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+// NewResultSpool cria o diretório do spool e descarta os lotes antigos
+func NewResultSpool(dir string) (*ResultSpool, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create result spool: %v", err)
+	}
+	spool := &ResultSpool{dir: dir}
+	spool.prune()
+	return spool, nil
+}
+
+// Append adiciona um arquivo enviado aos resultados do lote
+func (s *ResultSpool) Append(batchID string, file UploadedFile) error {
+	line, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode upload result: %v", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	f, err := os.OpenFile(s.path(batchID), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open result spool: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write result spool: %v", err)
+	}
+	return nil
+}
+
+// Load lê os arquivos enviados de um lote (vazio se o lote não tem resultados)
+func (s *ResultSpool) Load(batchID string) ([]UploadedFile, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	f, err := os.Open(s.path(batchID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open result spool: %v", err)
+	}
+	defer f.Close()
+
+	var files []UploadedFile
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var file UploadedFile
+		// Uma linha cortada por uma queda no meio da escrita é ignorada
+		if err := json.Unmarshal(scanner.Bytes(), &file); err != nil {
+			continue
+		}
+		files = append(files, file)
+	}
+	if err := scanner.Err(); err != nil {
+		return files, fmt.Errorf("failed to read result spool: %v", err)
+	}
+	return files, nil
+}
+
+// path retorna o arquivo do lote; caracteres fora de [A-Za-z0-9_-] viram "_"
+func (s *ResultSpool) path(batchID string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, batchID)
+	return filepath.Join(s.dir, name+".jsonl")
+}
+
+// prune remove os lotes sem novos resultados há mais de resultSpoolMaxAge
+func (s *ResultSpool) prune() {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
+			continue
+		}
+		if time.Since(info.ModTime()) > resultSpoolMaxAge {
+			os.Remove(filepath.Join(s.dir, entry.Name()))
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ChapterKey retorna a chave de um capítulo no JSON da obra, a mesma usada
+// pelo gerador ("1" e o relançamento "1v2" → "001")
+func ChapterKey(chapterID string) string {
+	base, _ := ParseChapterRevision(chapterID)
+	return new(JSONGenerator).formatChapterIndex(base)
+}
+
+// FilterChapters retorna o JSON da obra só com os capítulos aceitos por keep
+// e as chaves dos capítulos retirados. O arquivo em disco não é alterado;
+// sem capítulos retirados, data é retornado como está.
+func (jg *JSONGenerator) FilterChapters(data []byte, keep func(key string) bool) ([]byte, []string, error) {
+	var manga MangaJSON
+	if err := json.Unmarshal(data, &manga); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse manga JSON: %v", err)
+	}
+
+	var withheld []string
+	for key := range manga.Chapters {
+		if !keep(key) {
+			delete(manga.Chapters, key)
+			withheld = append(withheld, key)
+		}
+	}
+	if len(withheld) == 0 {
+		return data, nil, nil
+	}
+	sort.Strings(withheld)
+	return []byte(jg.buildOrderedJSON(manga)), withheld, nil
+}
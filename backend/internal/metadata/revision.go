@@ -0,0 +1,26 @@
+package metadata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+)
+
+// ContentRevision retorna a revisão de um JSON de obra (hash do conteúdo).
+// Usada para controle de concorrência otimista entre edições simultâneas.
+func ContentRevision(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// FileRevision retorna a revisão atual do arquivo ("" se ainda não existir)
+func FileRevision(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return ContentRevision(data), nil
+}
@@ -0,0 +1,90 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SeriesFilter seleciona obras pelo conteúdo dos JSONs em vez de uma lista
+// explícita. Os critérios preenchidos são combinados com "e"; All sozinho
+// seleciona a biblioteca inteira.
+type SeriesFilter struct {
+	All          bool   `json:"all,omitempty"`
+	UpdatedSince int64  `json:"updatedSince,omitempty"` // Unix: algum capítulo atualizado a partir daqui
+	Status       string `json:"status,omitempty"`       // status da obra, sem diferenciar maiúsculas
+	Group        string `json:"group,omitempty"`        // grupo de scan de algum capítulo (espelhos contam como o grupo base)
+}
+
+// IsEmpty indica que nenhum critério foi informado
+func (f SeriesFilter) IsEmpty() bool {
+	return !f.All && f.UpdatedSince == 0 && f.Status == "" && f.Group == ""
+}
+
+// Matches aplica o filtro a um JSON já carregado
+func (f SeriesFilter) Matches(manga *MangaJSON) bool {
+	if f.Status != "" && !strings.EqualFold(strings.TrimSpace(manga.Status), strings.TrimSpace(f.Status)) {
+		return false
+	}
+	if f.UpdatedSince == 0 && f.Group == "" {
+		return true
+	}
+
+	updated, grouped := f.UpdatedSince == 0, f.Group == ""
+	for _, chapter := range manga.Chapters {
+		if !updated {
+			if ts, err := strconv.ParseInt(chapter.LastUpdated, 10, 64); err == nil && ts >= f.UpdatedSince {
+				updated = true
+			}
+		}
+		if !grouped {
+			for group := range chapter.Groups {
+				if base, _, ok := ParseMirrorGroup(group); ok {
+					group = base
+				}
+				if strings.EqualFold(group, f.Group) {
+					grouped = true
+					break
+				}
+			}
+		}
+		if updated && grouped {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectSeries retorna, em ordem alfabética, os IDs (nome do JSON sem extensão)
+// das obras de jsonDir que passam no filtro. JSONs ilegíveis ficam de fora.
+func SelectSeries(jsonDir string, filter SeriesFilter) ([]string, error) {
+	if filter.IsEmpty() {
+		return nil, fmt.Errorf("empty series filter")
+	}
+
+	files, err := filepath.Glob(filepath.Join(jsonDir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list JSONs: %v", err)
+	}
+
+	selected := []string{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var manga MangaJSON
+		if err := json.Unmarshal(data, &manga); err != nil {
+			continue
+		}
+		if filter.Matches(&manga) {
+			selected = append(selected, strings.TrimSuffix(filepath.Base(file), ".json"))
+		}
+	}
+	sort.Strings(selected)
+	return selected, nil
+}
@@ -0,0 +1,131 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// TrashDirName é o subdiretório (dentro do diretório de JSONs) com os capítulos removidos
+const TrashDirName = ".trash"
+
+// maxTrashEntries limita quantas substituições são guardadas por obra (as mais antigas saem)
+const maxTrashEntries = 20
+
+// TrashEntry guarda os capítulos descartados por uma atualização em modo replace
+type TrashEntry struct {
+	RemovedAt time.Time          `json:"removedAt"`
+	Mode      string             `json:"mode"`
+	Chapters  map[string]Chapter `json:"chapters"`
+}
+
+// JSONTrash é o arquivo lateral com o histórico de capítulos removidos de uma obra
+type JSONTrash struct {
+	JSONFile string       `json:"jsonFile"`
+	Entries  []TrashEntry `json:"entries"` // Mais recente primeiro
+}
+
+// TrashPath retorna o caminho do arquivo de lixeira de um JSON de obra
+func TrashPath(jsonPath string) string {
+	return filepath.Join(filepath.Dir(jsonPath), TrashDirName, filepath.Base(jsonPath))
+}
+
+// LoadJSONTrash lê a lixeira de um JSON (vazia se nada foi removido ainda)
+func LoadJSONTrash(jsonPath string) (*JSONTrash, error) {
+	trash := &JSONTrash{JSONFile: filepath.Base(jsonPath), Entries: []TrashEntry{}}
+
+	data, err := os.ReadFile(TrashPath(jsonPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return trash, nil
+		}
+		return nil, fmt.Errorf("failed to read JSON trash: %v", err)
+	}
+
+	if err := json.Unmarshal(data, trash); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON trash: %v", err)
+	}
+	return trash, nil
+}
+
+// discardedChapters retorna os capítulos antigos que a nova versão removeu ou alterou
+func discardedChapters(previous, current map[string]Chapter) map[string]Chapter {
+	discarded := make(map[string]Chapter)
+	for index, chapter := range previous {
+		if kept, exists := current[index]; exists && reflect.DeepEqual(kept.Groups, chapter.Groups) {
+			continue
+		}
+		discarded[index] = chapter
+	}
+	return discarded
+}
+
+// moveToTrash grava os capítulos descartados na lixeira do JSON
+func moveToTrash(jsonPath, mode string, chapters map[string]Chapter) error {
+	if len(chapters) == 0 {
+		return nil
+	}
+
+	trash, err := LoadJSONTrash(jsonPath)
+	if err != nil {
+		// Lixeira corrompida não deve impedir a atualização: recomeça uma nova
+		trash = &JSONTrash{JSONFile: filepath.Base(jsonPath)}
+	}
+
+	trash.Entries = append([]TrashEntry{{
+		RemovedAt: time.Now(),
+		Mode:      mode,
+		Chapters:  chapters,
+	}}, trash.Entries...)
+	if len(trash.Entries) > maxTrashEntries {
+		trash.Entries = trash.Entries[:maxTrashEntries]
+	}
+
+	data, err := json.MarshalIndent(trash, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON trash: %v", err)
+	}
+
+	trashPath := TrashPath(jsonPath)
+	if err := os.MkdirAll(filepath.Dir(trashPath), 0755); err != nil {
+		return fmt.Errorf("failed to create trash directory: %v", err)
+	}
+	if err := os.WriteFile(trashPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON trash: %v", err)
+	}
+	return nil
+}
+
+// RemoveChapters apaga capítulos de um JSON de obra, guardando-os na lixeira
+// com o modo informado. Retorna quantos capítulos existiam e foram removidos.
+func (jg *JSONGenerator) RemoveChapters(jsonPath string, keys []string, mode string) (int, error) {
+	raw, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return 0, err
+	}
+	var manga MangaJSON
+	if err := json.Unmarshal(raw, &manga); err != nil {
+		return 0, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	removed := make(map[string]Chapter)
+	for _, key := range keys {
+		if chapter, exists := manga.Chapters[key]; exists {
+			removed[key] = chapter
+			delete(manga.Chapters, key)
+		}
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+	if err := moveToTrash(jsonPath, mode, removed); err != nil {
+		return 0, fmt.Errorf("failed to preserve removed chapters: %v", err)
+	}
+	if err := jg.saveJSONFile(jsonPath, manga); err != nil {
+		return 0, err
+	}
+	return len(removed), nil
+}
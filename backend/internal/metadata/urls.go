@@ -0,0 +1,40 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// RewritePageURLs aplica rewrite a todas as URLs de páginas do JSON e o salva
+// se alguma mudou. rewrite retorna a própria URL quando não há o que trocar.
+func (jg *JSONGenerator) RewritePageURLs(jsonPath string, rewrite func(pageURL string) string) (int, error) {
+	raw, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return 0, err
+	}
+	var manga MangaJSON
+	if err := json.Unmarshal(raw, &manga); err != nil {
+		return 0, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	changed := 0
+	for _, chapter := range manga.Chapters {
+		for _, urls := range chapter.Groups {
+			for i, pageURL := range urls {
+				if updated := rewrite(pageURL); updated != pageURL {
+					urls[i] = updated
+					changed++
+				}
+			}
+		}
+	}
+
+	if changed == 0 {
+		return 0, nil
+	}
+	if err := jg.saveJSONFile(jsonPath, manga); err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
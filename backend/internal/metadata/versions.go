@@ -0,0 +1,118 @@
+package metadata
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// chapterRevisionPattern reconhece pastas de capítulo relançadas: "ch 12 v2",
+// "012v3", "Cap 5 (v2)". O número do capítulo precisa vir antes do sufixo
+// para que nomes como "Vol2" não sejam confundidos com revisões.
+var chapterRevisionPattern = regexp.MustCompile(`(?i)^(.*\d)[\s._-]*[\[(]?v(\d+)[\])]?$`)
+
+// ChapterVersion é uma versão substituída de um capítulo, guardada em
+// previous_versions para que leitores e o histórico ainda a encontrem
+type ChapterVersion struct {
+	Version    int                 `json:"version"`
+	ReplacedAt string              `json:"replaced_at"`
+	Groups     map[string][]string `json:"groups"`
+}
+
+// ParseChapterRevision separa o sufixo de revisão do ID do capítulo
+// ("ch 12 v2" → "ch 12", 2). Sem sufixo, retorna o próprio ID e 0.
+func ParseChapterRevision(chapterID string) (string, int) {
+	match := chapterRevisionPattern.FindStringSubmatch(chapterID)
+	if match == nil {
+		return chapterID, 0
+	}
+	version, err := strconv.Atoi(match[2])
+	if err != nil || version == 0 {
+		return chapterID, 0
+	}
+	return match[1], version
+}
+
+// splitRevisions separa os capítulos com sufixo de revisão dos demais
+func splitRevisions(chapterFiles map[string][]UploadedFile) (regular, revisions map[string][]UploadedFile) {
+	regular = make(map[string][]UploadedFile)
+	revisions = make(map[string][]UploadedFile)
+	for chapterID, files := range chapterFiles {
+		if _, version := ParseChapterRevision(chapterID); version > 0 {
+			revisions[chapterID] = files
+		} else {
+			regular[chapterID] = files
+		}
+	}
+	return regular, revisions
+}
+
+// applyRevisions grava os relançamentos na chave do capítulo original. A versão
+// anterior vai para previous_versions; reenvios da mesma versão são mesclados
+// e versões mais antigas que a atual são ignoradas.
+func (jg *JSONGenerator) applyRevisions(mangaJSON *MangaJSON, revisions map[string][]UploadedFile) {
+	// Ordem crescente de versão: v3 arquiva a v2 quando as duas vêm no mesmo envio
+	chapterIDs := make([]string, 0, len(revisions))
+	for chapterID := range revisions {
+		chapterIDs = append(chapterIDs, chapterID)
+	}
+	sort.Slice(chapterIDs, func(i, j int) bool {
+		_, vi := ParseChapterRevision(chapterIDs[i])
+		_, vj := ParseChapterRevision(chapterIDs[j])
+		if vi != vj {
+			return vi < vj
+		}
+		return chapterIDs[i] < chapterIDs[j]
+	})
+
+	now := fmt.Sprintf("%d", time.Now().Unix())
+	for _, chapterID := range chapterIDs {
+		files := revisions[chapterID]
+		base, version := ParseChapterRevision(chapterID)
+		chapterIndex := jg.formatChapterIndex(base)
+		groups := jg.chapterGroups(jg.sortFilesByPageIndex(files))
+
+		existing, exists := mangaJSON.Chapters[chapterIndex]
+		current := existing.Version
+		if current == 0 {
+			current = 1 // capítulos sem versão são a primeira publicação
+		}
+
+		switch {
+		case !exists:
+			mangaJSON.Chapters[chapterIndex] = Chapter{
+				Title:       jg.getChapterTitle(base, files),
+				Volume:      jg.estimateVolume(base),
+				LastUpdated: now,
+				Groups:      groups,
+				Version:     version,
+			}
+		case version < current:
+			// Relançamento antigo chegando depois do atual: mantém o atual
+		case version == current:
+			if existing.Groups == nil {
+				existing.Groups = make(map[string][]string)
+			}
+			for group, urls := range groups {
+				existing.Groups[group] = jg.smartMergeURLs(existing.Groups[group], urls)
+			}
+			existing.Version = version
+			existing.LastUpdated = now
+			mangaJSON.Chapters[chapterIndex] = existing
+		default:
+			// Título e volume podem ter sido editados: só as URLs são trocadas.
+			// Grupos de outros idiomas não fazem parte do relançamento e ficam.
+			existing.PreviousVersions = append([]ChapterVersion{{
+				Version:    current,
+				ReplacedAt: now,
+				Groups:     existing.Groups,
+			}}, existing.PreviousVersions...)
+			existing.Groups = replaceLanguageGroups(existing.Groups, groups)
+			existing.Version = version
+			existing.LastUpdated = now
+			mangaJSON.Chapters[chapterIndex] = existing
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package mirrorhealth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/metadata"
+)
+
+// ErrRunning é retornado quando já existe uma verificação em andamento
+var ErrRunning = errors.New("verificação de espelhos já em andamento")
+
+// HostScore é a disponibilidade medida das páginas de um host
+type HostScore struct {
+	Host      string   `json:"host"`             // hostname das URLs
+	Labels    []string `json:"labels,omitempty"` // nomes do host nos grupos espelho
+	Checked   int      `json:"checked"`
+	Available int      `json:"available"`
+	Score     float64  `json:"score"` // disponíveis / verificadas
+	AvgMs     int64    `json:"avgMs"`
+	LastError string   `json:"lastError,omitempty"`
+	Series    int      `json:"series"` // obras espelhadas com páginas neste host
+}
+
+// Summary resume uma verificação dos espelhos
+type Summary struct {
+	StartedAt  time.Time             `json:"startedAt"`
+	FinishedAt time.Time             `json:"finishedAt"`
+	Series     int                   `json:"series"` // obras com capítulos espelhados
+	Hosts      map[string]*HostScore `json:"hosts"`
+	Ranking    []string              `json:"ranking"`          // hosts do mais ao menos saudável
+	Failed     map[string]string     `json:"failed,omitempty"` // JSONs que não puderam ser lidos
+	Canceled   bool                  `json:"canceled,omitempty"`
+}
+
+// Health converte as notas para a reescrita dos JSONs (metadata.FailoverMirrors)
+func (s *Summary) Health() map[string]metadata.HostHealth {
+	health := make(map[string]metadata.HostHealth)
+	if s == nil {
+		return health
+	}
+	for host, score := range s.Hosts {
+		if score.Checked == 0 {
+			continue
+		}
+		entry := metadata.HostHealth{Score: score.Score}
+		if len(score.Labels) > 0 {
+			entry.Label = score.Labels[0]
+		}
+		health[host] = entry
+	}
+	return health
+}
+
+// Config define os parâmetros da verificação periódica
+type Config struct {
+	JSONDir    string        // diretório dos JSONs da biblioteca
+	Interval   time.Duration // intervalo entre execuções (0 = apenas manual)
+	SampleSize int           // URLs verificadas por host em cada execução
+	Timeout    time.Duration // tempo máximo de cada requisição
+}
+
+// Checker mede a disponibilidade dos hosts das obras espelhadas
+type Checker struct {
+	config Config
+	client *http.Client
+
+	mutex   sync.Mutex
+	running bool
+	last    *Summary
+}
+
+// NewChecker cria o verificador de espelhos
+func NewChecker(config Config) *Checker {
+	if config.SampleSize <= 0 {
+		config.SampleSize = 20
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	return &Checker{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Start executa a verificação periodicamente até o contexto ser cancelado.
+// Não faz nada se o intervalo não estiver configurado.
+func (c *Checker) Start(ctx context.Context, onSummary func(*Summary)) {
+	if c.config.Interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(c.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				summary, err := c.RunOnce(ctx)
+				if err == nil && onSummary != nil {
+					onSummary(summary)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// LastSummary retorna o resumo da última verificação (nil se nunca executou)
+func (c *Checker) LastSummary() *Summary {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.last
+}
+
+// RunOnce verifica os hosts de todas as obras espelhadas
+func (c *Checker) RunOnce(ctx context.Context) (*Summary, error) {
+	if !c.begin() {
+		return nil, ErrRunning
+	}
+	return c.run(ctx), nil
+}
+
+// Trigger inicia uma verificação em background (ErrRunning se já houver uma)
+func (c *Checker) Trigger(ctx context.Context, onSummary func(*Summary)) error {
+	if !c.begin() {
+		return ErrRunning
+	}
+
+	go func() {
+		summary := c.run(ctx)
+		if onSummary != nil {
+			onSummary(summary)
+		}
+	}()
+	return nil
+}
+
+// begin marca uma execução como em andamento; false se já havia uma
+func (c *Checker) begin() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.running {
+		return false
+	}
+	c.running = true
+	return true
+}
+
+// run coleta as URLs espelhadas por host e verifica uma amostra de cada host em paralelo
+func (c *Checker) run(ctx context.Context) *Summary {
+	summary := &Summary{
+		StartedAt: time.Now(),
+		Hosts:     make(map[string]*HostScore),
+		Ranking:   []string{},
+		Failed:    make(map[string]string),
+	}
+
+	urls := c.collect(summary)
+
+	var wg sync.WaitGroup
+	for host, hostURLs := range urls {
+		wg.Add(1)
+		go func(score *HostScore, hostURLs []string) {
+			defer wg.Done()
+			c.checkHost(ctx, score, sample(hostURLs, c.config.SampleSize))
+		}(summary.Hosts[host], hostURLs)
+	}
+	wg.Wait()
+
+	for host := range summary.Hosts {
+		summary.Ranking = append(summary.Ranking, host)
+	}
+	sort.Slice(summary.Ranking, func(i, j int) bool {
+		a, b := summary.Hosts[summary.Ranking[i]], summary.Hosts[summary.Ranking[j]]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Host < b.Host
+	})
+	summary.Canceled = ctx.Err() != nil
+	summary.FinishedAt = time.Now()
+
+	c.mutex.Lock()
+	c.running = false
+	c.last = summary
+	c.mutex.Unlock()
+
+	return summary
+}
+
+// collect lê os JSONs e retorna as URLs dos capítulos espelhados, por host
+func (c *Checker) collect(summary *Summary) map[string][]string {
+	urls := make(map[string][]string)
+
+	files, err := filepath.Glob(filepath.Join(c.config.JSONDir, "*.json"))
+	if err != nil {
+		summary.Failed[c.config.JSONDir] = err.Error()
+		return urls
+	}
+
+	for _, file := range files {
+		mangaID := metadata.LockKey(file)
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			summary.Failed[mangaID] = err.Error()
+			continue
+		}
+		var manga metadata.MangaJSON
+		if err := json.Unmarshal(raw, &manga); err != nil {
+			summary.Failed[mangaID] = fmt.Sprintf("JSON inválido: %v", err)
+			continue
+		}
+
+		seriesHosts := make(map[string]bool)
+		for _, chapter := range manga.Chapters {
+			for _, members := range metadata.MirrorSets(chapter) {
+				for _, name := range members {
+					_, label, isMirror := metadata.ParseMirrorGroup(name)
+					for _, pageURL := range chapter.Groups[name] {
+						host := metadata.URLHost(pageURL)
+						if host == "" {
+							continue
+						}
+						score, exists := summary.Hosts[host]
+						if !exists {
+							score = &HostScore{Host: host}
+							summary.Hosts[host] = score
+						}
+						if isMirror && name != members[0] {
+							score.Labels = appendUnique(score.Labels, label)
+						}
+						seriesHosts[host] = true
+						urls[host] = append(urls[host], pageURL)
+					}
+				}
+			}
+		}
+
+		if len(seriesHosts) > 0 {
+			summary.Series++
+			for host := range seriesHosts {
+				summary.Hosts[host].Series++
+			}
+		}
+	}
+	return urls
+}
+
+// checkHost verifica as URLs de um host em sequência, para não sobrecarregá-lo
+func (c *Checker) checkHost(ctx context.Context, score *HostScore, urls []string) {
+	var total time.Duration
+	for _, pageURL := range urls {
+		if ctx.Err() != nil {
+			break
+		}
+
+		start := time.Now()
+		err := c.probe(ctx, pageURL)
+		total += time.Since(start)
+		score.Checked++
+		if err != nil {
+			score.LastError = err.Error()
+			continue
+		}
+		score.Available++
+	}
+
+	if score.Checked > 0 {
+		score.Score = float64(score.Available) / float64(score.Checked)
+		score.AvgMs = (total / time.Duration(score.Checked)).Milliseconds()
+	}
+}
+
+// probe verifica se uma página está disponível. Hosts que não aceitam HEAD
+// recebem um GET limitado ao primeiro byte.
+func (c *Checker) probe(ctx context.Context, pageURL string) error {
+	status, err := c.request(ctx, http.MethodHead, pageURL)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = c.request(ctx, http.MethodGet, pageURL)
+	}
+	if err != nil {
+		return err
+	}
+	if status >= 400 {
+		return fmt.Errorf("%s: HTTP %d", pageURL, status)
+	}
+	return nil
+}
+
+func (c *Checker) request(ctx context.Context, method, pageURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, pageURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// sample escolhe até size URLs distribuídas uniformemente pela lista
+func sample(urls []string, size int) []string {
+	if len(urls) <= size {
+		return urls
+	}
+	picked := make([]string, 0, size)
+	step := float64(len(urls)) / float64(size)
+	for i := 0; i < size; i++ {
+		picked = append(picked, urls[int(float64(i)*step)])
+	}
+	return picked
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -8,6 +8,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"go-upload/backend/internal/clock"
 )
 
 // AdvancedMetrics gerencia métricas avançadas para operações massivas
@@ -62,7 +64,8 @@ type AdvancedMetrics struct {
 	// Lifecycle
 	mutex                sync.RWMutex
 	stopChan             chan struct{}
-	ticker               *time.Ticker
+	ticker               clock.Ticker
+	clock                clock.Clock
 	wg                   sync.WaitGroup
 }
 
@@ -162,9 +165,15 @@ func (s AlertSeverity) String() string {
 
 // NewAdvancedMetrics cria um novo sistema de métricas avançadas
 func NewAdvancedMetrics() *AdvancedMetrics {
+	return NewAdvancedMetricsWithClock(clock.Real())
+}
+
+// NewAdvancedMetricsWithClock cria o sistema de métricas com o relógio clk
+func NewAdvancedMetricsWithClock(clk clock.Clock) *AdvancedMetrics {
 	am := &AdvancedMetrics{
-		startTime:           time.Now(),
-		lastMetricsUpdate:   time.Now(),
+		clock:               clk,
+		startTime:           clk.Now(),
+		lastMetricsUpdate:   clk.Now(),
 		errorCounts:         make(map[string]int64),
 		circuitBreakerStats: make(map[string]*CircuitBreakerMetrics),
 		rateLimiterStats:    make(map[string]*RateLimiterMetrics),
@@ -181,7 +190,7 @@ func NewAdvancedMetrics() *AdvancedMetrics {
 	}
 	
 	// Inicia coleta de métricas em background
-	am.ticker = time.NewTicker(1 * time.Minute)
+	am.ticker = clk.NewTicker(1 * time.Minute)
 	am.wg.Add(1)
 	go am.metricsCollector()
 	
@@ -194,7 +203,7 @@ func (am *AdvancedMetrics) metricsCollector() {
 	
 	for {
 		select {
-		case <-am.ticker.C:
+		case <-am.ticker.C():
 			am.collectSystemMetrics()
 			am.createHistoricalSnapshot()
 			am.checkThresholds()
@@ -223,13 +232,13 @@ func (am *AdvancedMetrics) collectSystemMetrics() {
 	}
 	
 	am.mutex.Lock()
-	am.lastMetricsUpdate = time.Now()
+	am.lastMetricsUpdate = am.clock.Now()
 	am.mutex.Unlock()
 }
 
 // createHistoricalSnapshot cria um snapshot histórico
 func (am *AdvancedMetrics) createHistoricalSnapshot() {
-	now := time.Now()
+	now := am.clock.Now()
 	
 	snapshot := &HistoricalSnapshot{
 		Timestamp:        now,
@@ -304,7 +313,7 @@ func (am *AdvancedMetrics) checkThresholds() {
 
 // cleanupOldMetrics limpa métricas antigas para evitar memory leak
 func (am *AdvancedMetrics) cleanupOldMetrics() {
-	cutoff := time.Now().Add(-24 * time.Hour)
+	cutoff := am.clock.Now().Add(-24 * time.Hour)
 	
 	// Limpa métricas de coleções antigas
 	am.cmMutex.Lock()
@@ -342,7 +351,7 @@ func (am *AdvancedMetrics) StartCollection(id, name string) {
 	
 	metrics := &CollectionMetrics{
 		Name:      name,
-		StartTime: time.Now(),
+		StartTime: am.clock.Now(),
 		Status:    "running",
 	}
 	
@@ -373,7 +382,7 @@ func (am *AdvancedMetrics) CompleteCollection(id string, success bool) {
 	
 	am.cmMutex.Lock()
 	if metrics, exists := am.collectionMetrics[id]; exists {
-		endTime := time.Now()
+		endTime := am.clock.Now()
 		metrics.EndTime = &endTime
 		if success {
 			metrics.Status = "completed"
@@ -421,7 +430,7 @@ func (am *AdvancedMetrics) RecordFileProcessed(collectionID string, fileSize int
 			}
 			
 			// Calcula velocidade atual
-			elapsed := time.Since(metrics.StartTime).Minutes()
+			elapsed := am.clock.Since(metrics.StartTime).Minutes()
 			if elapsed > 0 {
 				metrics.CurrentSpeed = float64(metrics.ProcessedFiles) / elapsed
 				if metrics.CurrentSpeed > metrics.PeakSpeed {
@@ -457,7 +466,7 @@ func (am *AdvancedMetrics) UpdateCircuitBreakerMetrics(name, state string, total
 	metrics.FailedRequests = failedReq
 	
 	if oldState != state {
-		metrics.LastStateChange = time.Now()
+		metrics.LastStateChange = am.clock.Now()
 		switch state {
 		case "open":
 			metrics.OpenCount++
@@ -487,7 +496,7 @@ func (am *AdvancedMetrics) UpdateRateLimiterMetrics(name string, currentRate, ma
 	
 	if oldRate != currentRate {
 		metrics.AdjustmentCount++
-		metrics.LastAdjustment = time.Now()
+		metrics.LastAdjustment = am.clock.Now()
 	}
 }
 
@@ -507,7 +516,7 @@ func (am *AdvancedMetrics) updateAverageUploadTime(duration time.Duration) {
 // updateCurrentUploadRate atualiza taxa de upload atual
 func (am *AdvancedMetrics) updateCurrentUploadRate() {
 	processed := atomic.LoadInt64(&am.processedFiles)
-	elapsed := time.Since(am.startTime).Minutes()
+	elapsed := am.clock.Since(am.startTime).Minutes()
 	
 	if elapsed > 0 {
 		rate := float64(processed) / elapsed
@@ -528,7 +537,7 @@ func (am *AdvancedMetrics) GetComprehensiveStats() map[string]interface{} {
 	// Métricas básicas
 	stats := map[string]interface{}{
 		"system": map[string]interface{}{
-			"uptime":                time.Since(am.startTime).String(),
+			"uptime":                am.clock.Since(am.startTime).String(),
 			"last_update":           am.lastMetricsUpdate,
 			"current_memory_mb":     atomic.LoadUint64(&am.currentMemoryUsage) / (1024 * 1024),
 			"peak_memory_mb":        atomic.LoadUint64(&am.peakMemoryUsage) / (1024 * 1024),
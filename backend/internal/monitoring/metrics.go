@@ -8,6 +8,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"go-upload/backend/internal/clock"
 )
 
 // Metrics representa as métricas do sistema
@@ -17,6 +19,8 @@ type Metrics struct {
 	SuccessfulUploads   int64     `json:"successfulUploads"`
 	FailedUploads       int64     `json:"failedUploads"`
 	BytesUploaded       int64     `json:"bytesUploaded"`
+	BytesTransferred    int64     `json:"bytesTransferred"` // enviados aos hosts, incluindo retries e espelhos
+	RetryBytes          int64     `json:"retryBytes"`       // ...dos quais reenviados por retries
 	AverageUploadTime   int64     `json:"averageUploadTime"` // em milliseconds
 	CurrentUploadRate   float64   `json:"currentUploadRate"` // uploads por segundo
 	
@@ -98,6 +102,8 @@ type Monitor struct {
 	cancel          context.CancelFunc
 	wg              sync.WaitGroup
 	collectors      []MetricCollector
+	clock           clock.Clock
+	hosts           map[string]*HostStats // uploads por host (protegido por mu)
 	
 	// Advanced metrics integration
 	advancedMetrics *AdvancedMetrics
@@ -111,14 +117,19 @@ type MetricCollector interface {
 
 // NewMonitor cria um novo monitor de métricas
 func NewMonitor() *Monitor {
+	return NewMonitorWithClock(clock.Real())
+}
+
+// NewMonitorWithClock cria um monitor cujos tickers e horários seguem clk
+func NewMonitorWithClock(clk clock.Clock) *Monitor {
 	ctx, cancel := context.WithCancel(context.Background())
 	
 	// Criar sistema de métricas avançadas
-	advancedMetrics := NewAdvancedMetrics()
+	advancedMetrics := NewAdvancedMetricsWithClock(clk)
 	
 	monitor := &Monitor{
 		metrics: &Metrics{
-			LastUpdated: time.Now(),
+			LastUpdated: clk.Now(),
 		},
 		perfMetrics: &PerformanceMetrics{
 			SystemLoad: SystemStats{
@@ -131,6 +142,7 @@ func NewMonitor() *Monitor {
 		cancel:          cancel,
 		collectors:      make([]MetricCollector, 0),
 		advancedMetrics: advancedMetrics,
+		clock:           clk,
 	}
 	
 	// Registrar callback de alerta
@@ -230,6 +242,57 @@ func (m *Monitor) RecordRateLimitHit() {
 	atomic.AddInt64(&m.metrics.RateLimitHits, 1)
 }
 
+// RecordTransfer registra bytes enviados a um host (retried = reenvio)
+func (m *Monitor) RecordTransfer(bytes int64, retried bool) {
+	atomic.AddInt64(&m.metrics.BytesTransferred, bytes)
+	if retried {
+		atomic.AddInt64(&m.metrics.RetryBytes, bytes)
+	}
+}
+
+// HostStats são os uploads de um host desde o início do servidor
+type HostStats struct {
+	Uploads    int64     `json:"uploads"`
+	Failed     int64     `json:"failed"`
+	Bytes      int64     `json:"bytes"`
+	LastUpload time.Time `json:"lastUpload,omitempty"`
+}
+
+// RecordHostUpload registra um upload concluído (ou que falhou) em um host
+func (m *Monitor) RecordHostUpload(host string, bytes int64, success bool) {
+	if host == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.hosts == nil {
+		m.hosts = make(map[string]*HostStats)
+	}
+	stats, exists := m.hosts[host]
+	if !exists {
+		stats = &HostStats{}
+		m.hosts[host] = stats
+	}
+	if !success {
+		stats.Failed++
+		return
+	}
+	stats.Uploads++
+	stats.Bytes += bytes
+	stats.LastUpload = m.clock.Now()
+}
+
+// HostStats retorna os uploads por host
+func (m *Monitor) HostStats() map[string]HostStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	hosts := make(map[string]HostStats, len(m.hosts))
+	for host, stats := range m.hosts {
+		hosts[host] = *stats
+	}
+	return hosts
+}
+
 // RecordRetryAttempt registra uma tentativa de retry
 func (m *Monitor) RecordRetryAttempt() {
 	atomic.AddInt64(&m.metrics.RetryAttempts, 1)
@@ -254,7 +317,7 @@ func (m *Monitor) GetMetrics() *Metrics {
 	
 	// Criar cópia das métricas
 	metrics := *m.metrics
-	metrics.LastUpdated = time.Now()
+	metrics.LastUpdated = m.clock.Now()
 	
 	return &metrics
 }
@@ -324,14 +387,14 @@ func (m *Monitor) calculateLatencyStats(durations []time.Duration) LatencyStats
 func (m *Monitor) collectSystemMetrics() {
 	defer m.wg.Done()
 	
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := m.clock.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 	
 	var lastGC uint32
 	
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			// Coletar métricas de runtime
 			var memStats runtime.MemStats
 			runtime.ReadMemStats(&memStats)
@@ -354,7 +417,7 @@ func (m *Monitor) collectSystemMetrics() {
 			}
 			
 			// Calcular taxa de upload atual (últimos 60 segundos)
-			now := time.Now()
+			now := m.clock.Now()
 			recentUploads := int64(0)
 			for _, duration := range m.uploadTimes {
 				if now.Sub(now.Add(-duration)) <= 60*time.Second {
@@ -408,7 +471,7 @@ func (m *Monitor) Reset() {
 	defer m.mu.Unlock()
 	
 	m.metrics = &Metrics{
-		LastUpdated: time.Now(),
+		LastUpdated: m.clock.Now(),
 	}
 	m.uploadTimes = m.uploadTimes[:0]
 	m.discoveryTimes = m.discoveryTimes[:0]
@@ -472,7 +535,7 @@ func (m *Monitor) SetMetricThresholds(thresholds *MetricThresholds) {
 // CreateComprehensiveSnapshot cria um snapshot completo incluindo métricas avançadas
 func (m *Monitor) CreateComprehensiveSnapshot() *ComprehensiveSnapshot {
 	return &ComprehensiveSnapshot{
-		Timestamp:        time.Now(),
+		Timestamp:        m.clock.Now(),
 		BasicMetrics:     m.GetMetrics(),
 		Performance:      m.GetPerformanceMetrics(),
 		AdvancedMetrics:  m.GetAdvancedMetrics(),
@@ -513,7 +576,7 @@ type MetricsSnapshot struct {
 // CreateSnapshot cria um snapshot completo das métricas
 func (m *Monitor) CreateSnapshot() *MetricsSnapshot {
 	return &MetricsSnapshot{
-		Timestamp:   time.Now(),
+		Timestamp:   m.clock.Now(),
 		Metrics:     m.GetMetrics(),
 		Performance: m.GetPerformanceMetrics(),
 		Custom:      make(map[string]interface{}),
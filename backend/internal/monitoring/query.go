@@ -0,0 +1,266 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Seções que Query sabe montar. Outras seções (ex.: "connections", "retries")
+// vêm de quem chama via Extra.
+const (
+	SectionMetrics     = "metrics"
+	SectionPerformance = "performance"
+	SectionHosts       = "hosts"
+	SectionAdvanced    = "advanced"    // coleções, arquivos, erros, breakers e rate limiters agregados
+	SectionCollections = "collections" // métricas por coleção (filtráveis por ID)
+	SectionHistory     = "history"     // snapshots por minuto (filtráveis por período)
+)
+
+// entrySections são seções em que Fields seleciona campos de cada item
+// (mapa por host/coleção ou lista de snapshots) e não do objeto inteiro
+var entrySections = map[string]bool{SectionHosts: true, SectionCollections: true, SectionHistory: true}
+
+// Query seleciona o que uma consulta de métricas retorna
+type Query struct {
+	Sections    []string  `json:"sections,omitempty"`    // vazio = seções padrão de quem chama
+	Fields      []string  `json:"fields,omitempty"`      // "seção.campo"; uma seção citada aqui é incluída
+	Collections []string  `json:"collections,omitempty"` // IDs de coleção (vazio = todas)
+	From        time.Time `json:"from,omitempty"`        // início do período do histórico
+	To          time.Time `json:"to,omitempty"`          // fim do período do histórico
+}
+
+// Extra monta uma seção fornecida por quem chama; só é chamada se a seção for pedida
+type Extra func() interface{}
+
+// ParseQuery lê uma Query de parâmetros de URL: sections, fields e
+// collections (separados por vírgula ou repetidos), from e to (veja ParseTime)
+func ParseQuery(values url.Values, now time.Time) (Query, error) {
+	query := Query{
+		Sections:    splitList(values["sections"]),
+		Fields:      splitList(values["fields"]),
+		Collections: splitList(values["collections"]),
+	}
+	var err error
+	if query.From, err = ParseTime(values.Get("from"), now); err != nil {
+		return Query{}, err
+	}
+	if query.To, err = ParseTime(values.Get("to"), now); err != nil {
+		return Query{}, err
+	}
+	return query, nil
+}
+
+// ParseTime aceita RFC 3339 ou uma duração relativa a now ("15m" = há 15
+// minutos). Vazio retorna o horário zero.
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(strings.TrimPrefix(value, "-")); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339 or a duration such as 15m", value)
+}
+
+// Empty indica se a consulta não filtra nada
+func (q Query) Empty() bool {
+	return len(q.Sections) == 0 && len(q.Fields) == 0 && len(q.Collections) == 0 && q.From.IsZero() && q.To.IsZero()
+}
+
+// Query monta as seções pedidas. Sem seções nem campos, filtrar por coleção
+// ou período escolhe as seções collections e history; sem filtro algum vale
+// defaults. extra fornece as seções que não são do Monitor. Seções
+// desconhecidas e períodos invertidos são erro.
+func (m *Monitor) Query(q Query, defaults []string, extra map[string]Extra) (map[string]interface{}, error) {
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return nil, fmt.Errorf("invalid time range: to is before from")
+	}
+
+	fields := make(map[string][]string)
+	for _, field := range q.Fields {
+		section, name, ok := strings.Cut(field, ".")
+		if !ok || section == "" || name == "" {
+			return nil, fmt.Errorf("invalid field %q: use section.field", field)
+		}
+		fields[section] = append(fields[section], name)
+	}
+
+	sections := q.Sections
+	if len(sections) == 0 && len(fields) == 0 {
+		if len(q.Collections) > 0 {
+			sections = append(sections, SectionCollections)
+		}
+		if !q.From.IsZero() || !q.To.IsZero() {
+			sections = append(sections, SectionHistory)
+		}
+		if len(sections) == 0 {
+			sections = defaults
+		}
+	}
+	wanted := make(map[string]bool)
+	var order []string
+	for _, section := range sections {
+		if !wanted[section] {
+			wanted[section] = true
+			order = append(order, section)
+		}
+	}
+	for section := range fields {
+		if !wanted[section] {
+			wanted[section] = true
+			order = append(order, section)
+		}
+	}
+
+	result := make(map[string]interface{}, len(order))
+	for _, section := range order {
+		var value interface{}
+		switch section {
+		case SectionMetrics:
+			value = m.GetMetrics()
+		case SectionPerformance:
+			value = m.GetPerformanceMetrics()
+		case SectionHosts:
+			value = m.HostStats()
+		case SectionAdvanced:
+			stats := m.GetAdvancedMetrics()
+			delete(stats, "active_collections")
+			delete(stats, "historical")
+			value = stats
+		case SectionCollections:
+			value = m.advancedMetrics.collectionsSnapshot(q.Collections)
+		case SectionHistory:
+			value = m.advancedMetrics.historyBetween(q.From, q.To)
+		default:
+			build, ok := extra[section]
+			if !ok {
+				return nil, fmt.Errorf("unknown metrics section %q (available: %s)", section, strings.Join(availableSections(extra), ", "))
+			}
+			value = build()
+		}
+
+		if names := fields[section]; len(names) > 0 {
+			picked, err := pickFields(value, names, entrySections[section])
+			if err != nil {
+				return nil, fmt.Errorf("section %s: %v", section, err)
+			}
+			value = picked
+		}
+		result[section] = value
+	}
+	return result, nil
+}
+
+// collectionsSnapshot copia as métricas das coleções ids (vazio = todas)
+func (am *AdvancedMetrics) collectionsSnapshot(ids []string) map[string]CollectionMetrics {
+	am.cmMutex.RLock()
+	defer am.cmMutex.RUnlock()
+
+	collections := make(map[string]CollectionMetrics)
+	if len(ids) == 0 {
+		for id, metrics := range am.collectionMetrics {
+			collections[id] = *metrics
+		}
+		return collections
+	}
+	for _, id := range ids {
+		if metrics, exists := am.collectionMetrics[id]; exists {
+			collections[id] = *metrics
+		}
+	}
+	return collections
+}
+
+// historyBetween retorna os snapshots entre from e to (zero = sem limite)
+func (am *AdvancedMetrics) historyBetween(from, to time.Time) []HistoricalSnapshot {
+	am.hmMutex.RLock()
+	defer am.hmMutex.RUnlock()
+
+	history := make([]HistoricalSnapshot, 0, len(am.historicalMetrics))
+	for _, snapshot := range am.historicalMetrics {
+		if !from.IsZero() && snapshot.Timestamp.Before(from) {
+			continue
+		}
+		if !to.IsZero() && snapshot.Timestamp.After(to) {
+			continue
+		}
+		history = append(history, *snapshot)
+	}
+	return history
+}
+
+// pickFields mantém só os campos names (nomes JSON) de value. Com perEntry,
+// aplica a seleção a cada item do mapa ou da lista.
+func pickFields(value interface{}, names []string, perEntry bool) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	pick := func(item interface{}) interface{} {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return item
+		}
+		picked := make(map[string]interface{}, len(names))
+		for _, name := range names {
+			if field, exists := object[name]; exists {
+				picked[name] = field
+			}
+		}
+		return picked
+	}
+
+	if !perEntry {
+		if _, ok := decoded.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("fields cannot be selected")
+		}
+		return pick(decoded), nil
+	}
+	switch entries := decoded.(type) {
+	case map[string]interface{}:
+		for key, entry := range entries {
+			entries[key] = pick(entry)
+		}
+	case []interface{}:
+		for i, entry := range entries {
+			entries[i] = pick(entry)
+		}
+	}
+	return decoded, nil
+}
+
+// availableSections lista as seções aceitas, em ordem alfabética
+func availableSections(extra map[string]Extra) []string {
+	sections := []string{SectionMetrics, SectionPerformance, SectionHosts, SectionAdvanced, SectionCollections, SectionHistory}
+	for section := range extra {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	return sections
+}
+
+// splitList junta valores repetidos e separados por vírgula, sem vazios
+func splitList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
@@ -0,0 +1,370 @@
+// Package msgpack serializa valores Go em MessagePack com o mesmo formato que
+// encoding/json produziria: nomes e omitempty das tags json, campos embutidos
+// promovidos, []byte em base64, time.Time em RFC 3339 e json.Marshaler/
+// encoding.TextMarshaler respeitados. Assim o cliente recebe as mesmas
+// estruturas em frames binários, só mais compactas e rápidas de gerar.
+//
+// Só há codificação: as requisições dos clientes continuam em JSON.
+package msgpack
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+	numberType        = reflect.TypeOf(json.Number(""))
+)
+
+// Marshal codifica v em MessagePack
+func Marshal(v interface{}) ([]byte, error) {
+	return appendValue(make([]byte, 0, 256), reflect.ValueOf(v))
+}
+
+func appendValue(buf []byte, v reflect.Value) ([]byte, error) {
+	if !v.IsValid() {
+		return append(buf, 0xc0), nil
+	}
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return append(buf, 0xc0), nil
+	}
+
+	// Métodos com receptor ponteiro valem para valores endereçáveis, como no encoding/json
+	t := v.Type()
+	if t.Kind() != reflect.Pointer && v.CanAddr() && !t.Implements(jsonMarshalerType) && !t.Implements(textMarshalerType) {
+		if pt := reflect.PointerTo(t); pt.Implements(jsonMarshalerType) || pt.Implements(textMarshalerType) {
+			v, t = v.Addr(), pt
+		}
+	}
+
+	switch {
+	case t == timeType:
+		return appendString(buf, v.Interface().(time.Time).Format(time.RFC3339Nano)), nil
+	case t.Kind() == reflect.Pointer && t.Elem() == timeType:
+		return appendValue(buf, v.Elem())
+	case t.Implements(jsonMarshalerType):
+		return appendJSONMarshaler(buf, v)
+	case t.Implements(textMarshalerType):
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return appendString(buf, string(text)), nil
+	case t == numberType:
+		return appendNumber(buf, v.String())
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return append(buf, 0xc0), nil
+		}
+		return appendValue(buf, v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			return append(buf, 0xc3), nil
+		}
+		return append(buf, 0xc2), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendInt(buf, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendUint(buf, v.Uint()), nil
+	case reflect.Float32:
+		buf = append(buf, 0xca)
+		return binary.BigEndian.AppendUint32(buf, math.Float32bits(float32(v.Float()))), nil
+	case reflect.Float64:
+		buf = append(buf, 0xcb)
+		return binary.BigEndian.AppendUint64(buf, math.Float64bits(v.Float())), nil
+	case reflect.String:
+		return appendString(buf, v.String()), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return append(buf, 0xc0), nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return appendString(buf, base64.StdEncoding.EncodeToString(v.Bytes())), nil
+		}
+		return appendArray(buf, v)
+	case reflect.Array:
+		return appendArray(buf, v)
+	case reflect.Map:
+		if v.IsNil() {
+			return append(buf, 0xc0), nil
+		}
+		return appendMap(buf, v)
+	case reflect.Struct:
+		return appendStruct(buf, v)
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type %s", t)
+}
+
+// appendJSONMarshaler reaproveita o MarshalJSON do tipo e recodifica o resultado
+func appendJSONMarshaler(buf []byte, v reflect.Value) ([]byte, error) {
+	data, err := v.Interface().(json.Marshaler).MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return appendValue(buf, reflect.ValueOf(decoded))
+}
+
+// appendNumber grava um json.Number como inteiro quando possível
+func appendNumber(buf []byte, number string) ([]byte, error) {
+	if n, err := strconv.ParseInt(number, 10, 64); err == nil {
+		return appendInt(buf, n), nil
+	}
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return nil, fmt.Errorf("msgpack: invalid number %q", number)
+	}
+	buf = append(buf, 0xcb)
+	return binary.BigEndian.AppendUint64(buf, math.Float64bits(f)), nil
+}
+
+func appendInt(buf []byte, n int64) []byte {
+	switch {
+	case n >= 0:
+		return appendUint(buf, uint64(n))
+	case n >= -32:
+		return append(buf, byte(n))
+	case n >= math.MinInt8:
+		return append(buf, 0xd0, byte(n))
+	case n >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xd1), uint16(n))
+	case n >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xd2), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(n))
+}
+
+func appendUint(buf []byte, n uint64) []byte {
+	switch {
+	case n <= 127:
+		return append(buf, byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xcd), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xce), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xcf), n)
+}
+
+func appendString(buf []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n < 32:
+		buf = append(buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		buf = append(buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xda), uint16(n))
+	default:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xdb), uint32(n))
+	}
+	return append(buf, s...)
+}
+
+func appendArrayHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xdc), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(buf, 0xdd), uint32(n))
+}
+
+func appendMapHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xde), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(buf, 0xdf), uint32(n))
+}
+
+func appendArray(buf []byte, v reflect.Value) ([]byte, error) {
+	buf = appendArrayHeader(buf, v.Len())
+	var err error
+	for i := 0; i < v.Len(); i++ {
+		if buf, err = appendValue(buf, v.Index(i)); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// appendMap grava as chaves como texto, como encoding/json faz
+func appendMap(buf []byte, v reflect.Value) ([]byte, error) {
+	buf = appendMapHeader(buf, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		buf = appendString(buf, key)
+		if buf, err = appendValue(buf, iter.Value()); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if k.Type().Implements(textMarshalerType) {
+		text, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("msgpack: unsupported map key type %s", k.Type())
+}
+
+// field é um campo serializado de um struct; index segue os campos embutidos
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+var fieldCache sync.Map // reflect.Type → []field
+
+// structFields lista os campos como encoding/json os veria
+func structFields(t reflect.Type) []field {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]field)
+	}
+	var fields []field
+	seen := make(map[string]bool)
+	var collect func(t reflect.Type, index []int)
+	collect = func(t reflect.Type, index []int) {
+		var embedded []reflect.StructField
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, options, _ := strings.Cut(tag, ",")
+			if sf.Anonymous && name == "" {
+				ft := sf.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					embedded = append(embedded, sf)
+					continue
+				}
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = sf.Name
+			}
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			fields = append(fields, field{
+				name:      name,
+				index:     append(append([]int(nil), index...), i),
+				omitEmpty: strings.Contains(","+options+",", ",omitempty,"),
+			})
+		}
+		// Campos promovidos perdem para os declarados no nível de cima
+		for _, sf := range embedded {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			collect(ft, append(append([]int(nil), index...), sf.Index...))
+		}
+	}
+	collect(t, nil)
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+func appendStruct(buf []byte, v reflect.Value) ([]byte, error) {
+	fields := structFields(v.Type())
+	values := make([]reflect.Value, 0, len(fields))
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || (f.omitEmpty && isEmpty(fv)) {
+			continue
+		}
+		values = append(values, fv)
+		names = append(names, f.name)
+	}
+
+	buf = appendMapHeader(buf, len(values))
+	var err error
+	for i, fv := range values {
+		buf = appendString(buf, names[i])
+		if buf, err = appendValue(buf, fv); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// fieldByIndex segue o caminho do campo; ponteiros embutidos nil omitem o campo
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isEmpty segue a regra de omitempty do encoding/json
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
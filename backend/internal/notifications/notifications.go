@@ -0,0 +1,236 @@
+// Package notifications guarda os eventos importantes (falhas, coleções
+// concluídas, páginas removidas dos hosts, capítulos aguardando aprovação)
+// com estado de lido/não lido, para que quem não estava conectado quando o
+// evento aconteceu ainda fique sabendo.
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Tipos de notificação
+const (
+	KindBatchFailed         = "batch_failed"         // lote terminou com arquivos que falharam
+	KindCollectionCompleted = "collection_completed" // coleção concluída
+	KindCollectionFailed    = "collection_failed"    // coleção falhou ou foi interrompida
+	KindTakedown            = "takedown"             // páginas publicadas que sumiram de um host
+	KindApprovalPending     = "approval_pending"     // capítulo enviado para QC
+	KindReviewReturned      = "review_returned"      // capítulo devolvido pelo QC
+)
+
+// Níveis de notificação
+const (
+	LevelInfo    = "info"
+	LevelWarning = "warning"
+	LevelError   = "error"
+)
+
+// DefaultMaxItems é quantas notificações são guardadas por padrão
+const DefaultMaxItems = 500
+
+// Notification é um evento guardado para o usuário. O texto é montado na
+// leitura a partir de Key e Args, no idioma de quem lê.
+type Notification struct {
+	ID        string                 `json:"id"`
+	Kind      string                 `json:"kind"`
+	Level     string                 `json:"level"`
+	Key       string                 `json:"key"`            // chave i18n da mensagem
+	Args      []string               `json:"args,omitempty"` // argumentos da mensagem
+	Message   string                 `json:"message,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+	ReadAt    *time.Time             `json:"readAt,omitempty"`
+}
+
+// Read informa se a notificação já foi lida
+func (n Notification) Read() bool {
+	return n.ReadAt != nil
+}
+
+// Store mantém as notificações em um arquivo JSON, da mais antiga à mais recente
+type Store struct {
+	path     string
+	maxItems int
+	items    []*Notification
+	nextID   int64
+	mutex    sync.RWMutex
+	saveMu   sync.Mutex // gravações na ordem das mudanças
+}
+
+// New cria o armazenamento e carrega o arquivo existente, se houver.
+// maxItems <= 0 usa DefaultMaxItems.
+func New(path string, maxItems int) (*Store, error) {
+	if maxItems <= 0 {
+		maxItems = DefaultMaxItems
+	}
+	s := &Store{path: path, maxItems: maxItems}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, fmt.Errorf("failed to read notifications: %v", err)
+	}
+	if err := json.Unmarshal(data, &s.items); err != nil {
+		s.items = nil
+		return s, fmt.Errorf("failed to parse notifications: %v", err)
+	}
+	for _, item := range s.items {
+		if id, err := strconv.ParseInt(item.ID, 10, 64); err == nil && id > s.nextID {
+			s.nextID = id
+		}
+	}
+	return s, nil
+}
+
+// Add guarda uma notificação e a retorna com ID e data preenchidos. Acima do
+// limite, as mais antigas já lidas saem primeiro.
+func (s *Store) Add(notification Notification) (Notification, error) {
+	if s == nil {
+		return notification, nil
+	}
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mutex.Lock()
+	s.nextID++
+	notification.ID = strconv.FormatInt(s.nextID, 10)
+	notification.ReadAt = nil
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+	if notification.Level == "" {
+		notification.Level = LevelInfo
+	}
+	s.items = append(s.items, &notification)
+	s.trim()
+	data, err := s.encode()
+	s.mutex.Unlock()
+
+	if err != nil {
+		return notification, err
+	}
+	return notification, s.write(data)
+}
+
+// trim descarta as notificações excedentes, lidas primeiro (chamador deve ter o lock)
+func (s *Store) trim() {
+	excess := len(s.items) - s.maxItems
+	if excess <= 0 {
+		return
+	}
+	kept := s.items[:0]
+	for _, item := range s.items {
+		if excess > 0 && item.Read() {
+			excess--
+			continue
+		}
+		kept = append(kept, item)
+	}
+	if excess > 0 {
+		kept = kept[excess:]
+	}
+	s.items = kept
+}
+
+// List retorna as notificações da mais recente à mais antiga (no máximo
+// limit; 0 = todas) e quantas não foram lidas
+func (s *Store) List(unreadOnly bool, limit int) ([]Notification, int) {
+	list := []Notification{}
+	if s == nil {
+		return list, 0
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	unread := 0
+	for i := len(s.items) - 1; i >= 0; i-- {
+		item := s.items[i]
+		if !item.Read() {
+			unread++
+		}
+		if unreadOnly && item.Read() {
+			continue
+		}
+		if limit <= 0 || len(list) < limit {
+			list = append(list, *item)
+		}
+	}
+	return list, unread
+}
+
+// Unread retorna quantas notificações não foram lidas
+func (s *Store) Unread() int {
+	_, unread := s.List(true, 1)
+	return unread
+}
+
+// MarkRead marca as notificações ids como lidas (vazio = todas) e informa
+// quantas mudaram
+func (s *Store) MarkRead(ids []string) (int, error) {
+	if s == nil {
+		return 0, nil
+	}
+
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mutex.Lock()
+	now := time.Now()
+	marked := 0
+	for _, item := range s.items {
+		if item.Read() || (len(wanted) > 0 && !wanted[item.ID]) {
+			continue
+		}
+		readAt := now
+		item.ReadAt = &readAt
+		marked++
+	}
+	if marked == 0 {
+		s.mutex.Unlock()
+		return 0, nil
+	}
+	data, err := s.encode()
+	s.mutex.Unlock()
+
+	if err != nil {
+		return 0, err
+	}
+	return marked, s.write(data)
+}
+
+// encode serializa as notificações (chamador deve ter o lock)
+func (s *Store) encode() ([]byte, error) {
+	data, err := json.MarshalIndent(s.items, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notifications: %v", err)
+	}
+	return data, nil
+}
+
+// write grava o arquivo de forma atômica
+func (s *Store) write(data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create notifications directory: %v", err)
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write notifications: %v", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write notifications: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,576 @@
+package optimize
+
+import (
+	"encoding/binary"
+	_ "image/jpeg" // decodificador usado na verificação pixel a pixel
+)
+
+var jpegSOI = []byte{0xFF, 0xD8}
+
+// huffTable é uma tabela de Huffman no formato do segmento DHT
+type huffTable struct {
+	bits [17]int // bits[l] = quantidade de códigos com l bits
+	vals []byte
+
+	// decodificação
+	maxCode [18]int
+	valPtr  [17]int
+	minCode [17]int
+	// codificação
+	code [256]uint16
+	size [256]uint8
+}
+
+// prepare monta as tabelas de decodificação e codificação a partir de bits/vals
+func (t *huffTable) prepare() bool {
+	code, k := 0, 0
+	for l := 1; l <= 16; l++ {
+		t.valPtr[l] = k
+		t.minCode[l] = code
+		for i := 0; i < t.bits[l]; i++ {
+			if k >= len(t.vals) {
+				return false
+			}
+			t.code[t.vals[k]] = uint16(code)
+			t.size[t.vals[k]] = uint8(l)
+			code++
+			k++
+		}
+		t.maxCode[l] = code - 1
+		if t.bits[l] == 0 {
+			t.maxCode[l] = -1
+		}
+		code <<= 1
+	}
+	t.maxCode[17] = 1 << 30
+	return k == len(t.vals)
+}
+
+// jpegComponent é um componente de cor declarado no SOF
+type jpegComponent struct {
+	id   byte
+	h, v int
+}
+
+// symbol é um símbolo de Huffman seguido dos seus bits extras
+type symbol struct {
+	table int // índice da tabela na varredura
+	value byte
+	extra uint16
+	size  uint8
+}
+
+// tableKey identifica uma tabela pela classe (0 = DC, 1 = AC) e pelo id
+type tableKey struct{ class, id int }
+
+// optimizeJPEG regrava as varreduras de um JPEG sequencial com tabelas de
+// Huffman ótimas para o seu conteúdo. JPEGs progressivos, aritméticos ou
+// malformados voltam intactos (ok = false).
+func optimizeJPEG(data []byte) ([]byte, bool) {
+	out := make([]byte, 0, len(data))
+	out = append(out, jpegSOI...)
+
+	tables := make(map[tableKey]*huffTable)
+	var components []jpegComponent
+	var width, height, restartInterval int
+
+	i := 2
+	for i < len(data) {
+		if data[i] != 0xFF {
+			return nil, false
+		}
+		for i+1 < len(data) && data[i+1] == 0xFF {
+			i++
+		}
+		if i+1 >= len(data) {
+			return nil, false
+		}
+		marker := data[i+1]
+		if marker == 0xD9 {
+			return append(out, 0xFF, 0xD9), true
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) || i+4 > len(data) {
+			return nil, false
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, false
+		}
+		payload := data[i+4 : end]
+
+		switch {
+		case marker == 0xC0 || marker == 0xC1:
+			if len(payload) < 6 || payload[0] != 8 {
+				return nil, false
+			}
+			height = int(binary.BigEndian.Uint16(payload[1:3]))
+			width = int(binary.BigEndian.Uint16(payload[3:5]))
+			count := int(payload[5])
+			if width == 0 || height == 0 || len(payload) < 6+3*count {
+				return nil, false
+			}
+			components = components[:0]
+			for c := 0; c < count; c++ {
+				hv := payload[7+3*c]
+				component := jpegComponent{id: payload[6+3*c], h: int(hv >> 4), v: int(hv & 15)}
+				if component.h < 1 || component.h > 4 || component.v < 1 || component.v > 4 {
+					return nil, false
+				}
+				components = append(components, component)
+			}
+			out = append(out, data[i:end]...)
+		case marker >= 0xC2 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC:
+			// Progressivo, sem perdas, hierárquico ou aritmético
+			return nil, false
+		case marker == 0xC4:
+			// As tabelas originais só servem para decodificar; as novas vão antes de cada SOS
+			if !parseDHT(payload, tables) {
+				return nil, false
+			}
+		case marker == 0xDD:
+			if len(payload) < 2 {
+				return nil, false
+			}
+			restartInterval = int(binary.BigEndian.Uint16(payload[0:2]))
+			out = append(out, data[i:end]...)
+		case marker == 0xDA:
+			scanEnd := entropyEnd(data, end)
+			scan, ok := rewriteScan(payload, data[end:scanEnd], tables, components, width, height, restartInterval)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, scan...)
+			i = scanEnd
+			continue
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return nil, false
+}
+
+// parseDHT lê as tabelas de um segmento DHT
+func parseDHT(payload []byte, tables map[tableKey]*huffTable) bool {
+	for len(payload) > 0 {
+		if len(payload) < 17 {
+			return false
+		}
+		class, id := int(payload[0]>>4), int(payload[0]&15)
+		if class > 1 || id > 3 {
+			return false
+		}
+		table := &huffTable{}
+		total := 0
+		for l := 1; l <= 16; l++ {
+			table.bits[l] = int(payload[l])
+			total += table.bits[l]
+		}
+		if total > 256 || len(payload) < 17+total {
+			return false
+		}
+		table.vals = append([]byte(nil), payload[17:17+total]...)
+		if !table.prepare() {
+			return false
+		}
+		tables[tableKey{class, id}] = table
+		payload = payload[17+total:]
+	}
+	return true
+}
+
+// entropyEnd encontra o fim dos dados comprimidos: o primeiro marcador que não
+// seja byte de escape (FF00) nem de reinício (RSTn)
+func entropyEnd(data []byte, start int) int {
+	for i := start; i+1 < len(data); i++ {
+		if data[i] != 0xFF {
+			continue
+		}
+		next := data[i+1]
+		if next == 0x00 || (next >= 0xD0 && next <= 0xD7) {
+			i++
+			continue
+		}
+		if next == 0xFF {
+			continue // preenchimento antes do marcador
+		}
+		return i
+	}
+	return len(data)
+}
+
+// scanComponent é um componente participante de uma varredura
+type scanComponent struct {
+	jpegComponent
+	dc, ac int // índices das tabelas na varredura
+}
+
+// rewriteScan decodifica os símbolos da varredura e a regrava com tabelas ótimas
+func rewriteScan(header, entropy []byte, tables map[tableKey]*huffTable, components []jpegComponent, width, height, restartInterval int) ([]byte, bool) {
+	if len(components) == 0 || len(header) < 1 {
+		return nil, false
+	}
+	count := int(header[0])
+	if count < 1 || count > 4 || len(header) != 4+2*count {
+		return nil, false
+	}
+	// Ss=0, Se=63, Ah=Al=0: varredura sequencial
+	if header[1+2*count] != 0 || header[2+2*count] != 63 || header[3+2*count] != 0 {
+		return nil, false
+	}
+
+	var keys []tableKey
+	indexOf := func(key tableKey) int {
+		for i, existing := range keys {
+			if existing == key {
+				return i
+			}
+		}
+		keys = append(keys, key)
+		return len(keys) - 1
+	}
+	var scan []scanComponent
+	for c := 0; c < count; c++ {
+		id, selectors := header[1+2*c], header[2+2*c]
+		var component *jpegComponent
+		for k := range components {
+			if components[k].id == id {
+				component = &components[k]
+			}
+		}
+		dcKey, acKey := tableKey{0, int(selectors >> 4)}, tableKey{1, int(selectors & 15)}
+		if component == nil || tables[dcKey] == nil || tables[acKey] == nil {
+			return nil, false
+		}
+		scan = append(scan, scanComponent{jpegComponent: *component, dc: indexOf(dcKey), ac: indexOf(acKey)})
+	}
+	decodeTables := make([]*huffTable, len(keys))
+	for k, key := range keys {
+		decodeTables[k] = tables[key]
+	}
+
+	symbols, restarts, ok := decodeScan(entropy, scan, decodeTables, components, width, height, restartInterval)
+	if !ok {
+		return nil, false
+	}
+
+	// Frequências por tabela e novas tabelas
+	frequencies := make([][257]int, len(keys))
+	for _, s := range symbols {
+		frequencies[s.table][s.value]++
+	}
+	optimal := make([]*huffTable, len(keys))
+	dht := []byte{0xFF, 0xC4, 0, 0}
+	for k, key := range keys {
+		optimal[k] = optimalTable(frequencies[k])
+		if !optimal[k].prepare() {
+			return nil, false
+		}
+		dht = append(dht, byte(key.class<<4|key.id))
+		for l := 1; l <= 16; l++ {
+			dht = append(dht, byte(optimal[k].bits[l]))
+		}
+		dht = append(dht, optimal[k].vals...)
+	}
+	binary.BigEndian.PutUint16(dht[2:4], uint16(len(dht)-2))
+
+	out := append(dht, 0xFF, 0xDA, 0, 0)
+	binary.BigEndian.PutUint16(out[len(out)-2:], uint16(len(header)+2))
+	out = append(out, header...)
+
+	var writer bitWriter
+	writer.out = out
+	next := 0
+	for n, s := range symbols {
+		for next < len(restarts) && restarts[next] == n {
+			writer.flush()
+			writer.out = append(writer.out, 0xFF, byte(0xD0+next%8))
+			next++
+		}
+		table := optimal[s.table]
+		writer.write(uint32(table.code[s.value]), table.size[s.value])
+		writer.write(uint32(s.extra), s.size)
+	}
+	writer.flush()
+	return writer.out, true
+}
+
+// decodeScan extrai os símbolos de todos os blocos da varredura. restarts guarda
+// o índice do símbolo antes do qual cada marcador RSTn aparece.
+func decodeScan(entropy []byte, scan []scanComponent, tables []*huffTable, components []jpegComponent, width, height, restartInterval int) ([]symbol, []int, bool) {
+	hMax, vMax := 1, 1
+	for _, component := range components {
+		hMax, vMax = max(hMax, component.h), max(vMax, component.v)
+	}
+
+	var mcus int
+	blocksPerMCU := 0
+	if len(scan) == 1 {
+		// Varredura não intercalada: um bloco por MCU
+		c := scan[0]
+		w := (width*c.h + hMax - 1) / hMax
+		h := (height*c.v + vMax - 1) / vMax
+		mcus = ((w + 7) / 8) * ((h + 7) / 8)
+		blocksPerMCU = 1
+		scan[0].h, scan[0].v = 1, 1
+	} else {
+		mcus = ((width + 8*hMax - 1) / (8 * hMax)) * ((height + 8*vMax - 1) / (8 * vMax))
+		for _, c := range scan {
+			blocksPerMCU += c.h * c.v
+		}
+		if blocksPerMCU > 10 {
+			return nil, nil, false
+		}
+	}
+
+	reader := bitReader{data: entropy}
+	symbols := make([]symbol, 0, mcus*blocksPerMCU*8)
+	var restarts []int
+	for mcu := 0; mcu < mcus; mcu++ {
+		if restartInterval > 0 && mcu > 0 && mcu%restartInterval == 0 {
+			if !reader.restart() {
+				return nil, nil, false
+			}
+			restarts = append(restarts, len(symbols))
+		}
+		for _, c := range scan {
+			for b := 0; b < c.h*c.v; b++ {
+				var ok bool
+				if symbols, ok = decodeBlock(&reader, symbols, tables, c.dc, c.ac); !ok {
+					return nil, nil, false
+				}
+			}
+		}
+	}
+	return symbols, restarts, true
+}
+
+// decodeBlock lê o coeficiente DC e os AC de um bloco 8x8
+func decodeBlock(reader *bitReader, symbols []symbol, tables []*huffTable, dc, ac int) ([]symbol, bool) {
+	value, ok := reader.decode(tables[dc])
+	if !ok || value > 11 {
+		return nil, false
+	}
+	extra, ok := reader.bits(value)
+	if !ok {
+		return nil, false
+	}
+	symbols = append(symbols, symbol{table: dc, value: value, extra: extra, size: value})
+
+	for k := 1; k < 64; {
+		value, ok := reader.decode(tables[ac])
+		if !ok {
+			return nil, false
+		}
+		run, size := value>>4, value&15
+		if size == 0 {
+			symbols = append(symbols, symbol{table: ac, value: value})
+			if run != 15 {
+				break // EOB
+			}
+			k += 16
+			continue
+		}
+		k += int(run)
+		extra, ok := reader.bits(size)
+		if !ok || k > 63 {
+			return nil, false
+		}
+		symbols = append(symbols, symbol{table: ac, value: value, extra: extra, size: size})
+		k++
+	}
+	return symbols, true
+}
+
+// optimalTable gera a tabela de Huffman ótima (JPEG, anexo K.2) com códigos de
+// até 16 bits; o símbolo 256 reserva o código só de uns, proibido pelo padrão
+func optimalTable(frequencies [257]int) *huffTable {
+	freq := frequencies
+	freq[256] = 1
+	var codeSize [257]int
+	var others [257]int
+	for i := range others {
+		others[i] = -1
+	}
+
+	for {
+		c1, c2 := -1, -1
+		for i := 0; i <= 256; i++ {
+			if freq[i] > 0 && (c1 < 0 || freq[i] <= freq[c1]) {
+				c1 = i
+			}
+		}
+		for i := 0; i <= 256; i++ {
+			if freq[i] > 0 && i != c1 && (c2 < 0 || freq[i] <= freq[c2]) {
+				c2 = i
+			}
+		}
+		if c2 < 0 {
+			break
+		}
+		freq[c1] += freq[c2]
+		freq[c2] = 0
+		codeSize[c1]++
+		for others[c1] >= 0 {
+			c1 = others[c1]
+			codeSize[c1]++
+		}
+		others[c1] = c2
+		codeSize[c2]++
+		for others[c2] >= 0 {
+			c2 = others[c2]
+			codeSize[c2]++
+		}
+	}
+
+	var bits [33]int
+	for i := 0; i <= 256; i++ {
+		if codeSize[i] > 0 {
+			bits[codeSize[i]]++
+		}
+	}
+	for i := 32; i > 16; i-- {
+		for bits[i] > 0 {
+			j := i - 2
+			for bits[j] == 0 {
+				j--
+			}
+			bits[i] -= 2
+			bits[i-1]++
+			bits[j+1] += 2
+			bits[j]--
+		}
+	}
+	i := 16
+	for bits[i] == 0 {
+		i--
+	}
+	bits[i]--
+
+	table := &huffTable{}
+	copy(table.bits[1:], bits[1:17])
+	for length := 1; length <= 32; length++ {
+		for value := 0; value < 256; value++ {
+			if codeSize[value] == length {
+				table.vals = append(table.vals, byte(value))
+			}
+		}
+	}
+	return table
+}
+
+// bitReader lê os dados comprimidos removendo os bytes de escape
+type bitReader struct {
+	data  []byte
+	pos   int
+	acc   uint32
+	count uint8
+}
+
+func (r *bitReader) fill() bool {
+	for r.count <= 24 {
+		if r.pos >= len(r.data) {
+			return r.count > 0
+		}
+		b := r.data[r.pos]
+		if b == 0xFF {
+			if r.pos+1 >= len(r.data) || r.data[r.pos+1] != 0x00 {
+				// Marcador: sem mais bits até o reinício
+				return r.count > 0
+			}
+			r.pos++
+		}
+		r.pos++
+		r.acc |= uint32(b) << (24 - r.count)
+		r.count += 8
+	}
+	return true
+}
+
+func (r *bitReader) bit() (uint32, bool) {
+	if r.count == 0 && !r.fill() {
+		return 0, false
+	}
+	b := r.acc >> 31
+	r.acc <<= 1
+	r.count--
+	return b, true
+}
+
+func (r *bitReader) bits(n uint8) (uint16, bool) {
+	var value uint32
+	for i := uint8(0); i < n; i++ {
+		b, ok := r.bit()
+		if !ok {
+			return 0, false
+		}
+		value = value<<1 | b
+	}
+	return uint16(value), true
+}
+
+func (r *bitReader) decode(table *huffTable) (byte, bool) {
+	code := 0
+	for l := 1; l <= 16; l++ {
+		b, ok := r.bit()
+		if !ok {
+			return 0, false
+		}
+		code = code<<1 | int(b)
+		if code <= table.maxCode[l] {
+			index := table.valPtr[l] + code - table.minCode[l]
+			if index < 0 || index >= len(table.vals) {
+				return 0, false
+			}
+			return table.vals[index], true
+		}
+	}
+	return 0, false
+}
+
+// restart descarta o preenchimento e consome o próximo marcador RSTn
+func (r *bitReader) restart() bool {
+	r.acc, r.count = 0, 0
+	for r.pos+1 < len(r.data) {
+		if r.data[r.pos] == 0xFF && r.data[r.pos+1] >= 0xD0 && r.data[r.pos+1] <= 0xD7 {
+			r.pos += 2
+			return true
+		}
+		r.pos++
+	}
+	return false
+}
+
+// bitWriter grava os bits inserindo o byte de escape após cada 0xFF
+type bitWriter struct {
+	out   []byte
+	acc   uint32
+	count uint8
+}
+
+func (w *bitWriter) write(value uint32, size uint8) {
+	for size > 0 {
+		size--
+		w.acc = w.acc<<1 | (value>>size)&1
+		w.count++
+		if w.count == 8 {
+			w.emit(byte(w.acc))
+			w.acc, w.count = 0, 0
+		}
+	}
+}
+
+func (w *bitWriter) emit(b byte) {
+	w.out = append(w.out, b)
+	if b == 0xFF {
+		w.out = append(w.out, 0x00)
+	}
+}
+
+// flush completa o último byte com uns, como exige o padrão
+func (w *bitWriter) flush() {
+	if w.count > 0 {
+		w.write(0xFF, 8-w.count)
+	}
+}
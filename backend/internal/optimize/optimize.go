@@ -0,0 +1,134 @@
+// Package optimize recomprime imagens sem perda de qualidade antes do upload:
+// PNGs são recodificados com compressão máxima e JPEGs recebem tabelas de
+// Huffman otimizadas (como jpegtran -optimize), sem tocar nos coeficientes.
+//
+// Toda saída é decodificada e comparada pixel a pixel com a original; se
+// houver qualquer diferença, ou se o arquivo não ficar menor, a original é
+// mantida. O trabalho é limitado a um número fixo de workers porque é
+// intensivo em CPU e os uploads rodam com concorrência bem maior.
+package optimize
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Config é a seção "optimize" da configuração
+type Config struct {
+	Enabled bool `json:"enabled"`           // otimiza todos os lotes e coleções por padrão
+	Workers int  `json:"workers,omitempty"` // otimizações simultâneas; padrão: número de CPUs
+}
+
+// Optimizer limita as otimizações simultâneas. Os métodos aceitam Optimizer nil
+// (arquivos voltam intactos).
+type Optimizer struct {
+	workers chan struct{}
+}
+
+// New cria um Optimizer com o número de workers da configuração
+func New(config Config) *Optimizer {
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &Optimizer{workers: make(chan struct{}, workers)}
+}
+
+// File grava em dir ("" = diretório temporário do sistema) uma cópia otimizada
+// de path. Se não houver ganho, retorna o próprio path e 0; caso contrário o
+// chamador deve apagar o arquivo retornado após o uso.
+func (o *Optimizer) File(path, dir string) (string, int64, error) {
+	if o == nil {
+		return path, 0, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	o.workers <- struct{}{}
+	out, saved := Optimize(data)
+	<-o.workers
+	if saved == 0 {
+		return path, 0, nil
+	}
+
+	tmp, err := os.CreateTemp(dir, "optimize-*"+filepath.Ext(path))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", 0, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", 0, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return tmp.Name(), int64(saved), nil
+}
+
+// Optimize retorna a versão otimizada dos dados e quantos bytes foram
+// economizados. Formatos não suportados e arquivos sem ganho voltam intactos.
+func Optimize(data []byte) ([]byte, int) {
+	var out []byte
+	var ok bool
+	switch {
+	case bytes.HasPrefix(data, jpegSOI):
+		out, ok = optimizeJPEG(data)
+	case bytes.HasPrefix(data, pngSignature):
+		out, ok = optimizePNG(data)
+	}
+	if !ok || len(out) >= len(data) || !samePixels(data, out) {
+		return data, 0
+	}
+	return out, len(data) - len(out)
+}
+
+// samePixels decodifica as duas versões e compara todos os pixels
+func samePixels(original, optimized []byte) bool {
+	a, _, err := image.Decode(bytes.NewReader(original))
+	if err != nil {
+		return false
+	}
+	b, _, err := image.Decode(bytes.NewReader(optimized))
+	if err != nil {
+		return false
+	}
+	if a.Bounds() != b.Bounds() {
+		return false
+	}
+
+	// Caminho rápido para os tipos que os decodificadores da biblioteca padrão produzem
+	switch a := a.(type) {
+	case *image.YCbCr:
+		if b, ok := b.(*image.YCbCr); ok && a.SubsampleRatio == b.SubsampleRatio {
+			return bytes.Equal(a.Y, b.Y) && bytes.Equal(a.Cb, b.Cb) && bytes.Equal(a.Cr, b.Cr)
+		}
+	case *image.Gray:
+		if b, ok := b.(*image.Gray); ok {
+			return bytes.Equal(a.Pix, b.Pix)
+		}
+	case *image.CMYK:
+		if b, ok := b.(*image.CMYK); ok {
+			return bytes.Equal(a.Pix, b.Pix)
+		}
+	}
+
+	bounds := a.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r1, g1, b1, a1 := a.At(x, y).RGBA()
+			r2, g2, b2, a2 := b.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package optimize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/png"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngKeep são os chunks auxiliares copiados da original logo após o IHDR:
+// perfil de cor, densidade e metadados. Os que dependem do tipo de cor
+// (bKGD, sBIT, hIST...) são descartados porque o encoder pode mudá-lo.
+var pngKeep = map[string]bool{
+	"iCCP": true, "sRGB": true, "gAMA": true, "cHRM": true, "cICP": true, "pHYs": true,
+	"tEXt": true, "zTXt": true, "iTXt": true, "eXIf": true, "tIME": true,
+}
+
+// optimizePNG recodifica a imagem com compressão máxima
+func optimizePNG(data []byte) ([]byte, bool) {
+	chunks, ok := pngChunks(data)
+	if !ok {
+		return nil, false
+	}
+	var kept [][]byte
+	for _, chunk := range chunks {
+		chunkType := string(chunk[4:8])
+		// APNG: a recodificação perderia os quadros da animação
+		if chunkType == "acTL" {
+			return nil, false
+		}
+		if pngKeep[chunkType] {
+			kept = append(kept, chunk)
+		}
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, false
+	}
+	var encoded bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&encoded, img); err != nil {
+		return nil, false
+	}
+
+	// IHDR é sempre o primeiro chunk: os auxiliares entram logo depois dele
+	out := encoded.Bytes()
+	ihdrEnd := len(pngSignature) + 12 + int(binary.BigEndian.Uint32(out[8:12]))
+	result := make([]byte, 0, len(out)+len(data)/8)
+	result = append(result, out[:ihdrEnd]...)
+	for _, chunk := range kept {
+		result = append(result, chunk...)
+	}
+	return append(result, out[ihdrEnd:]...), true
+}
+
+// pngChunks separa os chunks (com tamanho, tipo e CRC) até o IEND
+func pngChunks(data []byte) ([][]byte, bool) {
+	var chunks [][]byte
+	i := len(pngSignature)
+	for i < len(data) {
+		if i+8 > len(data) {
+			return nil, false
+		}
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:i+4]))
+		if end > len(data) || end < i {
+			return nil, false
+		}
+		chunks = append(chunks, data[i:end])
+		if string(data[i+4:i+8]) == "IEND" {
+			return chunks, true
+		}
+		i = end
+	}
+	return nil, false
+}
@@ -0,0 +1,273 @@
+// Package overrides guarda o mapeamento editável pelo usuário de nomes de
+// pasta para o título canônico e os IDs de provedor da obra. O matcher
+// consulta este mapeamento antes de buscar, para que pastas com nomes
+// estranhos ("OPM remake scans v3") sempre resolvam para a mesma obra sem
+// seleção manual repetida.
+package overrides
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/registry"
+)
+
+// Override associa um nome de pasta à obra canônica
+type Override struct {
+	Folder     string    `json:"folder"`               // nome da pasta como aparece na biblioteca
+	Title      string    `json:"title,omitempty"`      // título usado nas buscas no lugar do nome da pasta
+	AniListID  int       `json:"anilistId,omitempty"`  // obra da AniList usada sem busca
+	Provider   string    `json:"provider,omitempty"`   // fonte de metadados de ProviderID (plugin)
+	ProviderID string    `json:"providerId,omitempty"` // ID da obra em Provider
+	UpdatedAt  time.Time `json:"updatedAt,omitempty"`
+}
+
+// Query retorna o texto a buscar para a pasta: o título canônico, se houver
+func (o Override) Query() string {
+	if o.Title != "" {
+		return o.Title
+	}
+	return o.Folder
+}
+
+// Store mantém os overrides em um arquivo JSON (lista ordenada por pasta).
+// Edições manuais no arquivo são recarregadas na próxima consulta.
+type Store struct {
+	path      string
+	entries   map[string]*Override // nome de pasta normalizado -> override
+	modTime   time.Time            // modificação do arquivo na última leitura
+	loadErr   error                // arquivo inválido: edições pela API são recusadas para não sobrescrevê-lo
+	mutex     sync.RWMutex
+	saveMutex sync.Mutex
+}
+
+// New cria o armazenamento e carrega o arquivo existente, se houver
+func New(path string) (*Store, error) {
+	s := &Store{path: path, entries: make(map[string]*Override)}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.loadErr = s.load()
+	if s.loadErr != nil {
+		if info, err := os.Stat(path); err == nil {
+			s.modTime = info.ModTime()
+		}
+	}
+	return s, s.loadErr
+}
+
+// Key normaliza um nome de pasta para comparação (caixa, pontuação e
+// separadores como "-" e "_" são ignorados)
+func Key(folder string) string {
+	return registry.NormalizeTitle(folder)
+}
+
+// load lê o arquivo (chamador deve ter o lock de escrita)
+func (s *Store) load() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			s.entries = make(map[string]*Override)
+			s.modTime = time.Time{}
+			return nil
+		}
+		return fmt.Errorf("failed to read title overrides: %v", err)
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read title overrides: %v", err)
+	}
+
+	var list []*Override
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("failed to parse title overrides: %v", err)
+	}
+	entries := make(map[string]*Override, len(list))
+	for _, override := range list {
+		if key := Key(override.Folder); key != "" {
+			entries[key] = override
+		}
+	}
+	s.entries = entries
+	s.modTime = info.ModTime()
+	return nil
+}
+
+// refresh recarrega o arquivo se ele mudou desde a última leitura. Um arquivo
+// inválido mantém os overrides anteriores.
+func (s *Store) refresh() {
+	info, err := os.Stat(s.path)
+	s.mutex.RLock()
+	changed := (err == nil && !info.ModTime().Equal(s.modTime)) || (os.IsNotExist(err) && !s.modTime.IsZero())
+	s.mutex.RUnlock()
+	if !changed {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	previous, previousModTime := s.entries, s.modTime
+	s.loadErr = s.load()
+	if s.loadErr != nil {
+		s.entries = previous
+		if info != nil {
+			s.modTime = info.ModTime() // não tentar de novo até o arquivo mudar
+		} else {
+			s.modTime = previousModTime
+		}
+	}
+}
+
+// editable retorna o erro de um arquivo inválido (que uma gravação apagaria)
+func (s *Store) editable() error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.loadErr != nil {
+		return fmt.Errorf("fix %s before editing overrides: %v", s.path, s.loadErr)
+	}
+	return nil
+}
+
+// Reload relê o arquivo, retornando o erro de um arquivo inválido
+func (s *Store) Reload() error {
+	if s == nil {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.loadErr = s.load()
+	return s.loadErr
+}
+
+// Lookup retorna o override de uma pasta
+func (s *Store) Lookup(folder string) (Override, bool) {
+	if s == nil {
+		return Override{}, false
+	}
+	key := Key(folder)
+	if key == "" {
+		return Override{}, false
+	}
+	s.refresh()
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if override, found := s.entries[key]; found {
+		return *override, true
+	}
+	return Override{}, false
+}
+
+// Set cria ou substitui o override de uma pasta
+func (s *Store) Set(override Override) (Override, error) {
+	override.Folder = strings.TrimSpace(override.Folder)
+	override.Title = strings.TrimSpace(override.Title)
+	override.Provider = strings.TrimSpace(override.Provider)
+	override.ProviderID = strings.TrimSpace(override.ProviderID)
+	if Key(override.Folder) == "" {
+		return Override{}, fmt.Errorf("folder is required")
+	}
+	if override.Title == "" && override.AniListID == 0 && override.ProviderID == "" {
+		return Override{}, fmt.Errorf("override for %q needs a title, anilistId or providerId", override.Folder)
+	}
+	if override.ProviderID != "" && override.Provider == "" {
+		return Override{}, fmt.Errorf("providerId requires provider")
+	}
+	if override.AniListID < 0 {
+		return Override{}, fmt.Errorf("invalid anilistId %d", override.AniListID)
+	}
+	s.refresh()
+	if err := s.editable(); err != nil {
+		return Override{}, err
+	}
+
+	s.mutex.Lock()
+	override.UpdatedAt = time.Now()
+	s.entries[Key(override.Folder)] = &override
+	s.mutex.Unlock()
+
+	return override, s.save()
+}
+
+// Remove apaga o override de uma pasta
+func (s *Store) Remove(folder string) error {
+	s.refresh()
+	if err := s.editable(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	key := Key(folder)
+	if _, found := s.entries[key]; !found {
+		s.mutex.Unlock()
+		return fmt.Errorf("no title override for %q", folder)
+	}
+	delete(s.entries, key)
+	s.mutex.Unlock()
+
+	return s.save()
+}
+
+// List retorna os overrides ordenados por pasta
+func (s *Store) List() []Override {
+	if s == nil {
+		return []Override{}
+	}
+	s.refresh()
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	list := make([]Override, 0, len(s.entries))
+	for _, override := range s.entries {
+		list = append(list, *override)
+	}
+	sort.Slice(list, func(i, j int) bool { return Key(list[i].Folder) < Key(list[j].Folder) })
+	return list
+}
+
+// Err retorna o erro de leitura do arquivo (nil se válido); enquanto houver
+// erro valem os overrides da última leitura válida
+func (s *Store) Err() error {
+	if s == nil {
+		return nil
+	}
+	s.refresh()
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.loadErr
+}
+
+// save grava os overrides de forma atômica, indentados para edição manual
+func (s *Store) save() error {
+	s.saveMutex.Lock()
+	defer s.saveMutex.Unlock()
+
+	data, err := json.MarshalIndent(s.List(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode title overrides: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create title overrides directory: %v", err)
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write title overrides: %v", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write title overrides: %v", err)
+	}
+
+	// O arquivo gravado é o estado atual: não recarregar na próxima consulta
+	if info, err := os.Stat(s.path); err == nil {
+		s.mutex.Lock()
+		s.modTime = info.ModTime()
+		s.mutex.Unlock()
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+// Package phash calcula hashes perceptuais (dHash de 64 bits) das páginas para
+// achar duplicatas visuais: scans incluídos duas vezes no mesmo capítulo e
+// páginas repetidas entre versões do mesmo capítulo (ex. "Cap 10" e
+// "Cap 10 (Grupo B)"). Ao contrário de um hash do arquivo, o dHash continua
+// igual depois de recompressão, redimensionamento leve ou troca de formato.
+//
+// WebP não é decodificado pela biblioteca padrão: essas páginas ficam sem hash
+// e nunca são apontadas como duplicatas.
+package phash
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"  // registra o decodificador de GIF
+	_ "image/jpeg" // registra o decodificador de JPEG
+	_ "image/png"  // registra o decodificador de PNG
+	"math/bits"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultThreshold é a distância de Hamming máxima (em 64 bits) para duas
+// páginas serem consideradas a mesma imagem
+const DefaultThreshold = 6
+
+// Escopos de uma duplicata
+const (
+	ScopeChapter = "chapter" // repetida dentro do mesmo capítulo
+	ScopeVersion = "version" // repetida em outra versão do mesmo capítulo
+)
+
+// Hash é o dHash de uma imagem
+type Hash uint64
+
+// String formata o hash em hexadecimal
+func (h Hash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// MarshalText serializa o hash em hexadecimal (uint64 perderia precisão em JavaScript)
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// Distance conta os bits diferentes entre dois hashes (0 = visualmente idênticas)
+func Distance(a, b Hash) int {
+	return bits.OnesCount64(uint64(a ^ b))
+}
+
+// Compute calcula o dHash: a imagem é reduzida a 9x8 tons de cinza e cada bit
+// indica se um pixel é mais claro que o vizinho da direita
+func Compute(img image.Image) Hash {
+	var cells [8][9]uint64
+	var counts [8][9]uint64
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return 0
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row := (y - bounds.Min.Y) * 8 / height
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			col := (x - bounds.Min.X) * 9 / width
+			cells[row][col] += luma(img, x, y)
+			counts[row][col]++
+		}
+	}
+
+	var hash Hash
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			hash <<= 1
+			left := cells[row][col] * max(counts[row][col+1], 1)
+			right := cells[row][col+1] * max(counts[row][col], 1)
+			if left > right {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// luma retorna a luminância de um pixel (0-255), lendo direto o plano Y ou
+// Gray nos tipos que os decodificadores da biblioteca padrão produzem
+func luma(img image.Image, x, y int) uint64 {
+	switch img := img.(type) {
+	case *image.YCbCr:
+		return uint64(img.Y[img.YOffset(x, y)])
+	case *image.Gray:
+		return uint64(img.Pix[img.PixOffset(x, y)])
+	}
+	r, g, b, _ := img.At(x, y).RGBA()
+	return (299*uint64(r) + 587*uint64(g) + 114*uint64(b)) / 1000 >> 8
+}
+
+// Data calcula o hash de uma imagem em memória
+func Data(data []byte) (Hash, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return Compute(img), nil
+}
+
+// File calcula o hash de uma imagem em disco
+func File(path string) (Hash, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open image: %w", err)
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return Compute(img), nil
+}
+
+// Files calcula os hashes de vários arquivos com no máximo workers decodificações
+// simultâneas (0 = número de CPUs). Arquivos ilegíveis ou em formato não
+// suportado ficam fora do resultado.
+func Files(paths []string, workers int) map[string]Hash {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	hashes := make(map[string]Hash, len(paths))
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, workers)
+	for _, path := range paths {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(path string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			hash, err := File(path)
+			if err != nil {
+				return
+			}
+			mutex.Lock()
+			hashes[path] = hash
+			mutex.Unlock()
+		}(path)
+	}
+	wg.Wait()
+	return hashes
+}
+
+// Page é uma página com hash calculado. Chapter identifica a pasta do
+// capítulo; capítulos na mesma pasta pai com o mesmo número são versões.
+type Page struct {
+	Chapter string `json:"chapter"`
+	File    string `json:"file"`
+	Hash    Hash   `json:"hash"`
+}
+
+// Duplicate é uma página visualmente igual a outra que aparece antes dela
+type Duplicate struct {
+	Chapter         string `json:"chapter"`
+	File            string `json:"file"`
+	OriginalChapter string `json:"originalChapter"`
+	Original        string `json:"original"`
+	Distance        int    `json:"distance"`
+	Scope           string `json:"scope"`
+	Exact           bool   `json:"exact"` // distância 0: pode ser pulada sem perda
+}
+
+// Find compara as páginas de cada capítulo entre si e com as versões
+// anteriores do mesmo capítulo. Cada página é relatada no máximo uma vez,
+// contra a primeira ocorrência com distância até threshold; a ordem de pages
+// define qual ocorrência é a original.
+func Find(pages []Page, threshold int) []Duplicate {
+	var duplicates []Duplicate
+	reported := make(map[int]bool)
+
+	// Dentro de cada capítulo
+	byChapter := make(map[string][]int)
+	var chapters []string
+	for i, page := range pages {
+		if _, ok := byChapter[page.Chapter]; !ok {
+			chapters = append(chapters, page.Chapter)
+		}
+		byChapter[page.Chapter] = append(byChapter[page.Chapter], i)
+	}
+	for _, chapter := range chapters {
+		indexes := byChapter[chapter]
+		for j, current := range indexes {
+			for _, earlier := range indexes[:j] {
+				if reported[earlier] {
+					continue
+				}
+				if distance := Distance(pages[earlier].Hash, pages[current].Hash); distance <= threshold {
+					duplicates = append(duplicates, newDuplicate(pages[current], pages[earlier], distance, ScopeChapter))
+					reported[current] = true
+					break
+				}
+			}
+		}
+	}
+
+	// Entre versões: capítulos irmãos com o mesmo número
+	versions := make(map[string][]string)
+	var versionKeys []string
+	for _, chapter := range chapters {
+		number, ok := ChapterNumber(filepath.Base(chapter))
+		if !ok {
+			continue
+		}
+		key := filepath.Dir(chapter) + "\x00" + number
+		if _, ok := versions[key]; !ok {
+			versionKeys = append(versionKeys, key)
+		}
+		versions[key] = append(versions[key], chapter)
+	}
+	for _, key := range versionKeys {
+		group := versions[key]
+		for v, chapter := range group {
+			for _, current := range byChapter[chapter] {
+				if reported[current] {
+					continue
+				}
+				if earlier, distance, ok := closest(pages, group[:v], byChapter, reported, pages[current].Hash, threshold); ok {
+					duplicates = append(duplicates, newDuplicate(pages[current], pages[earlier], distance, ScopeVersion))
+					reported[current] = true
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].Chapter < duplicates[j].Chapter
+	})
+	return duplicates
+}
+
+// closest procura nas versões anteriores a primeira página dentro do limite
+func closest(pages []Page, chapters []string, byChapter map[string][]int, reported map[int]bool, hash Hash, threshold int) (int, int, bool) {
+	for _, chapter := range chapters {
+		for _, index := range byChapter[chapter] {
+			if reported[index] {
+				continue
+			}
+			if distance := Distance(pages[index].Hash, hash); distance <= threshold {
+				return index, distance, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func newDuplicate(page, original Page, distance int, scope string) Duplicate {
+	return Duplicate{
+		Chapter:         page.Chapter,
+		File:            page.File,
+		OriginalChapter: original.Chapter,
+		Original:        original.File,
+		Distance:        distance,
+		Scope:           scope,
+		Exact:           distance == 0,
+	}
+}
+
+var (
+	chapterKeywordPattern = regexp.MustCompile(`(?i)(?:cap[íi]tulo|cap|chapter|ch|ep)[\s._-]*(\d+(?:\.\d+)?)`)
+	chapterNumberPattern  = regexp.MustCompile(`\d+(?:\.\d+)?`)
+)
+
+// ChapterNumber extrai o número do capítulo do nome da pasta ("Cap. 010" → "10"),
+// preferindo o número após "cap"/"chapter"/"ch" ao primeiro número do nome
+func ChapterNumber(name string) (string, bool) {
+	number := ""
+	if match := chapterKeywordPattern.FindStringSubmatch(name); match != nil {
+		number = match[1]
+	} else if match := chapterNumberPattern.FindString(name); match != "" {
+		number = match
+	} else {
+		return "", false
+	}
+	if value, err := strconv.ParseFloat(number, 64); err == nil {
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	}
+	return strings.TrimLeft(number, "0"), true
+}
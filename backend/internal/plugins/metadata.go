@@ -0,0 +1,60 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound é retornado quando o plugin de metadados não conhece o ID
+var ErrNotFound = errors.New("metadata not found")
+
+// searchParams são os parâmetros do método search
+type searchParams struct {
+	Query string `json:"query"`
+}
+
+// detailsParams são os parâmetros do método details
+type detailsParams struct {
+	ID string `json:"id"`
+}
+
+// MetadataProvider expõe um plugin "metadata" como fonte de busca e detalhes
+// de obras. Os resultados usam os campos do JSON da obra (title, description,
+// artist, author, cover, status) mais "id", repassado a details.
+type MetadataProvider struct {
+	plugin *Plugin
+}
+
+// NewMetadataProvider adapta o plugin para busca de metadados
+func NewMetadataProvider(plugin *Plugin) *MetadataProvider {
+	return &MetadataProvider{plugin: plugin}
+}
+
+// Name retorna o nome da fonte (nome do plugin)
+func (m *MetadataProvider) Name() string {
+	return m.plugin.info.Name
+}
+
+// Search busca obras pelo título
+func (m *MetadataProvider) Search(ctx context.Context, query string) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	if err := m.plugin.Call(ctx, "search", searchParams{Query: query}, &results); err != nil {
+		return nil, err
+	}
+	if results == nil {
+		results = []map[string]interface{}{}
+	}
+	return results, nil
+}
+
+// Details retorna os metadados completos de uma obra pelo ID da fonte
+func (m *MetadataProvider) Details(ctx context.Context, id string) (map[string]interface{}, error) {
+	var details map[string]interface{}
+	if err := m.plugin.Call(ctx, "details", detailsParams{ID: id}, &details); err != nil {
+		return nil, err
+	}
+	if details == nil {
+		return nil, ErrNotFound
+	}
+	return details, nil
+}
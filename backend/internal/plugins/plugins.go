@@ -0,0 +1,256 @@
+// Package plugins carrega hosts de upload e fontes de metadados externos.
+//
+// Cada plugin é um executável que conversa com o servidor por stdin/stdout,
+// uma mensagem JSON por linha:
+//
+//	→ {"id":1,"method":"describe"}
+//	← {"id":1,"result":{"name":"imgur","version":"1.0.0","provides":["uploader"]}}
+//	→ {"id":2,"method":"upload","params":{"filePath":"/lib/x/1/01.jpg","manga":"x","chapter":"1","fileName":"01.jpg"}}
+//	← {"id":2,"result":{"url":"https://i.imgur.com/abc.jpg"}}
+//
+// Respostas com "error" preenchido são falhas da chamada. O stderr do plugin
+// vai para o log do servidor e o plugin deve sair quando o stdin fechar.
+// Métodos por capacidade: "uploader" → upload; "metadata" → search e details.
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProtocolVersion é exportado ao plugin na variável GO_UPLOAD_PLUGIN_PROTOCOL
+const ProtocolVersion = "1"
+
+// Capacidades anunciadas em describe
+const (
+	CapabilityUploader = "uploader"
+	CapabilityMetadata = "metadata"
+)
+
+// describeTimeout limita o handshake de cada plugin na carga
+const describeTimeout = 10 * time.Second
+
+// RateLimit é o limite de uploads anunciado por um plugin de host
+type RateLimit struct {
+	Tokens     int   `json:"tokens"`
+	IntervalMs int64 `json:"intervalMs"`
+}
+
+// Description é a resposta de describe
+type Description struct {
+	Name        string     `json:"name"`
+	Version     string     `json:"version,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Provides    []string   `json:"provides"`
+	RateLimit   *RateLimit `json:"rateLimit,omitempty"` // padrão: 10 por segundo
+}
+
+// Info descreve um plugin encontrado no diretório, carregado ou não
+type Info struct {
+	Description
+	Path  string `json:"path"`
+	Error string `json:"error,omitempty"` // falha na carga; o plugin fica desativado
+}
+
+// manifest permite registrar plugins que precisam de interpretador, ex.
+// {"command": "python3", "args": ["imgur.py"]} em imgur.json
+type manifest struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// Plugin é um plugin carregado
+type Plugin struct {
+	info    Info
+	process *process
+}
+
+// Info retorna a descrição do plugin
+func (p *Plugin) Info() Info {
+	return p.info
+}
+
+// Provides informa se o plugin anunciou a capacidade
+func (p *Plugin) Provides(capability string) bool {
+	for _, provided := range p.info.Provides {
+		if provided == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Call executa um método do plugin
+func (p *Plugin) Call(ctx context.Context, method string, params, result interface{}) error {
+	if err := p.process.call(ctx, method, params, result); err != nil {
+		return fmt.Errorf("plugin %s: %w", p.info.Name, err)
+	}
+	return nil
+}
+
+// Manager guarda os plugins descobertos no diretório de plugins
+type Manager struct {
+	dir string
+
+	mu      sync.RWMutex
+	loaded  map[string]*Plugin
+	entries []Info
+}
+
+// Load descobre e inicia os plugins de dir: executáveis e manifestos *.json.
+// Um diretório inexistente resulta num Manager vazio; falhas de plugins
+// individuais ficam em Info.Error e não impedem os demais.
+func Load(dir string) (*Manager, error) {
+	manager := &Manager{dir: dir, loaded: make(map[string]*Plugin)}
+	if dir == "" {
+		return manager, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manager, nil
+		}
+		return nil, fmt.Errorf("failed to read plugin directory %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		command, args, ok, err := pluginCommand(path)
+		if !ok {
+			continue
+		}
+		info := Info{Path: path}
+		if err == nil {
+			var plugin *Plugin
+			if plugin, err = manager.start(path, command, args); err == nil {
+				info = plugin.info
+			}
+		}
+		if err != nil {
+			info.Name = strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+			info.Error = err.Error()
+		}
+		manager.entries = append(manager.entries, info)
+	}
+	sort.Slice(manager.entries, func(a, b int) bool { return manager.entries[a].Name < manager.entries[b].Name })
+	return manager, nil
+}
+
+// pluginCommand decide como executar o arquivo; ok = false se não é um plugin
+func pluginCommand(path string) (command string, args []string, ok bool, err error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, true, fmt.Errorf("failed to read manifest: %w", err)
+		}
+		var m manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return "", nil, true, fmt.Errorf("invalid manifest: %w", err)
+		}
+		if m.Command == "" {
+			return "", nil, true, fmt.Errorf("manifest has no command")
+		}
+		return m.Command, m.Args, true, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, false, nil
+	}
+	if runtime.GOOS == "windows" {
+		if !strings.EqualFold(filepath.Ext(path), ".exe") {
+			return "", nil, false, nil
+		}
+	} else if info.Mode().Perm()&0o111 == 0 {
+		return "", nil, false, nil
+	}
+	return path, nil, true, nil
+}
+
+// start inicia o plugin e faz o handshake describe
+func (m *Manager) start(path, command string, args []string) (*Plugin, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	proc := newProcess(name, command, args, filepath.Dir(path))
+
+	ctx, cancel := context.WithTimeout(context.Background(), describeTimeout)
+	defer cancel()
+	var description Description
+	if err := proc.call(ctx, "describe", nil, &description); err != nil {
+		proc.close()
+		return nil, fmt.Errorf("describe failed: %w", err)
+	}
+	if description.Name == "" {
+		proc.close()
+		return nil, fmt.Errorf("describe returned no name")
+	}
+	if len(description.Provides) == 0 {
+		proc.close()
+		return nil, fmt.Errorf("plugin %s provides nothing", description.Name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.loaded[description.Name]; exists {
+		proc.close()
+		return nil, fmt.Errorf("duplicate plugin name %q", description.Name)
+	}
+	plugin := &Plugin{info: Info{Description: description, Path: path}, process: proc}
+	m.loaded[description.Name] = plugin
+	return plugin, nil
+}
+
+// Dir retorna o diretório de onde os plugins foram carregados
+func (m *Manager) Dir() string {
+	return m.dir
+}
+
+// List retorna todos os plugins encontrados, incluindo os que falharam ao carregar
+func (m *Manager) List() []Info {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Info(nil), m.entries...)
+}
+
+// Get retorna um plugin carregado pelo nome
+func (m *Manager) Get(name string) (*Plugin, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	plugin, exists := m.loaded[name]
+	return plugin, exists
+}
+
+// WithCapability retorna os plugins carregados que anunciaram a capacidade, por nome
+func (m *Manager) WithCapability(capability string) []*Plugin {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var plugins []*Plugin
+	for _, plugin := range m.loaded {
+		if plugin.Provides(capability) {
+			plugins = append(plugins, plugin)
+		}
+	}
+	sort.Slice(plugins, func(a, b int) bool { return plugins[a].info.Name < plugins[b].info.Name })
+	return plugins
+}
+
+// Close encerra todos os plugins
+func (m *Manager) Close() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, plugin := range m.loaded {
+		plugin.process.close()
+	}
+}
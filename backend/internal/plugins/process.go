@@ -0,0 +1,204 @@
+package plugins
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// maxMessageSize limita uma linha do protocolo (respostas de metadados podem ser grandes)
+const maxMessageSize = 16 << 20
+
+// closeTimeout é quanto um plugin tem para sair depois que o stdin é fechado
+const closeTimeout = 5 * time.Second
+
+// request é uma chamada ao plugin, uma por linha no stdin
+type request struct {
+	ID     int64       `json:"id"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params,omitempty"`
+}
+
+// response é a resposta do plugin, uma por linha no stdout
+type response struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// process mantém o subprocesso de um plugin e multiplexa as chamadas pelo id.
+// Se o plugin encerrar, a próxima chamada o inicia de novo.
+type process struct {
+	name    string
+	command string
+	args    []string
+	dir     string
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	pending map[int64]chan response
+	nextID  int64
+	exited  chan struct{}
+	closed  bool
+}
+
+func newProcess(name, command string, args []string, dir string) *process {
+	return &process{name: name, command: command, args: args, dir: dir}
+}
+
+// start inicia o subprocesso (chamado com mu)
+func (p *process) start() error {
+	cmd := exec.Command(p.command, p.args...)
+	cmd.Dir = p.dir
+	cmd.Env = append(os.Environ(), "GO_UPLOAD_PLUGIN_PROTOCOL="+ProtocolVersion)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open plugin stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open plugin stdout: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open plugin stderr: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start plugin %s: %w", p.command, err)
+	}
+
+	p.cmd = cmd
+	p.stdin = stdin
+	p.pending = make(map[int64]chan response)
+	p.exited = make(chan struct{})
+	stderrDone := make(chan struct{})
+	go p.logStderr(stderr, stderrDone)
+	go p.readResponses(stdout, stderrDone, p.exited)
+	return nil
+}
+
+// call envia method e decodifica o resultado em result (pode ser nil)
+func (p *process) call(ctx context.Context, method string, params, result interface{}) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return errors.New("plugin closed")
+	}
+	if p.cmd == nil {
+		if err := p.start(); err != nil {
+			p.mu.Unlock()
+			return err
+		}
+	}
+	p.nextID++
+	id := p.nextID
+	reply := make(chan response, 1)
+	p.pending[id] = reply
+	exited := p.exited
+
+	line, err := json.Marshal(request{ID: id, Method: method, Params: params})
+	if err == nil {
+		_, err = p.stdin.Write(append(line, '\n'))
+	}
+	p.mu.Unlock()
+	if err != nil {
+		p.forget(id)
+		return fmt.Errorf("failed to send %s to plugin: %w", method, err)
+	}
+
+	select {
+	case resp := <-reply:
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+		if result != nil {
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("invalid %s result from plugin: %w", method, err)
+			}
+		}
+		return nil
+	case <-exited:
+		return fmt.Errorf("plugin exited during %s", method)
+	case <-ctx.Done():
+		p.forget(id)
+		return ctx.Err()
+	}
+}
+
+func (p *process) forget(id int64) {
+	p.mu.Lock()
+	delete(p.pending, id)
+	p.mu.Unlock()
+}
+
+// readResponses entrega cada resposta à chamada de mesmo id até o stdout fechar
+func (p *process) readResponses(stdout io.Reader, stderrDone, exited chan struct{}) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		var resp response
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			log.Printf("⚠️ Plugin %s: ignoring invalid output line: %v", p.name, err)
+			continue
+		}
+		p.mu.Lock()
+		reply, exists := p.pending[resp.ID]
+		delete(p.pending, resp.ID)
+		p.mu.Unlock()
+		if exists {
+			reply <- resp
+		}
+	}
+
+	p.mu.Lock()
+	cmd := p.cmd
+	if p.exited == exited {
+		p.cmd = nil
+		p.pending = nil
+	}
+	p.mu.Unlock()
+	close(exited)
+	<-stderrDone
+	if cmd != nil {
+		if err := cmd.Wait(); err != nil {
+			log.Printf("⚠️ Plugin %s exited: %v", p.name, err)
+		}
+	}
+}
+
+// logStderr repassa o stderr do plugin para o log do servidor
+func (p *process) logStderr(stderr io.Reader, done chan struct{}) {
+	defer close(done)
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		log.Printf("🔌 [%s] %s", p.name, scanner.Text())
+	}
+}
+
+// close encerra o subprocesso fechando o stdin; o plugin deve sair ao ler EOF,
+// senão é morto após closeTimeout
+func (p *process) close() {
+	p.mu.Lock()
+	p.closed = true
+	cmd, stdin, exited := p.cmd, p.stdin, p.exited
+	p.mu.Unlock()
+	if cmd == nil {
+		return
+	}
+	stdin.Close()
+	select {
+	case <-exited:
+	case <-time.After(closeTimeout):
+		cmd.Process.Kill()
+		<-exited
+	}
+}
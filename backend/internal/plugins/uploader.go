@@ -0,0 +1,79 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"go-upload/backend/internal/upload"
+)
+
+// uploadTimeout limita cada chamada upload, além do cancelamento do lote
+const uploadTimeout = 10 * time.Minute
+
+// uploadParams são os parâmetros do método upload
+type uploadParams struct {
+	FilePath string `json:"filePath"`
+	Manga    string `json:"manga,omitempty"`
+	Chapter  string `json:"chapter,omitempty"`
+	FileName string `json:"fileName"`
+}
+
+// uploadResult é o resultado do método upload
+type uploadResult struct {
+	URL string `json:"url"`
+}
+
+// Uploader expõe um plugin "uploader" como host do BatchUploader
+type Uploader struct {
+	plugin *Plugin
+}
+
+// NewUploader adapta o plugin para upload.UploaderInterface e upload.DestinationUploader
+func NewUploader(plugin *Plugin) *Uploader {
+	return &Uploader{plugin: plugin}
+}
+
+// Upload envia o arquivo sem informação de obra/capítulo
+func (u *Uploader) Upload(filePath string) (string, error) {
+	return u.UploadTo(filePath, upload.UploadDestination{FileName: filepath.Base(filePath)})
+}
+
+// UploadTo envia o arquivo informando obra e capítulo, para hosts que organizam pastas
+func (u *Uploader) UploadTo(filePath string, dest upload.UploadDestination) (string, error) {
+	ctx, cancel := context.WithTimeout(dest.Context(), uploadTimeout)
+	defer cancel()
+
+	if dest.FileName == "" {
+		dest.FileName = filepath.Base(filePath)
+	}
+	var result uploadResult
+	err := u.plugin.Call(ctx, "upload", uploadParams{
+		FilePath: filePath,
+		Manga:    dest.Manga,
+		Chapter:  dest.Chapter,
+		FileName: dest.FileName,
+	}, &result)
+	if err != nil {
+		return "", err
+	}
+	if result.URL == "" {
+		return "", fmt.Errorf("plugin %s returned no URL for %s", u.plugin.info.Name, dest.FileName)
+	}
+	return result.URL, nil
+}
+
+// GetName retorna o nome do host (nome do plugin)
+func (u *Uploader) GetName() string {
+	return u.plugin.info.Name
+}
+
+// GetRateLimit usa o limite anunciado pelo plugin (padrão 10 por segundo)
+func (u *Uploader) GetRateLimit() (int, time.Duration) {
+	limit := u.plugin.info.RateLimit
+	if limit == nil || limit.Tokens <= 0 || limit.IntervalMs <= 0 {
+		return 10, time.Second
+	}
+	return limit.Tokens, time.Duration(limit.IntervalMs) * time.Millisecond
+}
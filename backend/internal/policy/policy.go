@@ -0,0 +1,328 @@
+// Package policy aplica as regras de conteúdo antes do upload: formatos
+// aceitos, dimensões mínimas/máximas e tamanho de arquivo. Arquivos fora da
+// política são recusados ou apenas sinalizados (action "flag"), e o resultado
+// vira um relatório de violações devolvido ao cliente.
+//
+// O pacote também decide se uma obra é NSFW a partir dos gêneros e tags
+// informados pelo provedor de metadados.
+package policy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"  // registra o decodificador de GIF
+	_ "image/jpeg" // registra o decodificador de JPEG
+	_ "image/png"  // registra o decodificador de PNG
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Action define o que acontece com um arquivo fora da política
+type Action string
+
+const (
+	ActionReject Action = "reject" // o arquivo não é enviado (padrão)
+	ActionFlag   Action = "flag"   // o arquivo é enviado e a violação só é relatada
+)
+
+// Regras que podem ser violadas
+const (
+	RuleFormat     = "format"
+	RuleDimensions = "dimensions"
+	RuleSize       = "size"
+	RuleUnreadable = "unreadable"
+)
+
+// defaultNSFWGenres são os gêneros que marcam uma obra como NSFW sem configuração
+var defaultNSFWGenres = []string{"Hentai", "Ecchi"}
+
+// Config é a seção "policy" da configuração
+type Config struct {
+	Formats     []string `json:"formats,omitempty"`     // ex. ["jpeg", "png", "webp"]; vazio = qualquer formato
+	MinWidth    int      `json:"minWidth,omitempty"`    // pixels; 0 = sem limite
+	MinHeight   int      `json:"minHeight,omitempty"`   // pixels; 0 = sem limite
+	MaxWidth    int      `json:"maxWidth,omitempty"`    // pixels; 0 = sem limite
+	MaxHeight   int      `json:"maxHeight,omitempty"`   // pixels; 0 = sem limite
+	MaxFileSize int64    `json:"maxFileSize,omitempty"` // bytes; 0 = sem limite
+	Action      Action   `json:"action,omitempty"`      // "reject" (padrão) ou "flag"
+	NSFWGenres  []string `json:"nsfwGenres,omitempty"`  // padrão: Hentai, Ecchi
+	NSFWTags    []string `json:"nsfwTags,omitempty"`    // tags do provedor que marcam a obra como NSFW
+}
+
+// Violation é uma regra violada por um arquivo
+type Violation struct {
+	File   string `json:"file"`
+	Rule   string `json:"rule"`
+	Detail string `json:"detail"`
+}
+
+// Report resume a checagem de um conjunto de arquivos
+type Report struct {
+	Action     Action      `json:"action"`
+	Checked    int         `json:"checked"`
+	Rejected   int         `json:"rejected"`
+	Flagged    int         `json:"flagged"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// HasViolations informa se algum arquivo violou a política
+func (r *Report) HasViolations() bool {
+	return len(r.Violations) > 0
+}
+
+// Summary junta as violações de um arquivo numa linha para logs e erros
+func Summary(violations []Violation) string {
+	parts := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		parts = append(parts, violation.Rule+": "+violation.Detail)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Engine aplica a política configurada
+type Engine struct {
+	config     Config
+	formats    map[string]bool
+	nsfwGenres map[string]bool
+	nsfwTags   map[string]bool
+}
+
+// New valida a configuração; sem regras de arquivo, Enabled retorna false
+// e apenas a classificação NSFW fica ativa
+func New(config Config) (*Engine, error) {
+	if config.Action == "" {
+		config.Action = ActionReject
+	}
+	if config.Action != ActionReject && config.Action != ActionFlag {
+		return nil, fmt.Errorf("invalid policy action %q (expected reject or flag)", config.Action)
+	}
+	if config.MinWidth < 0 || config.MinHeight < 0 || config.MaxWidth < 0 || config.MaxHeight < 0 || config.MaxFileSize < 0 {
+		return nil, fmt.Errorf("policy limits must not be negative")
+	}
+	if config.MaxWidth > 0 && config.MinWidth > config.MaxWidth {
+		return nil, fmt.Errorf("policy minWidth %d exceeds maxWidth %d", config.MinWidth, config.MaxWidth)
+	}
+	if config.MaxHeight > 0 && config.MinHeight > config.MaxHeight {
+		return nil, fmt.Errorf("policy minHeight %d exceeds maxHeight %d", config.MinHeight, config.MaxHeight)
+	}
+
+	engine := &Engine{
+		config:     config,
+		formats:    make(map[string]bool),
+		nsfwGenres: make(map[string]bool),
+		nsfwTags:   make(map[string]bool),
+	}
+	for _, format := range config.Formats {
+		engine.formats[normalizeFormat(format)] = true
+	}
+	genres := config.NSFWGenres
+	if genres == nil {
+		genres = defaultNSFWGenres
+	}
+	for _, genre := range genres {
+		engine.nsfwGenres[strings.ToLower(genre)] = true
+	}
+	for _, tag := range config.NSFWTags {
+		engine.nsfwTags[strings.ToLower(tag)] = true
+	}
+	return engine, nil
+}
+
+// Enabled informa se há alguma regra de arquivo configurada
+func (e *Engine) Enabled() bool {
+	if e == nil {
+		return false
+	}
+	c := e.config
+	return len(e.formats) > 0 || c.MinWidth > 0 || c.MinHeight > 0 || c.MaxWidth > 0 || c.MaxHeight > 0 || c.MaxFileSize > 0
+}
+
+// Action retorna a ação aplicada aos arquivos fora da política
+func (e *Engine) Action() Action {
+	if e == nil {
+		return ActionReject
+	}
+	return e.config.Action
+}
+
+// Rejects informa se as violações impedem o envio do arquivo
+func (e *Engine) Rejects(violations []Violation) bool {
+	return len(violations) > 0 && e.Action() == ActionReject
+}
+
+// NewReport cria um relatório vazio com a ação da política
+func (e *Engine) NewReport() *Report {
+	return &Report{Action: e.Action()}
+}
+
+// Record adiciona ao relatório o resultado da checagem de um arquivo
+func (e *Engine) Record(report *Report, violations []Violation) {
+	report.Checked++
+	if len(violations) == 0 {
+		return
+	}
+	if e.Rejects(violations) {
+		report.Rejected++
+	} else {
+		report.Flagged++
+	}
+	report.Violations = append(report.Violations, violations...)
+}
+
+// CheckFile verifica um arquivo em disco; name identifica o arquivo no relatório
+func (e *Engine) CheckFile(path, name string) []Violation {
+	if !e.Enabled() {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return []Violation{{File: name, Rule: RuleUnreadable, Detail: err.Error()}}
+	}
+	violations := e.checkSize(name, info.Size())
+	if !e.needsImage() {
+		return violations
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return append(violations, Violation{File: name, Rule: RuleUnreadable, Detail: err.Error()})
+	}
+	defer file.Close()
+	return append(violations, e.checkImage(name, bufio.NewReader(file))...)
+}
+
+// CheckContent verifica um arquivo recebido em memória (uploads em base64)
+func (e *Engine) CheckContent(name string, data []byte) []Violation {
+	if !e.Enabled() {
+		return nil
+	}
+	violations := e.checkSize(name, int64(len(data)))
+	if !e.needsImage() {
+		return violations
+	}
+	return append(violations, e.checkImage(name, bytes.NewReader(data))...)
+}
+
+// NSFW informa se a obra deve ser marcada como NSFW pelos dados do provedor
+func (e *Engine) NSFW(genres, tags []string, isAdult bool) bool {
+	if isAdult {
+		return true
+	}
+	if e == nil {
+		return false
+	}
+	for _, genre := range genres {
+		if e.nsfwGenres[strings.ToLower(genre)] {
+			return true
+		}
+	}
+	for _, tag := range tags {
+		if e.nsfwTags[strings.ToLower(tag)] {
+			return true
+		}
+	}
+	return false
+}
+
+// needsImage informa se alguma regra exige ler o cabeçalho da imagem
+func (e *Engine) needsImage() bool {
+	c := e.config
+	return len(e.formats) > 0 || c.MinWidth > 0 || c.MinHeight > 0 || c.MaxWidth > 0 || c.MaxHeight > 0
+}
+
+func (e *Engine) checkSize(name string, size int64) []Violation {
+	if e.config.MaxFileSize > 0 && size > e.config.MaxFileSize {
+		return []Violation{{
+			File:   name,
+			Rule:   RuleSize,
+			Detail: fmt.Sprintf("%d bytes exceeds the %d byte limit", size, e.config.MaxFileSize),
+		}}
+	}
+	return nil
+}
+
+// checkImage lê apenas o cabeçalho da imagem para obter formato e dimensões
+func (e *Engine) checkImage(name string, r io.Reader) []Violation {
+	format, width, height, err := decodeConfig(r)
+	if err != nil {
+		if len(e.formats) > 0 {
+			return []Violation{{File: name, Rule: RuleFormat, Detail: fmt.Sprintf("unrecognized image format (%s)", strings.TrimPrefix(filepath.Ext(name), "."))}}
+		}
+		return []Violation{{File: name, Rule: RuleUnreadable, Detail: err.Error()}}
+	}
+
+	var violations []Violation
+	if len(e.formats) > 0 && !e.formats[format] {
+		violations = append(violations, Violation{File: name, Rule: RuleFormat, Detail: fmt.Sprintf("format %s is not allowed", format)})
+	}
+	c := e.config
+	if (c.MinWidth > 0 && width < c.MinWidth) || (c.MinHeight > 0 && height < c.MinHeight) ||
+		(c.MaxWidth > 0 && width > c.MaxWidth) || (c.MaxHeight > 0 && height > c.MaxHeight) {
+		violations = append(violations, Violation{File: name, Rule: RuleDimensions, Detail: fmt.Sprintf("%dx%d is outside the allowed %s", width, height, c.dimensionRange())})
+	}
+	return violations
+}
+
+// dimensionRange descreve os limites de dimensão para as mensagens
+func (c Config) dimensionRange() string {
+	limit := func(value int) string {
+		if value == 0 {
+			return "any"
+		}
+		return fmt.Sprint(value)
+	}
+	return fmt.Sprintf("%sx%s to %sx%s", limit(c.MinWidth), limit(c.MinHeight), limit(c.MaxWidth), limit(c.MaxHeight))
+}
+
+// decodeConfig retorna formato e dimensões; WebP é lido à parte porque a
+// biblioteca padrão não o decodifica
+func decodeConfig(r io.Reader) (string, int, int, error) {
+	header := make([]byte, 30)
+	n, _ := io.ReadFull(r, header)
+	header = header[:n]
+	if width, height, ok := webpSize(header); ok {
+		return "webp", width, height, nil
+	}
+	config, format, err := image.DecodeConfig(io.MultiReader(bytes.NewReader(header), r))
+	if err != nil {
+		return "", 0, 0, err
+	}
+	return format, config.Width, config.Height, nil
+}
+
+// webpSize extrai as dimensões dos cabeçalhos VP8, VP8L e VP8X
+func webpSize(header []byte) (int, int, bool) {
+	if len(header) < 30 || string(header[0:4]) != "RIFF" || string(header[8:12]) != "WEBP" {
+		return 0, 0, false
+	}
+	switch string(header[12:16]) {
+	case "VP8 ":
+		width := int(binary.LittleEndian.Uint16(header[26:28]) & 0x3fff)
+		height := int(binary.LittleEndian.Uint16(header[28:30]) & 0x3fff)
+		return width, height, true
+	case "VP8L":
+		b := header[21:25]
+		width := 1 + (int(b[0]) | int(b[1]&0x3f)<<8)
+		height := 1 + (int(b[1]>>6) | int(b[2])<<2 | int(b[3]&0x0f)<<10)
+		return width, height, true
+	case "VP8X":
+		width := 1 + (int(header[24]) | int(header[25])<<8 | int(header[26])<<16)
+		height := 1 + (int(header[27]) | int(header[28])<<8 | int(header[29])<<16)
+		return width, height, true
+	}
+	return 0, 0, false
+}
+
+// normalizeFormat aceita extensões comuns como nomes de formato ("jpg" → "jpeg")
+func normalizeFormat(format string) string {
+	format = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(format), "."))
+	if format == "jpg" {
+		return "jpeg"
+	}
+	return format
+}
@@ -0,0 +1,340 @@
+// Package quality calcula estatísticas de qualidade dos capítulos antes do
+// envio, para que problemas de QC apareçam antes da publicação: páginas muito
+// pequenas, páginas em branco (entropia baixa dos tons de cinza) e larguras
+// fora do padrão do capítulo (páginas de outra fonte ou redimensionadas).
+//
+// WebP não é decodificado pela biblioteca padrão: essas páginas entram na
+// contagem mas ficam fora das estatísticas.
+package quality
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"  // registra o decodificador de GIF
+	_ "image/jpeg" // registra o decodificador de JPEG
+	_ "image/png"  // registra o decodificador de PNG
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Problemas que marcam um capítulo no relatório
+const (
+	IssueSmallPages         = "small_pages"
+	IssueBlankPages         = "blank_pages"
+	IssueResolutionVariance = "resolution_variance"
+	IssueUnreadable         = "unreadable"
+)
+
+// Motivos da remoção automática de uma página
+const (
+	RemovedBlank     = "blank"     // página em branco
+	RemovedDuplicate = "duplicate" // idêntica à página anterior
+)
+
+// Padrões da configuração
+const (
+	DefaultMinWidth          = 500
+	DefaultMinHeight         = 500
+	DefaultBlankEntropy      = 1.0
+	DefaultMaxWidthDeviation = 0.25
+)
+
+// maxSamples limita os pixels lidos por página no cálculo da entropia
+const maxSamples = 512 * 512
+
+// Config é a seção "quality" da configuração
+type Config struct {
+	Disabled          bool    `json:"disabled,omitempty"`          // sem relatório na estimativa e nas coleções
+	MinWidth          int     `json:"minWidth,omitempty"`          // pixels; páginas mais estreitas são "pequenas" (padrão 500)
+	MinHeight         int     `json:"minHeight,omitempty"`         // pixels; páginas mais baixas são "pequenas" (padrão 500)
+	BlankEntropy      float64 `json:"blankEntropy,omitempty"`      // bits; páginas abaixo disso estão "em branco" (padrão 1.0)
+	MaxWidthDeviation float64 `json:"maxWidthDeviation,omitempty"` // fração da largura mediana tolerada (padrão 0.25)
+}
+
+// Normalize aplica os padrões
+func (c Config) Normalize() Config {
+	if c.MinWidth <= 0 {
+		c.MinWidth = DefaultMinWidth
+	}
+	if c.MinHeight <= 0 {
+		c.MinHeight = DefaultMinHeight
+	}
+	if c.BlankEntropy <= 0 {
+		c.BlankEntropy = DefaultBlankEntropy
+	}
+	if c.MaxWidthDeviation <= 0 {
+		c.MaxWidthDeviation = DefaultMaxWidthDeviation
+	}
+	return c
+}
+
+// Page são as estatísticas de uma página
+type Page struct {
+	File    string  `json:"file"`
+	Width   int     `json:"width"`
+	Height  int     `json:"height"`
+	Entropy float64 `json:"entropy"` // bits (0 = uma cor só, 8 = máximo)
+}
+
+// Removal é uma página que a remoção automática deixou de fora do envio
+type Removal struct {
+	File     string `json:"file"`
+	Reason   string `json:"reason"`
+	Original string `json:"original,omitempty"` // página anterior, nas duplicatas
+}
+
+// ChapterReport resume a qualidade de um capítulo
+type ChapterReport struct {
+	Chapter       string    `json:"chapter"`
+	Pages         int       `json:"pages"`
+	Analyzed      int       `json:"analyzed"` // páginas decodificadas
+	MinWidth      int       `json:"minWidth"`
+	MaxWidth      int       `json:"maxWidth"`
+	MedianWidth   int       `json:"medianWidth"`
+	WidthStdDev   float64   `json:"widthStdDev"`             // desvio padrão da largura (px)
+	WidthOutliers []string  `json:"widthOutliers,omitempty"` // largura fora da tolerância em torno da mediana
+	SmallPages    []string  `json:"smallPages,omitempty"`
+	BlankPages    []string  `json:"blankPages,omitempty"`
+	Unreadable    []string  `json:"unreadable,omitempty"`
+	Issues        []string  `json:"issues,omitempty"`
+	Removed       []Removal `json:"removed,omitempty"` // páginas não enviadas (remoção automática)
+}
+
+// Analyzer calcula os relatórios de capítulo com uma configuração
+type Analyzer struct {
+	config  Config
+	workers int
+}
+
+// NewAnalyzer cria o analisador; workers limita as decodificações simultâneas (0 = CPUs)
+func NewAnalyzer(config Config, workers int) *Analyzer {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &Analyzer{config: config.Normalize(), workers: workers}
+}
+
+// Chapter analisa as páginas de um capítulo
+func (a *Analyzer) Chapter(chapter string, paths []string) ChapterReport {
+	report := ChapterReport{Chapter: chapter, Pages: len(paths)}
+
+	pages := make([]*Page, len(paths))
+	failed := make([]bool, len(paths))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, a.workers)
+	for i, path := range paths {
+		if strings.EqualFold(filepath.Ext(path), ".webp") {
+			continue
+		}
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, path string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			page, err := AnalyzeFile(path)
+			if err != nil {
+				failed[i] = true
+				return
+			}
+			pages[i] = page
+		}(i, path)
+	}
+	wg.Wait()
+
+	var widths []int
+	for i, page := range pages {
+		name := filepath.Base(paths[i])
+		if failed[i] {
+			report.Unreadable = append(report.Unreadable, name)
+			continue
+		}
+		if page == nil {
+			continue
+		}
+		report.Analyzed++
+		widths = append(widths, page.Width)
+		if page.Width < a.config.MinWidth || page.Height < a.config.MinHeight {
+			report.SmallPages = append(report.SmallPages, name)
+		}
+		if page.Entropy < a.config.BlankEntropy {
+			report.BlankPages = append(report.BlankPages, name)
+		}
+	}
+
+	if len(widths) > 0 {
+		sorted := append([]int{}, widths...)
+		sort.Ints(sorted)
+		report.MinWidth = sorted[0]
+		report.MaxWidth = sorted[len(sorted)-1]
+		report.MedianWidth = sorted[len(sorted)/2]
+
+		var sum, squares float64
+		for _, width := range widths {
+			sum += float64(width)
+		}
+		mean := sum / float64(len(widths))
+		for _, width := range widths {
+			squares += (float64(width) - mean) * (float64(width) - mean)
+		}
+		report.WidthStdDev = math.Round(math.Sqrt(squares/float64(len(widths)))*10) / 10
+
+		tolerance := float64(report.MedianWidth) * a.config.MaxWidthDeviation
+		for i, page := range pages {
+			if page != nil && math.Abs(float64(page.Width-report.MedianWidth)) > tolerance {
+				report.WidthOutliers = append(report.WidthOutliers, filepath.Base(paths[i]))
+			}
+		}
+	}
+
+	if len(report.SmallPages) > 0 {
+		report.Issues = append(report.Issues, IssueSmallPages)
+	}
+	if len(report.BlankPages) > 0 {
+		report.Issues = append(report.Issues, IssueBlankPages)
+	}
+	if len(report.WidthOutliers) > 0 {
+		report.Issues = append(report.Issues, IssueResolutionVariance)
+	}
+	if len(report.Unreadable) > 0 {
+		report.Issues = append(report.Issues, IssueUnreadable)
+	}
+	return report
+}
+
+// AnalyzeFile decodifica uma página e calcula dimensões e entropia
+func AnalyzeFile(path string) (*Page, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	bounds := img.Bounds()
+	return &Page{
+		File:    filepath.Base(path),
+		Width:   bounds.Dx(),
+		Height:  bounds.Dy(),
+		Entropy: Entropy(img),
+	}, nil
+}
+
+// Entropy é a entropia de Shannon (bits) do histograma de tons de cinza. Páginas
+// grandes são amostradas em grade para ler no máximo maxSamples pixels.
+func Entropy(img image.Image) float64 {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return 0
+	}
+	step := 1
+	for (width/step)*(height/step) > maxSamples {
+		step++
+	}
+
+	var histogram [256]int
+	total := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			histogram[luma(img, x, y)]++
+			total++
+		}
+	}
+
+	entropy := 0.0
+	for _, count := range histogram {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return math.Round(entropy*100) / 100
+}
+
+// luma retorna a luminância de um pixel (0-255)
+func luma(img image.Image, x, y int) uint8 {
+	switch img := img.(type) {
+	case *image.YCbCr:
+		return img.Y[img.YOffset(x, y)]
+	case *image.Gray:
+		return img.Pix[img.PixOffset(x, y)]
+	}
+	r, g, b, _ := img.At(x, y).RGBA()
+	return uint8((299*r + 587*g + 114*b) / 1000 >> 8)
+}
+
+// Report junta os relatórios de vários capítulos. Só os capítulos com problemas
+// ou páginas removidas são guardados, para o relatório de coleções grandes
+// continuar pequeno.
+type Report struct {
+	mutex sync.Mutex
+
+	chapters      int
+	pages         int
+	analyzed      int
+	smallPages    int
+	blankPages    int
+	widthOutliers int
+	unreadable    int
+	removed       int
+	flagged       []ChapterReport
+}
+
+// NewReport cria um relatório vazio
+func NewReport() *Report {
+	return &Report{flagged: []ChapterReport{}}
+}
+
+// Add inclui o relatório de um capítulo
+func (r *Report) Add(chapter ChapterReport) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.chapters++
+	r.pages += chapter.Pages
+	r.analyzed += chapter.Analyzed
+	r.smallPages += len(chapter.SmallPages)
+	r.blankPages += len(chapter.BlankPages)
+	r.widthOutliers += len(chapter.WidthOutliers)
+	r.unreadable += len(chapter.Unreadable)
+	r.removed += len(chapter.Removed)
+	if len(chapter.Issues) > 0 || len(chapter.Removed) > 0 {
+		r.flagged = append(r.flagged, chapter)
+	}
+}
+
+// HasIssues informa se algum capítulo foi marcado
+func (r *Report) HasIssues() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.flagged) > 0
+}
+
+// MarshalJSON serializa um retrato do relatório, com os capítulos marcados em ordem
+func (r *Report) MarshalJSON() ([]byte, error) {
+	r.mutex.Lock()
+	flagged := append([]ChapterReport{}, r.flagged...)
+	summary := struct {
+		Chapters      int             `json:"chapters"`
+		Pages         int             `json:"pages"`
+		Analyzed      int             `json:"analyzed"`
+		SmallPages    int             `json:"smallPages"`
+		BlankPages    int             `json:"blankPages"`
+		WidthOutliers int             `json:"widthOutliers"`
+		Unreadable    int             `json:"unreadable"`
+		Removed       int             `json:"removed"`
+		Flagged       []ChapterReport `json:"flagged"`
+	}{r.chapters, r.pages, r.analyzed, r.smallPages, r.blankPages, r.widthOutliers, r.unreadable, r.removed, flagged}
+	r.mutex.Unlock()
+
+	sort.Slice(summary.Flagged, func(i, j int) bool { return summary.Flagged[i].Chapter < summary.Flagged[j].Chapter })
+	return json.Marshal(summary)
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"sync"
 	"time"
+
+	"go-upload/backend/internal/clock"
 )
 
 // RateLimiter controls the rate of operations using a token bucket algorithm
 type RateLimiter struct {
 	tokens     chan struct{}
-	ticker     *time.Ticker
+	ticker     clock.Ticker
 	ctx        context.Context
 	cancel     context.CancelFunc
 	wg         sync.WaitGroup
@@ -21,11 +23,16 @@ type RateLimiter struct {
 // maxTokens: maximum number of concurrent operations
 // refillRate: how often tokens are added back
 func NewRateLimiter(maxTokens int, refillRate time.Duration) *RateLimiter {
+	return NewRateLimiterWithClock(maxTokens, refillRate, clock.Real())
+}
+
+// NewRateLimiterWithClock creates a rate limiter whose refills follow clk
+func NewRateLimiterWithClock(maxTokens int, refillRate time.Duration, clk clock.Clock) *RateLimiter {
 	ctx, cancel := context.WithCancel(context.Background())
 	
 	rl := &RateLimiter{
 		tokens:     make(chan struct{}, maxTokens),
-		ticker:     time.NewTicker(refillRate),
+		ticker:     clk.NewTicker(refillRate),
 		ctx:        ctx,
 		cancel:     cancel,
 		maxTokens:  maxTokens,
@@ -75,7 +82,7 @@ func (rl *RateLimiter) refillTokens() {
 	
 	for {
 		select {
-		case <-rl.ticker.C:
+		case <-rl.ticker.C():
 			// Try to add a token
 			select {
 			case rl.tokens <- struct{}{}:
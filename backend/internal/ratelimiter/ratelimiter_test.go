@@ -0,0 +1,76 @@
+package ratelimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-upload/backend/internal/clock"
+)
+
+// acquireNow tries to take a token without waiting
+func acquireNow(rl *RateLimiter) bool {
+	select {
+	case <-rl.tokens:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestRefillFollowsClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	rl := NewRateLimiterWithClock(2, time.Second, fake)
+	defer rl.Close()
+
+	for i := 0; i < 2; i++ {
+		if !acquireNow(rl) {
+			t.Fatalf("initial token %d not available", i+1)
+		}
+	}
+	if acquireNow(rl) {
+		t.Fatal("bucket should be empty before the clock advances")
+	}
+
+	// Less than one interval: still no refill
+	fake.Advance(999 * time.Millisecond)
+	if acquireNow(rl) {
+		t.Fatal("token refilled before the interval elapsed")
+	}
+
+	// Completing the interval adds exactly one token
+	fake.Advance(time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := rl.Acquire(ctx); err != nil {
+		t.Fatalf("no token after one refill interval: %v", err)
+	}
+	if acquireNow(rl) {
+		t.Fatal("one interval should refill a single token")
+	}
+}
+
+func TestRefillStopsWhenFull(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	rl := NewRateLimiterWithClock(3, time.Second, fake)
+	defer rl.Close()
+
+	for acquireNow(rl) {
+	}
+
+	// One token per interval until the bucket is full, then nothing more
+	for tick := 1; tick <= 5; tick++ {
+		fake.Advance(time.Second)
+		want := tick
+		if want > 3 {
+			want = 3
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for rl.Available() < want && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if got := rl.Available(); got != want {
+			t.Fatalf("after %d intervals Available() = %d, want %d", tick, got, want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// anilistCoverPattern extrai o ID da AniList das capas servidas pelo CDN dela
+// (ex.: .../media/manga/cover/large/bx30002-abc.jpg)
+var anilistCoverPattern = regexp.MustCompile(`anilistcdn/media/manga/cover/[^/]+/bx(\d+)-`)
+
+// Backfill registra as obras de jsonDir que ainda não estão no registro, com o
+// ID da AniList quando o JSON tiver um. Sem isso a biblioteca que já existia
+// antes do registro aparece como "nova" nas buscas. Entradas existentes só
+// ganham o ID da AniList que ainda não tinham; nada é sobrescrito.
+// Retorna quantas entradas foram criadas ou completadas.
+func (r *Registry) Backfill(jsonDir string) (int, error) {
+	files, err := filepath.Glob(filepath.Join(jsonDir, "*.json"))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao listar JSONs: %w", err)
+	}
+
+	changed := 0
+	r.mutex.Lock()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var manga map[string]interface{}
+		if json.Unmarshal(data, &manga) != nil {
+			continue
+		}
+		// Índices gerados no mesmo diretório (catálogo etc.) não têm capítulos
+		if _, isManga := manga["chapters"]; !isManga {
+			continue
+		}
+
+		mangaID := strings.TrimSuffix(filepath.Base(file), ".json")
+		anilistID := jsonAniListID(manga)
+		if _, owned := r.byAniList[anilistID]; owned {
+			anilistID = 0
+		}
+
+		entry, exists := r.entries[mangaID]
+		switch {
+		case !exists:
+			title, _ := manga["title"].(string)
+			r.index(&Entry{
+				MangaID:   mangaID,
+				Title:     strings.TrimSpace(title),
+				AniListID: anilistID,
+				UpdatedAt: time.Now(),
+			})
+		case entry.AniListID == 0 && anilistID > 0:
+			entry.AniListID = anilistID
+			entry.UpdatedAt = time.Now()
+			r.index(entry)
+		default:
+			continue
+		}
+		changed++
+	}
+	r.mutex.Unlock()
+
+	if changed == 0 {
+		return 0, nil
+	}
+	return changed, r.save()
+}
+
+// jsonAniListID procura o ID da AniList no JSON da obra: campo anilistId (ou
+// anilist_id) ou a URL da capa, quando ela vem do CDN da AniList. 0 = nenhum.
+func jsonAniListID(manga map[string]interface{}) int {
+	for _, field := range []string{"anilistId", "anilist_id"} {
+		switch value := manga[field].(type) {
+		case float64:
+			if value > 0 {
+				return int(value)
+			}
+		case string:
+			if id, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && id > 0 {
+				return id
+			}
+		}
+	}
+
+	if cover, _ := manga["cover"].(string); cover != "" {
+		if match := anilistCoverPattern.FindStringSubmatch(cover); match != nil {
+			if id, err := strconv.Atoi(match[1]); err == nil {
+				return id
+			}
+		}
+	}
+	return 0
+}
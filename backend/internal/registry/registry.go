@@ -0,0 +1,298 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Entry associa uma obra da biblioteca local aos IDs dos provedores de metadados
+type Entry struct {
+	MangaID   string    `json:"mangaId"` // ID estável da obra (nome do JSON sem extensão)
+	Title     string    `json:"title"`
+	AniListID int       `json:"anilistId,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Obras arquivadas (abandonadas, licenciadas) ficam fora da descoberta,
+	// do GitHub e das estatísticas, mas nada é apagado
+	Archived      bool       `json:"archived,omitempty"`
+	ArchivedAt    *time.Time `json:"archivedAt,omitempty"`
+	ArchiveReason string     `json:"archiveReason,omitempty"`
+
+	// Estado de revisão por capítulo (chave do capítulo no JSON, ex.: "001")
+	Chapters map[string]ChapterReview `json:"chapters,omitempty"`
+}
+
+// Registry mantém o mapeamento persistente entre obras locais e IDs de provedores
+type Registry struct {
+	path      string
+	entries   map[string]*Entry
+	byAniList map[int]string    // anilistId -> mangaId
+	byTitle   map[string]string // título normalizado -> mangaId
+	mutex     sync.RWMutex
+	saveMutex sync.Mutex
+}
+
+// New cria o registro e carrega o arquivo existente, se houver
+func New(path string) (*Registry, error) {
+	r := &Registry{
+		path:      path,
+		entries:   make(map[string]*Entry),
+		byAniList: make(map[int]string),
+		byTitle:   make(map[string]string),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return r, fmt.Errorf("erro ao ler registro de IDs: %w", err)
+	}
+
+	var entries []*Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return r, fmt.Errorf("erro ao decodificar registro de IDs: %w", err)
+	}
+	for _, entry := range entries {
+		r.index(entry)
+	}
+
+	return r, nil
+}
+
+// index adiciona a entrada aos mapas de busca (chamador deve ter o lock)
+func (r *Registry) index(entry *Entry) {
+	r.entries[entry.MangaID] = entry
+	if entry.AniListID > 0 {
+		r.byAniList[entry.AniListID] = entry.MangaID
+	}
+	if title := NormalizeTitle(entry.Title); title != "" {
+		r.byTitle[title] = entry.MangaID
+	}
+}
+
+// unindex remove a entrada dos mapas de busca (chamador deve ter o lock)
+func (r *Registry) unindex(entry *Entry) {
+	delete(r.entries, entry.MangaID)
+	if r.byAniList[entry.AniListID] == entry.MangaID {
+		delete(r.byAniList, entry.AniListID)
+	}
+	if title := NormalizeTitle(entry.Title); r.byTitle[title] == entry.MangaID {
+		delete(r.byTitle, title)
+	}
+}
+
+// Link registra ou atualiza uma obra. Campos vazios (título, anilistID 0)
+// preservam os valores já registrados.
+func (r *Registry) Link(mangaID, title string, anilistID int) error {
+	if mangaID == "" {
+		return fmt.Errorf("mangaId é obrigatório")
+	}
+
+	r.mutex.Lock()
+	entry := &Entry{MangaID: mangaID}
+	if existing, exists := r.entries[mangaID]; exists {
+		*entry = *existing
+		r.unindex(existing)
+	}
+
+	// Um ID da AniList pertence a uma única obra
+	if anilistID > 0 {
+		if owner, exists := r.byAniList[anilistID]; exists && owner != mangaID {
+			r.entries[owner].AniListID = 0
+			delete(r.byAniList, anilistID)
+		}
+		entry.AniListID = anilistID
+	}
+	if strings.TrimSpace(title) != "" {
+		entry.Title = strings.TrimSpace(title)
+	}
+	entry.UpdatedAt = time.Now()
+
+	r.index(entry)
+	r.mutex.Unlock()
+
+	return r.save()
+}
+
+// SetArchived arquiva ou desarquiva uma obra, registrando-a se ainda não
+// estiver no registro. O motivo só é guardado ao arquivar.
+func (r *Registry) SetArchived(mangaID, title string, archived bool, reason string) error {
+	if mangaID == "" {
+		return fmt.Errorf("mangaId é obrigatório")
+	}
+
+	r.mutex.Lock()
+	entry, exists := r.entries[mangaID]
+	if !exists {
+		entry = &Entry{MangaID: mangaID, Title: strings.TrimSpace(title)}
+		r.index(entry)
+	}
+	entry.Archived = archived
+	entry.ArchivedAt = nil
+	entry.ArchiveReason = ""
+	if archived {
+		now := time.Now()
+		entry.ArchivedAt = &now
+		entry.ArchiveReason = strings.TrimSpace(reason)
+	}
+	entry.UpdatedAt = time.Now()
+	r.mutex.Unlock()
+
+	return r.save()
+}
+
+// IsArchived informa se uma obra está arquivada
+func (r *Registry) IsArchived(mangaID string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entry, exists := r.entries[mangaID]
+	return exists && entry.Archived
+}
+
+// Archived retorna os IDs das obras arquivadas
+func (r *Registry) Archived() map[string]bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	archived := make(map[string]bool)
+	for mangaID, entry := range r.entries {
+		if entry.Archived {
+			archived[mangaID] = true
+		}
+	}
+	return archived
+}
+
+// Remove apaga uma obra do registro
+func (r *Registry) Remove(mangaID string) error {
+	r.mutex.Lock()
+	entry, exists := r.entries[mangaID]
+	if exists {
+		r.unindex(entry)
+	}
+	r.mutex.Unlock()
+
+	if !exists {
+		return nil
+	}
+	return r.save()
+}
+
+// Rename troca o ID de uma obra mantendo os vínculos (usado quando o JSON é
+// renomeado). Não faz nada se oldID não está registrado.
+func (r *Registry) Rename(oldID, newID string) error {
+	if oldID == "" || newID == "" {
+		return fmt.Errorf("mangaId é obrigatório")
+	}
+	if oldID == newID {
+		return nil
+	}
+
+	r.mutex.Lock()
+	entry, exists := r.entries[oldID]
+	if !exists {
+		r.mutex.Unlock()
+		return nil
+	}
+	if _, taken := r.entries[newID]; taken {
+		r.mutex.Unlock()
+		return fmt.Errorf("obra %s já está registrada", newID)
+	}
+	r.unindex(entry)
+	entry.MangaID = newID
+	entry.UpdatedAt = time.Now()
+	r.index(entry)
+	r.mutex.Unlock()
+
+	return r.save()
+}
+
+// Get retorna a entrada de uma obra
+func (r *Registry) Get(mangaID string) (Entry, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if entry, exists := r.entries[mangaID]; exists {
+		return entry.clone(), true
+	}
+	return Entry{}, false
+}
+
+// FindByAniListID retorna a obra local vinculada a um ID da AniList
+func (r *Registry) FindByAniListID(anilistID int) (Entry, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if mangaID, exists := r.byAniList[anilistID]; exists {
+		return r.entries[mangaID].clone(), true
+	}
+	return Entry{}, false
+}
+
+// FindByTitle retorna a obra local cujo título normalizado é igual ao informado
+func (r *Registry) FindByTitle(title string) (Entry, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if mangaID, exists := r.byTitle[NormalizeTitle(title)]; exists {
+		return r.entries[mangaID].clone(), true
+	}
+	return Entry{}, false
+}
+
+// Entries retorna todas as entradas ordenadas por ID
+func (r *Registry) Entries() []Entry {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entries := make([]Entry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry.clone())
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].MangaID < entries[j].MangaID })
+	return entries
+}
+
+// save grava o registro em disco de forma atômica
+func (r *Registry) save() error {
+	r.saveMutex.Lock()
+	defer r.saveMutex.Unlock()
+
+	entries := r.Entries()
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao codificar registro de IDs: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório do registro: %w", err)
+	}
+
+	tmpPath := r.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("erro ao salvar registro de IDs: %w", err)
+	}
+	return os.Rename(tmpPath, r.path)
+}
+
+// NormalizeTitle remove pontuação e diferenças de caixa para comparar títulos
+func NormalizeTitle(title string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		} else {
+			builder.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
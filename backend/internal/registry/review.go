@@ -0,0 +1,259 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChapterStatus é a etapa de um capítulo no fluxo de revisão da equipe
+type ChapterStatus string
+
+const (
+	ChapterUploaded  ChapterStatus = "uploaded"  // páginas enviadas, aguardando ir para QC
+	ChapterQC        ChapterStatus = "qc"        // em controle de qualidade
+	ChapterApproved  ChapterStatus = "approved"  // liberado para publicação
+	ChapterPublished ChapterStatus = "published" // enviado ao GitHub
+)
+
+// Role é o papel de quem move um capítulo no fluxo de revisão
+type Role string
+
+const (
+	RoleUploader Role = "uploader" // envia capítulos e os manda para QC
+	RoleQC       Role = "qc"       // aprova, devolve ou reabre capítulos
+	RoleAdmin    Role = "admin"    // qualquer transição
+)
+
+// ErrRoleDenied indica que o papel não pode fazer a transição pedida
+var ErrRoleDenied = errors.New("papel sem permissão")
+
+// maxReviewHistory limita as transições guardadas por capítulo
+const maxReviewHistory = 50
+
+// reviewTransitions lista, por estado de origem, os destinos permitidos e os
+// papéis que podem fazer a transição (admin pode todas). Aprovado → publicado
+// é feito pela sincronização com o GitHub ou por um admin.
+var reviewTransitions = map[ChapterStatus]map[ChapterStatus][]Role{
+	ChapterUploaded:  {ChapterQC: {RoleUploader, RoleQC}},
+	ChapterQC:        {ChapterApproved: {RoleQC}, ChapterUploaded: {RoleQC}},
+	ChapterApproved:  {ChapterQC: {RoleQC}, ChapterPublished: nil},
+	ChapterPublished: {ChapterQC: {RoleQC}},
+}
+
+// ChapterReview é o estado de revisão de um capítulo
+type ChapterReview struct {
+	Status      ChapterStatus `json:"status"`
+	UpdatedAt   time.Time     `json:"updatedAt"`
+	UpdatedBy   string        `json:"updatedBy,omitempty"`
+	Note        string        `json:"note,omitempty"`
+	PublishedAt *time.Time    `json:"publishedAt,omitempty"` // última publicação
+	History     []ReviewEvent `json:"history,omitempty"`     // transições, da mais antiga à mais recente
+}
+
+// ReviewEvent é uma transição no fluxo de revisão
+type ReviewEvent struct {
+	From ChapterStatus `json:"from,omitempty"`
+	To   ChapterStatus `json:"to"`
+	By   string        `json:"by,omitempty"`
+	Role Role          `json:"role,omitempty"`
+	Note string        `json:"note,omitempty"`
+	At   time.Time     `json:"at"`
+}
+
+// ChapterReviewEntry é um capítulo em revisão com a obra a que pertence
+type ChapterReviewEntry struct {
+	MangaID string `json:"mangaId"`
+	Title   string `json:"title,omitempty"`
+	Chapter string `json:"chapter"`
+	ChapterReview
+}
+
+// ParseChapterStatus valida um estado de revisão
+func ParseChapterStatus(value string) (ChapterStatus, error) {
+	status := ChapterStatus(strings.ToLower(strings.TrimSpace(value)))
+	if _, known := reviewTransitions[status]; !known {
+		return "", fmt.Errorf("estado de revisão inválido: %q (use uploaded, qc, approved ou published)", value)
+	}
+	return status, nil
+}
+
+// ParseRole valida um papel de revisão
+func ParseRole(value string) (Role, error) {
+	switch role := Role(strings.ToLower(strings.TrimSpace(value))); role {
+	case RoleUploader, RoleQC, RoleAdmin:
+		return role, nil
+	}
+	return "", fmt.Errorf("papel inválido: %q (use uploader, qc ou admin)", value)
+}
+
+// CanTransition informa se role pode mover um capítulo de from para to
+func CanTransition(role Role, from, to ChapterStatus) error {
+	roles, allowed := reviewTransitions[from][to]
+	if !allowed {
+		return fmt.Errorf("transição não permitida: %s → %s", from, to)
+	}
+	if role == RoleAdmin {
+		return nil
+	}
+	for _, allowedRole := range roles {
+		if role == allowedRole {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s não pode mover capítulos de %s para %s", ErrRoleDenied, role, from, to)
+}
+
+// clone copia a entrada com seu próprio mapa de capítulos
+func (e *Entry) clone() Entry {
+	entry := *e
+	if e.Chapters != nil {
+		entry.Chapters = make(map[string]ChapterReview, len(e.Chapters))
+		for chapter, review := range e.Chapters {
+			entry.Chapters[chapter] = review
+		}
+	}
+	return entry
+}
+
+// apply registra a transição de um capítulo (chamador deve ter o lock)
+func (e *Entry) apply(chapter string, event ReviewEvent) ChapterReview {
+	if e.Chapters == nil {
+		e.Chapters = make(map[string]ChapterReview)
+	}
+	review := e.Chapters[chapter]
+	event.From = review.Status
+	review.Status = event.To
+	review.UpdatedAt = event.At
+	review.UpdatedBy = event.By
+	review.Note = event.Note
+	if event.To == ChapterPublished {
+		at := event.At
+		review.PublishedAt = &at
+	}
+	history := append(append([]ReviewEvent(nil), review.History...), event)
+	if len(history) > maxReviewHistory {
+		history = history[len(history)-maxReviewHistory:]
+	}
+	review.History = history
+	e.Chapters[chapter] = review
+	e.UpdatedAt = event.At
+	return review
+}
+
+// MarkChapterUploaded coloca um capítulo recém-enviado em "uploaded",
+// registrando a obra se necessário. Reenviar um capítulo já aprovado ou
+// publicado o devolve ao início do fluxo. Retorna se o estado mudou.
+func (r *Registry) MarkChapterUploaded(mangaID, title, chapter, by string) (bool, error) {
+	if mangaID == "" || chapter == "" {
+		return false, fmt.Errorf("mangaId e capítulo são obrigatórios")
+	}
+
+	r.mutex.Lock()
+	entry, exists := r.entries[mangaID]
+	if !exists {
+		entry = &Entry{MangaID: mangaID, Title: strings.TrimSpace(title)}
+		r.index(entry)
+	}
+	if review, tracked := entry.Chapters[chapter]; tracked && review.Status == ChapterUploaded {
+		r.mutex.Unlock()
+		return false, nil
+	}
+	entry.apply(chapter, ReviewEvent{To: ChapterUploaded, By: by, At: time.Now()})
+	r.mutex.Unlock()
+
+	return true, r.save()
+}
+
+// TransitionChapter move um capítulo para o estado to, conferindo se role
+// pode fazer a transição
+func (r *Registry) TransitionChapter(mangaID, chapter string, to ChapterStatus, by string, role Role, note string) (ChapterReview, error) {
+	r.mutex.Lock()
+	entry, exists := r.entries[mangaID]
+	if !exists {
+		r.mutex.Unlock()
+		return ChapterReview{}, fmt.Errorf("obra não registrada: %s", mangaID)
+	}
+	review, tracked := entry.Chapters[chapter]
+	if !tracked {
+		r.mutex.Unlock()
+		return ChapterReview{}, fmt.Errorf("capítulo %s de %s não está no fluxo de revisão", chapter, mangaID)
+	}
+	if err := CanTransition(role, review.Status, to); err != nil {
+		r.mutex.Unlock()
+		return ChapterReview{}, err
+	}
+	review = entry.apply(chapter, ReviewEvent{To: to, By: by, Role: role, Note: strings.TrimSpace(note), At: time.Now()})
+	r.mutex.Unlock()
+
+	return review, r.save()
+}
+
+// PublishApproved marca como publicados os capítulos aprovados de uma obra
+// (após a sincronização com o GitHub) e retorna quais foram marcados
+func (r *Registry) PublishApproved(mangaID, by string) ([]string, error) {
+	r.mutex.Lock()
+	entry, exists := r.entries[mangaID]
+	if !exists {
+		r.mutex.Unlock()
+		return nil, nil
+	}
+	var published []string
+	now := time.Now()
+	for chapter, review := range entry.Chapters {
+		if review.Status == ChapterApproved {
+			entry.apply(chapter, ReviewEvent{To: ChapterPublished, By: by, At: now})
+			published = append(published, chapter)
+		}
+	}
+	r.mutex.Unlock()
+
+	if len(published) == 0 {
+		return nil, nil
+	}
+	sort.Strings(published)
+	return published, r.save()
+}
+
+// ChapterPublishable informa se um capítulo pode ir para o GitHub: aprovado,
+// já publicado ou fora do fluxo de revisão (capítulos anteriores a ele)
+func (r *Registry) ChapterPublishable(mangaID, chapter string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entry, exists := r.entries[mangaID]
+	if !exists {
+		return true
+	}
+	review, tracked := entry.Chapters[chapter]
+	return !tracked || review.Status == ChapterApproved || review.Status == ChapterPublished
+}
+
+// ChapterReviews lista os capítulos no fluxo de revisão, opcionalmente de uma
+// obra e em um estado, ordenados por obra e capítulo
+func (r *Registry) ChapterReviews(mangaID string, status ChapterStatus) []ChapterReviewEntry {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var reviews []ChapterReviewEntry
+	for id, entry := range r.entries {
+		if mangaID != "" && id != mangaID {
+			continue
+		}
+		for chapter, review := range entry.Chapters {
+			if status != "" && review.Status != status {
+				continue
+			}
+			reviews = append(reviews, ChapterReviewEntry{MangaID: id, Title: entry.Title, Chapter: chapter, ChapterReview: review})
+		}
+	}
+	sort.Slice(reviews, func(i, j int) bool {
+		if reviews[i].MangaID != reviews[j].MangaID {
+			return reviews[i].MangaID < reviews[j].MangaID
+		}
+		return reviews[i].Chapter < reviews[j].Chapter
+	})
+	return reviews
+}
@@ -0,0 +1,207 @@
+package release
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"go-upload/backend/internal/mangaid"
+	"go-upload/backend/internal/metadata"
+)
+
+// Formatos de post suportados
+const (
+	FormatMarkdown = "markdown"
+	FormatBBCode   = "bbcode"
+)
+
+// Formats lista os formatos na ordem em que são gerados
+var Formats = []string{FormatMarkdown, FormatBBCode}
+
+// Config personaliza os posts de lançamento
+type Config struct {
+	// ReaderURL gera o link de leitura de cada capítulo; aceita {manga} (ID da obra)
+	// e {chapter}. Vazio = o post lista apenas os links das páginas.
+	ReaderURL string `json:"readerUrl,omitempty"`
+	// Templates substitui os modelos embutidos: formato -> caminho de um text/template
+	Templates map[string]string `json:"templates,omitempty"`
+}
+
+// Link é um endereço do capítulo em um host
+type Link struct {
+	Host string `json:"host"`
+	URL  string `json:"url"` // primeira página do capítulo
+}
+
+// Chapter é um capítulo anunciado no post
+type Chapter struct {
+	ID        string `json:"id"`
+	Title     string `json:"title,omitempty"`
+	Pages     int    `json:"pages"`
+	ReaderURL string `json:"readerUrl,omitempty"`
+	Links     []Link `json:"links"` // host principal primeiro, depois os espelhos
+}
+
+// Manga é uma obra com os capítulos lançados
+type Manga struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Chapters []Chapter `json:"chapters"`
+}
+
+// Post é o lançamento de um lote, pronto para ser copiado em fóruns
+type Post struct {
+	BatchID   string            `json:"batchId"`
+	Group     string            `json:"group"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Manga     []Manga           `json:"manga"`
+	Text      map[string]string `json:"text"` // formato -> texto renderizado
+}
+
+// Builder monta e renderiza os posts de lançamento
+type Builder struct {
+	config    Config
+	templates map[string]*template.Template
+}
+
+// NewBuilder carrega os modelos, usando os embutidos para formatos sem personalização
+func NewBuilder(config Config) (*Builder, error) {
+	builder := &Builder{config: config, templates: make(map[string]*template.Template)}
+
+	for _, format := range Formats {
+		text := defaultTemplates[format]
+		if path := config.Templates[format]; path != "" {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s template: %v", format, err)
+			}
+			text = string(raw)
+		}
+		tmpl, err := template.New(format).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s template: %v", format, err)
+		}
+		builder.templates[format] = tmpl
+	}
+	for format := range config.Templates {
+		if _, known := builder.templates[format]; !known {
+			return nil, fmt.Errorf("unknown release post format %q", format)
+		}
+	}
+	return builder, nil
+}
+
+// Build agrupa os arquivos enviados por obra e capítulo e renderiza todos os formatos
+func (b *Builder) Build(batchID, group string, files []metadata.UploadedFile) (*Post, error) {
+	post := &Post{
+		BatchID:   batchID,
+		Group:     group,
+		CreatedAt: time.Now(),
+		Manga:     []Manga{},
+		Text:      make(map[string]string),
+	}
+
+	byManga := make(map[string]map[string][]metadata.UploadedFile)
+	titles := make(map[string]string)
+	for _, file := range files {
+		if byManga[file.MangaID] == nil {
+			byManga[file.MangaID] = make(map[string][]metadata.UploadedFile)
+		}
+		byManga[file.MangaID][file.ChapterID] = append(byManga[file.MangaID][file.ChapterID], file)
+		if file.MangaTitle != "" {
+			titles[file.MangaID] = file.MangaTitle
+		}
+	}
+
+	for mangaID, chapters := range byManga {
+		manga := Manga{ID: mangaID, Title: titles[mangaID], Chapters: []Chapter{}}
+		if manga.Title == "" {
+			manga.Title = mangaid.StripPrefix(mangaID)
+		}
+		for chapterID, pages := range chapters {
+			manga.Chapters = append(manga.Chapters, b.chapter(mangaID, chapterID, pages))
+		}
+		sort.Slice(manga.Chapters, func(i, j int) bool {
+			return chapterLess(manga.Chapters[i].ID, manga.Chapters[j].ID)
+		})
+		post.Manga = append(post.Manga, manga)
+	}
+	sort.Slice(post.Manga, func(i, j int) bool { return post.Manga[i].Title < post.Manga[j].Title })
+
+	for _, format := range Formats {
+		var text strings.Builder
+		if err := b.templates[format].Execute(&text, post); err != nil {
+			return nil, fmt.Errorf("failed to render %s post: %v", format, err)
+		}
+		post.Text[format] = strings.TrimSpace(text.String()) + "\n"
+	}
+	return post, nil
+}
+
+// chapter resume as páginas de um capítulo; o link de cada host é a primeira página
+func (b *Builder) chapter(mangaID, chapterID string, pages []metadata.UploadedFile) Chapter {
+	sort.Slice(pages, func(i, j int) bool { return pages[i].PageIndex < pages[j].PageIndex })
+
+	chapter := Chapter{ID: chapterID, Pages: len(pages), Links: []Link{}}
+	for _, page := range pages {
+		if page.ChapterTitle != "" {
+			chapter.Title = page.ChapterTitle
+			break
+		}
+	}
+
+	first := pages[0]
+	if first.URL != "" {
+		chapter.Links = append(chapter.Links, Link{Host: metadata.URLHost(first.URL), URL: first.URL})
+	}
+	mirrorHosts := make([]string, 0, len(first.Mirrors))
+	for host := range first.Mirrors {
+		mirrorHosts = append(mirrorHosts, host)
+	}
+	sort.Strings(mirrorHosts)
+	for _, host := range mirrorHosts {
+		chapter.Links = append(chapter.Links, Link{Host: metadata.URLHost(first.Mirrors[host]), URL: first.Mirrors[host]})
+	}
+
+	if b.config.ReaderURL != "" {
+		chapter.ReaderURL = strings.NewReplacer(
+			"{manga}", url.PathEscape(mangaid.StripPrefix(mangaID)),
+			"{chapter}", url.PathEscape(chapterID),
+		).Replace(b.config.ReaderURL)
+	}
+	return chapter
+}
+
+// chapterLess ordena capítulos numericamente quando possível ("2" antes de "10")
+func chapterLess(a, b string) bool {
+	numA, errA := strconv.ParseFloat(a, 64)
+	numB, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil && numA != numB {
+		return numA < numB
+	}
+	return a < b
+}
+
+var defaultTemplates = map[string]string{
+	FormatMarkdown: `{{range .Manga}}## {{.Title}}
+{{range .Chapters}}
+**Capítulo {{.ID}}{{if .Title}} — {{.Title}}{{end}}** ({{.Pages}} {{if eq .Pages 1}}página{{else}}páginas{{end}})
+{{if .ReaderURL}}- [Ler online]({{.ReaderURL}})
+{{end}}{{range .Links}}- [{{.Host}}]({{.URL}})
+{{end}}{{end}}
+{{end}}{{if .Group}}_Lançamento por {{.Group}}_{{end}}
+`,
+	FormatBBCode: `{{range .Manga}}[size=5][b]{{.Title}}[/b][/size]
+{{range .Chapters}}
+[b]Capítulo {{.ID}}{{if .Title}} — {{.Title}}{{end}}[/b] ({{.Pages}} {{if eq .Pages 1}}página{{else}}páginas{{end}})
+{{if .ReaderURL}}[url={{.ReaderURL}}]Ler online[/url]
+{{end}}{{range .Links}}[url={{.URL}}]{{.Host}}[/url]
+{{end}}{{end}}
+{{end}}{{if .Group}}[i]Lançamento por {{.Group}}[/i]{{end}}
+`,
+}
@@ -0,0 +1,65 @@
+package retry
+
+import (
+	"sync/atomic"
+)
+
+// MinBudget é o menor orçamento padrão de um grupo, para lotes pequenos
+const MinBudget = 10
+
+// Budget limita o total de retries de um grupo de operações (um lote, uma
+// coleção): quando o host está fora do ar, o grupo desiste em vez de repetir
+// cada arquivo até o fim. Um Budget nil não tem limite.
+type Budget struct {
+	limit int64
+	used  int64
+}
+
+// NewBudget cria um orçamento de limit retries; limit <= 0 retorna nil (sem limite)
+func NewBudget(limit int) *Budget {
+	if limit <= 0 {
+		return nil
+	}
+	return &Budget{limit: int64(limit)}
+}
+
+// BudgetFor cria o orçamento de um grupo de items operações a partir do valor
+// configurado: > 0 é o limite, < 0 desativa o limite e 0 usa o padrão (um
+// retry por item, no mínimo MinBudget)
+func BudgetFor(items, configured int) *Budget {
+	switch {
+	case configured > 0:
+		return NewBudget(configured)
+	case configured < 0:
+		return nil
+	}
+	return NewBudget(max(items, MinBudget))
+}
+
+// take consome um retry; false se o orçamento acabou
+func (b *Budget) take() bool {
+	if b == nil {
+		return true
+	}
+	if atomic.AddInt64(&b.used, 1) > b.limit {
+		atomic.AddInt64(&b.used, -1)
+		return false
+	}
+	return true
+}
+
+// Used retorna os retries já consumidos
+func (b *Budget) Used() int64 {
+	if b == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&b.used)
+}
+
+// Limit retorna o limite (0 = sem limite)
+func (b *Budget) Limit() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.limit
+}
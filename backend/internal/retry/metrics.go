@@ -0,0 +1,87 @@
+package retry
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Stats são as métricas de retry de um componente
+type Stats struct {
+	Succeeded       int64 `json:"succeeded"`       // operações concluídas
+	SucceededRetry  int64 `json:"succeededRetry"`  // ...das quais depois de pelo menos um retry
+	Failed          int64 `json:"failed"`          // operações que desistiram (erro permanente ou retries esgotados)
+	BudgetExhausted int64 `json:"budgetExhausted"` // ...das quais porque o orçamento do grupo acabou
+	Attempts        int64 `json:"attempts"`        // tentativas das operações terminadas
+	Retries         int64 `json:"retries"`         // retries agendados
+}
+
+type counters struct {
+	succeeded       int64
+	succeededRetry  int64
+	failed          int64
+	budgetExhausted int64
+	attempts        int64
+	retries         int64
+}
+
+var registry sync.Map // nome -> *counters
+
+func countersFor(name string) *counters {
+	if c, ok := registry.Load(name); ok {
+		return c.(*counters)
+	}
+	c, _ := registry.LoadOrStore(name, &counters{})
+	return c.(*counters)
+}
+
+func (c *counters) recordSuccess(attempts int) {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.succeeded, 1)
+	atomic.AddInt64(&c.attempts, int64(attempts))
+	if attempts > 1 {
+		atomic.AddInt64(&c.succeededRetry, 1)
+	}
+}
+
+func (c *counters) recordFailure(attempts int) {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.failed, 1)
+	atomic.AddInt64(&c.attempts, int64(attempts))
+}
+
+func (c *counters) recordExhausted(attempts int) {
+	if c == nil {
+		return
+	}
+	c.recordFailure(attempts)
+	atomic.AddInt64(&c.budgetExhausted, 1)
+}
+
+func (c *counters) recordRetry() {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.retries, 1)
+}
+
+// Snapshot retorna as métricas de todos os componentes que já usaram retry
+func Snapshot() map[string]Stats {
+	snapshot := make(map[string]Stats)
+	registry.Range(func(key, value interface{}) bool {
+		c := value.(*counters)
+		snapshot[key.(string)] = Stats{
+			Succeeded:       atomic.LoadInt64(&c.succeeded),
+			SucceededRetry:  atomic.LoadInt64(&c.succeededRetry),
+			Failed:          atomic.LoadInt64(&c.failed),
+			BudgetExhausted: atomic.LoadInt64(&c.budgetExhausted),
+			Attempts:        atomic.LoadInt64(&c.attempts),
+			Retries:         atomic.LoadInt64(&c.retries),
+		}
+		return true
+	})
+	return snapshot
+}
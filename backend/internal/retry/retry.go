@@ -0,0 +1,186 @@
+// Package retry concentra as regras de nova tentativa usadas pelos uploads
+// (lotes, Catbox, coleções) e pela AniList: backoff exponencial com jitter,
+// orçamento de tentativas extras compartilhado por um grupo (um lote, uma
+// coleção) e métricas por componente no mesmo formato.
+//
+// A tentativa 1 é a original; "retry" n é a tentativa n+1. O atraso antes do
+// retry n é BaseDelay * Factor^(n-1), limitado a MaxDelay, com ±Jitter.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Padrões da política
+const (
+	DefaultFactor   = 2.0
+	DefaultJitter   = 0.25 // ±25% do atraso
+	DefaultMaxDelay = time.Minute
+)
+
+// ErrBudgetExhausted indica que o orçamento de retries do grupo acabou
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+// Policy define quantas vezes e com que intervalo uma operação é repetida
+type Policy struct {
+	MaxRetries int           // tentativas extras após a primeira (0 = nenhuma)
+	BaseDelay  time.Duration // atraso antes do primeiro retry
+	MaxDelay   time.Duration // limite do atraso (padrão DefaultMaxDelay)
+	Factor     float64       // multiplicador a cada retry (padrão DefaultFactor)
+	Jitter     float64       // fração aleatória ± do atraso (0 = sem jitter)
+}
+
+// Delay retorna o atraso antes do retry n (n >= 1)
+func (p Policy) Delay(n int) time.Duration {
+	if n < 1 {
+		n = 1
+	}
+	factor := p.Factor
+	if factor <= 0 {
+		factor = DefaultFactor
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxDelay
+	}
+
+	delay := float64(p.BaseDelay) * math.Pow(factor, float64(n-1))
+	if delay > float64(maxDelay) {
+		delay = float64(maxDelay)
+	}
+	if p.Jitter > 0 {
+		delay += (rand.Float64()*2 - 1) * p.Jitter * delay
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return time.Duration(delay)
+}
+
+// Retrier aplica uma política a uma operação. O valor zero tenta uma vez só.
+type Retrier struct {
+	Policy
+
+	// Name identifica o componente nas métricas ("" = sem métricas)
+	Name string
+
+	// Budget limita os retries do grupo ao qual a operação pertence (nil = sem limite)
+	Budget *Budget
+
+	// Classify decide se um erro merece nova tentativa e, opcionalmente, o atraso
+	// pedido pelo serviço (Retry-After). nil = todo erro é repetido com o atraso
+	// da política. Erros marcados com Permanent nunca são repetidos.
+	Classify func(err error) (retryable bool, after time.Duration)
+
+	// OnRetry é chamado antes de aguardar cada retry (para logs)
+	OnRetry func(n int, err error, delay time.Duration)
+}
+
+// Next decide se o retry n (n >= 1) acontece depois do erro err e com que
+// atraso. Usado diretamente por quem agenda o retry por conta própria (filas);
+// quem executa a operação em laço usa Do. Um retry negado fica registrado
+// nas métricas como falha.
+func (r *Retrier) Next(n int, err error) (time.Duration, error) {
+	if n > r.MaxRetries || IsPermanent(err) {
+		r.metrics().recordFailure(n)
+		return 0, err
+	}
+	delay := time.Duration(0)
+	if r.Classify != nil {
+		retryable, after := r.Classify(err)
+		if !retryable {
+			r.metrics().recordFailure(n)
+			return 0, err
+		}
+		delay = after
+	}
+	if !r.Budget.take() {
+		r.metrics().recordExhausted(n)
+		return 0, &budgetError{err: err}
+	}
+	if delay <= 0 {
+		delay = r.Delay(n)
+	}
+	r.metrics().recordRetry()
+	return delay, nil
+}
+
+// Succeeded registra nas métricas uma operação concluída após attempts tentativas
+func (r *Retrier) Succeeded(attempts int) {
+	r.metrics().recordSuccess(attempts)
+}
+
+// Do executa op até ela dar certo, os retries acabarem ou ctx ser cancelado.
+// op recebe o número da tentativa (1 = original). Retorna quantas tentativas
+// foram feitas e o último erro (ctx.Err() se cancelado durante a espera).
+func (r *Retrier) Do(ctx context.Context, op func(attempt int) error) (int, error) {
+	for attempt := 1; ; attempt++ {
+		err := op(attempt)
+		if err == nil {
+			r.Succeeded(attempt)
+			return attempt, nil
+		}
+
+		delay, stop := r.Next(attempt, err)
+		if stop != nil {
+			return attempt, stop
+		}
+		if r.OnRetry != nil {
+			r.OnRetry(attempt, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			r.metrics().recordFailure(attempt)
+			return attempt, ctx.Err()
+		}
+	}
+}
+
+// metrics retorna os contadores do componente (nil sem Name)
+func (r *Retrier) metrics() *counters {
+	if r.Name == "" {
+		return nil
+	}
+	return countersFor(r.Name)
+}
+
+// permanentError marca um erro que não deve ser repetido
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marca err para que nenhum retry aconteça (nil continua nil)
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent informa se err foi marcado com Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// budgetError é o último erro da operação quando o orçamento impediu o retry
+type budgetError struct {
+	err error
+}
+
+func (e *budgetError) Error() string {
+	return ErrBudgetExhausted.Error() + ": " + e.err.Error()
+}
+
+func (e *budgetError) Unwrap() []error { return []error{ErrBudgetExhausted, e.err} }
@@ -0,0 +1,206 @@
+// Package scrub remove metadados de imagens antes do upload: EXIF (com a
+// miniatura embutida), XMP, IPTC e comentários, que em scans costumam trazer
+// o nome da máquina, o editor usado e até coordenadas de GPS.
+//
+// A remoção é feita nos blocos do arquivo, sem recodificar os pixels; perfis
+// de cor (ICC) são mantidos. Formatos suportados: JPEG, PNG e WebP. Outros
+// arquivos, ou arquivos malformados, são enviados como estão.
+package scrub
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Strip retorna os dados sem metadados e quantos bytes foram removidos.
+// Quando nada é removido, retorna os próprios dados.
+func Strip(data []byte) ([]byte, int) {
+	var out []byte
+	var ok bool
+	switch {
+	case bytes.HasPrefix(data, jpegSOI):
+		out, ok = stripJPEG(data)
+	case bytes.HasPrefix(data, pngSignature):
+		out, ok = stripPNG(data)
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		out, ok = stripWebP(data)
+	}
+	if !ok || len(out) >= len(data) {
+		return data, 0
+	}
+	return out, len(data) - len(out)
+}
+
+// File grava em dir ("" = diretório temporário do sistema) uma cópia de path
+// sem metadados. Se não houver o que remover, retorna o próprio path e 0;
+// caso contrário o chamador deve apagar o arquivo retornado após o uso.
+func File(path, dir string) (string, int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read image: %w", err)
+	}
+	out, removed := Strip(data)
+	if removed == 0 {
+		return path, 0, nil
+	}
+
+	tmp, err := os.CreateTemp(dir, "scrub-*"+filepath.Ext(path))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", 0, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", 0, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return tmp.Name(), int64(removed), nil
+}
+
+var (
+	jpegSOI      = []byte{0xFF, 0xD8}
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// Prefixos dos segmentos APPn de JPEG que carregam metadados
+var (
+	exifPrefix = []byte("Exif\x00")
+	xmpPrefix  = []byte("http://ns.adobe.com/")
+	jfxxPrefix = []byte("JFXX\x00") // miniatura da extensão JFIF
+)
+
+// stripJPEG copia os segmentos até o início da imagem (SOS), descartando
+// APP1 (EXIF/XMP), APP13 (Photoshop/IPTC), COM e miniaturas JFXX
+func stripJPEG(data []byte) ([]byte, bool) {
+	out := make([]byte, 0, len(data))
+	out = append(out, jpegSOI...)
+	i := 2
+	for i < len(data) {
+		if data[i] != 0xFF {
+			return nil, false
+		}
+		// Bytes de preenchimento 0xFF antes do marcador
+		for i+1 < len(data) && data[i+1] == 0xFF {
+			i++
+		}
+		if i+1 >= len(data) {
+			return nil, false
+		}
+		marker := data[i+1]
+		// Marcadores sem tamanho
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			out = append(out, data[i:i+2]...)
+			i += 2
+			continue
+		}
+		if marker == 0xD9 {
+			out = append(out, data[i:]...)
+			return out, true
+		}
+		if i+4 > len(data) {
+			return nil, false
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, false
+		}
+		// Início dos dados comprimidos: o restante é copiado como está
+		if marker == 0xDA {
+			out = append(out, data[i:]...)
+			return out, true
+		}
+		payload := data[i+4 : end]
+		if !jpegMetadata(marker, payload) {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out, true
+}
+
+func jpegMetadata(marker byte, payload []byte) bool {
+	switch marker {
+	case 0xE1:
+		return bytes.HasPrefix(payload, exifPrefix) || bytes.HasPrefix(payload, xmpPrefix)
+	case 0xED, 0xFE:
+		return true
+	case 0xE0:
+		return bytes.HasPrefix(payload, jfxxPrefix)
+	}
+	return false
+}
+
+// pngMetadata são os chunks de texto, EXIF e data de modificação
+var pngMetadata = map[string]bool{
+	"tEXt": true, "zTXt": true, "iTXt": true, "eXIf": true, "tIME": true,
+}
+
+func stripPNG(data []byte) ([]byte, bool) {
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	i := len(pngSignature)
+	for i < len(data) {
+		if i+8 > len(data) {
+			return nil, false
+		}
+		length := int(binary.BigEndian.Uint32(data[i : i+4]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) || end < i {
+			return nil, false
+		}
+		chunkType := string(data[i+4 : i+8])
+		if !pngMetadata[chunkType] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	return out, true
+}
+
+// Bits do cabeçalho VP8X que anunciam os chunks de metadados
+const (
+	vp8xEXIF = 0x08
+	vp8xXMP  = 0x04
+)
+
+func stripWebP(data []byte) ([]byte, bool) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	vp8x := -1
+	i := 12
+	for i < len(data) {
+		if i+8 > len(data) {
+			return nil, false
+		}
+		fourCC := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4 : i+8]))
+		end := i + 8 + size + size%2
+		if size < 0 || end > len(data) || end < i {
+			return nil, false
+		}
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			vp8x = len(out)
+			out = append(out, data[i:end]...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	if vp8x >= 0 && vp8x+8 < len(out) {
+		out[vp8x+8] &^= vp8xEXIF | vp8xXMP
+	}
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, true
+}
@@ -0,0 +1,275 @@
+// Package search mantém um índice invertido dos JSONs locais para a busca da
+// biblioteca: títulos, autores, artistas e descrições. O índice é atualizado de
+// forma incremental a cada busca, relendo só os JSONs criados, modificados ou
+// removidos desde a anterior.
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"go-upload/backend/internal/metadata"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultLimit é o número de resultados quando a busca não define um limite
+const DefaultLimit = 50
+
+// Campos indexados
+const (
+	FieldTitle       = "title"
+	FieldAuthor      = "author"
+	FieldArtist      = "artist"
+	FieldDescription = "description"
+)
+
+// fieldWeights define quanto um termo vale em cada campo: achar no título pesa mais
+var fieldWeights = map[string]float64{
+	FieldTitle:       4,
+	FieldAuthor:      2,
+	FieldArtist:      2,
+	FieldDescription: 1,
+}
+
+// Result é uma obra encontrada
+type Result struct {
+	ID      string   `json:"id"`
+	Title   string   `json:"title"`
+	Author  string   `json:"author"`
+	Artist  string   `json:"artist"`
+	Cover   string   `json:"cover"`
+	Status  string   `json:"status"`
+	Score   float64  `json:"score"`
+	Matches []string `json:"matches"` // campos em que algum termo foi encontrado
+}
+
+// document é um JSON indexado
+type document struct {
+	modTime time.Time
+	result  Result
+	terms   map[string]map[string]int // termo → campo → ocorrências
+}
+
+// Index é o índice invertido da biblioteca
+type Index struct {
+	jsonDir string
+
+	mutex    sync.Mutex
+	docs     map[string]*document       // caminho do JSON → documento
+	postings map[string]map[string]bool // termo → caminhos que o contêm
+}
+
+// NewIndex cria um índice vazio para os JSONs de jsonDir
+func NewIndex(jsonDir string) *Index {
+	return &Index{
+		jsonDir:  jsonDir,
+		docs:     make(map[string]*document),
+		postings: make(map[string]map[string]bool),
+	}
+}
+
+// Refresh reindexa os JSONs alterados desde a última chamada e remove os que
+// sumiram (apagados ou arquivados). JSONs inválidos ficam fora da busca.
+func (idx *Index) Refresh() error {
+	files, err := filepath.Glob(filepath.Join(idx.jsonDir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("failed to list JSONs: %v", err)
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	seen := make(map[string]bool, len(files))
+	for _, path := range files {
+		seen[path] = true
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if doc, ok := idx.docs[path]; ok && doc.modTime.Equal(info.ModTime()) {
+			continue
+		}
+		idx.remove(path)
+
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var manga metadata.MangaJSON
+		if err := json.Unmarshal(raw, &manga); err != nil {
+			continue
+		}
+		idx.add(path, info.ModTime(), manga)
+	}
+	for path := range idx.docs {
+		if !seen[path] {
+			idx.remove(path)
+		}
+	}
+	return nil
+}
+
+// add indexa um JSON; chamado com o mutex travado
+func (idx *Index) add(path string, modTime time.Time, manga metadata.MangaJSON) {
+	doc := &document{
+		modTime: modTime,
+		result: Result{
+			ID:     strings.TrimSuffix(filepath.Base(path), ".json"),
+			Title:  manga.Title,
+			Author: manga.Author,
+			Artist: manga.Artist,
+			Cover:  manga.Cover,
+			Status: manga.Status,
+		},
+		terms: make(map[string]map[string]int),
+	}
+	fields := map[string]string{
+		FieldTitle:       manga.Title,
+		FieldAuthor:      manga.Author,
+		FieldArtist:      manga.Artist,
+		FieldDescription: manga.Description,
+	}
+	for field, text := range fields {
+		for _, term := range Tokenize(text) {
+			if doc.terms[term] == nil {
+				doc.terms[term] = make(map[string]int)
+			}
+			doc.terms[term][field]++
+		}
+	}
+
+	idx.docs[path] = doc
+	for term := range doc.terms {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[string]bool)
+		}
+		idx.postings[term][path] = true
+	}
+}
+
+// remove tira um JSON do índice; chamado com o mutex travado
+func (idx *Index) remove(path string) {
+	doc, ok := idx.docs[path]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		delete(idx.postings[term], path)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.docs, path)
+}
+
+// Search atualiza o índice e retorna as obras que contêm todos os termos da
+// consulta, da mais relevante para a menos. O último termo também casa por
+// prefixo, para a busca funcionar enquanto o usuário digita.
+func (idx *Index) Search(query string, limit int) ([]Result, int, error) {
+	if err := idx.Refresh(); err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return []Result{}, 0, nil
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	var scores map[string]float64
+	matches := make(map[string]map[string]bool)
+	for i, term := range terms {
+		// Termos exatos valem o dobro dos encontrados só por prefixo
+		candidates := map[string]float64{term: 2}
+		if i == len(terms)-1 {
+			for indexed := range idx.postings {
+				if indexed != term && strings.HasPrefix(indexed, term) {
+					candidates[indexed] = 1
+				}
+			}
+		}
+
+		termScores := make(map[string]float64)
+		for candidate, boost := range candidates {
+			for path := range idx.postings[candidate] {
+				if scores != nil {
+					if _, ok := scores[path]; !ok {
+						continue
+					}
+				}
+				score := 0.0
+				for field, count := range idx.docs[path].terms[candidate] {
+					score += fieldWeights[field] * float64(count) * boost
+					if matches[path] == nil {
+						matches[path] = make(map[string]bool)
+					}
+					matches[path][field] = true
+				}
+				if score > termScores[path] {
+					termScores[path] = score
+				}
+			}
+		}
+
+		// Todos os termos precisam aparecer: a interseção só diminui
+		next := make(map[string]float64, len(termScores))
+		for path, score := range termScores {
+			next[path] = scores[path] + score
+		}
+		scores = next
+		if len(scores) == 0 {
+			break
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for path, score := range scores {
+		result := idx.docs[path].result
+		result.Score = score
+		result.Matches = []string{}
+		for field := range matches[path] {
+			result.Matches = append(result.Matches, field)
+		}
+		sort.Strings(result.Matches)
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return strings.ToLower(results[i].Title) < strings.ToLower(results[j].Title)
+	})
+
+	total := len(results)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, total, nil
+}
+
+// Tokenize divide um texto em termos minúsculos sem acentos ("Ação" e "acao"
+// viram o mesmo termo), separando por qualquer caractere que não seja letra ou número
+func Tokenize(text string) []string {
+	// Transformers guardam estado: um por chamada, já que buscas rodam em paralelo
+	accentFolder := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(accentFolder, text)
+	if err != nil {
+		folded = text
+	}
+	return strings.FieldsFunc(strings.ToLower(folded), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package selfupdate
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Restart substitui o processo atual pelo executável em path com os mesmos
+// argumentos e ambiente. O PID não muda, então systemd e outros supervisores
+// continuam acompanhando o serviço.
+func Restart(path string, args []string) error {
+	if err := syscall.Exec(path, append([]string{path}, args...), os.Environ()); err != nil {
+		return fmt.Errorf("failed to exec %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package selfupdate
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Restart inicia o executável em path com os mesmos argumentos; o chamador
+// deve encerrar o processo atual em seguida (o Windows não tem exec)
+func Restart(path string, args []string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package share
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// pollInterval é o intervalo entre as verificações de progresso enviadas pelo WebSocket
+const pollInterval = 2 * time.Second
+
+// StatusFunc retorna a visão pública de uma coleção (false se ela não existe mais)
+type StatusFunc func(collectionID string) (interface{}, bool)
+
+// View é o que um link compartilhado vê: a coleção e o prazo do link
+type View struct {
+	CollectionID string      `json:"collectionId"`
+	ExpiresAt    time.Time   `json:"expiresAt"`
+	Collection   interface{} `json:"collection"`
+}
+
+// Handler serve os links compartilhados:
+//
+//	/share/<token>         página de status
+//	/share/<token>/status  status atual em JSON
+//	/share/<token>/ws      WebSocket que envia o status a cada mudança
+//
+// Tokens inválidos recebem 404 e expirados 410. O WebSocket não aceita ações:
+// mensagens do cliente são descartadas.
+type Handler struct {
+	signer   *Signer
+	status   StatusFunc
+	upgrader *websocket.Upgrader
+}
+
+// NewHandler cria o handler dos links compartilhados
+func NewHandler(signer *Signer, status StatusFunc, upgrader *websocket.Upgrader) *Handler {
+	return &Handler{signer: signer, status: status, upgrader: upgrader}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// O token está na URL: não pode vazar por Referer, caches ou buscadores
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	token, view, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, PathPrefix), "/")
+	claims, err := h.signer.Verify(token)
+	if errors.Is(err, ErrExpired) {
+		http.Error(w, err.Error(), http.StatusGone)
+		return
+	}
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch view {
+	case "":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(statusPage))
+	case "status":
+		current, ok := h.view(claims)
+		if !ok {
+			http.Error(w, "collection no longer available", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(current)
+	case "ws":
+		h.serveWebSocket(w, r, token, claims)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// view monta o status atual da coleção do token
+func (h *Handler) view(claims Claims) (View, bool) {
+	collection, ok := h.status(claims.CollectionID)
+	if !ok {
+		return View{}, false
+	}
+	return View{
+		CollectionID: claims.CollectionID,
+		ExpiresAt:    time.Unix(claims.ExpiresAt, 0).UTC(),
+		Collection:   collection,
+	}, true
+}
+
+// serveWebSocket envia o status sempre que ele muda, até o link expirar, ser
+// revogado ou o cliente sair. O token é verificado de novo a cada consulta, para
+// que RevokeAll derrube também quem já está conectado.
+func (h *Handler) serveWebSocket(w http.ResponseWriter, r *http.Request, token string, claims Claims) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// Somente leitura: o que o cliente enviar é descartado, só o fechamento importa
+	conn.SetReadLimit(512)
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	expiry := time.NewTimer(time.Until(time.Unix(claims.ExpiresAt, 0)))
+	defer expiry.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var last []byte
+	for {
+		if _, err := h.signer.Verify(token); err != nil {
+			h.close(conn, websocket.ClosePolicyViolation, err.Error())
+			return
+		}
+		current, ok := h.view(claims)
+		if !ok {
+			h.close(conn, websocket.CloseNormalClosure, "collection no longer available")
+			return
+		}
+		data, err := json.Marshal(current)
+		if err == nil && !bytes.Equal(data, last) {
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+			last = data
+		}
+
+		select {
+		case <-closed:
+			return
+		case <-expiry.C:
+			h.close(conn, websocket.ClosePolicyViolation, ErrExpired.Error())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Handler) close(conn *websocket.Conn, code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+}
+
+// statusPage é a página mínima aberta pelo link: conecta ao WebSocket ao lado
+// e, se ele cair, consulta /status para saber se o link ainda vale
+const statusPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="referrer" content="no-referrer">
+<title>Collection progress</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+h1 { font-size: 1.4rem; margin-bottom: .25rem; }
+.muted { color: #666; font-size: .9rem; }
+.bar { background: #eee; border-radius: 4px; height: 1.2rem; overflow: hidden; margin: 1rem 0; }
+.bar div { background: #3b82f6; height: 100%; width: 0; transition: width .5s; }
+table { border-collapse: collapse; width: 100%; }
+td { padding: .3rem 0; border-bottom: 1px solid #eee; }
+td:last-child { text-align: right; font-variant-numeric: tabular-nums; }
+</style>
+</head>
+<body>
+<h1 id="name">Collection progress</h1>
+<div class="muted" id="state">Connecting…</div>
+<div class="bar"><div id="bar"></div></div>
+<table>
+<tr><td>Status</td><td id="status">-</td></tr>
+<tr><td>Files</td><td id="files">-</td></tr>
+<tr><td>Failed files</td><td id="failed">-</td></tr>
+<tr><td>Chapters</td><td id="chapters">-</td></tr>
+<tr><td>Series</td><td id="series">-</td></tr>
+<tr><td>Speed</td><td id="speed">-</td></tr>
+<tr><td>ETA</td><td id="eta">-</td></tr>
+</table>
+<p class="muted">Read-only link, valid until <span id="expires">-</span>.</p>
+<script>
+const base = location.pathname.replace(/\/$/, "");
+const text = (id, value) => { document.getElementById(id).textContent = value; };
+
+function render(view) {
+  const c = view.collection || {};
+  const p = c.progress || {};
+  text("name", c.name || view.collectionId);
+  text("status", c.queuePosition > 0 ? c.status + " (queue #" + c.queuePosition + ")" : c.status);
+  text("files", (p.uploadedFiles || 0) + " / " + (p.totalFiles || 0));
+  text("failed", p.failedFiles || 0);
+  text("chapters", (p.completedChapters || 0) + " / " + (p.totalChapters || 0));
+  text("series", (p.completedObras || 0) + " / " + (p.totalObras || 0));
+  text("speed", (p.currentSpeed || 0).toFixed(1) + " files/s");
+  text("eta", p.eta || "-");
+  text("expires", new Date(view.expiresAt).toLocaleString());
+  text("state", "Updated " + new Date().toLocaleTimeString());
+  document.getElementById("bar").style.width = Math.min(p.percentage || 0, 100) + "%";
+}
+
+function connect() {
+  const ws = new WebSocket((location.protocol === "https:" ? "wss://" : "ws://") + location.host + base + "/ws");
+  ws.onmessage = (event) => render(JSON.parse(event.data));
+  ws.onclose = () => {
+    fetch(base + "/status").then((response) => {
+      if (response.status === 410) { text("state", "This link has expired."); return; }
+      if (!response.ok) { text("state", "This collection is no longer available."); return; }
+      response.json().then(render);
+      text("state", "Reconnecting…");
+      setTimeout(connect, 5000);
+    }).catch(() => { text("state", "Reconnecting…"); setTimeout(connect, 5000); });
+  };
+}
+connect();
+</script>
+</body>
+</html>
+`
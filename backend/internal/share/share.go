@@ -0,0 +1,164 @@
+// Package share gera links somente leitura e com prazo de validade para
+// acompanhar o progresso de uma coleção (um líder de equipe, por exemplo) sem
+// acesso ao servidor: o token só abre a página de status e o WebSocket de
+// progresso daquela coleção, e nenhuma ação pode ser enviada por ele.
+//
+// Os tokens são assinados com HMAC-SHA256 e não ficam guardados no servidor;
+// revogar todos os links troca a chave de assinatura.
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PathPrefix é o prefixo HTTP dos links compartilhados (/share/<token>)
+const PathPrefix = "/share/"
+
+// Prazos dos links
+const (
+	DefaultTTL = 24 * time.Hour
+	MaxTTL     = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidToken é retornado para tokens malformados ou com assinatura inválida
+	ErrInvalidToken = errors.New("invalid share token")
+	// ErrExpired é retornado para tokens válidos cujo prazo já passou
+	ErrExpired = errors.New("share link expired")
+)
+
+// Claims é o conteúdo assinado de um token
+type Claims struct {
+	CollectionID string `json:"c"`
+	ExpiresAt    int64  `json:"e"` // Unix
+}
+
+// Link é um link compartilhado recém-gerado
+type Link struct {
+	Token        string    `json:"token"`
+	Path         string    `json:"path"` // página de status; o WebSocket fica em <path>/ws
+	CollectionID string    `json:"collectionId"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// Signer emite e verifica os tokens com a chave guardada em disco
+type Signer struct {
+	path string
+
+	mutex  sync.RWMutex
+	secret []byte
+}
+
+// NewSigner carrega a chave de path, criando uma nova se o arquivo não existe
+func NewSigner(path string) (*Signer, error) {
+	s := &Signer{path: path}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		secret, decodeErr := hex.DecodeString(strings.TrimSpace(string(data)))
+		if decodeErr == nil && len(secret) >= 32 {
+			s.secret = secret
+			return s, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read share secret: %v", err)
+	}
+	if err := s.RevokeAll(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Issue gera um link para a coleção válido por ttl (DefaultTTL se zero, limitado a MaxTTL)
+func (s *Signer) Issue(collectionID string, ttl time.Duration) (Link, error) {
+	if collectionID == "" {
+		return Link{}, fmt.Errorf("collection ID is required")
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if ttl > MaxTTL {
+		return Link{}, fmt.Errorf("share links last at most %s", MaxTTL)
+	}
+
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	payload, err := json.Marshal(Claims{CollectionID: collectionID, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return Link{}, fmt.Errorf("failed to encode share token: %v", err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	token := encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded))
+
+	return Link{
+		Token:        token,
+		Path:         PathPrefix + token,
+		CollectionID: collectionID,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// Verify confere a assinatura e o prazo de um token
+func (s *Signer) Verify(token string) (Claims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return Claims{}, ErrInvalidToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.sign(encoded)) {
+		return Claims{}, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.CollectionID == "" {
+		return Claims{}, ErrInvalidToken
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return claims, ErrExpired
+	}
+	return claims, nil
+}
+
+// RevokeAll troca a chave de assinatura: todos os links emitidos deixam de valer
+func (s *Signer) RevokeAll() error {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate share secret: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create share secret directory: %v", err)
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(hex.EncodeToString(secret)), 0600); err != nil {
+		return fmt.Errorf("failed to save share secret: %v", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to save share secret: %v", err)
+	}
+
+	s.mutex.Lock()
+	s.secret = secret
+	s.mutex.Unlock()
+	return nil
+}
+
+func (s *Signer) sign(encoded string) []byte {
+	s.mutex.RLock()
+	mac := hmac.New(sha256.New, s.secret)
+	s.mutex.RUnlock()
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,232 @@
+package signedurls
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/metadata"
+)
+
+// ErrRunning é retornado quando já existe uma renovação em andamento
+var ErrRunning = errors.New("renovação de URLs assinadas já em andamento")
+
+// Signer é implementado pelos uploaders de buckets privados (S3/R2)
+type Signer interface {
+	// ResignURL gera uma nova URL assinada; false se a URL não é do bucket
+	ResignURL(rawURL string) (string, bool)
+}
+
+// Summary resume uma renovação
+type Summary struct {
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt time.Time         `json:"finishedAt"`
+	Scanned    int               `json:"scanned"`          // JSONs lidos
+	Signed     int               `json:"signed"`           // URLs assinadas encontradas
+	Renewed    int               `json:"renewed"`          // URLs reassinadas
+	Updated    map[string]int    `json:"updated"`          // obra -> URLs reassinadas
+	NextExpiry *time.Time        `json:"nextExpiry"`       // expiração mais próxima após a renovação
+	Failed     map[string]string `json:"failed,omitempty"` // JSONs que não puderam ser atualizados
+	Canceled   bool              `json:"canceled,omitempty"`
+}
+
+// Config define os parâmetros da renovação periódica
+type Config struct {
+	JSONDir     string        // diretório dos JSONs da biblioteca
+	Interval    time.Duration // intervalo entre execuções (0 = apenas manual)
+	RenewBefore time.Duration // reassina URLs que expiram dentro deste prazo
+	Lock        sync.Locker   // serializa a escrita com as edições de metadados (opcional)
+}
+
+// Refresher reassina as URLs pré-assinadas dos JSONs antes que expirem
+type Refresher struct {
+	config    Config
+	generator *metadata.JSONGenerator
+	signers   []Signer
+
+	mutex   sync.Mutex
+	running bool
+	last    *Summary
+}
+
+// NewRefresher cria o renovador de URLs assinadas
+func NewRefresher(config Config, generator *metadata.JSONGenerator, signers []Signer) *Refresher {
+	if config.RenewBefore <= 0 {
+		config.RenewBefore = 24 * time.Hour
+	}
+
+	return &Refresher{
+		config:    config,
+		generator: generator,
+		signers:   signers,
+	}
+}
+
+// Enabled indica se algum uploader gera URLs assinadas
+func (r *Refresher) Enabled() bool {
+	return len(r.signers) > 0
+}
+
+// Start executa a renovação periodicamente até o contexto ser cancelado.
+// Não faz nada sem intervalo configurado ou sem buckets privados.
+func (r *Refresher) Start(ctx context.Context, onSummary func(*Summary)) {
+	if r.config.Interval <= 0 || !r.Enabled() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				summary, err := r.RunOnce(ctx)
+				if err == nil && onSummary != nil {
+					onSummary(summary)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// LastSummary retorna o resumo da última renovação (nil se nunca executou)
+func (r *Refresher) LastSummary() *Summary {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.last
+}
+
+// RunOnce reassina as URLs prestes a expirar em todos os JSONs
+func (r *Refresher) RunOnce(ctx context.Context) (*Summary, error) {
+	if !r.begin() {
+		return nil, ErrRunning
+	}
+	return r.run(ctx), nil
+}
+
+// Trigger inicia uma renovação em background (ErrRunning se já houver uma)
+func (r *Refresher) Trigger(ctx context.Context, onSummary func(*Summary)) error {
+	if !r.begin() {
+		return ErrRunning
+	}
+
+	go func() {
+		summary := r.run(ctx)
+		if onSummary != nil {
+			onSummary(summary)
+		}
+	}()
+	return nil
+}
+
+// begin marca uma execução como em andamento; false se já havia uma
+func (r *Refresher) begin() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.running {
+		return false
+	}
+	r.running = true
+	return true
+}
+
+func (r *Refresher) run(ctx context.Context) *Summary {
+	summary := &Summary{
+		StartedAt: time.Now(),
+		Updated:   make(map[string]int),
+		Failed:    make(map[string]string),
+	}
+
+	files, err := filepath.Glob(filepath.Join(r.config.JSONDir, "*.json"))
+	if err != nil {
+		summary.Failed[r.config.JSONDir] = err.Error()
+	}
+
+	for _, file := range files {
+		if ctx.Err() != nil {
+			summary.Canceled = true
+			break
+		}
+		mangaID := metadata.LockKey(file)
+		renewed, err := r.refreshFile(file, summary)
+		summary.Scanned++
+		if err != nil {
+			summary.Failed[mangaID] = err.Error()
+			continue
+		}
+		if renewed > 0 {
+			summary.Updated[mangaID] = renewed
+			summary.Renewed += renewed
+		}
+	}
+	summary.FinishedAt = time.Now()
+
+	r.mutex.Lock()
+	r.running = false
+	r.last = summary
+	r.mutex.Unlock()
+
+	return summary
+}
+
+// refreshFile reassina as URLs de um JSON que expiram antes do prazo
+func (r *Refresher) refreshFile(jsonPath string, summary *Summary) (int, error) {
+	if r.config.Lock != nil {
+		r.config.Lock.Lock()
+		defer r.config.Lock.Unlock()
+	}
+
+	deadline := time.Now().Add(r.config.RenewBefore)
+	return r.generator.RewritePageURLs(jsonPath, func(pageURL string) string {
+		expires, signed := Expiry(pageURL)
+		if !signed {
+			return pageURL
+		}
+		summary.Signed++
+
+		if expires.Before(deadline) {
+			for _, signer := range r.signers {
+				if renewed, ok := signer.ResignURL(pageURL); ok {
+					pageURL = renewed
+					expires, _ = Expiry(renewed)
+					break
+				}
+			}
+		}
+		if summary.NextExpiry == nil || expires.Before(*summary.NextExpiry) {
+			next := expires
+			summary.NextExpiry = &next
+		}
+		return pageURL
+	})
+}
+
+// Expiry retorna quando uma URL pré-assinada (SigV4) deixa de valer;
+// false se a URL não é assinada
+func Expiry(rawURL string) (time.Time, bool) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return time.Time{}, false
+	}
+	query := parsed.Query()
+	if query.Get("X-Amz-Signature") == "" {
+		return time.Time{}, false
+	}
+	signedAt, err := time.Parse("20060102T150405Z", query.Get("X-Amz-Date"))
+	if err != nil {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return signedAt.Add(time.Duration(seconds) * time.Second), true
+}
@@ -0,0 +1,268 @@
+// Package signing assina os JSONs das obras enviados ao GitHub com uma chave
+// ed25519, para que leitores e espelhos confiram que os arquivos não foram
+// alterados depois da distribuição. A assinatura pode ir em um arquivo
+// <obra>.json.sig ao lado do JSON (detached) ou no próprio JSON, no campo
+// "signature", como um JWS com payload destacado (embedded).
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Modos de assinatura
+const (
+	ModeDetached = "detached" // <arquivo>.sig com a assinatura em base64 dos bytes do arquivo
+	ModeEmbedded = "embedded" // campo "signature" com o JWS da forma canônica do JSON
+)
+
+// SignatureSuffix é acrescentado ao nome do JSON no modo detached
+const SignatureSuffix = ".sig"
+
+// SignatureField é o campo do JSON que recebe o JWS no modo embedded
+const SignatureField = "signature"
+
+// Config configura a assinatura dos JSONs
+type Config struct {
+	PrivateKey string `json:"privateKey,omitempty"` // chave ed25519 em base64 (semente de 32 bytes ou chave de 64); vazio = JSON_SIGNING_KEY
+	Mode       string `json:"mode,omitempty"`       // detached (padrão) ou embedded
+	KeyID      string `json:"keyId,omitempty"`      // kid do cabeçalho JWS (padrão: início do SHA-256 da chave pública)
+}
+
+// Signer assina os JSONs. Um Signer nil não assina nada.
+type Signer struct {
+	key   ed25519.PrivateKey
+	mode  string
+	keyID string
+}
+
+// New cria o assinador; nil sem configuração
+func New(config *Config) (*Signer, error) {
+	if config == nil {
+		return nil, nil
+	}
+	encoded := strings.TrimSpace(config.PrivateKey)
+	if encoded == "" {
+		encoded = strings.TrimSpace(os.Getenv("JSON_SIGNING_KEY"))
+	}
+	if encoded == "" {
+		return nil, fmt.Errorf("signing privateKey is required")
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing privateKey: %v", err)
+	}
+	var key ed25519.PrivateKey
+	switch len(raw) {
+	case ed25519.SeedSize:
+		key = ed25519.NewKeyFromSeed(raw)
+	case ed25519.PrivateKeySize:
+		key = ed25519.PrivateKey(raw)
+		if !bytes.Equal(ed25519.NewKeyFromSeed(raw[:ed25519.SeedSize]), key) {
+			return nil, fmt.Errorf("invalid signing privateKey: public half does not match the seed")
+		}
+	default:
+		return nil, fmt.Errorf("invalid signing privateKey: expected %d or %d bytes of base64 ed25519 key", ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(config.Mode))
+	switch mode {
+	case "":
+		mode = ModeDetached
+	case ModeDetached, ModeEmbedded:
+	default:
+		return nil, fmt.Errorf("invalid signing mode %q (use detached or embedded)", config.Mode)
+	}
+
+	signer := &Signer{key: key, mode: mode, keyID: config.KeyID}
+	if signer.keyID == "" {
+		sum := sha256.Sum256(key.Public().(ed25519.PublicKey))
+		signer.keyID = hex.EncodeToString(sum[:8])
+	}
+	return signer, nil
+}
+
+// Mode retorna o modo de assinatura
+func (s *Signer) Mode() string {
+	return s.mode
+}
+
+// KeyID retorna o identificador da chave
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// PublicKey retorna a chave pública em base64, a ser divulgada aos leitores
+func (s *Signer) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.key.Public().(ed25519.PublicKey))
+}
+
+// SignFiles assina os JSONs (nome -> conteúdo). No modo embedded o conteúdo de
+// cada arquivo é trocado pela versão com o campo signature; no detached as
+// assinaturas são retornadas como arquivos <nome>.sig. Assinaturas ed25519 são
+// determinísticas: um JSON inalterado gera a mesma assinatura e nenhum commit.
+func (s *Signer) SignFiles(files map[string]string) (map[string]string, error) {
+	if s == nil || len(files) == 0 {
+		return nil, nil
+	}
+	if s.mode == ModeEmbedded {
+		for name, content := range files {
+			signed, err := s.Embed([]byte(content))
+			if err != nil {
+				return nil, fmt.Errorf("failed to sign %s: %v", name, err)
+			}
+			files[name] = string(signed)
+		}
+		return nil, nil
+	}
+
+	signatures := make(map[string]string, len(files))
+	for name, content := range files {
+		signatures[name+SignatureSuffix] = s.Detached([]byte(content))
+	}
+	return signatures, nil
+}
+
+// Detached retorna a assinatura em base64 dos bytes exatos do arquivo
+func (s *Signer) Detached(content []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, content)) + "\n"
+}
+
+// Embed grava no JSON o campo signature com um JWS compacto de payload
+// destacado (RFC 7515, apêndice F): o payload é a forma canônica do documento
+// sem o campo signature. O resto do arquivo é mantido como está.
+func (s *Signer) Embed(content []byte) ([]byte, error) {
+	payload, err := Canonical(content)
+	if err != nil {
+		return nil, err
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "EdDSA", "kid": s.keyID})
+	protected := base64.RawURLEncoding.EncodeToString(header)
+	signingInput := protected + "." + base64.RawURLEncoding.EncodeToString(payload)
+	jws := protected + ".." + base64.RawURLEncoding.EncodeToString(ed25519.Sign(s.key, []byte(signingInput)))
+
+	unsigned, err := withoutSignature(content)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.TrimRight(unsigned, " \t\r\n")
+	body := bytes.TrimRight(trimmed[:len(trimmed)-1], " \t\r\n")
+	field, _ := json.Marshal(jws)
+
+	var out bytes.Buffer
+	out.Write(body)
+	if !bytes.HasSuffix(body, []byte("{")) {
+		out.WriteString(",")
+	}
+	out.WriteString("\n  \"" + SignatureField + "\": ")
+	out.Write(field)
+	out.WriteString("\n}")
+	if len(trimmed) < len(unsigned) {
+		out.Write(unsigned[len(trimmed):])
+	}
+	return out.Bytes(), nil
+}
+
+// Canonical retorna a forma canônica do JSON que o modo embedded assina: sem o
+// campo signature, chaves em ordem em todos os níveis, sem espaços, sem escape
+// de HTML e com os números como estão no arquivo
+func Canonical(content []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var document map[string]interface{}
+	if err := decoder.Decode(&document); err != nil || document == nil {
+		return nil, fmt.Errorf("invalid JSON: expected an object")
+	}
+	delete(document, SignatureField)
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(out.Bytes(), "\n"), nil
+}
+
+// VerifyDetached confere a assinatura de um arquivo .sig (base64 ou crua)
+func VerifyDetached(publicKey ed25519.PublicKey, content, signature []byte) error {
+	if len(signature) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if err != nil {
+			return fmt.Errorf("invalid signature: %v", err)
+		}
+		signature = decoded
+	}
+	if len(signature) != ed25519.SignatureSize || !ed25519.Verify(publicKey, content, signature) {
+		return fmt.Errorf("signature verification failed")
+	}
+	return nil
+}
+
+// VerifyEmbedded confere o campo signature de um JSON assinado no modo embedded
+func VerifyEmbedded(publicKey ed25519.PublicKey, content []byte) error {
+	document, err := decodeObject(content)
+	if err != nil {
+		return err
+	}
+	var jws string
+	if raw, exists := document[SignatureField]; !exists || json.Unmarshal(raw, &jws) != nil {
+		return fmt.Errorf("missing %s field", SignatureField)
+	}
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return fmt.Errorf("invalid %s: expected a JWS with detached payload", SignatureField)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(rawHeader, &header) != nil || header.Alg != "EdDSA" {
+		return fmt.Errorf("invalid %s header", SignatureField)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("invalid %s: %v", SignatureField, err)
+	}
+	payload, err := Canonical(content)
+	if err != nil {
+		return err
+	}
+	signingInput := parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload)
+	if len(signature) != ed25519.SignatureSize || !ed25519.Verify(publicKey, []byte(signingInput), signature) {
+		return fmt.Errorf("signature verification failed")
+	}
+	return nil
+}
+
+// decodeObject lê o JSON como objeto, preservando o texto de cada valor
+func decodeObject(content []byte) (map[string]json.RawMessage, error) {
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if document == nil {
+		return nil, fmt.Errorf("invalid JSON: expected an object")
+	}
+	return document, nil
+}
+
+// withoutSignature remove um campo signature anterior, regravando o JSON
+// indentado; sem o campo o conteúdo volta inalterado
+func withoutSignature(content []byte) ([]byte, error) {
+	document, err := decodeObject(content)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := document[SignatureField]; !exists {
+		return content, nil
+	}
+	delete(document, SignatureField)
+	return json.MarshalIndent(document, "", "  ")
+}
@@ -0,0 +1,163 @@
+package sitegen
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxDescription é o tamanho das descrições nas tags OpenGraph; as plataformas
+// cortam textos maiores
+const maxDescription = 200
+
+// pageMeta são as tags OpenGraph/Twitter de uma página
+type pageMeta struct {
+	SiteName    string
+	Type        string // "website" ou "book"
+	Title       string
+	Description string
+	Image       string // a capa da obra, já é uma URL absoluta
+	URL         string // vazio sem BaseURL
+}
+
+// siteMap monta as URLs absolutas das páginas e registra as que entram no sitemap
+type siteMap struct {
+	config  Config
+	baseURL string
+	entries []sitemapURL
+}
+
+func newSiteMap(config Config) *siteMap {
+	base := strings.TrimSpace(config.BaseURL)
+	if base != "" && !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	return &siteMap{config: config, baseURL: base}
+}
+
+// enabled indica se há endereço público para gerar sitemap.xml e robots.txt
+func (m *siteMap) enabled() bool {
+	return m.baseURL != ""
+}
+
+// add registra a página no sitemap e retorna sua URL absoluta ("" sem BaseURL)
+func (m *siteMap) add(dir string, updated time.Time, priority string) string {
+	if !m.enabled() {
+		return ""
+	}
+	loc := m.baseURL + dir
+	entry := sitemapURL{Loc: loc, Priority: priority}
+	if !updated.IsZero() {
+		entry.LastMod = updated.Format("2006-01-02")
+	}
+	m.entries = append(m.entries, entry)
+	return loc
+}
+
+func (m *siteMap) home(library []*series) *pageMeta {
+	var updated time.Time
+	for _, manga := range library {
+		if manga.Updated.After(updated) {
+			updated = manga.Updated
+		}
+	}
+	description := m.config.Description
+	if description == "" {
+		description = fmt.Sprintf("%d obra(s) disponíveis para leitura online.", len(library))
+	}
+	return &pageMeta{
+		SiteName:    m.config.Title,
+		Type:        "website",
+		Title:       m.config.Title,
+		Description: truncate(description),
+		URL:         m.add("", updated, "1.0"),
+	}
+}
+
+func (m *siteMap) series(manga *series) *pageMeta {
+	description := manga.Description
+	if description == "" {
+		description = fmt.Sprintf("Leia %s online: %d capítulo(s).", manga.Title, len(manga.Chapters))
+	}
+	return &pageMeta{
+		SiteName:    m.config.Title,
+		Type:        "book",
+		Title:       manga.Title,
+		Description: truncate(description),
+		Image:       manga.Cover,
+		URL:         m.add(manga.ID+"/", manga.Updated, "0.8"),
+	}
+}
+
+// chapter gera as tags do leitor; só a página do grupo principal entra no
+// sitemap, as dos demais grupos apontam para ela como canônica
+func (m *siteMap) chapter(manga *series, ch *chapter, src source) *pageMeta {
+	title := fmt.Sprintf("%s — Capítulo %s", manga.Title, ch.Key)
+	if ch.Title != "" {
+		title += ": " + ch.Title
+	}
+	meta := &pageMeta{
+		SiteName:    m.config.Title,
+		Type:        "book",
+		Title:       title,
+		Description: fmt.Sprintf("Capítulo %s de %s, %d página(s).", ch.Key, manga.Title, len(src.Pages)),
+		Image:       manga.Cover,
+	}
+	dir := manga.ID + "/" + ch.Dir + "/"
+	if src.File == "index.html" {
+		meta.URL = m.add(dir, ch.Updated, "0.5")
+	} else if m.enabled() {
+		meta.URL = m.baseURL + dir
+	}
+	return meta
+}
+
+// sitemap gera o sitemap.xml com as páginas registradas
+func (m *siteMap) sitemap() []byte {
+	body, _ := xml.MarshalIndent(sitemapDocument{
+		Namespace: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs:      m.entries,
+	}, "", "  ")
+	return append([]byte(xml.Header), append(body, '\n')...)
+}
+
+// atRoot indica se o site fica na raiz do domínio; buscadores só leem o
+// robots.txt da raiz (em project pages do GitHub ele seria ignorado)
+func (m *siteMap) atRoot() bool {
+	parsed, err := url.Parse(m.baseURL)
+	return err == nil && parsed.Path == "/"
+}
+
+// robots libera o site inteiro e aponta o sitemap para os buscadores
+func (m *siteMap) robots() []byte {
+	return []byte(fmt.Sprintf("User-agent: *\nAllow: /\n\nSitemap: %ssitemap.xml\n", m.baseURL))
+}
+
+// truncate corta a descrição no limite, terminando em palavra inteira
+func truncate(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= maxDescription {
+		return text
+	}
+	runes := []rune(text)[:maxDescription]
+	cut := string(runes)
+	if space := strings.LastIndex(cut, " "); space > maxDescription/2 {
+		cut = cut[:space]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+type sitemapDocument struct {
+	XMLName   xml.Name     `xml:"urlset"`
+	Namespace string       `xml:"xmlns,attr"`
+	URLs      []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc      string `xml:"loc"`
+	LastMod  string `xml:"lastmod,omitempty"`
+	Priority string `xml:"priority,omitempty"`
+}
@@ -0,0 +1,414 @@
+package sitegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-upload/backend/internal/metadata"
+)
+
+// Config define o site gerado
+type Config struct {
+	Title       string `json:"title,omitempty"`       // Título do site (padrão "Biblioteca")
+	Description string `json:"description,omitempty"` // Descrição da página inicial (OpenGraph)
+	BaseURL     string `json:"baseUrl,omitempty"`     // Endereço público do site; necessário para sitemap.xml e og:url
+	OutputDir   string `json:"outputDir,omitempty"`   // Pasta de saída (padrão <dataDir>/site)
+	JSONDir     string `json:"-"`                     // diretório dos JSONs da biblioteca
+}
+
+// Result resume uma geração
+type Result struct {
+	OutputDir string            `json:"outputDir"`
+	Series    int               `json:"series"`
+	Chapters  int               `json:"chapters"`
+	Pages     int               `json:"pages"` // páginas HTML geradas
+	Files     []string          `json:"-"`     // caminhos relativos de todos os arquivos gerados
+	Failed    map[string]string `json:"failed,omitempty"`
+}
+
+// series é uma obra com os capítulos em ordem de leitura
+type series struct {
+	ID          string
+	Title       string
+	Description string
+	Author      string
+	Artist      string
+	Status      string
+	Cover       string
+	Updated     time.Time
+	Chapters    []*chapter
+}
+
+type chapter struct {
+	Key     string
+	Dir     string // pasta do capítulo dentro da obra
+	Title   string
+	Volume  string
+	Updated time.Time
+	Sources []source // primeiro o grupo principal, depois os demais, os espelhos e os links externos
+}
+
+// source é a lista de páginas de um grupo; File é a página HTML que o exibe.
+// Grupos com o link de uma página de leitura externa (Telegraph) viram só um
+// link: File é a própria URL e nada é renderizado para eles.
+type source struct {
+	Name     string
+	Label    string // nome exibido; espelhos aparecem como "grupo · host"
+	File     string
+	Pages    []string
+	External bool
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Generate renderiza o site a partir dos JSONs. O site é montado em uma pasta
+// temporária e só substitui o anterior quando está completo.
+func Generate(config Config) (*Result, error) {
+	if config.Title == "" {
+		config.Title = "Biblioteca"
+	}
+	if config.OutputDir == "" {
+		return nil, fmt.Errorf("output directory is required")
+	}
+
+	result := &Result{OutputDir: config.OutputDir, Failed: make(map[string]string)}
+	library := loadLibrary(config.JSONDir, result)
+
+	staging := config.OutputDir + ".tmp"
+	if err := os.RemoveAll(staging); err != nil {
+		return nil, err
+	}
+	writer := &siteWriter{root: staging, result: result}
+
+	writer.write(".nojekyll", []byte{})
+	writer.write("style.css", []byte(stylesheet))
+	site := newSiteMap(config)
+	writer.render("index.html", indexTemplate, map[string]interface{}{
+		"Title":  config.Title,
+		"Root":   "",
+		"Series": library,
+		"Meta":   site.home(library),
+	})
+	for _, manga := range library {
+		writer.render(filepath.Join(manga.ID, "index.html"), seriesTemplate, map[string]interface{}{
+			"Title":  manga.Title,
+			"Site":   config.Title,
+			"Root":   "../",
+			"Series": manga,
+			"Meta":   site.series(manga),
+		})
+		for i, ch := range manga.Chapters {
+			var previous, next *chapter
+			if i > 0 {
+				previous = manga.Chapters[i-1]
+			}
+			if i+1 < len(manga.Chapters) {
+				next = manga.Chapters[i+1]
+			}
+			for _, src := range ch.Sources {
+				if src.External {
+					continue
+				}
+				writer.render(filepath.Join(manga.ID, ch.Dir, src.File), chapterTemplate, map[string]interface{}{
+					"Title":    fmt.Sprintf("%s — Capítulo %s", manga.Title, ch.Key),
+					"Root":     "../../",
+					"Series":   manga,
+					"Chapter":  ch,
+					"Source":   src,
+					"Previous": previous,
+					"Next":     next,
+					"Meta":     site.chapter(manga, ch, src),
+				})
+			}
+			result.Chapters++
+		}
+		result.Series++
+	}
+	if site.enabled() {
+		writer.write("sitemap.xml", site.sitemap())
+		if site.atRoot() {
+			writer.write("robots.txt", site.robots())
+		}
+	}
+	if writer.err != nil {
+		os.RemoveAll(staging)
+		return nil, writer.err
+	}
+
+	if err := os.RemoveAll(config.OutputDir); err != nil {
+		return nil, fmt.Errorf("failed to replace previous site: %v", err)
+	}
+	if err := os.Rename(staging, config.OutputDir); err != nil {
+		return nil, fmt.Errorf("failed to publish site: %v", err)
+	}
+	return result, nil
+}
+
+// ReadFiles retorna o conteúdo dos arquivos gerados, indexado pelo caminho relativo
+// com barras (formato esperado pelo GitHubService)
+func (r *Result) ReadFiles() (map[string]string, error) {
+	files := make(map[string]string, len(r.Files))
+	for _, rel := range r.Files {
+		raw, err := os.ReadFile(filepath.Join(r.OutputDir, rel))
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.ToSlash(rel)] = string(raw)
+	}
+	return files, nil
+}
+
+// loadLibrary lê os JSONs; obras inválidas ou sem capítulos ficam de fora
+func loadLibrary(jsonDir string, result *Result) []*series {
+	files, err := filepath.Glob(filepath.Join(jsonDir, "*.json"))
+	if err != nil {
+		result.Failed[jsonDir] = err.Error()
+		return nil
+	}
+
+	var library []*series
+	for _, file := range files {
+		mangaID := metadata.LockKey(file)
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			result.Failed[mangaID] = err.Error()
+			continue
+		}
+		var manga metadata.MangaJSON
+		if err := json.Unmarshal(raw, &manga); err != nil {
+			result.Failed[mangaID] = fmt.Sprintf("JSON inválido: %v", err)
+			continue
+		}
+		if len(manga.Chapters) == 0 {
+			continue
+		}
+
+		entry := &series{
+			ID:          unsafeChars.ReplaceAllString(mangaID, "-"),
+			Title:       manga.Title,
+			Description: manga.Description,
+			Author:      manga.Author,
+			Artist:      manga.Artist,
+			Status:      manga.Status,
+			Cover:       manga.Cover,
+		}
+		if entry.Title == "" {
+			entry.Title = mangaID
+		}
+		for key, data := range manga.Chapters {
+			ch := newChapter(key, data)
+			if len(ch.Sources) == 0 || ch.Sources[0].External {
+				continue
+			}
+			if ch.Updated.After(entry.Updated) {
+				entry.Updated = ch.Updated
+			}
+			entry.Chapters = append(entry.Chapters, ch)
+		}
+		sort.Slice(entry.Chapters, func(i, j int) bool {
+			return chapterLess(entry.Chapters[i].Key, entry.Chapters[j].Key)
+		})
+		library = append(library, entry)
+	}
+
+	sort.Slice(library, func(i, j int) bool {
+		return strings.ToLower(library[i].Title) < strings.ToLower(library[j].Title)
+	})
+	return library
+}
+
+// newChapter ordena os grupos: grupos principais antes dos espelhos e os links
+// externos por último, em ordem alfabética
+func newChapter(key string, data metadata.Chapter) *chapter {
+	ch := &chapter{Key: key, Dir: unsafeChars.ReplaceAllString(key, "-"), Title: data.Title, Volume: data.Volume}
+	if seconds, err := strconv.ParseInt(data.LastUpdated, 10, 64); err == nil && seconds > 0 {
+		ch.Updated = time.Unix(seconds, 0).UTC()
+	}
+
+	names := make([]string, 0, len(data.Groups))
+	for name, pages := range data.Groups {
+		if len(pages) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		linkI, linkJ := metadata.IsPageLinkGroup(names[i]), metadata.IsPageLinkGroup(names[j])
+		if linkI != linkJ {
+			return !linkI
+		}
+		_, _, mirrorI := metadata.ParseMirrorGroup(names[i])
+		_, _, mirrorJ := metadata.ParseMirrorGroup(names[j])
+		if mirrorI != mirrorJ {
+			return !mirrorI
+		}
+		return names[i] < names[j]
+	})
+
+	for i, name := range names {
+		if metadata.IsPageLinkGroup(name) {
+			group, _, _ := metadata.ParseMirrorGroup(name)
+			ch.Sources = append(ch.Sources, source{Name: name, Label: group + " · Telegraph", File: data.Groups[name][0], External: true})
+			continue
+		}
+		file := "index.html"
+		if i > 0 {
+			file = fmt.Sprintf("%d.html", i+1)
+		}
+		label := name
+		if group, host, mirror := metadata.ParseMirrorGroup(name); mirror {
+			label = group + " · " + host
+		}
+		ch.Sources = append(ch.Sources, source{Name: name, Label: label, File: file, Pages: data.Groups[name]})
+	}
+	return ch
+}
+
+// chapterLess ordena capítulos numericamente quando possível ("2" antes de "10")
+func chapterLess(a, b string) bool {
+	numA, errA := strconv.ParseFloat(a, 64)
+	numB, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil && numA != numB {
+		return numA < numB
+	}
+	return a < b
+}
+
+// siteWriter grava os arquivos e guarda o primeiro erro
+type siteWriter struct {
+	root   string
+	result *Result
+	err    error
+}
+
+func (w *siteWriter) write(rel string, data []byte) {
+	if w.err != nil {
+		return
+	}
+	path := filepath.Join(w.root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		w.err = err
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		w.err = err
+		return
+	}
+	w.result.Files = append(w.result.Files, rel)
+}
+
+func (w *siteWriter) render(rel string, tmpl *template.Template, data interface{}) {
+	if w.err != nil {
+		return
+	}
+	var page strings.Builder
+	if err := tmpl.Execute(&page, data); err != nil {
+		w.err = fmt.Errorf("failed to render %s: %v", rel, err)
+		return
+	}
+	w.write(rel, []byte(page.String()))
+	w.result.Pages++
+}
+
+var templateFuncs = template.FuncMap{
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("02/01/2006")
+	},
+}
+
+const layoutHeader = `<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+{{with .Meta}}{{if .Description}}<meta name="description" content="{{.Description}}">
+{{end}}<meta property="og:site_name" content="{{.SiteName}}">
+<meta property="og:type" content="{{.Type}}">
+<meta property="og:title" content="{{.Title}}">
+{{if .Description}}<meta property="og:description" content="{{.Description}}">
+{{end}}{{if .URL}}<meta property="og:url" content="{{.URL}}">
+<link rel="canonical" href="{{.URL}}">
+{{end}}{{if .Image}}<meta property="og:image" content="{{.Image}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:image" content="{{.Image}}">
+{{else}}<meta name="twitter:card" content="summary">
+{{end}}<meta name="twitter:title" content="{{.Title}}">
+{{if .Description}}<meta name="twitter:description" content="{{.Description}}">
+{{end}}{{end}}<link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body>
+`
+
+const layoutFooter = `</body>
+</html>
+`
+
+var indexTemplate = template.Must(template.New("index").Funcs(templateFuncs).Parse(layoutHeader + `<header><h1>{{.Title}}</h1></header>
+<main class="grid">
+{{range .Series}}<a class="card" href="{{.ID}}/">
+{{if .Cover}}<img src="{{.Cover}}" alt="" loading="lazy">{{end}}
+<strong>{{.Title}}</strong>
+<span>{{len .Chapters}} capítulo(s){{with date .Updated}} · {{.}}{{end}}</span>
+</a>
+{{else}}<p>Nenhuma obra publicada.</p>
+{{end}}</main>
+` + layoutFooter))
+
+var seriesTemplate = template.Must(template.New("series").Funcs(templateFuncs).Parse(layoutHeader + `<header><a href="{{.Root}}">{{.Site}}</a><h1>{{.Series.Title}}</h1></header>
+<main class="series">
+{{with .Series}}{{if .Cover}}<img class="cover" src="{{.Cover}}" alt="">{{end}}
+<section>
+{{if .Author}}<p><b>Autor:</b> {{.Author}}</p>{{end}}
+{{if .Artist}}<p><b>Artista:</b> {{.Artist}}</p>{{end}}
+{{if .Status}}<p><b>Status:</b> {{.Status}}</p>{{end}}
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+<ul class="chapters">
+{{range .Chapters}}<li><a href="{{.Dir}}/">{{if .Volume}}Vol. {{.Volume}} · {{end}}Capítulo {{.Key}}{{if .Title}} — {{.Title}}{{end}}</a>{{with date .Updated}} <small>{{.}}</small>{{end}}</li>
+{{end}}</ul>
+</section>{{end}}
+</main>
+` + layoutFooter))
+
+var chapterTemplate = template.Must(template.New("chapter").Funcs(templateFuncs).Parse(layoutHeader + `<header><a href="../">{{.Series.Title}}</a><h1>Capítulo {{.Chapter.Key}}{{if .Chapter.Title}} — {{.Chapter.Title}}{{end}}</h1>
+{{if gt (len .Chapter.Sources) 1}}<nav class="sources">{{range .Chapter.Sources}}<a href="{{.File}}"{{if eq .Name $.Source.Name}} class="active"{{end}}>{{.Label}}</a> {{end}}</nav>{{end}}
+</header>
+<main class="reader">
+{{range .Source.Pages}}<img src="{{.}}" alt="" loading="lazy">
+{{end}}</main>
+<nav class="pager">
+{{if .Previous}}<a href="../{{.Previous.Dir}}/">← Capítulo {{.Previous.Key}}</a>{{end}}
+<a href="../">Índice</a>
+{{if .Next}}<a href="../{{.Next.Dir}}/">Capítulo {{.Next.Key}} →</a>{{end}}
+</nav>
+` + layoutFooter))
+
+const stylesheet = `body { margin: 0; font-family: system-ui, sans-serif; background: #111827; color: #e5e7eb; }
+a { color: #93c5fd; text-decoration: none; }
+header { padding: 1rem; text-align: center; }
+header h1 { margin: .5rem 0; font-size: 1.4rem; }
+.grid { display: grid; grid-template-columns: repeat(auto-fill, minmax(160px, 1fr)); gap: 1rem; padding: 1rem; }
+.card { display: flex; flex-direction: column; gap: .25rem; color: inherit; }
+.card img { width: 100%; aspect-ratio: 2 / 3; object-fit: cover; border-radius: 4px; }
+.card span, small { color: #9ca3af; font-size: .85rem; }
+.series { display: flex; flex-wrap: wrap; gap: 1.5rem; max-width: 960px; margin: 0 auto; padding: 1rem; }
+.series .cover { width: 220px; border-radius: 4px; }
+.series section { flex: 1; min-width: 260px; }
+.chapters { list-style: none; padding: 0; }
+.chapters li { padding: .4rem 0; border-bottom: 1px solid #1f2937; }
+.sources a { margin: 0 .25rem; }
+.sources a.active { font-weight: bold; text-decoration: underline; }
+.reader { display: flex; flex-direction: column; align-items: center; }
+.reader img { display: block; max-width: 100%; }
+.pager { display: flex; justify-content: center; gap: 2rem; padding: 1.5rem; }
+`
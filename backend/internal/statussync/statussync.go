@@ -0,0 +1,307 @@
+package statussync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/anilist"
+	"go-upload/backend/internal/mangaid"
+	"go-upload/backend/internal/metadata"
+	"go-upload/backend/internal/registry"
+)
+
+// ErrRunning é retornado quando já existe uma atualização em andamento
+var ErrRunning = errors.New("atualização de status já em andamento")
+
+// statusPattern localiza o valor do campo status no JSON para substituição no lugar
+var statusPattern = regexp.MustCompile(`("status"\s*:\s*)("(?:[^"\\]|\\.)*"|null)`)
+
+// Change descreve uma obra cujo status mudou no provedor
+type Change struct {
+	MangaID          string `json:"mangaId"`
+	Title            string `json:"title"`
+	AniListID        int    `json:"anilistId"`
+	OldStatus        string `json:"oldStatus"`
+	NewStatus        string `json:"newStatus"`
+	Applied          bool   `json:"applied"` // false quando o campo status está travado
+	ProviderChapters int    `json:"providerChapters,omitempty"`
+	LocalChapters    int    `json:"localChapters"`
+}
+
+// ChapterGap indica uma obra com menos capítulos locais do que o provedor informa
+type ChapterGap struct {
+	MangaID          string `json:"mangaId"`
+	Title            string `json:"title"`
+	ProviderChapters int    `json:"providerChapters"`
+	LocalChapters    int    `json:"localChapters"`
+}
+
+// Summary resume uma execução da atualização de status
+type Summary struct {
+	StartedAt   time.Time         `json:"startedAt"`
+	FinishedAt  time.Time         `json:"finishedAt"`
+	Checked     int               `json:"checked"`
+	Changes     []Change          `json:"changes"`
+	ChapterGaps []ChapterGap      `json:"chapterGaps,omitempty"`
+	Failed      map[string]string `json:"failed"`
+	Canceled    bool              `json:"canceled,omitempty"`
+}
+
+// Config define os parâmetros da atualização periódica
+type Config struct {
+	JSONDir       string               // diretório dos JSONs da biblioteca
+	Interval      time.Duration        // intervalo entre execuções (0 = apenas manual)
+	Throttle      time.Duration        // intervalo mínimo entre chamadas ao provedor
+	CheckChapters bool                 // compara também a contagem de capítulos
+	Locks         *metadata.FieldLocks // status travado é reportado mas não alterado
+	Lock          sync.Locker          // serializa a escrita com as edições de metadados (opcional)
+}
+
+// Refresher atualiza o status das obras vinculadas à AniList
+type Refresher struct {
+	config   Config
+	service  *anilist.AniListService
+	registry *registry.Registry
+
+	mutex   sync.Mutex
+	running bool
+	last    *Summary
+}
+
+// NewRefresher cria o atualizador de status
+func NewRefresher(config Config, service *anilist.AniListService, idRegistry *registry.Registry) *Refresher {
+	if config.Throttle <= 0 {
+		config.Throttle = 2 * time.Second
+	}
+
+	return &Refresher{
+		config:   config,
+		service:  service,
+		registry: idRegistry,
+	}
+}
+
+// Start executa a atualização periodicamente até o contexto ser cancelado.
+// Não faz nada se o intervalo não estiver configurado.
+func (r *Refresher) Start(ctx context.Context, onSummary func(*Summary)) {
+	if r.config.Interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				summary, err := r.RunOnce(ctx)
+				if err == nil && onSummary != nil {
+					onSummary(summary)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// LastSummary retorna o resumo da última execução (nil se nunca executou)
+func (r *Refresher) LastSummary() *Summary {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.last
+}
+
+// RunOnce verifica todas as obras vinculadas e atualiza os JSONs cujo status mudou
+func (r *Refresher) RunOnce(ctx context.Context) (*Summary, error) {
+	if !r.begin() {
+		return nil, ErrRunning
+	}
+	return r.run(ctx), nil
+}
+
+// Trigger inicia uma execução em background (ErrRunning se já houver uma)
+func (r *Refresher) Trigger(ctx context.Context, onSummary func(*Summary)) error {
+	if !r.begin() {
+		return ErrRunning
+	}
+
+	go func() {
+		summary := r.run(ctx)
+		if onSummary != nil {
+			onSummary(summary)
+		}
+	}()
+	return nil
+}
+
+// begin marca uma execução como em andamento; false se já havia uma
+func (r *Refresher) begin() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.running {
+		return false
+	}
+	r.running = true
+	return true
+}
+
+// run percorre o registro de IDs verificando cada obra vinculada
+func (r *Refresher) run(ctx context.Context) *Summary {
+	summary := &Summary{
+		StartedAt: time.Now(),
+		Changes:   []Change{},
+		Failed:    make(map[string]string),
+	}
+
+	var lastCall time.Time
+	for _, entry := range r.registry.Entries() {
+		if entry.AniListID == 0 || entry.Archived {
+			continue
+		}
+
+		// Throttle entre chamadas ao provedor
+		if wait := r.config.Throttle - time.Since(lastCall); wait > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(wait):
+			}
+		}
+		if ctx.Err() != nil {
+			summary.Canceled = true
+			break
+		}
+		lastCall = time.Now()
+
+		if err := r.checkManga(ctx, entry, summary); err != nil {
+			summary.Failed[entry.MangaID] = err.Error()
+		}
+		summary.Checked++
+	}
+	summary.FinishedAt = time.Now()
+
+	r.mutex.Lock()
+	r.running = false
+	r.last = summary
+	r.mutex.Unlock()
+
+	return summary
+}
+
+// checkManga compara o status local de uma obra com o do provedor
+func (r *Refresher) checkManga(ctx context.Context, entry registry.Entry, summary *Summary) error {
+	jsonPath := mangaid.Path(r.config.JSONDir, entry.MangaID)
+	raw, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return err
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("JSON inválido: %w", err)
+	}
+
+	details, err := r.service.GetMangaDetailsWithRetry(ctx, entry.AniListID)
+	if err != nil {
+		return err
+	}
+	media := details.Media
+
+	title, _ := data["title"].(string)
+	if title == "" {
+		title = entry.Title
+	}
+	localChapters := 0
+	if chapters, ok := data["chapters"].(map[string]interface{}); ok {
+		localChapters = len(chapters)
+	}
+	providerChapters := 0
+	if media.Chapters != nil {
+		providerChapters = *media.Chapters
+	}
+
+	if r.config.CheckChapters && providerChapters > localChapters {
+		summary.ChapterGaps = append(summary.ChapterGaps, ChapterGap{
+			MangaID:          entry.MangaID,
+			Title:            title,
+			ProviderChapters: providerChapters,
+			LocalChapters:    localChapters,
+		})
+	}
+
+	oldStatus, _ := data["status"].(string)
+	newStatus := anilist.MapAniListToMangaMetadata(media).Status
+	if newStatus == "" || newStatus == oldStatus {
+		return nil
+	}
+
+	change := Change{
+		MangaID:          entry.MangaID,
+		Title:            title,
+		AniListID:        entry.AniListID,
+		OldStatus:        oldStatus,
+		NewStatus:        newStatus,
+		ProviderChapters: providerChapters,
+		LocalChapters:    localChapters,
+	}
+
+	if !r.config.Locks.IsLocked(entry.MangaID, "status") {
+		if err := r.applyStatus(jsonPath, newStatus); err != nil {
+			return err
+		}
+		change.Applied = true
+	}
+
+	summary.Changes = append(summary.Changes, change)
+	return nil
+}
+
+// applyStatus relê o JSON e grava o novo status segurando a trava compartilhada com
+// os outros escritores. A leitura de checkManga é anterior à consulta à AniList e
+// pode estar desatualizada.
+func (r *Refresher) applyStatus(path, status string) error {
+	if r.config.Lock != nil {
+		r.config.Lock.Lock()
+		defer r.config.Lock.Unlock()
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("JSON inválido: %w", err)
+	}
+	return writeStatus(path, raw, data, status)
+}
+
+// writeStatus grava o novo status preservando a ordem dos campos quando possível
+func writeStatus(path string, raw []byte, data map[string]interface{}, status string) error {
+	encoded, _ := json.Marshal(status)
+
+	text := string(raw)
+	if loc := statusPattern.FindStringSubmatchIndex(text); loc != nil {
+		text = text[:loc[4]] + string(encoded) + text[loc[5]:]
+	}
+
+	var check map[string]interface{}
+	if json.Unmarshal([]byte(text), &check) != nil || check["status"] != status {
+		data["status"] = status
+		output, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		text = string(output)
+	}
+
+	return os.WriteFile(path, []byte(text), 0644)
+}
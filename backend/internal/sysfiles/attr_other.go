@@ -0,0 +1,8 @@
+//go:build !windows
+
+package sysfiles
+
+// hiddenAttribute não se aplica fora do Windows: arquivos ocultos são os dotfiles
+func hiddenAttribute(path string) bool {
+	return false
+}
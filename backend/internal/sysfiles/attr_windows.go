@@ -0,0 +1,16 @@
+package sysfiles
+
+import "syscall"
+
+// hiddenAttribute informa se o arquivo tem os atributos oculto ou sistema
+func hiddenAttribute(path string) bool {
+	pointer, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return false
+	}
+	attributes, err := syscall.GetFileAttributes(pointer)
+	if err != nil {
+		return false
+	}
+	return attributes&(syscall.FILE_ATTRIBUTE_HIDDEN|syscall.FILE_ATTRIBUTE_SYSTEM) != 0
+}
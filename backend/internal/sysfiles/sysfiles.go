@@ -0,0 +1,68 @@
+// Package sysfiles reconhece arquivos de sistema e ocultos que acompanham
+// bibliotecas copiadas entre sistemas operacionais (.DS_Store, Thumbs.db,
+// desktop.ini, resource forks do macOS, dotfiles) para que a descoberta, o
+// processamento de coleções e os lotes os ignorem da mesma forma.
+package sysfiles
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Rule é uma regra de arquivo de sistema, agrupada pelo sistema que o cria
+type Rule struct {
+	OS          string // windows, darwin, linux ou any
+	Name        string
+	Description string
+	match       func(name string) bool
+}
+
+// exact reconhece um nome exato, sem diferenciar maiúsculas
+func exact(names ...string) func(string) bool {
+	return func(name string) bool {
+		for _, candidate := range names {
+			if strings.EqualFold(name, candidate) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Rules lista as regras na ordem de verificação. Todas valem em qualquer
+// sistema: uma biblioteca criada no macOS e copiada para um servidor Linux
+// traz os arquivos do Finder junto.
+var Rules = []Rule{
+	{OS: "darwin", Name: "resource-fork", Description: "macOS resource fork (._*)", match: func(name string) bool { return strings.HasPrefix(name, "._") }},
+	{OS: "darwin", Name: "finder", Description: "macOS Finder metadata", match: exact(".DS_Store", ".localized", "Icon\r")},
+	{OS: "darwin", Name: "macos-volume", Description: "macOS volume folders", match: exact("__MACOSX", ".Spotlight-V100", ".Trashes", ".fseventsd", ".TemporaryItems", ".DocumentRevisions-V100", ".AppleDouble")},
+	{OS: "windows", Name: "explorer", Description: "Windows Explorer metadata", match: exact("Thumbs.db", "ehthumbs.db", "ehthumbs_vista.db", "desktop.ini")},
+	{OS: "windows", Name: "windows-volume", Description: "Windows volume folders", match: exact("$RECYCLE.BIN", "System Volume Information")},
+	{OS: "linux", Name: "linux-desktop", Description: "Linux desktop metadata", match: func(name string) bool {
+		return strings.EqualFold(name, ".directory") || strings.HasPrefix(name, ".Trash-")
+	}},
+	{OS: "any", Name: "dotfile", Description: "hidden dotfile", match: func(name string) bool { return strings.HasPrefix(name, ".") }},
+}
+
+// Match retorna a regra que reconhece o nome (só a última parte do caminho)
+func Match(name string) (Rule, bool) {
+	name = filepath.Base(filepath.FromSlash(name))
+	if name == "." || name == ".." {
+		return Rule{}, false
+	}
+	for _, rule := range Rules {
+		if rule.match(name) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Skip informa se o arquivo ou pasta em path deve ser ignorado: pelo nome ou,
+// no Windows, pelos atributos oculto/sistema
+func Skip(path string) bool {
+	if _, matched := Match(path); matched {
+		return true
+	}
+	return hiddenAttribute(path)
+}
@@ -0,0 +1,65 @@
+package tuning
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// memoryInfo lê /proc/meminfo e respeita o limite de memória do cgroup (Docker,
+// systemd MemoryMax), que o kernel não reflete no meminfo
+func memoryInfo() (total, available uint64) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total = value << 10
+		case "MemAvailable:":
+			available = value << 10
+		}
+	}
+
+	if limit := cgroupMemoryLimit(); limit > 0 && (total == 0 || limit < total) {
+		total = limit
+		if available > limit {
+			available = limit
+		}
+	}
+	return total, available
+}
+
+// cgroupMemoryLimit retorna o limite do cgroup v2 ou v1 (0 = sem limite)
+func cgroupMemoryLimit() uint64 {
+	for _, path := range []string{
+		"/sys/fs/cgroup/memory.max",
+		"/sys/fs/cgroup/memory/memory.limit_in_bytes",
+	} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		value := strings.TrimSpace(string(data))
+		if value == "max" {
+			return 0
+		}
+		if limit, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return limit
+		}
+	}
+	return 0
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !windows
+
+package tuning
+
+// memoryInfo não é medida nas demais plataformas: só CPU e rede limitam a recomendação
+func memoryInfo() (total, available uint64) {
+	return 0, 0
+}
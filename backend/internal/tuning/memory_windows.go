@@ -0,0 +1,31 @@
+package tuning
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// memoryStatusEx é a estrutura MEMORYSTATUSEX da API do Windows
+type memoryStatusEx struct {
+	length               uint32
+	memoryLoad           uint32
+	totalPhys            uint64
+	availPhys            uint64
+	totalPageFile        uint64
+	availPageFile        uint64
+	totalVirtual         uint64
+	availVirtual         uint64
+	availExtendedVirtual uint64
+}
+
+var globalMemoryStatusEx = syscall.NewLazyDLL("kernel32.dll").NewProc("GlobalMemoryStatusEx")
+
+// memoryInfo consulta GlobalMemoryStatusEx
+func memoryInfo() (total, available uint64) {
+	status := memoryStatusEx{}
+	status.length = uint32(unsafe.Sizeof(status))
+	if ok, _, _ := globalMemoryStatusEx.Call(uintptr(unsafe.Pointer(&status))); ok == 0 {
+		return 0, 0
+	}
+	return status.totalPhys, status.availPhys
+}
@@ -0,0 +1,199 @@
+// Package tuning sugere a concorrência de uploads e de descoberta a partir dos
+// recursos da máquina: núcleos, memória disponível e a vazão de upload
+// observada. Os padrões fixos (100 uploads, 20 descobertas) saturam aparelhos
+// pequenos como um Raspberry Pi; a recomendação cresce com o hardware até eles.
+package tuning
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// DefaultInterval é o intervalo da reavaliação periódica
+const DefaultInterval = 15 * time.Minute
+
+// Parâmetros da heurística
+const (
+	uploadWorkersPerCPU    = 8         // uploads esperam a rede, não a CPU
+	discoveryWorkersPerCPU = 2         // a descoberta é limitada pelo disco
+	memoryPerUploadWorker  = 8 << 20   // buffers, otimização e remoção de EXIF de uma página
+	bandwidthPerWorker     = 256 << 10 // bytes/s que justificam mais um upload simultâneo
+	memoryBudget           = 0.75      // fração da memória disponível usada pelos uploads
+	minWorkers             = 2
+)
+
+// Config é a seção "tuning" da configuração
+type Config struct {
+	Disabled bool   `json:"disabled,omitempty"` // usa os padrões fixos em vez de medir a máquina
+	Interval string `json:"interval,omitempty"` // reavaliação periódica (ex.: "15m"); "0" desliga
+}
+
+// IntervalDuration interpreta Interval (vazio = DefaultInterval)
+func (c Config) IntervalDuration() (time.Duration, error) {
+	if c.Interval == "" {
+		return DefaultInterval, nil
+	}
+	if c.Interval == "0" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid tuning interval %q: %v", c.Interval, err)
+	}
+	return interval, nil
+}
+
+// Resources são os recursos medidos da máquina
+type Resources struct {
+	CPUs            int     `json:"cpus"`
+	MemoryTotal     uint64  `json:"memoryTotal"`     // bytes (0 = desconhecida)
+	MemoryAvailable uint64  `json:"memoryAvailable"` // bytes, já limitada pelo cgroup em contêineres
+	ThroughputBPS   float64 `json:"throughputBps"`   // maior vazão de upload observada (0 = sem amostra)
+}
+
+// Recommendation é a concorrência sugerida para os recursos medidos
+type Recommendation struct {
+	MaxWorkers       int      `json:"maxWorkers"`
+	DiscoveryWorkers int      `json:"discoveryWorkers"`
+	Reasons          []string `json:"reasons,omitempty"` // o que limitou cada valor
+}
+
+// Detect mede os núcleos e a memória da máquina
+func Detect() Resources {
+	total, available := memoryInfo()
+	return Resources{
+		CPUs:            runtime.GOMAXPROCS(0),
+		MemoryTotal:     total,
+		MemoryAvailable: available,
+	}
+}
+
+// Recommend calcula a concorrência para os recursos, sem passar dos limites de ceiling
+func Recommend(resources Resources, ceiling Recommendation) Recommendation {
+	cpus := max(resources.CPUs, 1)
+	recommendation := Recommendation{Reasons: []string{}}
+
+	workers := cpus * uploadWorkersPerCPU
+	reason := fmt.Sprintf("upload workers limited by CPU (%d cores)", cpus)
+	if resources.MemoryAvailable > 0 {
+		byMemory := int(float64(resources.MemoryAvailable) * memoryBudget / memoryPerUploadWorker)
+		if byMemory < workers {
+			workers = byMemory
+			reason = fmt.Sprintf("upload workers limited by memory (%d MiB available)", resources.MemoryAvailable>>20)
+		}
+	}
+	if resources.ThroughputBPS > 0 {
+		// Uploads além do que a conexão sustenta só esperam na fila do host
+		byNetwork := 2 * int(math.Ceil(resources.ThroughputBPS/bandwidthPerWorker))
+		if byNetwork < workers {
+			workers = byNetwork
+			reason = fmt.Sprintf("upload workers limited by network (%.1f MiB/s observed)", resources.ThroughputBPS/(1<<20))
+		}
+	}
+	recommendation.MaxWorkers, reason = clamp(workers, ceiling.MaxWorkers, reason, "upload workers")
+	recommendation.Reasons = append(recommendation.Reasons, reason)
+
+	discovery := cpus * discoveryWorkersPerCPU
+	recommendation.DiscoveryWorkers, reason = clamp(discovery, ceiling.DiscoveryWorkers,
+		fmt.Sprintf("discovery workers limited by CPU (%d cores)", cpus), "discovery workers")
+	recommendation.Reasons = append(recommendation.Reasons, reason)
+	return recommendation
+}
+
+// clamp mantém value entre minWorkers e ceiling (0 = sem teto)
+func clamp(value, ceiling int, reason, name string) (int, string) {
+	if ceiling > 0 && value >= ceiling {
+		return ceiling, fmt.Sprintf("%s at the default maximum (%d)", name, ceiling)
+	}
+	if value < minWorkers {
+		return minWorkers, fmt.Sprintf("%s at the minimum (%d)", name, minWorkers)
+	}
+	return value, reason
+}
+
+// Tuner reavalia a recomendação periodicamente com a vazão observada
+type Tuner struct {
+	interval      time.Duration
+	ceiling       Recommendation
+	bytesUploaded func() int64 // total de bytes enviados desde o início
+
+	mutex          sync.Mutex
+	lastBytes      int64
+	lastSample     time.Time
+	peakThroughput float64
+	resources      Resources
+	recommendation Recommendation
+}
+
+// NewTuner cria o avaliador e já calcula a recomendação inicial (sem vazão observada)
+func NewTuner(interval time.Duration, ceiling Recommendation, bytesUploaded func() int64) *Tuner {
+	t := &Tuner{interval: interval, ceiling: ceiling, bytesUploaded: bytesUploaded}
+	t.Evaluate()
+	return t
+}
+
+// Evaluate mede os recursos novamente e atualiza a recomendação. A vazão é a
+// maior já vista entre duas avaliações com uploads: janelas ociosas não indicam
+// que a conexão ficou mais lenta.
+func (t *Tuner) Evaluate() (Resources, Recommendation) {
+	resources := Detect()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	if t.bytesUploaded != nil {
+		bytes := t.bytesUploaded()
+		if !t.lastSample.IsZero() && bytes > t.lastBytes {
+			if elapsed := now.Sub(t.lastSample).Seconds(); elapsed > 0 {
+				t.peakThroughput = max(t.peakThroughput, float64(bytes-t.lastBytes)/elapsed)
+			}
+		}
+		t.lastBytes = bytes
+		t.lastSample = now
+	}
+	resources.ThroughputBPS = t.peakThroughput
+
+	t.resources = resources
+	t.recommendation = Recommend(resources, t.ceiling)
+	return t.resources, t.recommendation
+}
+
+// Last retorna a última medição e recomendação
+func (t *Tuner) Last() (Resources, Recommendation) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.resources, t.recommendation
+}
+
+// Start reavalia a cada intervalo até ctx terminar, chamando onChange quando a
+// recomendação muda. Sem intervalo, não faz nada.
+func (t *Tuner) Start(ctx context.Context, onChange func(Resources, Recommendation)) {
+	if t.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				_, previous := t.Last()
+				resources, recommendation := t.Evaluate()
+				changed := recommendation.MaxWorkers != previous.MaxWorkers ||
+					recommendation.DiscoveryWorkers != previous.DiscoveryWorkers
+				if changed && onChange != nil {
+					onChange(resources, recommendation)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -3,35 +3,58 @@ package upload
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"go-upload/backend/internal/backup"
+	"go-upload/backend/internal/clock"
+	"go-upload/backend/internal/joblog"
+	"go-upload/backend/internal/mangaid"
+	"go-upload/backend/internal/optimize"
 	"go-upload/backend/internal/ratelimiter"
+	"go-upload/backend/internal/retry"
+	"go-upload/backend/internal/scrub"
+	"go-upload/backend/internal/urlcheck"
+	"go-upload/backend/internal/urlrules"
 	"go-upload/backend/internal/websocket"
 )
 
 // UploadRequest representa uma solicitação de upload
 type UploadRequest struct {
-	ID          string `json:"id"`
-	Host        string `json:"host"`
-	Manga       string `json:"manga"`
-	Chapter     string `json:"chapter"`
-	FileName    string `json:"fileName"`
-	FileContent string `json:"fileContent"`
-	FilePath    string `json:"filePath,omitempty"` // Para streaming de arquivos grandes
-	Priority    int    `json:"priority,omitempty"` // 0 = normal, 1 = high, 2 = urgent
+	ID          string   `json:"id"`
+	Host        string   `json:"host"`
+	Manga       string   `json:"manga"`
+	Chapter     string   `json:"chapter"`
+	FileName    string   `json:"fileName"`
+	FileContent string   `json:"fileContent"`
+	FilePath    string   `json:"filePath,omitempty"` // Para streaming de arquivos grandes
+	Priority    int      `json:"priority,omitempty"` // 0 = normal, 1 = high, 2 = urgent
+	Mirrors     []string `json:"mirrors,omitempty"`  // Hosts extras que recebem o mesmo arquivo em paralelo
+	Language    string   `json:"language,omitempty"` // Idioma do capítulo (BCP 47, ex.: pt-BR); vazio = Options.Language
+	
+	spooled     bool // FilePath é uma cópia do FileContent gravada no spool (apagada após o upload)
 }
 
 // UploadResult representa o resultado de um upload
 type UploadResult struct {
-	ID       string    `json:"id"`
-	FileName string    `json:"fileName"`
-	URL      string    `json:"url"`
-	Error    error     `json:"error,omitempty"`
-	Duration time.Duration `json:"duration"`
+	ID           string              `json:"id"`
+	FileName     string              `json:"fileName"`
+	URL          string              `json:"url"`
+	Host         string              `json:"host,omitempty"`
+	Size         int64               `json:"size,omitempty"`         // Bytes enviados (apenas em sucesso)
+	Receipt      *Receipt            `json:"receipt,omitempty"`      // Identificadores devolvidos pelo host (ID, token de remoção...)
+	Mirrors      map[string]string   `json:"mirrors,omitempty"`      // host espelho -> URL
+	Receipts     map[string]*Receipt `json:"receipts,omitempty"`     // host espelho -> recibo
+	MirrorErrors map[string]string   `json:"mirrorErrors,omitempty"` // host espelho -> erro
+	SHA256       string              `json:"sha256,omitempty"`       // Hash do arquivo enviado (com o backup ou SetHashUploads ligados)
+	Language     string              `json:"language,omitempty"`     // Idioma do capítulo (UploadRequest.Language)
+	Error        error               `json:"error,omitempty"`
+	Duration     time.Duration       `json:"duration"`
 }
 
 // BatchUploadRequest representa uma solicitação de upload em lote
@@ -40,6 +63,7 @@ type BatchUploadRequest struct {
 	Uploads   []UploadRequest `json:"uploads"`
 	Priority  int             `json:"priority,omitempty"`
 	Options   BatchOptions    `json:"options,omitempty"`
+	IdempotencyKey string     `json:"idempotencyKey,omitempty"` // Chave do cliente para evitar lotes duplicados
 }
 
 // BatchOptions configura opções para uploads em lote
@@ -50,6 +74,13 @@ type BatchOptions struct {
 	ProgressInterval  time.Duration `json:"progressInterval,omitempty"`
 	SkipExisting      bool          `json:"skipExisting,omitempty"`
 	EnableCompression bool          `json:"enableCompression,omitempty"`
+	MirrorHosts       []string      `json:"mirrorHosts,omitempty"` // Espelhamento: cada arquivo também vai para estes hosts
+	KeepMetadata      bool          `json:"keepMetadata,omitempty"` // Não remove EXIF/XMP/miniaturas antes do envio
+	Optimize          bool          `json:"optimize,omitempty"`     // Recomprime PNG/JPEG sem perdas antes do envio
+	SkipDuplicatePages bool         `json:"skipDuplicatePages,omitempty"` // Não envia páginas visualmente idênticas a outra do mesmo capítulo
+	RetryBudget       int           `json:"retryBudget,omitempty"` // Total de retries do lote (0 = um por arquivo, mínimo 10; -1 = sem limite)
+	Language          string        `json:"language,omitempty"`    // Idioma padrão dos capítulos do lote (multilíngue: um grupo por idioma no JSON)
+	IncludeHidden     bool          `json:"includeHidden,omitempty"` // Envia também arquivos de sistema e ocultos (.DS_Store, Thumbs.db, dotfiles)
 }
 
 // BatchProgress representa o progresso de um lote
@@ -64,6 +95,13 @@ type BatchProgress struct {
 	EstimatedETA time.Time `json:"estimatedEta"`
 	BytesUploaded int64    `json:"bytesUploaded"`
 	TotalBytes    int64     `json:"totalBytes"`
+	MetadataStripped      int64 `json:"metadataStripped"`      // Arquivos que tinham EXIF/XMP removidos
+	MetadataBytesRemoved  int64 `json:"metadataBytesRemoved"`  // Bytes economizados com a remoção
+	OptimizedFiles        int64 `json:"optimizedFiles"`        // Arquivos recomprimidos sem perdas
+	OptimizationBytesSaved int64 `json:"optimizationBytesSaved"` // Bytes economizados com a otimização
+	BytesTransferred      int64 `json:"bytesTransferred"`      // Bytes enviados aos hosts, incluindo retries e espelhos
+	RetryBytes            int64 `json:"retryBytes"`            // ...dos quais reenviados por retries
+	MirrorBytes           int64 `json:"mirrorBytes"`           // ...dos quais enviados aos hosts espelho
 }
 
 // UploaderInterface define a interface para uploaders
@@ -73,26 +111,103 @@ type UploaderInterface interface {
 	GetRateLimit() (int, time.Duration) // tokens per interval
 }
 
+// UploadDestination identifica um arquivo pela obra e capítulo a que pertence
+type UploadDestination struct {
+	Manga    string
+	Chapter  string
+	FileName string
+	Ctx      context.Context // interrompe o envio em andamento (cancel_batch, parada de emergência); nil = nunca
+}
+
+// Context retorna o contexto do envio, context.Background() quando não definido
+func (d UploadDestination) Context() context.Context {
+	if d.Ctx == nil {
+		return context.Background()
+	}
+	return d.Ctx
+}
+
+// DestinationUploader é implementado por hosts que organizam os arquivos em pastas
+// por obra e capítulo (ex.: WebDAV); os demais recebem apenas o caminho do arquivo
+type DestinationUploader interface {
+	UploadTo(filePath string, dest UploadDestination) (string, error)
+}
+
+// CredentialChecker é implementado por hosts que conseguem validar endereço e
+// credenciais com uma chamada de teste, sem enviar arquivos (usado pelo --doctor)
+type CredentialChecker interface {
+	CheckCredentials(ctx context.Context) error
+}
+
+// MetricsProvider é implementado por hosts que expõem métricas próprias (ex.:
+// cota restante da conta), listadas junto com o host
+type MetricsProvider interface {
+	GetMetrics() map[string]interface{}
+}
+
 // ResultCallback é chamado quando um upload completa
 type ResultCallback func(batchID string, result UploadResult)
 
+// CompletionCallback é chamado quando todos os arquivos de um lote terminaram
+type CompletionCallback func(batchID string, completed, failed, total int64)
+
+// TransferHook é chamado a cada envio de bytes a um host (retried = reenvio)
+type TransferHook func(bytes int64, retried bool)
+
 // BatchUploader gerencia uploads em lote com alta concorrência
 type BatchUploader struct {
 	uploaders      map[string]UploaderInterface
 	rateLimiters   map[string]*ratelimiter.RateLimiter
+	breakers       map[string]*hostBreaker // hosts sem circuit breaker próprio
+	clock          clock.Clock             // reposição dos rate limiters e progresso dos lotes
 	wsManager      *websocket.Manager
 	maxWorkers     int
+	activeWorkers  int64 // workers enviando um arquivo agora
 	workerPool     chan struct{}
 	pendingJobs    chan *uploadJob
 	results        chan UploadResult
 	batches        map[string]*batchState
 	batchesMu      sync.RWMutex
+	idempotencyKeys map[string]string // idempotencyKey -> batchID (protegido por batchesMu)
 	ctx            context.Context
 	cancel         context.CancelFunc
 	wg             sync.WaitGroup
 	
 	// Callback for upload results
 	resultCallback ResultCallback
+	
+	// Callback de lote concluído (notificações persistentes)
+	completionCallback CompletionCallback
+	
+	// Tráfego enviado aos hosts, para as métricas globais
+	transferHook   TransferHook
+	
+	// Diretório dos arquivos temporários de upload ("" = diretório temporário do sistema)
+	spoolDir       string
+	
+	// Sessões de envio em partes, retomadas entre tentativas e reinícios
+	chunkSessions  *ChunkStore
+	
+	// Log por lote consultado pelos clientes (nil = sem captura)
+	jobLog         *joblog.Store
+	
+	// Otimização sem perdas dos lotes com Optimize (nil = arquivos intactos)
+	optimizer      *optimize.Optimizer
+	
+	// Cópia local de cada arquivo enviado (nil = sem backup)
+	backup         *backup.Store
+	
+	// Modo de pouca memória: conteúdo base64 vai para o spool e resultados não são acumulados
+	lowMemory      bool
+	
+	// Calcula o SHA-256 de cada arquivo enviado mesmo sem backup (manifestos de checksums)
+	hashUploads    bool
+	
+	// Regras por host que trocam URLs de visualizador pela URL direta (nil = sem regras)
+	urlRewriter    *urlrules.Rewriter
+	
+	// Confere as URLs retornadas antes de irem para os resultados (nil = sem checagem)
+	urlValidator   *urlcheck.Validator
 }
 
 // batchState mantém o estado de um lote de uploads
@@ -101,6 +216,8 @@ type batchState struct {
 	progress  *BatchProgress
 	results   []UploadResult
 	startTime time.Time
+	endTime   time.Time // zero até o lote terminar
+	retryBudget *retry.Budget // Retries restantes do lote, compartilhados por arquivos e espelhos
 	mu        sync.RWMutex
 	ctx       context.Context
 	cancel    context.CancelFunc
@@ -108,12 +225,17 @@ type batchState struct {
 
 // uploadJob representa um trabalho de upload individual
 type uploadJob struct {
+	ctx         context.Context // contexto do lote: cancelado por cancel_batch e pela parada de emergência
 	request     UploadRequest
 	batchID     string
 	attempt     int
 	maxAttempts int
 	retryDelay  time.Duration
+	retryBudget *retry.Budget
 	resultChan  chan<- UploadResult
+	preparedPath string // cópia sem metadados/otimizada usada por todas as tentativas e espelhos
+	progress    *BatchProgress // contadores de tráfego do lote
+	mirror      bool           // envio para um host espelho
 }
 
 // NewBatchUploader cria um novo uploader em lote
@@ -127,12 +249,16 @@ func NewBatchUploader(wsManager *websocket.Manager, maxWorkers int) *BatchUpload
 	bu := &BatchUploader{
 		uploaders:    make(map[string]UploaderInterface),
 		rateLimiters: make(map[string]*ratelimiter.RateLimiter),
+		breakers:     make(map[string]*hostBreaker),
+		clock:        clock.Real(),
 		wsManager:    wsManager,
 		maxWorkers:   maxWorkers,
 		workerPool:   make(chan struct{}, maxWorkers),
 		pendingJobs:  make(chan *uploadJob, maxWorkers*10),
 		results:      make(chan UploadResult, maxWorkers*5),
 		batches:      make(map[string]*batchState),
+		idempotencyKeys: make(map[string]string),
+		chunkSessions: &ChunkStore{sessions: make(map[string]*ChunkSession)},
 		ctx:          ctx,
 		cancel:       cancel,
 	}
@@ -156,7 +282,94 @@ func (bu *BatchUploader) RegisterUploader(host string, uploader UploaderInterfac
 	
 	// Criar rate limiter baseado nas limitações do uploader
 	tokens, interval := uploader.GetRateLimit()
-	bu.rateLimiters[host] = ratelimiter.NewRateLimiter(tokens, interval)
+	bu.rateLimiters[host] = ratelimiter.NewRateLimiterWithClock(tokens, interval, bu.clock)
+	
+	// Circuit breaker: o do próprio host quando existe, senão um genérico
+	if own, ok := uploader.(BreakerUploader); ok {
+		delete(bu.breakers, host)
+		own.OnBreakerChange(func(status BreakerStatus) {
+			status.Host = host
+			bu.breakerChanged(status)
+		})
+	} else {
+		bu.breakers[host] = newHostBreaker(host, bu.breakerChanged)
+	}
+}
+
+// SetClock define o relógio dos rate limiters e dos relatórios de progresso;
+// os rate limiters só o usam nos hosts registrados depois
+func (bu *BatchUploader) SetClock(clk clock.Clock) {
+	bu.clock = clk
+}
+
+// WorkerStats é a ocupação dos workers de upload
+type WorkerStats struct {
+	Active int `json:"active"` // workers enviando um arquivo
+	Total  int `json:"total"`
+	Queued int `json:"queued"` // arquivos esperando um worker
+}
+
+// WorkerStats retorna a ocupação atual dos workers de upload
+func (bu *BatchUploader) WorkerStats() WorkerStats {
+	return WorkerStats{
+		Active: int(atomic.LoadInt64(&bu.activeWorkers)),
+		Total:  bu.maxWorkers,
+		Queued: len(bu.pendingJobs),
+	}
+}
+
+// HostInfo descreve um host registrado no BatchUploader
+type HostInfo struct {
+	Name         string `json:"name"`
+	RateTokens   int    `json:"rateTokens"`     // uploads permitidos por intervalo
+	RateInterval int64  `json:"rateIntervalMs"`
+	Chunked      bool   `json:"chunked"`        // envio retomável em partes
+	Destinations bool   `json:"destinations"`   // organiza os arquivos por obra/capítulo
+	Metrics      map[string]interface{} `json:"metrics,omitempty"` // métricas do próprio host (MetricsProvider)
+}
+
+// HostDetails lista os hosts registrados com limites e recursos, por nome
+func (bu *BatchUploader) HostDetails() []HostInfo {
+	hosts := make([]HostInfo, 0, len(bu.uploaders))
+	for name, uploader := range bu.uploaders {
+		tokens, interval := uploader.GetRateLimit()
+		_, chunked := uploader.(ChunkedUploader)
+		_, destinations := uploader.(DestinationUploader)
+		info := HostInfo{
+			Name:         name,
+			RateTokens:   tokens,
+			RateInterval: interval.Milliseconds(),
+			Chunked:      chunked,
+			Destinations: destinations,
+		}
+		if provider, ok := uploader.(MetricsProvider); ok {
+			info.Metrics = provider.GetMetrics()
+		}
+		hosts = append(hosts, info)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Name < hosts[j].Name })
+	return hosts
+}
+
+// HasUploader informa se há um uploader registrado para o host
+func (bu *BatchUploader) HasUploader(host string) bool {
+	_, exists := bu.uploaders[host]
+	return exists
+}
+
+// SetJobLog registra onde guardar o log de cada lote (envios, retries e erros)
+func (bu *BatchUploader) SetJobLog(store *joblog.Store) {
+	bu.jobLog = store
+}
+
+// SetOptimizer registra o otimizador usado pelos lotes com Optimize
+func (bu *BatchUploader) SetOptimizer(optimizer *optimize.Optimizer) {
+	bu.optimizer = optimizer
+}
+
+// SetBackup registra onde copiar cada arquivo enviado com sucesso
+func (bu *BatchUploader) SetBackup(store *backup.Store) {
+	bu.backup = store
 }
 
 // SetResultCallback registra um callback para resultados de upload
@@ -164,6 +377,59 @@ func (bu *BatchUploader) SetResultCallback(callback ResultCallback) {
 	bu.resultCallback = callback
 }
 
+// SetCompletionCallback registra um callback para lotes concluídos
+func (bu *BatchUploader) SetCompletionCallback(callback CompletionCallback) {
+	bu.completionCallback = callback
+}
+
+// SetTransferHook registra uma função chamada a cada envio de bytes a um host
+func (bu *BatchUploader) SetTransferHook(hook TransferHook) {
+	bu.transferHook = hook
+}
+
+// SetLowMemory ativa o modo de pouca memória: o conteúdo base64 dos lotes novos é
+// gravado no spool assim que o lote começa (em vez de ficar na requisição até o
+// upload) e os resultados de cada arquivo não são guardados no estado do lote
+func (bu *BatchUploader) SetLowMemory(enabled bool) {
+	bu.lowMemory = enabled
+}
+
+// SetHashUploads faz cada upload bem-sucedido informar o SHA-256 do conteúdo
+// enviado, mesmo com o backup desligado
+func (bu *BatchUploader) SetHashUploads(enabled bool) {
+	bu.hashUploads = enabled
+}
+
+// SetURLRewriter registra as regras aplicadas às URLs retornadas por cada host
+func (bu *BatchUploader) SetURLRewriter(rewriter *urlrules.Rewriter) {
+	bu.urlRewriter = rewriter
+}
+
+// SetURLValidator registra a checagem das URLs retornadas; uma URL recusada
+// marca o arquivo como falho, sem novas tentativas
+func (bu *BatchUploader) SetURLValidator(validator *urlcheck.Validator) {
+	bu.urlValidator = validator
+}
+
+// SetSpoolDir define onde os arquivos temporários de upload são gravados
+func (bu *BatchUploader) SetSpoolDir(dir string) error {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create spool directory: %v", err)
+		}
+	}
+	bu.spoolDir = dir
+	return nil
+}
+
+// SetChunkSessionsPath define onde as sessões de envio em partes são salvas,
+// para que uploads grandes sejam retomados mesmo após reiniciar o servidor
+func (bu *BatchUploader) SetChunkSessionsPath(path string) error {
+	store, err := NewChunkStore(path)
+	bu.chunkSessions = store
+	return err
+}
+
 // StartBatch inicia um lote de uploads
 func (bu *BatchUploader) StartBatch(req BatchUploadRequest) error {
 	// Configurar opções padrão
@@ -180,6 +446,14 @@ func (bu *BatchUploader) StartBatch(req BatchUploadRequest) error {
 		req.Options.ProgressInterval = 2 * time.Second
 	}
 	
+	if bu.lowMemory {
+		if err := bu.spoolContents(req.Uploads); err != nil {
+			// O lote não existe: a chave reservada não pode apontar para ele
+			bu.releaseIdempotencyKey(req)
+			return err
+		}
+	}
+	
 	batchCtx, batchCancel := context.WithCancel(bu.ctx)
 	
 	batch := &batchState{
@@ -189,16 +463,19 @@ func (bu *BatchUploader) StartBatch(req BatchUploadRequest) error {
 			Total:     int64(len(req.Uploads)),
 			StartTime: time.Now(),
 		},
-		results:   make([]UploadResult, 0, len(req.Uploads)),
 		startTime: time.Now(),
 		ctx:       batchCtx,
 		cancel:    batchCancel,
+		retryBudget: retry.BudgetFor(len(req.Uploads), req.Options.RetryBudget),
 	}
 	
 	bu.batchesMu.Lock()
 	bu.batches[req.ID] = batch
 	bu.batchesMu.Unlock()
 	
+	bu.jobLog.Add(req.ID, joblog.Info, "batch started: %d files, concurrency %d, up to %d retries per file",
+		len(req.Uploads), req.Options.MaxConcurrency, req.Options.RetryAttempts)
+	
 	// Calcular tamanho total estimado
 	go bu.calculateBatchSize(batch)
 	
@@ -212,17 +489,27 @@ func (bu *BatchUploader) StartBatch(req BatchUploadRequest) error {
 		for i, uploadReq := range req.Uploads {
 			select {
 			case <-batchCtx.Done():
+				removeSpooled(req.Uploads[i:])
 				return
 			case semaphore <- struct{}{}:
+				if len(uploadReq.Mirrors) == 0 {
+					uploadReq.Mirrors = req.Options.MirrorHosts
+				}
+				if uploadReq.Language == "" {
+					uploadReq.Language = req.Options.Language
+				}
 				go func(req UploadRequest, index int) {
 					defer func() { <-semaphore }()
 					
 					job := &uploadJob{
+						ctx:         batchCtx,
 						request:     req,
 						batchID:     batch.request.ID,
 						maxAttempts: batch.request.Options.RetryAttempts,
 						retryDelay:  batch.request.Options.RetryDelay,
+						retryBudget: batch.retryBudget,
 						resultChan:  bu.results,
+						progress:    batch.progress,
 					}
 					
 					bu.pendingJobs <- job
@@ -244,7 +531,9 @@ func (bu *BatchUploader) worker() {
 	for {
 		select {
 		case job := <-bu.pendingJobs:
+			atomic.AddInt64(&bu.activeWorkers, 1)
 			bu.processUploadJob(job)
+			atomic.AddInt64(&bu.activeWorkers, -1)
 		case <-bu.ctx.Done():
 			return
 		}
@@ -254,6 +543,23 @@ func (bu *BatchUploader) worker() {
 // processUploadJob processa um trabalho de upload individual
 func (bu *BatchUploader) processUploadJob(job *uploadJob) {
 	start := time.Now()
+	if job.request.spooled {
+		defer os.Remove(job.request.FilePath)
+	}
+	
+	// Ignorar trabalhos de lotes já cancelados ou removidos da memória
+	bu.batchesMu.RLock()
+	batch, exists := bu.batches[job.batchID]
+	bu.batchesMu.RUnlock()
+	if !exists || batch.ctx.Err() != nil {
+		job.resultChan <- UploadResult{
+			ID:       job.request.ID,
+			FileName: job.request.FileName,
+			Error:    websocket.Errorf(websocket.ErrCanceled, "batch canceled: %s", job.batchID),
+			Duration: time.Since(start),
+		}
+		return
+	}
 	
 	// Verificar se o uploader existe
 	uploader, exists := bu.uploaders[job.request.Host]
@@ -261,7 +567,7 @@ func (bu *BatchUploader) processUploadJob(job *uploadJob) {
 		job.resultChan <- UploadResult{
 			ID:       job.request.ID,
 			FileName: job.request.FileName,
-			Error:    fmt.Errorf("uploader not found for host: %s", job.request.Host),
+			Error:    websocket.Errorf(websocket.ErrHostUnsupported, "uploader not found for host: %s", job.request.Host),
 			Duration: time.Since(start),
 		}
 		return
@@ -269,77 +575,346 @@ func (bu *BatchUploader) processUploadJob(job *uploadJob) {
 	
 	// Aplicar rate limiting
 	rateLimiter := bu.rateLimiters[job.request.Host]
-	ctx, cancel := context.WithTimeout(bu.ctx, 30*time.Second)
+	ctx, cancel := context.WithTimeout(job.ctx, 30*time.Second)
 	defer cancel()
 	
 	if err := rateLimiter.Acquire(ctx); err != nil {
 		job.resultChan <- UploadResult{
 			ID:       job.request.ID,
 			FileName: job.request.FileName,
-			Error:    fmt.Errorf("rate limit timeout: %v", err),
+			Error:    websocket.Errorf(websocket.ErrHostRateLimited, "rate limit timeout: %v", err),
 			Duration: time.Since(start),
 		}
 		return
 	}
 	defer rateLimiter.Release()
 	
+	// Metadados e otimização são aplicados uma vez por arquivo, antes do host principal e dos espelhos
+	if cleanup := bu.preprocessFile(job, batch); cleanup != nil {
+		defer cleanup()
+	}
+	
+	// Espelhos são enviados em paralelo com o host principal
+	waitMirrors := bu.startMirrorUploads(job, start)
+	
 	// Processar upload com retry
 	result := bu.uploadWithRetry(job, uploader, start)
+	result.Mirrors, result.Receipts, result.MirrorErrors = waitMirrors()
+	if result.Error == nil {
+		result.SHA256 = bu.backupFile(job)
+		if result.SHA256 == "" && bu.hashUploads {
+			result.SHA256 = bu.hashFile(job)
+		}
+	}
 	job.resultChan <- result
 }
 
-// uploadWithRetry executa upload com retry automático
-func (bu *BatchUploader) uploadWithRetry(job *uploadJob, uploader UploaderInterface, startTime time.Time) UploadResult {
-	var lastErr error
+// uploadedFile retorna o arquivo com o conteúdo que foi para os hosts; cleanup
+// apaga a cópia temporária criada para um upload base64
+func (bu *BatchUploader) uploadedFile(job *uploadJob) (path string, cleanup func(), err error) {
+	if job.preparedPath != "" {
+		return job.preparedPath, func() {}, nil
+	}
+	prepared, err := bu.prepareFile(job.request)
+	if err != nil {
+		return "", nil, err
+	}
+	if job.request.FilePath == "" {
+		return prepared, func() { os.Remove(prepared) }, nil
+	}
+	return prepared, func() {}, nil
+}
+
+// hashFile retorna o SHA-256 do conteúdo enviado ("" se o arquivo não puder ser lido)
+func (bu *BatchUploader) hashFile(job *uploadJob) string {
+	path, cleanup, err := bu.uploadedFile(job)
+	if err != nil {
+		bu.jobLog.Add(job.batchID, joblog.Warn, "%s: checksum skipped: %v", job.request.FileName, err)
+		return ""
+	}
+	defer cleanup()
 	
-	for attempt := 0; attempt <= job.maxAttempts; attempt++ {
-		// Preparar arquivo temporário
-		tempFile, err := bu.prepareFile(job.request)
+	sum, err := backup.HashFile(path)
+	if err != nil {
+		bu.jobLog.Add(job.batchID, joblog.Warn, "%s: checksum skipped: %v", job.request.FileName, err)
+		return ""
+	}
+	return sum
+}
+
+// backupFile copia para o backup a versão do arquivo que foi para os hosts e
+// retorna o seu SHA-256 ("" sem backup ou se a cópia falhar; o upload vale igual)
+func (bu *BatchUploader) backupFile(job *uploadJob) string {
+	if bu.backup == nil {
+		return ""
+	}
+	path, cleanup, err := bu.uploadedFile(job)
+	if err != nil {
+		bu.jobLog.Add(job.batchID, joblog.Warn, "%s: backup skipped: %v", job.request.FileName, err)
+		return ""
+	}
+	defer cleanup()
+	
+	sum, err := bu.backup.Save(mangaid.Normalize(job.request.Manga), job.request.Chapter, job.request.FileName, path)
+	if err != nil {
+		bu.jobLog.Add(job.batchID, joblog.Warn, "%s: backup failed: %v", job.request.FileName, err)
+		return ""
+	}
+	return sum
+}
+
+// startMirrorUploads envia o arquivo para os hosts espelho em paralelo. A função
+// retornada aguarda os envios e retorna as URLs, os recibos e os erros por host.
+func (bu *BatchUploader) startMirrorUploads(job *uploadJob, start time.Time) func() (map[string]string, map[string]*Receipt, map[string]string) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	urls := make(map[string]string)
+	receipts := make(map[string]*Receipt)
+	errs := make(map[string]string)
+	
+	setResult := func(host, url string, receipt *Receipt, err error) {
+		mu.Lock()
+		defer mu.Unlock()
 		if err != nil {
-			return UploadResult{
-				ID:       job.request.ID,
-				FileName: job.request.FileName,
-				Error:    fmt.Errorf("failed to prepare file: %v", err),
-				Duration: time.Since(startTime),
+			errs[host] = err.Error()
+		} else {
+			urls[host] = url
+			if receipt != nil {
+				receipts[host] = receipt
 			}
 		}
+	}
+	
+	for _, host := range job.request.Mirrors {
+		if host == job.request.Host {
+			continue
+		}
+		uploader, exists := bu.uploaders[host]
+		if !exists {
+			setResult(host, "", nil, websocket.Errorf(websocket.ErrHostUnsupported, "uploader not found for host: %s", host))
+			continue
+		}
 		
-		// Tentar upload
-		url, err := uploader.Upload(tempFile)
-		os.Remove(tempFile) // Limpar arquivo temporário
-		
-		if err == nil {
-			return UploadResult{
-				ID:       job.request.ID,
-				FileName: job.request.FileName,
-				URL:      url,
-				Duration: time.Since(startTime),
+		wg.Add(1)
+		go func(host string, uploader UploaderInterface) {
+			defer wg.Done()
+			
+			rateLimiter := bu.rateLimiters[host]
+			ctx, cancel := context.WithTimeout(job.ctx, 30*time.Second)
+			defer cancel()
+			if err := rateLimiter.Acquire(ctx); err != nil {
+				setResult(host, "", nil, websocket.Errorf(websocket.ErrHostRateLimited, "rate limit timeout: %v", err))
+				return
 			}
+			defer rateLimiter.Release()
+			
+			mirrorJob := *job
+			mirrorJob.request.Host = host
+			mirrorJob.mirror = true
+			result := bu.uploadWithRetry(&mirrorJob, uploader, start)
+			setResult(host, result.URL, result.Receipt, result.Error)
+		}(host, uploader)
+	}
+	
+	return func() (map[string]string, map[string]*Receipt, map[string]string) {
+		wg.Wait()
+		if len(urls) == 0 {
+			urls = nil
+		}
+		if len(receipts) == 0 {
+			receipts = nil
+		}
+		if len(errs) == 0 {
+			errs = nil
+		}
+		return urls, receipts, errs
+	}
+}
+
+// uploadWithRetry executa upload com retry automático (backoff exponencial com
+// jitter a partir de retryDelay, limitado pelo orçamento de retries do lote)
+func (bu *BatchUploader) uploadWithRetry(job *uploadJob, uploader UploaderInterface, startTime time.Time) UploadResult {
+	var url string
+	var receipt Receipt
+	var size int64
+	retrier := &retry.Retrier{
+		Policy: retry.Policy{
+			MaxRetries: job.maxAttempts,
+			BaseDelay:  job.retryDelay,
+			Jitter:     retry.DefaultJitter,
+		},
+		Name:   "upload",
+		Budget: job.retryBudget,
+		OnRetry: func(n int, err error, delay time.Duration) {
+			bu.jobLog.Add(job.batchID, joblog.Warn, "%s: attempt %d/%d on %s failed: %v (retrying in %s)",
+				job.request.FileName, n, job.maxAttempts+1, job.request.Host, err, delay.Round(time.Millisecond))
+		},
+	}
+	
+	// O contexto do lote chega às requisições dos hosts: cancelar o lote interrompe
+	// o envio em andamento, não só os próximos
+	dest := UploadDestination{
+		Manga:    job.request.Manga,
+		Chapter:  job.request.Chapter,
+		FileName: job.request.FileName,
+		Ctx:      job.ctx,
+	}
+	attempts, err := retrier.Do(job.ctx, func(attempt int) error {
+		// Host com o circuito aberto: falha já, sem gastar retries
+		if err := bu.hostAvailable(job.request.Host); err != nil {
+			return retry.Permanent(err)
 		}
 		
-		lastErr = err
+		// Preparar arquivo temporário
+		tempFile := job.preparedPath
+		var err error
+		if tempFile == "" {
+			tempFile, err = bu.prepareFile(job.request)
+		}
+		if err != nil {
+			bu.jobLog.Add(job.batchID, joblog.Error, "%s: failed to prepare file: %v", job.request.FileName, err)
+			return retry.Permanent(websocket.Errorf(websocket.ErrIO, "failed to prepare file: %v", err))
+		}
 		
-		// Aguardar antes do retry
-		if attempt < job.maxAttempts {
-			select {
-			case <-time.After(job.retryDelay):
-			case <-bu.ctx.Done():
-				return UploadResult{
-					ID:       job.request.ID,
-					FileName: job.request.FileName,
-					Error:    bu.ctx.Err(),
-					Duration: time.Since(startTime),
-				}
-			}
+		size = 0
+		receipt = Receipt{}
+		if info, statErr := os.Stat(tempFile); statErr == nil {
+			size = info.Size()
 		}
-	}
+		
+		// Tentar upload (em partes quando o host suporta e o arquivo é grande)
+		// Envios em partes contam cada parte enviada; os demais, o arquivo inteiro por tentativa
+		if chunked, ok := uploader.(ChunkedUploader); ok && size > chunked.ChunkSize() {
+			url, receipt, err = bu.uploadChunked(job.ctx, job.request, chunked, tempFile, size, func(bytes int64, retried bool) {
+				bu.countTransfer(job, bytes, retried || attempt > 1)
+			})
+		} else if withReceipt, ok := uploader.(ReceiptUploader); ok {
+			bu.countTransfer(job, size, attempt > 1)
+			url, receipt, err = withReceipt.UploadWithReceipt(tempFile, dest)
+		} else if organized, ok := uploader.(DestinationUploader); ok {
+			bu.countTransfer(job, size, attempt > 1)
+			url, err = organized.UploadTo(tempFile, dest)
+		} else {
+			bu.countTransfer(job, size, attempt > 1)
+			url, err = uploader.Upload(tempFile)
+		}
+		if job.request.FilePath == "" && job.preparedPath == "" {
+			os.Remove(tempFile) // Limpar arquivo temporário (arquivos do usuário são mantidos)
+		}
+		if err != nil && job.ctx.Err() != nil {
+			return err // envio interrompido pelo cancelamento do lote: não é falha do host
+		}
+		bu.recordHostResult(job.request.Host, err)
+		if err != nil {
+			return err
+		}
+		
+		url = bu.urlRewriter.Rewrite(job.request.Host, url)
+		if err := bu.urlValidator.Check(job.ctx, job.request.Host, url); err != nil {
+			bu.jobLog.Add(job.batchID, joblog.Error, "%s: %s returned an unusable URL %q: %v", job.request.FileName, job.request.Host, url, err)
+			return retry.Permanent(websocket.Errorf(websocket.ErrInvalidURL, "%s returned an unusable URL: %v", job.request.Host, err))
+		}
+		return nil
+	})
 	
-	return UploadResult{
+	result := UploadResult{
 		ID:       job.request.ID,
 		FileName: job.request.FileName,
-		Error:    fmt.Errorf("upload failed after %d attempts: %v", job.maxAttempts+1, lastErr),
+		Host:     job.request.Host,
+		Language: job.request.Language,
 		Duration: time.Since(startTime),
 	}
+	switch {
+	case err == nil:
+		result.URL = url
+		result.Size = size
+		if !receipt.Empty() {
+			result.Receipt = &receipt
+		}
+	case retry.IsPermanent(err):
+		result.Error = errors.Unwrap(err)
+	case job.ctx.Err() != nil:
+		result.Error = websocket.WithCode(websocket.ErrCanceled, err)
+	case errors.Is(err, retry.ErrBudgetExhausted):
+		bu.jobLog.Add(job.batchID, joblog.Error, "%s: batch retry budget (%d) exhausted, giving up on %s",
+			job.request.FileName, job.retryBudget.Limit(), job.request.Host)
+		result.Error = websocket.Errorf(websocket.ErrUploadFailed, "upload failed after %d attempts (%v)", attempts, err)
+	default:
+		result.Error = websocket.Errorf(websocket.ErrUploadFailed, "upload failed after %d attempts: %v", attempts, err)
+	}
+	return result
+}
+
+// countTransfer soma bytes enviados a um host ao tráfego do lote e às métricas
+func (bu *BatchUploader) countTransfer(job *uploadJob, bytes int64, retried bool) {
+	if job.progress != nil {
+		atomic.AddInt64(&job.progress.BytesTransferred, bytes)
+		if retried {
+			atomic.AddInt64(&job.progress.RetryBytes, bytes)
+		}
+		if job.mirror {
+			atomic.AddInt64(&job.progress.MirrorBytes, bytes)
+		}
+	}
+	if bu.transferHook != nil {
+		bu.transferHook(bytes, retried)
+	}
+}
+
+// preprocessFile grava em job.preparedPath a versão do arquivo que vai para os
+// hosts: sem EXIF, XMP e miniaturas (exceto com KeepMetadata) e recomprimida sem
+// perdas (com Optimize). Retorna a função que apaga a cópia (nil se não houver cópia).
+func (bu *BatchUploader) preprocessFile(job *uploadJob, batch *batchState) func() {
+	if batch == nil {
+		return nil
+	}
+	options := batch.request.Options
+	if options.KeepMetadata && !options.Optimize {
+		return nil
+	}
+	path, err := bu.prepareFile(job.request)
+	if err != nil {
+		return nil // a falha é relatada pela tentativa de upload
+	}
+	
+	// Cada etapa pode gerar uma nova cópia; a anterior é apagada (nunca o arquivo do usuário)
+	advance := func(next string) {
+		if next != path && path != job.request.FilePath {
+			os.Remove(path)
+		}
+		path = next
+	}
+	
+	if !options.KeepMetadata {
+		scrubbed, removed, err := scrub.File(path, bu.spoolDir)
+		if err != nil {
+			bu.jobLog.Add(job.batchID, joblog.Warn, "%s: metadata not removed: %v", job.request.FileName, err)
+		} else {
+			advance(scrubbed)
+		}
+		if removed > 0 {
+			atomic.AddInt64(&batch.progress.MetadataStripped, 1)
+			atomic.AddInt64(&batch.progress.MetadataBytesRemoved, removed)
+		}
+	}
+	if options.Optimize {
+		optimized, saved, err := bu.optimizer.File(path, bu.spoolDir)
+		if err != nil {
+			bu.jobLog.Add(job.batchID, joblog.Warn, "%s: optimization skipped: %v", job.request.FileName, err)
+		} else {
+			advance(optimized)
+		}
+		if saved > 0 {
+			atomic.AddInt64(&batch.progress.OptimizedFiles, 1)
+			atomic.AddInt64(&batch.progress.OptimizationBytesSaved, saved)
+		}
+	}
+	
+	if path == job.request.FilePath {
+		return nil
+	}
+	job.preparedPath = path
+	return func() { os.Remove(path) }
 }
 
 // prepareFile prepara um arquivo para upload (decodifica base64 ou cria link para arquivo)
@@ -358,7 +933,7 @@ func (bu *BatchUploader) prepareFile(req UploadRequest) (string, error) {
 		return "", fmt.Errorf("failed to decode base64: %v", err)
 	}
 	
-	tmpFile, err := os.CreateTemp("", fmt.Sprintf("upload-%s-*", req.ID))
+	tmpFile, err := os.CreateTemp(bu.spoolDir, fmt.Sprintf("upload-%s-*", req.ID))
 	if err != nil {
 		return "", fmt.Errorf("failed to create temp file: %v", err)
 	}
@@ -372,6 +947,34 @@ func (bu *BatchUploader) prepareFile(req UploadRequest) (string, error) {
 	return tmpFile.Name(), nil
 }
 
+// spoolContents grava o FileContent de cada upload no spool e troca o conteúdo
+// pelo caminho, liberando a string base64 enquanto o lote espera os workers
+func (bu *BatchUploader) spoolContents(uploads []UploadRequest) error {
+	for i := range uploads {
+		if uploads[i].FilePath != "" || uploads[i].FileContent == "" {
+			continue
+		}
+		path, err := bu.prepareFile(uploads[i])
+		if err != nil {
+			removeSpooled(uploads[:i])
+			return err
+		}
+		uploads[i].FilePath = path
+		uploads[i].FileContent = ""
+		uploads[i].spooled = true
+	}
+	return nil
+}
+
+// removeSpooled apaga as cópias no spool de uploads que não vão mais ser enviados
+func removeSpooled(uploads []UploadRequest) {
+	for _, upload := range uploads {
+		if upload.spooled {
+			os.Remove(upload.FilePath)
+		}
+	}
+}
+
 // resultProcessor processa resultados de upload
 func (bu *BatchUploader) resultProcessor() {
 	defer bu.wg.Done()
@@ -412,15 +1015,27 @@ func (bu *BatchUploader) handleUploadResult(result UploadResult) {
 	}
 	
 	targetBatch.mu.Lock()
-	targetBatch.results = append(targetBatch.results, result)
+	if !bu.lowMemory {
+		targetBatch.results = append(targetBatch.results, result)
+	}
 	
 	if result.Error != nil {
 		atomic.AddInt64(&targetBatch.progress.Failed, 1)
 	} else {
 		atomic.AddInt64(&targetBatch.progress.Completed, 1)
+		atomic.AddInt64(&targetBatch.progress.BytesUploaded, result.Size)
 	}
 	targetBatch.mu.Unlock()
 	
+	if result.Error != nil {
+		bu.jobLog.Add(batchID, joblog.Error, "%s: %v", result.FileName, result.Error)
+	} else {
+		bu.jobLog.Add(batchID, joblog.Info, "%s: uploaded to %s in %s", result.FileName, result.Host, result.Duration.Round(time.Millisecond))
+	}
+	for host, mirrorErr := range result.MirrorErrors {
+		bu.jobLog.Add(batchID, joblog.Warn, "%s: mirror %s failed: %s", result.FileName, host, mirrorErr)
+	}
+	
 	// Enviar resultado individual para WebSocket
 	bu.sendUploadResult(batchID, result)
 	
@@ -435,12 +1050,12 @@ func (bu *BatchUploader) handleUploadResult(result UploadResult) {
 
 // progressReporter envia atualizações de progresso
 func (bu *BatchUploader) progressReporter(batch *batchState) {
-	ticker := time.NewTicker(batch.request.Options.ProgressInterval)
+	ticker := bu.clock.NewTicker(batch.request.Options.ProgressInterval)
 	defer ticker.Stop()
 	
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			bu.sendProgressUpdate(batch)
 		case <-batch.ctx.Done():
 			return
@@ -455,6 +1070,14 @@ func (bu *BatchUploader) sendProgressUpdate(batch *batchState) {
 	progress.Completed = atomic.LoadInt64(&batch.progress.Completed)
 	progress.Failed = atomic.LoadInt64(&batch.progress.Failed)
 	progress.Skipped = atomic.LoadInt64(&batch.progress.Skipped)
+	progress.MetadataStripped = atomic.LoadInt64(&batch.progress.MetadataStripped)
+	progress.MetadataBytesRemoved = atomic.LoadInt64(&batch.progress.MetadataBytesRemoved)
+	progress.OptimizedFiles = atomic.LoadInt64(&batch.progress.OptimizedFiles)
+	progress.OptimizationBytesSaved = atomic.LoadInt64(&batch.progress.OptimizationBytesSaved)
+	progress.BytesUploaded = atomic.LoadInt64(&batch.progress.BytesUploaded)
+	progress.BytesTransferred = atomic.LoadInt64(&batch.progress.BytesTransferred)
+	progress.RetryBytes = atomic.LoadInt64(&batch.progress.RetryBytes)
+	progress.MirrorBytes = atomic.LoadInt64(&batch.progress.MirrorBytes)
 	
 	// Calcular ETA
 	elapsed := time.Since(batch.startTime)
@@ -495,6 +1118,7 @@ func (bu *BatchUploader) sendUploadResult(batchID string, result UploadResult) {
 	
 	if result.Error != nil {
 		response.Error = result.Error.Error()
+		response.ErrorCode = websocket.CodeOf(result.Error)
 	}
 	
 	bu.wsManager.Broadcast(response)
@@ -530,6 +1154,9 @@ func (bu *BatchUploader) checkBatchCompletion(batch *batchState) {
 	
 	if completed+failed >= total {
 		// Lote completado
+		batch.mu.Lock()
+		batch.endTime = time.Now()
+		batch.mu.Unlock()
 		batch.cancel()
 		
 		// Enviar notificação final
@@ -546,21 +1173,52 @@ func (bu *BatchUploader) checkBatchCompletion(batch *batchState) {
 				"failed":    failed,
 				"total":     total,
 				"duration":  time.Since(batch.startTime).String(),
+				"metadataBytesRemoved": atomic.LoadInt64(&batch.progress.MetadataBytesRemoved),
+				"optimizationBytesSaved": atomic.LoadInt64(&batch.progress.OptimizationBytesSaved),
+				"bytesTransferred": atomic.LoadInt64(&batch.progress.BytesTransferred),
+				"retryBytes": atomic.LoadInt64(&batch.progress.RetryBytes),
+				"mirrorBytes": atomic.LoadInt64(&batch.progress.MirrorBytes),
 			},
 		}
 		
 		bu.wsManager.Broadcast(response)
+		if bu.completionCallback != nil {
+			bu.completionCallback(batch.request.ID, completed, failed, total)
+		}
+		
+		level := joblog.Info
+		if failed > 0 {
+			level = joblog.Warn
+		}
+		bu.jobLog.Add(batch.request.ID, level, "batch finished: %d uploaded, %d failed of %d in %s",
+			completed, failed, total, time.Since(batch.startTime).Round(time.Second))
 		
 		// Remover lote da memória após um tempo
 		go func() {
 			time.Sleep(5 * time.Minute)
 			bu.batchesMu.Lock()
-			delete(bu.batches, batch.request.ID)
+			if bu.batches[batch.request.ID] == batch {
+				bu.removeBatchLocked(batch)
+			}
 			bu.batchesMu.Unlock()
 		}()
 	}
 }
 
+// ReserveIdempotencyKey associa uma chave de idempotência a um novo lote.
+// Se a chave já pertence a outro lote, retorna o ID existente e false.
+func (bu *BatchUploader) ReserveIdempotencyKey(key, batchID string) (string, bool) {
+	bu.batchesMu.Lock()
+	defer bu.batchesMu.Unlock()
+	
+	if existingID, exists := bu.idempotencyKeys[key]; exists {
+		return existingID, false
+	}
+	
+	bu.idempotencyKeys[key] = batchID
+	return batchID, true
+}
+
 // CancelBatch cancela um lote em andamento
 func (bu *BatchUploader) CancelBatch(batchID string) error {
 	bu.batchesMu.RLock()
@@ -572,9 +1230,28 @@ func (bu *BatchUploader) CancelBatch(batchID string) error {
 	}
 	
 	batch.cancel()
+	bu.jobLog.Add(batchID, joblog.Warn, "batch canceled")
 	return nil
 }
 
+// CancelAll cancela todos os lotes em andamento e retorna seus IDs
+func (bu *BatchUploader) CancelAll() []string {
+	bu.batchesMu.RLock()
+	defer bu.batchesMu.RUnlock()
+	
+	canceled := make([]string, 0, len(bu.batches))
+	for id, batch := range bu.batches {
+		if batch.ctx.Err() != nil {
+			continue
+		}
+		batch.cancel()
+		bu.jobLog.Add(id, joblog.Warn, "batch canceled by emergency stop")
+		canceled = append(canceled, id)
+	}
+	
+	return canceled
+}
+
 // GetBatchStatus retorna o status de um lote
 func (bu *BatchUploader) GetBatchStatus(batchID string) (*BatchProgress, error) {
 	bu.batchesMu.RLock()
@@ -590,11 +1267,147 @@ func (bu *BatchUploader) GetBatchStatus(batchID string) (*BatchProgress, error)
 	progress.Completed = atomic.LoadInt64(&batch.progress.Completed)
 	progress.Failed = atomic.LoadInt64(&batch.progress.Failed)
 	progress.Skipped = atomic.LoadInt64(&batch.progress.Skipped)
+	progress.MetadataStripped = atomic.LoadInt64(&batch.progress.MetadataStripped)
+	progress.MetadataBytesRemoved = atomic.LoadInt64(&batch.progress.MetadataBytesRemoved)
+	progress.OptimizedFiles = atomic.LoadInt64(&batch.progress.OptimizedFiles)
+	progress.OptimizationBytesSaved = atomic.LoadInt64(&batch.progress.OptimizationBytesSaved)
+	progress.BytesUploaded = atomic.LoadInt64(&batch.progress.BytesUploaded)
+	progress.BytesTransferred = atomic.LoadInt64(&batch.progress.BytesTransferred)
+	progress.RetryBytes = atomic.LoadInt64(&batch.progress.RetryBytes)
+	progress.MirrorBytes = atomic.LoadInt64(&batch.progress.MirrorBytes)
 	batch.mu.RUnlock()
 	
 	return &progress, nil
 }
 
+// BatchSummary resume um lote para listagens
+type BatchSummary struct {
+	Progress    BatchProgress `json:"progress"`
+	State       string        `json:"state"` // running, canceled ou completed
+	Priority    int           `json:"priority,omitempty"`
+	Hosts       []string      `json:"hosts"`
+	MirrorHosts []string      `json:"mirrorHosts,omitempty"`
+}
+
+// ListBatches retorna os lotes em memória (em andamento ou concluídos há
+// menos de 5 minutos), dos mais recentes aos mais antigos
+func (bu *BatchUploader) ListBatches() []BatchSummary {
+	bu.batchesMu.RLock()
+	defer bu.batchesMu.RUnlock()
+	
+	summaries := make([]BatchSummary, 0, len(bu.batches))
+	for _, batch := range bu.batches {
+		batch.mu.RLock()
+		summary := BatchSummary{
+			Progress:    *batch.progress,
+			Priority:    batch.request.Priority,
+			MirrorHosts: batch.request.Options.MirrorHosts,
+		}
+		batch.mu.RUnlock()
+		summary.Progress.Completed = atomic.LoadInt64(&batch.progress.Completed)
+		summary.Progress.Failed = atomic.LoadInt64(&batch.progress.Failed)
+		summary.Progress.Skipped = atomic.LoadInt64(&batch.progress.Skipped)
+		summary.Progress.MetadataStripped = atomic.LoadInt64(&batch.progress.MetadataStripped)
+		summary.Progress.MetadataBytesRemoved = atomic.LoadInt64(&batch.progress.MetadataBytesRemoved)
+		summary.Progress.OptimizedFiles = atomic.LoadInt64(&batch.progress.OptimizedFiles)
+		summary.Progress.OptimizationBytesSaved = atomic.LoadInt64(&batch.progress.OptimizationBytesSaved)
+		summary.Progress.BytesUploaded = atomic.LoadInt64(&batch.progress.BytesUploaded)
+		summary.Progress.BytesTransferred = atomic.LoadInt64(&batch.progress.BytesTransferred)
+		summary.Progress.RetryBytes = atomic.LoadInt64(&batch.progress.RetryBytes)
+		summary.Progress.MirrorBytes = atomic.LoadInt64(&batch.progress.MirrorBytes)
+		
+		hosts := make(map[string]bool)
+		for _, upload := range batch.request.Uploads {
+			if !hosts[upload.Host] {
+				hosts[upload.Host] = true
+				summary.Hosts = append(summary.Hosts, upload.Host)
+			}
+		}
+		sort.Strings(summary.Hosts)
+		
+		switch {
+		case summary.Progress.Completed+summary.Progress.Failed >= summary.Progress.Total:
+			summary.State = "completed"
+		case batch.ctx.Err() != nil:
+			summary.State = "canceled"
+		default:
+			summary.State = "running"
+		}
+		summaries = append(summaries, summary)
+	}
+	
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Progress.StartTime.After(summaries[j].Progress.StartTime)
+	})
+	return summaries
+}
+
+// RemoveBatch tira um lote da memória, cancelando-o se ainda está em andamento
+func (bu *BatchUploader) RemoveBatch(batchID string) error {
+	bu.batchesMu.Lock()
+	batch, exists := bu.batches[batchID]
+	if exists {
+		bu.removeBatchLocked(batch)
+	}
+	bu.batchesMu.Unlock()
+	
+	if !exists {
+		return fmt.Errorf("batch not found: %s", batchID)
+	}
+	if batch.ctx.Err() == nil {
+		batch.cancel()
+		bu.jobLog.Add(batchID, joblog.Warn, "batch canceled and removed from memory")
+	}
+	return nil
+}
+
+// PurgeBatches tira da memória os lotes concluídos ou cancelados há mais de
+// olderThan e retorna seus IDs. Lotes em andamento nunca são removidos aqui.
+func (bu *BatchUploader) PurgeBatches(olderThan time.Duration) []string {
+	cutoff := time.Now().Add(-olderThan)
+	
+	bu.batchesMu.Lock()
+	defer bu.batchesMu.Unlock()
+	
+	purged := []string{}
+	for id, batch := range bu.batches {
+		if batch.ctx.Err() == nil {
+			continue
+		}
+		batch.mu.RLock()
+		finished := batch.endTime
+		batch.mu.RUnlock()
+		if finished.IsZero() {
+			// Cancelado antes de terminar
+			finished = batch.startTime
+		}
+		if finished.Before(cutoff) {
+			bu.removeBatchLocked(batch)
+			purged = append(purged, id)
+		}
+	}
+	sort.Strings(purged)
+	return purged
+}
+
+// releaseIdempotencyKey libera a chave de um lote que não chegou a iniciar
+func (bu *BatchUploader) releaseIdempotencyKey(req BatchUploadRequest) {
+	bu.batchesMu.Lock()
+	defer bu.batchesMu.Unlock()
+	
+	if key := req.IdempotencyKey; key != "" && bu.idempotencyKeys[key] == req.ID {
+		delete(bu.idempotencyKeys, key)
+	}
+}
+
+// removeBatchLocked apaga um lote e sua chave de idempotência (chamado com batchesMu)
+func (bu *BatchUploader) removeBatchLocked(batch *batchState) {
+	delete(bu.batches, batch.request.ID)
+	if key := batch.request.IdempotencyKey; key != "" && bu.idempotencyKeys[key] == batch.request.ID {
+		delete(bu.idempotencyKeys, key)
+	}
+}
+
 // Close fecha o uploader em lote
 func (bu *BatchUploader) Close() {
 	bu.cancel()
@@ -0,0 +1,205 @@
+package upload
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/websocket"
+)
+
+// Padrões do circuit breaker dos hosts sem breaker próprio
+const (
+	breakerThreshold = 5                // falhas seguidas que abrem o circuito
+	breakerCooldown  = 60 * time.Second // tempo aberto antes do envio de teste
+)
+
+// BreakerState é o estado do circuit breaker de um host
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // envios normais
+	BreakerOpen     BreakerState = "open"      // envios recusados até RetryAt
+	BreakerHalfOpen BreakerState = "half_open" // envio de teste em andamento
+)
+
+// BreakerStatus descreve o circuit breaker de um host
+type BreakerStatus struct {
+	Host      string       `json:"host"`
+	State     BreakerState `json:"state"`
+	Failures  int          `json:"failures"`            // falhas seguidas
+	Since     time.Time    `json:"since"`               // última mudança de estado (zero se nunca mudou)
+	RetryAt   *time.Time   `json:"retryAt,omitempty"`   // quando o circuito aberto aceita um envio de teste
+	LastError string       `json:"lastError,omitempty"` // última falha registrada
+}
+
+// BreakerUploader é implementado por hosts com circuit breaker próprio (ex.:
+// Catbox); os demais recebem um breaker do BatchUploader
+type BreakerUploader interface {
+	BreakerStatus() BreakerStatus
+	OnBreakerChange(listener func(BreakerStatus))
+}
+
+// hostBreaker abre o circuito de um host após breakerThreshold falhas seguidas
+type hostBreaker struct {
+	host      string
+	state     BreakerState
+	failures  int
+	since     time.Time
+	openUntil time.Time
+	lastError string
+	onChange  func(BreakerStatus)
+	mu        sync.Mutex
+}
+
+func newHostBreaker(host string, onChange func(BreakerStatus)) *hostBreaker {
+	return &hostBreaker{host: host, state: BreakerClosed, onChange: onChange}
+}
+
+// allow informa se um envio pode acontecer; passado o cooldown, o circuito
+// aberto deixa passar um envio de teste (meio aberto)
+func (hb *hostBreaker) allow() bool {
+	hb.mu.Lock()
+	if hb.state != BreakerOpen {
+		hb.mu.Unlock()
+		return true
+	}
+	if time.Now().Before(hb.openUntil) {
+		hb.mu.Unlock()
+		return false
+	}
+	status := hb.setStateLocked(BreakerHalfOpen)
+	hb.mu.Unlock()
+	hb.notify(status)
+	return true
+}
+
+// record registra o resultado de um envio
+func (hb *hostBreaker) record(err error) {
+	hb.mu.Lock()
+	var status *BreakerStatus
+	if err == nil {
+		hb.failures = 0
+		if hb.state != BreakerClosed {
+			status = hb.setStateLocked(BreakerClosed)
+		}
+	} else {
+		hb.failures++
+		hb.lastError = err.Error()
+		if hb.state == BreakerHalfOpen || (hb.state == BreakerClosed && hb.failures >= breakerThreshold) {
+			hb.openUntil = time.Now().Add(breakerCooldown)
+			status = hb.setStateLocked(BreakerOpen)
+		}
+	}
+	hb.mu.Unlock()
+	hb.notify(status)
+}
+
+// status retorna o estado atual
+func (hb *hostBreaker) status() BreakerStatus {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	return hb.statusLocked()
+}
+
+func (hb *hostBreaker) statusLocked() BreakerStatus {
+	status := BreakerStatus{
+		Host:      hb.host,
+		State:     hb.state,
+		Failures:  hb.failures,
+		Since:     hb.since,
+		LastError: hb.lastError,
+	}
+	if hb.state == BreakerOpen {
+		retryAt := hb.openUntil
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
+func (hb *hostBreaker) setStateLocked(state BreakerState) *BreakerStatus {
+	hb.state = state
+	hb.since = time.Now()
+	status := hb.statusLocked()
+	return &status
+}
+
+// notify avisa a mudança de estado fora do mutex
+func (hb *hostBreaker) notify(status *BreakerStatus) {
+	if status != nil && hb.onChange != nil {
+		hb.onChange(*status)
+	}
+}
+
+// hostAvailable recusa envios para hosts com o circuito aberto
+func (bu *BatchUploader) hostAvailable(host string) error {
+	if breaker := bu.breakers[host]; breaker != nil {
+		if !breaker.allow() {
+			status := breaker.status()
+			return websocket.Errorf(websocket.ErrHostUnavailable, "host %s unavailable after %d consecutive failures (retry at %s)",
+				host, status.Failures, status.RetryAt.Format(time.RFC3339))
+		}
+		return nil
+	}
+	if own, ok := bu.uploaders[host].(BreakerUploader); ok {
+		status := own.BreakerStatus()
+		if status.State == BreakerOpen && status.RetryAt != nil && time.Now().Before(*status.RetryAt) {
+			return websocket.Errorf(websocket.ErrHostUnavailable, "host %s unavailable (circuit open until %s)",
+				host, status.RetryAt.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// recordHostResult alimenta o breaker do host (hosts com breaker próprio contam sozinhos)
+func (bu *BatchUploader) recordHostResult(host string, err error) {
+	if breaker := bu.breakers[host]; breaker != nil {
+		breaker.record(err)
+	}
+}
+
+// HostBreakers lista o circuit breaker de cada host registrado, por nome
+func (bu *BatchUploader) HostBreakers() []BreakerStatus {
+	statuses := make([]BreakerStatus, 0, len(bu.uploaders))
+	for host, uploader := range bu.uploaders {
+		var status BreakerStatus
+		if breaker := bu.breakers[host]; breaker != nil {
+			status = breaker.status()
+		} else if own, ok := uploader.(BreakerUploader); ok {
+			status = own.BreakerStatus()
+			status.Host = host
+		} else {
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Host < statuses[j].Host })
+	return statuses
+}
+
+// breakerChanged avisa os clientes quando um host para de aceitar envios
+// (host_degraded) ou volta a funcionar (host_recovered)
+func (bu *BatchUploader) breakerChanged(status BreakerStatus) {
+	var event string
+	switch status.State {
+	case BreakerOpen:
+		event = "host_degraded"
+	case BreakerClosed:
+		event = "host_recovered"
+	default:
+		return
+	}
+	if bu.wsManager == nil {
+		return
+	}
+	response := websocket.Response{
+		Status: event,
+		Data:   status,
+	}
+	if status.State == BreakerOpen {
+		response.ErrorCode = websocket.ErrHostUnavailable
+		response.Error = fmt.Sprintf("host %s unavailable after %d consecutive failures", status.Host, status.Failures)
+	}
+	bu.wsManager.Broadcast(response)
+}
@@ -0,0 +1,268 @@
+package upload
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// chunkRetries é o número de novas tentativas de cada parte antes de desistir do envio
+const chunkRetries = 4
+
+// chunkRetryDelay é o atraso inicial entre tentativas de uma parte (dobra a cada falha)
+const chunkRetryDelay = time.Second
+
+// chunkSessionTTL descarta sessões antigas; os hosts expiram envios incompletos
+const chunkSessionTTL = 24 * time.Hour
+
+// ChunkPart é uma parte já recebida pelo host
+type ChunkPart struct {
+	Number int    `json:"number"`
+	Size   int64  `json:"size"`
+	ETag   string `json:"etag,omitempty"` // exigido para concluir envios S3 multipart
+}
+
+// ChunkSession é um envio em partes em andamento, persistido para ser retomado
+// após falhas de rede ou reinício do servidor
+type ChunkSession struct {
+	Key       string          `json:"key"`
+	Host      string          `json:"host"`
+	ID        string          `json:"id"`               // URL do upload tus ou uploadId do S3
+	Object    string          `json:"object,omitempty"` // chave do objeto (S3) ou caminho de destino (WebDAV)
+	Size      int64           `json:"size"`
+	Offset    int64           `json:"offset"` // bytes confirmados pelo host
+	Parts     []ChunkPart     `json:"parts,omitempty"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	Receipt   Receipt         `json:"-"` // identificadores preenchidos por FinishChunked
+	Ctx       context.Context `json:"-"` // contexto do lote para as requisições das partes (nil = nunca cancela)
+}
+
+// Context retorna o contexto das requisições da sessão, context.Background() quando não definido
+func (s *ChunkSession) Context() context.Context {
+	if s.Ctx == nil {
+		return context.Background()
+	}
+	return s.Ctx
+}
+
+// ChunkedUploader é implementado por hosts que aceitam envio em partes retomável
+// (tus, S3 multipart). Arquivos maiores que ChunkSize são enviados parte a parte,
+// com retry por parte, em vez de recomeçar do zero a cada falha.
+type ChunkedUploader interface {
+	ChunkSize() int64
+	BeginChunked(filePath string, size int64) (*ChunkSession, error)
+	ResumeChunked(session *ChunkSession) error            // sincroniza Offset/Parts com o host
+	UploadChunk(session *ChunkSession, data []byte) error // envia a partir de Offset e avança
+	FinishChunked(session *ChunkSession) (string, error)  // retorna a URL final (e preenche session.Receipt, se houver)
+}
+
+// DestinationChunkedUploader é implementado por hosts em partes que organizam os
+// arquivos por obra e capítulo (ex.: WebDAV); o envio começa por BeginChunkedTo
+// no lugar de BeginChunked
+type DestinationChunkedUploader interface {
+	BeginChunkedTo(filePath string, size int64, dest UploadDestination) (*ChunkSession, error)
+}
+
+// ChunkStore guarda as sessões de envio em partes em um arquivo JSON
+// (caminho vazio = apenas em memória)
+type ChunkStore struct {
+	path     string
+	sessions map[string]*ChunkSession
+	mutex    sync.Mutex
+}
+
+// NewChunkStore carrega as sessões salvas, descartando as expiradas
+func NewChunkStore(path string) (*ChunkStore, error) {
+	store := &ChunkStore{path: path, sessions: make(map[string]*ChunkSession)}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return store, fmt.Errorf("failed to read chunk sessions: %v", err)
+	}
+	if err := json.Unmarshal(data, &store.sessions); err != nil {
+		store.sessions = make(map[string]*ChunkSession)
+		return store, fmt.Errorf("failed to parse chunk sessions: %v", err)
+	}
+	for key, session := range store.sessions {
+		if time.Since(session.UpdatedAt) > chunkSessionTTL {
+			delete(store.sessions, key)
+		}
+	}
+	return store, nil
+}
+
+// Get retorna uma cópia da sessão salva (nil se não houver)
+func (cs *ChunkStore) Get(key string) *ChunkSession {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	session, exists := cs.sessions[key]
+	if !exists || time.Since(session.UpdatedAt) > chunkSessionTTL {
+		return nil
+	}
+	copied := *session
+	copied.Parts = append([]ChunkPart(nil), session.Parts...)
+	return &copied
+}
+
+// Put salva o progresso de uma sessão
+func (cs *ChunkStore) Put(session *ChunkSession) error {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	copied := *session
+	copied.Parts = append([]ChunkPart(nil), session.Parts...)
+	copied.UpdatedAt = time.Now()
+	cs.sessions[session.Key] = &copied
+	return cs.save()
+}
+
+// Delete remove uma sessão concluída
+func (cs *ChunkStore) Delete(key string) error {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	delete(cs.sessions, key)
+	return cs.save()
+}
+
+// save grava as sessões (chamado com o mutex travado)
+func (cs *ChunkStore) save() error {
+	if cs.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(cs.sessions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode chunk sessions: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cs.path), 0755); err != nil {
+		return fmt.Errorf("failed to create chunk sessions directory: %v", err)
+	}
+	tmpPath := cs.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write chunk sessions: %v", err)
+	}
+	return os.Rename(tmpPath, cs.path)
+}
+
+// chunkSessionKey identifica o envio de um arquivo para um host. Arquivos do usuário
+// são identificados pelo caminho, tamanho e data; conteúdo enviado pelo cliente, pelo ID.
+func chunkSessionKey(req UploadRequest, filePath string, size int64) string {
+	if req.FilePath != "" {
+		modTime := int64(0)
+		if info, err := os.Stat(filePath); err == nil {
+			modTime = info.ModTime().UnixNano()
+		}
+		if absPath, err := filepath.Abs(filePath); err == nil {
+			filePath = absPath
+		}
+		return fmt.Sprintf("%s|%s|%d|%d", req.Host, filePath, size, modTime)
+	}
+	return fmt.Sprintf("%s|id:%s|%d", req.Host, req.ID, size)
+}
+
+// uploadChunked envia um arquivo em partes, retomando a sessão salva quando existir.
+// Cada parte tem seus próprios retries; se mesmo assim falhar, a sessão fica salva
+// e a próxima tentativa do arquivo continua do último byte confirmado. count recebe
+// o tamanho de cada parte enviada (retried nos reenvios de uma parte que falhou).
+func (bu *BatchUploader) uploadChunked(ctx context.Context, req UploadRequest, uploader ChunkedUploader, filePath string, size int64, count TransferHook) (string, Receipt, error) {
+	key := chunkSessionKey(req, filePath, size)
+
+	session := bu.chunkSessions.Get(key)
+	if session != nil {
+		session.Ctx = ctx
+		if err := uploader.ResumeChunked(session); err != nil {
+			log.Printf("⚠️ Chunked upload of %s cannot be resumed, restarting: %v", req.FileName, err)
+			session = nil
+		} else {
+			log.Printf("🔁 Resuming chunked upload of %s at %d/%d bytes", req.FileName, session.Offset, size)
+		}
+	}
+	if session == nil {
+		var err error
+		if organized, ok := uploader.(DestinationChunkedUploader); ok {
+			session, err = organized.BeginChunkedTo(filePath, size, UploadDestination{
+				Manga:    req.Manga,
+				Chapter:  req.Chapter,
+				FileName: req.FileName,
+				Ctx:      ctx,
+			})
+		} else {
+			session, err = uploader.BeginChunked(filePath, size)
+		}
+		if err != nil {
+			return "", Receipt{}, fmt.Errorf("failed to start chunked upload: %v", err)
+		}
+		session.Key = key
+		session.Host = req.Host
+		session.Size = size
+		session.Ctx = ctx
+	}
+	if err := bu.chunkSessions.Put(session); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", Receipt{}, err
+	}
+	defer file.Close()
+
+	buffer := make([]byte, uploader.ChunkSize())
+	failures := 0
+	for session.Offset < size {
+		length := size - session.Offset
+		if length > int64(len(buffer)) {
+			length = int64(len(buffer))
+		}
+		if _, err := file.ReadAt(buffer[:length], session.Offset); err != nil && err != io.EOF {
+			return "", Receipt{}, fmt.Errorf("failed to read chunk: %v", err)
+		}
+
+		count(length, failures > 0)
+		if err := uploader.UploadChunk(session, buffer[:length]); err != nil {
+			failures++
+			if failures > chunkRetries {
+				return "", Receipt{}, fmt.Errorf("chunk at offset %d failed after %d attempts: %v", session.Offset, failures, err)
+			}
+
+			select {
+			case <-time.After(chunkRetryDelay << (failures - 1)):
+			case <-ctx.Done():
+				return "", Receipt{}, ctx.Err()
+			}
+
+			// O host pode ter recebido parte dos bytes antes da falha
+			if resumeErr := uploader.ResumeChunked(session); resumeErr != nil {
+				log.Printf("⚠️ Failed to resync chunked upload of %s: %v", req.FileName, resumeErr)
+			}
+			continue
+		}
+
+		failures = 0
+		if err := bu.chunkSessions.Put(session); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+	}
+
+	url, err := uploader.FinishChunked(session)
+	if err != nil {
+		return "", Receipt{}, fmt.Errorf("failed to finish chunked upload: %v", err)
+	}
+	if err := bu.chunkSessions.Delete(key); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+	return url, session.Receipt, nil
+}
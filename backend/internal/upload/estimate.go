@@ -0,0 +1,117 @@
+package upload
+
+import (
+	"sort"
+	"time"
+
+	"go-upload/backend/internal/websocket"
+)
+
+// defaultResponseTime é usado quando o host ainda não tem medições de resposta
+const defaultResponseTime = 2 * time.Second
+
+// maxListedOversized limita quantos arquivos acima do limite são listados na estimativa
+const maxListedOversized = 50
+
+// FileSizeLimiter é implementado por uploaders cujo host limita o tamanho dos arquivos
+type FileSizeLimiter interface {
+	GetMaxFileSize() int64
+}
+
+// ResponseTimer é implementado por uploaders que medem o tempo médio de resposta do host
+type ResponseTimer interface {
+	GetAverageResponseTime() time.Duration
+}
+
+// EstimateFile é um arquivo considerado na estimativa
+type EstimateFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// HostEstimate é a estimativa de custo de enviar um conjunto de arquivos para um host
+type HostEstimate struct {
+	Host              string         `json:"host"`
+	Files             int            `json:"files"`
+	TotalBytes        int64          `json:"totalBytes"`
+	LargestFile       int64          `json:"largestFile"`
+	Requests          int            `json:"requests"`      // uma requisição por arquivo (sem contar retries)
+	RatePerMinute     int            `json:"ratePerMinute"` // taxa atual do host
+	Concurrency       int            `json:"concurrency"`
+	AvgResponseMs     int64          `json:"avgResponseMs"`
+	EstimatedSeconds  int64          `json:"estimatedSeconds"`
+	EstimatedDuration string         `json:"estimatedDuration"`
+	MaxFileSize       int64          `json:"maxFileSize,omitempty"` // 0 = limite desconhecido
+	OversizedCount    int            `json:"oversizedCount"`
+	OversizedFiles    []EstimateFile `json:"oversizedFiles,omitempty"`
+	WithinLimits      bool           `json:"withinLimits"`
+}
+
+// Hosts retorna os hosts com uploader registrado, em ordem alfabética
+func (bu *BatchUploader) Hosts() []string {
+	hosts := make([]string, 0, len(bu.uploaders))
+	for host := range bu.uploaders {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// EstimateHost estima bytes, requisições e duração de enviar os arquivos para um host,
+// e verifica se algum arquivo ultrapassa o tamanho máximo aceito
+func (bu *BatchUploader) EstimateHost(host string, files []EstimateFile) (*HostEstimate, error) {
+	uploader, exists := bu.uploaders[host]
+	if !exists {
+		return nil, websocket.Errorf(websocket.ErrHostUnsupported, "uploader not found for host: %s", host)
+	}
+
+	estimate := &HostEstimate{
+		Host:        host,
+		Files:       len(files),
+		Requests:    len(files),
+		Concurrency: bu.maxWorkers,
+	}
+	if limiter, ok := uploader.(FileSizeLimiter); ok {
+		estimate.MaxFileSize = limiter.GetMaxFileSize()
+	}
+
+	for _, file := range files {
+		estimate.TotalBytes += file.Size
+		if file.Size > estimate.LargestFile {
+			estimate.LargestFile = file.Size
+		}
+		if estimate.MaxFileSize > 0 && file.Size > estimate.MaxFileSize {
+			estimate.OversizedCount++
+			if len(estimate.OversizedFiles) < maxListedOversized {
+				estimate.OversizedFiles = append(estimate.OversizedFiles, file)
+			}
+		}
+	}
+	estimate.WithinLimits = estimate.OversizedCount == 0
+
+	tokens, interval := uploader.GetRateLimit()
+	if tokens > 0 && interval > 0 {
+		estimate.RatePerMinute = int(float64(tokens) * float64(time.Minute) / float64(interval))
+	}
+
+	responseTime := defaultResponseTime
+	if timer, ok := uploader.(ResponseTimer); ok && timer.GetAverageResponseTime() > 0 {
+		responseTime = timer.GetAverageResponseTime()
+	}
+	estimate.AvgResponseMs = responseTime.Milliseconds()
+
+	// A duração é limitada pela taxa do host ou pela latência dividida entre os workers
+	var duration time.Duration
+	if estimate.RatePerMinute > 0 {
+		duration = time.Duration(float64(estimate.Requests) / float64(estimate.RatePerMinute) * float64(time.Minute))
+	}
+	if estimate.Concurrency > 0 {
+		if latencyBound := responseTime * time.Duration(estimate.Requests) / time.Duration(estimate.Concurrency); latencyBound > duration {
+			duration = latencyBound
+		}
+	}
+	estimate.EstimatedSeconds = int64(duration.Seconds())
+	estimate.EstimatedDuration = duration.Round(time.Second).String()
+
+	return estimate, nil
+}
@@ -0,0 +1,22 @@
+package upload
+
+// Receipt guarda os identificadores que o host devolve junto com a URL. Com
+// eles o arquivo pode ser apagado ou migrado depois, e um chamado de suporte
+// ao host consegue apontar o arquivo exato.
+type Receipt struct {
+	ID          string `json:"id,omitempty"`          // identificador do arquivo no host (chave do objeto nos buckets)
+	DeleteToken string `json:"deleteToken,omitempty"` // token que autoriza apagar o arquivo (ex.: deletehash do Imgur)
+	DeleteURL   string `json:"deleteUrl,omitempty"`   // página de remoção, quando o host fornece uma
+	VersionID   string `json:"versionId,omitempty"`   // versão do objeto em buckets com versionamento
+}
+
+// Empty informa se o recibo não tem nenhum identificador
+func (r *Receipt) Empty() bool {
+	return r == nil || *r == Receipt{}
+}
+
+// ReceiptUploader é implementado por hosts que devolvem identificadores além da
+// URL. O BatchUploader usa este método no lugar de UploadTo/Upload.
+type ReceiptUploader interface {
+	UploadWithReceipt(filePath string, dest UploadDestination) (string, Receipt, error)
+}
@@ -0,0 +1,171 @@
+// Package urlcheck confere as URLs retornadas pelos hosts antes de irem para
+// os JSONs: bem formadas, em https e sem cara de placeholder (domínios de
+// exemplo, templates não expandidos, "undefined"...). Opcionalmente cada URL
+// também precisa responder a um HEAD rápido. Uma URL recusada marca o arquivo
+// como falho em vez de poluir o capítulo.
+package urlcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// defaultHeadTimeout é o tempo máximo do HEAD sem timeout configurado
+const defaultHeadTimeout = 5 * time.Second
+
+// placeholderHosts são domínios que nunca hospedam páginas de verdade
+var placeholderHosts = []string{
+	"example.com", "example.org", "example.net", "example.edu",
+	"placehold.co", "placehold.it", "placeholder.com", "dummyimage.com",
+}
+
+// placeholderSegments são trechos do caminho que indicam um valor não preenchido
+var placeholderSegments = map[string]bool{"undefined": true, "null": true, "nil": true}
+
+// Config configura a validação das URLs
+type Config struct {
+	AllowHTTP    []string `json:"allowHttp,omitempty"`    // hosts que podem retornar http:// (ex.: webdav na rede local); "*" = todos
+	Head         bool     `json:"head,omitempty"`         // confere cada URL com um HEAD antes de aceitá-la
+	HeadTimeout  string   `json:"headTimeout,omitempty"`  // ex. "3s"; padrão 5s
+	Placeholders []string `json:"placeholders,omitempty"` // regex extras de URLs de placeholder
+}
+
+// Validator confere as URLs. Os métodos aceitam Validator nil (nenhuma checagem).
+type Validator struct {
+	allowHTTP    map[string]bool
+	head         bool
+	client       *http.Client
+	placeholders []*regexp.Regexp
+}
+
+// New cria o validador; sem configuração valem os padrões (https obrigatório, sem HEAD)
+func New(config *Config) (*Validator, error) {
+	if config == nil {
+		config = &Config{}
+	}
+	validator := &Validator{allowHTTP: make(map[string]bool), head: config.Head}
+	for _, host := range config.AllowHTTP {
+		validator.allowHTTP[host] = true
+	}
+	timeout := defaultHeadTimeout
+	if config.HeadTimeout != "" {
+		parsed, err := time.ParseDuration(config.HeadTimeout)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid url validation headTimeout %q", config.HeadTimeout)
+		}
+		timeout = parsed
+	}
+	if config.Head {
+		validator.client = &http.Client{Timeout: timeout}
+	}
+	for i, pattern := range config.Placeholders {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("url validation placeholder %d: %v", i, err)
+		}
+		validator.placeholders = append(validator.placeholders, compiled)
+	}
+	return validator, nil
+}
+
+// Check confere a URL retornada pelo host
+func (v *Validator) Check(ctx context.Context, host, link string) error {
+	if v == nil {
+		return nil
+	}
+	if err := v.checkFormat(host, link); err != nil {
+		return err
+	}
+	if v.head {
+		return v.checkHead(ctx, link)
+	}
+	return nil
+}
+
+// checkFormat confere a forma da URL, sem acessar a rede
+func (v *Validator) checkFormat(host, link string) error {
+	if strings.TrimSpace(link) == "" {
+		return fmt.Errorf("empty URL")
+	}
+	if strings.ContainsAny(link, " \t\r\n") {
+		return fmt.Errorf("URL contains whitespace")
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return fmt.Errorf("malformed URL: %v", err)
+	}
+	switch parsed.Scheme {
+	case "https":
+	case "http":
+		if !v.allowHTTP[host] && !v.allowHTTP["*"] {
+			return fmt.Errorf("URL is not https (add %q to urlValidation.allowHttp to accept it)", host)
+		}
+	default:
+		return fmt.Errorf("unsupported URL scheme %q", parsed.Scheme)
+	}
+	hostname := strings.ToLower(parsed.Hostname())
+	if hostname == "" {
+		return fmt.Errorf("URL has no host")
+	}
+
+	if strings.ContainsAny(link, "{}") {
+		return fmt.Errorf("placeholder URL: unexpanded template")
+	}
+	for _, domain := range placeholderHosts {
+		if hostname == domain || strings.HasSuffix(hostname, "."+domain) {
+			return fmt.Errorf("placeholder URL: %s", hostname)
+		}
+	}
+	for _, segment := range strings.Split(parsed.Path, "/") {
+		if placeholderSegments[strings.ToLower(segment)] {
+			return fmt.Errorf("placeholder URL: %q in the path", segment)
+		}
+	}
+	for _, pattern := range v.placeholders {
+		if pattern.MatchString(link) {
+			return fmt.Errorf("placeholder URL: matches %q", pattern.String())
+		}
+	}
+	return nil
+}
+
+// checkHead confere que a URL responde e não é uma página HTML (visualizador).
+// Servidores sem HEAD recebem um GET do primeiro byte.
+func (v *Validator) checkHead(ctx context.Context, link string) error {
+	resp, err := v.request(ctx, http.MethodHead, link)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp, err = v.request(ctx, http.MethodGet, link)
+	}
+	if err != nil {
+		return fmt.Errorf("URL did not respond: %v", err)
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("URL responded with status %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); strings.HasPrefix(contentType, "text/html") {
+		return fmt.Errorf("URL serves a web page (%s), not the file", contentType)
+	}
+	return nil
+}
+
+// request faz a requisição de checagem; o corpo é descartado
+func (v *Validator) request(ctx context.Context, method, link string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
@@ -0,0 +1,170 @@
+// Package urlrules reescreve as URLs retornadas pelos hosts antes de irem para
+// os JSONs. Alguns hosts devolvem a página do visualizador em vez da imagem;
+// as regras de cada host trocam essa URL pela direta com uma substituição por
+// regex, parâmetros acrescentados à query (ex.: raw=1) ou a troca do subdomínio.
+package urlrules
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Rule é uma transformação da URL. As ações são aplicadas nesta ordem:
+// Replace, Subdomain e Query.
+type Rule struct {
+	Pattern   string `json:"pattern,omitempty"`   // regex; a regra só vale para URLs que casam (vazio = todas)
+	Replace   string `json:"replace,omitempty"`   // substitui os trechos que casam com Pattern ($1, ${nome}...)
+	Subdomain string `json:"subdomain,omitempty"` // troca o subdomínio (ex.: "i" → i.host.com); "-" remove
+	Query     string `json:"query,omitempty"`     // parâmetros acrescentados se ausentes (ex.: "raw=1")
+}
+
+// rule é uma Rule validada
+type rule struct {
+	Rule
+	pattern *regexp.Regexp
+	query   url.Values
+	keys    []string // ordem dos parâmetros em Query
+}
+
+// Rewriter aplica as regras de cada host. Os métodos aceitam Rewriter nil (sem regras).
+type Rewriter struct {
+	rules map[string][]rule
+}
+
+// New valida as regras (host -> regras em ordem); nil sem regras
+func New(rules map[string][]Rule) (*Rewriter, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	rewriter := &Rewriter{rules: make(map[string][]rule, len(rules))}
+	for host, hostRules := range rules {
+		for i, config := range hostRules {
+			compiled := rule{Rule: config}
+			if config.Pattern != "" {
+				pattern, err := regexp.Compile(config.Pattern)
+				if err != nil {
+					return nil, fmt.Errorf("url rule %d of %s: invalid pattern: %v", i, host, err)
+				}
+				compiled.pattern = pattern
+			} else if config.Replace != "" {
+				return nil, fmt.Errorf("url rule %d of %s: replace requires a pattern", i, host)
+			}
+			if config.Subdomain != "" && config.Subdomain != "-" && !validLabel(config.Subdomain) {
+				return nil, fmt.Errorf("url rule %d of %s: invalid subdomain %q", i, host, config.Subdomain)
+			}
+			if config.Query != "" {
+				query, err := url.ParseQuery(strings.TrimPrefix(config.Query, "?"))
+				if err != nil {
+					return nil, fmt.Errorf("url rule %d of %s: invalid query %q: %v", i, host, config.Query, err)
+				}
+				compiled.query = query
+				for _, pair := range strings.Split(strings.TrimPrefix(config.Query, "?"), "&") {
+					key, _, _ := strings.Cut(pair, "=")
+					if key, err := url.QueryUnescape(key); err == nil && key != "" {
+						compiled.keys = append(compiled.keys, key)
+					}
+				}
+			}
+			if compiled.pattern == nil && config.Subdomain == "" && compiled.query == nil {
+				return nil, fmt.Errorf("url rule %d of %s: no action (use replace, subdomain or query)", i, host)
+			}
+			rewriter.rules[host] = append(rewriter.rules[host], compiled)
+		}
+	}
+	return rewriter, nil
+}
+
+// Has informa se o host tem regras
+func (r *Rewriter) Has(host string) bool {
+	return r != nil && len(r.rules[host]) > 0
+}
+
+// Rewrite aplica as regras do host à URL; sem regras a URL volta inalterada
+func (r *Rewriter) Rewrite(host, link string) string {
+	if !r.Has(host) || link == "" {
+		return link
+	}
+	for _, rule := range r.rules[host] {
+		link = rule.apply(link)
+	}
+	return link
+}
+
+// apply aplica uma regra à URL
+func (r *rule) apply(link string) string {
+	if r.pattern != nil {
+		if !r.pattern.MatchString(link) {
+			return link
+		}
+		if r.Replace != "" {
+			link = r.pattern.ReplaceAllString(link, r.Replace)
+		}
+	}
+	if r.Subdomain == "" && r.query == nil {
+		return link
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Host == "" {
+		return link
+	}
+	if r.Subdomain != "" {
+		parsed.Host = switchSubdomain(parsed.Host, r.Subdomain)
+	}
+	if r.query != nil {
+		present := parsed.Query()
+		var added []string
+		for _, key := range r.keys {
+			if _, exists := present[key]; exists {
+				continue
+			}
+			for _, value := range r.query[key] {
+				added = append(added, url.QueryEscape(key)+"="+url.QueryEscape(value))
+			}
+			present[key] = r.query[key]
+		}
+		if len(added) > 0 {
+			// Os parâmetros existentes ficam como estão (URLs assinadas dependem da ordem)
+			if parsed.RawQuery != "" {
+				parsed.RawQuery += "&"
+			}
+			parsed.RawQuery += strings.Join(added, "&")
+		}
+	}
+	return parsed.String()
+}
+
+// switchSubdomain troca o que vem antes do domínio (os dois últimos rótulos)
+// pelo subdomínio pedido, ou o remove com "-"
+func switchSubdomain(host, subdomain string) string {
+	hostname, port := host, ""
+	if i := strings.LastIndex(host, ":"); i >= 0 && !strings.Contains(host[i:], "]") {
+		hostname, port = host[:i], host[i:]
+	}
+	labels := strings.Split(hostname, ".")
+	if len(labels) < 2 {
+		return host
+	}
+	domain := strings.Join(labels[len(labels)-2:], ".")
+	if subdomain == "-" {
+		return domain + port
+	}
+	return subdomain + "." + domain + port
+}
+
+// validLabel informa se s é um subdomínio válido (rótulos separados por ponto)
+func validLabel(s string) bool {
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,314 @@
+// Package webhooks avisa sites externos (ex.: o CMS do site do grupo) dos
+// capítulos novos de cada obra. Cada obra configurada tem o próprio endpoint,
+// que recebe por POST um evento chapter.published com os dados do capítulo
+// sempre que um capítulo dela é publicado.
+//
+// O corpo é assinado com HMAC-SHA256: o cabeçalho X-Go-Upload-Signature traz
+// "sha256=" seguido do HMAC em hexadecimal de "<timestamp>.<corpo>", com o
+// timestamp (segundos Unix) em X-Go-Upload-Timestamp. O site recalcula o HMAC
+// com o segredo compartilhado e recusa timestamps antigos para evitar replay.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-upload/backend/internal/mangaid"
+	"go-upload/backend/internal/metadata"
+	"go-upload/backend/internal/retry"
+)
+
+// EventChapterPublished é o tipo do evento de capítulo publicado
+const EventChapterPublished = "chapter.published"
+
+// Cabeçalhos enviados com cada evento
+const (
+	HeaderEvent     = "X-Go-Upload-Event"
+	HeaderDelivery  = "X-Go-Upload-Delivery"
+	HeaderTimestamp = "X-Go-Upload-Timestamp"
+	HeaderSignature = "X-Go-Upload-Signature"
+)
+
+// defaultTimeout é o tempo máximo de cada tentativa sem timeout configurado
+const defaultTimeout = 30 * time.Second
+
+// maxResponse é quanto da resposta de um endpoint com falha vai na mensagem de erro
+const maxResponse = 2048
+
+// Config configura os webhooks por obra
+type Config struct {
+	Secret  string             `json:"secret,omitempty"`  // segredo padrão do HMAC; vazio = MANGA_WEBHOOK_SECRET
+	Timeout string             `json:"timeout,omitempty"` // ex. "10s"; padrão 30s por tentativa
+	Retries int                `json:"retries,omitempty"` // novas tentativas após falha (padrão 3; -1 = nenhuma)
+	Works   map[string]Webhook `json:"works"`             // ID ou título da obra -> webhook
+}
+
+// Webhook é o endpoint de uma obra
+type Webhook struct {
+	URL     string            `json:"url"`
+	Secret  string            `json:"secret,omitempty"`  // substitui o segredo padrão
+	Headers map[string]string `json:"headers,omitempty"` // cabeçalhos extras (ex.: autenticação do CMS)
+}
+
+// Event é o corpo enviado ao webhook
+type Event struct {
+	Event     string              `json:"event"`
+	Time      time.Time           `json:"time"`
+	MangaID   string              `json:"mangaId"`
+	Manga     string              `json:"manga"` // título da obra
+	Chapter   string              `json:"chapter"`
+	Title     string              `json:"title,omitempty"`
+	Volume    string              `json:"volume,omitempty"`
+	Language  string              `json:"language,omitempty"`
+	Version   int                 `json:"version,omitempty"`
+	Updated   string              `json:"lastUpdated,omitempty"`
+	Groups    map[string][]string `json:"groups"` // grupo -> URLs das páginas
+	PageCount int                 `json:"pageCount"`
+}
+
+// Notifier entrega os eventos. Os métodos aceitam Notifier nil (sem webhooks).
+type Notifier struct {
+	works   map[string]Webhook // ID normalizado -> webhook, com o segredo resolvido
+	client  *http.Client
+	retrier retry.Retrier
+}
+
+// New valida os webhooks; nil sem configuração
+func New(config *Config) (*Notifier, error) {
+	if config == nil || len(config.Works) == 0 {
+		return nil, nil
+	}
+	timeout := defaultTimeout
+	if config.Timeout != "" {
+		parsed, err := time.ParseDuration(config.Timeout)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid webhook timeout %q", config.Timeout)
+		}
+		timeout = parsed
+	}
+	retries := config.Retries
+	switch {
+	case retries == 0:
+		retries = 3
+	case retries < 0:
+		retries = 0
+	}
+	secret := config.Secret
+	if secret == "" {
+		secret = os.Getenv("MANGA_WEBHOOK_SECRET")
+	}
+
+	notifier := &Notifier{
+		works:  make(map[string]Webhook, len(config.Works)),
+		client: &http.Client{Timeout: timeout},
+		retrier: retry.Retrier{
+			Name: "webhooks",
+			Policy: retry.Policy{
+				MaxRetries: retries,
+				BaseDelay:  2 * time.Second,
+				Jitter:     retry.DefaultJitter,
+			},
+		},
+	}
+	for work, webhook := range config.Works {
+		mangaID := mangaid.Normalize(work)
+		if mangaID == "" {
+			return nil, fmt.Errorf("webhook for %q: invalid work", work)
+		}
+		if _, exists := notifier.works[mangaID]; exists {
+			return nil, fmt.Errorf("webhook for %q: work %s configured twice", work, mangaID)
+		}
+		if !strings.HasPrefix(webhook.URL, "https://") && !strings.HasPrefix(webhook.URL, "http://") {
+			return nil, fmt.Errorf("webhook for %q: url must be http(s), got %q", work, webhook.URL)
+		}
+		if webhook.Secret == "" {
+			webhook.Secret = secret
+		}
+		if webhook.Secret == "" {
+			return nil, fmt.Errorf("webhook for %q: secret is required (per work, in secret or MANGA_WEBHOOK_SECRET)", work)
+		}
+		notifier.works[mangaID] = webhook
+	}
+	return notifier, nil
+}
+
+// Has informa se a obra tem webhook
+func (n *Notifier) Has(mangaID string) bool {
+	if n == nil {
+		return false
+	}
+	_, exists := n.works[mangaid.Normalize(mangaID)]
+	return exists
+}
+
+// Works lista os IDs das obras com webhook
+func (n *Notifier) Works() []string {
+	if n == nil {
+		return nil
+	}
+	works := make([]string, 0, len(n.works))
+	for mangaID := range n.works {
+		works = append(works, mangaID)
+	}
+	sort.Strings(works)
+	return works
+}
+
+// ChapterKeys retorna os capítulos de um JSON de obra (nil se ilegível)
+func ChapterKeys(content []byte) map[string]bool {
+	var manga metadata.MangaJSON
+	if err := json.Unmarshal(content, &manga); err != nil {
+		return nil
+	}
+	keys := make(map[string]bool, len(manga.Chapters))
+	for key := range manga.Chapters {
+		keys[key] = true
+	}
+	return keys
+}
+
+// NewChapters retorna, em ordem, os capítulos de after que não estão em before
+func NewChapters(before map[string]bool, after []byte) []string {
+	var added []string
+	for key := range ChapterKeys(after) {
+		if !before[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	return added
+}
+
+// Publish envia um evento chapter.published por capítulo, com os dados lidos
+// do JSON da obra. Cada entrega é repetida com backoff até dar certo ou as
+// tentativas acabarem; as falhas são reunidas no erro retornado.
+func (n *Notifier) Publish(ctx context.Context, mangaID string, content []byte, chapters []string) error {
+	if n == nil || len(chapters) == 0 {
+		return nil
+	}
+	mangaID = mangaid.Normalize(mangaID)
+	webhook, exists := n.works[mangaID]
+	if !exists {
+		return nil
+	}
+	var manga metadata.MangaJSON
+	if err := json.Unmarshal(content, &manga); err != nil {
+		return fmt.Errorf("failed to parse manga JSON: %v", err)
+	}
+
+	var failures []string
+	for _, key := range chapters {
+		chapter, exists := manga.Chapters[key]
+		if !exists {
+			continue
+		}
+		event := Event{
+			Event:    EventChapterPublished,
+			Time:     time.Now(),
+			MangaID:  mangaID,
+			Manga:    manga.Title,
+			Chapter:  key,
+			Title:    chapter.Title,
+			Volume:   chapter.Volume,
+			Language: chapter.Language,
+			Version:  chapter.Version,
+			Updated:  chapter.LastUpdated,
+			Groups:   chapter.Groups,
+		}
+		for _, pages := range chapter.Groups {
+			if len(pages) > event.PageCount {
+				event.PageCount = len(pages)
+			}
+		}
+		if err := n.Deliver(ctx, webhook, event); err != nil {
+			failures = append(failures, fmt.Sprintf("chapter %s: %v", key, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("webhook %s: %s", webhook.URL, strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// Deliver envia um evento ao webhook, com novas tentativas em falhas de rede,
+// 429 e 5xx. A mesma entrega mantém o ID em todas as tentativas, para que o
+// site descarte duplicatas.
+func (n *Notifier) Deliver(ctx context.Context, webhook Webhook, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %v", err)
+	}
+	delivery := deliveryID()
+	_, err = n.retrier.Do(ctx, func(attempt int) error {
+		return n.post(ctx, webhook, event.Event, delivery, body)
+	})
+	return err
+}
+
+// post faz uma tentativa de entrega; respostas 4xx (exceto 429) não são repetidas
+func (n *Notifier) post(ctx context.Context, webhook Webhook, event, delivery string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("failed to create request: %v", err))
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-upload-webhooks")
+	for name, value := range webhook.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, delivery)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	text, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	err = fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(text)))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return err
+	}
+	return retry.Permanent(err)
+}
+
+// Sign retorna o valor do cabeçalho de assinatura: "sha256=" e o HMAC-SHA256
+// em hexadecimal de "<timestamp>.<corpo>"
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify confere a assinatura de uma entrega, para sites escritos em Go
+func Verify(secret, timestamp, signature string, body []byte) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
+
+// deliveryID gera um identificador aleatório para a entrega
+func deliveryID() string {
+	var id [12]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
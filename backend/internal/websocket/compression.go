@@ -0,0 +1,34 @@
+package websocket
+
+// compressionSettings é o permessage-deflate aplicado às conexões novas
+type compressionSettings struct {
+	disabled  bool
+	threshold int // bytes; mensagens menores vão sem compressão
+	level     int // 0 = padrão da biblioteca (1, o mais rápido)
+}
+
+// SetCompression ajusta a compressão das mensagens enviadas nas próximas
+// conexões. A extensão só é usada quando o cliente a oferece no handshake;
+// mensagens abaixo de threshold bytes não compensam o custo e vão sem compressão.
+func (m *Manager) SetCompression(enabled bool, threshold, level int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.compression = compressionSettings{disabled: !enabled, threshold: threshold, level: level}
+}
+
+// applyCompression copia a configuração atual para uma conexão nova
+func (m *Manager) applyCompression(c *Connection) {
+	m.mu.RLock()
+	settings := m.compression
+	m.mu.RUnlock()
+
+	if settings.disabled {
+		c.compressMin = -1
+		c.conn.EnableWriteCompression(false)
+		return
+	}
+	c.compressMin = settings.threshold
+	if settings.level != 0 {
+		c.conn.SetCompressionLevel(settings.level)
+	}
+}
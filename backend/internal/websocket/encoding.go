@@ -0,0 +1,64 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"go-upload/backend/internal/msgpack"
+)
+
+// Codificações das respostas enviadas ao cliente. As requisições são sempre JSON.
+const (
+	EncodingJSON    = "json"    // frames de texto (padrão)
+	EncodingMsgpack = "msgpack" // frames binários MessagePack, com as mesmas estruturas do JSON
+)
+
+// ParseEncoding valida o nome de uma codificação ("" = EncodingJSON)
+func ParseEncoding(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", EncodingJSON:
+		return EncodingJSON, nil
+	case EncodingMsgpack, "messagepack":
+		return EncodingMsgpack, nil
+	}
+	return "", fmt.Errorf("unsupported encoding %q (use %s or %s)", name, EncodingJSON, EncodingMsgpack)
+}
+
+// Encoding retorna a codificação das respostas da conexão
+func (c *Connection) Encoding() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.encoding == "" {
+		return EncodingJSON
+	}
+	return c.encoding
+}
+
+// SetEncoding troca a codificação das próximas respostas. Mensagens já na fila
+// também saem na nova codificação: o cliente distingue pelo tipo do frame
+// (texto = JSON, binário = MessagePack).
+func (c *Connection) SetEncoding(encoding string) {
+	c.mu.Lock()
+	c.encoding = encoding
+	c.mu.Unlock()
+}
+
+// writeResponse serializa e envia uma resposta, comprimindo só as grandes
+func (c *Connection) writeResponse(response Response) error {
+	messageType := websocket.TextMessage
+	var data []byte
+	var err error
+	if c.Encoding() == EncodingMsgpack {
+		messageType = websocket.BinaryMessage
+		data, err = msgpack.Marshal(response)
+	} else {
+		data, err = json.Marshal(response)
+	}
+	if err != nil {
+		return err
+	}
+	c.conn.EnableWriteCompression(c.compressMin >= 0 && len(data) >= c.compressMin)
+	return c.conn.WriteMessage(messageType, data)
+}
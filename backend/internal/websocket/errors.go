@@ -0,0 +1,106 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode identifica o tipo de erro em uma Response para que o cliente
+// possa tomar decisões sem depender do texto da mensagem
+type ErrorCode string
+
+const (
+	// Requisição
+	ErrInvalidRequest  ErrorCode = "E_INVALID_REQUEST"   // JSON malformado ou formato inesperado
+	ErrMissingField    ErrorCode = "E_MISSING_FIELD"     // Campo obrigatório ausente
+	ErrNotImplemented  ErrorCode = "E_NOT_IMPLEMENTED"   // Ação ainda não suportada
+	ErrMessageTooLarge ErrorCode = "E_MESSAGE_TOO_LARGE" // Mensagem ou FileContent acima do limite
+	ErrReadOnly        ErrorCode = "E_READ_ONLY"         // Ação que altera dados em um servidor somente leitura
+
+	// Sistema de arquivos e descoberta
+	ErrPathNotFound    ErrorCode = "E_PATH_NOT_FOUND"   // Caminho inexistente na biblioteca
+	ErrDiscoveryFailed ErrorCode = "E_DISCOVERY_FAILED" // Falha ao varrer a estrutura
+	ErrIO              ErrorCode = "E_IO"               // Falha de leitura/escrita em disco
+
+	// Metadados JSON
+	ErrJSONNotFound     ErrorCode = "E_JSON_NOT_FOUND"    // Arquivo JSON da obra não existe
+	ErrJSONInvalid      ErrorCode = "E_JSON_INVALID"      // JSON existente não pôde ser lido
+	ErrJSONConflict     ErrorCode = "E_JSON_CONFLICT"     // JSON existente não pôde ser mesclado
+	ErrMetadataConflict ErrorCode = "E_METADATA_CONFLICT" // JSON alterado por outro cliente desde a leitura
+
+	// Uploads
+	ErrUploadsDisabled ErrorCode = "E_UPLOADS_DISABLED"  // Parada de emergência ou safe mode
+	ErrUploadFailed    ErrorCode = "E_UPLOAD_FAILED"     // Host recusou ou falhou após retries
+	ErrHostRateLimited ErrorCode = "E_HOST_RATE_LIMITED" // Timeout aguardando o rate limiter do host
+	ErrHostUnsupported ErrorCode = "E_HOST_UNSUPPORTED"  // Nenhum uploader registrado para o host
+	ErrHostUnavailable ErrorCode = "E_HOST_UNAVAILABLE"  // Circuit breaker do host aberto após falhas seguidas
+	ErrCanceled        ErrorCode = "E_CANCELED"          // Lote ou coleção cancelados
+	ErrInvalidURL      ErrorCode = "E_INVALID_URL"       // Host retornou URL malformada, sem https ou de placeholder
+
+	// Coleções e lotes
+	ErrBatchNotFound      ErrorCode = "E_BATCH_NOT_FOUND"
+	ErrCollectionNotFound ErrorCode = "E_COLLECTION_NOT_FOUND"
+	ErrCollectionFailed   ErrorCode = "E_COLLECTION_FAILED"
+	ErrJobRunning         ErrorCode = "E_JOB_RUNNING"        // Job de fundo já em andamento
+	ErrJobNotFound        ErrorCode = "E_JOB_NOT_FOUND"      // Job inexistente ou expirado (claim_job)
+	ErrJobClaimDenied     ErrorCode = "E_JOB_CLAIM_DENIED"   // Token de posse inválido
+	ErrNothingToPublish   ErrorCode = "E_NOTHING_TO_PUBLISH" // Capítulo sem uploads aguardando publish_chapter
+	ErrReviewDenied       ErrorCode = "E_REVIEW_DENIED"      // Conexão sem papel ou papel sem permissão para a transição
+
+	// Serviços externos
+	ErrAniListFailed      ErrorCode = "E_ANILIST_FAILED"
+	ErrGitHubFailed       ErrorCode = "E_GITHUB_FAILED"
+	ErrServiceUnavailable ErrorCode = "E_SERVICE_UNAVAILABLE" // Serviço interno não inicializado
+	ErrConfigFailed       ErrorCode = "E_CONFIG_FAILED"
+	ErrUpdateFailed       ErrorCode = "E_UPDATE_FAILED"      // Consulta, download ou verificação da nova versão
+	ErrProviderNotFound   ErrorCode = "E_PROVIDER_NOT_FOUND" // Fonte de metadados inexistente
+	ErrPluginFailed       ErrorCode = "E_PLUGIN_FAILED"      // Plugin externo falhou ou não respondeu
+	ErrHookFailed         ErrorCode = "E_HOOK_FAILED"        // Hook do pipeline recusou o estágio
+	ErrPolicyViolation    ErrorCode = "E_POLICY_VIOLATION"   // Arquivos fora da política de conteúdo
+
+	// Genérico
+	ErrInternal ErrorCode = "E_INTERNAL"
+)
+
+// CodedError associa um ErrorCode a um erro retornado por um handler
+type CodedError struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// Errorf cria um erro com código, no mesmo formato de fmt.Errorf
+func Errorf(code ErrorCode, format string, args ...interface{}) error {
+	return &CodedError{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// WithCode associa um código a um erro existente (nil continua nil)
+func WithCode(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CodedError{Code: code, Err: err}
+}
+
+// CodeOf retorna o código de um erro, ou ErrInternal se ele não tiver um
+func CodeOf(err error) ErrorCode {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return ErrInternal
+}
+
+// ensureErrorCode garante que toda resposta de erro carregue um código
+func ensureErrorCode(response *Response) {
+	if response.Error != "" && response.ErrorCode == "" {
+		response.ErrorCode = ErrInternal
+	}
+}
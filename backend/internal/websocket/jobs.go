@@ -0,0 +1,253 @@
+package websocket
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// finishedJobRetention é por quanto tempo um job concluído continua disponível
+// para claim, para que um cliente que reconectou ainda receba o resultado
+const finishedJobRetention = 15 * time.Minute
+
+// JobInfo descreve um job e seu dono atual
+type JobInfo struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Owner     string    `json:"owner,omitempty"` // ID da conexão dona; vazio = órfão
+	Orphaned  bool      `json:"orphaned"`
+	LastSeq   int64     `json:"lastSeq"`
+	FirstSeq  int64     `json:"firstSeq"` // evento mais antigo ainda no buffer
+	Done      bool      `json:"done"`
+	StartedAt time.Time `json:"startedAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// jobStream guarda os eventos recentes de um job
+type jobStream struct {
+	info   JobInfo
+	token  string
+	owner  *Connection
+	events []Response
+
+	sendMu sync.Mutex // mantém a ordem das entregas ao dono; obtido antes de JobStreams.mu
+}
+
+// JobStreams desacopla o progresso de jobs longos da conexão que os iniciou:
+// os eventos são numerados e bufferizados, entregues ao dono enquanto ele
+// estiver conectado, e outro cliente pode assumir o job com o token de posse
+type JobStreams struct {
+	bufferSize int
+
+	mu   sync.Mutex
+	jobs map[string]*jobStream
+}
+
+// NewJobStreams cria o registro de jobs, guardando até bufferSize eventos por job
+func NewJobStreams(bufferSize int) *JobStreams {
+	if bufferSize <= 0 {
+		bufferSize = 500
+	}
+	return &JobStreams{bufferSize: bufferSize, jobs: make(map[string]*jobStream)}
+}
+
+// Open registra um job com seu dono e retorna o token de posse exigido por Claim
+func (j *JobStreams) Open(jobID, kind string, owner *Connection) string {
+	token := newClaimToken()
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.prune(now)
+
+	stream := &jobStream{
+		info:  JobInfo{ID: jobID, Kind: kind, StartedAt: now, UpdatedAt: now},
+		token: token,
+		owner: owner,
+	}
+	if owner != nil {
+		stream.info.Owner = owner.ID
+	}
+	j.jobs[jobID] = stream
+	return token
+}
+
+// Discard remove um job que não chegou a iniciar
+func (j *JobStreams) Discard(jobID string) {
+	j.mu.Lock()
+	delete(j.jobs, jobID)
+	j.mu.Unlock()
+}
+
+// Publish numera o evento, guarda no buffer e entrega ao dono, se houver.
+// Se a entrega falhar o dono é desligado e o job fica órfão.
+func (j *JobStreams) Publish(jobID string, response Response) {
+	j.publish(jobID, response, false)
+}
+
+// Finish publica o evento final do job; ele continua disponível para claim
+// por um tempo antes de ser descartado
+func (j *JobStreams) Finish(jobID string, response Response) {
+	j.publish(jobID, response, true)
+}
+
+func (j *JobStreams) publish(jobID string, response Response, done bool) {
+	stream := j.lookup(jobID)
+	if stream == nil {
+		return
+	}
+	// Ordem dos locks: sendMu antes de mu, para que as entregas sigam a numeração
+	stream.sendMu.Lock()
+	defer stream.sendMu.Unlock()
+
+	j.mu.Lock()
+	stream.info.LastSeq++
+	stream.info.UpdatedAt = time.Now()
+	stream.info.Done = stream.info.Done || done
+	response.Seq = stream.info.LastSeq
+	stream.events = append(stream.events, response)
+	if len(stream.events) > j.bufferSize {
+		stream.events = stream.events[len(stream.events)-j.bufferSize:]
+	}
+	stream.info.FirstSeq = stream.events[0].Seq
+	owner := stream.owner
+	j.mu.Unlock()
+
+	if owner == nil {
+		return
+	}
+	if err := deliver(owner, response); err != nil {
+		j.mu.Lock()
+		if stream.owner == owner {
+			stream.owner = nil
+			stream.info.Owner = ""
+			stream.info.Orphaned = true
+		}
+		j.mu.Unlock()
+	}
+}
+
+// Claim transfere o job para conn e reenvia os eventos do buffer com número
+// maior que since. Retorna o estado do job e o dono anterior (nil se órfão).
+func (j *JobStreams) Claim(jobID, token string, conn *Connection, since int64) (JobInfo, *Connection, error) {
+	stream := j.lookup(jobID)
+	if stream == nil {
+		return JobInfo{}, nil, Errorf(ErrJobNotFound, "job not found: %s", jobID)
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(stream.token)) != 1 {
+		return JobInfo{}, nil, Errorf(ErrJobClaimDenied, "invalid claim token for job %s", jobID)
+	}
+	stream.sendMu.Lock()
+	defer stream.sendMu.Unlock()
+
+	j.mu.Lock()
+	previous := stream.owner
+	stream.owner = conn
+	stream.info.Owner = conn.ID
+	stream.info.Orphaned = false
+	var replay []Response
+	for _, event := range stream.events {
+		if event.Seq > since {
+			replay = append(replay, event)
+		}
+	}
+	info := stream.info
+	j.mu.Unlock()
+
+	for _, event := range replay {
+		if err := deliver(conn, event); err != nil {
+			break
+		}
+	}
+	if previous == conn {
+		previous = nil
+	}
+	return info, previous, nil
+}
+
+// lookup retorna o stream de um job (nil se não existe ou já expirou)
+func (j *JobStreams) lookup(jobID string) *jobStream {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.prune(time.Now())
+	return j.jobs[jobID]
+}
+
+// Detach desliga conn de todos os jobs que ela possuía e retorna os que
+// ainda estão em andamento. Chamado quando a conexão cai.
+func (j *JobStreams) Detach(conn *Connection) []JobInfo {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var orphaned []JobInfo
+	for _, stream := range j.jobs {
+		if stream.owner != conn {
+			continue
+		}
+		stream.owner = nil
+		stream.info.Owner = ""
+		stream.info.Orphaned = true
+		if !stream.info.Done {
+			orphaned = append(orphaned, stream.info)
+		}
+	}
+	return orphaned
+}
+
+// Owned retorna os IDs dos jobs cuja conexão dona é conn
+func (j *JobStreams) Owned(conn *Connection) []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ids := []string{}
+	for id, stream := range j.jobs {
+		if stream.owner == conn {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// List retorna todos os jobs conhecidos, dos mais recentes aos mais antigos
+func (j *JobStreams) List() []JobInfo {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.prune(time.Now())
+
+	jobs := make([]JobInfo, 0, len(j.jobs))
+	for _, stream := range j.jobs {
+		jobs = append(jobs, stream.info)
+	}
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].StartedAt.After(jobs[b].StartedAt) })
+	return jobs
+}
+
+// prune descarta jobs concluídos há mais tempo que a retenção (chamado com mu)
+func (j *JobStreams) prune(now time.Time) {
+	for id, stream := range j.jobs {
+		if stream.info.Done && now.Sub(stream.info.UpdatedAt) > finishedJobRetention {
+			delete(j.jobs, id)
+		}
+	}
+}
+
+// deliver envia sem derrubar o job se a conexão já tiver sido fechada
+func deliver(conn *Connection, response Response) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("connection closed")
+		}
+	}()
+	return conn.Send(response)
+}
+
+func newClaimToken() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"sort"
 	"sync"
 	"time"
 
@@ -25,7 +27,9 @@ type Response struct {
 	Status      string      `json:"status"`
 	Data        interface{} `json:"data,omitempty"`
 	Error       string      `json:"error,omitempty"`
+	ErrorCode   ErrorCode   `json:"errorCode,omitempty"`
 	RequestID   string      `json:"requestId,omitempty"`
+	Seq         int64       `json:"seq,omitempty"` // Número do evento no stream do job (JobStreams)
 	Progress    *Progress   `json:"progress,omitempty"`
 	File        string      `json:"file,omitempty"`
 	URL         string      `json:"url,omitempty"`
@@ -57,6 +61,12 @@ type Connection struct {
 	cancel       context.CancelFunc
 	lastPing     time.Time
 	LastActivity time.Time // Adicionado para massive_manager
+	locale       string    // Idioma das mensagens enviadas ao cliente
+	reviewer     string    // Nome de quem usa a conexão no fluxo de revisão (set_reviewer)
+	role         string    // Papel no fluxo de revisão ("" = não identificado)
+	compressMin  int       // Tamanho mínimo de mensagem comprimida (-1 = nunca comprimir)
+	encoding     string    // Codificação das respostas: EncodingJSON (texto) ou EncodingMsgpack (binário)
+	maxMessage   int64     // Tamanho máximo de uma mensagem recebida
 	mu           sync.RWMutex
 	wg           sync.WaitGroup
 }
@@ -68,6 +78,10 @@ type Manager struct {
 	unregister  chan *Connection
 	broadcast   chan Response
 	handlers    map[string]MessageHandler
+	onDisconnect []func(*Connection)
+	compression compressionSettings
+	sendBuffer  int // Respostas enfileiradas por conexão (0 = defaultSendBuffer)
+	maxMessage  int64 // Tamanho máximo de uma mensagem recebida (0 = DefaultMaxMessageSize)
 	mu          sync.RWMutex
 	ctx         context.Context
 	cancel      context.CancelFunc
@@ -105,20 +119,84 @@ func (m *Manager) RegisterHandler(action string, handler MessageHandler) {
 	m.handlers[action] = handler
 }
 
+// Actions retorna as ações com handler registrado, em ordem alfabética
+func (m *Manager) Actions() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	actions := make([]string, 0, len(m.handlers))
+	for action := range m.handlers {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// OnDisconnect registra uma função chamada (em goroutine própria) quando uma
+// conexão é encerrada, por fechamento ou inatividade
+func (m *Manager) OnDisconnect(hook func(*Connection)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onDisconnect = append(m.onDisconnect, hook)
+}
+
+// disconnected encerra o contexto da conexão e avisa os hooks (chamado com m.mu)
+func (m *Manager) disconnected(conn *Connection) {
+	conn.cancel()
+	for _, hook := range m.onDisconnect {
+		go hook(conn)
+	}
+}
+
+// defaultSendBuffer é a fila de respostas de cada conexão
+const defaultSendBuffer = 256
+
+// SetSendBuffer define o tamanho da fila de respostas das próximas conexões;
+// filas menores gastam menos memória com clientes lentos
+func (m *Manager) SetSendBuffer(size int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sendBuffer = size
+}
+
+// DefaultMaxMessageSize é o tamanho máximo padrão de uma mensagem recebida
+const DefaultMaxMessageSize = 16 << 20
+
+// SetMaxMessageSize define o tamanho máximo das mensagens recebidas pelas
+// próximas conexões. Mensagens maiores são descartadas sem serem guardadas em
+// memória e o cliente recebe um erro ErrMessageTooLarge; a conexão continua.
+func (m *Manager) SetMaxMessageSize(size int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxMessage = size
+}
+
 // NewConnection cria uma nova conexão gerenciada
 func (m *Manager) NewConnection(conn *websocket.Conn, connectionID string) *Connection {
 	ctx, cancel := context.WithCancel(m.ctx)
 	
+	m.mu.RLock()
+	sendBuffer := m.sendBuffer
+	maxMessage := m.maxMessage
+	m.mu.RUnlock()
+	if sendBuffer <= 0 {
+		sendBuffer = defaultSendBuffer
+	}
+	if maxMessage <= 0 {
+		maxMessage = DefaultMaxMessageSize
+	}
+	
 	connection := &Connection{
 		ID:           connectionID,
 		conn:         conn,
-		send:         make(chan Response, 256),
+		send:         make(chan Response, sendBuffer),
 		manager:      m,
 		ctx:          ctx,
 		cancel:       cancel,
 		lastPing:     time.Now(),
 		LastActivity: time.Now(), // Inicializar LastActivity
+		maxMessage:   maxMessage,
 	}
+	m.applyCompression(connection)
 	
 	// Registrar conexão
 	m.register <- connection
@@ -150,6 +228,7 @@ func (m *Manager) run() {
 			m.mu.Lock()
 			if _, ok := m.connections[conn.ID]; ok {
 				delete(m.connections, conn.ID)
+				m.disconnected(conn)
 				close(conn.send)
 			}
 			m.mu.Unlock()
@@ -163,6 +242,7 @@ func (m *Manager) run() {
 				default:
 					// Canal de envio está cheio, remover conexão
 					delete(m.connections, conn.ID)
+					m.disconnected(conn)
 					close(conn.send)
 				}
 			}
@@ -188,6 +268,7 @@ func (m *Manager) SendToConnection(connectionID string, response Response) error
 		return fmt.Errorf("connection not found: %s", connectionID)
 	}
 	
+	ensureErrorCode(&response)
 	select {
 	case conn.send <- response:
 		return nil
@@ -198,6 +279,7 @@ func (m *Manager) SendToConnection(connectionID string, response Response) error
 
 // Broadcast envia uma resposta para todas as conexões
 func (m *Manager) Broadcast(response Response) {
+	ensureErrorCode(&response)
 	select {
 	case m.broadcast <- response:
 	default:
@@ -225,7 +307,7 @@ func (m *Manager) cleanupInactiveConnections() {
 		
 		if now.Sub(lastPing) > 60*time.Second {
 			delete(m.connections, id)
-			conn.cancel()
+			m.disconnected(conn)
 			close(conn.send)
 			log.Printf("Removed inactive connection: %s", id)
 		}
@@ -260,7 +342,6 @@ func (c *Connection) readPump() {
 		c.conn.Close()
 	}()
 	
-	c.conn.SetReadLimit(512 * 1024) // 512KB limit per message
 	c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 	c.conn.SetPongHandler(func(string) error {
 		c.mu.Lock()
@@ -275,13 +356,22 @@ func (c *Connection) readPump() {
 		case <-c.ctx.Done():
 			return
 		default:
-			_, messageBytes, err := c.conn.ReadMessage()
+			messageBytes, tooLarge, err := c.readMessage()
 			if err != nil {
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 					log.Printf("WebSocket error: %v", err)
 				}
 				return
 			}
+			if tooLarge {
+				log.Printf("WebSocket: mensagem acima de %d bytes descartada (%s)", c.maxMessage, c.ID)
+				c.send <- Response{
+					Status:    "error",
+					Error:     fmt.Sprintf("message exceeds the %d byte limit", c.maxMessage),
+					ErrorCode: ErrMessageTooLarge,
+				}
+				continue
+			}
 			
 			var msg Message
 			if err := json.Unmarshal(messageBytes, &msg); err != nil {
@@ -292,6 +382,9 @@ func (c *Connection) readPump() {
 			// LOG DETALHADO DE TODAS AS MENSAGENS
 			log.Printf("🔍 WebSocket: Mensagem recebida - Action: %s, RequestID: %s, Raw: %s", msg.Action, msg.RequestID, string(messageBytes))
 			
+			// Qualquer mensagem prova que o cliente está vivo
+			c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+			
 			// Atualizar LastActivity quando receber mensagem
 			c.mu.Lock()
 			c.LastActivity = time.Now()
@@ -318,6 +411,7 @@ func (c *Connection) readPump() {
 						response := Response{
 							Status:    "error",
 							Error:     err.Error(),
+							ErrorCode: CodeOf(err),
 							RequestID: msg.RequestID,
 						}
 						c.send <- response
@@ -333,6 +427,27 @@ func (c *Connection) readPump() {
 	}
 }
 
+// readMessage lê a próxima mensagem até c.maxMessage bytes. O restante de uma
+// mensagem maior é lido e descartado aos poucos, sem ocupar memória, e tooLarge
+// é verdadeiro.
+func (c *Connection) readMessage() (data []byte, tooLarge bool, err error) {
+	_, reader, err := c.conn.NextReader()
+	if err != nil {
+		return nil, false, err
+	}
+	data, err = io.ReadAll(io.LimitReader(reader, c.maxMessage+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(data)) <= c.maxMessage {
+		return data, false, nil
+	}
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return nil, true, err
+	}
+	return nil, true, nil
+}
+
 // writePump gerencia o envio de mensagens para a conexão
 func (c *Connection) writePump() {
 	defer func() {
@@ -352,7 +467,7 @@ func (c *Connection) writePump() {
 				return
 			}
 			
-			if err := c.conn.WriteJSON(response); err != nil {
+			if err := c.writeResponse(response); err != nil {
 				log.Printf("WebSocket write error: %v", err)
 				return
 			}
@@ -380,6 +495,8 @@ func (c *Connection) Send(response Response) error {
 	default:
 	}
 	
+	ensureErrorCode(&response)
+	
 	// Tentar enviar com timeout e verificação de contexto
 	select {
 	case c.send <- response:
@@ -391,6 +508,44 @@ func (c *Connection) Send(response Response) error {
 	}
 }
 
+// Touch registra um heartbeat do cliente; conta como pong para a limpeza de
+// conexões inativas (alguns proxies não repassam frames de controle)
+func (c *Connection) Touch() {
+	c.mu.Lock()
+	c.lastPing = time.Now()
+	c.LastActivity = time.Now()
+	c.mu.Unlock()
+}
+
+// Locale retorna o idioma configurado para a conexão ("" se não definido)
+func (c *Connection) Locale() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.locale
+}
+
+// SetLocale define o idioma das mensagens enviadas para a conexão
+func (c *Connection) SetLocale(locale string) {
+	c.mu.Lock()
+	c.locale = locale
+	c.mu.Unlock()
+}
+
+// Reviewer retorna o nome e o papel identificados na conexão pelo fluxo de revisão
+func (c *Connection) Reviewer() (string, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.reviewer, c.role
+}
+
+// SetReviewer identifica quem usa a conexão e com qual papel
+func (c *Connection) SetReviewer(name, role string) {
+	c.mu.Lock()
+	c.reviewer = name
+	c.role = role
+	c.mu.Unlock()
+}
+
 // Close fecha a conexão
 func (c *Connection) Close() {
 	c.cancel()
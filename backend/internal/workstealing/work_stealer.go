@@ -7,6 +7,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"go-upload/backend/internal/retry"
 )
 
 // Priority define os níveis de prioridade para tasks
@@ -27,6 +29,7 @@ type Task struct {
 	CreatedAt   time.Time
 	Retries     int
 	MaxRetries  int
+	Retry       *retry.Retrier // Se definido, substitui MaxRetries e o atraso linear
 	Context     context.Context
 	Cancel      context.CancelFunc
 	OnComplete  func(error)
@@ -440,21 +443,25 @@ func (w *Worker) processTask(task *Task) {
 func (w *Worker) completeTask(task *Task, err error) {
 	if err != nil {
 		// Se falhou e ainda tem retries
-		if task.Retries < task.MaxRetries {
+		delay, retryErr := task.retryDelay(err)
+		if retryErr == nil {
 			task.Retries++
 			
 			// Reenviar para a mesma fila com delay
 			go func() {
-				delay := time.Duration(task.Retries) * time.Second
 				time.Sleep(delay)
 				w.pool.Submit(task)
 			}()
 			return
 		}
+		err = retryErr
 		
 		atomic.AddInt64(&w.pool.failedTasks, 1)
 	} else {
 		atomic.AddInt64(&w.pool.completedTasks, 1)
+		if task.Retry != nil {
+			task.Retry.Succeeded(task.Retries + 1)
+		}
 	}
 	
 	// Callback de conclusão
@@ -468,6 +475,18 @@ func (w *Worker) completeTask(task *Task, err error) {
 	}
 }
 
+// retryDelay decide se a task falha com err é reenviada e após quanto tempo;
+// retorna o erro final quando não há mais retries
+func (t *Task) retryDelay(err error) (time.Duration, error) {
+	if t.Retry != nil {
+		return t.Retry.Next(t.Retries+1, err)
+	}
+	if t.Retries < t.MaxRetries {
+		return time.Duration(t.Retries+1) * time.Second, nil
+	}
+	return 0, err
+}
+
 // monitor monitora o pool em background
 func (wp *WorkerPool) monitor() {
 	defer wp.wg.Done()
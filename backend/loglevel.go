@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Log levels accepted by the logLevel setting, from the most to the least verbose
+const (
+	logDebug = iota
+	logInfo
+	logWarn
+	logError
+)
+
+var logLevelNames = map[string]int{
+	"DEBUG":   logDebug,
+	"INFO":    logInfo,
+	"WARN":    logWarn,
+	"WARNING": logWarn,
+	"ERROR":   logError,
+}
+
+// levelWriter drops log lines below the configured level. The server logs through
+// the standard logger, so the level of a line comes from the markers already used
+// in the messages: "[DEBUG]"/"DEBUG:", "[WARN]"/⚠️ and "[ERROR]"/❌; anything else is INFO.
+type levelWriter struct {
+	mutex sync.Mutex
+	out   io.Writer
+	level int
+}
+
+func (w *levelWriter) Write(line []byte) (int, error) {
+	if lineLevel(line) < w.level {
+		return len(line), nil
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.out.Write(line)
+}
+
+// lineLevel classifies a formatted log line
+func lineLevel(line []byte) int {
+	switch {
+	case bytes.Contains(line, []byte("[ERROR]")), bytes.Contains(line, []byte("❌")):
+		return logError
+	case bytes.Contains(line, []byte("[WARN]")), bytes.Contains(line, []byte("⚠️")):
+		return logWarn
+	case bytes.Contains(line, []byte("[DEBUG]")), bytes.Contains(line, []byte("DEBUG:")):
+		return logDebug
+	}
+	return logInfo
+}
+
+// setLogLevel applies the logLevel setting to the standard logger.
+// Empty keeps every line; an unknown name is an error and keeps every line too.
+func setLogLevel(name string) error {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		return nil
+	}
+	level, known := logLevelNames[name]
+	if !known {
+		return fmt.Errorf("unknown logLevel %q (use DEBUG, INFO, WARN or ERROR)", name)
+	}
+	log.SetOutput(&levelWriter{out: os.Stderr, level: level})
+	return nil
+}
@@ -1,30 +1,77 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"go-upload/backend/internal/analytics"
+	"go-upload/backend/internal/backup"
 	"go-upload/backend/internal/anilist"
+	"go-upload/backend/internal/autofill"
+	"go-upload/backend/internal/catalog"
+	"go-upload/backend/internal/checksums"
+	"go-upload/backend/internal/clock"
 	"go-upload/backend/internal/collection"
+	"go-upload/backend/internal/compression"
+	"go-upload/backend/internal/cubari"
+	"go-upload/backend/internal/export"
 	"go-upload/backend/internal/discovery"
+	"go-upload/backend/internal/feed"
 	"go-upload/backend/internal/github"
+	"go-upload/backend/internal/hooks"
+	"go-upload/backend/internal/i18n"
+	"go-upload/backend/internal/integrity"
+	"go-upload/backend/internal/joblog"
+	"go-upload/backend/internal/mangaid"
 	"go-upload/backend/internal/metadata"
 	"go-upload/backend/internal/monitoring"
+	"go-upload/backend/internal/optimize"
+	"go-upload/backend/internal/notifications"
+	"go-upload/backend/internal/overrides"
+	"go-upload/backend/internal/phash"
+	"go-upload/backend/internal/plugins"
+	"go-upload/backend/internal/policy"
+	"go-upload/backend/internal/quality"
+	"go-upload/backend/internal/registry"
+	"go-upload/backend/internal/release"
+	"go-upload/backend/internal/retry"
+	"go-upload/backend/internal/search"
+	"go-upload/backend/internal/mirrorhealth"
+	"go-upload/backend/internal/selfupdate"
+	"go-upload/backend/internal/signing"
+	"go-upload/backend/internal/share"
+	"go-upload/backend/internal/signedurls"
+	"go-upload/backend/internal/sitegen"
+	"go-upload/backend/internal/statussync"
+	"go-upload/backend/internal/sysfiles"
+	"go-upload/backend/internal/tuning"
 	"go-upload/backend/internal/upload"
+	"go-upload/backend/internal/urlcheck"
+	"go-upload/backend/internal/urlrules"
+	"go-upload/backend/internal/webhooks"
 	"go-upload/backend/internal/workstealing"
 	wsmanager "go-upload/backend/internal/websocket"
 	"go-upload/backend/uploaders"
@@ -33,10 +80,26 @@ import (
 // --- Constants ---
 const (
 	LIBRARY_ROOT            = "manga_library" // Default root directory for all scans
-	DEFAULT_MAX_WORKERS     = 100             // Maximum concurrent upload workers
+	DEFAULT_MAX_WORKERS     = 100             // Maximum concurrent upload workers (ceiling of auto-tuning)
 	DEFAULT_MAX_CONNECTIONS = 1000            // Maximum WebSocket connections
 	SERVER_PORT             = ":8080"
-	DISCOVERY_WORKERS       = 20              // Workers for concurrent discovery
+	DISCOVERY_WORKERS       = 20              // Workers for concurrent discovery (ceiling of auto-tuning)
+	DEFAULT_MAX_CONCURRENT_COLLECTIONS = 1    // Collections executing at the same time (others wait in queue)
+	DEFAULT_COLLECTION_BATCH_SIZE = 50        // Files queued per collection batch
+	DEFAULT_MAX_FILE_CONTENT_SIZE = 10 << 20  // Decoded fileContent bytes per file
+	FAST_TIMERS_FACTOR      = 0.1             // --fast-timers: periodic timers run 10x faster
+)
+
+// Limits of lowMemory mode (1GB NAS boxes, Raspberry Pis)
+const (
+	LOW_MEMORY_MAX_WORKERS        = 8   // Upload workers
+	LOW_MEMORY_DISCOVERY_WORKERS  = 2   // Discovery workers
+	LOW_MEMORY_COLLECTION_BATCH   = 10  // Files queued per collection batch
+	LOW_MEMORY_SEND_BUFFER        = 32  // Queued responses per WebSocket connection
+	LOW_MEMORY_JOB_STREAM_BUFFER  = 100 // Buffered progress messages per job
+	LOW_MEMORY_GC_PERCENT         = 50  // GOGC: collect twice as often
+	LOW_MEMORY_MAX_MESSAGE_SIZE   = 4 << 20 // WebSocket message bytes
+	LOW_MEMORY_MAX_FILE_CONTENT   = 3 << 20 // Decoded fileContent bytes per file
 )
 
 // --- High-Performance Server ---
@@ -46,24 +109,72 @@ type HighPerformanceServer struct {
 	batchUploader     *upload.BatchUploader
 	discoverer        *discovery.ConcurrentDiscoverer
 	monitor           *monitoring.Monitor
+	clock             clock.Clock             // Tickers and waits of the periodic loops (scaled by --fast-timers)
 	collectionProcessor *collection.CollectionProcessor
 	workerPool        *workstealing.WorkerPool
 	jsonGenerator     *metadata.JSONGenerator
 	anilistService    *anilist.AniListService  // Phase 2.3: AniList integration
 	githubService     *github.GitHubService   // GitHub integration
+	idRegistry        *registry.Registry      // Local manga ↔ provider ID mapping
+	titleOverrides    *overrides.Store        // Folder name → canonical title/provider ID, consulted before searching
+	notifications     *notifications.Store    // Failures, finished collections, takedowns and pending approvals kept until read
+	takedownsSeen     map[string]int          // Unavailable pages per host at the last mirror check (new takedowns only)
+	takedownsMu       sync.Mutex
+	fieldLocks        *metadata.FieldLocks    // Metadata fields protected from automatic updates
+	jsonQuarantine    *metadata.Quarantine    // Repaired/quarantined JSON report (get_corrupt_jsons)
+	shareLinks        *share.Signer           // Read-only, time-limited collection progress links (/share/)
+	autoFiller        *autofill.Filler        // Library-wide metadata auto-fill job
+	statusRefresher   *statussync.Refresher   // Periodic status refresh of linked manga
+	uploadHistory     *analytics.History      // Successful uploads, source of get_series_analytics
+	mirrorChecker     *mirrorhealth.Checker   // Periodic availability scoring of mirror hosts
+	urlRefresher      *signedurls.Refresher   // Re-signs expiring pre-signed URLs of private buckets
+	releaseBuilder    *release.Builder        // Markdown/BBCode release posts of finished batches
+	feedGenerator     *feed.Generator         // RSS feeds of recent chapters (/feed.xml)
+	catalogIndex      *catalog.Generator      // Aggregate index.json of the library (/index.json, GitHub sync)
+	libraryIndex      *search.Index           // Full-text index of the local JSONs (search_library)
+	siteConfig        sitegen.Config          // Static reader site built by generate_static_site
+	siteMu            sync.Mutex              // One static site generation at a time
+	jobStreams        *wsmanager.JobStreams   // Buffered progress of collections, claimable after a disconnect
+	jobLogs           *joblog.Store           // Per-batch/collection log lines served by get_job_logs
+	updater           *selfupdate.Updater     // GitHub release checks and binary replacement (check_update/apply_update); nil when the update section is invalid
+	updaterErr        error                   // Why updater is nil, reported by check_update/apply_update
+	plugins           *plugins.Manager        // External uploader and metadata provider plugins
+	hooks             *hooks.Runner           // Shell/webhook hooks around discovery, chapter upload and JSON generation
+	mangaWebhooks     *webhooks.Notifier      // Per-manga webhooks (e.g. the group's CMS) told about each newly published chapter
+	contentPolicy     *policy.Engine          // Pre-upload format/dimension/size rules and NSFW classification
+	qualityAnalyzer   *quality.Analyzer       // Per-chapter QC stats of estimates and collections (nil = disabled)
+	backupStore       *backup.Store           // Local copies of uploaded files, checked by verify_backup
+	checksums         *checksums.Writer       // Per-chapter checksums.json manifests (nil = disabled)
+	signer            *signing.Signer         // Signs the series JSONs synced to GitHub (nil = disabled)
+	telegraph         *uploaders.TelegraphUploader // Publishes a telegra.ph page per chapter written to the JSONs (nil = disabled)
+	integrityScanner  *integrity.Scanner      // Cross-checks library folders, ID registry, JSONs and upload history
+	restartRequested  chan string             // Path of a newly installed binary; main restarts into it
+	restarting        int32                   // 1 while shutting down to restart into a new binary
+	tuner             *tuning.Tuner           // CPU/memory/throughput based worker recommendation (get_tuning)
+	autoTuned         bool                    // maxWorkers came from the tuner, so it keeps following it
+	uploadLimit       int32                   // Concurrency of new batches; lowered by the tuner when auto-tuned
 	
 	// JSON generation tracking
 	uploadResults     map[string][]metadata.UploadedFile  // Track real upload results by batchID
 	batchMangaTitles  map[string]map[string]string         // Track manga titles by batchID -> mangaID -> title
+	deferredBatches   map[string]bool                      // Batches uploaded with deferPublish: no JSON until publish_chapter
+	uploadResultsTouched map[string]time.Time              // Last write to uploadResults/batchMangaTitles per batchID (state cleanup)
 	uploadResultsMu   sync.RWMutex                        // Protect upload tracking maps
+	resultSpool       *metadata.ResultSpool               // lowMemory: upload results on disk instead of uploadResults
+	metadataMu        sync.Mutex                          // Serializes every read-modify-write of the manga JSONs
+	
+	// Emergency stop / safe mode (1 = uploads disabled)
+	uploadsDisabled   int32
 	
 	// Configuration
 	config            *ServerConfig
+	paths             DataPaths
 	
 	// Lifecycle management
 	ctx               context.Context
 	cancel            context.CancelFunc
 	wg                sync.WaitGroup
+	ready             int32 // 1 once listening, back to 0 while shutting down
 	
 	// HTTP server
 	httpServer        *http.Server
@@ -71,14 +182,63 @@ type HighPerformanceServer struct {
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	MaxWorkers       int    `json:"maxWorkers"`
+	MaxWorkers       int    `json:"maxWorkers"`       // 0 = sized from CPU and memory (see Tuning)
 	MaxConnections   int    `json:"maxConnections"`
-	DiscoveryWorkers int    `json:"discoveryWorkers"`
+	DiscoveryWorkers int    `json:"discoveryWorkers"` // 0 = sized from CPU
+	MaxConcurrentCollections int `json:"maxConcurrentCollections"`
 	Port             string `json:"port"`
+	DataDir          string `json:"dataDir"`        // Root of all on-disk state (see DataPaths)
 	LibraryRoot      string `json:"libraryRoot"`
-	MetadataOutput   string `json:"metadataOutput"`
+	MetadataOutput   string `json:"metadataOutput"` // Empty = <dataDir>/json
+	PluginDir        string `json:"pluginDir,omitempty"` // Empty = <dataDir>/plugins
+	Symlinks         string `json:"symlinks,omitempty"`  // Discovery symlink handling: "follow" (default, cycle-safe) or "ignore"
+	IncludeHiddenFiles bool `json:"includeHiddenFiles,omitempty"` // Keep system/hidden files (.DS_Store, Thumbs.db, desktop.ini, dotfiles, ._ forks) in discovery, collections and batches
 	EnableMetrics    bool   `json:"enableMetrics"`
-	LogLevel         string `json:"logLevel"`
+	LogLevel         string `json:"logLevel"` // DEBUG, INFO, WARN or ERROR; lines below it are dropped (empty = all)
+	Hosts            []string `json:"hosts,omitempty"` // Enabled upload hosts (empty = all)
+	HostURLRules     map[string][]urlrules.Rule `json:"hostUrlRules,omitempty"` // Per-host rewrites of returned URLs (viewer page → direct image) before JSON generation
+	URLValidation    *urlcheck.Config `json:"urlValidation,omitempty"` // Returned URLs must be well-formed https, not placeholders, optionally answering a HEAD
+	Tus              *uploaders.TusConfig `json:"tus,omitempty"` // Resumable chunked uploads to a tus server
+	S3               *uploaders.S3Config  `json:"s3,omitempty"`  // S3-compatible bucket (multipart for large files)
+	R2               *uploaders.R2Config  `json:"r2,omitempty"`  // Cloudflare R2 bucket behind a custom domain
+	CloudflareImages *uploaders.CloudflareImagesConfig `json:"cloudflareImages,omitempty"` // Cloudflare Images ("cfimages" host)
+	WebDAV           *uploaders.WebDAVConfig `json:"webdav,omitempty"` // Nextcloud/ownCloud or generic WebDAV storage
+	Remote           *uploaders.RemoteConfig `json:"remote,omitempty"` // SFTP/FTP(S) web host; registered under its protocol name
+	Checksums        *checksums.Config `json:"checksums,omitempty"` // Per-chapter checksums.json (file → sha256 → URL) in the library and/or the GitHub repo
+	Signing          *signing.Config `json:"signing,omitempty"` // ed25519 key signing the JSONs synced to GitHub (.sig files or embedded JWS)
+	Imgur            *uploaders.ImgurConfig  `json:"imgur,omitempty"`  // Imgur, anonymous (clientId) or on an account (accessToken); one album per chapter
+	ImgBB            *uploaders.ImgBBConfig  `json:"imgbb,omitempty"`  // ImgBB (apiKey), optionally expiring uploads
+	Pixeldrain       *uploaders.PixeldrainConfig `json:"pixeldrain,omitempty"` // Pixeldrain account (apiKey); stops before the monthly transfer quota runs out
+	ImgChest         *uploaders.ImgChestConfig `json:"imgchest,omitempty"` // ImgChest (accessToken); one post per chapter
+	Gofile           *uploaders.GofileConfig `json:"gofile,omitempty"` // Gofile, anonymous or on an account (token); one folder per chapter
+	Telegraph        *uploaders.TelegraphConfig `json:"telegraph,omitempty"` // Telegraph (accessToken): image host and one telegra.ph page per chapter as the "<group> (telegra.ph)" group
+	Release          *release.Config `json:"release,omitempty"` // Reader link and custom templates for get_release_post
+	Review           *ReviewConfig   `json:"review,omitempty"`  // Chapter review workflow (uploaded → qc → approved → published) and team roles
+	Feed             *feed.Config    `json:"feed,omitempty"`    // RSS feed title, site and chapter links
+	Site             *sitegen.Config `json:"site,omitempty"`    // Static reader site title and output folder
+	Update           *selfupdate.Config `json:"update,omitempty"` // Release repo, channel and signing key for self-update
+	Hooks            []hooks.Hook    `json:"hooks,omitempty"`   // Commands/webhooks run around pipeline stages
+	MangaWebhooks    *webhooks.Config `json:"mangaWebhooks,omitempty"` // Signed webhook per manga called with each newly published chapter
+	Policy           *policy.Config  `json:"policy,omitempty"`  // Allowed formats/dimensions/size and NSFW genres/tags
+	Optimize         *optimize.Config `json:"optimize,omitempty"` // Lossless PNG/JPEG recompression before upload
+	Compression      *compression.Config `json:"compression,omitempty"` // gzip/deflate HTTP responses and WebSocket permessage-deflate
+	Tuning           *tuning.Config  `json:"tuning,omitempty"`  // Worker auto-tuning from CPU, memory and upload throughput
+	Quality          *quality.Config `json:"quality,omitempty"` // Small/blank page and resolution variance checks per chapter
+	Backup           *backup.Config  `json:"backup,omitempty"`  // Local copy of every uploaded file, verified against the upload history
+	Profile          string `json:"profile,omitempty"`
+	SafeMode         bool   `json:"safeMode"`
+	ReadOnly         bool   `json:"readOnly,omitempty"` // Mirror of a synced data directory: only catalog, search and stats actions, nothing is written
+	OfflineDBPath    string `json:"offlineDbPath,omitempty"`
+	FastTimers       bool   `json:"fastTimers,omitempty"` // Debug: metrics, JSON polling and rate limiter refills run 10x faster
+	StatusRefreshInterval string `json:"statusRefreshInterval,omitempty"` // e.g. "24h"; empty = manual refresh only
+	MirrorHealthInterval string `json:"mirrorHealthInterval,omitempty"` // e.g. "6h"; empty = manual check only
+	SignedURLRefreshInterval string `json:"signedUrlRefreshInterval,omitempty"` // Default "1h" when a bucket uses signed URLs
+	ResumeOnStart    bool   `json:"resumeOnStart"` // Resume collections interrupted by a restart
+	LowMemory        bool   `json:"lowMemory,omitempty"` // Small worker pools, buffers and GC target; upload results spooled to disk
+	KeepImageMetadata bool  `json:"keepImageMetadata,omitempty"` // Upload images with EXIF/XMP untouched (stripped by default)
+	MaxMessageSize   int64  `json:"maxMessageSize,omitempty"`     // Bytes per WebSocket message; 0 = 16MB (4MB in lowMemory)
+	MaxFileContentSize int64 `json:"maxFileContentSize,omitempty"` // Decoded bytes per base64 fileContent; 0 = 10MB (3MB in lowMemory)
+	StateRetention   *StateRetention `json:"stateRetention,omitempty"` // Age-based cleanup of finished batches, collections and upload results
 }
 
 // WebSocket request/response types (updated for new architecture)
@@ -95,6 +255,10 @@ type WebSocketRequest struct {
 	Uploads         []upload.UploadRequest     `json:"uploads,omitempty"`
 	Options         *upload.BatchOptions       `json:"options,omitempty"`
 	BatchID         string                     `json:"batchId,omitempty"`
+	IdempotencyKey  string                     `json:"idempotencyKey,omitempty"`
+	MirrorHosts     []string                   `json:"mirrorHosts,omitempty"` // Upload every file to these hosts as well
+	Language        string                     `json:"language,omitempty"`    // Chapter language (BCP 47) for files that don't set their own
+	DeferPublish    bool                       `json:"deferPublish,omitempty"` // Upload only: the JSON is written later by publish_chapter
 	
 	// JSON generation fields (new)
 	IncludeJSON              bool                       `json:"includeJSON,omitempty"`
@@ -108,15 +272,63 @@ type WebSocketRequest struct {
 	CollectionID    string                     `json:"collectionId,omitempty"`
 	ParallelLimit   int                        `json:"parallelLimit,omitempty"`
 	CollectionOptions *CollectionProcessingOptions `json:"collectionOptions,omitempty"`
+	CollectionOrder []string                   `json:"collectionOrder,omitempty"`
+	TTL             string                     `json:"ttl,omitempty"` // create_share_link: link lifetime ("2h", "7d"); 24h by default
+	
+	// Job ownership fields (claim_job)
+	JobID           string                     `json:"jobId,omitempty"`
+	ClaimToken      string                     `json:"claimToken,omitempty"`
+	Since           int64                      `json:"since,omitempty"` // Replay only events after this sequence number
+	ActiveOnly      bool                       `json:"activeOnly,omitempty"` // list_jobs: skip finished jobs
+	Level           string                     `json:"level,omitempty"`      // get_job_logs: minimum level (info, warn, error)
+	Force           bool                       `json:"force,omitempty"`      // apply_update: restart even while batches are uploading
+	Provider        string                     `json:"provider,omitempty"`   // search_metadata/get_metadata_details: metadata source
+	ProviderID      string                     `json:"providerId,omitempty"` // get_metadata_details: series ID at the provider
+	DetectDuplicates bool                      `json:"detectDuplicates,omitempty"`   // check_upload_policy: also report visually duplicate pages
+	DuplicateThreshold int                     `json:"duplicateThreshold,omitempty"` // check_upload_policy: max perceptual hash distance (default 6)
+	AutoRepair      bool                       `json:"autoRepair,omitempty"` // scan_integrity: repair every fixable issue once the scan ends
+	IssueIDs        []string                   `json:"issueIds,omitempty"`   // repair_integrity: issues to repair (empty = all fixable)
+	Targets         []string                   `json:"targets,omitempty"`   // purge_internal_state: uploadResults, batches, collections (empty = all)
+	IDs             []string                   `json:"ids,omitempty"`       // purge_internal_state: entries to remove regardless of age; mark_read: notifications to mark as read
+	OlderThan       string                     `json:"olderThan,omitempty"` // purge_internal_state/cleanup_state: age cutoff ("0s" = every finished entry; empty = stateRetention)
+	MaxCount        int                        `json:"maxCount,omitempty"`  // cleanup_state: finished collection states to keep, newest first
+	IncludeArchived bool                       `json:"includeArchived,omitempty"` // discovery/analytics: also show archived series
+	Reason          string                     `json:"reason,omitempty"`          // archive_series: why the series was archived (dropped, licensed...)
+	Query           string                     `json:"query,omitempty"`           // search_library: words to find in titles, authors and descriptions
+	Limit           int                        `json:"limit,omitempty"`           // search_library: max results (default 50)
+	SourceURL       string                     `json:"sourceUrl,omitempty"`       // import_cubari: cubari.moe series link or the URL of its JSON
+	Format          string                     `json:"format,omitempty"`          // export_chapter: "folder" (default) or "cbz"
+	OutputDir       string                     `json:"outputDir,omitempty"`       // export_chapter: destination directory (default <dataDir>/exports/<manga>)
+	Fetch           bool                       `json:"fetch,omitempty"`           // verify_backup: download missing or corrupted copies from the hosts
+	Name            string                     `json:"name,omitempty"`            // save/apply/delete_anilist_profile: preset name
+	Override        *overrides.Override        `json:"override,omitempty"`        // set/remove_title_override: folder name → canonical title/provider ID
+	ReviewerToken   string                     `json:"reviewerToken,omitempty"`   // set_reviewer: token of a review.users entry
+	Status          string                     `json:"status,omitempty"`          // set_chapter_status: target review status; list_chapter_reviews: filter
+	Note            string                     `json:"note,omitempty"`            // set_chapter_status: QC feedback shown with the status
+	UnreadOnly      bool                       `json:"unreadOnly,omitempty"`      // get_notifications: skip notifications already read
+	All             bool                       `json:"all,omitempty"`             // mark_read: mark every notification as read
+	Sections        []string                   `json:"sections,omitempty"`        // get_metrics: sections to return (metrics, performance, hosts, advanced, collections, history, connections, retries)
+	Collections     []string                   `json:"collections,omitempty"`     // get_metrics: collection IDs for the collections section (empty = all)
+	From            string                     `json:"from,omitempty"`            // get_metrics: history start (RFC 3339 or a duration ago, e.g. "30m")
+	To              string                     `json:"to,omitempty"`              // get_metrics: history end
 	
 	// Metadata editing fields
 	Payload         map[string]interface{}     `json:"payload,omitempty"`
+	Link            *metadata.RelatedSeries    `json:"link,omitempty"`
+	Unlink          bool                       `json:"unlink,omitempty"`
+	Reciprocal      bool                       `json:"reciprocal,omitempty"`
+	DropBelow       float64                    `json:"dropBelow,omitempty"` // Mirror failover: drop mirrors scoring below this
+	DryRun          bool                       `json:"dryRun,omitempty"`    // migrate_json_filenames: report without renaming
 	
 	// AniList integration fields (Phase 2.3)
 	SearchQuery     string                     `json:"searchQuery,omitempty"`
 	AniListID       int                        `json:"anilistId,omitempty"`
 	MangaTitle      string                     `json:"mangaTitle,omitempty"`
 	SelectedResult  map[string]interface{}     `json:"selectedResult,omitempty"`
+	DumpSource      string                     `json:"dumpSource,omitempty"`
+	Fields          []string                   `json:"fields,omitempty"`
+	Group           string                     `json:"group,omitempty"`
+	Resume          bool                       `json:"resume,omitempty"`
 	
 	// GitHub integration fields
 	Token           string                     `json:"token,omitempty"`
@@ -124,6 +336,51 @@ type WebSocketRequest struct {
 	Branch          string                     `json:"branch,omitempty"`
 	Folder          string                     `json:"folder,omitempty"`
 	GitHubSettings  map[string]interface{}     `json:"githubSettings,omitempty"`
+	Private         bool                       `json:"private,omitempty"` // github_bootstrap: visibility of a new repository
+	
+	// Localization
+	Locale          string                     `json:"locale,omitempty"`
+	Encoding        string                     `json:"encoding,omitempty"` // set_encoding: json or msgpack
+}
+
+// JobListEntry is one batch or collection in the list_jobs dashboard
+type JobListEntry struct {
+	Kind      string          `json:"kind"` // "batch" or "collection"
+	ID        string          `json:"id"`
+	Name      string          `json:"name,omitempty"`
+	State     string          `json:"state"`
+	Owner     string          `json:"owner,omitempty"`    // Connection receiving the progress stream
+	Orphaned  bool            `json:"orphaned,omitempty"` // Running without an owner; adopt it with claim_job
+	Progress  JobListProgress `json:"progress"`
+	StartedAt time.Time       `json:"startedAt"`
+	EndedAt   *time.Time      `json:"endedAt,omitempty"`
+	Controls  []string        `json:"controls"` // Actions that apply to the job in its current state
+	Detail    interface{}     `json:"detail"`   // Kind-specific summary (upload.BatchSummary or collection.JobSummary)
+}
+
+// JobListProgress is the file progress shared by batches and collections
+type JobListProgress struct {
+	Done       int64   `json:"done"`
+	Failed     int64   `json:"failed"`
+	Total      int64   `json:"total"`
+	Percentage float64 `json:"percentage"`
+}
+
+func newJobListProgress(done, failed, total int64) JobListProgress {
+	progress := JobListProgress{Done: done, Failed: failed, Total: total}
+	if total > 0 {
+		progress.Percentage = float64(done+failed) / float64(total) * 100
+	}
+	return progress
+}
+
+// active reports whether the job is still running or waiting to run
+func (e JobListEntry) active() bool {
+	switch e.State {
+	case "running", "queued", string(collection.StatusPending):
+		return true
+	}
+	return false
 }
 
 // BatchFileInfo represents file information from frontend
@@ -133,6 +390,7 @@ type BatchFileInfo struct {
 	Chapter   string `json:"chapter"`
 	FileName  string `json:"fileName"`
 	FileSize  int64  `json:"fileSize"`
+	Language  string `json:"language,omitempty"`
 }
 
 // CollectionProcessingOptions define as opções para processamento de coleções
@@ -142,7 +400,25 @@ type CollectionProcessingOptions struct {
 	MaxConcurrency   int    `json:"maxConcurrency"`
 	BatchSize        int    `json:"batchSize"`
 	RetryAttempts    int    `json:"retryAttempts"`
+	RetryBudget      int    `json:"retryBudget,omitempty"` // Retries da coleção inteira (0 = um por arquivo; -1 = sem limite)
 	EnablePersistence bool  `json:"enablePersistence"`
+	Priority         int    `json:"priority,omitempty"`
+	
+	// Orçamento de recursos da coleção
+	MaxWorkers       int    `json:"maxWorkers,omitempty"`
+	MaxBandwidthBPS  int64  `json:"maxBandwidthBps,omitempty"`
+	MaxSpoolBytes    int64  `json:"maxSpoolBytes,omitempty"`
+	
+	// Mantém EXIF/XMP das imagens (removidos por padrão)
+	KeepMetadata     bool   `json:"keepMetadata,omitempty"`
+	// Recomprime PNG/JPEG sem perdas (padrão: seção optimize da configuração)
+	Optimize         bool   `json:"optimize,omitempty"`
+	// Pula páginas idênticas (hash perceptual) a outra do mesmo capítulo
+	SkipDuplicatePages bool `json:"skipDuplicatePages,omitempty"`
+	// Não envia páginas em branco nem idênticas à anterior (registradas no relatório de qualidade)
+	AutoRemovePages  bool   `json:"autoRemovePages,omitempty"`
+	// Inclui arquivos de sistema e ocultos (.DS_Store, Thumbs.db, dotfiles), ignorados por padrão
+	IncludeHidden    bool   `json:"includeHidden,omitempty"`
 }
 
 // Legacy compatibility types
@@ -166,6 +442,7 @@ type HierarchyMetadata struct {
 	TotalLevels  int               `json:"totalLevels"`
 	LevelMap     map[string]string `json:"levelMap"`
 	Stats        HierarchyStats    `json:"stats"`
+	Symlinks     []discovery.SymlinkEntry `json:"symlinks,omitempty"`
 }
 
 type HierarchyStats struct {
@@ -230,6 +507,16 @@ func generateOrderedJSON(data map[string]interface{}) ([]byte, error) {
 	chaptersStr := strings.ReplaceAll(string(chaptersJSON), "\n  ", "\n    ")
 	chaptersStr = strings.TrimPrefix(chaptersStr, "  ")
 	
+	// Related series block (seasons, spin-offs) is only written when present
+	relatedStr := ""
+	if related, ok := data["related"].([]interface{}); ok && len(related) > 0 {
+		relatedJSON, err := json.MarshalIndent(related, "  ", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal related series: %v", err)
+		}
+		relatedStr = fmt.Sprintf("\n  \"related\": %s,", relatedJSON)
+	}
+	
 	// Build JSON manually with exact field order and indentation
 	jsonStr := fmt.Sprintf(`{
   "title": %q,
@@ -238,7 +525,7 @@ func generateOrderedJSON(data map[string]interface{}) ([]byte, error) {
   "author": %q,
   "cover": %q,
   "status": %q,
-  "group": %q,
+  "group": %q,%s
   "chapters": %s
 }`,
 		getValue("title"),
@@ -248,6 +535,7 @@ func generateOrderedJSON(data map[string]interface{}) ([]byte, error) {
 		getValue("cover"),
 		getValue("status"),
 		getValue("group"),
+		relatedStr,
 		chaptersStr)
 	
 	return []byte(jsonStr), nil
@@ -257,17 +545,79 @@ func generateOrderedJSON(data map[string]interface{}) ([]byte, error) {
 func NewHighPerformanceServer(config *ServerConfig) *HighPerformanceServer {
 	ctx, cancel := context.WithCancel(context.Background())
 	
+	// Resolve the on-disk layout under the data directory
+	paths := resolveDataPaths(config)
+	config.MetadataOutput = paths.JSONOutput
+	if err := paths.ensure(); err != nil {
+		log.Printf("⚠️ Failed to prepare data directory: %v", err)
+	}
+	
+	// Periodic timers follow an injectable clock; --fast-timers speeds them up for debugging
+	clk := clock.Real()
+	if config.FastTimers {
+		clk = clock.Scaled(FAST_TIMERS_FACTOR)
+		log.Printf("⚠️ Fast timers enabled: periodic timers run %.0fx faster", 1/FAST_TIMERS_FACTOR)
+	}
+	
 	// Initialize monitoring
-	monitor := monitoring.NewMonitor()
+	monitor := monitoring.NewMonitorWithClock(clk)
+	
+	// Size the worker pools from the machine when maxWorkers/discoveryWorkers are not set
+	tuningConfig := tuning.Config{}
+	if config.Tuning != nil {
+		tuningConfig = *config.Tuning
+	}
+	tuningInterval, err := tuningConfig.IntervalDuration()
+	if err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+	tuner := tuning.NewTuner(tuningInterval, tuning.Recommendation{
+		MaxWorkers:       DEFAULT_MAX_WORKERS,
+		DiscoveryWorkers: DISCOVERY_WORKERS,
+	}, func() int64 {
+		return monitor.GetMetrics().BytesUploaded
+	})
+	autoTuned := resolveConcurrency(config, tuner, tuningConfig.Disabled)
+	if config.LowMemory {
+		applyLowMemory(config)
+	}
 	
 	// Initialize WebSocket manager
 	wsManager := wsmanager.NewManager()
+	wsManager.SetMaxMessageSize(config.maxMessageSize())
+	jobStreamBuffer := 500
+	if config.LowMemory {
+		wsManager.SetSendBuffer(LOW_MEMORY_SEND_BUFFER)
+		jobStreamBuffer = LOW_MEMORY_JOB_STREAM_BUFFER
+	}
 	
 	// Initialize batch uploader with high concurrency
 	batchUploader := upload.NewBatchUploader(wsManager, config.MaxWorkers)
+	batchUploader.SetClock(clk)
+	if err := batchUploader.SetSpoolDir(paths.Spool); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+	if err := batchUploader.SetChunkSessionsPath(paths.ChunkSessions); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+	batchUploader.SetLowMemory(config.LowMemory)
+	
+	// Low-memory mode keeps the results of running batches on disk until their JSONs are generated
+	var resultSpool *metadata.ResultSpool
+	if config.LowMemory {
+		if resultSpool, err = metadata.NewResultSpool(filepath.Join(paths.Spool, "results")); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+	}
 	
 	// Initialize concurrent discoverer
 	discoverer := discovery.NewConcurrentDiscoverer(config.DiscoveryWorkers)
+	symlinkMode, err := discovery.ParseSymlinkMode(config.Symlinks)
+	if err != nil {
+		log.Printf("⚠️ %v; following symlinks", err)
+	}
+	discoverer.SetSymlinkMode(symlinkMode)
+	discoverer.SetIncludeHidden(config.IncludeHiddenFiles)
 	
 	// Initialize worker pool for massive processing
 	workerPool := workstealing.NewWorkerPool(config.MaxWorkers)
@@ -275,47 +625,497 @@ func NewHighPerformanceServer(config *ServerConfig) *HighPerformanceServer {
 	// Initialize collection processor with advanced capabilities
 	collectionConfig := &collection.ProcessorConfig{
 		MaxConcurrency:    config.MaxWorkers,
-		BatchSize:         50,
+		BatchSize:         config.collectionBatchSize(),
 		RetryAttempts:     3,
 		RetryDelay:        2 * time.Second,
 		ProgressInterval:  5 * time.Second,
 		EnablePersistence: true,
-		StateFilePath:     "collection_state",
+		StateFilePath:     paths.CollectionState,
+		MaxConcurrentCollections: config.MaxConcurrentCollections,
 	}
 	collectionProcessor := collection.NewCollectionProcessor(collectionConfig)
+	collectionProcessor.SetSpoolDir(paths.Spool)
 	
 	// Initialize JSON generator
 	jsonGenerator := metadata.NewJSONGenerator(config.LibraryRoot, "scan_group")
+	jsonGenerator.SetJSONDir(paths.JSONOutput)
+	
+	// Field locks: curated metadata is never overwritten by merges or auto-fill
+	fieldLocks, err := metadata.NewFieldLocks(paths.FieldLocks)
+	if err != nil {
+		log.Printf("⚠️ Failed to load metadata field locks: %v", err)
+	}
+	jsonGenerator.SetFieldLocks(fieldLocks)
+	
+	// Corrupted JSONs are repaired or quarantined (.corrupt) instead of being skipped or overwritten
+	jsonQuarantine, err := jsonGenerator.EnableQuarantine(paths.CorruptReport)
+	if err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+	// A read-only mirror never repairs or renames the JSONs it mirrors
+	if !config.ReadOnly {
+		if found, err := jsonQuarantine.Scan(); err != nil {
+			log.Printf("⚠️ Failed to check JSON files: %v", err)
+		} else {
+			for _, entry := range found {
+				log.Printf("⚠️ JSON %s %s: %s", entry.File, entry.Action, entry.Error)
+			}
+		}
+	}
+	
+	// Shared progress links are signed with a key kept in the data directory
+	// (a mirror runs no collections, so it neither issues links nor creates the key)
+	var shareLinks *share.Signer
+	if !config.ReadOnly {
+		if shareLinks, err = share.NewSigner(paths.ShareSecret); err != nil {
+			log.Printf("⚠️ Shared progress links disabled: %v", err)
+			shareLinks = nil
+		}
+	}
 	
 	// Initialize AniList service (Phase 2.3)
-	anilistService := anilist.NewAniListService()
+	// Cover images are cached locally and served over /covers/{hash}
+	// Search cache, config and offline database live next to the cache file in the data directory
+	anilistService := anilist.NewAniListServiceOptimized(&anilist.DefaultLogger{}, time.Hour, paths.AniListCache, true, paths.Covers)
+	if config.OfflineDBPath != "" {
+		if err := anilistService.SetOfflineDumpPath(config.OfflineDBPath); err != nil {
+			log.Printf("⚠️ Failed to load offline metadata database %s: %v", config.OfflineDBPath, err)
+		}
+	}
 	
 	// Initialize GitHub service
 	githubService := github.NewGitHubService()
 	
-	// Register uploaders
-	catboxUploader := uploaders.NewCatboxUploader()
-	batchUploader.RegisterUploader("catbox", catboxUploader)
+	// Initialize ID registry (local manga ↔ AniList IDs)
+	idRegistry, err := registry.New(paths.Registry)
+	if err != nil {
+		log.Printf("⚠️ Failed to load ID registry: %v", err)
+	}
+	// Series whose JSON predates the registry are tracked too, so searches don't report them as new
+	// (a read-only mirror uses the registry it receives as is: backfilling saves it)
+	if !config.ReadOnly {
+		if added, err := idRegistry.Backfill(config.MetadataOutput); err != nil {
+			log.Printf("⚠️ Failed to backfill ID registry: %v", err)
+		} else if added > 0 {
+			log.Printf("📇 ID registry backfilled with %d series from %s", added, config.MetadataOutput)
+		}
+	}
+	
+	// User-editable folder name → canonical title mapping (hand edits are picked up on the next lookup)
+	titleOverrides, err := overrides.New(paths.TitleOverrides)
+	if err != nil {
+		log.Printf("⚠️ Failed to load title overrides: %v", err)
+	}
+	
+	// Events kept for users who are not connected when they happen
+	notificationStore, err := notifications.New(paths.Notifications, notifications.DefaultMaxItems)
+	if err != nil {
+		log.Printf("⚠️ Failed to load notifications: %v", err)
+	}
+	
+	// Periodic status refresh of manga linked to AniList
+	var statusRefreshInterval time.Duration
+	if config.StatusRefreshInterval != "" {
+		statusRefreshInterval, err = time.ParseDuration(config.StatusRefreshInterval)
+		if err != nil {
+			log.Printf("⚠️ Invalid statusRefreshInterval %q: %v", config.StatusRefreshInterval, err)
+		}
+	}
+	// Periodic availability scoring of mirrored hosts
+	var mirrorHealthInterval time.Duration
+	if config.MirrorHealthInterval != "" {
+		mirrorHealthInterval, err = time.ParseDuration(config.MirrorHealthInterval)
+		if err != nil {
+			log.Printf("⚠️ Invalid mirrorHealthInterval %q: %v", config.MirrorHealthInterval, err)
+		}
+	}
+	mirrorChecker := mirrorhealth.NewChecker(mirrorhealth.Config{
+		JSONDir:  paths.JSONOutput,
+		Interval: mirrorHealthInterval,
+	})
+	
+	// Register uploaders (private buckets also feed the signed URL refresher)
+	var urlSigners []signedurls.Signer
+	if config.hostEnabled("catbox") {
+		catboxUploader := uploaders.NewCatboxUploader()
+		batchUploader.RegisterUploader("catbox", catboxUploader)
+	}
+	if config.Tus != nil && config.hostEnabled("tus") {
+		if tusUploader, err := uploaders.NewTusUploader(*config.Tus); err != nil {
+			log.Printf("⚠️ tus host disabled: %v", err)
+		} else {
+			batchUploader.RegisterUploader("tus", tusUploader)
+		}
+	}
+	if config.S3 != nil && config.hostEnabled("s3") {
+		if s3Uploader, err := uploaders.NewS3Uploader(*config.S3); err != nil {
+			log.Printf("⚠️ s3 host disabled: %v", err)
+		} else {
+			batchUploader.RegisterUploader("s3", s3Uploader)
+			if config.S3.SignedURLs {
+				urlSigners = append(urlSigners, s3Uploader)
+			}
+		}
+	}
+	if config.R2 != nil && config.hostEnabled("r2") {
+		if r2Uploader, err := uploaders.NewR2Uploader(*config.R2); err != nil {
+			log.Printf("⚠️ r2 host disabled: %v", err)
+		} else {
+			batchUploader.RegisterUploader("r2", r2Uploader)
+			if config.R2.SignedURLs {
+				urlSigners = append(urlSigners, r2Uploader)
+			}
+		}
+	}
+	if config.CloudflareImages != nil && config.hostEnabled("cfimages") {
+		if imagesUploader, err := uploaders.NewCloudflareImagesUploader(*config.CloudflareImages); err != nil {
+			log.Printf("⚠️ cfimages host disabled: %v", err)
+		} else {
+			batchUploader.RegisterUploader("cfimages", imagesUploader)
+		}
+	}
+	if config.WebDAV != nil && config.hostEnabled("webdav") {
+		if webdavUploader, err := uploaders.NewWebDAVUploader(*config.WebDAV); err != nil {
+			log.Printf("⚠️ webdav host disabled: %v", err)
+		} else {
+			batchUploader.RegisterUploader("webdav", webdavUploader)
+		}
+	}
+	if config.Remote != nil && config.hostEnabled(config.Remote.Protocol) {
+		if remoteUploader, err := uploaders.NewRemoteUploader(*config.Remote); err != nil {
+			log.Printf("⚠️ %s host disabled: %v", config.Remote.Protocol, err)
+		} else {
+			batchUploader.RegisterUploader(config.Remote.Protocol, remoteUploader)
+		}
+	}
+	if config.Imgur != nil && config.hostEnabled("imgur") {
+		if imgurUploader, err := uploaders.NewImgurUploader(*config.Imgur); err != nil {
+			log.Printf("⚠️ imgur host disabled: %v", err)
+		} else {
+			batchUploader.RegisterUploader("imgur", imgurUploader)
+		}
+	}
+	if config.ImgBB != nil && config.hostEnabled("imgbb") {
+		if imgbbUploader, err := uploaders.NewImgBBUploader(*config.ImgBB); err != nil {
+			log.Printf("⚠️ imgbb host disabled: %v", err)
+		} else {
+			batchUploader.RegisterUploader("imgbb", imgbbUploader)
+		}
+	}
+	if config.Pixeldrain != nil && config.hostEnabled("pixeldrain") {
+		if pixeldrainUploader, err := uploaders.NewPixeldrainUploader(*config.Pixeldrain); err != nil {
+			log.Printf("⚠️ pixeldrain host disabled: %v", err)
+		} else {
+			batchUploader.RegisterUploader("pixeldrain", pixeldrainUploader)
+		}
+	}
+	if config.ImgChest != nil && config.hostEnabled("imgchest") {
+		if imgchestUploader, err := uploaders.NewImgChestUploader(*config.ImgChest); err != nil {
+			log.Printf("⚠️ imgchest host disabled: %v", err)
+		} else {
+			batchUploader.RegisterUploader("imgchest", imgchestUploader)
+		}
+	}
+	if config.Gofile != nil && config.hostEnabled("gofile") {
+		if gofileUploader, err := uploaders.NewGofileUploader(*config.Gofile); err != nil {
+			log.Printf("⚠️ gofile host disabled: %v", err)
+		} else {
+			batchUploader.RegisterUploader("gofile", gofileUploader)
+		}
+	}
+	// Telegraph pages are published whenever it is configured; the image host only when enabled
+	var telegraphUploader *uploaders.TelegraphUploader
+	if config.Telegraph != nil {
+		if telegraphUploader, err = uploaders.NewTelegraphUploader(*config.Telegraph); err != nil {
+			log.Printf("⚠️ telegraph disabled: %v", err)
+		} else if config.hostEnabled("telegraph") {
+			batchUploader.RegisterUploader("telegraph", telegraphUploader)
+		}
+	}
+	
+	// Plugins add hosts and metadata sources; built-in hosts keep their names
+	pluginManager, err := plugins.Load(paths.Plugins)
+	if err != nil {
+		log.Printf("⚠️ Plugins disabled: %v", err)
+		pluginManager, _ = plugins.Load("")
+	}
+	for _, info := range pluginManager.List() {
+		if info.Error != "" {
+			log.Printf("⚠️ Plugin %s (%s) not loaded: %s", info.Name, info.Path, info.Error)
+		} else {
+			log.Printf("🔌 Plugin %s %s loaded: %v", info.Name, info.Version, info.Provides)
+		}
+	}
+	for _, plugin := range pluginManager.WithCapability(plugins.CapabilityUploader) {
+		name := plugin.Info().Name
+		switch {
+		case batchUploader.HasUploader(name):
+			log.Printf("⚠️ Plugin host %s ignored: a built-in host already uses that name", name)
+		case config.hostEnabled(name):
+			batchUploader.RegisterUploader(name, plugins.NewUploader(plugin))
+		}
+	}
+	
+	// Release post templates; broken custom templates fall back to the built-in ones
+	var releaseConfig release.Config
+	if config.Release != nil {
+		releaseConfig = *config.Release
+	}
+	releaseBuilder, err := release.NewBuilder(releaseConfig)
+	if err != nil {
+		log.Printf("⚠️ Release post templates ignored: %v", err)
+		releaseBuilder, _ = release.NewBuilder(release.Config{ReaderURL: releaseConfig.ReaderURL})
+	}
+	
+	// RSS feeds reuse the release reader link unless the feed sets its own
+	var feedConfig feed.Config
+	if config.Feed != nil {
+		feedConfig = *config.Feed
+	}
+	if feedConfig.ReaderURL == "" {
+		feedConfig.ReaderURL = releaseConfig.ReaderURL
+	}
+	feedConfig.JSONDir = paths.JSONOutput
+	
+	var siteConfig sitegen.Config
+	if config.Site != nil {
+		siteConfig = *config.Site
+	}
+	if siteConfig.OutputDir == "" {
+		siteConfig.OutputDir = paths.Site
+	}
+	siteConfig.JSONDir = paths.JSONOutput
+	
+	// Self-update follows the stable channel of the upstream repo unless configured otherwise
+	var updateConfig selfupdate.Config
+	if config.Update != nil {
+		updateConfig = *config.Update
+	}
+	// An invalid section disables self-update: falling back to defaults would drop
+	// the configured public key and install releases without a signature check
+	updater, updaterErr := selfupdate.New(updateConfig, version)
+	if updaterErr != nil {
+		log.Printf("⚠️ Self-update disabled: %v", updaterErr)
+		updaterErr = fmt.Errorf("self-update is disabled until the update section is fixed: %v", updaterErr)
+	}
+	
+	// Signed JSONs let readers and mirrors detect files altered after distribution
+	signer, err := signing.New(config.Signing)
+	if err != nil {
+		log.Printf("⚠️ JSON signing disabled: %v", err)
+	} else if signer != nil {
+		log.Printf("🔏 Signing synced JSONs (%s, key %s); public key: %s", signer.Mode(), signer.KeyID(), signer.PublicKey())
+	}
+	
+	// Pipeline hooks; an invalid hook disables all of them rather than running a partial set
+	hookRunner, err := hooks.New(config.Hooks)
+	if err != nil {
+		log.Printf("⚠️ Pipeline hooks disabled: %v", err)
+	}
+	
+	// Per-manga webhooks; like the hooks, an invalid entry disables all of them
+	mangaWebhooks, err := webhooks.New(config.MangaWebhooks)
+	if err != nil {
+		log.Printf("⚠️ Manga webhooks disabled: %v", err)
+	} else if mangaWebhooks != nil {
+		log.Printf("🔔 Manga webhooks configured for %d work(s)", len(mangaWebhooks.Works()))
+	}
+	
+	// Content policy checked before every upload; NSFW genre defaults apply even without one
+	var policyConfig policy.Config
+	if config.Policy != nil {
+		policyConfig = *config.Policy
+	}
+	contentPolicy, err := policy.New(policyConfig)
+	if err != nil {
+		log.Printf("⚠️ Content policy ignored: %v", err)
+		contentPolicy, _ = policy.New(policy.Config{})
+	}
+	
+	// Batches and collections keep their own log so failed runs can be debugged from the UI
+	jobLogs := joblog.NewStore(1000, 200)
+	batchUploader.SetJobLog(jobLogs)
+	collectionProcessor.SetJobLog(jobLogs)
+	
+	// Lossless optimization runs on its own CPU-bound worker slots, shared by batches and collections
+	var optimizeConfig optimize.Config
+	if config.Optimize != nil {
+		optimizeConfig = *config.Optimize
+	}
+	optimizer := optimize.New(optimizeConfig)
+	batchUploader.SetOptimizer(optimizer)
+	collectionProcessor.SetOptimizer(optimizer)
+	
+	// Every byte sent to a host (retries and mirrors included) feeds the transfer metrics
+	batchUploader.SetTransferHook(monitor.RecordTransfer)
+	collectionProcessor.SetTransferHook(monitor.RecordTransfer)
+	
+	// Uploaded files are copied to the backup root only when enabled; verify_backup works either way
+	backupStore := backup.New(paths.Backup)
+	if config.Backup != nil && config.Backup.Enabled {
+		batchUploader.SetBackup(backupStore)
+		collectionProcessor.SetBackup(backupStore)
+		log.Printf("💾 Backing up uploaded files to %s", paths.Backup)
+	}
+	
+	// Checksum manifests are built from the hashes recorded in the upload history
+	if config.Checksums.Enabled() {
+		batchUploader.SetHashUploads(true)
+		collectionProcessor.SetHashUploads(true)
+	}
+	
+	// Host URL rules turn viewer links into direct image URLs before they reach the JSONs
+	urlRewriter, err := urlrules.New(config.HostURLRules)
+	if err != nil {
+		log.Printf("⚠️ Host URL rules disabled: %v", err)
+	} else if urlRewriter != nil {
+		batchUploader.SetURLRewriter(urlRewriter)
+		collectionProcessor.SetURLRewriter(urlRewriter)
+		for host := range config.HostURLRules {
+			if !batchUploader.HasUploader(host) {
+				log.Printf("⚠️ URL rules for %q: no such host is registered", host)
+			}
+		}
+	}
+	
+	// Returned URLs are checked after the rules; a rejected URL fails the file instead of
+	// reaching the chapter. An invalid configuration falls back to the default checks.
+	urlValidator, err := urlcheck.New(config.URLValidation)
+	if err != nil {
+		log.Printf("⚠️ URL validation settings ignored: %v", err)
+		urlValidator, _ = urlcheck.New(nil)
+	}
+	batchUploader.SetURLValidator(urlValidator)
+	collectionProcessor.SetURLValidator(urlValidator)
+	
+	// Chapter quality report (small, blank and odd-sized pages) in estimates and collections
+	var qualityAnalyzer *quality.Analyzer
+	if config.Quality == nil || !config.Quality.Disabled {
+		var qualityConfig quality.Config
+		if config.Quality != nil {
+			qualityConfig = *config.Quality
+		}
+		qualityAnalyzer = quality.NewAnalyzer(qualityConfig, 0)
+		collectionProcessor.SetQualityAnalyzer(qualityAnalyzer)
+	}
+	
+	uploadHistory := analytics.NewHistory(paths.UploadHistory)
 	
 	server := &HighPerformanceServer{
 		wsManager:           wsManager,
 		batchUploader:       batchUploader,
 		discoverer:          discoverer,
 		monitor:             monitor,
+		clock:               clk,
 		collectionProcessor: collectionProcessor,
 		workerPool:          workerPool,
 		jsonGenerator:       jsonGenerator,
 		anilistService:      anilistService,  // Phase 2.3: AniList integration
 		githubService:       githubService,   // GitHub integration
+		idRegistry:          idRegistry,
+		titleOverrides:      titleOverrides,
+		notifications:       notificationStore,
+		takedownsSeen:       make(map[string]int),
+		fieldLocks:          fieldLocks,
+		jsonQuarantine:      jsonQuarantine,
+		shareLinks:          shareLinks,
+		uploadHistory:       uploadHistory,
+		mirrorChecker:       mirrorChecker,
+		releaseBuilder:      releaseBuilder,
+		feedGenerator:       feed.NewGenerator(feedConfig),
+		catalogIndex:        catalog.NewGenerator(config.MetadataOutput),
+		libraryIndex:        search.NewIndex(config.MetadataOutput),
+		siteConfig:          siteConfig,
+		jobStreams:          wsmanager.NewJobStreams(jobStreamBuffer),
+		jobLogs:             jobLogs,
+		updater:             updater,
+		updaterErr:          updaterErr,
+		plugins:             pluginManager,
+		hooks:               hookRunner,
+		mangaWebhooks:       mangaWebhooks,
+		contentPolicy:       contentPolicy,
+		qualityAnalyzer:     qualityAnalyzer,
+		backupStore:         backupStore,
+		checksums:           checksums.NewWriter(config.Checksums, uploadHistory),
+		signer:              signer,
+		telegraph:           telegraphUploader,
+		restartRequested:    make(chan string, 1),
+		tuner:               tuner,
+		autoTuned:           autoTuned,
+		uploadLimit:         int32(config.MaxWorkers),
 		uploadResults:       make(map[string][]metadata.UploadedFile),
+		resultSpool:         resultSpool,
 		batchMangaTitles:    make(map[string]map[string]string),
+		deferredBatches:     make(map[string]bool),
+		uploadResultsTouched: make(map[string]time.Time),
 		config:              config,
+		paths:               paths,
 		ctx:                 ctx,
 		cancel:              cancel,
 	}
 	
+	// Pre-signed URLs are re-signed well before they lapse; writes share the metadata lock
+	signedURLInterval := time.Hour
+	if config.SignedURLRefreshInterval != "" {
+		if signedURLInterval, err = time.ParseDuration(config.SignedURLRefreshInterval); err != nil {
+			log.Printf("⚠️ Invalid signedUrlRefreshInterval %q: %v", config.SignedURLRefreshInterval, err)
+			signedURLInterval = time.Hour
+		}
+	}
+	server.urlRefresher = signedurls.NewRefresher(signedurls.Config{
+		JSONDir:  paths.JSONOutput,
+		Interval: signedURLInterval,
+		Lock:     &server.metadataMu,
+	}, jsonGenerator, urlSigners)
+	
+	// Library integrity scan; repairs share the metadata lock with save_metadata
+	server.integrityScanner = integrity.NewScanner(integrity.Config{
+		LibraryRoot: config.LibraryRoot,
+		JSONDir:     paths.JSONOutput,
+		Lock:        &server.metadataMu,
+	}, jsonGenerator, idRegistry, server.uploadHistory)
+	
+	// AniList status refresh and auto-fill rewrite the same JSONs, so they share the lock too
+	server.statusRefresher = statussync.NewRefresher(statussync.Config{
+		JSONDir:       paths.JSONOutput,
+		Interval:      statusRefreshInterval,
+		CheckChapters: true,
+		Locks:         fieldLocks,
+		Lock:          &server.metadataMu,
+	}, anilistService, idRegistry)
+	server.autoFiller = autofill.NewFiller(autofill.Config{
+		JSONDir:   config.MetadataOutput,
+		StatePath: paths.AutoFillState,
+		Locks:     fieldLocks,
+		Overrides: titleOverrides,
+		Lock:      &server.metadataMu,
+	}, anilistService, idRegistry)
+	
+	// Safe mode: server starts with uploads disabled for post-incident inspection
+	if config.SafeMode {
+		server.uploadsDisabled = 1
+		collectionProcessor.Halt()
+		log.Println("⚠️ Safe mode enabled: uploads are disabled until resume_uploads is received")
+	}
+	
+	// Read-only mirror: never uploads, and mutating actions are refused (see restrictToReadOnly)
+	if config.ReadOnly {
+		server.uploadsDisabled = 1
+		collectionProcessor.Halt()
+		log.Println("🔒 Read-only mirror mode: serving JSONs, feeds, stats and search; mutating actions are disabled")
+	}
+	
 	// Register upload result callback for JSON generation
 	batchUploader.SetResultCallback(server.handleUploadResult)
+	batchUploader.SetCompletionCallback(server.handleBatchCompleted)
+	
+	// Jobs outlive the connection that started them
+	wsManager.OnDisconnect(server.handleDisconnect)
+	
+	// Collection uploads go to the upload history as well
+	collectionProcessor.SetFileUploadedHook(server.recordCollectionUpload)
+	collectionProcessor.SetChapterHook(server.beforeCollectionChapter)
+	collectionProcessor.SetFileCheck(server.checkCollectionFile)
 	
 	// Register WebSocket handlers
 	server.registerWebSocketHandlers()
@@ -326,6 +1126,50 @@ func NewHighPerformanceServer(config *ServerConfig) *HighPerformanceServer {
 	return server
 }
 
+// readOnlyActions are the WebSocket actions a read-only mirror answers: catalog
+// and search, stats, and per-connection settings. Actions that write the data
+// directory, upload, or call out to GitHub/AniList/hosts are refused.
+var readOnlyActions = map[string]bool{
+	"load_metadata":            true,
+	"get_metadata_locks":       true,
+	"search_library":           true,
+	"list_archived_series":     true,
+	"get_series_analytics":     true,
+	"get_release_post":         true,
+	"get_manga_status_summary": true,
+	"get_mirror_health":        true,
+	"get_integrity_report":     true,
+	"get_metrics":              true,
+	"get_status":               true,
+	"get_worker_stats":         true,
+	"get_tuning":               true,
+	"get_hosts":                true,
+	"get_metadata_providers":   true,
+	"heartbeat":                true,
+	"set_locale":               true,
+	"set_encoding":             true,
+}
+
+// restrictToReadOnly replaces the handler of every action outside readOnlyActions
+// with one that refuses it
+func (s *HighPerformanceServer) restrictToReadOnly() {
+	for _, action := range s.wsManager.Actions() {
+		if !readOnlyActions[action] {
+			s.wsManager.RegisterHandler(action, s.handleReadOnlyRefusal)
+		}
+	}
+}
+
+// handleReadOnlyRefusal answers a mutating action on a read-only mirror
+func (s *HighPerformanceServer) handleReadOnlyRefusal(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	return conn.Send(wsmanager.Response{
+		Status:    "error",
+		Error:     i18n.T(connLocale(conn), i18n.MsgReadOnly, msg.Action),
+		ErrorCode: wsmanager.ErrReadOnly,
+		RequestID: msg.RequestID,
+	})
+}
+
 // registerWebSocketHandlers registers all WebSocket message handlers
 func (s *HighPerformanceServer) registerWebSocketHandlers() {
 	// Discovery handler (parallel processing)
@@ -337,6 +1181,18 @@ func (s *HighPerformanceServer) registerWebSocketHandlers() {
 	// Metadata handlers
 	s.wsManager.RegisterHandler("save_metadata", s.handleSaveMetadata)
 	s.wsManager.RegisterHandler("load_metadata", s.handleLoadMetadata)
+	s.wsManager.RegisterHandler("get_json_trash", s.handleGetJSONTrash)
+	s.wsManager.RegisterHandler("get_metadata_locks", s.handleMetadataLocks)
+	s.wsManager.RegisterHandler("lock_metadata_fields", s.handleMetadataLocks)
+	s.wsManager.RegisterHandler("unlock_metadata_fields", s.handleMetadataLocks)
+	s.wsManager.RegisterHandler("link_series", s.handleLinkSeries)
+	s.wsManager.RegisterHandler("get_corrupt_jsons", s.handleGetCorruptJSONs)
+	s.wsManager.RegisterHandler("restore_corrupt_jsons", s.handleRestoreCorruptJSONs)
+	s.wsManager.RegisterHandler("migrate_json_filenames", s.handleMigrateJSONFilenames)
+	s.wsManager.RegisterHandler("import_cubari", s.handleImportCubari)
+	s.wsManager.RegisterHandler("export_chapter", s.handleExportChapter)
+	s.wsManager.RegisterHandler("verify_backup", s.handleVerifyBackup)
+	s.wsManager.RegisterHandler("get_series_analytics", s.handleSeriesAnalytics)
 	
 	// Single upload handler (legacy compatibility)
 	s.wsManager.RegisterHandler("upload", s.handleSingleUpload)
@@ -347,12 +1203,51 @@ func (s *HighPerformanceServer) registerWebSocketHandlers() {
 	// Cancel batch handler
 	s.wsManager.RegisterHandler("cancel_batch", s.handleCancelBatch)
 	
+	// Forum-ready release post of a batch (Markdown and BBCode)
+	s.wsManager.RegisterHandler("get_release_post", s.handleGetReleasePost)
+	
+	// Two-phase publish: chapters uploaded with deferPublish wait for QC before the JSON is written
+	s.wsManager.RegisterHandler("list_unpublished_chapters", s.handleListUnpublishedChapters)
+	s.wsManager.RegisterHandler("publish_chapter", s.handlePublishChapter)
+	
+	// Review workflow: chapter status (uploaded → qc → approved → published) with team roles
+	s.wsManager.RegisterHandler("set_reviewer", s.handleSetReviewer)
+	s.wsManager.RegisterHandler("list_chapter_reviews", s.handleListChapterReviews)
+	s.wsManager.RegisterHandler("set_chapter_status", s.handleSetChapterStatus)
+	
+	// Notification center: failures, finished collections, takedowns and pending approvals
+	s.wsManager.RegisterHandler("get_notifications", s.handleGetNotifications)
+	s.wsManager.RegisterHandler("mark_read", s.handleMarkRead)
+	
+	// Emergency stop handlers (global kill switch)
+	s.wsManager.RegisterHandler("emergency_stop", s.handleEmergencyStop)
+	s.wsManager.RegisterHandler("resume_uploads", s.handleResumeUploads)
+	
 	// Collection processing handlers (massive scale)
 	s.wsManager.RegisterHandler("process_collection", s.handleProcessCollection)
+	s.wsManager.RegisterHandler("estimate_collection", s.handleEstimateCollection)
 	s.wsManager.RegisterHandler("get_collection_status", s.handleGetCollectionStatus)
 	s.wsManager.RegisterHandler("cancel_collection", s.handleCancelCollection)
 	s.wsManager.RegisterHandler("pause_collection", s.handlePauseCollection)
 	s.wsManager.RegisterHandler("resume_collection", s.handleResumeCollection)
+	s.wsManager.RegisterHandler("get_collection_queue", s.handleGetCollectionQueue)
+	s.wsManager.RegisterHandler("reorder_collection_queue", s.handleReorderCollectionQueue)
+	s.wsManager.RegisterHandler("create_share_link", s.handleCreateShareLink)
+	s.wsManager.RegisterHandler("revoke_share_links", s.handleRevokeShareLinks)
+	s.wsManager.RegisterHandler("get_internal_state", s.handleGetInternalState)
+	s.wsManager.RegisterHandler("purge_internal_state", s.handlePurgeInternalState)
+	s.wsManager.RegisterHandler("cleanup_state", s.handleCleanupState)
+	
+	// Job ownership and dashboard: heartbeat, list every batch/collection, claim progress streams, logs
+	s.wsManager.RegisterHandler("heartbeat", s.handleHeartbeat)
+	s.wsManager.RegisterHandler("list_jobs", s.handleListJobs)
+	s.wsManager.RegisterHandler("claim_job", s.handleClaimJob)
+	s.wsManager.RegisterHandler("get_job_logs", s.handleGetJobLogs)
+	
+	// Localization handler
+	s.wsManager.RegisterHandler("set_locale", s.handleSetLocale)
+	s.wsManager.RegisterHandler("set_encoding", s.handleSetEncoding)
+	s.wsManager.RegisterHandler("get_tuning", s.handleGetTuning)
 	
 	// Metrics handler
 	s.wsManager.RegisterHandler("get_metrics", s.handleGetMetrics)
@@ -366,15 +1261,75 @@ func (s *HighPerformanceServer) registerWebSocketHandlers() {
 	// AniList integration handlers (Phase 2.3)
 	s.wsManager.RegisterHandler("search_anilist", s.handleSearchAniList)
 	s.wsManager.RegisterHandler("select_anilist_result", s.handleSelectAniListResult)
+	s.wsManager.RegisterHandler("list_title_overrides", s.handleListTitleOverrides)
+	s.wsManager.RegisterHandler("set_title_override", s.handleSetTitleOverride)
+	s.wsManager.RegisterHandler("remove_title_override", s.handleRemoveTitleOverride)
 	
 	// AniList configuration handlers (Phase 4.3)
 	s.wsManager.RegisterHandler("get_anilist_config", s.handleGetAniListConfig)
 	s.wsManager.RegisterHandler("update_anilist_config", s.handleUpdateAniListConfig)
 	s.wsManager.RegisterHandler("reset_anilist_config", s.handleResetAniListConfig)
+	s.wsManager.RegisterHandler("save_anilist_profile", s.handleSaveAniListProfile)
+	s.wsManager.RegisterHandler("apply_anilist_profile", s.handleApplyAniListProfile)
+	s.wsManager.RegisterHandler("list_anilist_profiles", s.handleListAniListProfiles)
+	s.wsManager.RegisterHandler("delete_anilist_profile", s.handleDeleteAniListProfile)
+	s.wsManager.RegisterHandler("import_offline_metadata", s.handleImportOfflineMetadata)
+	
+	// Library metadata auto-fill job
+	s.wsManager.RegisterHandler("auto_fill_library_metadata", s.handleAutoFillLibraryMetadata)
+	s.wsManager.RegisterHandler("get_auto_fill_status", s.handleGetAutoFillStatus)
+	s.wsManager.RegisterHandler("cancel_auto_fill", s.handleCancelAutoFill)
+	
+	// Manga status refresh from the provider
+	s.wsManager.RegisterHandler("refresh_manga_status", s.handleRefreshMangaStatus)
+	s.wsManager.RegisterHandler("get_manga_status_summary", s.handleGetMangaStatusSummary)
+	
+	// Mirror health scoring and JSON failover
+	s.wsManager.RegisterHandler("check_mirror_health", s.handleCheckMirrorHealth)
+	s.wsManager.RegisterHandler("get_mirror_health", s.handleGetMirrorHealth)
+	s.wsManager.RegisterHandler("apply_mirror_failover", s.handleApplyMirrorFailover)
+	
+	// Pre-signed URL renewal for private buckets
+	s.wsManager.RegisterHandler("refresh_signed_urls", s.handleRefreshSignedURLs)
+	s.wsManager.RegisterHandler("get_signed_url_status", s.handleGetSignedURLStatus)
 	
 	// GitHub integration handlers
 	s.wsManager.RegisterHandler("github_folders", s.handleGitHubFolders)
 	s.wsManager.RegisterHandler("github_upload", s.handleGitHubUpload)
+	s.wsManager.RegisterHandler("github_bootstrap", s.handleGitHubBootstrap)
+	
+	// Static reader site, optionally published to GitHub Pages
+	s.wsManager.RegisterHandler("generate_static_site", s.handleGenerateStaticSite)
+	
+	// Self-update from GitHub releases
+	s.wsManager.RegisterHandler("check_update", s.handleCheckUpdate)
+	s.wsManager.RegisterHandler("apply_update", s.handleApplyUpdate)
+	
+	// Upload hosts and metadata sources, including plugins
+	s.wsManager.RegisterHandler("get_hosts", s.handleGetHosts)
+	s.wsManager.RegisterHandler("get_metadata_providers", s.handleGetMetadataProviders)
+	s.wsManager.RegisterHandler("search_metadata", s.handleSearchMetadata)
+	s.wsManager.RegisterHandler("get_metadata_details", s.handleGetMetadataDetails)
+	
+	// Content policy dry run over a folder
+	s.wsManager.RegisterHandler("check_upload_policy", s.handleCheckUploadPolicy)
+	
+	// Full-text search over the local JSON library
+	s.wsManager.RegisterHandler("search_library", s.handleSearchLibrary)
+	
+	// Series archive (dropped or licensed series)
+	s.wsManager.RegisterHandler("archive_series", s.handleArchiveSeries)
+	s.wsManager.RegisterHandler("unarchive_series", s.handleUnarchiveSeries)
+	s.wsManager.RegisterHandler("list_archived_series", s.handleListArchivedSeries)
+	
+	// Library integrity: folders, ID registry, JSONs and upload history
+	s.wsManager.RegisterHandler("scan_integrity", s.handleScanIntegrity)
+	s.wsManager.RegisterHandler("get_integrity_report", s.handleGetIntegrityReport)
+	s.wsManager.RegisterHandler("repair_integrity", s.handleRepairIntegrity)
+	
+	if s.config.ReadOnly {
+		s.restrictToReadOnly()
+	}
 }
 
 // handleDiscovery processes discovery requests with parallel scanning
@@ -382,7 +1337,7 @@ func (s *HighPerformanceServer) handleDiscovery(conn *wsmanager.Connection, msg
 	var req WebSocketRequest
 	reqData, _ := json.Marshal(msg.Data)
 	if err := json.Unmarshal(reqData, &req); err != nil {
-		return fmt.Errorf("invalid discovery request: %v", err)
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid discovery request: %v", err)
 	}
 	
 	go func() {
@@ -397,11 +1352,23 @@ func (s *HighPerformanceServer) handleDiscovery(conn *wsmanager.Connection, msg
 			log.Printf("Starting parallel discovery on relative path: %s", targetPath)
 		}
 		
+		// Hooks may prepare the folder (e.g. unpack archives) or veto the discovery
+		if err := s.runHook(hooks.Event{Stage: hooks.BeforeDiscovery, Path: targetPath}); err != nil {
+			safeSend(conn, wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgHookRejected, err),
+				ErrorCode: wsmanager.ErrHookFailed,
+				RequestID: req.RequestID,
+			})
+			return
+		}
+		
 		// Verify path exists
 		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
 			response := wsmanager.Response{
 				Status:    "error",
-				Error:     fmt.Sprintf("Path does not exist: %s", targetPath),
+				Error:     i18n.T(connLocale(conn), i18n.MsgPathNotFound, targetPath),
+				ErrorCode: wsmanager.ErrPathNotFound,
 				RequestID: req.RequestID,
 			}
 			safeSend(conn, response)
@@ -435,7 +1402,8 @@ func (s *HighPerformanceServer) handleDiscovery(conn *wsmanager.Connection, msg
 			s.monitor.RecordDiscovery(duration, 0)
 			response := wsmanager.Response{
 				Status:    "error",
-				Error:     fmt.Sprintf("Failed to discover structure: %v", err),
+				Error:     i18n.T(connLocale(conn), i18n.MsgDiscoveryFailed, err),
+				ErrorCode: wsmanager.ErrDiscoveryFailed,
 				RequestID: req.RequestID,
 			}
 			safeSend(conn, response)
@@ -445,6 +1413,11 @@ func (s *HighPerformanceServer) handleDiscovery(conn *wsmanager.Connection, msg
 		// Record metrics
 		s.monitor.RecordDiscovery(duration, int64(result.Metadata.Stats.TotalImages))
 		
+		// Archived series are hidden unless explicitly requested
+		if !req.IncludeArchived {
+			s.hideArchivedSeries(result.Tree)
+		}
+		
 		// Convert to legacy format for compatibility
 		legacyMetadata := &HierarchyMetadata{
 			RootLevel:   result.Metadata.RootLevel,
@@ -456,6 +1429,7 @@ func (s *HighPerformanceServer) handleDiscovery(conn *wsmanager.Connection, msg
 				TotalImages:      result.Metadata.Stats.TotalImages,
 				TotalChapters:    result.Metadata.Stats.TotalChapters,
 			},
+			Symlinks: result.Metadata.Symlinks,
 		}
 		
 		response := wsmanager.Response{
@@ -469,6 +1443,7 @@ func (s *HighPerformanceServer) handleDiscovery(conn *wsmanager.Connection, msg
 			duration, result.Metadata.RootLevel, result.Metadata.TotalLevels, result.Metadata.Stats.TotalImages)
 		
 		safeSend(conn, response)
+		go s.runAfterHook(hooks.Event{Stage: hooks.AfterDiscovery, Path: targetPath, Data: legacyMetadata})
 	}()
 	
 	return nil
@@ -481,7 +1456,7 @@ func (s *HighPerformanceServer) handleLibraryDiscovery(conn *wsmanager.Connectio
 	log.Printf("DEBUG: msg.Data = %+v", msg.Data)
 	log.Printf("DEBUG: reqData = %s", string(reqData))
 	if err := json.Unmarshal(reqData, &req); err != nil {
-		return fmt.Errorf("invalid library discovery request: %v", err)
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid library discovery request: %v", err)
 	}
 	log.Printf("DEBUG: parsed req = %+v", req)
 	
@@ -499,11 +1474,23 @@ func (s *HighPerformanceServer) handleLibraryDiscovery(conn *wsmanager.Connectio
 		
 		log.Printf("DEBUG: req.FullPath='%s', req.BasePath='%s', targetPath='%s'", req.FullPath, req.BasePath, targetPath)
 		
+		// Hooks may prepare the folder (e.g. unpack archives) or veto the discovery
+		if err := s.runHook(hooks.Event{Stage: hooks.BeforeDiscovery, Path: targetPath}); err != nil {
+			conn.Send(wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgHookRejected, err),
+				ErrorCode: wsmanager.ErrHookFailed,
+				RequestID: req.RequestID,
+			})
+			return
+		}
+		
 		// Verify path exists
 		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
 			response := wsmanager.Response{
 				Status:    "error",
-				Error:     fmt.Sprintf("Path does not exist: %s", targetPath),
+				Error:     i18n.T(connLocale(conn), i18n.MsgPathNotFound, targetPath),
+				ErrorCode: wsmanager.ErrPathNotFound,
 				RequestID: req.RequestID,
 			}
 			conn.Send(response)
@@ -537,7 +1524,8 @@ func (s *HighPerformanceServer) handleLibraryDiscovery(conn *wsmanager.Connectio
 			s.monitor.RecordDiscovery(duration, 0)
 			response := wsmanager.Response{
 				Status:    "error",
-				Error:     fmt.Sprintf("Failed to discover library: %v", err),
+				Error:     i18n.T(connLocale(conn), i18n.MsgLibraryDiscoveryFailed, err),
+				ErrorCode: wsmanager.ErrDiscoveryFailed,
 				RequestID: req.RequestID,
 			}
 			conn.Send(response)
@@ -547,6 +1535,11 @@ func (s *HighPerformanceServer) handleLibraryDiscovery(conn *wsmanager.Connectio
 		// Record metrics
 		s.monitor.RecordDiscovery(duration, int64(result.Metadata.Stats.TotalImages))
 		
+		// Archived series are hidden unless explicitly requested
+		if !req.IncludeArchived {
+			s.hideArchivedSeries(result.Tree)
+		}
+		
 		// Convert to legacy format for compatibility
 		legacyMetadata := &HierarchyMetadata{
 			RootLevel:   result.Metadata.RootLevel,
@@ -558,6 +1551,7 @@ func (s *HighPerformanceServer) handleLibraryDiscovery(conn *wsmanager.Connectio
 				TotalImages:      result.Metadata.Stats.TotalImages,
 				TotalChapters:    result.Metadata.Stats.TotalChapters,
 			},
+			Symlinks: result.Metadata.Symlinks,
 		}
 		
 		response := wsmanager.Response{
@@ -571,6 +1565,7 @@ func (s *HighPerformanceServer) handleLibraryDiscovery(conn *wsmanager.Connectio
 			duration, result.Metadata.RootLevel, result.Metadata.Stats.TotalDirectories)
 		
 		conn.Send(response)
+		go s.runAfterHook(hooks.Event{Stage: hooks.AfterDiscovery, Path: targetPath, Data: legacyMetadata})
 	}()
 	
 	return nil
@@ -589,7 +1584,7 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 	}
 	
 	if !ok || payloadData == nil {
-		return fmt.Errorf("invalid payload format - not a map")
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid payload format - not a map")
 	}
 	
 	go func() {
@@ -597,7 +1592,8 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 		if payloadData == nil {
 			response := wsmanager.Response{
 				Status:    "error",
-				Error:     "Invalid payload format",
+				Error:     i18n.T(connLocale(conn), i18n.MsgInvalidPayload),
+				ErrorCode: wsmanager.ErrInvalidRequest,
 				RequestID: msg.RequestID,
 			}
 			conn.Send(response)
@@ -608,7 +1604,8 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 		if !ok {
 			response := wsmanager.Response{
 				Status:    "error",
-				Error:     "Missing metadata in payload", 
+				Error:     i18n.T(connLocale(conn), i18n.MsgMissingMetadata), 
+				ErrorCode: wsmanager.ErrMissingField,
 				RequestID: msg.RequestID,
 			}
 			conn.Send(response)
@@ -619,33 +1616,28 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 		mangaID, mangaIDOk := payloadData["mangaID"].(string)
 		mangaPath, pathOk := payloadData["mangaPath"].(string)
 		
-		var sanitizedFolderName string
+		var seriesID string
 		
 		if mangaIDOk && mangaID != "" {
 			// Use mangaID for consistent filename generation (preferred method)
-			folderName := mangaID
-			if strings.HasPrefix(mangaID, "auto-") {
-				folderName = strings.TrimPrefix(mangaID, "auto-")
-			}
-			sanitizedFolderName = sanitizeFilename(folderName)
-			log.Printf("🔍 SAVE DEBUG: Usando mangaID: %s → %s", mangaID, sanitizedFolderName)
+			seriesID = mangaID
 		} else if pathOk && mangaPath != "" {
 			// Fallback to mangaPath extraction (legacy method)
-			folderName := filepath.Base(mangaPath)
-			sanitizedFolderName = sanitizeFilename(folderName)
-			log.Printf("🔍 SAVE DEBUG: Fallback mangaPath: %s → %s", mangaPath, sanitizedFolderName)
+			seriesID = filepath.Base(mangaPath)
 		} else {
 			response := wsmanager.Response{
 				Status:    "error", 
-				Error:     "Missing mangaID or mangaPath in payload",
+				Error:     i18n.T(connLocale(conn), i18n.MsgMissingMangaPath),
+				ErrorCode: wsmanager.ErrMissingField,
 				RequestID: msg.RequestID,
 			}
 			conn.Send(response)
 			return
 		}
 		
+		sanitizedFolderName := mangaid.Normalize(seriesID)
 		log.Printf("🔍 SAVE DEBUG: mangaPath: %s", mangaPath) 
-		log.Printf("🔍 SAVE DEBUG: sanitized: %s", sanitizedFolderName)
+		log.Printf("🔍 SAVE DEBUG: sanitized: %s → %s", seriesID, sanitizedFolderName)
 		
 		// Use JSON output directory from payload first, then settings, then default
 		jsonOutputDir := ""
@@ -660,9 +1652,9 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 			log.Printf("🔍 SAVE DEBUG: Usando diretório padrão/config: %s", jsonOutputDir)
 		}
 		
-		// Create the JSON file path with consistent filename based on folder name
-		jsonFileName := fmt.Sprintf("%s.json", sanitizedFolderName)
-		metadataPath := filepath.Join(jsonOutputDir, jsonFileName)
+		// Same filename rules as the JSON generator; a JSON named by the old rules is updated in place
+		metadataPath := mangaid.Path(jsonOutputDir, seriesID)
+		jsonFileName := filepath.Base(metadataPath)
 		
 		log.Printf("🔍 SAVE DEBUG: Salvando em: %s", metadataPath)
 		log.Printf("🔍 SAVE DEBUG: Arquivo JSON: %s", jsonFileName)
@@ -671,7 +1663,24 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 		if err := os.MkdirAll(jsonOutputDir, 0755); err != nil {
 			response := wsmanager.Response{
 				Status:    "error",
-				Error:     fmt.Sprintf("Failed to create JSON directory: %v", err),
+				Error:     i18n.T(connLocale(conn), i18n.MsgJSONDirFailed, err),
+				ErrorCode: wsmanager.ErrIO,
+				RequestID: msg.RequestID,
+			}
+			conn.Send(response)
+			return
+		}
+		
+		// Saves are serialized so the revision check and the write are atomic
+		s.metadataMu.Lock()
+		defer s.metadataMu.Unlock()
+		
+		currentRevision, err := metadataRevision(metadataPath)
+		if err != nil {
+			response := wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgJSONParseFailed, err),
+				ErrorCode: wsmanager.ErrIO,
 				RequestID: msg.RequestID,
 			}
 			conn.Send(response)
@@ -708,6 +1717,28 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 			}
 		}
 		
+		// Optimistic concurrency: the client sends the revision it loaded; if the file
+		// changed since then, return the current version so the frontend can merge
+		if expectedRevision, _ := payloadData["revision"].(string); expectedRevision != "" && expectedRevision != currentRevision {
+			log.Printf("⚠️ Conflito de edição em %s: revisão %s, atual %s", metadataPath, expectedRevision, currentRevision)
+			response := wsmanager.Response{
+				Status:    "conflict",
+				Error:     i18n.T(connLocale(conn), i18n.MsgMetadataConflict, jsonFileName),
+				ErrorCode: wsmanager.ErrMetadataConflict,
+				Payload: map[string]interface{}{
+					"filePath":         metadataPath,
+					"mangaID":          mangaID,
+					"revision":         currentRevision,
+					"expectedRevision": expectedRevision,
+					"current":          existingData,
+					"metadata":         metadata,
+				},
+				RequestID: msg.RequestID,
+			}
+			conn.Send(response)
+			return
+		}
+		
 		// Smart merge: Update only valid fields that are present in the new metadata
 		validFields := map[string]string{
 			"nome":      "title",
@@ -723,6 +1754,7 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 			"grupo":     "group",
 			"group":     "group",
 			"status":    "status",
+			"nsfw":      "nsfw",
 		}
 		
 		fieldsUpdated := []string{}
@@ -751,7 +1783,10 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 			log.Printf("✅ Nenhum campo alterado, mantendo arquivo original inalterado")
 			response := wsmanager.Response{
 				Status:    "metadata_saved",
-				Payload:   map[string]interface{}{"metadata": existingData},
+				Payload:   map[string]interface{}{
+					"metadata": existingData,
+					"revision": currentRevision,
+				},
 				RequestID: msg.RequestID,
 			}
 			conn.Send(response)
@@ -760,7 +1795,6 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 		
 		// Convert back to JSON preserving field order
 		var jsonData []byte
-		var err error
 		
 		// Try to preserve original formatting and field order if file exists
 		if existingBytes, readErr := os.ReadFile(metadataPath); readErr == nil {
@@ -801,7 +1835,8 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 				if err != nil {
 					response := wsmanager.Response{
 						Status:    "error",
-						Error:     fmt.Sprintf("Failed to marshal updated JSON: %v", err),
+						Error:     i18n.T(connLocale(conn), i18n.MsgJSONMarshalFailed, err),
+						ErrorCode: wsmanager.ErrJSONInvalid,
 						RequestID: msg.RequestID,
 					}
 					conn.Send(response)
@@ -815,7 +1850,8 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 			if err != nil {
 				response := wsmanager.Response{
 					Status:    "error",
-					Error:     fmt.Sprintf("Failed to generate ordered JSON: %v", err),
+					Error:     i18n.T(connLocale(conn), i18n.MsgJSONGenerateFailed, err),
+					ErrorCode: wsmanager.ErrJSONInvalid,
 					RequestID: msg.RequestID,
 				}
 				conn.Send(response)
@@ -828,7 +1864,8 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 		if err := os.WriteFile(metadataPath, jsonData, 0644); err != nil {
 			response := wsmanager.Response{
 				Status:    "error",
-				Error:     fmt.Sprintf("Failed to write metadata file: %v", err),
+				Error:     i18n.T(connLocale(conn), i18n.MsgMetadataWriteFailed, err),
+				ErrorCode: wsmanager.ErrIO,
 				RequestID: msg.RequestID,
 			}
 			conn.Send(response)
@@ -837,12 +1874,21 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 		
 		log.Printf("Successfully saved metadata to: %s", metadataPath)
 		
-		// Send success response
+		// Keep the ID registry in sync with the library JSON
+		title, _ := existingData["title"].(string)
+		anilistID, _ := payloadData["anilistId"].(float64)
+		if err := s.idRegistry.Link(sanitizedFolderName, title, int(anilistID)); err != nil {
+			log.Printf("⚠️ Failed to update ID registry for %s: %v", sanitizedFolderName, err)
+		}
+		
+		// Send success response (with the new revision for the next save)
+		newRevision, _ := metadataRevision(metadataPath)
 		response := wsmanager.Response{
 			Status:    "metadata_saved",
 			Payload:   map[string]interface{}{
 				"filePath": metadataPath,
 				"metadata": metadata,
+				"revision": newRevision,
 			},
 			RequestID: msg.RequestID,
 		}
@@ -852,25 +1898,251 @@ func (s *HighPerformanceServer) handleSaveMetadata(conn *wsmanager.Connection, m
 	return nil
 }
 
-// handleLoadMetadata loads metadata from an existing JSON file
-func (s *HighPerformanceServer) handleLoadMetadata(conn *wsmanager.Connection, msg wsmanager.Message) error {
-	log.Printf("🌐 WEBSOCKET: Recebida mensagem load_metadata")
-	log.Printf("🌐 WEBSOCKET: Message data: %+v", msg.Data)
-	log.Printf("🌐 WEBSOCKET: Message payload: %+v", msg.Payload)
-	
-	// Extract payload data
-	var payloadData map[string]interface{}
-	var ok bool
+// metadataRevision returns the current revision of a manga JSON ("" if it does not exist)
+func metadataRevision(path string) (string, error) {
+	return metadata.FileRevision(path)
+}
+
+// handleMetadataLocks lists, locks or unlocks metadata fields of a manga (depending on the action).
+// Locked fields are skipped by UpdateExistingJSON merges and the AniList auto-fill.
+func (s *HighPerformanceServer) handleMetadataLocks(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid metadata locks request: %v", err)
+	}
 	
-	if msg.Data != nil {
-		payloadData, ok = msg.Data.(map[string]interface{})
-	} else if msg.Payload != nil {
-		payloadData, ok = msg.Payload.(map[string]interface{})
+	if req.Manga == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgMissingMangaName),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	// Locks are keyed like the JSON file name (mangaID without the "auto-" prefix)
+	mangaID := mangaid.Normalize(req.Manga)
+	
+	lockedFields := s.fieldLocks.Locked(mangaID)
+	if msg.Action != "get_metadata_locks" {
+		var err error
+		// Taken so a save_metadata or auto-fill in progress finishes with the old locks
+		s.metadataMu.Lock()
+		lockedFields, err = s.fieldLocks.Set(mangaID, req.Fields, msg.Action == "lock_metadata_fields")
+		s.metadataMu.Unlock()
+		if err != nil {
+			return conn.Send(wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgFieldLocksFailed, err),
+				ErrorCode: wsmanager.ErrInvalidRequest,
+				RequestID: req.RequestID,
+			})
+		}
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "metadata_locks",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"manga":          req.Manga,
+			"mangaId":        mangaID,
+			"lockedFields":   lockedFields,
+			"lockableFields": metadata.LockableFields,
+		},
+	})
+}
+
+// handleLinkSeries links (or unlinks) a related series (season, sequel, spin-off) in the
+// "related" block of a manga JSON. With reciprocal, the inverse link is written to the
+// other manga's JSON when it exists locally.
+func (s *HighPerformanceServer) handleLinkSeries(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid link series request: %v", err)
+	}
+	
+	if req.Manga == "" || req.Link == nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgMissingMangaName),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	mangaID := mangaid.Normalize(req.Manga)
+	jsonPath := mangaid.Path(s.config.MetadataOutput, mangaID)
+	
+	link := *req.Link
+	if link.ID != "" {
+		link.ID = mangaid.Normalize(link.ID)
+	}
+	
+	// Same lock as save_metadata so links do not race with metadata edits
+	s.metadataMu.Lock()
+	defer s.metadataMu.Unlock()
+	
+	related, err := metadata.LinkSeries(jsonPath, link, req.Unlink)
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgLinkSeriesFailed, err),
+			ErrorCode: wsmanager.ErrInvalidRequest,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	reciprocalLinked := false
+	if req.Reciprocal && link.ID != "" && link.ID != mangaID {
+		targetPath := mangaid.Path(s.config.MetadataOutput, link.ID)
+		if _, statErr := os.Stat(targetPath); statErr == nil {
+			inverse := metadata.RelatedSeries{
+				Relation: metadata.InverseRelation(link.Relation),
+				ID:       mangaID,
+			}
+			var source metadata.MangaJSON
+			if raw, readErr := os.ReadFile(jsonPath); readErr == nil && json.Unmarshal(raw, &source) == nil {
+				inverse.Title = source.Title
+			}
+			if inverse.Relation == "" {
+				inverse.Relation = link.Relation
+			}
+			if _, err := metadata.LinkSeries(targetPath, inverse, req.Unlink); err != nil {
+				log.Printf("⚠️ Failed to write reciprocal link in %s: %v", targetPath, err)
+			} else {
+				reciprocalLinked = true
+			}
+		}
+	}
+	
+	// Every client showing this manga needs the new reading order
+	s.wsManager.Broadcast(wsmanager.Response{
+		Status:    "series_linked",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"manga":      req.Manga,
+			"mangaId":    mangaID,
+			"related":    related,
+			"reciprocal": reciprocalLinked,
+		},
+	})
+	
+	return nil
+}
+
+// handleSeriesAnalytics aggregates release cadence, pages per chapter and bytes per host
+// per series and per scan group. Optional manga/group narrow the report down.
+func (s *HighPerformanceServer) handleSeriesAnalytics(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid series analytics request: %v", err)
+	}
+	
+	records, err := s.uploadHistory.Load()
+	if err != nil {
+		log.Printf("⚠️ Upload history partially read: %v", err)
+	}
+	
+	// Archived series stay out of the stats unless explicitly requested
+	var extraDirs []string
+	if req.IncludeArchived {
+		extraDirs = append(extraDirs, filepath.Join(s.config.MetadataOutput, metadata.ArchiveDirName))
+	} else if archived := s.idRegistry.Archived(); len(archived) > 0 {
+		active := records[:0]
+		for _, record := range records {
+			if !archived[record.MangaID] {
+				active = append(active, record)
+			}
+		}
+		records = active
+	}
+	
+	report, err := analytics.Compute(s.config.MetadataOutput, records, extraDirs...)
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgAnalyticsFailed, err),
+			ErrorCode: wsmanager.ErrIO,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	mangaID := ""
+	if req.Manga != "" {
+		mangaID = mangaid.Normalize(req.Manga)
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "series_analytics",
+		RequestID: req.RequestID,
+		Data:      report.Filter(mangaID, req.Group),
+	})
+}
+
+// handleGetJSONTrash returns the chapters removed from a manga JSON by replace-mode updates
+func (s *HighPerformanceServer) handleGetJSONTrash(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid get json trash request: %v", err)
+	}
+	
+	if req.Manga == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgMissingMangaName),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	// Same filename rules as the JSON generator (mangaID with optional "auto-" prefix)
+	jsonPath := mangaid.Path(s.config.MetadataOutput, req.Manga)
+	
+	trash, err := metadata.LoadJSONTrash(jsonPath)
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgJSONParseFailed, err),
+			ErrorCode: wsmanager.ErrJSONInvalid,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "json_trash",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"manga":     req.Manga,
+			"jsonFile":  jsonPath,
+			"trashFile": metadata.TrashPath(jsonPath),
+			"entries":   trash.Entries,
+		},
+	})
+}
+
+// handleLoadMetadata loads metadata from an existing JSON file
+func (s *HighPerformanceServer) handleLoadMetadata(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	log.Printf("🌐 WEBSOCKET: Recebida mensagem load_metadata")
+	log.Printf("🌐 WEBSOCKET: Message data: %+v", msg.Data)
+	log.Printf("🌐 WEBSOCKET: Message payload: %+v", msg.Payload)
+	
+	// Extract payload data
+	var payloadData map[string]interface{}
+	var ok bool
+	
+	if msg.Data != nil {
+		payloadData, ok = msg.Data.(map[string]interface{})
+	} else if msg.Payload != nil {
+		payloadData, ok = msg.Payload.(map[string]interface{})
 	}
 	
 	if !ok || payloadData == nil {
 		log.Printf("❌ WEBSOCKET: Payload inválido - não é um map")
-		return fmt.Errorf("invalid payload format - not a map")
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid payload format - not a map")
 	}
 	
 	go func() {
@@ -880,32 +2152,28 @@ func (s *HighPerformanceServer) handleLoadMetadata(conn *wsmanager.Connection, m
 		mangaID, mangaIDOk := payloadData["mangaID"].(string)
 		mangaName, nameOk := payloadData["mangaName"].(string)
 		
-		var sanitizedFolderName string
+		var seriesID string
 		
 		if mangaIDOk && mangaID != "" {
 			// Use mangaID for consistent filename generation (preferred method)
-			folderName := mangaID
-			if strings.HasPrefix(mangaID, "auto-") {
-				folderName = strings.TrimPrefix(mangaID, "auto-")
-			}
-			sanitizedFolderName = sanitizeFilename(folderName)
-			log.Printf("🔍 LOAD DEBUG: Usando mangaID: %s → %s", mangaID, sanitizedFolderName)
+			seriesID = mangaID
 		} else if nameOk && mangaName != "" {
 			// Fallback to mangaName sanitization (legacy method)
-			sanitizedFolderName = sanitizeFilename(mangaName)
-			log.Printf("🔍 LOAD DEBUG: Fallback mangaName: %s → %s", mangaName, sanitizedFolderName)
+			seriesID = mangaName
 		} else {
 			log.Printf("❌ MangaID/MangaName inválido: ID=%v, Name=%v", payloadData["mangaID"], payloadData["mangaName"])
 			response := wsmanager.Response{
 				Status:    "error",
-				Error:     "Missing mangaID or mangaName in payload",
+				Error:     i18n.T(connLocale(conn), i18n.MsgMissingMangaName),
+				ErrorCode: wsmanager.ErrMissingField,
 				RequestID: msg.RequestID,
 			}
 			conn.Send(response)
 			return
 		}
 		
-		log.Printf("📄 Procurando JSON para filename: %s", sanitizedFolderName)
+		sanitizedFolderName := mangaid.Normalize(seriesID)
+		log.Printf("📄 Procurando JSON para filename: %s → %s", seriesID, sanitizedFolderName)
 		
 		// Get JSON output directory from payload or config
 		jsonOutputDir := ""
@@ -922,12 +2190,12 @@ func (s *HighPerformanceServer) handleLoadMetadata(conn *wsmanager.Connection, m
 		
 		log.Printf("📁 Diretório de busca: %s", jsonOutputDir)
 		
-		// Use the same filename generation logic as save_metadata for consistency
-		jsonFileName := fmt.Sprintf("%s.json", sanitizedFolderName)
-		log.Printf("📝 Procurando arquivo: %s", jsonFileName)
-		
-		// Try to load the JSON file using consistent filename
-		jsonPath := filepath.Join(jsonOutputDir, jsonFileName)
+		// Use the same filename resolution as save_metadata for consistency
+		jsonPath, legacyName := mangaid.Resolve(jsonOutputDir, seriesID)
+		jsonFileName := filepath.Base(jsonPath)
+		if legacyName {
+			log.Printf("📝 Usando JSON com nome antigo: %s", jsonFileName)
+		}
 		log.Printf("🔍 Carregando arquivo: %s", jsonPath)
 		
 		jsonData, err := os.ReadFile(jsonPath)
@@ -935,7 +2203,8 @@ func (s *HighPerformanceServer) handleLoadMetadata(conn *wsmanager.Connection, m
 			log.Printf("❌ Arquivo JSON não encontrado: %s (erro: %v)", jsonPath, err)
 			response := wsmanager.Response{
 				Status:    "error",
-				Error:     fmt.Sprintf("JSON file not found for filename: %s", jsonFileName),
+				Error:     i18n.T(connLocale(conn), i18n.MsgJSONNotFound, jsonFileName),
+				ErrorCode: wsmanager.ErrJSONNotFound,
 				RequestID: msg.RequestID,
 			}
 			safeSend(conn, response)
@@ -944,12 +2213,16 @@ func (s *HighPerformanceServer) handleLoadMetadata(conn *wsmanager.Connection, m
 		
 		log.Printf("✅ Arquivo JSON carregado: %s", jsonPath)
 		
+		// Revision the client must send back with save_metadata
+		revision := metadata.ContentRevision(jsonData)
+		
 		// Parse JSON data
 		var metadata map[string]interface{}
 		if err := json.Unmarshal(jsonData, &metadata); err != nil {
 			response := wsmanager.Response{
 				Status:    "error",
-				Error:     fmt.Sprintf("Failed to parse JSON file: %v", err),
+				Error:     i18n.T(connLocale(conn), i18n.MsgJSONParseFailed, err),
+				ErrorCode: wsmanager.ErrJSONInvalid,
 				RequestID: msg.RequestID,
 			}
 			safeSend(conn, response)
@@ -963,10 +2236,12 @@ func (s *HighPerformanceServer) handleLoadMetadata(conn *wsmanager.Connection, m
 		response := wsmanager.Response{
 			Status:    "metadata_loaded",
 			Payload:   map[string]interface{}{
-				"filePath": jsonPath,
-				"metadata": metadata,
-				"mangaID":  mangaID, // Echo back the mangaID for filtering
-				"mangaName": mangaName, // Echo back the mangaName as fallback
+				"filePath":     jsonPath,
+				"metadata":     metadata,
+				"revision":     revision,
+				"lockedFields": s.fieldLocks.Locked(sanitizedFolderName),
+				"mangaID":      mangaID, // Echo back the mangaID for filtering
+				"mangaName":    mangaName, // Echo back the mangaName as fallback
 			},
 			RequestID: msg.RequestID,
 		}
@@ -978,10 +2253,14 @@ func (s *HighPerformanceServer) handleLoadMetadata(conn *wsmanager.Connection, m
 
 // handleSingleUpload processes single upload requests (legacy compatibility)
 func (s *HighPerformanceServer) handleSingleUpload(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	if s.uploadsHalted() {
+		return s.sendUploadsDisabled(conn, msg.RequestID)
+	}
+	
 	var req WebSocketRequest
 	reqData, _ := json.Marshal(msg.Data)
 	if err := json.Unmarshal(reqData, &req); err != nil {
-		return fmt.Errorf("invalid upload request: %v", err)
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid upload request: %v", err)
 	}
 	
 	// Convert to batch upload with single item
@@ -993,6 +2272,9 @@ func (s *HighPerformanceServer) handleSingleUpload(conn *wsmanager.Connection, m
 		FileName:    req.FileName,
 		FileContent: req.FileContent,
 	}
+	if err := s.validateFileContents([]upload.UploadRequest{uploadReq}); err != nil {
+		return conn.Send(s.fileContentResponse(conn, req.RequestID, err))
+	}
 	
 	batchReq := upload.BatchUploadRequest{
 		ID:      uploadReq.ID,
@@ -1002,6 +2284,8 @@ func (s *HighPerformanceServer) handleSingleUpload(conn *wsmanager.Connection, m
 			RetryAttempts:    3,
 			RetryDelay:       2 * time.Second,
 			ProgressInterval: 1 * time.Second,
+			KeepMetadata:     s.config.KeepImageMetadata,
+			Optimize:         s.optimizeByDefault(),
 		},
 	}
 	
@@ -1010,10 +2294,14 @@ func (s *HighPerformanceServer) handleSingleUpload(conn *wsmanager.Connection, m
 
 // handleBatchUpload processes batch upload requests
 func (s *HighPerformanceServer) handleBatchUpload(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	if s.uploadsHalted() {
+		return s.sendUploadsDisabled(conn, msg.RequestID)
+	}
+	
 	var req WebSocketRequest
 	reqData, _ := json.Marshal(msg.Data)
 	if err := json.Unmarshal(reqData, &req); err != nil {
-		return fmt.Errorf("invalid batch upload request: %v", err)
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid batch upload request: %v", err)
 	}
 	
 	// Handle new format with Files field or legacy Uploads field
@@ -1028,6 +2316,7 @@ func (s *HighPerformanceServer) handleBatchUpload(conn *wsmanager.Connection, ms
 				Manga:    fileInfo.Manga,
 				Chapter:  fileInfo.Chapter,
 				FileName: fileInfo.FileName,
+				Language: fileInfo.Language,
 				// FileContent will be sent separately or streamed
 			}
 			uploads = append(uploads, uploadReq)
@@ -1035,12 +2324,102 @@ func (s *HighPerformanceServer) handleBatchUpload(conn *wsmanager.Connection, ms
 	} else {
 		// Legacy format
 		uploads = req.Uploads
+		if err := s.validateFileContents(uploads); err != nil {
+			return conn.Send(s.fileContentResponse(conn, req.RequestID, err))
+		}
+	}
+	
+	// Mirroring: every extra host must have a registered uploader
+	for _, host := range req.MirrorHosts {
+		if !s.batchUploader.HasUploader(host) {
+			return conn.Send(wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgMirrorHostUnavailable, host),
+				ErrorCode: wsmanager.ErrHostUnsupported,
+				RequestID: req.RequestID,
+			})
+		}
+	}
+	
+	// Chapter language: each file's own, else the batch default
+	batchLanguage := req.Language
+	if batchLanguage == "" && req.Options != nil {
+		batchLanguage = req.Options.Language
+	}
+	batchLanguage, err := metadata.NormalizeLanguage(batchLanguage)
+	if err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "%v", err)
+	}
+	for i := range uploads {
+		if uploads[i].Language, err = metadata.NormalizeLanguage(uploads[i].Language); err != nil {
+			return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "%s: %v", uploads[i].FileName, err)
+		}
 	}
 	
 	// Create batch request
 	batchReq := upload.BatchUploadRequest{
-		ID:      fmt.Sprintf("batch_%d", time.Now().UnixNano()),
-		Uploads: uploads,
+		ID:             fmt.Sprintf("batch_%d", time.Now().UnixNano()),
+		Uploads:        uploads,
+		IdempotencyKey: req.IdempotencyKey,
+	}
+	
+	// .DS_Store, Thumbs.db, dotfiles and the like are dropped unless asked for
+	uploads, systemFiles := s.filterSystemFiles(uploads, req.Options != nil && req.Options.IncludeHidden)
+	if len(systemFiles) > 0 && len(uploads) == 0 {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgSystemFilesOnly, len(systemFiles)),
+			ErrorCode: wsmanager.ErrInvalidRequest,
+			RequestID: req.RequestID,
+			Data:      map[string]interface{}{"skippedSystemFiles": systemFiles},
+		})
+	}
+	
+	// before_chapter_upload hooks can reject chapters; the rest of the batch goes on
+	uploads, rejected := s.filterChaptersByHook(batchReq.ID, req.Host, uploads)
+	if len(rejected) > 0 && len(uploads) == 0 {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgHookRejected, rejected[0].Error),
+			ErrorCode: wsmanager.ErrHookFailed,
+			RequestID: req.RequestID,
+			Data:      map[string]interface{}{"rejectedChapters": rejected},
+		})
+	}
+	
+	// Files outside the content policy are dropped (or only flagged) and reported back
+	uploads, policyReport := s.applyUploadPolicy(batchReq.ID, uploads)
+	if policyReport.Rejected > 0 && len(uploads) == 0 {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgPolicyRejected, policyReport.Rejected),
+			ErrorCode: wsmanager.ErrPolicyViolation,
+			RequestID: req.RequestID,
+			Data:      map[string]interface{}{"policyReport": policyReport},
+		})
+	}
+	batchReq.Uploads = uploads
+	
+	// Retried submission: return the existing batch instead of starting a new one
+	if req.IdempotencyKey != "" {
+		if existingID, reserved := s.batchUploader.ReserveIdempotencyKey(req.IdempotencyKey, batchReq.ID); !reserved {
+			log.Printf("Duplicate batch submission for idempotency key %s, returning batch %s", req.IdempotencyKey, existingID)
+			
+			data := map[string]interface{}{
+				"batchId":   existingID,
+				"duplicate": true,
+			}
+			if progress, err := s.batchUploader.GetBatchStatus(existingID); err == nil {
+				data["count"] = progress.Total
+				data["progress"] = progress
+			}
+			
+			return conn.Send(wsmanager.Response{
+				Status:    "batch_started",
+				RequestID: req.RequestID,
+				Data:      data,
+			})
+		}
 	}
 	
 	if req.Options != nil {
@@ -1048,38 +2427,90 @@ func (s *HighPerformanceServer) handleBatchUpload(conn *wsmanager.Connection, ms
 	} else {
 		// Default batch options for high performance
 		batchReq.Options = upload.BatchOptions{
-			MaxConcurrency:   min(len(uploads), s.config.MaxWorkers/2),
+			MaxConcurrency:   min(len(uploads), max(1, s.uploadConcurrency()/2)),
 			RetryAttempts:    3,
 			RetryDelay:       2 * time.Second,
 			ProgressInterval: 2 * time.Second,
 		}
 	}
+	if len(req.MirrorHosts) > 0 {
+		batchReq.Options.MirrorHosts = req.MirrorHosts
+	}
+	batchReq.Options.Language = batchLanguage
+	if s.config.KeepImageMetadata {
+		batchReq.Options.KeepMetadata = true
+	}
+	if s.optimizeByDefault() {
+		batchReq.Options.Optimize = true
+	}
 	
-	// Send immediate confirmation
+	// Pages that are exact perceptual copies of an earlier page of the same chapter are not uploaded
+	var skippedDuplicates []phash.Duplicate
+	if batchReq.Options.SkipDuplicatePages {
+		uploads, skippedDuplicates = s.skipDuplicatePages(batchReq.ID, uploads)
+		batchReq.Uploads = uploads
+	}
+	
+	// Confirmation sent once the batch has started
+	data := map[string]interface{}{
+		"batchId": batchReq.ID,
+		"count":   len(uploads),
+	}
+	if len(skippedDuplicates) > 0 {
+		data["skippedDuplicates"] = skippedDuplicates
+	}
+	if len(systemFiles) > 0 {
+		data["skippedSystemFiles"] = systemFiles
+	}
+	if len(rejected) > 0 {
+		data["rejectedChapters"] = rejected
+	}
+	if policyReport.HasViolations() {
+		data["policyReport"] = policyReport
+	}
+	if req.DeferPublish {
+		data["deferPublish"] = true
+	}
 	response := wsmanager.Response{
 		Status:    "batch_started",
 		RequestID: req.RequestID,
-		Data: map[string]interface{}{
-			"batchId": batchReq.ID,
-			"count":   len(uploads),
-		},
+		Data:      data,
 	}
-	conn.Send(response)
 	
-	// Store manga titles for JSON generation
-	if req.GenerateIndividualJSONs && len(req.Files) > 0 {
+	// Store manga titles for JSON generation and release posts
+	if len(req.Files) > 0 {
 		s.uploadResultsMu.Lock()
 		s.batchMangaTitles[batchReq.ID] = make(map[string]string)
 		for _, fileInfo := range req.Files {
 			s.batchMangaTitles[batchReq.ID][fileInfo.MangaID] = fileInfo.Manga
 		}
+		s.uploadResultsTouched[batchReq.ID] = time.Now()
+		s.uploadResultsMu.Unlock()
+	}
+	// Two-phase publish: uploads are kept in the history as unpublished until publish_chapter
+	if req.DeferPublish {
+		s.uploadResultsMu.Lock()
+		s.deferredBatches[batchReq.ID] = true
+		s.uploadResultsTouched[batchReq.ID] = time.Now()
 		s.uploadResultsMu.Unlock()
-		
-		go s.handleJSONGeneration(conn, req, batchReq.ID)
 	}
 	
-	// Start batch upload
-	return s.batchUploader.StartBatch(batchReq)
+	// Start batch upload. batch_started is only sent once the batch exists; on failure
+	// the client gets the error and StartBatch has already released the idempotency key.
+	if err := s.batchUploader.StartBatch(batchReq); err != nil {
+		s.uploadResultsMu.Lock()
+		delete(s.batchMangaTitles, batchReq.ID)
+		delete(s.deferredBatches, batchReq.ID)
+		delete(s.uploadResultsTouched, batchReq.ID)
+		s.uploadResultsMu.Unlock()
+		return err
+	}
+	conn.Send(response)
+	
+	if !req.DeferPublish && req.GenerateIndividualJSONs && len(req.Files) > 0 {
+		go s.handleJSONGeneration(conn, req, batchReq.ID)
+	}
+	return nil
 }
 
 // handleCancelBatch cancels a batch upload
@@ -1087,22 +2518,25 @@ func (s *HighPerformanceServer) handleCancelBatch(conn *wsmanager.Connection, ms
 	var req WebSocketRequest
 	reqData, _ := json.Marshal(msg.Data)
 	if err := json.Unmarshal(reqData, &req); err != nil {
-		return fmt.Errorf("invalid cancel batch request: %v", err)
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid cancel batch request: %v", err)
 	}
 	
 	err := s.batchUploader.CancelBatch(req.BatchID)
 	status := "batch_canceled"
 	var errorMsg string
+	var errorCode wsmanager.ErrorCode
 	
 	if err != nil {
 		status = "error"
 		errorMsg = err.Error()
+		errorCode = wsmanager.ErrBatchNotFound
 	}
 	
 	response := wsmanager.Response{
 		Status:    status,
 		RequestID: req.RequestID,
 		Error:     errorMsg,
+		ErrorCode: errorCode,
 		Data: map[string]interface{}{
 			"batchId": req.BatchID,
 		},
@@ -1111,1554 +2545,6375 @@ func (s *HighPerformanceServer) handleCancelBatch(conn *wsmanager.Connection, ms
 	return conn.Send(response)
 }
 
-// handleJSONGeneration processes individual JSON generation for manga uploads
-func (s *HighPerformanceServer) handleJSONGeneration(conn *wsmanager.Connection, req WebSocketRequest, batchID string) {
-	log.Printf("Starting JSON generation for batch %s with %d manga(s)", batchID, len(req.MangaList))
+// handleGetReleasePost renders the release post of a batch from its successful uploads,
+// optionally narrowed to one manga and chapter. Posts of running batches list what finished so far.
+func (s *HighPerformanceServer) handleGetReleasePost(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid release post request: %v", err)
+	}
+	if req.BatchID == "" {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "batchId is required")
+	}
 	
-	// Wait a bit for uploads to start
-	time.Sleep(2 * time.Second)
+	var files []metadata.UploadedFile
+	for _, file := range s.batchUploadResults(req.BatchID) {
+		if req.Manga != "" && file.MangaID != req.Manga {
+			continue
+		}
+		if req.Chapter != "" && file.ChapterID != req.Chapter {
+			continue
+		}
+		files = append(files, file)
+	}
 	
-	// Monitor batch progress and generate JSONs when uploads complete
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	if len(files) == 0 {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgReleasePostEmpty, req.BatchID),
+			ErrorCode: wsmanager.ErrBatchNotFound,
+			RequestID: req.RequestID,
+		})
+	}
 	
-	processedMangas := make(map[string]bool)
-	uploadResults := make(map[string][]metadata.UploadedFile)
+	post, err := s.releaseBuilder.Build(req.BatchID, s.jsonGenerator.GroupName(), files)
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgReleasePostFailed, err),
+			ErrorCode: wsmanager.ErrInternal,
+			RequestID: req.RequestID,
+		})
+	}
 	
-	for {
-		select {
-		case <-ticker.C:
-			// Check batch status
-			batchProgress, err := s.batchUploader.GetBatchStatus(batchID)
-			if err != nil {
-				log.Printf("Error getting batch status: %v", err)
-				continue
-			}
-			
-			// Get real upload results from captured data
-			s.getUploadResults(batchID, uploadResults)
-			
-			// Process JSONs for completed uploads
-			for _, mangaID := range req.MangaList {
-				if processedMangas[mangaID] {
-					continue
-				}
-				
-				// Check if this manga has uploaded files
-				if files, exists := uploadResults[mangaID]; exists && len(files) > 0 {
-					if err := s.generateMangaJSON(conn, mangaID, files, req); err != nil {
-						log.Printf("Error generating JSON for manga %s: %v", mangaID, err)
-						// Send error notification
-						s.sendJSONError(conn, mangaID, err)
-					} else {
-						processedMangas[mangaID] = true
-					}
-				}
-			}
-			
-			// Check if batch is complete
-			if batchProgress.Completed+batchProgress.Failed >= batchProgress.Total {
-				log.Printf("Batch %s completed, finishing JSON generation", batchID)
-				return
-			}
-			
-		case <-s.ctx.Done():
-			return
+	// Finished batches are dropped from the uploader after a while, so a missing status means done
+	complete := true
+	if progress, err := s.batchUploader.GetBatchStatus(req.BatchID); err == nil {
+		complete = progress.Completed+progress.Failed >= progress.Total
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "release_post",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"post":     post,
+			"complete": complete,
+		},
+	})
+}
+
+// unpublishedChapter is a chapter uploaded with deferPublish that is not in its JSON yet
+type unpublishedChapter struct {
+	MangaID    string    `json:"mangaId"`
+	Title      string    `json:"title,omitempty"`
+	Chapter    string    `json:"chapter"`
+	Pages      int       `json:"pages"`
+	Groups     []string  `json:"groups"`
+	UploadedAt time.Time `json:"uploadedAt"` // Latest upload of the chapter
+}
+
+// unpublishedRecords returns the history records still waiting for publish_chapter,
+// optionally narrowed to one manga and chapter
+func (s *HighPerformanceServer) unpublishedRecords(mangaID, chapter string) ([]analytics.Record, error) {
+	records, err := s.uploadHistory.Load()
+	if err != nil {
+		return nil, err
+	}
+	var pending []analytics.Record
+	for _, record := range records {
+		if !record.Unpublished {
+			continue
+		}
+		if mangaID != "" && record.MangaID != mangaID {
+			continue
 		}
+		if chapter != "" && record.Chapter != chapter {
+			continue
+		}
+		pending = append(pending, record)
 	}
+	return pending, nil
 }
 
-// generateMangaJSON generates JSON for a specific manga
-func (s *HighPerformanceServer) generateMangaJSON(conn *wsmanager.Connection, mangaID string, uploadedFiles []metadata.UploadedFile, req WebSocketRequest) error {
-	// Get manga metadata from files
-	var mangaTitle string
-	for _, file := range uploadedFiles {
-		if file.MangaID == mangaID {
-			mangaTitle = file.MangaTitle
-			break
+// unpublishedFiles rebuilds the uploaded pages of a chapter from its unpublished records.
+// Mirror records become Mirrors of the page with the same file; a page uploaded again
+// before publishing keeps its latest upload.
+func (s *HighPerformanceServer) unpublishedFiles(records []analytics.Record) []metadata.UploadedFile {
+	type pageKey struct{ group, file string }
+	pages := make(map[pageKey]*metadata.UploadedFile)
+	var order []pageKey
+	for _, record := range records {
+		if _, _, mirror := metadata.ParseMirrorGroup(record.Group); mirror {
+			continue
+		}
+		key := pageKey{record.Group, record.File}
+		if _, exists := pages[key]; !exists {
+			order = append(order, key)
+		}
+		pageIndex := record.Page
+		if pageIndex == 0 {
+			pageIndex = s.extractPageIndexFromFileName(record.File)
+		}
+		pages[key] = &metadata.UploadedFile{
+			MangaID:    record.MangaID,
+			MangaTitle: record.Title,
+			ChapterID:  record.Chapter,
+			FileName:   record.File,
+			URL:        record.URL,
+			PageIndex:  pageIndex,
+			Language:   metadata.GroupLanguage(record.Group),
 		}
 	}
-	
-	// Create manga metadata (in real implementation, this would come from a database or discovery)
-	mangaMetadata := metadata.MangaMetadata{
-		ID:          mangaID,
-		Title:       mangaTitle,
-		Description: fmt.Sprintf("Descrição da obra %s", mangaTitle),
-		Artist:      "Artista Desconhecido",
-		Author:      "Autor Desconhecido", 
-		Cover:       fmt.Sprintf("https://placehold.co/200x300/1f2937/9ca3af?text=%s", mangaTitle),
-		Status:      "Em Andamento",
+	for _, record := range records {
+		group, host, mirror := metadata.ParseMirrorGroup(record.Group)
+		if !mirror {
+			continue
+		}
+		if page, exists := pages[pageKey{group, record.File}]; exists {
+			if page.Mirrors == nil {
+				page.Mirrors = make(map[string]string)
+			}
+			page.Mirrors[host] = record.URL
+		}
 	}
 	
-	metadataMap := map[string]metadata.MangaMetadata{
-		mangaID: mangaMetadata,
+	files := make([]metadata.UploadedFile, 0, len(order))
+	for _, key := range order {
+		files = append(files, *pages[key])
 	}
-	
-	// Send JSON generation start notification
-	s.sendJSONProgress(conn, "json_generated", mangaID, mangaTitle, "")
-	
-	// Check if JSON already exists (use mangaID as unique identifier)
-	// Extract folder name from mangaID (remove "auto-" prefix if present)
-	folderName := mangaID
-	if strings.HasPrefix(mangaID, "auto-") {
-		folderName = strings.TrimPrefix(mangaID, "auto-")
+	return files
+}
+
+// handleListUnpublishedChapters lists the chapters uploaded with deferPublish that wait
+// for review, optionally narrowed to one manga
+func (s *HighPerformanceServer) handleListUnpublishedChapters(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid unpublished chapters request: %v", err)
 	}
-	sanitizedFolderName := s.jsonGenerator.SanitizeFilename(folderName)
-	expectedJSONPath := filepath.Join("json", fmt.Sprintf("%s.json", sanitizedFolderName))
 	
-	var jsonPaths []string
+	mangaID := ""
+	if req.Manga != "" {
+		mangaID = mangaid.Normalize(req.Manga)
+	}
+	records, err := s.unpublishedRecords(mangaID, "")
+	if err != nil {
+		return wsmanager.WithCode(wsmanager.ErrIO, err)
+	}
 	
-	if _, statErr := os.Stat(expectedJSONPath); statErr == nil {
-		// JSON exists - use update mode from request or default to smart
-		updateMode := req.UpdateMode
-		if updateMode == "" {
-			updateMode = "smart" // Default mode
-		}
-		
-		// Passar metadados opcionais se disponível para preservar informações base
-		if mangaMetadata, exists := metadataMap[mangaID]; exists {
-			if err := s.jsonGenerator.UpdateExistingJSON(expectedJSONPath, uploadedFiles, updateMode, mangaMetadata); err != nil {
-				return fmt.Errorf("failed to update existing JSON: %v", err)
+	byChapter := make(map[[2]string][]analytics.Record)
+	for _, record := range records {
+		key := [2]string{record.MangaID, record.Chapter}
+		byChapter[key] = append(byChapter[key], record)
+	}
+	chapters := make([]unpublishedChapter, 0, len(byChapter))
+	for key, chapterRecords := range byChapter {
+		chapter := unpublishedChapter{MangaID: key[0], Chapter: key[1], Pages: len(s.unpublishedFiles(chapterRecords))}
+		groups := make(map[string]bool)
+		for _, record := range chapterRecords {
+			if record.Title != "" {
+				chapter.Title = record.Title
 			}
-		} else {
-			// Sem metadados - apenas atualizar capítulos
-			if err := s.jsonGenerator.UpdateExistingJSON(expectedJSONPath, uploadedFiles, updateMode); err != nil {
-				return fmt.Errorf("failed to update existing JSON: %v", err)
+			if record.Time.After(chapter.UploadedAt) {
+				chapter.UploadedAt = record.Time
+			}
+			if !groups[record.Group] {
+				groups[record.Group] = true
+				chapter.Groups = append(chapter.Groups, record.Group)
 			}
 		}
-		
-		jsonPaths = []string{expectedJSONPath}
-		log.Printf("Updated existing JSON for manga %s at %s using mode: %s", mangaID, expectedJSONPath, updateMode)
-	} else {
-		// JSON doesn't exist - create new one
-		var err error
-		jsonPaths, err = s.jsonGenerator.GenerateIndividualJSONs(uploadedFiles, metadataMap)
-		if err != nil {
-			return fmt.Errorf("failed to generate JSON: %v", err)
-		}
-		log.Printf("Generated new JSON for manga %s", mangaID)
-	}
-	
-	// Send completion notification
-	for _, jsonPath := range jsonPaths {
-		s.sendJSONProgress(conn, "json_complete", mangaID, mangaTitle, jsonPath)
-		log.Printf("JSON processing complete for manga %s at %s", mangaID, jsonPath)
+		sort.Strings(chapter.Groups)
+		chapters = append(chapters, chapter)
 	}
+	sort.Slice(chapters, func(i, j int) bool {
+		if chapters[i].MangaID != chapters[j].MangaID {
+			return chapters[i].MangaID < chapters[j].MangaID
+		}
+		return chapters[i].Chapter < chapters[j].Chapter
+	})
 	
-	return nil
+	return conn.Send(wsmanager.Response{
+		Status:    "unpublished_chapters",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"chapters": chapters,
+		},
+	})
 }
 
-// getUploadResults retrieves real upload results from captured data
-func (s *HighPerformanceServer) getUploadResults(batchID string, uploadResults map[string][]metadata.UploadedFile) {
-	s.uploadResultsMu.RLock()
-	defer s.uploadResultsMu.RUnlock()
-	
-	// Get real results for this batch
-	if realResults, exists := s.uploadResults[batchID]; exists {
-		for _, uploadedFile := range realResults {
-			uploadResults[uploadedFile.MangaID] = append(uploadResults[uploadedFile.MangaID], uploadedFile)
-		}
-		log.Printf("Retrieved %d real upload results for batch %s", len(realResults), batchID)
+// handlePublishChapter writes a chapter uploaded with deferPublish into the manga JSON
+// and, when GitHub settings are given, syncs that JSON to the repository
+func (s *HighPerformanceServer) handlePublishChapter(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid publish request: %v", err)
 	}
-}
-
-// sendJSONProgress sends JSON progress notifications
-func (s *HighPerformanceServer) sendJSONProgress(conn *wsmanager.Connection, status, mangaID, mangaTitle, jsonPath string) {
-	response := wsmanager.Response{
-		Status:    status,
-		MangaID:   mangaID,
-		MangaTitle: mangaTitle,
-		JSONPath:  jsonPath,
+	if req.Manga == "" || req.Chapter == "" {
+		field := "manga"
+		if req.Manga != "" {
+			field = "chapter"
+		}
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, field),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
 	}
 	
-	conn.Send(response)
-}
-
-// sendJSONError sends JSON error notifications
-func (s *HighPerformanceServer) sendJSONError(conn *wsmanager.Connection, mangaID string, err error) {
-	response := wsmanager.Response{
-		Status:  "json_error",
-		MangaID: mangaID,
-		Error:   err.Error(),
+	if req.Token == "" && req.GitHubSettings != nil {
+		req.Token, _ = req.GitHubSettings["token"].(string)
+		req.Repo, _ = req.GitHubSettings["repo"].(string)
+		req.Branch, _ = req.GitHubSettings["branch"].(string)
+		req.Folder, _ = req.GitHubSettings["folder"].(string)
+	}
+	syncGitHub := req.Token != "" || req.Repo != ""
+	if syncGitHub && (req.Token == "" || req.Repo == "") {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgGitHubCredentialsMissing),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	if req.Branch == "" {
+		req.Branch = "main"
 	}
 	
-	conn.Send(response)
-}
-
-// handleUploadResult captures real upload results for JSON generation
-func (s *HighPerformanceServer) handleUploadResult(batchID string, result upload.UploadResult) {
-	if result.Error != nil {
-		// Skip failed uploads
-		return
+	mangaID := mangaid.Normalize(req.Manga)
+	records, err := s.unpublishedRecords(mangaID, req.Chapter)
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgPublishFailed, err),
+			ErrorCode: wsmanager.ErrIO,
+			RequestID: req.RequestID,
+		})
+	}
+	files := s.unpublishedFiles(records)
+	if len(files) == 0 {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgPublishNothing, req.Manga, req.Chapter),
+			ErrorCode: wsmanager.ErrNothingToPublish,
+			RequestID: req.RequestID,
+		})
 	}
 	
-	s.uploadResultsMu.Lock()
-	defer s.uploadResultsMu.Unlock()
+	if err := s.generateMangaJSON(conn, mangaID, files, req); err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgPublishFailed, err),
+			ErrorCode: wsmanager.CodeOf(err),
+			RequestID: req.RequestID,
+		})
+	}
 	
-	// Extract manga information from result ID (format: file_{mangaID}_{chapter}_{timestamp})
-	parts := strings.Split(result.ID, "_")
-	if len(parts) < 3 {
-		log.Printf("Invalid upload result ID format: %s", result.ID)
-		return
+	// The JSON is written: a failure here only means the chapter is listed again
+	// (publishing it twice merges the same URLs)
+	if _, err := s.uploadHistory.Update(func(record *analytics.Record) bool {
+		if !record.Unpublished || record.MangaID != mangaID || record.Chapter != req.Chapter {
+			return false
+		}
+		record.Unpublished = false
+		return true
+	}); err != nil {
+		log.Printf("⚠️ Failed to mark %s chapter %s as published: %v", mangaID, req.Chapter, err)
 	}
 	
-	mangaID := parts[1]
-	chapterID := parts[2]
+	jsonPath := mangaid.Path(s.config.MetadataOutput, mangaID)
+	log.Printf("📢 Published %s chapter %s (%d pages) to %s", mangaID, req.Chapter, len(files), jsonPath)
 	
-	// Get manga title from stored batch info
-	var mangaTitle string
-	if batchTitles, exists := s.batchMangaTitles[batchID]; exists {
-		mangaTitle = batchTitles[mangaID]
+	data := map[string]interface{}{
+		"mangaId":  mangaID,
+		"chapter":  req.Chapter,
+		"pages":    len(files),
+		"jsonPath": jsonPath,
+		"synced":   false,
 	}
-	
-	// Create uploaded file entry with real URL and page index
-	uploadedFile := metadata.UploadedFile{
-		MangaID:    mangaID,
-		MangaTitle: mangaTitle,
-		ChapterID:  chapterID,
-		FileName:   result.FileName,
-		URL:        result.URL, // Real URL from upload
-		PageIndex:  s.extractPageIndexFromFileName(result.FileName),
+	if !syncGitHub {
+		return conn.Send(wsmanager.Response{Status: "chapter_published", RequestID: req.RequestID, Data: data})
 	}
 	
-	// Store result by batchID
-	s.uploadResults[batchID] = append(s.uploadResults[batchID], uploadedFile)
+	go func() {
+		content, err := os.ReadFile(jsonPath)
+		if err == nil {
+			var withheld []string
+			if content, withheld, err = s.reviewedJSON(mangaID, content); len(withheld) > 0 {
+				data["withheldChapters"] = withheld
+			}
+		}
+		if err == nil {
+			syncOptions := github.SyncOptions{}
+			if index, _, indexErr := s.catalogIndex.JSON(); indexErr != nil {
+				log.Printf("⚠️ Failed to build catalog index: %v", indexErr)
+			} else {
+				syncOptions.ExtraFiles = map[string]string{catalog.FileName: string(index)}
+			}
+			s.addChecksumFiles(&syncOptions, []string{mangaID})
+			jsonFiles := map[string]string{filepath.Base(jsonPath): string(content)}
+			var syncResult *github.SyncResult
+			if err = s.signJSONFiles(&syncOptions, jsonFiles); err == nil {
+				syncResult, err = s.githubService.SyncJSONFiles(req.Token, req.Repo, req.Branch, req.Folder, jsonFiles, syncOptions)
+			}
+			if err == nil {
+				data["synced"] = true
+				data["commit"] = syncResult.CommitResponse
+				data["changes"] = syncResult.Changes
+				data["reviewPublished"] = s.markReviewedPublished([]string{mangaID})
+			}
+		}
+		if err != nil {
+			log.Printf("GitHub sync error after publishing %s chapter %s: %v", mangaID, req.Chapter, err)
+			safeSend(conn, wsmanager.Response{
+				Status:    "github_error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgGitHubUploadFailed, err),
+				ErrorCode: wsmanager.ErrGitHubFailed,
+				RequestID: req.RequestID,
+				Data:      data,
+			})
+			return
+		}
+		
+		log.Printf("✅ Synced published %s chapter %s to %s", mangaID, req.Chapter, req.Repo)
+		data["repo"] = req.Repo
+		data["branch"] = req.Branch
+		data["folder"] = req.Folder
+		safeSend(conn, wsmanager.Response{Status: "chapter_published", RequestID: req.RequestID, Data: data})
+	}()
 	
-	log.Printf("Captured real upload result: %s -> %s (page %d)", result.FileName, result.URL, uploadedFile.PageIndex)
-}
-
-// extractPageIndexFromFileName extrai o índice da página do nome do arquivo
-func (s *HighPerformanceServer) extractPageIndexFromFileName(fileName string) int {
-	// Usar a mesma lógica do JSONGenerator
-	jsonGen := metadata.NewJSONGenerator("", "")
-	return jsonGen.ExtractPageIndex(fileName)
+	return nil
 }
 
-// handleGetMetrics returns current system metrics
-func (s *HighPerformanceServer) handleGetMetrics(conn *wsmanager.Connection, msg wsmanager.Message) error {
-	metrics := s.monitor.GetMetrics()
-	perfMetrics := s.monitor.GetPerformanceMetrics()
-	
-	response := wsmanager.Response{
-		Status:    "metrics",
-		RequestID: msg.RequestID,
-		Data: map[string]interface{}{
-			"metrics":     metrics,
-			"performance": perfMetrics,
-			"connections": s.wsManager.GetConnectionCount(),
-		},
+// connReviewer returns who acts on a connection in the review workflow. Without
+// configured users every connection is an admin; otherwise set_reviewer is required.
+func (s *HighPerformanceServer) connReviewer(conn *wsmanager.Connection) (string, registry.Role, bool) {
+	if len(s.config.Review.Users) == 0 {
+		return "", registry.RoleAdmin, true
 	}
-	
-	return conn.Send(response)
+	name, role := conn.Reviewer()
+	if role == "" {
+		return "", "", false
+	}
+	return name, registry.Role(role), true
 }
 
-// handleGetStatus returns server status information
-func (s *HighPerformanceServer) handleGetStatus(conn *wsmanager.Connection, msg wsmanager.Message) error {
-	response := wsmanager.Response{
-		Status:    "status",
-		RequestID: msg.RequestID,
-		Data: map[string]interface{}{
-			"server":      "high-performance-manga-uploader",
-			"version":     "2.0.0",
-			"uptime":      time.Since(startTime).String(),
-			"connections": s.wsManager.GetConnectionCount(),
-			"config":      s.config,
-		},
-	}
-	
-	return conn.Send(response)
+// sendReviewDisabled rejects review actions while review.enabled is off
+func (s *HighPerformanceServer) sendReviewDisabled(conn *wsmanager.Connection, requestID string) error {
+	return conn.Send(wsmanager.Response{
+		Status:    "error",
+		Error:     i18n.T(connLocale(conn), i18n.MsgReviewDisabled),
+		ErrorCode: wsmanager.ErrInvalidRequest,
+		RequestID: requestID,
+	})
 }
 
-// handleProcessCollection processa uma coleção completa de mangás
-func (s *HighPerformanceServer) handleProcessCollection(conn *wsmanager.Connection, msg wsmanager.Message) error {
+// handleSetReviewer identifies the team member using this connection by the token
+// of a review.users entry; their role decides which status changes are allowed
+func (s *HighPerformanceServer) handleSetReviewer(conn *wsmanager.Connection, msg wsmanager.Message) error {
 	var req WebSocketRequest
 	reqData, _ := json.Marshal(msg.Data)
 	if err := json.Unmarshal(reqData, &req); err != nil {
-		return fmt.Errorf("invalid process collection request: %v", err)
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid set reviewer request: %v", err)
 	}
-	
-	// Valida parâmetros obrigatórios
-	if req.CollectionName == "" {
+	if !s.config.reviewEnabled() {
+		return s.sendReviewDisabled(conn, req.RequestID)
+	}
+	if req.ReviewerToken == "" {
 		return conn.Send(wsmanager.Response{
 			Status:    "error",
-			Error:     "collectionName is required",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "reviewerToken"),
+			ErrorCode: wsmanager.ErrMissingField,
 			RequestID: req.RequestID,
 		})
 	}
 	
-	if req.BasePath == "" {
+	user, found := s.config.reviewUser(req.ReviewerToken)
+	if !found {
 		return conn.Send(wsmanager.Response{
-			Status:    "error", 
-			Error:     "basePath is required",
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgReviewerUnknown),
+			ErrorCode: wsmanager.ErrReviewDenied,
 			RequestID: req.RequestID,
 		})
 	}
-	
-	if req.Host == "" {
-		req.Host = "catbox" // Default
+	role, err := registry.ParseRole(user.Role)
+	if err != nil {
+		return wsmanager.WithCode(wsmanager.ErrConfigFailed, fmt.Errorf("review user %s: %v", user.Name, err))
 	}
+	conn.SetReviewer(user.Name, string(role))
+	log.Printf("👤 Connection %s identified as %s (%s)", conn.ID, user.Name, role)
 	
-	// Gera ID único se não fornecido
-	if req.CollectionID == "" {
-		req.CollectionID = fmt.Sprintf("collection_%d", time.Now().UnixNano())
-	}
-	
-	// Configura opções de processamento
-	processorOptions := &collection.ProcessorConfig{
-		MaxConcurrency:    req.ParallelLimit,
-		BatchSize:         50,
-		RetryAttempts:     3,
-		RetryDelay:        2 * time.Second,
-		ProgressInterval:  2 * time.Second,
-		EnablePersistence: true,
-		StateFilePath:     "collection_state",
-	}
-	
-	if req.CollectionOptions != nil {
-		if req.CollectionOptions.MaxConcurrency > 0 {
-			processorOptions.MaxConcurrency = req.CollectionOptions.MaxConcurrency
-		}
-		if req.CollectionOptions.BatchSize > 0 {
-			processorOptions.BatchSize = req.CollectionOptions.BatchSize
-		}
-		if req.CollectionOptions.RetryAttempts > 0 {
-			processorOptions.RetryAttempts = req.CollectionOptions.RetryAttempts
-		}
-		processorOptions.EnablePersistence = req.CollectionOptions.EnablePersistence
-		
-		if req.CollectionOptions.ResumeFrom != "" {
-			processorOptions.ResumeFrom = req.CollectionOptions.ResumeFrom
-		}
-		processorOptions.SkipExisting = req.CollectionOptions.SkipExisting
-	}
-	
-	// Se não especificado, usa configuração padrão
-	if processorOptions.MaxConcurrency <= 0 {
-		processorOptions.MaxConcurrency = min(req.ParallelLimit, s.config.MaxWorkers)
-		if processorOptions.MaxConcurrency <= 0 {
-			processorOptions.MaxConcurrency = 100
-		}
-	}
-	
-	// Resolve caminho completo
-	fullPath := req.BasePath
-	if !filepath.IsAbs(fullPath) {
-		fullPath = filepath.Join(s.config.LibraryRoot, req.BasePath)
-	}
-	
-	// Callback de progresso - envia via WebSocket
-	onProgress := func(update *collection.ProgressUpdate) {
-		response := wsmanager.Response{
-			Status:    "collection_progress",
-			RequestID: req.RequestID,
-			Data: map[string]interface{}{
-				"collection":     req.CollectionName,
-				"collectionId":   req.CollectionID,
-				"progress":       update.Progress,
-				"currentFile":    update.CurrentFile,
-				"updateType":     update.Type,
-				"timestamp":      update.Timestamp,
-			},
-		}
-		conn.Send(response)
-	}
-	
-	// Callback de conclusão
-	onComplete := func(err error) {
-		status := "collection_completed"
-		errorMsg := ""
-		
-		if err != nil {
-			status = "collection_failed"
-			errorMsg = err.Error()
-		}
-		
-		response := wsmanager.Response{
-			Status:    status,
-			RequestID: req.RequestID,
-			Error:     errorMsg,
-			Data: map[string]interface{}{
-				"collection":   req.CollectionName,
-				"collectionId": req.CollectionID,
-				"timestamp":    time.Now(),
-			},
-		}
-		conn.Send(response)
-	}
-	
-	// Cria requisição de processamento
-	collectionReq := &collection.CollectionRequest{
-		ID:             req.CollectionID,
-		CollectionName: req.CollectionName,
-		BasePath:       fullPath,
-		Host:           req.Host,
-		Options:        processorOptions,
-		OnProgress:     onProgress,
-		OnComplete:     onComplete,
-	}
-	
-	// Inicia processamento
-	job, err := s.collectionProcessor.ProcessCollection(collectionReq)
-	if err != nil {
-		return conn.Send(wsmanager.Response{
-			Status:    "error",
-			Error:     fmt.Sprintf("Failed to start collection processing: %v", err),
-			RequestID: req.RequestID,
-		})
-	}
-	
-	// Resposta imediata de confirmação
-	response := wsmanager.Response{
-		Status:    "collection_started",
+	return conn.Send(wsmanager.Response{
+		Status:    "reviewer_set",
 		RequestID: req.RequestID,
 		Data: map[string]interface{}{
-			"collection":   req.CollectionName,
-			"collectionId": job.ID,
-			"basePath":     fullPath,
-			"host":         req.Host,
-			"options":      processorOptions,
-			"timestamp":    job.StartTime,
+			"name": user.Name,
+			"role": role,
 		},
-	}
-	
-	return conn.Send(response)
+	})
 }
 
-// handleGetCollectionStatus retorna o status de uma coleção
-func (s *HighPerformanceServer) handleGetCollectionStatus(conn *wsmanager.Connection, msg wsmanager.Message) error {
+// handleListChapterReviews lists the chapters in the review workflow, optionally
+// narrowed to one manga and one status (e.g. the QC queue)
+func (s *HighPerformanceServer) handleListChapterReviews(conn *wsmanager.Connection, msg wsmanager.Message) error {
 	var req WebSocketRequest
 	reqData, _ := json.Marshal(msg.Data)
 	if err := json.Unmarshal(reqData, &req); err != nil {
-		return fmt.Errorf("invalid get collection status request: %v", err)
-	}
-	
-	if req.CollectionID == "" {
-		return conn.Send(wsmanager.Response{
-			Status:    "error",
-			Error:     "collectionId is required",
-			RequestID: req.RequestID,
-		})
-	}
-	
-	job, exists := s.collectionProcessor.GetJobStatus(req.CollectionID)
-	if !exists {
-		return conn.Send(wsmanager.Response{
-			Status:    "error",
-			Error:     "collection not found",
-			RequestID: req.RequestID,
-		})
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid chapter reviews request: %v", err)
 	}
 	
-	// Calcula progresso atual (acessos thread-safe via métodos do job)
-	progress := &collection.CollectionProgress{
-		TotalObras:        job.TotalObras,
-		CompletedObras:    job.CompletedObras,
-		TotalChapters:     job.TotalChapters,
-		CompletedChapters: job.CompletedChapters,
-		TotalFiles:        job.TotalFiles,
-		UploadedFiles:     job.UploadedFiles,
-		FailedFiles:       job.FailedFiles,
-		CurrentSpeed:      job.CurrentSpeed,
-		AverageSpeed:      job.AverageSpeed,
+	var status registry.ChapterStatus
+	if req.Status != "" {
+		var err error
+		if status, err = registry.ParseChapterStatus(req.Status); err != nil {
+			return wsmanager.WithCode(wsmanager.ErrInvalidRequest, err)
+		}
 	}
-	
-	if job.TotalFiles > 0 {
-		progress.Percentage = float64(job.UploadedFiles) / float64(job.TotalFiles) * 100
+	mangaID := ""
+	if req.Manga != "" {
+		mangaID = mangaid.Normalize(req.Manga)
 	}
 	
-	if job.ETA != nil {
-		progress.ETA = job.ETA.String()
+	reviews := s.idRegistry.ChapterReviews(mangaID, status)
+	if reviews == nil {
+		reviews = []registry.ChapterReviewEntry{}
 	}
-	
-	response := wsmanager.Response{
-		Status:    "collection_status",
+	return conn.Send(wsmanager.Response{
+		Status:    "chapter_reviews",
 		RequestID: req.RequestID,
 		Data: map[string]interface{}{
-			"collection":   job.Name,
-			"collectionId": job.ID,
-			"status":       job.Status,
-			"progress":     progress,
-			"startTime":    job.StartTime,
-			"lastFile":     job.LastProcessedFile,
+			"enabled":  s.config.reviewEnabled(),
+			"chapters": reviews,
 		},
-	}
-	
-	return conn.Send(response)
+	})
 }
 
-// handleCancelCollection cancela uma coleção
-func (s *HighPerformanceServer) handleCancelCollection(conn *wsmanager.Connection, msg wsmanager.Message) error {
+// handleSetChapterStatus moves a chapter through the review workflow. The role of the
+// connection (set_reviewer) must allow the transition; every client is notified.
+func (s *HighPerformanceServer) handleSetChapterStatus(conn *wsmanager.Connection, msg wsmanager.Message) error {
 	var req WebSocketRequest
 	reqData, _ := json.Marshal(msg.Data)
 	if err := json.Unmarshal(reqData, &req); err != nil {
-		return fmt.Errorf("invalid cancel collection request: %v", err)
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid chapter status request: %v", err)
+	}
+	if !s.config.reviewEnabled() {
+		return s.sendReviewDisabled(conn, req.RequestID)
+	}
+	for _, field := range []struct{ name, value string }{{"manga", req.Manga}, {"chapter", req.Chapter}, {"status", req.Status}} {
+		if field.value == "" {
+			return conn.Send(wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, field.name),
+				ErrorCode: wsmanager.ErrMissingField,
+				RequestID: req.RequestID,
+			})
+		}
+	}
+	status, err := registry.ParseChapterStatus(req.Status)
+	if err != nil {
+		return wsmanager.WithCode(wsmanager.ErrInvalidRequest, err)
 	}
 	
-	if req.CollectionID == "" {
+	name, role, identified := s.connReviewer(conn)
+	if !identified {
 		return conn.Send(wsmanager.Response{
 			Status:    "error",
-			Error:     "collectionId is required",
+			Error:     i18n.T(connLocale(conn), i18n.MsgReviewNotIdentified),
+			ErrorCode: wsmanager.ErrReviewDenied,
 			RequestID: req.RequestID,
 		})
 	}
 	
-	err := s.collectionProcessor.CancelJob(req.CollectionID)
-	status := "collection_cancelled"
-	errorMsg := ""
-	
+	mangaID := mangaid.Normalize(req.Manga)
+	chapter := metadata.ChapterKey(req.Chapter)
+	review, err := s.idRegistry.TransitionChapter(mangaID, chapter, status, name, role, req.Note)
 	if err != nil {
-		status = "error"
-		errorMsg = err.Error()
+		code := wsmanager.ErrInvalidRequest
+		if errors.Is(err, registry.ErrRoleDenied) {
+			code = wsmanager.ErrReviewDenied
+		}
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgReviewFailed, err),
+			ErrorCode: code,
+			RequestID: req.RequestID,
+		})
+	}
+	log.Printf("📝 %s chapter %s → %s (by %s, %s)", mangaID, chapter, status, name, role)
+	
+	data := map[string]interface{}{"mangaId": mangaID, "chapter": chapter, "by": name}
+	if len(review.History) > 0 && review.History[len(review.History)-1].From == registry.ChapterQC && status == registry.ChapterUploaded {
+		data["note"] = review.Note
+		s.notify(notifications.KindReviewReturned, notifications.LevelWarning, i18n.MsgNotifyReviewReturned, data, mangaID, chapter)
+	} else if status == registry.ChapterQC {
+		s.notify(notifications.KindApprovalPending, notifications.LevelInfo, i18n.MsgNotifyApprovalPending, data, mangaID, chapter)
 	}
 	
-	response := wsmanager.Response{
-		Status:    status,
+	// Other team members see the chapter move without polling
+	s.wsManager.Broadcast(wsmanager.Response{
+		Status:    "chapter_status_changed",
 		RequestID: req.RequestID,
-		Error:     errorMsg,
 		Data: map[string]interface{}{
-			"collectionId": req.CollectionID,
-			"timestamp":    time.Now(),
+			"mangaId": mangaID,
+			"chapter": chapter,
+			"review":  review,
 		},
+	})
+	return nil
+}
+
+// reviewedJSON withholds from a JSON about to be synced the chapters that are not
+// approved yet (review workflow enabled); the local file keeps them
+func (s *HighPerformanceServer) reviewedJSON(work string, content []byte) ([]byte, []string, error) {
+	if !s.config.reviewEnabled() {
+		return content, nil, nil
 	}
-	
-	return conn.Send(response)
+	mangaID := mangaid.Normalize(work)
+	return s.jsonGenerator.FilterChapters(content, func(key string) bool {
+		return s.idRegistry.ChapterPublishable(mangaID, key)
+	})
 }
 
-// handlePauseCollection pausa uma coleção (placeholder para futura implementação)
-func (s *HighPerformanceServer) handlePauseCollection(conn *wsmanager.Connection, msg wsmanager.Message) error {
-	var req WebSocketRequest
-	reqData, _ := json.Marshal(msg.Data)
-	if err := json.Unmarshal(reqData, &req); err != nil {
-		return fmt.Errorf("invalid pause collection request: %v", err)
+// markReviewedPublished moves the approved chapters of synced works to published
+func (s *HighPerformanceServer) markReviewedPublished(works []string) map[string][]string {
+	if !s.config.reviewEnabled() {
+		return nil
 	}
-	
-	// TODO: Implementar pause functionality no collection processor
-	response := wsmanager.Response{
-		Status:    "error",
-		Error:     "pause functionality not yet implemented",
-		RequestID: req.RequestID,
+	published := make(map[string][]string)
+	for _, work := range works {
+		mangaID := mangaid.Normalize(work)
+		chapters, err := s.idRegistry.PublishApproved(mangaID, "github_sync")
+		if err != nil {
+			log.Printf("⚠️ Failed to mark %s chapters as published: %v", mangaID, err)
+		}
+		if len(chapters) > 0 {
+			published[mangaID] = chapters
+			if s.mangaWebhooks.Has(mangaID) {
+				go s.notifyMangaWebhook(mangaID, mangaid.Path(s.config.MetadataOutput, mangaID), nil, chapters)
+			}
+		}
 	}
-	
-	return conn.Send(response)
+	return published
 }
 
-// handleResumeCollection retoma uma coleção (placeholder para futura implementação)
-func (s *HighPerformanceServer) handleResumeCollection(conn *wsmanager.Connection, msg wsmanager.Message) error {
-	var req WebSocketRequest
-	reqData, _ := json.Marshal(msg.Data)
-	if err := json.Unmarshal(reqData, &req); err != nil {
-		return fmt.Errorf("invalid resume collection request: %v", err)
-	}
-	
-	// TODO: Implementar resume functionality no collection processor
-	response := wsmanager.Response{
+// uploadsHalted reports whether uploads are disabled by emergency stop or safe mode
+func (s *HighPerformanceServer) uploadsHalted() bool {
+	return atomic.LoadInt32(&s.uploadsDisabled) == 1
+}
+
+// sendUploadsDisabled rejects an upload request while uploads are disabled
+func (s *HighPerformanceServer) sendUploadsDisabled(conn *wsmanager.Connection, requestID string) error {
+	return conn.Send(wsmanager.Response{
 		Status:    "error",
-		Error:     "resume functionality not yet implemented",
-		RequestID: req.RequestID,
-	}
-	
-	return conn.Send(response)
+		Error:     i18n.T(connLocale(conn), i18n.MsgUploadsDisabled),
+		ErrorCode: wsmanager.ErrUploadsDisabled,
+		RequestID: requestID,
+	})
 }
 
-// handleGetWorkerStats retorna estatísticas do worker pool
-func (s *HighPerformanceServer) handleGetWorkerStats(conn *wsmanager.Connection, msg wsmanager.Message) error {
-	workerStats := s.workerPool.GetStats()
-	collectionStats := s.collectionProcessor.GetMetrics()
+// handleEmergencyStop halts every running upload immediately.
+// Collections are paused with their progress saved so they can be resumed later.
+func (s *HighPerformanceServer) handleEmergencyStop(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	atomic.StoreInt32(&s.uploadsDisabled, 1)
 	
-	response := wsmanager.Response{
-		Status:    "worker_stats",
+	canceledBatches := s.batchUploader.CancelAll()
+	haltedCollections := s.collectionProcessor.Halt()
+	
+	log.Printf("🛑 Emergency stop: %d batch(es) canceled, %d collection(s) paused", len(canceledBatches), len(haltedCollections))
+	
+	// Notify every client, not only the one that triggered the stop
+	s.wsManager.Broadcast(wsmanager.Response{
+		Status:    "emergency_stopped",
 		RequestID: msg.RequestID,
 		Data: map[string]interface{}{
-			"workerPool":          workerStats,
-			"collectionProcessor": collectionStats,
-			"server": map[string]interface{}{
-				"uptime":      time.Since(startTime).String(),
-				"connections": s.wsManager.GetConnectionCount(),
-			},
+			"canceledBatches":   canceledBatches,
+			"pausedCollections": haltedCollections,
+			"timestamp":         time.Now(),
 		},
-	}
+	})
 	
-	return conn.Send(response)
+	return nil
 }
 
-// handleSearchAniList handles AniList search requests
-func (s *HighPerformanceServer) handleSearchAniList(conn *wsmanager.Connection, msg wsmanager.Message) error {
-	var req WebSocketRequest
-	reqData, _ := json.Marshal(msg.Data)
-	if err := json.Unmarshal(reqData, &req); err != nil {
-		return fmt.Errorf("invalid search request: %v", err)
-	}
-
-	// DEBUG: Log da query recebida
-	log.Printf("🔍 DEBUG SEARCH - Query recebida: '%s' (len: %d bytes)", req.SearchQuery, len(req.SearchQuery))
-	log.Printf("🔍 DEBUG SEARCH - Query em hex: % x", []byte(req.SearchQuery))
-	
-	if req.SearchQuery == "" {
-		response := wsmanager.Response{
-			Status:    "error",
-			Error:     "Search query is required",
-			RequestID: req.RequestID,
+// handleResumeUploads re-enables uploads after an emergency stop or safe-mode startup
+func (s *HighPerformanceServer) handleResumeUploads(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	atomic.StoreInt32(&s.uploadsDisabled, 0)
+	s.collectionProcessor.Unhalt()
+	
+	log.Println("✅ Uploads re-enabled")
+	
+	// Collections paused by the emergency stop continue where they stopped
+	resumed := make([]string, 0)
+	for _, saved := range s.collectionProcessor.ResumableJobs() {
+		if saved.Status != collection.StatusPaused {
+			continue // interrupted by a restart: left to --resume / resume_collection
 		}
-		return conn.Send(response)
+		if err := s.resubmitCollection(saved); err != nil {
+			log.Printf("⚠️ Failed to resume collection %s: %v", saved.ID, err)
+			continue
+		}
+		resumed = append(resumed, saved.ID)
+		log.Printf("Resumed paused collection %s (%s)", saved.Name, saved.ID)
+	}
+	
+	s.wsManager.Broadcast(wsmanager.Response{
+		Status:    "uploads_resumed",
+		RequestID: msg.RequestID,
+		Data: map[string]interface{}{
+			"resumedCollections": resumed,
+			"timestamp":          time.Now(),
+		},
+	})
+	
+	return nil
+}
+
+// handleJSONGeneration processes individual JSON generation for manga uploads
+func (s *HighPerformanceServer) handleJSONGeneration(conn *wsmanager.Connection, req WebSocketRequest, batchID string) {
+	log.Printf("Starting JSON generation for batch %s with %d manga(s)", batchID, len(req.MangaList))
+	
+	// Wait a bit for uploads to start
+	s.clock.Sleep(2 * time.Second)
+	
+	// Monitor batch progress and generate JSONs when uploads complete
+	ticker := s.clock.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	
+	processedMangas := make(map[string]bool)
+	uploadResults := make(map[string][]metadata.UploadedFile)
+	
+	for {
+		select {
+		case <-ticker.C():
+			// Check batch status
+			batchProgress, err := s.batchUploader.GetBatchStatus(batchID)
+			if err != nil {
+				log.Printf("Error getting batch status: %v", err)
+				continue
+			}
+			
+			// Get real upload results from captured data
+			s.getUploadResults(batchID, uploadResults)
+			
+			// Process JSONs for completed uploads
+			for _, mangaID := range req.MangaList {
+				if processedMangas[mangaID] {
+					continue
+				}
+				
+				// Check if this manga has uploaded files
+				if files, exists := uploadResults[mangaID]; exists && len(files) > 0 {
+					if err := s.generateMangaJSON(conn, mangaID, files, req); err != nil {
+						log.Printf("Error generating JSON for manga %s: %v", mangaID, err)
+						// Send error notification
+						s.sendJSONError(conn, mangaID, err)
+					} else {
+						processedMangas[mangaID] = true
+					}
+				}
+			}
+			
+			// Check if batch is complete
+			if batchProgress.Completed+batchProgress.Failed >= batchProgress.Total {
+				log.Printf("Batch %s completed, finishing JSON generation", batchID)
+				return
+			}
+			
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// generateMangaJSON generates JSON for a specific manga
+func (s *HighPerformanceServer) generateMangaJSON(conn *wsmanager.Connection, mangaID string, uploadedFiles []metadata.UploadedFile, req WebSocketRequest) error {
+	// Get manga metadata from files
+	var mangaTitle string
+	for _, file := range uploadedFiles {
+		if file.MangaID == mangaID {
+			mangaTitle = file.MangaTitle
+			break
+		}
+	}
+	
+	// Create manga metadata (in real implementation, this would come from a database or discovery)
+	mangaMetadata := metadata.MangaMetadata{
+		ID:          mangaID,
+		Title:       mangaTitle,
+		Description: fmt.Sprintf("Descrição da obra %s", mangaTitle),
+		Artist:      "Artista Desconhecido",
+		Author:      "Autor Desconhecido", 
+		Cover:       fmt.Sprintf("https://placehold.co/200x300/1f2937/9ca3af?text=%s", mangaTitle),
+		Status:      "Em Andamento",
+	}
+	
+	metadataMap := map[string]metadata.MangaMetadata{
+		mangaID: mangaMetadata,
+	}
+	
+	// Send JSON generation start notification
+	s.sendJSONProgress(conn, "json_generated", mangaID, mangaTitle, "")
+	
+	// Check if JSON already exists (use mangaID as unique identifier)
+	expectedJSONPath := mangaid.Path(s.config.MetadataOutput, mangaID)
+	
+	// The read of the current JSON and the rewrite hold the metadata lock, so a
+	// concurrent save_metadata or auto-fill cannot drop the new chapters (or vice versa)
+	s.metadataMu.Lock()
+	jsonPaths, knownChapters, err := s.writeMangaJSON(mangaID, expectedJSONPath, uploadedFiles, metadataMap, req)
+	s.metadataMu.Unlock()
+	if err != nil {
+		return err
+	}
+	
+	// Send completion notification
+	for _, jsonPath := range jsonPaths {
+		if s.telegraph != nil {
+			s.publishTelegraphPages(mangaID, jsonPath, uploadedFiles)
+		}
+		s.sendJSONProgress(conn, "json_complete", mangaID, mangaTitle, jsonPath)
+		log.Printf("JSON processing complete for manga %s at %s", mangaID, jsonPath)
+		
+		if s.hooks.Has(hooks.AfterJSON) {
+			event := hooks.Event{Stage: hooks.AfterJSON, Manga: mangaTitle, JSONPath: jsonPath}
+			if content, err := os.ReadFile(jsonPath); err == nil && json.Valid(content) {
+				event.JSON = content
+			}
+			go s.runAfterHook(event)
+		}
+		if knownChapters != nil {
+			go s.notifyMangaWebhook(mangaID, jsonPath, knownChapters, nil)
+		}
+	}
+	
+	return nil
+}
+
+// writeMangaJSON updates the manga JSON with the uploaded files, or creates it when missing.
+// It returns the written paths and, for the manga webhook, the chapters known before the write.
+// The caller holds metadataMu.
+func (s *HighPerformanceServer) writeMangaJSON(mangaID, expectedJSONPath string, uploadedFiles []metadata.UploadedFile, metadataMap map[string]metadata.MangaMetadata, req WebSocketRequest) ([]string, map[string]bool, error) {
+	// Chapters missing from the JSON before this write are new for the manga webhook.
+	// With the review workflow the webhook fires when approved chapters are synced instead.
+	var knownChapters map[string]bool
+	if s.mangaWebhooks.Has(mangaID) && !s.config.reviewEnabled() {
+		if content, err := os.ReadFile(expectedJSONPath); err == nil {
+			knownChapters = webhooks.ChapterKeys(content)
+		} else if os.IsNotExist(err) {
+			knownChapters = map[string]bool{}
+		}
+	}
+	
+	var jsonPaths []string
+	
+	if _, statErr := os.Stat(expectedJSONPath); statErr == nil {
+		// JSON exists - use update mode from request or default to smart
+		updateMode := req.UpdateMode
+		if updateMode == "" {
+			updateMode = "smart" // Default mode
+		}
+		
+		// Passar metadados opcionais se disponível para preservar informações base
+		if mangaMetadata, exists := metadataMap[mangaID]; exists {
+			if err := s.jsonGenerator.UpdateExistingJSON(expectedJSONPath, uploadedFiles, updateMode, mangaMetadata); err != nil {
+				return nil, nil, wsmanager.Errorf(wsmanager.ErrJSONConflict, "failed to update existing JSON: %v", err)
+			}
+		} else {
+			// Sem metadados - apenas atualizar capítulos
+			if err := s.jsonGenerator.UpdateExistingJSON(expectedJSONPath, uploadedFiles, updateMode); err != nil {
+				return nil, nil, wsmanager.Errorf(wsmanager.ErrJSONConflict, "failed to update existing JSON: %v", err)
+			}
+		}
+		
+		jsonPaths = []string{expectedJSONPath}
+		log.Printf("Updated existing JSON for manga %s at %s using mode: %s", mangaID, expectedJSONPath, updateMode)
+	} else {
+		// JSON doesn't exist - create new one
+		var err error
+		jsonPaths, err = s.jsonGenerator.GenerateIndividualJSONs(uploadedFiles, metadataMap)
+		if err != nil {
+			return nil, nil, wsmanager.Errorf(wsmanager.ErrIO, "failed to generate JSON: %v", err)
+		}
+		log.Printf("Generated new JSON for manga %s", mangaID)
+	}
+	
+	return jsonPaths, knownChapters, nil
+}
+
+// publishTelegraphPages creates (or updates) the telegra.ph page of each uploaded chapter
+// and stores its link in the JSON as the "<group> (telegra.ph)" group. The page shows the
+// group's Telegraph mirror when it has every page, the group's own images otherwise.
+// Chapters still in review are left out; failures only keep the JSON without the page.
+func (s *HighPerformanceServer) publishTelegraphPages(mangaID, jsonPath string, uploadedFiles []metadata.UploadedFile) {
+	raw, err := os.ReadFile(jsonPath)
+	if err != nil {
+		log.Printf("⚠️ Telegraph pages for %s skipped: %v", mangaID, err)
+		return
+	}
+	var manga metadata.MangaJSON
+	if err := json.Unmarshal(raw, &manga); err != nil {
+		log.Printf("⚠️ Telegraph pages for %s skipped: invalid JSON: %v", mangaID, err)
+		return
+	}
+	
+	chapters := make(map[string]bool)
+	for _, file := range uploadedFiles {
+		if file.MangaID != mangaID {
+			continue
+		}
+		key := metadata.ChapterKey(file.ChapterID)
+		if !s.config.reviewEnabled() || s.idRegistry.ChapterPublishable(mangaID, key) {
+			chapters[key] = true
+		}
+	}
+	
+	title := manga.Title
+	if title == "" {
+		title = mangaID
+	}
+	pages := make(map[string]map[string][]string)
+	published := 0
+	for key := range chapters {
+		chapter, exists := manga.Chapters[key]
+		if !exists {
+			continue
+		}
+		for group, images := range chapter.Groups {
+			if _, _, mirror := metadata.ParseMirrorGroup(group); mirror || len(images) == 0 {
+				continue
+			}
+			if mirrored := chapter.Groups[metadata.MirrorGroupName(group, "telegraph")]; len(mirrored) == len(images) {
+				images = mirrored
+			}
+			pageGroup := metadata.MirrorGroupName(group, metadata.TelegraphHost)
+			var path string
+			if existing := chapter.Groups[pageGroup]; len(existing) > 0 {
+				path = uploaders.TelegraphPagePath(existing[0])
+			}
+			pageTitle := fmt.Sprintf("%s — Capítulo %s", title, key)
+			if chapter.Title != "" {
+				pageTitle += " — " + chapter.Title
+			}
+			pageURL, err := s.telegraph.PublishPage(s.ctx, path, pageTitle, images)
+			if err != nil && path != "" {
+				// Page of another account or deleted: publish a new one
+				pageURL, err = s.telegraph.PublishPage(s.ctx, "", pageTitle, images)
+			}
+			if err != nil {
+				log.Printf("⚠️ Telegraph page for %s chapter %s (%s) failed: %v", mangaID, key, group, err)
+				continue
+			}
+			if pages[key] == nil {
+				pages[key] = make(map[string][]string)
+			}
+			pages[key][pageGroup] = []string{pageURL}
+			published++
+		}
+	}
+	if len(pages) == 0 {
+		return
+	}
+	s.metadataMu.Lock()
+	_, err = s.jsonGenerator.SetChapterGroups(jsonPath, pages)
+	s.metadataMu.Unlock()
+	if err != nil {
+		log.Printf("⚠️ Failed to store Telegraph pages of %s: %v", mangaID, err)
+		return
+	}
+	log.Printf("📰 Published %d Telegraph page(s) for %s", published, mangaID)
+}
+
+// notifyMangaWebhook sends the manga webhook the given chapters or, with known set,
+// the chapters of the JSON that are not in known
+func (s *HighPerformanceServer) notifyMangaWebhook(mangaID, jsonPath string, known map[string]bool, chapters []string) {
+	content, err := os.ReadFile(jsonPath)
+	if err != nil {
+		log.Printf("⚠️ Manga webhook for %s skipped: %v", mangaID, err)
+		return
+	}
+	if known != nil {
+		chapters = webhooks.NewChapters(known, content)
+	}
+	if len(chapters) == 0 {
+		return
+	}
+	if err := s.mangaWebhooks.Publish(s.ctx, mangaID, content, chapters); err != nil {
+		log.Printf("⚠️ Manga webhook failed for %s: %v", mangaID, err)
+		return
+	}
+	log.Printf("🔔 Manga webhook notified of %s chapter(s) %s", mangaID, strings.Join(chapters, ", "))
+}
+
+// getUploadResults retrieves real upload results from captured data
+func (s *HighPerformanceServer) getUploadResults(batchID string, uploadResults map[string][]metadata.UploadedFile) {
+	// Get real results for this batch
+	if realResults := s.batchUploadResults(batchID); len(realResults) > 0 {
+		for _, uploadedFile := range realResults {
+			uploadResults[uploadedFile.MangaID] = append(uploadResults[uploadedFile.MangaID], uploadedFile)
+		}
+		log.Printf("Retrieved %d real upload results for batch %s", len(realResults), batchID)
+	}
+}
+
+// batchUploadResults returns the successful uploads of a batch, read from the
+// result spool in low-memory mode
+func (s *HighPerformanceServer) batchUploadResults(batchID string) []metadata.UploadedFile {
+	s.uploadResultsMu.RLock()
+	defer s.uploadResultsMu.RUnlock()
+	
+	if s.resultSpool != nil {
+		files, err := s.resultSpool.Load(batchID)
+		if err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+		return files
+	}
+	return append([]metadata.UploadedFile(nil), s.uploadResults[batchID]...)
+}
+
+// sendJSONProgress sends JSON progress notifications
+func (s *HighPerformanceServer) sendJSONProgress(conn *wsmanager.Connection, status, mangaID, mangaTitle, jsonPath string) {
+	response := wsmanager.Response{
+		Status:    status,
+		MangaID:   mangaID,
+		MangaTitle: mangaTitle,
+		JSONPath:  jsonPath,
+	}
+	
+	conn.Send(response)
+}
+
+// sendJSONError sends JSON error notifications
+func (s *HighPerformanceServer) sendJSONError(conn *wsmanager.Connection, mangaID string, err error) {
+	response := wsmanager.Response{
+		Status:    "json_error",
+		MangaID:   mangaID,
+		Error:     err.Error(),
+		ErrorCode: wsmanager.CodeOf(err),
+	}
+	
+	conn.Send(response)
+}
+
+// handleUploadResult captures real upload results for JSON generation
+func (s *HighPerformanceServer) handleUploadResult(batchID string, result upload.UploadResult) {
+	// Feeds the observed throughput used by the tuner
+	s.monitor.RecordUpload(result.Error == nil, result.Duration, result.Size)
+	s.monitor.RecordHostUpload(result.Host, result.Size, result.Error == nil)
+	
+	if result.Error != nil {
+		// Skip failed uploads
+		return
+	}
+	
+	s.uploadResultsMu.Lock()
+	defer s.uploadResultsMu.Unlock()
+	
+	// Extract manga information from result ID (format: file_{mangaID}_{chapter}_{timestamp})
+	parts := strings.Split(result.ID, "_")
+	if len(parts) < 3 {
+		log.Printf("Invalid upload result ID format: %s", result.ID)
+		return
+	}
+	
+	mangaID := parts[1]
+	chapterID := parts[2]
+	
+	// Get manga title from stored batch info
+	var mangaTitle string
+	if batchTitles, exists := s.batchMangaTitles[batchID]; exists {
+		mangaTitle = batchTitles[mangaID]
+	}
+	
+	// Create uploaded file entry with real URL and page index
+	uploadedFile := metadata.UploadedFile{
+		MangaID:    mangaID,
+		MangaTitle: mangaTitle,
+		ChapterID:  chapterID,
+		FileName:   result.FileName,
+		URL:        result.URL, // Real URL from upload
+		PageIndex:  s.extractPageIndexFromFileName(result.FileName),
+		Mirrors:    result.Mirrors,
+		Language:   result.Language,
+	}
+	
+	// Store result by batchID (on disk in low-memory mode)
+	if s.resultSpool != nil {
+		if err := s.resultSpool.Append(batchID, uploadedFile); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+	} else {
+		s.uploadResults[batchID] = append(s.uploadResults[batchID], uploadedFile)
+		s.uploadResultsTouched[batchID] = time.Now()
+	}
+	
+	record := analytics.Record{
+		MangaID: mangaid.Normalize(mangaID),
+		Chapter: chapterID,
+		Group:   metadata.LanguageGroupName(s.jsonGenerator.GroupName(), result.Language),
+		Host:    result.Host,
+		Bytes:   result.Size,
+		URL:     result.URL,
+		File:    result.FileName,
+		SHA256:  result.SHA256,
+	}
+	setRecordReceipt(&record, result.Receipt)
+	if s.deferredBatches[batchID] {
+		record.Unpublished = true
+		record.Page = uploadedFile.PageIndex
+		record.Title = mangaTitle
+	}
+	records := []analytics.Record{record}
+	for host, url := range result.Mirrors {
+		mirror := record
+		mirror.Group = metadata.MirrorGroupName(record.Group, host)
+		mirror.Host = host
+		mirror.URL = url
+		setRecordReceipt(&mirror, result.Receipts[host])
+		records = append(records, mirror)
+	}
+	if err := s.uploadHistory.Append(records...); err != nil {
+		log.Printf("⚠️ Failed to record upload history: %v", err)
+	}
+	s.markChapterUploaded(record.MangaID, mangaTitle, chapterID)
+	s.checksums.Track(batchID, checksums.Chapter{MangaID: record.MangaID, Chapter: chapterID, Dir: s.libraryChapterDir(record.MangaID, mangaTitle, chapterID)})
+	
+	log.Printf("Captured real upload result: %s -> %s (page %d)", result.FileName, result.URL, uploadedFile.PageIndex)
+}
+
+// recordCollectionUpload adds a file uploaded by a collection to the upload history
+func (s *HighPerformanceServer) recordCollectionUpload(job *collection.CollectionJob, obra *collection.ObraJob, chapter *collection.ChapterJob, file *collection.FileJob) {
+	s.monitor.RecordHostUpload(job.Host, file.Size, true)
+	record := analytics.Record{
+		MangaID: mangaid.Normalize(obra.Name),
+		Chapter: chapter.Name,
+		Group:   s.jsonGenerator.GroupName(),
+		Host:    job.Host,
+		Bytes:   file.Size,
+		URL:     file.URL,
+		File:    file.Name,
+		SHA256:  file.SHA256,
+	}
+	setRecordReceipt(&record, file.Receipt)
+	if err := s.uploadHistory.Append(record); err != nil {
+		log.Printf("⚠️ Failed to record upload history: %v", err)
+	}
+	s.markChapterUploaded(mangaid.Normalize(obra.Name), obra.Name, chapter.Name)
+	s.checksums.Track(job.ID, checksums.Chapter{MangaID: mangaid.Normalize(obra.Name), Chapter: chapter.Name, Dir: chapter.Path})
+}
+
+// setRecordReceipt copies the host-side identifiers of an upload (file ID, delete
+// token, object version) into its history record
+func setRecordReceipt(record *analytics.Record, receipt *upload.Receipt) {
+	if receipt == nil {
+		return
+	}
+	record.HostFileID = receipt.ID
+	record.DeleteToken = receipt.DeleteToken
+	record.DeleteURL = receipt.DeleteURL
+	record.VersionID = receipt.VersionID
+}
+
+// libraryChapterDir returns the library folder of an uploaded chapter, looked up
+// by series title and ID ("" when the upload did not come from the library)
+func (s *HighPerformanceServer) libraryChapterDir(mangaID, mangaTitle, chapterID string) string {
+	for _, series := range []string{mangaTitle, mangaID} {
+		if series == "" {
+			continue
+		}
+		dir := filepath.Join(s.config.LibraryRoot, series, chapterID)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return ""
+}
+
+// writeChecksums writes the checksums.json of the library chapters uploaded by a
+// batch or collection once it is over
+func (s *HighPerformanceServer) writeChecksums(jobID string) {
+	written, err := s.checksums.Flush(jobID)
+	if err != nil {
+		log.Printf("⚠️ Failed to write checksums of %s: %v", jobID, err)
+	}
+	if len(written) > 0 {
+		log.Printf("🔏 Wrote %s for %d chapter(s) of %s", checksums.FileName, len(written), jobID)
+	}
+}
+
+// addChecksumFiles sends the checksums manifests of works along with a GitHub
+// sync; chapters withheld from the JSONs stay out
+func (s *HighPerformanceServer) addChecksumFiles(options *github.SyncOptions, works []string) {
+	mangaIDs := make([]string, 0, len(works))
+	for _, work := range works {
+		mangaIDs = append(mangaIDs, mangaid.Normalize(work))
+	}
+	files, err := s.checksums.GitHubFiles(mangaIDs, func(mangaID, chapter string) bool {
+		return !s.config.reviewEnabled() || s.idRegistry.ChapterPublishable(mangaID, chapter)
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to build checksums for GitHub: %v", err)
+		return
+	}
+	if len(files) == 0 {
+		return
+	}
+	if options.ExtraFiles == nil {
+		options.ExtraFiles = make(map[string]string)
+	}
+	for name, content := range files {
+		options.ExtraFiles[name] = content
+	}
+}
+
+// signJSONFiles signs the series JSONs of a GitHub sync: embedded signatures
+// replace the file contents, detached ones travel as <file>.sig extra files
+func (s *HighPerformanceServer) signJSONFiles(options *github.SyncOptions, jsonFiles map[string]string) error {
+	signatures, err := s.signer.SignFiles(jsonFiles)
+	if err != nil {
+		return err
+	}
+	if len(signatures) == 0 {
+		return nil
+	}
+	if options.ExtraFiles == nil {
+		options.ExtraFiles = make(map[string]string)
+	}
+	for name, signature := range signatures {
+		options.ExtraFiles[name] = signature
+	}
+	return nil
+}
+
+// markChapterUploaded puts an uploaded chapter at the start of the review workflow
+func (s *HighPerformanceServer) markChapterUploaded(mangaID, title, chapterID string) {
+	if !s.config.reviewEnabled() {
+		return
+	}
+	if _, err := s.idRegistry.MarkChapterUploaded(mangaID, title, metadata.ChapterKey(chapterID), ""); err != nil {
+		log.Printf("⚠️ Failed to record review status of %s chapter %s: %v", mangaID, chapterID, err)
+	}
+}
+
+// ChapterRejection is a chapter left out of a batch by a before_chapter_upload hook
+type ChapterRejection struct {
+	Manga   string `json:"manga"`
+	Chapter string `json:"chapter"`
+	Files   int    `json:"files"`
+	Error   string `json:"error"`
+}
+
+// runHook runs the hooks of a pipeline stage; failures of ignoreErrors hooks are only logged
+func (s *HighPerformanceServer) runHook(event hooks.Event) error {
+	err := s.hooks.Run(s.ctx, event)
+	if err != nil && !hooks.Blocking(err) {
+		log.Printf("⚠️ Ignored %s hook failure: %v", event.Stage, err)
+		return nil
+	}
+	return err
+}
+
+// runAfterHook runs after_* hooks, which can no longer stop their stage
+func (s *HighPerformanceServer) runAfterHook(event hooks.Event) {
+	if err := s.runHook(event); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+}
+
+// filterChaptersByHook runs before_chapter_upload for each chapter of a batch and
+// drops the uploads of the chapters a hook rejected
+func (s *HighPerformanceServer) filterChaptersByHook(batchID, host string, uploads []upload.UploadRequest) ([]upload.UploadRequest, []ChapterRejection) {
+	if !s.hooks.Has(hooks.BeforeChapterUpload) {
+		return uploads, nil
+	}
+	
+	type chapterKey struct{ manga, chapter string }
+	var order []chapterKey
+	chapters := make(map[chapterKey][]upload.UploadRequest)
+	for _, up := range uploads {
+		key := chapterKey{up.Manga, up.Chapter}
+		if _, exists := chapters[key]; !exists {
+			order = append(order, key)
+		}
+		chapters[key] = append(chapters[key], up)
+	}
+	
+	var accepted []upload.UploadRequest
+	var rejected []ChapterRejection
+	for _, key := range order {
+		files := chapters[key]
+		event := hooks.Event{
+			Stage:   hooks.BeforeChapterUpload,
+			Manga:   key.manga,
+			Chapter: key.chapter,
+			Host:    host,
+			JobID:   batchID,
+		}
+		for _, up := range files {
+			event.Files = append(event.Files, up.FileName)
+			if event.Path == "" && up.FilePath != "" {
+				event.Path = filepath.Dir(up.FilePath)
+			}
+		}
+		if err := s.runHook(event); err != nil {
+			log.Printf("⚠️ Chapter %s/%s rejected: %v", key.manga, key.chapter, err)
+			s.jobLogs.Add(batchID, joblog.Warn, "chapter %s/%s rejected: %v", key.manga, key.chapter, err)
+			rejected = append(rejected, ChapterRejection{Manga: key.manga, Chapter: key.chapter, Files: len(files), Error: err.Error()})
+			continue
+		}
+		accepted = append(accepted, files...)
+	}
+	return accepted, rejected
+}
+
+// beforeCollectionChapter runs before_chapter_upload for a chapter of a collection
+func (s *HighPerformanceServer) beforeCollectionChapter(job *collection.CollectionJob, obra *collection.ObraJob, chapter *collection.ChapterJob) error {
+	if !s.hooks.Has(hooks.BeforeChapterUpload) {
+		return nil
+	}
+	files := make([]string, 0, len(chapter.Files))
+	for _, file := range chapter.Files {
+		files = append(files, file.Name)
+	}
+	return s.runHook(hooks.Event{
+		Stage:   hooks.BeforeChapterUpload,
+		Manga:   obra.Name,
+		Chapter: chapter.Name,
+		Path:    chapter.Path,
+		Host:    job.Host,
+		JobID:   job.ID,
+		Files:   files,
+	})
+}
+
+// optimizeByDefault reports whether every batch and collection gets the lossless optimization stage
+func (s *HighPerformanceServer) optimizeByDefault() bool {
+	return s.config.Optimize != nil && s.config.Optimize.Enabled
+}
+
+// applyUploadPolicy checks the files of a batch against the content policy and
+// drops the rejected ones; flagged files stay in the batch
+func (s *HighPerformanceServer) applyUploadPolicy(batchID string, uploads []upload.UploadRequest) ([]upload.UploadRequest, *policy.Report) {
+	report := s.contentPolicy.NewReport()
+	if !s.contentPolicy.Enabled() {
+		return uploads, report
+	}
+	
+	accepted := make([]upload.UploadRequest, 0, len(uploads))
+	for _, up := range uploads {
+		name := filepath.Join(up.Manga, up.Chapter, up.FileName)
+		var violations []policy.Violation
+		if up.FilePath != "" {
+			violations = s.contentPolicy.CheckFile(up.FilePath, name)
+		} else if data, err := base64.StdEncoding.DecodeString(up.FileContent); err == nil && len(data) > 0 {
+			violations = s.contentPolicy.CheckContent(name, data)
+		} else {
+			// Content streamed later or invalid base64 (the upload itself reports it)
+			accepted = append(accepted, up)
+			continue
+		}
+		
+		s.contentPolicy.Record(report, violations)
+		if len(violations) > 0 {
+			s.jobLogs.Add(batchID, joblog.Warn, "%s: content policy (%s): %s", name, report.Action, policy.Summary(violations))
+		}
+		if !s.contentPolicy.Rejects(violations) {
+			accepted = append(accepted, up)
+		}
+	}
+	if report.HasViolations() {
+		log.Printf("⚠️ Batch %s: %d file(s) rejected and %d flagged by the content policy", batchID, report.Rejected, report.Flagged)
+	}
+	return accepted, report
+}
+
+// skipDuplicatePages drops the pages of a batch whose perceptual hash is identical
+// to an earlier page of the same chapter (scans included twice)
+func (s *HighPerformanceServer) skipDuplicatePages(batchID string, uploads []upload.UploadRequest) ([]upload.UploadRequest, []phash.Duplicate) {
+	var paths []string
+	for _, up := range uploads {
+		if up.FilePath != "" {
+			paths = append(paths, up.FilePath)
+		}
+	}
+	fileHashes := phash.Files(paths, 0)
+	
+	var pages []phash.Page
+	indexes := make(map[string]int)
+	for i, up := range uploads {
+		hash, ok := fileHashes[up.FilePath]
+		if up.FilePath == "" {
+			// Content streamed later or invalid base64 is never treated as a duplicate
+			data, err := base64.StdEncoding.DecodeString(up.FileContent)
+			if err != nil || len(data) == 0 {
+				continue
+			}
+			hash, err = phash.Data(data)
+			ok = err == nil
+		}
+		if !ok {
+			continue
+		}
+		name := filepath.Join(up.Manga, up.Chapter, up.FileName)
+		pages = append(pages, phash.Page{Chapter: filepath.Join(up.Manga, up.Chapter), File: name, Hash: hash})
+		indexes[name] = i
+	}
+	
+	var skipped []phash.Duplicate
+	drop := make(map[int]bool)
+	for _, duplicate := range phash.Find(pages, 0) {
+		if duplicate.Scope != phash.ScopeChapter {
+			continue
+		}
+		skipped = append(skipped, duplicate)
+		drop[indexes[duplicate.File]] = true
+		s.jobLogs.Add(batchID, joblog.Info, "%s skipped: same page as %s", duplicate.File, duplicate.Original)
+	}
+	if len(skipped) == 0 {
+		return uploads, nil
+	}
+	
+	kept := make([]upload.UploadRequest, 0, len(uploads)-len(drop))
+	for i, up := range uploads {
+		if !drop[i] {
+			kept = append(kept, up)
+		}
+	}
+	log.Printf("🔁 Batch %s: %d duplicate page(s) skipped", batchID, len(skipped))
+	return kept, skipped
+}
+
+// checkCollectionFile applies the content policy to a file of a collection;
+// flagged files are only logged
+func (s *HighPerformanceServer) checkCollectionFile(job *collection.CollectionJob, obra *collection.ObraJob, chapter *collection.ChapterJob, file *collection.FileJob) error {
+	violations := s.contentPolicy.CheckFile(file.Path, filepath.Join(obra.Name, chapter.Name, file.Name))
+	if len(violations) == 0 {
+		return nil
+	}
+	if !s.contentPolicy.Rejects(violations) {
+		s.jobLogs.Add(job.ID, joblog.Warn, "%s/%s/%s: flagged by content policy: %s", obra.Name, chapter.Name, file.Name, policy.Summary(violations))
+		return nil
+	}
+	return fmt.Errorf("content policy: %s", policy.Summary(violations))
+}
+
+// extractPageIndexFromFileName extrai o índice da página do nome do arquivo
+func (s *HighPerformanceServer) extractPageIndexFromFileName(fileName string) int {
+	// Usar a mesma lógica do JSONGenerator
+	jsonGen := metadata.NewJSONGenerator("", "")
+	return jsonGen.ExtractPageIndex(fileName)
+}
+
+// connLocale returns the message locale configured for a connection
+func connLocale(conn *wsmanager.Connection) i18n.Locale {
+	return i18n.ParseLocale(conn.Locale())
+}
+
+// handleSetLocale changes the language of messages sent to this connection
+func (s *HighPerformanceServer) handleSetLocale(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid set locale request: %v", err)
+	}
+	
+	if req.Locale == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "locale"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	locale := i18n.ParseLocale(req.Locale)
+	conn.SetLocale(string(locale))
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "locale_changed",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"locale":    locale,
+			"message":   i18n.T(locale, i18n.MsgLocaleChanged),
+			"supported": i18n.SupportedLocales(),
+		},
+	})
+}
+
+// handleSetEncoding switches the responses of this connection between JSON text
+// frames and MessagePack binary frames. Requests are always JSON.
+func (s *HighPerformanceServer) handleSetEncoding(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid set encoding request: %v", err)
+	}
+	
+	encoding, err := wsmanager.ParseEncoding(req.Encoding)
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgUnsupportedEncoding, req.Encoding),
+			ErrorCode: wsmanager.ErrInvalidRequest,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	// The confirmation already goes out in the new encoding
+	conn.SetEncoding(encoding)
+	return conn.Send(wsmanager.Response{
+		Status:    "encoding_changed",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"encoding":  encoding,
+			"supported": []string{wsmanager.EncodingJSON, wsmanager.EncodingMsgpack},
+		},
+	})
+}
+
+// handleGetTuning reports the measured resources, the recommended worker counts
+// and the values in effect
+func (s *HighPerformanceServer) handleGetTuning(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	resources, recommendation := s.tuner.Last()
+	return conn.Send(wsmanager.Response{
+		Status:    "tuning",
+		RequestID: msg.RequestID,
+		Data: map[string]interface{}{
+			"resources":      resources,
+			"recommendation": recommendation,
+			"autoTuned":      s.autoTuned,
+			"current": map[string]interface{}{
+				"maxWorkers":        s.config.MaxWorkers,
+				"discoveryWorkers":  s.config.DiscoveryWorkers,
+				"uploadConcurrency": s.uploadConcurrency(),
+			},
+		},
+	})
+}
+
+// handleTuningChange follows a new recommendation: auto-tuned servers adjust the
+// concurrency of new batches (pools keep their startup size, so only downwards),
+// configured ones just log the suggestion
+func (s *HighPerformanceServer) handleTuningChange(resources tuning.Resources, recommendation tuning.Recommendation) {
+	if !s.autoTuned {
+		if recommendation.MaxWorkers < s.config.MaxWorkers {
+			log.Printf("💡 Suggested maxWorkers: %d (configured: %d; %s)", recommendation.MaxWorkers, s.config.MaxWorkers, strings.Join(recommendation.Reasons, "; "))
+		}
+		return
+	}
+	limit := min(recommendation.MaxWorkers, s.config.MaxWorkers)
+	if previous := atomic.SwapInt32(&s.uploadLimit, int32(limit)); int(previous) != limit {
+		log.Printf("⚙️ Upload concurrency adjusted to %d (%s)", limit, strings.Join(recommendation.Reasons, "; "))
+	}
+}
+
+// uploadConcurrency is the default concurrency of new batches and collections
+func (s *HighPerformanceServer) uploadConcurrency() int {
+	return int(atomic.LoadInt32(&s.uploadLimit))
+}
+
+// handleGetMetrics returns current system metrics
+func (s *HighPerformanceServer) handleGetMetrics(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid metrics request: %v", err)
+	}
+	
+	query := monitoring.Query{Sections: req.Sections, Fields: req.Fields, Collections: req.Collections}
+	var err error
+	if query.From, err = monitoring.ParseTime(req.From, time.Now()); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "%v", err)
+	}
+	if query.To, err = monitoring.ParseTime(req.To, time.Now()); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "%v", err)
+	}
+	
+	data, err := s.monitor.Query(query, defaultMetricsSections, s.metricsExtras())
+	if err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "%v", err)
+	}
+	
+	response := wsmanager.Response{
+		Status:    "metrics",
+		RequestID: msg.RequestID,
+		Data:      data,
+	}
+	
+	return conn.Send(response)
+}
+
+// defaultMetricsSections is what get_metrics returns when no sections or fields are requested
+var defaultMetricsSections = []string{monitoring.SectionMetrics, monitoring.SectionPerformance, "connections", "retries", monitoring.SectionHosts}
+
+// metricsExtras are the metrics sections owned by the server rather than the monitor
+func (s *HighPerformanceServer) metricsExtras() map[string]monitoring.Extra {
+	return map[string]monitoring.Extra{
+		"connections": func() interface{} { return s.wsManager.GetConnectionCount() },
+		"retries":     func() interface{} { return retry.Snapshot() },
+	}
+}
+
+// handleGetStatus returns server status information
+func (s *HighPerformanceServer) handleGetStatus(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	response := wsmanager.Response{
+		Status:    "status",
+		RequestID: msg.RequestID,
+		Data: map[string]interface{}{
+			"server":      "high-performance-manga-uploader",
+			"version":     version,
+			"uptime":      time.Since(startTime).String(),
+			"connections": s.wsManager.GetConnectionCount(),
+			"config":      s.config,
+			"paths":       s.paths,
+			"uploadsHalted": s.uploadsHalted(),
+			"hostBreakers":  s.batchUploader.HostBreakers(),
+		},
+	}
+	if inventory, err := s.collectionProcessor.StateInventory(false); err == nil {
+		response.Data.(map[string]interface{})["stateFiles"] = inventory
+	}
+	
+	return conn.Send(response)
+}
+
+// handleProcessCollection processa uma coleção completa de mangás
+func (s *HighPerformanceServer) handleProcessCollection(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	if s.uploadsHalted() {
+		return s.sendUploadsDisabled(conn, msg.RequestID)
+	}
+	
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid process collection request: %v", err)
+	}
+	
+	// Valida parâmetros obrigatórios
+	if req.CollectionName == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "collectionName"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	if req.BasePath == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error", 
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "basePath"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	if req.Host == "" {
+		req.Host = "catbox" // Default
+	}
+	
+	// Gera ID único se não fornecido
+	if req.CollectionID == "" {
+		req.CollectionID = fmt.Sprintf("collection_%d", time.Now().UnixNano())
+	}
+	
+	// Configura opções de processamento
+	processorOptions := &collection.ProcessorConfig{
+		MaxConcurrency:    req.ParallelLimit,
+		BatchSize:         s.config.collectionBatchSize(),
+		RetryAttempts:     3,
+		RetryDelay:        2 * time.Second,
+		ProgressInterval:  2 * time.Second,
+		EnablePersistence: true,
+		StateFilePath:     s.paths.CollectionState,
+		KeepMetadata:      s.config.KeepImageMetadata,
+		Optimize:          s.optimizeByDefault(),
+		IncludeHidden:     s.config.IncludeHiddenFiles,
+	}
+	
+	if req.CollectionOptions != nil {
+		if req.CollectionOptions.MaxConcurrency > 0 {
+			processorOptions.MaxConcurrency = req.CollectionOptions.MaxConcurrency
+		}
+		if req.CollectionOptions.BatchSize > 0 {
+			processorOptions.BatchSize = req.CollectionOptions.BatchSize
+		}
+		if req.CollectionOptions.RetryAttempts > 0 {
+			processorOptions.RetryAttempts = req.CollectionOptions.RetryAttempts
+		}
+		processorOptions.RetryBudget = req.CollectionOptions.RetryBudget
+		processorOptions.EnablePersistence = req.CollectionOptions.EnablePersistence
+		
+		if req.CollectionOptions.ResumeFrom != "" {
+			processorOptions.ResumeFrom = req.CollectionOptions.ResumeFrom
+		}
+		processorOptions.SkipExisting = req.CollectionOptions.SkipExisting
+		if req.CollectionOptions.KeepMetadata {
+			processorOptions.KeepMetadata = true
+		}
+		if req.CollectionOptions.Optimize {
+			processorOptions.Optimize = true
+		}
+		processorOptions.SkipDuplicatePages = req.CollectionOptions.SkipDuplicatePages
+		processorOptions.AutoRemovePages = req.CollectionOptions.AutoRemovePages
+		if req.CollectionOptions.IncludeHidden {
+			processorOptions.IncludeHidden = true
+		}
+		
+		if req.CollectionOptions.MaxWorkers > 0 || req.CollectionOptions.MaxBandwidthBPS > 0 || req.CollectionOptions.MaxSpoolBytes > 0 {
+			processorOptions.Budget = &collection.ResourceBudget{
+				MaxWorkers:      req.CollectionOptions.MaxWorkers,
+				MaxBandwidthBPS: req.CollectionOptions.MaxBandwidthBPS,
+				MaxSpoolBytes:   req.CollectionOptions.MaxSpoolBytes,
+			}
+		}
+	}
+	
+	// Se não especificado, usa configuração padrão
+	if processorOptions.MaxConcurrency <= 0 {
+		processorOptions.MaxConcurrency = min(req.ParallelLimit, s.uploadConcurrency())
+		if processorOptions.MaxConcurrency <= 0 {
+			processorOptions.MaxConcurrency = 100
+		}
+	}
+	
+	// Resolve caminho completo
+	fullPath := req.BasePath
+	if !filepath.IsAbs(fullPath) {
+		fullPath = filepath.Join(s.config.LibraryRoot, req.BasePath)
+	}
+	
+	// Os eventos vão para o stream do job, não para a conexão: se ela cair o
+	// job continua e outro cliente pode assumi-lo com claim_job
+	claimToken := s.jobStreams.Open(req.CollectionID, "collection", conn)
+	
+	// Callback de progresso - envia via WebSocket
+	onProgress := func(update *collection.ProgressUpdate) {
+		response := wsmanager.Response{
+			Status:    "collection_progress",
+			RequestID: req.RequestID,
+			Data: map[string]interface{}{
+				"collection":     req.CollectionName,
+				"collectionId":   req.CollectionID,
+				"progress":       update.Progress,
+				"currentFile":    update.CurrentFile,
+				"updateType":     update.Type,
+				"timestamp":      update.Timestamp,
+			},
+		}
+		s.jobStreams.Publish(req.CollectionID, response)
+	}
+	
+	// Callback de conclusão
+	onComplete := func(err error) {
+		status := "collection_completed"
+		errorMsg := ""
+		var errorCode wsmanager.ErrorCode
+		
+		if err != nil {
+			status = "collection_failed"
+			errorMsg = err.Error()
+			errorCode = wsmanager.ErrCollectionFailed
+			if errors.Is(err, collection.ErrProcessorHalted) {
+				errorCode = wsmanager.ErrCanceled
+			}
+		}
+		s.notifyCollection(req.CollectionName, req.CollectionID, err)
+		s.writeChecksums(req.CollectionID)
+		
+		data := map[string]interface{}{
+			"collection":   req.CollectionName,
+			"collectionId": req.CollectionID,
+			"timestamp":    time.Now(),
+		}
+		if job, exists := s.collectionProcessor.GetJobStatus(req.CollectionID); exists && job.QualityReport() != nil {
+			data["quality"] = job.QualityReport()
+		}
+		
+		response := wsmanager.Response{
+			Status:    status,
+			RequestID: req.RequestID,
+			Error:     errorMsg,
+			ErrorCode: errorCode,
+			Data:      data,
+		}
+		s.jobStreams.Finish(req.CollectionID, response)
+	}
+	
+	// Prioridade na fila de coleções (maior executa primeiro)
+	priority := 0
+	if req.CollectionOptions != nil {
+		priority = req.CollectionOptions.Priority
+	}
+	
+	// Cria requisição de processamento
+	collectionReq := &collection.CollectionRequest{
+		ID:             req.CollectionID,
+		CollectionName: req.CollectionName,
+		BasePath:       fullPath,
+		Host:           req.Host,
+		Options:        processorOptions,
+		Priority:       priority,
+		OnProgress:     onProgress,
+		OnComplete:     onComplete,
+	}
+	
+	// Inicia processamento
+	job, err := s.collectionProcessor.ProcessCollection(collectionReq)
+	if err != nil {
+		s.jobStreams.Discard(req.CollectionID)
+		errorCode := wsmanager.ErrCollectionFailed
+		if errors.Is(err, collection.ErrProcessorHalted) {
+			errorCode = wsmanager.ErrUploadsDisabled
+		}
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgCollectionStartFailed, err),
+			ErrorCode: errorCode,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	// Resposta imediata de confirmação (coleções aguardando slot são reportadas como enfileiradas)
+	status := "collection_started"
+	queuePosition := s.collectionProcessor.GetQueuePosition(job.ID)
+	if queuePosition > 0 {
+		status = "collection_queued"
+	}
+	
+	response := wsmanager.Response{
+		Status:    status,
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"collection":    req.CollectionName,
+			"collectionId":  job.ID,
+			"basePath":      fullPath,
+			"host":          req.Host,
+			"options":       processorOptions,
+			"priority":      priority,
+			"queuePosition": queuePosition,
+			"claimToken":    claimToken,
+			"timestamp":     job.StartTime,
+		},
+	}
+	
+	return conn.Send(response)
+}
+
+// handleDisconnect orphans the jobs of a dropped connection and tells the other clients they can claim them
+func (s *HighPerformanceServer) handleDisconnect(conn *wsmanager.Connection) {
+	orphaned := s.jobStreams.Detach(conn)
+	if len(orphaned) == 0 {
+		return
+	}
+	log.Printf("⚠️ Connection %s dropped; %d job(s) keep running without an owner", conn.ID, len(orphaned))
+	s.wsManager.Broadcast(wsmanager.Response{
+		Status: "job_orphaned",
+		Data: map[string]interface{}{
+			"jobs": orphaned,
+		},
+	})
+}
+
+// handleHeartbeat keeps the connection alive and reports the jobs it owns
+func (s *HighPerformanceServer) handleHeartbeat(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	conn.Touch()
+	return conn.Send(wsmanager.Response{
+		Status:    "heartbeat_ack",
+		RequestID: msg.RequestID,
+		Data: map[string]interface{}{
+			"connectionId": conn.ID,
+			"serverTime":   time.Now(),
+			"ownedJobs":    s.jobStreams.Owned(conn),
+		},
+	})
+}
+
+// handleListJobs lists active and recent batches and collections with state, owner, progress
+// and the actions that apply to each one (the "what is the server doing" view)
+func (s *HighPerformanceServer) handleListJobs(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid list jobs request: %v", err)
+	}
+	
+	streams := make(map[string]wsmanager.JobInfo)
+	for _, info := range s.jobStreams.List() {
+		streams[info.ID] = info
+	}
+	
+	jobs := []JobListEntry{}
+	for _, batch := range s.batchUploader.ListBatches() {
+		entry := JobListEntry{
+			Kind:      "batch",
+			ID:        batch.Progress.BatchID,
+			State:     batch.State,
+			Progress:  newJobListProgress(batch.Progress.Completed, batch.Progress.Failed, batch.Progress.Total),
+			StartedAt: batch.Progress.StartTime,
+			Controls:  []string{"get_release_post", "get_job_logs"},
+			Detail:    batch,
+		}
+		if batch.State == "running" {
+			entry.Controls = append([]string{"cancel_batch"}, entry.Controls...)
+		}
+		jobs = append(jobs, entry)
+	}
+	for _, job := range s.collectionProcessor.ListJobs() {
+		entry := JobListEntry{
+			Kind:      "collection",
+			ID:        job.ID,
+			Name:      job.Name,
+			State:     string(job.Status),
+			Progress:  newJobListProgress(int64(job.Progress.UploadedFiles), int64(job.Progress.FailedFiles), int64(job.Progress.TotalFiles)),
+			StartedAt: job.StartTime,
+			EndedAt:   job.EndTime,
+			Controls:  []string{"get_collection_status", "get_job_logs"},
+			Detail:    job,
+		}
+		if job.QueuePosition > 0 {
+			entry.State = "queued"
+			entry.Controls = append(entry.Controls, "cancel_collection", "reorder_collection_queue")
+		} else if job.Status == collection.StatusRunning || job.Status == collection.StatusPending {
+			entry.Controls = append(entry.Controls, "cancel_collection")
+		}
+		if stream, ok := streams[job.ID]; ok {
+			entry.Owner = stream.Owner
+			entry.Orphaned = stream.Orphaned && !stream.Done
+			entry.Controls = append(entry.Controls, "claim_job")
+		}
+		jobs = append(jobs, entry)
+	}
+	
+	counts := make(map[string]int)
+	active := jobs[:0]
+	for _, job := range jobs {
+		counts[job.State]++
+		if req.ActiveOnly && !job.active() {
+			continue
+		}
+		active = append(active, job)
+	}
+	jobs = active
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].active() != jobs[j].active() {
+			return jobs[i].active()
+		}
+		return jobs[i].StartedAt.After(jobs[j].StartedAt)
+	})
+	
+	controls := []string{"emergency_stop"}
+	if s.uploadsHalted() {
+		controls = []string{"resume_uploads"}
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "job_list",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"jobs":          jobs,
+			"counts":        counts,
+			"uploadsHalted": s.uploadsHalted(),
+			"connections":   s.wsManager.GetConnectionCount(),
+			"controls":      controls,
+			"timestamp":     time.Now(),
+		},
+	})
+}
+
+// handleGetJobLogs returns the captured log lines of a batch or collection after `since`
+func (s *HighPerformanceServer) handleGetJobLogs(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid job logs request: %v", err)
+	}
+	jobID := req.JobID
+	if jobID == "" {
+		jobID = req.BatchID
+	}
+	if jobID == "" {
+		jobID = req.CollectionID
+	}
+	if jobID == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "jobId"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	logs, ok := s.jobLogs.Get(jobID, req.Since, joblog.Level(strings.ToLower(req.Level)))
+	if !ok {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgJobNotFound, jobID),
+			ErrorCode: wsmanager.ErrJobNotFound,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "job_logs",
+		RequestID: req.RequestID,
+		Data:      logs,
+	})
+}
+
+// handleClaimJob moves a job's progress stream to this connection, replaying buffered events after `since`.
+// The claim token returned when the job started is required, since any client may send claim_job.
+func (s *HighPerformanceServer) handleClaimJob(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid claim job request: %v", err)
+	}
+	if req.JobID == "" {
+		req.JobID = req.CollectionID
+	}
+	if req.JobID == "" || req.ClaimToken == "" {
+		field := "jobId"
+		if req.JobID != "" {
+			field = "claimToken"
+		}
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, field),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	info, previous, err := s.jobStreams.Claim(req.JobID, req.ClaimToken, conn, req.Since)
+	if err != nil {
+		message := i18n.T(connLocale(conn), i18n.MsgJobNotFound, req.JobID)
+		if wsmanager.CodeOf(err) == wsmanager.ErrJobClaimDenied {
+			message = i18n.T(connLocale(conn), i18n.MsgJobClaimDenied, req.JobID)
+		}
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     message,
+			ErrorCode: wsmanager.CodeOf(err),
+			RequestID: req.RequestID,
+		})
+	}
+	
+	if previous != nil {
+		safeSend(previous, wsmanager.Response{
+			Status: "job_released",
+			Data: map[string]interface{}{
+				"jobId":     info.ID,
+				"claimedBy": conn.ID,
+			},
+		})
+	}
+	log.Printf("🔁 Job %s claimed by connection %s (events after #%d replayed)", info.ID, conn.ID, req.Since)
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "job_claimed",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"job": info,
+		},
+	})
+}
+
+// handleGetCollectionQueue retorna as coleções em execução e as que aguardam na fila
+func (s *HighPerformanceServer) handleGetCollectionQueue(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	return conn.Send(wsmanager.Response{
+		Status:    "collection_queue",
+		RequestID: msg.RequestID,
+		Data:      s.collectionProcessor.GetQueue(),
+	})
+}
+
+// handleReorderCollectionQueue redefine a ordem das coleções pendentes
+func (s *HighPerformanceServer) handleReorderCollectionQueue(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid reorder collection queue request: %v", err)
+	}
+	
+	if len(req.CollectionOrder) == 0 {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "collectionOrder"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	if err := s.collectionProcessor.ReorderQueue(req.CollectionOrder); err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     err.Error(),
+			ErrorCode: wsmanager.ErrInvalidRequest,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "collection_queue_reordered",
+		RequestID: req.RequestID,
+		Data:      s.collectionProcessor.GetQueue(),
+	})
+}
+
+// handleEstimateCollection estima bytes, requisições e duração do envio de uma coleção
+// para um host (ou para todos os hosts registrados), antes de iniciar o processamento
+func (s *HighPerformanceServer) handleEstimateCollection(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid estimate collection request: %v", err)
+	}
+	
+	if req.BasePath == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "basePath"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	// Mesmo caminho que process_collection usaria
+	fullPath := req.BasePath
+	if !filepath.IsAbs(fullPath) {
+		fullPath = filepath.Join(s.config.LibraryRoot, req.BasePath)
+	}
+	if _, err := os.Stat(fullPath); err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgPathNotFound, fullPath),
+			ErrorCode: wsmanager.ErrPathNotFound,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	hosts := s.batchUploader.Hosts()
+	if req.Host != "" {
+		hosts = []string{req.Host}
+	}
+	
+	go func() {
+		result, err := s.discoverer.DiscoverStructure(fullPath, nil)
+		if err != nil {
+			safeSend(conn, wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgEstimateFailed, err),
+				ErrorCode: wsmanager.ErrDiscoveryFailed,
+				RequestID: req.RequestID,
+			})
+			return
+		}
+		
+		var files []upload.EstimateFile
+		chapters := make(map[string][]string)
+		for _, path := range discovery.FilePaths(fullPath, result.Tree) {
+			if info, err := os.Stat(path); err == nil {
+				files = append(files, upload.EstimateFile{Path: path, Size: info.Size()})
+				chapters[filepath.Dir(path)] = append(chapters[filepath.Dir(path)], path)
+			}
+		}
+		
+		var estimates []*upload.HostEstimate
+		recommended := ""
+		var fastest int64
+		for _, host := range hosts {
+			estimate, err := s.batchUploader.EstimateHost(host, files)
+			if err != nil {
+				safeSend(conn, wsmanager.Response{
+					Status:    "error",
+					Error:     i18n.T(connLocale(conn), i18n.MsgEstimateFailed, err),
+					ErrorCode: wsmanager.CodeOf(err),
+					RequestID: req.RequestID,
+				})
+				return
+			}
+			estimates = append(estimates, estimate)
+			
+			// Recomenda o host mais rápido que aceita todos os arquivos
+			if estimate.WithinLimits && (recommended == "" || estimate.EstimatedSeconds < fastest) {
+				recommended = estimate.Host
+				fastest = estimate.EstimatedSeconds
+			}
+		}
+		
+		data := map[string]interface{}{
+			"basePath":        fullPath,
+			"files":           len(files),
+			"chapters":        result.Metadata.Stats.TotalChapters,
+			"estimates":       estimates,
+			"recommendedHost": recommended,
+		}
+		
+		// Dry run QC: the same report the collection would produce
+		if s.qualityAnalyzer != nil {
+			report := quality.NewReport()
+			for dir, pages := range chapters {
+				sort.Strings(pages)
+				name, _ := filepath.Rel(fullPath, dir)
+				report.Add(s.qualityAnalyzer.Chapter(filepath.ToSlash(name), pages))
+			}
+			data["quality"] = report
+		}
+		
+		safeSend(conn, wsmanager.Response{
+			Status:    "collection_estimate",
+			RequestID: req.RequestID,
+			Data:      data,
+		})
+	}()
+	
+	return nil
+}
+
+// handleGetCollectionStatus retorna o status de uma coleção
+func (s *HighPerformanceServer) handleGetCollectionStatus(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid get collection status request: %v", err)
+	}
+	
+	if req.CollectionID == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "collectionId"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	job, exists := s.collectionProcessor.GetJobStatus(req.CollectionID)
+	if !exists {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgCollectionNotFound),
+			ErrorCode: wsmanager.ErrCollectionNotFound,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	// Calcula progresso atual (acessos thread-safe via métodos do job)
+	progress := &collection.CollectionProgress{
+		TotalObras:        job.TotalObras,
+		CompletedObras:    job.CompletedObras,
+		TotalChapters:     job.TotalChapters,
+		CompletedChapters: job.CompletedChapters,
+		TotalFiles:        job.TotalFiles,
+		UploadedFiles:     job.UploadedFiles,
+		FailedFiles:       job.FailedFiles,
+		CurrentSpeed:      job.CurrentSpeed,
+		AverageSpeed:      job.AverageSpeed,
+	}
+	
+	if job.TotalFiles > 0 {
+		progress.Percentage = float64(job.UploadedFiles) / float64(job.TotalFiles) * 100
+	}
+	
+	if job.ETA != nil {
+		progress.ETA = job.ETA.String()
+	}
+	
+	response := wsmanager.Response{
+		Status:    "collection_status",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"collection":   job.Name,
+			"collectionId": job.ID,
+			"status":       job.Status,
+			"progress":     progress,
+			"startTime":    job.StartTime,
+			"lastFile":     job.LastProcessedFile,
+			"budget":       job.BudgetStats(),
+			"journal":      job.JournalSummary(),
+			"quality":      job.QualityReport(),
+		},
+	}
+	
+	return conn.Send(response)
+}
+
+// handleCancelCollection cancela uma coleção
+func (s *HighPerformanceServer) handleCancelCollection(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid cancel collection request: %v", err)
+	}
+	
+	if req.CollectionID == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "collectionId"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	err := s.collectionProcessor.CancelJob(req.CollectionID)
+	status := "collection_cancelled"
+	errorMsg := ""
+	var errorCode wsmanager.ErrorCode
+	
+	if err != nil {
+		status = "error"
+		errorMsg = err.Error()
+		errorCode = wsmanager.ErrCollectionNotFound
+	}
+	
+	response := wsmanager.Response{
+		Status:    status,
+		RequestID: req.RequestID,
+		Error:     errorMsg,
+		ErrorCode: errorCode,
+		Data: map[string]interface{}{
+			"collectionId": req.CollectionID,
+			"timestamp":    time.Now(),
+		},
+	}
+	
+	return conn.Send(response)
+}
+
+// handlePauseCollection pausa uma coleção (placeholder para futura implementação)
+func (s *HighPerformanceServer) handlePauseCollection(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid pause collection request: %v", err)
+	}
+	
+	// TODO: Implementar pause functionality no collection processor
+	response := wsmanager.Response{
+		Status:    "error",
+		Error:     i18n.T(connLocale(conn), i18n.MsgNotImplemented, "pause"),
+		ErrorCode: wsmanager.ErrNotImplemented,
+		RequestID: req.RequestID,
+	}
+	
+	return conn.Send(response)
+}
+
+// handleResumeCollection retoma uma coleção pausada pela parada de emergência ou
+// interrompida por um reinício; os arquivos já enviados são pulados pelo journal
+func (s *HighPerformanceServer) handleResumeCollection(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid resume collection request: %v", err)
+	}
+	
+	if req.CollectionID == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "collectionId"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	if s.uploadsHalted() {
+		return s.sendUploadsDisabled(conn, req.RequestID)
+	}
+	
+	var saved *collection.CollectionJob
+	for _, job := range s.collectionProcessor.ResumableJobs() {
+		if job.ID == req.CollectionID {
+			saved = job
+			break
+		}
+	}
+	if saved == nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgCollectionNotFound),
+			ErrorCode: wsmanager.ErrCollectionNotFound,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	if err := s.resubmitCollection(saved); err != nil {
+		errorCode := wsmanager.ErrCollectionFailed
+		if errors.Is(err, collection.ErrProcessorHalted) {
+			errorCode = wsmanager.ErrUploadsDisabled
+		}
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgCollectionStartFailed, err),
+			ErrorCode: errorCode,
+			RequestID: req.RequestID,
+		})
+	}
+	log.Printf("Resumed collection %s (%s)", saved.Name, saved.ID)
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "collection_resumed",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"collection":   saved.Name,
+			"collectionId": saved.ID,
+			"timestamp":    time.Now(),
+		},
+	})
+}
+
+// handleGetWorkerStats retorna estatísticas do worker pool
+func (s *HighPerformanceServer) handleGetWorkerStats(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	workerStats := s.workerPool.GetStats()
+	collectionStats := s.collectionProcessor.GetMetrics()
+	
+	response := wsmanager.Response{
+		Status:    "worker_stats",
+		RequestID: msg.RequestID,
+		Data: map[string]interface{}{
+			"workerPool":          workerStats,
+			"uploadWorkers":       s.batchUploader.WorkerStats(),
+			"collectionProcessor": collectionStats,
+			"server": map[string]interface{}{
+				"uptime":      time.Since(startTime).String(),
+				"connections": s.wsManager.GetConnectionCount(),
+			},
+		},
+	}
+	
+	return conn.Send(response)
+}
+
+// handleSearchAniList handles AniList search requests
+func (s *HighPerformanceServer) handleSearchAniList(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid search request: %v", err)
+	}
+
+	// DEBUG: Log da query recebida
+	log.Printf("🔍 DEBUG SEARCH - Query recebida: '%s' (len: %d bytes)", req.SearchQuery, len(req.SearchQuery))
+	log.Printf("🔍 DEBUG SEARCH - Query em hex: % x", []byte(req.SearchQuery))
+	
+	if req.SearchQuery == "" {
+		response := wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgSearchQueryRequired),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		}
+		return conn.Send(response)
+	}
+	
+	// A title override for the folder replaces the query; a pinned AniList ID is returned for preselection
+	searchQuery := req.SearchQuery
+	override, overridden := s.titleOverrides.Lookup(req.SearchQuery)
+	if !overridden && req.Manga != "" {
+		override, overridden = s.titleOverrides.Lookup(req.Manga)
+	}
+	if overridden {
+		searchQuery = override.Query()
+		log.Printf("🔁 Title override for %q: searching %q (AniList ID %d)", override.Folder, searchQuery, override.AniListID)
+	}
+	
+	go func() {
+		startTime := time.Now()
+		
+		// Send progress update
+		progressResponse := wsmanager.Response{
+			Status:    "search_progress",
+			RequestID: req.RequestID,
+			Progress: &wsmanager.Progress{
+				Current:    0,
+				Total:      1,
+				Percentage: 0,
+				Stage:      "searching_anilist",
+			},
+		}
+		safeSend(conn, progressResponse)
+		
+		// Perform AniList search with retry and error handling
+		// Criar contexto com timeout para evitar busca infinita
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second) // 60 segundos de timeout
+		defer cancel()
+		
+		results, err := s.anilistService.SearchMangaWithRetry(ctx, searchQuery, 1, 10)
+		
+		duration := time.Since(startTime)
+		log.Printf("AniList search completed in %v for query: %s", duration, searchQuery)
+		
+		if err != nil {
+			// Verificar se é um erro amigável (FriendlyError)
+			var friendlyErr *anilist.FriendlyError
+			var errorMessage string
+			var errorData map[string]interface{}
+
+			if errors.As(err, &friendlyErr) {
+				// Erro amigável - usar mensagem personalizada no idioma do cliente
+				friendlyErr = friendlyErr.Localized(connLocale(conn))
+				errorMessage = friendlyErr.UserMessage
+				errorData = map[string]interface{}{
+					"error_code":        friendlyErr.ErrorCode,
+					"severity":          string(friendlyErr.Severity),
+					"suggestions":       friendlyErr.Suggestions,
+					"user_message":      friendlyErr.UserMessage,
+					"technical_message": friendlyErr.TechnicalMessage,
+				}
+
+				if friendlyErr.RetryAfter != nil {
+					errorData["retry_after_seconds"] = int(friendlyErr.RetryAfter.Seconds())
+				}
+			} else {
+				// Erro técnico - usar mensagem genérica
+				errorMessage = i18n.T(connLocale(conn), i18n.MsgAniListSearchUnexpected)
+				errorData = map[string]interface{}{
+					"error_code":     "UNEXPECTED_ERROR",
+					"severity":       "error",
+					"user_message":   errorMessage,
+					"suggestions":    i18n.Lines(connLocale(conn), i18n.MsgAniListUnexpectedHints),
+				}
+			}
+
+			response := wsmanager.Response{
+				Status:    "anilist_error",
+				Error:     errorMessage,
+				ErrorCode: wsmanager.ErrAniListFailed,
+				RequestID: req.RequestID,
+				Data:      errorData,
+			}
+			safeSend(conn, response)
+			return
+		}
+		
+		// Send successful response
+		response := wsmanager.Response{
+			Status:    "search_anilist_complete",
+			RequestID: req.RequestID,
+			Data: map[string]interface{}{
+				"results":     results.Results,
+				"resultCount": len(results.Results),
+				"searchQuery": req.SearchQuery,
+				"duration":    duration.String(),
+				"total":       results.Total,
+				"hasNextPage": results.HasNextPage,
+				"source":      results.Source,
+				"localCovers": results.LocalCovers,
+				"libraryMatches": s.libraryMatches(results.Results),
+			},
+		}
+		if overridden {
+			response.Data.(map[string]interface{})["override"] = override
+			response.Data.(map[string]interface{})["resolvedQuery"] = searchQuery
+		}
+		safeSend(conn, response)
+	}()
+	
+	return nil
+}
+
+// handleSelectAniListResult handles selection of an AniList result and metadata integration
+func (s *HighPerformanceServer) handleSelectAniListResult(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid selection request: %v", err)
+	}
+	
+	if req.AniListID == 0 {
+		response := wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgAniListIDRequired),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		}
+		return conn.Send(response)
+	}
+	
+	go func() {
+		startTime := time.Now()
+		
+		// Send progress update
+		progressResponse := wsmanager.Response{
+			Status:    "anilist_fetch_progress",
+			RequestID: req.RequestID,
+			Progress: &wsmanager.Progress{
+				Current:    0,
+				Total:      2,
+				Percentage: 0,
+				Stage:      "fetching_details",
+			},
+		}
+		safeSend(conn, progressResponse)
+		
+		// Fetch detailed information from AniList with retry and error handling
+		details, err := s.anilistService.GetMangaDetailsWithRetry(context.Background(), req.AniListID)
+		if err != nil {
+			// Verificar se é um erro amigável (FriendlyError)
+			var friendlyErr *anilist.FriendlyError
+			var errorMessage string
+			var errorData map[string]interface{}
+
+			if errors.As(err, &friendlyErr) {
+				// Erro amigável - usar mensagem personalizada no idioma do cliente
+				friendlyErr = friendlyErr.Localized(connLocale(conn))
+				errorMessage = friendlyErr.UserMessage
+				errorData = map[string]interface{}{
+					"error_code":        friendlyErr.ErrorCode,
+					"severity":          string(friendlyErr.Severity),
+					"suggestions":       friendlyErr.Suggestions,
+					"user_message":      friendlyErr.UserMessage,
+					"technical_message": friendlyErr.TechnicalMessage,
+				}
+
+				if friendlyErr.RetryAfter != nil {
+					errorData["retry_after_seconds"] = int(friendlyErr.RetryAfter.Seconds())
+				}
+			} else {
+				// Erro técnico - usar mensagem genérica
+				errorMessage = i18n.T(connLocale(conn), i18n.MsgAniListDetailsUnexpected)
+				errorData = map[string]interface{}{
+					"error_code":     "UNEXPECTED_ERROR",
+					"severity":       "error",
+					"user_message":   errorMessage,
+					"suggestions":    i18n.Lines(connLocale(conn), i18n.MsgAniListUnexpectedHints),
+				}
+			}
+
+			response := wsmanager.Response{
+				Status:    "anilist_error",
+				Error:     errorMessage,
+				ErrorCode: wsmanager.ErrAniListFailed,
+				RequestID: req.RequestID,
+				Data:      errorData,
+			}
+			safeSend(conn, response)
+			return
+		}
+		
+		// Update progress
+		progressResponse.Progress.Current = 1
+		progressResponse.Progress.Percentage = 50
+		progressResponse.Progress.Stage = "processing_metadata"
+		safeSend(conn, progressResponse)
+		
+		// Convert to metadata format (using the mapping function from anilist service)
+		metadata := anilist.MapAniListToMangaMetadata(details.Media)
+		tags := make([]string, 0, len(details.Media.Tags))
+		for _, tag := range details.Media.Tags {
+			tags = append(tags, tag.Name)
+		}
+		metadata.NSFW = s.contentPolicy.NSFW(details.Media.Genres, tags, details.Media.IsAdult)
+		
+		// Remember which library entry this AniList ID belongs to
+		s.linkAniListSelection(req, details.Media.ID)
+		
+		duration := time.Since(startTime)
+		log.Printf("AniList details fetched and processed in %v for ID: %d", duration, req.AniListID)
+		
+		// Send successful response with integrated metadata
+		response := wsmanager.Response{
+			Status:    "anilist_selection_complete",
+			RequestID: req.RequestID,
+			Data: map[string]interface{}{
+				"anilistData": details,
+				"metadata":    metadata,
+				"mangaTitle":  req.MangaTitle,
+				"duration":    duration.String(),
+			},
+			Metadata: metadata,
+		}
+		safeSend(conn, response)
+	}()
+	
+	return nil
+}
+
+// handleListTitleOverrides lists the folder name → canonical title mappings
+func (s *HighPerformanceServer) handleListTitleOverrides(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	data := map[string]interface{}{
+		"overrides": s.titleOverrides.List(),
+		"file":      s.paths.TitleOverrides,
+	}
+	if err := s.titleOverrides.Err(); err != nil {
+		data["fileError"] = err.Error()
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "title_overrides",
+		RequestID: msg.RequestID,
+		Data:      data,
+	})
+}
+
+// handleSetTitleOverride creates or replaces the override of a folder name
+func (s *HighPerformanceServer) handleSetTitleOverride(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid title override request: %v", err)
+	}
+	if req.Override == nil || strings.TrimSpace(req.Override.Folder) == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "override.folder"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	override, err := s.titleOverrides.Set(*req.Override)
+	if err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "%v", err)
+	}
+	log.Printf("🔁 Title override saved: %q → %q (AniList ID %d)", override.Folder, override.Title, override.AniListID)
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "title_override_saved",
+		Data:      override,
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleRemoveTitleOverride deletes the override of a folder name
+func (s *HighPerformanceServer) handleRemoveTitleOverride(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid title override request: %v", err)
+	}
+	if req.Override == nil || strings.TrimSpace(req.Override.Folder) == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "override.folder"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	if err := s.titleOverrides.Remove(req.Override.Folder); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "%v", err)
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "title_override_removed",
+		Data:      map[string]interface{}{"folder": req.Override.Folder},
+		RequestID: msg.RequestID,
+	})
+}
+
+// LibraryMatch describes a local library entry matching a search candidate
+type LibraryMatch struct {
+	MangaID string `json:"mangaId"`
+	Title   string `json:"title"`
+	MatchBy string `json:"matchBy"` // "id" (linked in the registry) or "title"
+}
+
+// libraryMatches annotates search candidates that are already tracked in the
+// local library, keyed by AniList ID
+func (s *HighPerformanceServer) libraryMatches(results []anilist.MangaBasic) map[int]LibraryMatch {
+	matches := make(map[int]LibraryMatch)
+	
+	for _, manga := range results {
+		if entry, found := s.idRegistry.FindByAniListID(manga.ID); found {
+			matches[manga.ID] = LibraryMatch{MangaID: entry.MangaID, Title: entry.Title, MatchBy: "id"}
+			continue
+		}
+		
+		// Title fallback only for entries not yet linked to another AniList ID
+		titles := []*string{manga.Title.English, manga.Title.Romaji, manga.Title.Native}
+		for _, synonym := range manga.Synonyms {
+			synonym := synonym
+			titles = append(titles, &synonym)
+		}
+		for _, title := range titles {
+			if title == nil || *title == "" {
+				continue
+			}
+			if entry, found := s.idRegistry.FindByTitle(*title); found && entry.AniListID == 0 {
+				matches[manga.ID] = LibraryMatch{MangaID: entry.MangaID, Title: entry.Title, MatchBy: "title"}
+				break
+			}
+		}
+	}
+	
+	return matches
+}
+
+// linkAniListSelection records the AniList ID chosen for a library manga
+func (s *HighPerformanceServer) linkAniListSelection(req WebSocketRequest, anilistID int) {
+	mangaID := ""
+	if req.Manga != "" {
+		mangaID = mangaid.Normalize(req.Manga)
+	} else if entry, found := s.idRegistry.FindByTitle(req.MangaTitle); found {
+		mangaID = entry.MangaID
+	}
+	if mangaID == "" {
+		return
+	}
+	
+	if err := s.idRegistry.Link(mangaID, req.MangaTitle, anilistID); err != nil {
+		log.Printf("⚠️ Failed to link %s to AniList ID %d: %v", mangaID, anilistID, err)
+	}
+}
+
+// setupHTTPServer configures the HTTP server with optimizations
+func (s *HighPerformanceServer) setupHTTPServer() {
+	mux := http.NewServeMux()
+	
+	// WebSocket endpoint with connection management
+	mux.HandleFunc("/ws", s.handleWebSocket)
+	
+	// Metrics endpoint (optional HTTP endpoint for monitoring)
+	if s.config.EnableMetrics {
+		mux.HandleFunc("/metrics", s.handleHTTPMetrics)
+		mux.HandleFunc("/health", s.handleHealthCheck)
+	}
+	
+	// Liveness (process is up) and readiness (accepting work) for supervisors
+	mux.HandleFunc("/live", s.handleHealthCheck)
+	mux.HandleFunc("/ready", s.handleReadiness)
+	
+	// AniList metrics endpoint for performance monitoring
+	mux.HandleFunc("/api/anilist/metrics", s.handleAniListMetrics)
+	
+	// AniList health status endpoint
+	mux.HandleFunc("/api/anilist/health", s.handleAniListHealth)
+	
+	// Locally cached AniList cover images
+	mux.HandleFunc(anilist.CoverURLPrefix, s.handleCover)
+	
+	// RSS feeds of recent releases: whole library and per manga (/feed/<manga>.xml)
+	mux.HandleFunc("/feed.xml", s.handleFeed)
+	mux.HandleFunc("/feed/", s.handleFeed)
+	mux.HandleFunc("/"+catalog.FileName, s.handleCatalogIndex)
+	
+	// Generated manga JSONs for reader frontends (/json/<manga>.json), with ETag revalidation
+	mux.HandleFunc("/json/", s.handleMangaJSON)
+	
+	// Read-only collection progress for share links (/share/<token>, /share/<token>/ws)
+	if s.shareLinks != nil {
+		mux.Handle(share.PathPrefix, share.NewHandler(s.shareLinks, s.sharedCollectionStatus, &upgrader))
+	}
+	
+	// Compression: large discovery trees, metrics and JSONs shrink several times
+	var compressionConfig compression.Config
+	if s.config.Compression != nil {
+		compressionConfig = *s.config.Compression
+	}
+	compressionConfig = compressionConfig.Normalize()
+	upgrader.EnableCompression = !compressionConfig.DisableWebSocket
+	s.wsManager.SetCompression(!compressionConfig.DisableWebSocket, compressionConfig.Threshold, compressionConfig.Level)
+	
+	s.httpServer = &http.Server{
+		Addr:         s.config.Port,
+		Handler:      compression.Handler(mux, compressionConfig),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+}
+
+// handleWebSocket handles WebSocket connections with enhanced management
+func (s *HighPerformanceServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+	
+	// Generate unique connection ID
+	connectionID := fmt.Sprintf("conn_%d_%s", time.Now().UnixNano(), r.RemoteAddr)
+	
+	// Create managed connection
+	managedConn := s.wsManager.NewConnection(conn, connectionID)
+	
+	// Initial locale from ?lang= or the Accept-Language header (can be changed with set_locale)
+	locale := r.URL.Query().Get("lang")
+	if locale == "" {
+		locale = r.Header.Get("Accept-Language")
+	}
+	if locale != "" {
+		managedConn.SetLocale(string(i18n.ParseLocale(locale)))
+	}
+	
+	// Binary MessagePack responses from ?encoding=msgpack (can be changed with set_encoding)
+	if encoding, err := wsmanager.ParseEncoding(r.URL.Query().Get("encoding")); err == nil {
+		managedConn.SetEncoding(encoding)
+	}
+	
+	// Record connection metrics
+	s.monitor.RecordWebSocketConnection(true)
+	
+	// permessage-deflate is only used when the client offers it in the handshake
+	compressed := upgrader.EnableCompression && strings.Contains(strings.ToLower(r.Header.Get("Sec-WebSocket-Extensions")), "permessage-deflate")
+	log.Printf("New WebSocket connection: %s (compression: %v, encoding: %s)", connectionID, compressed, managedConn.Encoding())
+	
+	// Connection will be automatically cleaned up by the manager
+}
+
+// handleHTTPMetrics serves metrics over HTTP for monitoring tools
+func (s *HighPerformanceServer) handleHTTPMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	// Without parameters keep the full snapshot; ?sections=, ?fields=, ?collections=, ?from= and ?to= narrow it down
+	var metrics interface{} = s.monitor.CreateSnapshot()
+	query, err := monitoring.ParseQuery(r.URL.Query(), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !query.Empty() {
+		data, err := s.monitor.Query(query, nil, s.metricsExtras())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data["timestamp"] = time.Now()
+		metrics = data
+	}
+	if err := json.NewEncoder(w).Encode(metrics); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleHealthCheck provides a health check endpoint
+func (s *HighPerformanceServer) handleHealthCheck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	health := map[string]interface{}{
+		"status":      "healthy",
+		"timestamp":   time.Now(),
+		"uptime":      time.Since(startTime).String(),
+		"connections": s.wsManager.GetConnectionCount(),
+		"version":     version,
+	}
+	
+	json.NewEncoder(w).Encode(health)
+}
+
+// handleReadiness reports whether the server is accepting work.
+// Unlike liveness it fails (503) before the listener is up and while shutting down.
+func (s *HighPerformanceServer) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	ready := s.isReady()
+	readiness := map[string]interface{}{
+		"ready":         ready,
+		"timestamp":     time.Now(),
+		"uploadsHalted": s.uploadsHalted(),
+		"collections":   s.collectionProcessor.GetQueue(),
+	}
+	
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(readiness)
+}
+
+// isReady reports whether the server is listening and not shutting down
+func (s *HighPerformanceServer) isReady() bool {
+	return atomic.LoadInt32(&s.ready) == 1 && s.ctx.Err() == nil
+}
+
+// Start starts the high-performance server
+func (s *HighPerformanceServer) Start() error {
+	log.Printf("Starting High-Performance Manga Upload Server...")
+	log.Printf("Configuration: %+v", s.config)
+	
+	// Start worker pool
+	if err := s.workerPool.Start(); err != nil {
+		return fmt.Errorf("failed to start worker pool: %v", err)
+	}
+	
+	// Start collection processor
+	if err := s.collectionProcessor.Start(); err != nil {
+		return fmt.Errorf("failed to start collection processor: %v", err)
+	}
+	
+	// The background jobs below rewrite JSONs and state files; a read-only mirror
+	// leaves that to the instance it is synced from
+	if !s.config.ReadOnly {
+		// Start periodic manga status refresh (no-op without an interval)
+		s.statusRefresher.Start(s.ctx, s.broadcastStatusSummary)
+		
+		// Start periodic mirror health scoring (no-op without an interval)
+		s.mirrorChecker.Start(s.ctx, s.broadcastMirrorHealth)
+		
+		// Start pre-signed URL renewal (no-op without private buckets)
+		s.urlRefresher.Start(s.ctx, s.broadcastSignedURLSummary)
+		
+		// Start periodic update checks (no-op without an interval)
+		if s.updater != nil {
+			s.updater.Start(s.ctx, s.broadcastUpdateAvailable)
+		}
+	}
+	
+	// Start periodic re-evaluation of the worker recommendation (no-op without an interval)
+	s.tuner.Start(s.ctx, s.handleTuningChange)
+	
+	// Start age-based cleanup of finished batches, collections and upload results
+	if policy := s.config.statePolicy(); policy.Interval > 0 && !s.config.ReadOnly {
+		s.wg.Add(1)
+		go s.stateCleanupLoop(policy)
+	}
+	
+	// Start metrics logging
+	if s.config.EnableMetrics {
+		s.wg.Add(1)
+		go s.metricsLogger()
+	}
+	
+	log.Printf("Server starting on %s", s.config.Port)
+	log.Printf("Max workers: %d, Max connections: %d", s.config.MaxWorkers, s.config.MaxConnections)
+	log.Printf("Discovery workers: %d", s.config.DiscoveryWorkers)
+	if s.config.LowMemory {
+		log.Printf("Low-memory mode: small buffers, upload results spooled to disk")
+	}
+	
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+	
+	// Ready: listener is bound, supervisors can route traffic
+	atomic.StoreInt32(&s.ready, 1)
+	if notified, err := sdNotify(SD_READY); err != nil {
+		log.Printf("⚠️ systemd notify failed: %v", err)
+	} else if notified {
+		log.Println("Notified systemd: ready")
+	}
+	
+	if interval := sdWatchdogInterval(); interval > 0 {
+		s.wg.Add(1)
+		go s.watchdogLoop(interval)
+	}
+	
+	if !s.config.ReadOnly {
+		s.resumeInterruptedCollections()
+	}
+	
+	return s.httpServer.Serve(listener)
+}
+
+// watchdogLoop pings the systemd watchdog while the server is healthy.
+// A stalled collection processor stops the pings so systemd restarts the process.
+func (s *HighPerformanceServer) watchdogLoop(interval time.Duration) {
+	defer s.wg.Done()
+	
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C:
+			if !s.isReady() {
+				continue
+			}
+			
+			// The snapshot takes the queue lock: a deadlock here means the process is hung
+			done := make(chan struct{})
+			go func() {
+				s.collectionProcessor.GetQueue()
+				close(done)
+			}()
+			
+			select {
+			case <-done:
+				sdNotify(SD_WATCHDOG)
+			case <-time.After(interval):
+				log.Println("⚠️ Collection processor unresponsive, skipping watchdog ping")
+			}
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// resumeInterruptedCollections re-submits collections left unfinished by a restart, a crash
+// or an emergency stop.
+// Files already uploaded are skipped thanks to the per-job file journal.
+func (s *HighPerformanceServer) resumeInterruptedCollections() {
+	jobs := s.collectionProcessor.ResumableJobs()
+	if len(jobs) == 0 {
+		return
+	}
+	
+	if !s.config.ResumeOnStart || s.uploadsHalted() {
+		log.Printf("%d interrupted collection(s) found; start with --resume to continue them", len(jobs))
+		return
+	}
+	
+	for _, saved := range jobs {
+		if err := s.resubmitCollection(saved); err != nil {
+			log.Printf("⚠️ Failed to resume collection %s: %v", saved.ID, err)
+			continue
+		}
+		log.Printf("Resumed interrupted collection %s (%s)", saved.Name, saved.ID)
+	}
+}
+
+// resubmitCollection queues a saved collection again under the same ID; the file
+// journal makes it skip what was already uploaded
+func (s *HighPerformanceServer) resubmitCollection(saved *collection.CollectionJob) error {
+	collectionID := saved.ID
+	collectionName := saved.Name
+	
+	_, err := s.collectionProcessor.ProcessCollection(&collection.CollectionRequest{
+		ID:             collectionID,
+		CollectionName: collectionName,
+		BasePath:       saved.BasePath,
+		Host:           saved.Host,
+		Options:        saved.Options,
+		OnProgress: func(update *collection.ProgressUpdate) {
+			s.wsManager.Broadcast(wsmanager.Response{
+				Status: "collection_progress",
+				Data: map[string]interface{}{
+					"collection":   collectionName,
+					"collectionId": collectionID,
+					"progress":     update.Progress,
+					"currentFile":  update.CurrentFile,
+					"updateType":   update.Type,
+					"timestamp":    update.Timestamp,
+					"resumed":      true,
+				},
+			})
+		},
+		OnComplete: func(err error) {
+			status := "collection_completed"
+			errorMsg := ""
+			var errorCode wsmanager.ErrorCode
+			if err != nil {
+				status = "collection_failed"
+				errorMsg = err.Error()
+				errorCode = wsmanager.ErrCollectionFailed
+				if errors.Is(err, collection.ErrProcessorHalted) {
+					errorCode = wsmanager.ErrCanceled
+				}
+			}
+			s.notifyCollection(collectionName, collectionID, err)
+			s.writeChecksums(collectionID)
+			s.wsManager.Broadcast(wsmanager.Response{
+				Status:    status,
+				Error:     errorMsg,
+				ErrorCode: errorCode,
+				Data: map[string]interface{}{
+					"collection":   collectionName,
+					"collectionId": collectionID,
+					"timestamp":    time.Now(),
+					"resumed":      true,
+				},
+			})
+		},
+	})
+	return err
+}
+
+// metricsLogger periodically logs metrics
+func (s *HighPerformanceServer) metricsLogger() {
+	defer s.wg.Done()
+	
+	ticker := s.clock.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C():
+			s.monitor.LogMetrics()
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// GracefulShutdown gracefully shuts down the server
+func (s *HighPerformanceServer) GracefulShutdown() {
+	log.Println("Initiating graceful shutdown...")
+	
+	// Stop advertising readiness before anything is torn down
+	atomic.StoreInt32(&s.ready, 0)
+	if atomic.LoadInt32(&s.restarting) == 1 {
+		// Same PID comes back with the new binary and sends READY=1 again
+		sdNotify(SD_RELOADING)
+	} else {
+		sdNotify(SD_STOPPING)
+	}
+	
+	// Interrupted collections stay resumable instead of failing
+	if interrupted := s.collectionProcessor.Shutdown(); len(interrupted) > 0 {
+		log.Printf("Interrupted %d collection(s), they will resume on next start: %v", len(interrupted), interrupted)
+	}
+	
+	// Cancel context to stop all goroutines
+	s.cancel()
+	
+	// Shutdown HTTP server
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		log.Printf("HTTP server shutdown error: %v", err)
+	}
+	
+	// Close components in proper order
+	log.Println("Stopping collection processor...")
+	if err := s.collectionProcessor.Stop(); err != nil {
+		log.Printf("Collection processor shutdown error: %v", err)
+	}
+	
+	log.Println("Stopping worker pool...")
+	if err := s.workerPool.Stop(); err != nil {
+		log.Printf("Worker pool shutdown error: %v", err)
+	}
+	
+	log.Println("Closing remaining components...")
+	s.batchUploader.Close()
+	s.discoverer.Close()
+	s.plugins.Close()
+	s.wsManager.Close()
+	s.monitor.Close()
+	
+	// Wait for all goroutines to finish
+	s.wg.Wait()
+	
+	log.Println("Graceful shutdown completed")
+}
+
+
+// baseConfig returns the built-in defaults (overridden by config profiles and env vars)
+func baseConfig() *ServerConfig {
+	return &ServerConfig{
+		MaxConnections:   DEFAULT_MAX_CONNECTIONS,
+		MaxConcurrentCollections: DEFAULT_MAX_CONCURRENT_COLLECTIONS,
+		Port:             SERVER_PORT,
+		LibraryRoot:      LIBRARY_ROOT,
+		DataDir:          DEFAULT_DATA_DIR,
+		EnableMetrics:    true,
+		LogLevel:         "INFO",
+	}
+}
+
+// Global start time for uptime calculation
+var startTime = time.Now()
+
+// version is compared against GitHub release tags by check_update;
+// release builds set it with -ldflags "-X main.version=<tag>"
+var version = "2.0.0"
+
+// main function with graceful shutdown
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	
+	// Subcommands: "service install|uninstall" and "top"
+	if len(os.Args) > 1 && os.Args[1] == "service" {
+		if err := runServiceCommand(os.Args[2:]); err != nil {
+			log.Fatalf("Service error: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "top" {
+		if err := runTopCommand(os.Args[2:]); err != nil {
+			log.Fatalf("top: %v", err)
+		}
+		return
+	}
+	
+	safeMode := flag.Bool("safe-mode", false, "start with uploads disabled to inspect state after an incident")
+	readOnly := flag.Bool("read-only", false, "serve a synced data directory as a public mirror: JSONs, feeds, stats and search only, nothing is written")
+	offlineDB := flag.String("offline-db", "", "path to an AniList/MangaDex metadata dump (JSON or JSON Lines) for offline mode")
+	configPath := flag.String("config", DEFAULT_CONFIG_FILE, "path to the configuration file with named profiles")
+	profile := flag.String("profile", "", "configuration profile to use (overrides GO_UPLOAD_PROFILE and defaultProfile)")
+	resume := flag.Bool("resume", false, "resume collections interrupted by a previous shutdown or crash")
+	fastTimers := flag.Bool("fast-timers", false, "debug: run metrics, JSON polling and rate limiter timers 10x faster")
+	dataDir := flag.String("data-dir", "", "directory holding all on-disk state (JSON output, job state, caches, spool)")
+	doctor := flag.Bool("doctor", false, "check paths, tokens and host credentials (dry-run calls), print fixes and exit")
+	flag.Parse()
+	
+	// Load configuration
+	config, err := loadConfig(*configPath, *profile)
+	if err != nil {
+		log.Fatalf("Configuration error: %v", err)
+	}
+	if err := setLogLevel(config.LogLevel); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+	if config.Profile != "" {
+		log.Printf("Using configuration profile %q from %s", config.Profile, *configPath)
+	}
+	config.SafeMode = *safeMode
+	if *readOnly {
+		config.ReadOnly = true
+	}
+	config.OfflineDBPath = *offlineDB
+	if *dataDir != "" {
+		config.DataDir = *dataDir
+	}
+	if *resume {
+		config.ResumeOnStart = true
+	}
+	if *fastTimers {
+		config.FastTimers = true
+	}
+	
+	if *doctor {
+		if err := runDoctorCommand(config, *configPath); err != nil {
+			fmt.Fprintf(os.Stderr, "doctor: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	
+	// Refuse to start with a configuration that would only fail later, mid-upload;
+	// safe mode and read-only mirrors start anyway since uploads are disabled there
+	if report := runDoctor(context.Background(), config, false); report.count(doctorFatal) > 0 {
+		report.print(os.Stderr, doctorFatal)
+		if !config.SafeMode && !config.ReadOnly {
+			log.Fatalf("Configuration error: fix the problems above (run with --doctor for a full check)")
+		}
+		log.Printf("⚠️ Starting with uploads disabled despite %d configuration problem(s)", report.count(doctorFatal))
+	}
+	
+	// Create and configure server
+	server := NewHighPerformanceServer(config)
+	
+	// Setup graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	
+	// Start server in goroutine
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Start()
+	}()
+	
+	// Wait for shutdown signal or server error
+	select {
+	case sig := <-sigChan:
+		log.Printf("Received signal: %v", sig)
+		server.GracefulShutdown()
+	case executable := <-server.restartRequested:
+		log.Printf("Restarting into the updated binary %s...", executable)
+		server.GracefulShutdown()
+		if err := restartProcess(executable); err != nil {
+			// A failing exit lets the supervisor (systemd Restart=on-failure) start the new binary
+			log.Fatalf("Restart failed: %v", err)
+		}
+	case err := <-serverErr:
+		if err != nil && err != http.ErrServerClosed {
+			log.Printf("Server error: %v", err)
+		}
+	}
+}
+
+// restartProcess starts the updated binary with the same flags; --resume is added so
+// collections interrupted by the restart continue where they stopped
+func restartProcess(executable string) error {
+	args := os.Args[1:]
+	resume := false
+	for _, arg := range args {
+		if arg == "-resume" || arg == "--resume" || strings.HasPrefix(arg, "-resume=") || strings.HasPrefix(arg, "--resume=") {
+			resume = true
+		}
+	}
+	if !resume {
+		args = append([]string{"--resume"}, args...)
+	}
+	return selfupdate.Restart(executable, args)
+}
+
+// Utility functions
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// handleAniListMetrics provides performance metrics for the AniList integration
+func (s *HighPerformanceServer) handleAniListMetrics(w http.ResponseWriter, r *http.Request) {
+	if s.anilistService == nil {
+		http.Error(w, "AniList service not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	
+	// Get metrics from AniList service
+	metrics := s.anilistService.GetPerformanceMetrics()
+	
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	
+	if err := json.NewEncoder(w).Encode(metrics); err != nil {
+		log.Printf("Error encoding AniList metrics: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleAniListHealth provides health status for the AniList integration
+func (s *HighPerformanceServer) handleAniListHealth(w http.ResponseWriter, r *http.Request) {
+	if s.anilistService == nil {
+		http.Error(w, "AniList service not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	
+	// Get health status from AniList service
+	status := s.anilistService.GetServiceStatus()
+	
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	
+	// Set HTTP status code based on health
+	if healthy, ok := status["healthy"].(bool); ok && !healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Printf("Error encoding AniList health status: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleCover serves cover images cached by the AniList image loader
+func (s *HighPerformanceServer) handleCover(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.anilistService == nil {
+		http.Error(w, "AniList service not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	
+	name := strings.TrimPrefix(r.URL.Path, anilist.CoverURLPrefix)
+	localPath, ok := s.anilistService.CoverFilePath(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	
+	// File names are derived from the source URL hash, so content never changes
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", `"`+strings.TrimSuffix(name, filepath.Ext(name))+`"`)
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	http.ServeFile(w, r, localPath)
+}
+
+// handleFeed serves the RSS feed of the library, or of one manga under /feed/<manga>.xml
+func (s *HighPerformanceServer) handleFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	
+	var body []byte
+	var updated time.Time
+	var err error
+	if name := strings.TrimPrefix(r.URL.Path, "/feed/"); name != r.URL.Path {
+		if !strings.HasSuffix(name, ".xml") {
+			http.NotFound(w, r)
+			return
+		}
+		body, updated, err = s.feedGenerator.Manga(strings.TrimSuffix(name, ".xml"))
+	} else {
+		body, updated, err = s.feedGenerator.Library()
+	}
+	if errors.Is(err, feed.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("⚠️ Failed to build RSS feed %s: %v", r.URL.Path, err)
+		http.Error(w, "Failed to build feed", http.StatusInternalServerError)
+		return
+	}
+	
+	// Feed readers and bots poll often; Last-Modified lets them get 304 responses
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	http.ServeContent(w, r, "", updated, bytes.NewReader(body))
+}
+
+// handleCatalogIndex serves the aggregate index.json listing every series in the library
+func (s *HighPerformanceServer) handleCatalogIndex(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	
+	body, updated, err := s.catalogIndex.JSON()
+	if err != nil {
+		log.Printf("⚠️ Failed to build catalog index: %v", err)
+		http.Error(w, "Failed to build catalog index", http.StatusInternalServerError)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("ETag", contentETag(body))
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	http.ServeContent(w, r, "", updated, bytes.NewReader(body))
+}
+
+// handleMangaJSON serves a generated manga JSON. Readers poll these for new chapters:
+// ETag and Last-Modified let them revalidate and get 304 while the file is unchanged.
+func (s *HighPerformanceServer) handleMangaJSON(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	
+	// Only top-level JSONs: no subpaths, hidden files or the archive
+	name := strings.TrimPrefix(r.URL.Path, "/json/")
+	if !strings.HasSuffix(name, ".json") || name != filepath.Base(name) || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		http.NotFound(w, r)
+		return
+	}
+	jsonPath := filepath.Join(s.config.MetadataOutput, name)
+	
+	info, err := os.Stat(jsonPath)
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		log.Printf("⚠️ Failed to read %s: %v", jsonPath, err)
+		http.Error(w, "Failed to read JSON", http.StatusInternalServerError)
+		return
+	}
+	
+	// no-cache: clients may keep the file but must revalidate before using it
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("ETag", contentETag(data))
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Expose-Headers", "ETag, Last-Modified")
+	http.ServeContent(w, r, name, info.ModTime(), bytes.NewReader(data))
+}
+
+// contentETag derives a strong ETag from the content, so rewriting a JSON without
+// changes (e.g. a metadata save) doesn't invalidate the readers' copies
+func contentETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// =============================================
+//         NOTIFICATION CENTER
+// =============================================
+
+// notify stores an important event and pushes it to every connected client; clients
+// that were away read it later with get_notifications
+func (s *HighPerformanceServer) notify(kind, level, key string, data map[string]interface{}, args ...string) {
+	notification, err := s.notifications.Add(notifications.Notification{
+		Kind:  kind,
+		Level: level,
+		Key:   key,
+		Args:  args,
+		Data:  data,
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to store notification: %v", err)
+	}
+	notification.Message = notificationMessage(i18n.DefaultLocale, notification)
+	
+	s.wsManager.Broadcast(wsmanager.Response{
+		Status: "notification",
+		Data: map[string]interface{}{
+			"notification": notification,
+			"unread":       s.notifications.Unread(),
+		},
+	})
+}
+
+// notificationMessage renders the text of a notification in the reader's language
+func notificationMessage(locale i18n.Locale, notification notifications.Notification) string {
+	args := make([]interface{}, len(notification.Args))
+	for i, arg := range notification.Args {
+		args[i] = arg
+	}
+	return i18n.T(locale, notification.Key, args...)
+}
+
+// handleBatchCompleted notifies batches that finished with failed files
+func (s *HighPerformanceServer) handleBatchCompleted(batchID string, completed, failed, total int64) {
+	go s.writeChecksums(batchID)
+	if failed == 0 {
+		return
+	}
+	s.notify(notifications.KindBatchFailed, notifications.LevelError, i18n.MsgNotifyBatchFailed,
+		map[string]interface{}{"batchId": batchID, "completed": completed, "failed": failed, "total": total},
+		batchID, strconv.FormatInt(failed, 10), strconv.FormatInt(total, 10))
+}
+
+// notifyCollection notifies a finished or failed collection
+func (s *HighPerformanceServer) notifyCollection(name, collectionID string, err error) {
+	data := map[string]interface{}{"collection": name, "collectionId": collectionID}
+	if err != nil {
+		data["error"] = err.Error()
+		s.notify(notifications.KindCollectionFailed, notifications.LevelError, i18n.MsgNotifyCollectionFailed, data, name, err.Error())
+		return
+	}
+	s.notify(notifications.KindCollectionCompleted, notifications.LevelInfo, i18n.MsgNotifyCollectionCompleted, data, name)
+}
+
+// notifyTakedowns notifies hosts where published pages went missing since the last
+// mirror check, so a takedown is reported once rather than on every check
+func (s *HighPerformanceServer) notifyTakedowns(summary *mirrorhealth.Summary) {
+	if summary == nil || summary.Canceled {
+		return
+	}
+	for _, host := range summary.Ranking {
+		score := summary.Hosts[host]
+		missing := score.Checked - score.Available
+		
+		s.takedownsMu.Lock()
+		previous := s.takedownsSeen[host]
+		s.takedownsSeen[host] = missing
+		s.takedownsMu.Unlock()
+		
+		if missing > previous {
+			s.notify(notifications.KindTakedown, notifications.LevelWarning, i18n.MsgNotifyTakedown,
+				map[string]interface{}{"host": host, "missing": missing, "checked": score.Checked, "lastError": score.LastError},
+				strconv.Itoa(missing), strconv.Itoa(score.Checked), host)
+		}
+	}
+}
+
+// handleGetNotifications lists the notifications, newest first, in the client's language
+func (s *HighPerformanceServer) handleGetNotifications(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid notifications request: %v", err)
+	}
+	
+	list, unread := s.notifications.List(req.UnreadOnly, req.Limit)
+	locale := connLocale(conn)
+	for i := range list {
+		list[i].Message = notificationMessage(locale, list[i])
+	}
+	return conn.Send(wsmanager.Response{
+		Status:    "notifications",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"notifications": list,
+			"unread":        unread,
+		},
+	})
+}
+
+// handleMarkRead marks notifications as read (ids, or all: true); every client gets the new unread count
+func (s *HighPerformanceServer) handleMarkRead(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid mark read request: %v", err)
+	}
+	if len(req.IDs) == 0 && !req.All {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "ids"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	ids := req.IDs
+	if req.All {
+		ids = nil
+	}
+	marked, err := s.notifications.MarkRead(ids)
+	if err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInternal, "failed to mark notifications as read: %v", err)
+	}
+	
+	s.wsManager.Broadcast(wsmanager.Response{
+		Status:    "notifications_read",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"ids":    req.IDs,
+			"all":    req.All,
+			"marked": marked,
+			"unread": s.notifications.Unread(),
+		},
+	})
+	return nil
+}
+
+// =============================================
+//         ANILIST CONFIGURATION HANDLERS
+// =============================================
+
+// handleGetAniListConfig retorna as configurações atuais da AniList
+func (s *HighPerformanceServer) handleGetAniListConfig(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	log.Printf("🔧 handleGetAniListConfig: ===== HANDLER CHAMADO =====")
+	log.Printf("🔧 handleGetAniListConfig: Requisição recebida - RequestID: %s", msg.RequestID)
+	log.Printf("🔧 handleGetAniListConfig: Message Data: %+v", msg.Data)
+	log.Printf("🔧 handleGetAniListConfig: Message Action: %s", msg.Action)
+	
+	if s.anilistService == nil {
+		log.Printf("❌ handleGetAniListConfig: anilistService é nil")
+		response := wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgAniListNotInitialized),
+			ErrorCode: wsmanager.ErrServiceUnavailable,
+			RequestID: msg.RequestID,
+		}
+		return conn.Send(response)
+	}
+	
+	config := s.anilistService.GetConfig()
+	log.Printf("✅ handleGetAniListConfig: Configuração obtida: %+v", config)
+	
+	response := wsmanager.Response{
+		Status:    "config_retrieved",
+		Data:      config,
+		RequestID: msg.RequestID,
+	}
+	
+	log.Printf("📤 handleGetAniListConfig: Enviando resposta - Status: %s", response.Status)
+	return conn.Send(response)
+}
+
+// handleUpdateAniListConfig atualiza as configurações da AniList
+func (s *HighPerformanceServer) handleUpdateAniListConfig(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	log.Printf("🔧 handleUpdateAniListConfig: ===== HANDLER CHAMADO =====")
+	log.Printf("🔧 handleUpdateAniListConfig: Requisição recebida - RequestID: %s", msg.RequestID)
+	
+	// Verificar se o AniListService está disponível
+	if s.anilistService == nil {
+		log.Printf("❌ handleUpdateAniListConfig: anilistService é nil")
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgAniListNotInitialized),
+			ErrorCode: wsmanager.ErrServiceUnavailable,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	// Extrair configuração dos dados da mensagem de forma mais simples
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgInvalidRequestData),
+			ErrorCode: wsmanager.ErrInvalidRequest,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	configData, ok := data["config"].(map[string]interface{})
+	if !ok {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgConfigMissing),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	// Obter configuração atual e aplicar atualizações
+	currentConfig := s.anilistService.GetConfig()
+	if currentConfig == nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgConfigGetFailed),
+			ErrorCode: wsmanager.ErrConfigFailed,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	// Aplicar atualizações campo por campo de forma segura
+	if enabled, ok := configData["enabled"].(bool); ok {
+		currentConfig.Enabled = enabled
+	}
+	if langPref, ok := configData["language_preference"].(string); ok {
+		currentConfig.LanguagePreference = anilist.LanguagePreference(langPref)
+	}
+	if fillMode, ok := configData["fill_mode"].(string); ok {
+		currentConfig.FillMode = anilist.FillMode(fillMode)
+	}
+	if autoSearch, ok := configData["auto_search"].(bool); ok {
+		currentConfig.AutoSearch = autoSearch
+	}
+	if cacheEnabled, ok := configData["cache_enabled"].(bool); ok {
+		currentConfig.CacheEnabled = cacheEnabled
+	}
+	if preferAniList, ok := configData["prefer_anilist"].(bool); ok {
+		currentConfig.PreferAniList = preferAniList
+	}
+	if offlineMode, ok := configData["offline_mode"].(bool); ok {
+		currentConfig.OfflineMode = offlineMode
+	}
+	
+	// Atualizar configuração
+	if err := s.anilistService.UpdateConfig(currentConfig); err != nil {
+		log.Printf("❌ handleUpdateAniListConfig: Erro ao atualizar: %v", err)
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgConfigUpdateFailed, err),
+			ErrorCode: wsmanager.ErrConfigFailed,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	// Retornar configuração atualizada
+	updatedConfig := s.anilistService.GetConfig()
+	log.Printf("✅ handleUpdateAniListConfig: Configuração atualizada com sucesso")
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "config_updated",
+		Data:      updatedConfig,
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleResetAniListConfig restaura as configurações padrão
+func (s *HighPerformanceServer) handleResetAniListConfig(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	if err := s.anilistService.ResetConfig(); err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgConfigResetFailed, err),
+			ErrorCode: wsmanager.ErrConfigFailed,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	// Retornar configuração resetada
+	resetConfig := s.anilistService.GetConfig()
+	return conn.Send(wsmanager.Response{
+		Status:    "config_reset",
+		Data:      resetConfig,
+		RequestID: msg.RequestID,
+	})
+}
+
+// aniListProfileRequest decodes a save/apply/delete_anilist_profile request and checks the preset name
+func (s *HighPerformanceServer) aniListProfileRequest(conn *wsmanager.Connection, msg wsmanager.Message) (string, bool, error) {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return "", false, wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid AniList profile request: %v", err)
+	}
+	
+	if s.anilistService == nil {
+		return "", false, conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgAniListNotInitialized),
+			ErrorCode: wsmanager.ErrServiceUnavailable,
+			RequestID: msg.RequestID,
+		})
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return "", false, conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "name"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: msg.RequestID,
+		})
+	}
+	return req.Name, true, nil
+}
+
+// sendAniListProfileError reports a failed AniList profile operation
+func sendAniListProfileError(conn *wsmanager.Connection, msg wsmanager.Message, err error) error {
+	return conn.Send(wsmanager.Response{
+		Status:    "error",
+		Error:     i18n.T(connLocale(conn), i18n.MsgAniListProfileFailed, err),
+		ErrorCode: wsmanager.ErrConfigFailed,
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleSaveAniListProfile saves the current AniList configuration as a named preset
+func (s *HighPerformanceServer) handleSaveAniListProfile(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	name, ok, err := s.aniListProfileRequest(conn, msg)
+	if !ok {
+		return err
+	}
+	
+	profile, err := s.anilistService.SaveConfigProfile(name)
+	if err != nil {
+		return sendAniListProfileError(conn, msg, err)
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "anilist_profile_saved",
+		Data:      profile,
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleApplyAniListProfile replaces the AniList configuration with a saved preset
+func (s *HighPerformanceServer) handleApplyAniListProfile(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	name, ok, err := s.aniListProfileRequest(conn, msg)
+	if !ok {
+		return err
+	}
+	
+	config, err := s.anilistService.ApplyConfigProfile(name)
+	if err != nil {
+		return sendAniListProfileError(conn, msg, err)
+	}
+	log.Printf("🔧 AniList profile %q applied (language=%s, fill mode=%s)", name, config.LanguagePreference, config.FillMode)
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "anilist_profile_applied",
+		Data:      map[string]interface{}{"name": strings.TrimSpace(name), "config": config},
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleListAniListProfiles lists the saved AniList configuration presets
+func (s *HighPerformanceServer) handleListAniListProfiles(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	if s.anilistService == nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgAniListNotInitialized),
+			ErrorCode: wsmanager.ErrServiceUnavailable,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	profiles, err := s.anilistService.ListConfigProfiles()
+	if err != nil {
+		return sendAniListProfileError(conn, msg, err)
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "anilist_profiles",
+		Data:      map[string]interface{}{"profiles": profiles, "current": s.anilistService.GetConfig()},
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleDeleteAniListProfile removes a saved AniList configuration preset
+func (s *HighPerformanceServer) handleDeleteAniListProfile(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	name, ok, err := s.aniListProfileRequest(conn, msg)
+	if !ok {
+		return err
+	}
+	
+	if err := s.anilistService.DeleteConfigProfile(name); err != nil {
+		return sendAniListProfileError(conn, msg, err)
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "anilist_profile_deleted",
+		Data:      map[string]interface{}{"name": strings.TrimSpace(name)},
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleImportOfflineMetadata baixa (URL) ou copia (caminho local) um dump de metadados
+// para o banco offline usado quando a AniList está inacessível
+func (s *HighPerformanceServer) handleImportOfflineMetadata(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid offline import request: %v", err)
+	}
+	
+	if req.DumpSource == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "dumpSource"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	go func() {
+		ctx, cancel := context.WithTimeout(s.ctx, 30*time.Minute)
+		defer cancel()
+		
+		if err := s.anilistService.ImportOfflineDump(ctx, req.DumpSource); err != nil {
+			log.Printf("❌ Offline metadata import failed: %v", err)
+			safeSend(conn, wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgOfflineImportFailed, err),
+				ErrorCode: wsmanager.ErrAniListFailed,
+				RequestID: msg.RequestID,
+			})
+			return
+		}
+		
+		safeSend(conn, wsmanager.Response{
+			Status:    "offline_metadata_imported",
+			Data:      s.anilistService.GetOfflineStats(),
+			RequestID: msg.RequestID,
+		})
+	}()
+	
+	return nil
+}
+
+// handleAutoFillLibraryMetadata starts the job that fills missing author/cover/description
+// in every library JSON from AniList, applying only high-confidence matches
+func (s *HighPerformanceServer) handleAutoFillLibraryMetadata(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid auto-fill request: %v", err)
+	}
+	
+	job, err := s.autoFiller.Start(req.Resume,
+		func(progress autofill.Progress) {
+			s.wsManager.Broadcast(wsmanager.Response{
+				Status: "auto_fill_progress",
+				Data:   progress,
+			})
+		},
+		func(job *autofill.Job) {
+			log.Printf("📚 Auto-fill %s finished: %s (%d applied, %d for review, %d failed)",
+				job.ID, job.Status, len(job.Applied), len(job.Review), len(job.Failed))
+			s.wsManager.Broadcast(wsmanager.Response{
+				Status: "auto_fill_complete",
+				Data:   job,
+			})
+		})
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgAutoFillRunning),
+			ErrorCode: wsmanager.ErrJobRunning,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	log.Printf("📚 Auto-fill %s started (resume: %v)", job.ID, req.Resume)
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "auto_fill_started",
+		Data:      job,
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleGetAutoFillStatus returns the current (or last) auto-fill job, including the review list
+func (s *HighPerformanceServer) handleGetAutoFillStatus(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	return conn.Send(wsmanager.Response{
+		Status:    "auto_fill_status",
+		Data:      s.autoFiller.Status(),
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleCancelAutoFill stops the running auto-fill job; its state is kept for resume
+func (s *HighPerformanceServer) handleCancelAutoFill(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	return conn.Send(wsmanager.Response{
+		Status:    "auto_fill_cancel",
+		Data:      map[string]interface{}{"canceled": s.autoFiller.Cancel()},
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleRefreshMangaStatus starts a status refresh of every manga linked to AniList
+func (s *HighPerformanceServer) handleRefreshMangaStatus(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	if err := s.statusRefresher.Trigger(s.ctx, s.broadcastStatusSummary); err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgStatusRefreshRunning),
+			ErrorCode: wsmanager.ErrJobRunning,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "manga_status_refresh_started",
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleGetMangaStatusSummary returns the summary of the last status refresh (nil if none ran)
+func (s *HighPerformanceServer) handleGetMangaStatusSummary(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	return conn.Send(wsmanager.Response{
+		Status:    "manga_status_summary",
+		Data:      s.statusRefresher.LastSummary(),
+		RequestID: msg.RequestID,
+	})
+}
+
+// broadcastStatusSummary notifies every client of the status changes found by a refresh
+func (s *HighPerformanceServer) broadcastStatusSummary(summary *statussync.Summary) {
+	for _, change := range summary.Changes {
+		log.Printf("📰 Status of %s changed: %q → %q (applied: %v)", change.MangaID, change.OldStatus, change.NewStatus, change.Applied)
+	}
+	log.Printf("📰 Status refresh finished: %d checked, %d changed, %d failed",
+		summary.Checked, len(summary.Changes), len(summary.Failed))
+	
+	s.wsManager.Broadcast(wsmanager.Response{
+		Status: "manga_status_summary",
+		Data:   summary,
+	})
+}
+
+// handleCheckMirrorHealth starts an availability check of every host holding mirrored chapters
+func (s *HighPerformanceServer) handleCheckMirrorHealth(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	if err := s.mirrorChecker.Trigger(s.ctx, s.broadcastMirrorHealth); err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgMirrorHealthRunning),
+			ErrorCode: wsmanager.ErrJobRunning,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "mirror_health_check_started",
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleGetMirrorHealth returns the host scores of the last mirror check (nil if none ran)
+func (s *HighPerformanceServer) handleGetMirrorHealth(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	return conn.Send(wsmanager.Response{
+		Status:    "mirror_health_summary",
+		Data:      s.mirrorChecker.LastSummary(),
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleApplyMirrorFailover rewrites mirrored JSONs (one manga, or all when none is given)
+// so the primary group points at the healthiest host, using the scores of the last check.
+// With dropBelow, mirrors scoring below it are dropped (kept in the JSON trash).
+func (s *HighPerformanceServer) handleApplyMirrorFailover(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid mirror failover request: %v", err)
+	}
+	
+	summary := s.mirrorChecker.LastSummary()
+	if summary == nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgMirrorHealthMissing),
+			ErrorCode: wsmanager.ErrInvalidRequest,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	var jsonPaths []string
+	if req.Manga != "" {
+		mangaID := mangaid.Normalize(req.Manga)
+		jsonPaths = []string{mangaid.Path(s.config.MetadataOutput, mangaID)}
+	} else {
+		jsonPaths, _ = filepath.Glob(filepath.Join(s.config.MetadataOutput, "*.json"))
+	}
+	
+	// Same lock as save_metadata so the rewrite does not race with metadata edits
+	s.metadataMu.Lock()
+	defer s.metadataMu.Unlock()
+	
+	health := summary.Health()
+	results := []*metadata.MirrorFailoverResult{}
+	failed := make(map[string]string)
+	for _, jsonPath := range jsonPaths {
+		result, err := s.jsonGenerator.FailoverMirrors(jsonPath, health, req.DropBelow)
+		if err != nil {
+			if req.Manga != "" {
+				return conn.Send(wsmanager.Response{
+					Status:    "error",
+					Error:     i18n.T(connLocale(conn), i18n.MsgMirrorFailoverFailed, req.Manga, err),
+					ErrorCode: wsmanager.ErrInvalidRequest,
+					RequestID: req.RequestID,
+				})
+			}
+			failed[metadata.LockKey(jsonPath)] = err.Error()
+			continue
+		}
+		if len(result.Changes) > 0 {
+			log.Printf("🪞 Mirror failover rewrote %d chapter group(s) of %s", len(result.Changes), result.MangaID)
+			results = append(results, result)
+		}
+	}
+	
+	// Clients showing these manga need the new page URLs
+	s.wsManager.Broadcast(wsmanager.Response{
+		Status:    "mirror_failover_applied",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"updated":   results,
+			"failed":    failed,
+			"dropBelow": req.DropBelow,
+			"checkedAt": summary.FinishedAt,
+		},
+	})
+	
+	return nil
+}
+
+// broadcastMirrorHealth notifies every client of the host scores found by a mirror check
+func (s *HighPerformanceServer) broadcastMirrorHealth(summary *mirrorhealth.Summary) {
+	for _, host := range summary.Ranking {
+		score := summary.Hosts[host]
+		log.Printf("🪞 Mirror host %s: %d/%d available (%.0f%%)", host, score.Available, score.Checked, score.Score*100)
+	}
+	s.notifyTakedowns(summary)
+	
+	s.wsManager.Broadcast(wsmanager.Response{
+		Status: "mirror_health_summary",
+		Data:   summary,
+	})
+}
+
+// handleRefreshSignedURLs re-signs, in the background, every pre-signed URL close to expiry
+func (s *HighPerformanceServer) handleRefreshSignedURLs(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	if !s.urlRefresher.Enabled() {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgSignedURLsDisabled),
+			ErrorCode: wsmanager.ErrInvalidRequest,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	if err := s.urlRefresher.Trigger(s.ctx, s.broadcastSignedURLSummary); err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgSignedURLsRunning),
+			ErrorCode: wsmanager.ErrJobRunning,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "signed_url_refresh_started",
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleGetSignedURLStatus returns the summary of the last signed URL refresh (nil if none ran)
+func (s *HighPerformanceServer) handleGetSignedURLStatus(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	return conn.Send(wsmanager.Response{
+		Status:    "signed_url_summary",
+		Data:      s.urlRefresher.LastSummary(),
+		RequestID: msg.RequestID,
+	})
+}
+
+// broadcastSignedURLSummary notifies every client after a refresh; updated manga carry new page URLs
+func (s *HighPerformanceServer) broadcastSignedURLSummary(summary *signedurls.Summary) {
+	log.Printf("🔏 Signed URL refresh: %d of %d URL(s) renewed in %d JSON(s), %d failed",
+		summary.Renewed, summary.Signed, len(summary.Updated), len(summary.Failed))
+	
+	s.wsManager.Broadcast(wsmanager.Response{
+		Status: "signed_url_summary",
+		Data:   summary,
+	})
+}
+
+// =============================================
+//         GITHUB INTEGRATION HANDLERS
+// =============================================
+
+// handleGitHubFolders lists folders in a GitHub repository
+func (s *HighPerformanceServer) handleGitHubFolders(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	// Log received data for debugging
+	log.Printf("🔍 GitHub folders request: %+v", msg.Data)
+
+	// Extract data directly from msg.Data map
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgGitHubInvalidFolders),
+			ErrorCode: wsmanager.ErrInvalidRequest,
+			RequestID: msg.RequestID,
+		})
+	}
+
+	token, _ := data["token"].(string)
+	repo, _ := data["repo"].(string)
+	branch, _ := data["branch"].(string)
+	maxDepth := 3 // Default depth for recursion
+	
+	// Check if depth parameter was provided
+	if d, ok := data["maxDepth"].(float64); ok {
+		maxDepth = int(d)
+	}
+
+	if token == "" || repo == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error", 
+			Error:     i18n.T(connLocale(conn), i18n.MsgGitHubCredentialsMissing),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: msg.RequestID,
+		})
+	}
+
+	if branch == "" {
+		branch = "main"
+	}
+
+	go func() {
+		log.Printf("Starting GitHub folders listing for repo: %s", repo)
+		
+		// Send progress update
+		progressResponse := wsmanager.Response{
+			Status:    "github_folders_progress",
+			RequestID: msg.RequestID,
+			Progress: &wsmanager.Progress{
+				Current:    0,
+				Total:      1,
+				Percentage: 0,
+				Stage:      "listing_folders",
+			},
+		}
+		safeSend(conn, progressResponse)
+
+		// List folders using GitHub service (recursively)
+		folders, err := s.githubService.ListFoldersRecursively(token, repo, branch, maxDepth)
+		if err != nil {
+			log.Printf("GitHub folders error: %v", err)
+			response := wsmanager.Response{
+				Status:    "github_error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgGitHubListFailed, err),
+				ErrorCode: wsmanager.ErrGitHubFailed,
+				RequestID: msg.RequestID,
+				Data: map[string]interface{}{
+					"error_type": "folders_list_failed",
+					"repo":       repo,
+					"branch":     branch,
+				},
+			}
+			safeSend(conn, response)
+			return
+		}
+
+		log.Printf("Successfully listed %d folders from GitHub repo %s", len(folders), repo)
+
+		// Send success response
+		response := wsmanager.Response{
+			Status:    "github_folders_complete", 
+			RequestID: msg.RequestID,
+			Data: map[string]interface{}{
+				"folders":     folders,
+				"folderCount": len(folders),
+				"repo":        repo,
+				"branch":      branch,
+			},
+		}
+		log.Printf("📤 Sending GitHub folders response: %+v", response)
+		safeSend(conn, response)
+	}()
+
+	return nil
+}
+
+// handleGitHubUpload uploads JSON files to GitHub repository
+func (s *HighPerformanceServer) handleGitHubUpload(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	if s.uploadsHalted() {
+		return s.sendUploadsDisabled(conn, msg.RequestID)
+	}
+	
+	// Log received data for debugging
+	log.Printf("🔍 GitHub upload request: %+v", msg.Data)
+
+	// Extract data directly from msg.Data map
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgGitHubInvalidUpload),
+			ErrorCode: wsmanager.ErrInvalidRequest,
+			RequestID: msg.RequestID,
+		})
+	}
+
+	// Extract GitHub settings - support both direct fields and githubSettings object
+	var token, repo, branch, folder, updateMode string
+	var selectedWorks []string
+	var syncOptions github.SyncOptions
+
+	// Try direct fields first
+	token, _ = data["token"].(string)
+	repo, _ = data["repo"].(string)
+	branch, _ = data["branch"].(string)
+	folder, _ = data["folder"].(string)
+	updateMode, _ = data["updateMode"].(string)
+
+	// If direct fields not found, try githubSettings
+	if token == "" {
+		if githubSettings, ok := data["githubSettings"].(map[string]interface{}); ok {
+			if t, ok := githubSettings["token"].(string); ok {
+				token = t
+			}
+			if r, ok := githubSettings["repo"].(string); ok {
+				repo = r
+			}
+			if b, ok := githubSettings["branch"].(string); ok {
+				branch = b
+			}
+			if f, ok := githubSettings["folder"].(string); ok {
+				folder = f
+			}
+			if u, ok := githubSettings["updateMode"].(string); ok {
+				updateMode = u
+			}
+		}
+	}
+
+	// Commit message template and changelog: request fields win over saved settings
+	for _, source := range []interface{}{data["githubSettings"], data} {
+		settings, ok := source.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if t, ok := settings["commitMessage"].(string); ok && t != "" {
+			syncOptions.MessageTemplate = t
+		}
+		if c, ok := settings["changelog"].(bool); ok {
+			syncOptions.Changelog = c
+		}
+		if p, ok := settings["changelogPath"].(string); ok && p != "" {
+			syncOptions.ChangelogPath = p
+		}
+	}
+
+	// Get selected works from request
+	if works, exists := data["selectedWorks"]; exists {
+		if worksList, ok := works.([]interface{}); ok {
+			for _, work := range worksList {
+				if workStr, ok := work.(string); ok {
+					selectedWorks = append(selectedWorks, workStr)
+				}
+			}
+		}
+	}
+
+	log.Printf("🔍 Parsed GitHub settings - Token: %s, Repo: %s, Branch: %s, Folder: %s, Works: %d", 
+		token[:10]+"...", repo, branch, folder, len(selectedWorks))
+
+	if token == "" || repo == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgGitHubCredentialsMissing),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: msg.RequestID,
+		})
+	}
+
+	// Without an explicit list, a filter selects the works server-side
+	// (e.g. {"updatedSince": <unix>} pushes everything changed since then)
+	jsonOutputDir := s.config.MetadataOutput
+	if jsonOutputDir == "" {
+		jsonOutputDir = "json"
+	}
+	rawFilter, hasFilter := data["filter"]
+	if len(selectedWorks) == 0 && hasFilter {
+		var filter metadata.SeriesFilter
+		filterData, _ := json.Marshal(rawFilter)
+		if err := json.Unmarshal(filterData, &filter); err != nil || filter.IsEmpty() {
+			if err == nil {
+				err = fmt.Errorf("no filter criteria given")
+			}
+			return conn.Send(wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgGitHubInvalidFilter, err),
+				ErrorCode: wsmanager.ErrInvalidRequest,
+				RequestID: msg.RequestID,
+			})
+		}
+		
+		works, err := metadata.SelectSeries(jsonOutputDir, filter)
+		if err != nil {
+			return conn.Send(wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgGitHubFilterFailed, err),
+				ErrorCode: wsmanager.ErrIO,
+				RequestID: msg.RequestID,
+			})
+		}
+		if len(works) == 0 {
+			return conn.Send(wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgGitHubFilterNoMatch),
+				ErrorCode: wsmanager.ErrJSONNotFound,
+				RequestID: msg.RequestID,
+				Data:      map[string]interface{}{"filter": filter},
+			})
+		}
+		selectedWorks = works
+		log.Printf("🔍 GitHub filter %+v selected %d works", filter, len(selectedWorks))
+	}
+
+	if len(selectedWorks) == 0 {
+		return conn.Send(wsmanager.Response{
+			Status:    "error", 
+			Error:     i18n.T(connLocale(conn), i18n.MsgGitHubNoWorksSelected),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: msg.RequestID,
+		})
+	}
+
+	if branch == "" {
+		branch = "main"
+	}
+
+	if updateMode == "" {
+		updateMode = "smart"
+	}
+
+	go func() {
+		log.Printf("Starting GitHub upload for %d JSON files to repo: %s", len(selectedWorks), repo)
+
+		// Send progress update
+		progressResponse := wsmanager.Response{
+			Status:    "github_upload_progress",
+			RequestID: msg.RequestID,
+			Progress: &wsmanager.Progress{
+				Current:    0,
+				Total:      len(selectedWorks),
+				Percentage: 0,
+				Stage:      "preparing_upload",
+			},
+		}
+		safeSend(conn, progressResponse)
+
+		// Collect JSON files to upload
+		jsonFiles := make(map[string]string)
+		var corrupt []metadata.CorruptFile
+		var syncedWorks []string
+		withheldChapters := make(map[string][]string)
+
+		for i, work := range selectedWorks {
+			// Progress update
+			progressResponse.Progress.Current = i
+			progressResponse.Progress.Percentage = int((float64(i) / float64(len(selectedWorks))) * 100)
+			progressResponse.Progress.Stage = fmt.Sprintf("reading_json_%d", i+1)
+			safeSend(conn, progressResponse)
+
+			// Same filename resolution as save_metadata and the JSON generator
+			jsonFilePath := mangaid.Path(jsonOutputDir, work)
+			jsonFileName := filepath.Base(jsonFilePath)
+			
+			// Archived series are never synced
+			if s.idRegistry.IsArchived(mangaid.Normalize(work)) {
+				log.Printf("📦 Skipping archived series %s", work)
+				continue
+			}
+
+			// A corrupted JSON is never pushed: it is repaired or quarantined and
+			// restored from the copy already published in the repository
+			s.metadataMu.Lock()
+			_, err := s.jsonQuarantine.Check(jsonFilePath)
+			s.metadataMu.Unlock()
+			if err != nil {
+				log.Printf("⚠️ %v", err)
+				if restoreErr := s.restoreFromGitHub(token, repo, branch, folder, jsonFileName); restoreErr != nil {
+					log.Printf("⚠️ Failed to restore %s from GitHub: %v", jsonFileName, restoreErr)
+					corrupt = append(corrupt, metadata.CorruptFile{File: jsonFileName, Action: metadata.CorruptQuarantined, Error: err.Error()})
+					continue
+				}
+				log.Printf("♻️ Restored %s from %s", jsonFileName, repo)
+				corrupt = append(corrupt, metadata.CorruptFile{File: jsonFileName, Action: metadata.CorruptRestored, Error: err.Error(), RestoredFrom: "github:" + repo})
+			}
+			
+			// Read JSON file
+			jsonContent, err := os.ReadFile(jsonFilePath)
+			if err != nil {
+				log.Printf("⚠️ Failed to read JSON file %s: %v", jsonFilePath, err)
+				// Continue with other files instead of failing completely
+				continue
+			}
+
+			// Chapters still in review stay out of the repository
+			jsonContent, withheld, err := s.reviewedJSON(work, jsonContent)
+			if err != nil {
+				log.Printf("⚠️ Failed to filter reviewed chapters of %s: %v", jsonFilePath, err)
+				continue
+			}
+			if len(withheld) > 0 {
+				withheldChapters[mangaid.Normalize(work)] = withheld
+				log.Printf("📝 Withholding %d chapter(s) of %s pending review", len(withheld), work)
+			}
+
+			jsonFiles[jsonFileName] = string(jsonContent)
+			syncedWorks = append(syncedWorks, work)
+			log.Printf("✅ Added JSON file: %s (%d bytes)", jsonFileName, len(jsonContent))
+		}
+
+		if len(jsonFiles) == 0 {
+			response := wsmanager.Response{
+				Status:    "github_error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgGitHubNoJSONFiles),
+				ErrorCode: wsmanager.ErrJSONNotFound,
+				RequestID: msg.RequestID,
+				Data:      map[string]interface{}{"corrupt": corrupt},
+			}
+			safeSend(conn, response)
+			return
+		}
+
+		log.Printf("📦 Prepared %d JSON files for GitHub upload", len(jsonFiles))
+
+		// Upload to GitHub
+		progressResponse.Progress.Stage = "uploading_to_github"
+		progressResponse.Progress.Percentage = 90
+		safeSend(conn, progressResponse)
+
+		// The catalog index goes with every sync so the repo always has an entry point
+		if index, _, err := s.catalogIndex.JSON(); err != nil {
+			log.Printf("⚠️ Failed to build catalog index: %v", err)
+		} else {
+			syncOptions.ExtraFiles = map[string]string{catalog.FileName: string(index)}
+		}
+		s.addChecksumFiles(&syncOptions, syncedWorks)
+		
+		// Unsigned JSONs are never pushed while signing is configured
+		err := s.signJSONFiles(&syncOptions, jsonFiles)
+		var syncResult *github.SyncResult
+		if err == nil {
+			syncResult, err = s.githubService.SyncJSONFiles(token, repo, branch, folder, jsonFiles, syncOptions)
+		}
+		if err != nil {
+			log.Printf("GitHub upload error: %v", err)
+			response := wsmanager.Response{
+				Status:    "github_error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgGitHubUploadFailed, err),
+				ErrorCode: wsmanager.ErrGitHubFailed,
+				RequestID: msg.RequestID,
+				Data: map[string]interface{}{
+					"error_type":    "upload_failed",
+					"repo":          repo,
+					"branch":        branch,
+					"folder":        folder,
+					"files_count":   len(jsonFiles),
+				},
+			}
+			safeSend(conn, response)
+			return
+		}
+
+		log.Printf("✅ Successfully synced %d JSON files to GitHub repo %s (%d changed, %d unchanged)", len(jsonFiles), repo, len(syncResult.Changes), syncResult.Unchanged)
+		reviewPublished := s.markReviewedPublished(syncedWorks)
+
+		// Send success response
+		response := wsmanager.Response{
+			Status:    "github_upload_complete",
+			RequestID: msg.RequestID,
+			Data: map[string]interface{}{
+				"commit":        syncResult.CommitResponse,
+				"uploadedCount": len(jsonFiles),
+				"changes":       syncResult.Changes,
+				"unchanged":     syncResult.Unchanged,
+				"extraUpdated":  syncResult.ExtraUpdated,
+				"changelogUpdated": syncResult.ChangelogUpdated,
+				"repo":          repo,
+				"branch":        branch,
+				"folder":        folder,
+				"updateMode":    updateMode,
+				"selectedWorks": selectedWorks,
+				"uploadedFiles": jsonFiles,
+				"corrupt":       corrupt,
+				"withheldChapters": withheldChapters,
+				"reviewPublished":  reviewPublished,
+			},
+		}
+		safeSend(conn, response)
+	}()
+
+	return nil
+}
+
+// handleGitHubBootstrap creates the repository, branch and folder (with index.json and
+// README) used by github_upload when they don't exist yet
+func (s *HighPerformanceServer) handleGitHubBootstrap(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid GitHub bootstrap request: %v", err)
+	}
+	
+	// Same settings object the frontend sends with github_upload
+	if req.Token == "" && req.GitHubSettings != nil {
+		req.Token, _ = req.GitHubSettings["token"].(string)
+		req.Repo, _ = req.GitHubSettings["repo"].(string)
+		req.Branch, _ = req.GitHubSettings["branch"].(string)
+		req.Folder, _ = req.GitHubSettings["folder"].(string)
+	}
+	if req.Token == "" || req.Repo == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgGitHubCredentialsMissing),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	go func() {
+		result, err := s.githubService.Bootstrap(req.Token, req.Repo, req.Branch, req.Folder, github.BootstrapOptions{Private: req.Private})
+		if err != nil {
+			log.Printf("GitHub bootstrap error: %v", err)
+			safeSend(conn, wsmanager.Response{
+				Status:    "github_error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgGitHubBootstrapFailed, err),
+				ErrorCode: wsmanager.ErrGitHubFailed,
+				RequestID: msg.RequestID,
+				Data: map[string]interface{}{
+					"error_type": "bootstrap_failed",
+					"repo":       req.Repo,
+					"branch":     req.Branch,
+				},
+			})
+			return
+		}
+		
+		log.Printf("✅ GitHub repo %s ready (repo created: %v, branch created: %v, files created: %d)",
+			result.Repo, result.RepoCreated, result.BranchCreated, len(result.Created))
+		safeSend(conn, wsmanager.Response{
+			Status:    "github_bootstrap_complete",
+			RequestID: msg.RequestID,
+			Data:      result,
+		})
+	}()
+	
+	return nil
+}
+
+// handleGenerateStaticSite renders the static reader site from the JSON library and,
+// when token and repo are given, publishes it to GitHub Pages (branch gh-pages by default)
+func (s *HighPerformanceServer) handleGenerateStaticSite(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid static site request: %v", err)
+	}
+	publish := req.Token != "" || req.Repo != ""
+	if publish && (req.Token == "" || req.Repo == "") {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgGitHubCredentialsMissing),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: msg.RequestID,
+		})
+	}
+	branch := req.Branch
+	if branch == "" {
+		branch = "gh-pages"
+	}
+	
+	if !s.siteMu.TryLock() {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgSiteRunning),
+			ErrorCode: wsmanager.ErrJobRunning,
+			RequestID: msg.RequestID,
+		})
 	}
 	
 	go func() {
-		startTime := time.Now()
+		defer s.siteMu.Unlock()
 		
-		// Send progress update
-		progressResponse := wsmanager.Response{
-			Status:    "search_progress",
-			RequestID: req.RequestID,
-			Progress: &wsmanager.Progress{
-				Current:    0,
-				Total:      1,
-				Percentage: 0,
-				Stage:      "searching_anilist",
-			},
+		result, err := sitegen.Generate(s.siteConfig)
+		if err != nil {
+			log.Printf("❌ Static site generation failed: %v", err)
+			safeSend(conn, wsmanager.Response{
+				Status:    "static_site_error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgSiteFailed, err),
+				ErrorCode: wsmanager.ErrIO,
+				RequestID: msg.RequestID,
+			})
+			return
 		}
-		safeSend(conn, progressResponse)
+		log.Printf("🌐 Static site generated in %s: %d series, %d chapters, %d pages", result.OutputDir, result.Series, result.Chapters, result.Pages)
 		
-		// Perform AniList search with retry and error handling
-		// Criar contexto com timeout para evitar busca infinita
-		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second) // 60 segundos de timeout
-		defer cancel()
+		data := map[string]interface{}{
+			"site":      result,
+			"published": false,
+		}
+		if !publish {
+			safeSend(conn, wsmanager.Response{Status: "static_site_complete", RequestID: msg.RequestID, Data: data})
+			return
+		}
+		
+		files, err := result.ReadFiles()
+		if err != nil {
+			safeSend(conn, wsmanager.Response{
+				Status:    "static_site_error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgSiteFailed, err),
+				ErrorCode: wsmanager.ErrIO,
+				RequestID: msg.RequestID,
+			})
+			return
+		}
 		
-		results, err := s.anilistService.SearchMangaWithRetry(ctx, req.SearchQuery, 1, 10)
+		// GitHub has no bulk upload: one commit per changed file, unchanged files are skipped
+		progress := wsmanager.Response{
+			Status:    "static_site_progress",
+			RequestID: msg.RequestID,
+			Progress:  &wsmanager.Progress{Total: len(files), Stage: "uploading_to_github"},
+		}
+		commit, err := s.githubService.UploadFiles(req.Token, req.Repo, branch, req.Folder, "Update static site", files,
+			func(done, total int, filePath string, skipped bool) {
+				progress.Progress.Current = done
+				progress.Progress.Percentage = done * 100 / total
+				safeSend(conn, progress)
+			})
+		if err != nil {
+			log.Printf("GitHub Pages publish error: %v", err)
+			safeSend(conn, wsmanager.Response{
+				Status:    "static_site_error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgGitHubUploadFailed, err),
+				ErrorCode: wsmanager.ErrGitHubFailed,
+				RequestID: msg.RequestID,
+				Data:      data,
+			})
+			return
+		}
 		
-		duration := time.Since(startTime)
-		log.Printf("AniList search completed in %v for query: %s", duration, req.SearchQuery)
+		log.Printf("✅ Static site published to %s (%s): %s", req.Repo, branch, commit.Message)
+		data["published"] = true
+		data["commit"] = commit
+		data["repo"] = req.Repo
+		data["branch"] = branch
+		data["folder"] = req.Folder
+		safeSend(conn, wsmanager.Response{Status: "static_site_complete", RequestID: msg.RequestID, Data: data})
+	}()
+	
+	return nil
+}
+
+// handleCheckUpdate looks for a newer release on the configured channel
+func (s *HighPerformanceServer) handleCheckUpdate(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	if s.updater == nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgUpdateCheckFailed, s.updaterErr),
+			ErrorCode: wsmanager.ErrUpdateFailed,
+			RequestID: msg.RequestID,
+		})
+	}
+	result, err := s.updater.Check(s.ctx)
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgUpdateCheckFailed, err),
+			ErrorCode: wsmanager.ErrUpdateFailed,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "update_status",
+		RequestID: msg.RequestID,
+		Data:      result,
+	})
+}
+
+// handleApplyUpdate downloads and verifies the newest release, swaps the executable
+// and restarts into it. Collections are persisted by the shutdown and resumed by the
+// new process; running batches would be cut short, so they block the update unless forced.
+func (s *HighPerformanceServer) handleApplyUpdate(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid apply update request: %v", err)
+	}
+	if s.updater == nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgUpdateFailed, s.updaterErr),
+			ErrorCode: wsmanager.ErrUpdateFailed,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	running := 0
+	for _, batch := range s.batchUploader.ListBatches() {
+		if batch.State == "running" {
+			running++
+		}
+	}
+	if running > 0 && !req.Force {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgUpdateBusy, running),
+			ErrorCode: wsmanager.ErrJobRunning,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	go func() {
+		progress := wsmanager.Response{
+			Status:    "update_progress",
+			RequestID: msg.RequestID,
+			Progress:  &wsmanager.Progress{},
+		}
+		applied, err := s.updater.Apply(s.ctx, func(stage string, done, total int64) {
+			progress.Progress.Stage = stage
+			progress.Progress.Current = int(done)
+			progress.Progress.Total = int(total)
+			progress.Progress.Percentage = 0
+			if total > 0 {
+				progress.Progress.Percentage = int(done * 100 / total)
+			}
+			safeSend(conn, progress)
+		})
+		switch {
+		case errors.Is(err, selfupdate.ErrRunning):
+			safeSend(conn, wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgUpdateRunning),
+				ErrorCode: wsmanager.ErrJobRunning,
+				RequestID: msg.RequestID,
+			})
+			return
+		case errors.Is(err, selfupdate.ErrUpToDate):
+			safeSend(conn, wsmanager.Response{
+				Status:    "update_not_available",
+				Error:     i18n.T(connLocale(conn), i18n.MsgUpToDate, version),
+				RequestID: msg.RequestID,
+				Data:      s.updater.LastCheck(),
+			})
+			return
+		case err != nil:
+			log.Printf("❌ Update failed: %v", err)
+			safeSend(conn, wsmanager.Response{
+				Status:    "update_error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgUpdateFailed, err),
+				ErrorCode: wsmanager.ErrUpdateFailed,
+				RequestID: msg.RequestID,
+			})
+			return
+		}
+		
+		log.Printf("⬆️ Updated %s → %s (sha256 %s, signature verified: %v), restarting", applied.From, applied.To, applied.SHA256, applied.Verified)
+		safeSend(conn, wsmanager.Response{
+			Status:    "update_applied",
+			RequestID: msg.RequestID,
+			Data:      applied,
+		})
+		s.wsManager.Broadcast(wsmanager.Response{
+			Status: "server_restarting",
+			Data: map[string]interface{}{
+				"reason":  "update",
+				"version": applied.To,
+			},
+		})
+		
+		atomic.StoreInt32(&s.restarting, 1)
+		select {
+		case s.restartRequested <- applied.Executable:
+		default:
+		}
+	}()
+	
+	return nil
+}
+
+// broadcastUpdateAvailable tells connected clients about a newer release found by the periodic check
+func (s *HighPerformanceServer) broadcastUpdateAvailable(result *selfupdate.CheckResult) {
+	log.Printf("⬆️ Update available: %s → %s (%s)", result.Current, result.Latest.Version, result.Latest.URL)
+	
+	s.wsManager.Broadcast(wsmanager.Response{
+		Status: "update_available",
+		Data:   result,
+	})
+}
+
+// HostListEntry is one upload host in get_hosts
+type HostListEntry struct {
+	upload.HostInfo
+	Source string        `json:"source"`           // "builtin" or "plugin"
+	Plugin *plugins.Info `json:"plugin,omitempty"` // Set for plugin hosts
+}
+
+// handleGetHosts lists the registered upload hosts and every plugin found in the plugin directory
+func (s *HighPerformanceServer) handleGetHosts(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	hosts := []HostListEntry{}
+	for _, host := range s.batchUploader.HostDetails() {
+		entry := HostListEntry{HostInfo: host, Source: "builtin"}
+		if plugin, exists := s.plugins.Get(host.Name); exists && plugin.Provides(plugins.CapabilityUploader) {
+			info := plugin.Info()
+			entry.Source = "plugin"
+			entry.Plugin = &info
+		}
+		hosts = append(hosts, entry)
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "hosts",
+		RequestID: msg.RequestID,
+		Data: map[string]interface{}{
+			"hosts":     hosts,
+			"plugins":   s.plugins.List(),
+			"pluginDir": s.plugins.Dir(),
+		},
+	})
+}
+
+// handleGetMetadataProviders lists the metadata sources: AniList plus metadata plugins
+func (s *HighPerformanceServer) handleGetMetadataProviders(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	providers := []map[string]interface{}{
+		{
+			"name":      "anilist",
+			"source":    "builtin",
+			"available": s.anilistService != nil,
+			"actions":   []string{"search_anilist", "select_anilist_result"},
+		},
+	}
+	for _, plugin := range s.plugins.WithCapability(plugins.CapabilityMetadata) {
+		info := plugin.Info()
+		providers = append(providers, map[string]interface{}{
+			"name":      info.Name,
+			"source":    "plugin",
+			"available": true,
+			"actions":   []string{"search_metadata", "get_metadata_details"},
+			"plugin":    info,
+		})
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "metadata_providers",
+		RequestID: msg.RequestID,
+		Data: map[string]interface{}{
+			"providers": providers,
+			"plugins":   s.plugins.List(),
+		},
+	})
+}
+
+// metadataProvider resolves a metadata plugin by name, replying with an error when there is none
+func (s *HighPerformanceServer) metadataProvider(conn *wsmanager.Connection, requestID, name string) (*plugins.MetadataProvider, error) {
+	plugin, exists := s.plugins.Get(name)
+	if !exists || !plugin.Provides(plugins.CapabilityMetadata) {
+		return nil, conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgProviderNotFound, name),
+			ErrorCode: wsmanager.ErrProviderNotFound,
+			RequestID: requestID,
+		})
+	}
+	return plugins.NewMetadataProvider(plugin), nil
+}
+
+// handleSearchMetadata searches a metadata plugin by title
+func (s *HighPerformanceServer) handleSearchMetadata(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid metadata search request: %v", err)
+	}
+	if req.SearchQuery == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgSearchQueryRequired),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: msg.RequestID,
+		})
+	}
+	provider, err := s.metadataProvider(conn, msg.RequestID, req.Provider)
+	if provider == nil {
+		return err
+	}
+	
+	searchQuery := req.SearchQuery
+	override, overridden := s.titleOverrides.Lookup(req.SearchQuery)
+	if overridden {
+		searchQuery = override.Query()
+	}
+	
+	go func() {
+		ctx, cancel := context.WithTimeout(s.ctx, 60*time.Second)
+		defer cancel()
 		
+		results, err := provider.Search(ctx, searchQuery)
 		if err != nil {
-			// Verificar se é um erro amigável (FriendlyError)
-			var friendlyErr *anilist.FriendlyError
-			var errorMessage string
-			var errorData map[string]interface{}
+			log.Printf("❌ Metadata search on %s failed: %v", provider.Name(), err)
+			safeSend(conn, wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgPluginFailed, provider.Name(), err),
+				ErrorCode: wsmanager.ErrPluginFailed,
+				RequestID: msg.RequestID,
+			})
+			return
+		}
+		data := map[string]interface{}{
+			"provider": provider.Name(),
+			"query":    req.SearchQuery,
+			"results":  results,
+		}
+		if overridden {
+			data["resolvedQuery"] = searchQuery
+			if override.Provider == provider.Name() && override.ProviderID != "" {
+				data["override"] = override
+			}
+		}
+		safeSend(conn, wsmanager.Response{
+			Status:    "metadata_search_results",
+			RequestID: msg.RequestID,
+			Data:      data,
+		})
+	}()
+	
+	return nil
+}
 
-			if errors.As(err, &friendlyErr) {
-				// Erro amigável - usar mensagem personalizada
-				errorMessage = friendlyErr.UserMessage
-				errorData = map[string]interface{}{
-					"error_code":        friendlyErr.ErrorCode,
-					"severity":          string(friendlyErr.Severity),
-					"suggestions":       friendlyErr.Suggestions,
-					"user_message":      friendlyErr.UserMessage,
-					"technical_message": friendlyErr.TechnicalMessage,
-				}
+// handleGetMetadataDetails fetches the full metadata of a series from a metadata plugin
+func (s *HighPerformanceServer) handleGetMetadataDetails(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid metadata details request: %v", err)
+	}
+	if req.ProviderID == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgProviderIDRequired),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: msg.RequestID,
+		})
+	}
+	provider, err := s.metadataProvider(conn, msg.RequestID, req.Provider)
+	if provider == nil {
+		return err
+	}
+	
+	go func() {
+		ctx, cancel := context.WithTimeout(s.ctx, 60*time.Second)
+		defer cancel()
+		
+		details, err := provider.Details(ctx, req.ProviderID)
+		if err != nil {
+			log.Printf("❌ Metadata details on %s failed: %v", provider.Name(), err)
+			safeSend(conn, wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgPluginFailed, provider.Name(), err),
+				ErrorCode: wsmanager.ErrPluginFailed,
+				RequestID: msg.RequestID,
+			})
+			return
+		}
+		safeSend(conn, wsmanager.Response{
+			Status:    "metadata_details",
+			RequestID: msg.RequestID,
+			Data: map[string]interface{}{
+				"provider":   provider.Name(),
+				"providerId": req.ProviderID,
+				"details":    details,
+			},
+		})
+	}()
+	
+	return nil
+}
 
-				if friendlyErr.RetryAfter != nil {
-					errorData["retry_after_seconds"] = int(friendlyErr.RetryAfter.Seconds())
-				}
-			} else {
-				// Erro técnico - usar mensagem genérica
-				errorMessage = "Erro inesperado ao buscar na AniList. Tente novamente ou use a entrada manual."
-				errorData = map[string]interface{}{
-					"error_code":     "UNEXPECTED_ERROR",
-					"severity":       "error",
-					"user_message":   errorMessage,
-					"suggestions":    []string{"Tente novamente em alguns instantes", "Use a entrada manual de metadados"},
-				}
+// handleCheckUploadPolicy checks every image under a folder against the content
+// policy without uploading anything and returns the violation report; with
+// detectDuplicates it also lists pages repeated within a chapter or across
+// versions of the same chapter
+func (s *HighPerformanceServer) handleCheckUploadPolicy(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid policy check request: %v", err)
+	}
+	
+	if req.BasePath == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "basePath"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	fullPath := req.BasePath
+	if !filepath.IsAbs(fullPath) {
+		fullPath = filepath.Join(s.config.LibraryRoot, req.BasePath)
+	}
+	if _, err := os.Stat(fullPath); err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgPathNotFound, fullPath),
+			ErrorCode: wsmanager.ErrPathNotFound,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	threshold := req.DuplicateThreshold
+	if threshold <= 0 {
+		threshold = phash.DefaultThreshold
+	}
+	
+	go func() {
+		report := s.contentPolicy.NewReport()
+		var paths []string
+		err := filepath.WalkDir(fullPath, func(path string, entry os.DirEntry, err error) error {
+			if err != nil {
+				return err
 			}
-
-			response := wsmanager.Response{
-				Status:    "anilist_error",
-				Error:     errorMessage,
-				RequestID: req.RequestID,
-				Data:      errorData,
+			if entry.IsDir() || !discovery.SupportedExtensions[strings.ToLower(filepath.Ext(path))] {
+				return nil
 			}
-			safeSend(conn, response)
+			name, _ := filepath.Rel(fullPath, path)
+			s.contentPolicy.Record(report, s.contentPolicy.CheckFile(path, name))
+			paths = append(paths, path)
+			return nil
+		})
+		if err != nil {
+			safeSend(conn, wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgPolicyCheckFailed, err),
+				ErrorCode: wsmanager.ErrIO,
+				RequestID: req.RequestID,
+			})
 			return
 		}
 		
-		// Send successful response
-		response := wsmanager.Response{
-			Status:    "search_anilist_complete",
-			RequestID: req.RequestID,
-			Data: map[string]interface{}{
-				"results":     results.Results,
-				"resultCount": len(results.Results),
-				"searchQuery": req.SearchQuery,
-				"duration":    duration.String(),
-				"total":       results.Total,
-				"hasNextPage": results.HasNextPage,
-			},
+		data := map[string]interface{}{
+			"path":    fullPath,
+			"enabled": s.contentPolicy.Enabled(),
+			"report":  report,
 		}
-		safeSend(conn, response)
+		if req.DetectDuplicates {
+			hashes := phash.Files(paths, 0)
+			var pages []phash.Page
+			for _, path := range paths {
+				if hash, ok := hashes[path]; ok {
+					name, _ := filepath.Rel(fullPath, path)
+					pages = append(pages, phash.Page{Chapter: filepath.Dir(name), File: name, Hash: hash})
+				}
+			}
+			data["hashedPages"] = len(pages)
+			data["duplicates"] = phash.Find(pages, threshold)
+		}
+		
+		safeSend(conn, wsmanager.Response{
+			Status:    "policy_report",
+			RequestID: req.RequestID,
+			Data:      data,
+		})
 	}()
 	
 	return nil
 }
 
-// handleSelectAniListResult handles selection of an AniList result and metadata integration
-func (s *HighPerformanceServer) handleSelectAniListResult(conn *wsmanager.Connection, msg wsmanager.Message) error {
+// handleScanIntegrity starts a background integrity scan of the library; the report is
+// broadcast when it ends. With autoRepair, every fixable issue is repaired right after.
+func (s *HighPerformanceServer) handleScanIntegrity(conn *wsmanager.Connection, msg wsmanager.Message) error {
 	var req WebSocketRequest
 	reqData, _ := json.Marshal(msg.Data)
 	if err := json.Unmarshal(reqData, &req); err != nil {
-		return fmt.Errorf("invalid selection request: %v", err)
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid integrity scan request: %v", err)
 	}
 	
-	if req.AniListID == 0 {
-		response := wsmanager.Response{
+	err := s.integrityScanner.Trigger(func(report *integrity.Report) {
+		s.broadcastIntegrityReport(report)
+		if !req.AutoRepair || report.Error != "" || report.Fixable == 0 {
+			return
+		}
+		result, err := s.integrityScanner.Repair(nil)
+		if err != nil {
+			log.Printf("⚠️ Integrity auto-repair failed: %v", err)
+			return
+		}
+		s.broadcastIntegrityRepair(result)
+	})
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgIntegrityRunning),
+			ErrorCode: wsmanager.ErrJobRunning,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "integrity_scan_started",
+		RequestID: req.RequestID,
+	})
+}
+
+// handleGetIntegrityReport returns the issues of the last integrity scan (nil if none ran)
+func (s *HighPerformanceServer) handleGetIntegrityReport(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	return conn.Send(wsmanager.Response{
+		Status:    "integrity_report",
+		Data:      s.integrityScanner.LastReport(),
+		RequestID: msg.RequestID,
+	})
+}
+
+// handleRepairIntegrity applies the automatic repair of the given issues of the last
+// scan (every fixable issue when none is given)
+func (s *HighPerformanceServer) handleRepairIntegrity(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid integrity repair request: %v", err)
+	}
+	
+	result, err := s.integrityScanner.Repair(req.IssueIDs)
+	if errors.Is(err, integrity.ErrNoReport) {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgIntegrityMissing),
+			ErrorCode: wsmanager.ErrInvalidRequest,
+			RequestID: req.RequestID,
+		})
+	}
+	if err != nil {
+		return conn.Send(wsmanager.Response{
 			Status:    "error",
-			Error:     "AniList ID is required",
+			Error:     i18n.T(connLocale(conn), i18n.MsgIntegrityRunning),
+			ErrorCode: wsmanager.ErrJobRunning,
 			RequestID: req.RequestID,
+		})
+	}
+	
+	s.broadcastIntegrityRepair(result)
+	return conn.Send(wsmanager.Response{
+		Status:    "integrity_repaired",
+		RequestID: req.RequestID,
+		Data:      result,
+	})
+}
+
+// broadcastIntegrityReport logs and pushes a finished integrity scan to every client
+func (s *HighPerformanceServer) broadcastIntegrityReport(report *integrity.Report) {
+	if report.Error != "" {
+		log.Printf("⚠️ Integrity scan failed: %s", report.Error)
+	} else {
+		log.Printf("🩺 Integrity scan: %d issue(s), %d fixable (%d JSONs, %d folders, %d history records)",
+			len(report.Issues), report.Fixable, report.JSONs, report.Folders, report.HistoryRecords)
+	}
+	
+	s.wsManager.Broadcast(wsmanager.Response{
+		Status: "integrity_report",
+		Data:   report,
+	})
+}
+
+// broadcastIntegrityRepair logs a repair and pushes the updated report to every client
+func (s *HighPerformanceServer) broadcastIntegrityRepair(result *integrity.RepairResult) {
+	log.Printf("🩺 Integrity repair: %d repaired, %d failed", len(result.Repaired), len(result.Failed))
+	for id, err := range result.Failed {
+		log.Printf("⚠️ Integrity repair of %s failed: %s", id, err)
+	}
+	
+	s.wsManager.Broadcast(wsmanager.Response{
+		Status: "integrity_report",
+		Data:   s.integrityScanner.LastReport(),
+	})
+}
+
+// hideArchivedSeries removes archived series from the top level of a discovery tree
+func (s *HighPerformanceServer) hideArchivedSeries(tree discovery.LibraryNode) {
+	archived := s.idRegistry.Archived()
+	if len(archived) == 0 {
+		return
+	}
+	for name := range tree {
+		if strings.HasPrefix(name, "_") {
+			continue
 		}
-		return conn.Send(response)
+		if archived[mangaid.Normalize(name)] {
+			delete(tree, name)
+		}
+	}
+}
+
+// handleArchiveSeries moves a series' JSON into the archive and marks it archived in the
+// ID registry; nothing is deleted and unarchive_series restores it
+func (s *HighPerformanceServer) handleArchiveSeries(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid archive series request: %v", err)
 	}
 	
-	go func() {
-		startTime := time.Now()
-		
-		// Send progress update
-		progressResponse := wsmanager.Response{
-			Status:    "anilist_fetch_progress",
+	if req.Manga == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgMissingMangaName),
+			ErrorCode: wsmanager.ErrMissingField,
 			RequestID: req.RequestID,
-			Progress: &wsmanager.Progress{
-				Current:    0,
-				Total:      2,
-				Percentage: 0,
-				Stage:      "fetching_details",
-			},
+		})
+	}
+	
+	mangaID := mangaid.Normalize(req.Manga)
+	jsonPath := mangaid.Path(s.config.MetadataOutput, mangaID)
+	
+	// Same lock as save_metadata so the move does not race with metadata edits
+	s.metadataMu.Lock()
+	defer s.metadataMu.Unlock()
+	
+	moved, err := metadata.ArchiveJSON(jsonPath)
+	if err == nil {
+		if err = s.idRegistry.SetArchived(mangaID, req.Manga, true, req.Reason); err != nil && moved {
+			// Keep JSON and registry consistent: the series stays active
+			metadata.UnarchiveJSON(jsonPath)
 		}
-		safeSend(conn, progressResponse)
-		
-		// Fetch detailed information from AniList with retry and error handling
-		details, err := s.anilistService.GetMangaDetailsWithRetry(context.Background(), req.AniListID)
-		if err != nil {
-			// Verificar se é um erro amigável (FriendlyError)
-			var friendlyErr *anilist.FriendlyError
-			var errorMessage string
-			var errorData map[string]interface{}
+	}
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgArchiveFailed, mangaID, err),
+			ErrorCode: wsmanager.ErrIO,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	entry, _ := s.idRegistry.Get(mangaID)
+	log.Printf("📦 Series %s archived (%s)", mangaID, entry.ArchiveReason)
+	return conn.Send(wsmanager.Response{
+		Status:    "series_archived",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"mangaId":      mangaID,
+			"jsonArchived": moved,
+			"entry":        entry,
+		},
+	})
+}
 
-			if errors.As(err, &friendlyErr) {
-				// Erro amigável - usar mensagem personalizada
-				errorMessage = friendlyErr.UserMessage
-				errorData = map[string]interface{}{
-					"error_code":        friendlyErr.ErrorCode,
-					"severity":          string(friendlyErr.Severity),
-					"suggestions":       friendlyErr.Suggestions,
-					"user_message":      friendlyErr.UserMessage,
-					"technical_message": friendlyErr.TechnicalMessage,
-				}
+// handleUnarchiveSeries restores an archived series' JSON and clears its archived state
+func (s *HighPerformanceServer) handleUnarchiveSeries(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid unarchive series request: %v", err)
+	}
+	
+	if req.Manga == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgMissingMangaName),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	mangaID := mangaid.Normalize(req.Manga)
+	jsonPath := mangaid.Path(s.config.MetadataOutput, mangaID)
+	
+	s.metadataMu.Lock()
+	defer s.metadataMu.Unlock()
+	
+	restored, err := metadata.UnarchiveJSON(jsonPath)
+	if err == nil {
+		if err = s.idRegistry.SetArchived(mangaID, req.Manga, false, ""); err != nil && restored {
+			metadata.ArchiveJSON(jsonPath)
+		}
+	}
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgUnarchiveFailed, mangaID, err),
+			ErrorCode: wsmanager.ErrIO,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	entry, _ := s.idRegistry.Get(mangaID)
+	log.Printf("📦 Series %s unarchived", mangaID)
+	return conn.Send(wsmanager.Response{
+		Status:    "series_unarchived",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"mangaId":      mangaID,
+			"jsonRestored": restored,
+			"entry":        entry,
+		},
+	})
+}
 
-				if friendlyErr.RetryAfter != nil {
-					errorData["retry_after_seconds"] = int(friendlyErr.RetryAfter.Seconds())
-				}
-			} else {
-				// Erro técnico - usar mensagem genérica
-				errorMessage = "Erro inesperado ao obter detalhes da AniList. Tente novamente ou use a entrada manual."
-				errorData = map[string]interface{}{
-					"error_code":     "UNEXPECTED_ERROR",
-					"severity":       "error",
-					"user_message":   errorMessage,
-					"suggestions":    []string{"Tente novamente em alguns instantes", "Use a entrada manual de metadados"},
-				}
-			}
+// handleListArchivedSeries returns the registry entries of every archived series
+func (s *HighPerformanceServer) handleListArchivedSeries(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	archived := []registry.Entry{}
+	for _, entry := range s.idRegistry.Entries() {
+		if entry.Archived {
+			archived = append(archived, entry)
+		}
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "archived_series",
+		RequestID: msg.RequestID,
+		Data:      archived,
+	})
+}
 
-			response := wsmanager.Response{
-				Status:    "anilist_error",
-				Error:     errorMessage,
-				RequestID: req.RequestID,
-				Data:      errorData,
+// handleSearchLibrary searches titles, authors and descriptions of the local JSONs,
+// so the frontend doesn't have to load every JSON to filter the library
+func (s *HighPerformanceServer) handleSearchLibrary(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid library search request: %v", err)
+	}
+	
+	results, total, err := s.libraryIndex.Search(req.Query, req.Limit)
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgLibrarySearchFailed, err),
+			ErrorCode: wsmanager.ErrIO,
+			RequestID: req.RequestID,
+		})
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "library_search_results",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"query":   req.Query,
+			"results": results,
+			"total":   total,
+		},
+	})
+}
+
+// handleGetCorruptJSONs checks every JSON in the output folder (repairing or
+// quarantining broken ones) and returns the report
+func (s *HighPerformanceServer) handleGetCorruptJSONs(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	s.metadataMu.Lock()
+	found, err := s.jsonQuarantine.Scan()
+	s.metadataMu.Unlock()
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgJSONScanFailed, err),
+			ErrorCode: wsmanager.ErrIO,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "corrupt_jsons",
+		RequestID: msg.RequestID,
+		Data: map[string]interface{}{
+			"found":   found,
+			"pending": s.jsonQuarantine.Pending(),
+			"report":  s.jsonQuarantine.Report(),
+		},
+	})
+}
+
+// handleRestoreCorruptJSONs recreates quarantined JSONs from the copies published
+// in the GitHub repository (same settings as github_upload)
+func (s *HighPerformanceServer) handleRestoreCorruptJSONs(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid restore request: %v", err)
+	}
+	
+	if req.Token == "" && req.GitHubSettings != nil {
+		req.Token, _ = req.GitHubSettings["token"].(string)
+		req.Repo, _ = req.GitHubSettings["repo"].(string)
+		req.Branch, _ = req.GitHubSettings["branch"].(string)
+		req.Folder, _ = req.GitHubSettings["folder"].(string)
+	}
+	if req.Token == "" || req.Repo == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgGitHubCredentialsMissing),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	if req.Branch == "" {
+		req.Branch = "main"
+	}
+	
+	go func() {
+		restored := []string{}
+		failed := make(map[string]string)
+		for _, entry := range s.jsonQuarantine.Pending() {
+			if err := s.restoreFromGitHub(req.Token, req.Repo, req.Branch, req.Folder, entry.File); err != nil {
+				failed[entry.File] = err.Error()
+				continue
 			}
-			safeSend(conn, response)
-			return
+			restored = append(restored, entry.File)
 		}
+		log.Printf("♻️ Restored %d quarantined JSON(s) from %s (%d failed)", len(restored), req.Repo, len(failed))
 		
-		// Update progress
-		progressResponse.Progress.Current = 1
-		progressResponse.Progress.Percentage = 50
-		progressResponse.Progress.Stage = "processing_metadata"
-		safeSend(conn, progressResponse)
-		
-		// Convert to metadata format (using the mapping function from anilist service)
-		metadata := anilist.MapAniListToMangaMetadata(details.Media)
-		
-		duration := time.Since(startTime)
-		log.Printf("AniList details fetched and processed in %v for ID: %d", duration, req.AniListID)
-		
-		// Send successful response with integrated metadata
-		response := wsmanager.Response{
-			Status:    "anilist_selection_complete",
+		safeSend(conn, wsmanager.Response{
+			Status:    "corrupt_jsons_restored",
 			RequestID: req.RequestID,
 			Data: map[string]interface{}{
-				"anilistData": details,
-				"metadata":    metadata,
-				"mangaTitle":  req.MangaTitle,
-				"duration":    duration.String(),
+				"restored": restored,
+				"failed":   failed,
+				"pending":  s.jsonQuarantine.Pending(),
 			},
-			Metadata: metadata,
-		}
-		safeSend(conn, response)
+		})
 	}()
 	
 	return nil
 }
 
-// setupHTTPServer configures the HTTP server with optimizations
-func (s *HighPerformanceServer) setupHTTPServer() {
-	mux := http.NewServeMux()
-	
-	// WebSocket endpoint with connection management
-	mux.HandleFunc("/ws", s.handleWebSocket)
-	
-	// Metrics endpoint (optional HTTP endpoint for monitoring)
-	if s.config.EnableMetrics {
-		mux.HandleFunc("/metrics", s.handleHTTPMetrics)
-		mux.HandleFunc("/health", s.handleHealthCheck)
+// restoreFromGitHub recreates a quarantined JSON from the copy published in the repository
+func (s *HighPerformanceServer) restoreFromGitHub(token, repo, branch, folder, fileName string) error {
+	content, err := s.githubService.DownloadFile(token, repo, branch, folder, fileName)
+	if err != nil {
+		return err
 	}
-	
-	// AniList metrics endpoint for performance monitoring
-	mux.HandleFunc("/api/anilist/metrics", s.handleAniListMetrics)
-	
-	// AniList health status endpoint
-	mux.HandleFunc("/api/anilist/health", s.handleAniListHealth)
-	
-	s.httpServer = &http.Server{
-		Addr:         s.config.Port,
-		Handler:      mux,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	if content == "" {
+		return fmt.Errorf("%s was never published to %s", fileName, repo)
 	}
+	s.metadataMu.Lock()
+	defer s.metadataMu.Unlock()
+	return s.jsonQuarantine.Restore(fileName, []byte(content), "github:"+repo)
 }
 
-// handleWebSocket handles WebSocket connections with enhanced management
-func (s *HighPerformanceServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Printf("WebSocket upgrade failed: %v", err)
-		return
+// handleMigrateJSONFilenames renames JSONs created under the old filename rules to
+// the current ones and moves their ID registry entries along. With dryRun it only
+// reports what would be renamed; collisions are never resolved automatically.
+func (s *HighPerformanceServer) handleMigrateJSONFilenames(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid migration request: %v", err)
 	}
 	
-	// Generate unique connection ID
-	connectionID := fmt.Sprintf("conn_%d_%s", time.Now().UnixNano(), r.RemoteAddr)
-	
-	// Create managed connection
-	managedConn := s.wsManager.NewConnection(conn, connectionID)
-	
-	// Record connection metrics
-	s.monitor.RecordWebSocketConnection(true)
-	
-	log.Printf("New WebSocket connection: %s", connectionID)
-	
-	// Connection will be automatically cleaned up by the manager
-	_ = managedConn // Suppress unused variable warning
-}
-
-// handleHTTPMetrics serves metrics over HTTP for monitoring tools
-func (s *HighPerformanceServer) handleHTTPMetrics(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+	// Series folders carry the accents the old rules dropped from the filenames
+	var folders []string
+	if entries, err := os.ReadDir(s.config.LibraryRoot); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+				folders = append(folders, entry.Name())
+			}
+		}
+	}
 	
-	metrics := s.monitor.CreateSnapshot()
-	if err := json.NewEncoder(w).Encode(metrics); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	// Same lock as save_metadata so no JSON is written while it is renamed
+	s.metadataMu.Lock()
+	migration, err := mangaid.Migrate(s.config.MetadataOutput, folders, req.DryRun)
+	registryErrors := make(map[string]string)
+	if migration != nil && !req.DryRun {
+		for _, rename := range migration.Renamed {
+			oldID := strings.TrimSuffix(rename.From, ".json")
+			newID := strings.TrimSuffix(rename.To, ".json")
+			if renameErr := s.idRegistry.Rename(oldID, newID); renameErr != nil {
+				registryErrors[rename.From] = renameErr.Error()
+			}
+		}
 	}
-}
-
-// handleHealthCheck provides a health check endpoint
-func (s *HighPerformanceServer) handleHealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+	s.metadataMu.Unlock()
 	
-	health := map[string]interface{}{
-		"status":      "healthy",
-		"timestamp":   time.Now(),
-		"uptime":      time.Since(startTime).String(),
-		"connections": s.wsManager.GetConnectionCount(),
-		"version":     "2.0.0",
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgJSONMigrationFailed, err),
+			ErrorCode: wsmanager.ErrIO,
+			RequestID: req.RequestID,
+		})
 	}
+	log.Printf("🏷️ JSON filename migration: %d renamed, %d collision(s), dryRun=%v", len(migration.Renamed), len(migration.Collisions), req.DryRun)
 	
-	json.NewEncoder(w).Encode(health)
+	return conn.Send(wsmanager.Response{
+		Status:    "json_filenames_migrated",
+		RequestID: req.RequestID,
+		Data: map[string]interface{}{
+			"migration":      migration,
+			"registryErrors": registryErrors,
+		},
+	})
 }
 
-// Start starts the high-performance server
-func (s *HighPerformanceServer) Start() error {
-	log.Printf("Starting High-Performance Manga Upload Server...")
-	log.Printf("Configuration: %+v", s.config)
-	
-	// Start worker pool
-	if err := s.workerPool.Start(); err != nil {
-		return fmt.Errorf("failed to start worker pool: %v", err)
+// handleCreateShareLink creates a read-only, time-limited link to watch the
+// progress of one collection without access to the rest of the server
+func (s *HighPerformanceServer) handleCreateShareLink(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid share link request: %v", err)
 	}
 	
-	// Start collection processor
-	if err := s.collectionProcessor.Start(); err != nil {
-		return fmt.Errorf("failed to start collection processor: %v", err)
+	if s.shareLinks == nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgShareLinksDisabled),
+			ErrorCode: wsmanager.ErrIO,
+			RequestID: req.RequestID,
+		})
 	}
-	
-	// Start metrics logging
-	if s.config.EnableMetrics {
-		s.wg.Add(1)
-		go s.metricsLogger()
+	if req.CollectionID == "" {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "collectionId"),
+			ErrorCode: wsmanager.ErrMissingField,
+			RequestID: req.RequestID,
+		})
+	}
+	if _, exists := s.collectionProcessor.Summary(req.CollectionID); !exists {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgCollectionNotFound),
+			ErrorCode: wsmanager.ErrCollectionNotFound,
+			RequestID: req.RequestID,
+		})
 	}
 	
-	log.Printf("Server starting on %s", s.config.Port)
-	log.Printf("Max workers: %d, Max connections: %d", s.config.MaxWorkers, s.config.MaxConnections)
-	log.Printf("Discovery workers: %d", s.config.DiscoveryWorkers)
-	
-	return s.httpServer.ListenAndServe()
-}
-
-// metricsLogger periodically logs metrics
-func (s *HighPerformanceServer) metricsLogger() {
-	defer s.wg.Done()
-	
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
-	
-	for {
-		select {
-		case <-ticker.C:
-			s.monitor.LogMetrics()
-		case <-s.ctx.Done():
-			return
+	ttl, err := parseShareTTL(req.TTL)
+	if err == nil {
+		var link share.Link
+		if link, err = s.shareLinks.Issue(req.CollectionID, ttl); err == nil {
+			log.Printf("🔗 Share link for collection %s valid until %s", req.CollectionID, link.ExpiresAt.Format(time.RFC3339))
+			return conn.Send(wsmanager.Response{
+				Status:    "share_link_created",
+				RequestID: req.RequestID,
+				Data:      link,
+			})
 		}
 	}
+	return conn.Send(wsmanager.Response{
+		Status:    "error",
+		Error:     i18n.T(connLocale(conn), i18n.MsgShareLinkFailed, err),
+		ErrorCode: wsmanager.ErrInvalidRequest,
+		RequestID: req.RequestID,
+	})
 }
 
-// GracefulShutdown gracefully shuts down the server
-func (s *HighPerformanceServer) GracefulShutdown() {
-	log.Println("Initiating graceful shutdown...")
-	
-	// Cancel context to stop all goroutines
-	s.cancel()
-	
-	// Shutdown HTTP server
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	
-	if err := s.httpServer.Shutdown(ctx); err != nil {
-		log.Printf("HTTP server shutdown error: %v", err)
-	}
-	
-	// Close components in proper order
-	log.Println("Stopping collection processor...")
-	if err := s.collectionProcessor.Stop(); err != nil {
-		log.Printf("Collection processor shutdown error: %v", err)
+// handleRevokeShareLinks invalidates every share link issued so far
+func (s *HighPerformanceServer) handleRevokeShareLinks(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	if s.shareLinks == nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgShareLinksDisabled),
+			ErrorCode: wsmanager.ErrIO,
+			RequestID: msg.RequestID,
+		})
 	}
-	
-	log.Println("Stopping worker pool...")
-	if err := s.workerPool.Stop(); err != nil {
-		log.Printf("Worker pool shutdown error: %v", err)
+	if err := s.shareLinks.RevokeAll(); err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgShareLinkFailed, err),
+			ErrorCode: wsmanager.ErrIO,
+			RequestID: msg.RequestID,
+		})
 	}
+	log.Printf("🔗 All share links revoked")
 	
-	log.Println("Closing remaining components...")
-	s.batchUploader.Close()
-	s.discoverer.Close()
-	s.wsManager.Close()
-	s.monitor.Close()
-	
-	// Wait for all goroutines to finish
-	s.wg.Wait()
-	
-	log.Println("Graceful shutdown completed")
+	return conn.Send(wsmanager.Response{
+		Status:    "share_links_revoked",
+		RequestID: msg.RequestID,
+	})
 }
 
-
-// Default configuration
-func getDefaultConfig() *ServerConfig {
-	// Try to read from environment variables
-	maxWorkers := DEFAULT_MAX_WORKERS
-	if env := os.Getenv("MAX_WORKERS"); env != "" {
-		if val, err := strconv.Atoi(env); err == nil {
-			maxWorkers = val
-		}
+// parseShareTTL accepts Go durations plus whole days ("7d"); empty means the default
+func parseShareTTL(value string) (time.Duration, error) {
+	if value == "" {
+		return share.DefaultTTL, nil
 	}
-	
-	maxConnections := DEFAULT_MAX_CONNECTIONS
-	if env := os.Getenv("MAX_CONNECTIONS"); env != "" {
-		if val, err := strconv.Atoi(env); err == nil {
-			maxConnections = val
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
 		}
 	}
-	
-	port := SERVER_PORT
-	if env := os.Getenv("PORT"); env != "" {
-		if !strings.HasPrefix(env, ":") {
-			env = ":" + env
-		}
-		port = env
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid ttl %q", value)
 	}
-	
-	return &ServerConfig{
-		MaxWorkers:       maxWorkers,
-		MaxConnections:   maxConnections,
-		DiscoveryWorkers: DISCOVERY_WORKERS,
-		Port:             port,
-		LibraryRoot:      LIBRARY_ROOT,
-		MetadataOutput:   "json", // Default directory for JSON files
-		EnableMetrics:    true,
-		LogLevel:         "INFO",
+	return ttl, nil
+}
+
+// sharedCollectionStatus is what a share link shows: progress and state, without
+// local paths or anything else about the server
+func (s *HighPerformanceServer) sharedCollectionStatus(collectionID string) (interface{}, bool) {
+	summary, exists := s.collectionProcessor.Summary(collectionID)
+	if !exists {
+		return nil, false
 	}
+	return map[string]interface{}{
+		"name":          summary.Name,
+		"status":        summary.Status,
+		"queuePosition": summary.QueuePosition,
+		"startTime":     summary.StartTime,
+		"endTime":       summary.EndTime,
+		"progress":      summary.Progress,
+	}, true
 }
 
-// Global start time for uptime calculation
-var startTime = time.Now()
+// fileContentError describes an upload whose base64 fileContent is invalid or
+// larger than maxFileContentSize
+type fileContentError struct {
+	fileName string
+	size     int64 // decoded bytes; 0 when the content is not valid base64
+	invalid  error
+}
 
-// main function with graceful shutdown
-func main() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	
-	// Load configuration
-	config := getDefaultConfig()
-	
-	// Create and configure server
-	server := NewHighPerformanceServer(config)
-	
-	// Setup graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	
-	// Start server in goroutine
-	serverErr := make(chan error, 1)
-	go func() {
-		serverErr <- server.Start()
-	}()
-	
-	// Wait for shutdown signal or server error
-	select {
-	case sig := <-sigChan:
-		log.Printf("Received signal: %v", sig)
-		server.GracefulShutdown()
-	case err := <-serverErr:
-		if err != nil && err != http.ErrServerClosed {
-			log.Printf("Server error: %v", err)
+func (e *fileContentError) Error() string {
+	if e.invalid != nil {
+		return fmt.Sprintf("invalid base64 content in %s: %v", e.fileName, e.invalid)
+	}
+	return fmt.Sprintf("%s is %d bytes, above the file content limit", e.fileName, e.size)
+}
+
+// validateFileContents checks the base64 fileContent of each upload before the
+// batch starts: the decoded size must fit maxFileContentSize and the content
+// must decode. The check streams the content, so nothing is decoded into memory.
+func (s *HighPerformanceServer) validateFileContents(uploads []upload.UploadRequest) error {
+	limit := s.config.maxFileContentSize()
+	for _, up := range uploads {
+		if up.FileContent == "" {
+			continue
+		}
+		if size := int64(base64.StdEncoding.DecodedLen(len(up.FileContent))); size > limit+2 {
+			return &fileContentError{fileName: up.FileName, size: size}
+		}
+		size, err := io.Copy(io.Discard, base64.NewDecoder(base64.StdEncoding, strings.NewReader(up.FileContent)))
+		if err != nil {
+			return &fileContentError{fileName: up.FileName, invalid: err}
+		}
+		if size > limit {
+			return &fileContentError{fileName: up.FileName, size: size}
+		}
+	}
+	return nil
+}
+
+// filterSystemFiles drops hidden and system files (.DS_Store, Thumbs.db,
+// desktop.ini, dotfiles, ._ resource forks) from a batch and returns their names
+func (s *HighPerformanceServer) filterSystemFiles(uploads []upload.UploadRequest, includeHidden bool) ([]upload.UploadRequest, []string) {
+	if includeHidden || s.config.IncludeHiddenFiles {
+		return uploads, nil
+	}
+	var kept []upload.UploadRequest
+	var skipped []string
+	for _, up := range uploads {
+		name := up.FileName
+		if name == "" {
+			name = filepath.Base(up.FilePath)
 		}
+		_, matched := sysfiles.Match(name)
+		if matched || (up.FilePath != "" && sysfiles.Skip(up.FilePath)) {
+			skipped = append(skipped, name)
+			continue
+		}
+		kept = append(kept, up)
 	}
+	return kept, skipped
 }
 
-// Utility functions
-func min(a, b int) int {
-	if a < b {
-		return a
+// fileContentResponse builds the error sent for an upload rejected by validateFileContents
+func (s *HighPerformanceServer) fileContentResponse(conn *wsmanager.Connection, requestID string, err error) wsmanager.Response {
+	response := wsmanager.Response{Status: "error", RequestID: requestID}
+	var contentErr *fileContentError
+	if errors.As(err, &contentErr) && contentErr.invalid == nil {
+		response.Error = i18n.T(connLocale(conn), i18n.MsgFileContentTooLarge, contentErr.fileName, contentErr.size, s.config.maxFileContentSize())
+		response.ErrorCode = wsmanager.ErrMessageTooLarge
+		return response
 	}
-	return b
+	response.Error = i18n.T(connLocale(conn), i18n.MsgFileContentInvalid, err)
+	response.ErrorCode = wsmanager.ErrInvalidRequest
+	return response
 }
 
-// sanitizeFilename cleans a string for use as a filename with consistent behavior
-func sanitizeFilename(input string) string {
-	log.Printf("🧹 SANITIZE DEBUG: input='%s' bytes=%v", input, []byte(input))
-	
-	// Replace specific accented characters with their unaccented equivalents
-	replacements := map[string]string{
-		"ç": "c", "Ç": "C",
-		"ã": "a", "Ã": "A",
-		"à": "a", "À": "A",
-		"á": "a", "Á": "A",
-		"â": "a", "Â": "A",
-		"ä": "a", "Ä": "A",
-		"é": "e", "É": "E",
-		"è": "e", "È": "E",
-		"ê": "e", "Ê": "E",
-		"ë": "e", "Ë": "E",
-		"í": "i", "Í": "I",
-		"ì": "i", "Ì": "I",
-		"î": "i", "Î": "I",
-		"ï": "i", "Ï": "I",
-		"ó": "o", "Ó": "O",
-		"ò": "o", "Ò": "O",
-		"ô": "o", "Ô": "O",
-		"õ": "o", "Õ": "O",
-		"ö": "o", "Ö": "O",
-		"ú": "u", "Ú": "U",
-		"ù": "u", "Ù": "U",
-		"û": "u", "Û": "U",
-		"ü": "u", "Ü": "U",
-		"ñ": "n", "Ñ": "N",
-	}
-	
-	sanitized := input
-	for accented, unaccented := range replacements {
-		sanitized = strings.ReplaceAll(sanitized, accented, unaccented)
-	}
-	log.Printf("🧹 SANITIZE DEBUG: accents_replaced='%s'", sanitized)
-	
-	// Replace spaces with underscores
-	sanitized = strings.ReplaceAll(sanitized, " ", "_")
-	log.Printf("🧹 SANITIZE DEBUG: spaces_to_underscores='%s'", sanitized)
-	
-	// Only remove truly problematic filename characters: / \ : * ? " < > |
-	problematicChars := []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|"}
-	for _, char := range problematicChars {
-		sanitized = strings.ReplaceAll(sanitized, char, "_")
-	}
-	log.Printf("🧹 SANITIZE DEBUG: removed_problematic='%s'", sanitized)
-	
-	// Remove multiple consecutive underscores
-	multipleUnderscores := regexp.MustCompile(`_{2,}`)
-	sanitized = multipleUnderscores.ReplaceAllString(sanitized, "_")
-	
-	// Trim underscores from beginning and end
-	sanitized = strings.Trim(sanitized, "_")
-	
-	log.Printf("🧹 SANITIZE DEBUG: final='%s'", sanitized)
-	return sanitized
+// Targets of purge_internal_state
+const (
+	STATE_UPLOAD_RESULTS = "uploadResults" // uploadResults and batchMangaTitles
+	STATE_BATCHES        = "batches"
+	STATE_COLLECTIONS    = "collections"
+)
+
+// uploadResultsEntry describes the upload results kept for one batch
+type uploadResultsEntry struct {
+	BatchID   string    `json:"batchId"`
+	Files     int       `json:"files"`
+	Titles    int       `json:"titles"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Active    bool      `json:"active"` // Batch still tracked by the uploader
 }
 
-// handleAniListMetrics provides performance metrics for the AniList integration
-func (s *HighPerformanceServer) handleAniListMetrics(w http.ResponseWriter, r *http.Request) {
-	if s.anilistService == nil {
-		http.Error(w, "AniList service not initialized", http.StatusServiceUnavailable)
-		return
-	}
-	
-	// Get metrics from AniList service
-	metrics := s.anilistService.GetPerformanceMetrics()
+// statePurge lists what a cleanup removed, by target
+type statePurge struct {
+	UploadResults []string          `json:"uploadResults"`
+	Batches       []string          `json:"batches"`
+	Collections   []string          `json:"collections"`
+	Errors        map[string]string `json:"errors,omitempty"` // ID -> why it was kept
+}
+
+// stateCleanupLoop periodically drops finished work older than the retention policy
+func (s *HighPerformanceServer) stateCleanupLoop(policy statePolicy) {
+	defer s.wg.Done()
 	
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+	ticker := time.NewTicker(policy.Interval)
+	defer ticker.Stop()
 	
-	if err := json.NewEncoder(w).Encode(metrics); err != nil {
-		log.Printf("Error encoding AniList metrics: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
+	for {
+		select {
+		case <-ticker.C:
+			purged := s.purgeStaleState(policy)
+			if removed := len(purged.UploadResults) + len(purged.Batches) + len(purged.Collections); removed > 0 {
+				log.Printf("🧹 State cleanup: %d upload result set(s), %d batch(es), %d collection(s) removed",
+					len(purged.UploadResults), len(purged.Batches), len(purged.Collections))
+			}
+			s.cleanupStateFiles(policy.StateFiles)
+		case <-s.ctx.Done():
+			return
+		}
 	}
 }
 
-// handleAniListHealth provides health status for the AniList integration
-func (s *HighPerformanceServer) handleAniListHealth(w http.ResponseWriter, r *http.Request) {
-	if s.anilistService == nil {
-		http.Error(w, "AniList service not initialized", http.StatusServiceUnavailable)
-		return
+// purgeStaleState removes the entries older than each target's retention (0 = keep)
+func (s *HighPerformanceServer) purgeStaleState(policy statePolicy) statePurge {
+	purged := statePurge{UploadResults: []string{}, Batches: []string{}, Collections: []string{}}
+	if policy.Batches > 0 {
+		purged.Batches = s.batchUploader.PurgeBatches(policy.Batches)
 	}
-	
-	// Get health status from AniList service
-	status := s.anilistService.GetServiceStatus()
-	
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	
-	// Set HTTP status code based on health
-	if healthy, ok := status["healthy"].(bool); ok && !healthy {
-		w.WriteHeader(http.StatusServiceUnavailable)
+	if policy.UploadResults > 0 {
+		purged.UploadResults = s.purgeUploadResults(policy.UploadResults)
 	}
-	
-	if err := json.NewEncoder(w).Encode(status); err != nil {
-		log.Printf("Error encoding AniList health status: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
+	if policy.Collections > 0 {
+		purged.Collections = s.collectionProcessor.PurgeJobs(policy.Collections)
 	}
+	return purged
 }
 
-// =============================================
-//         ANILIST CONFIGURATION HANDLERS
-// =============================================
-
-// handleGetAniListConfig retorna as configurações atuais da AniList
-func (s *HighPerformanceServer) handleGetAniListConfig(conn *wsmanager.Connection, msg wsmanager.Message) error {
-	log.Printf("🔧 handleGetAniListConfig: ===== HANDLER CHAMADO =====")
-	log.Printf("🔧 handleGetAniListConfig: Requisição recebida - RequestID: %s", msg.RequestID)
-	log.Printf("🔧 handleGetAniListConfig: Message Data: %+v", msg.Data)
-	log.Printf("🔧 handleGetAniListConfig: Message Action: %s", msg.Action)
+// purgeUploadResults drops the results and titles of batches the uploader no
+// longer tracks and that got no new result for longer than olderThan
+func (s *HighPerformanceServer) purgeUploadResults(olderThan time.Duration) []string {
+	cutoff := time.Now().Add(-olderThan)
 	
-	if s.anilistService == nil {
-		log.Printf("❌ handleGetAniListConfig: anilistService é nil")
-		response := wsmanager.Response{
-			Status:    "error",
-			Error:     "AniList service not initialized",
-			RequestID: msg.RequestID,
+	s.uploadResultsMu.Lock()
+	defer s.uploadResultsMu.Unlock()
+	
+	purged := []string{}
+	for batchID := range s.uploadResultsBatchesLocked() {
+		if s.uploadResultsTouched[batchID].After(cutoff) {
+			continue
 		}
-		return conn.Send(response)
+		if _, err := s.batchUploader.GetBatchStatus(batchID); err == nil {
+			continue
+		}
+		s.removeUploadResultsLocked(batchID)
+		purged = append(purged, batchID)
 	}
-	
-	config := s.anilistService.GetConfig()
-	log.Printf("✅ handleGetAniListConfig: Configuração obtida: %+v", config)
-	
-	response := wsmanager.Response{
-		Status:    "config_retrieved",
-		Data:      config,
-		RequestID: msg.RequestID,
+	sort.Strings(purged)
+	return purged
+}
+
+// uploadResultsBatchesLocked returns every batchID with results or titles (called with uploadResultsMu)
+func (s *HighPerformanceServer) uploadResultsBatchesLocked() map[string]bool {
+	batches := make(map[string]bool, len(s.uploadResults)+len(s.batchMangaTitles))
+	for batchID := range s.uploadResults {
+		batches[batchID] = true
 	}
-	
-	log.Printf("📤 handleGetAniListConfig: Enviando resposta - Status: %s", response.Status)
-	return conn.Send(response)
+	for batchID := range s.batchMangaTitles {
+		batches[batchID] = true
+	}
+	for batchID := range s.deferredBatches {
+		batches[batchID] = true
+	}
+	return batches
 }
 
-// handleUpdateAniListConfig atualiza as configurações da AniList
-func (s *HighPerformanceServer) handleUpdateAniListConfig(conn *wsmanager.Connection, msg wsmanager.Message) error {
-	log.Printf("🔧 handleUpdateAniListConfig: ===== HANDLER CHAMADO =====")
-	log.Printf("🔧 handleUpdateAniListConfig: Requisição recebida - RequestID: %s", msg.RequestID)
-	
-	// Verificar se o AniListService está disponível
-	if s.anilistService == nil {
-		log.Printf("❌ handleUpdateAniListConfig: anilistService é nil")
-		return conn.Send(wsmanager.Response{
-			Status:    "error",
-			Error:     "AniList service not initialized",
-			RequestID: msg.RequestID,
+// removeUploadResultsLocked drops one batch from the upload tracking maps (called with uploadResultsMu)
+func (s *HighPerformanceServer) removeUploadResultsLocked(batchID string) bool {
+	_, hasResults := s.uploadResults[batchID]
+	_, hasTitles := s.batchMangaTitles[batchID]
+	delete(s.uploadResults, batchID)
+	delete(s.batchMangaTitles, batchID)
+	delete(s.deferredBatches, batchID)
+	delete(s.uploadResultsTouched, batchID)
+	return hasResults || hasTitles
+}
+
+// handleGetInternalState lists the in-memory state that grows with every batch
+// and collection, with the retention policy of the periodic cleanup
+func (s *HighPerformanceServer) handleGetInternalState(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	s.uploadResultsMu.RLock()
+	entries := make([]uploadResultsEntry, 0, len(s.uploadResults))
+	for batchID := range s.uploadResultsBatchesLocked() {
+		entries = append(entries, uploadResultsEntry{
+			BatchID:   batchID,
+			Files:     len(s.uploadResults[batchID]),
+			Titles:    len(s.batchMangaTitles[batchID]),
+			UpdatedAt: s.uploadResultsTouched[batchID],
 		})
 	}
+	s.uploadResultsMu.RUnlock()
+	for i := range entries {
+		_, err := s.batchUploader.GetBatchStatus(entries[i].BatchID)
+		entries[i].Active = err == nil
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].UpdatedAt.After(entries[j].UpdatedAt) })
 	
-	// Extrair configuração dos dados da mensagem de forma mais simples
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		return conn.Send(wsmanager.Response{
-			Status:    "error",
-			Error:     "Invalid request data format",
-			RequestID: msg.RequestID,
-		})
+	policy := s.config.statePolicy()
+	return conn.Send(wsmanager.Response{
+		Status:    "internal_state",
+		RequestID: msg.RequestID,
+		Data: map[string]interface{}{
+			"uploadResults":  entries,
+			"resultsSpooled": s.resultSpool != nil,
+			"batches":        s.batchUploader.ListBatches(),
+			"collections":    s.collectionProcessor.ListJobs(),
+			"retention": map[string]string{
+				"interval":      policy.Interval.String(),
+				"uploadResults": policy.UploadResults.String(),
+				"batches":       policy.Batches.String(),
+				"collections":   policy.Collections.String(),
+				"stateFiles":    policy.StateFiles.MaxAge.String(),
+				"maxStateFiles": strconv.Itoa(policy.StateFiles.MaxCount),
+			},
+		},
+	})
+}
+
+// handlePurgeInternalState removes entries from the in-memory state: the given
+// IDs (running batches are canceled, unfinished collections are kept) or, without
+// IDs, every finished entry older than olderThan (default: the retention policy)
+func (s *HighPerformanceServer) handlePurgeInternalState(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid purge internal state request: %v", err)
 	}
 	
-	configData, ok := data["config"].(map[string]interface{})
-	if !ok {
-		return conn.Send(wsmanager.Response{
-			Status:    "error",
-			Error:     "Missing or invalid config data",
-			RequestID: msg.RequestID,
-		})
+	targets := map[string]bool{}
+	for _, target := range req.Targets {
+		switch target {
+		case STATE_UPLOAD_RESULTS, STATE_BATCHES, STATE_COLLECTIONS:
+			targets[target] = true
+		default:
+			return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "unknown state target %q", target)
+		}
+	}
+	if len(targets) == 0 {
+		targets = map[string]bool{STATE_UPLOAD_RESULTS: true, STATE_BATCHES: true, STATE_COLLECTIONS: true}
 	}
 	
-	// Obter configuração atual e aplicar atualizações
-	currentConfig := s.anilistService.GetConfig()
-	if currentConfig == nil {
-		return conn.Send(wsmanager.Response{
-			Status:    "error",
-			Error:     "Failed to get current config",
-			RequestID: msg.RequestID,
-		})
+	purged := statePurge{UploadResults: []string{}, Batches: []string{}, Collections: []string{}}
+	if len(req.IDs) > 0 {
+		purged.Errors = make(map[string]string)
+		for _, id := range req.IDs {
+			if targets[STATE_BATCHES] {
+				if err := s.batchUploader.RemoveBatch(id); err == nil {
+					purged.Batches = append(purged.Batches, id)
+				}
+			}
+			if targets[STATE_UPLOAD_RESULTS] {
+				s.uploadResultsMu.Lock()
+				removed := s.removeUploadResultsLocked(id)
+				s.uploadResultsMu.Unlock()
+				if removed {
+					purged.UploadResults = append(purged.UploadResults, id)
+				}
+			}
+			if targets[STATE_COLLECTIONS] {
+				if err := s.collectionProcessor.RemoveJob(id); err == nil {
+					purged.Collections = append(purged.Collections, id)
+				} else if _, exists := s.collectionProcessor.GetJobStatus(id); exists {
+					purged.Errors[id] = err.Error()
+				}
+			}
+		}
+	} else {
+		policy := s.config.statePolicy()
+		if req.OlderThan != "" {
+			olderThan, err := time.ParseDuration(req.OlderThan)
+			if err != nil || olderThan < 0 {
+				return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid olderThan %q", req.OlderThan)
+			}
+			// Zero removes every finished entry of the requested targets
+			if olderThan == 0 {
+				olderThan = time.Nanosecond
+			}
+			policy.UploadResults, policy.Batches, policy.Collections = olderThan, olderThan, olderThan
+		}
+		if !targets[STATE_UPLOAD_RESULTS] {
+			policy.UploadResults = 0
+		}
+		if !targets[STATE_BATCHES] {
+			policy.Batches = 0
+		}
+		if !targets[STATE_COLLECTIONS] {
+			policy.Collections = 0
+		}
+		purged = s.purgeStaleState(policy)
 	}
 	
-	// Aplicar atualizações campo por campo de forma segura
-	if enabled, ok := configData["enabled"].(bool); ok {
-		currentConfig.Enabled = enabled
+	log.Printf("🧹 Internal state purged: %d upload result set(s), %d batch(es), %d collection(s)",
+		len(purged.UploadResults), len(purged.Batches), len(purged.Collections))
+	
+	return conn.Send(wsmanager.Response{
+		Status:    "internal_state_purged",
+		RequestID: msg.RequestID,
+		Data:      purged,
+	})
+}
+
+// cleanupStateFiles applies the collection_state retention and logs what was removed
+func (s *HighPerformanceServer) cleanupStateFiles(policy collection.StatePolicy) {
+	if policy.MaxAge == 0 && policy.MaxCount == 0 {
+		return
 	}
-	if langPref, ok := configData["language_preference"].(string); ok {
-		currentConfig.LanguagePreference = anilist.LanguagePreference(langPref)
+	cleanup, err := s.collectionProcessor.CleanupStateFiles(policy, false)
+	if err != nil {
+		log.Printf("⚠️ %v", err)
+		return
 	}
-	if fillMode, ok := configData["fill_mode"].(string); ok {
-		currentConfig.FillMode = anilist.FillMode(fillMode)
+	for _, message := range cleanup.Errors {
+		log.Printf("⚠️ %s", message)
 	}
-	if autoSearch, ok := configData["auto_search"].(bool); ok {
-		currentConfig.AutoSearch = autoSearch
+	if len(cleanup.Removed) > 0 {
+		log.Printf("🧹 Removed %d stale collection state(s), %d bytes freed", len(cleanup.Removed), cleanup.FreedBytes)
 	}
-	if cacheEnabled, ok := configData["cache_enabled"].(bool); ok {
-		currentConfig.CacheEnabled = cacheEnabled
+}
+
+// handleCleanupState removes the saved states of finished collections older than
+// olderThan or beyond maxCount (defaults: stateRetention). Resumable, paused and
+// running collections are always kept; dryRun only reports.
+func (s *HighPerformanceServer) handleCleanupState(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid cleanup state request: %v", err)
 	}
-	if preferAniList, ok := configData["prefer_anilist"].(bool); ok {
-		currentConfig.PreferAniList = preferAniList
+	
+	policy := s.config.statePolicy().StateFiles
+	if req.OlderThan != "" || req.MaxCount != 0 {
+		policy = collection.StatePolicy{MaxCount: req.MaxCount}
+		if req.OlderThan != "" {
+			olderThan, err := time.ParseDuration(req.OlderThan)
+			if err != nil || olderThan < 0 {
+				return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid olderThan %q", req.OlderThan)
+			}
+			// Zero removes every finished collection state
+			if olderThan == 0 {
+				olderThan = time.Nanosecond
+			}
+			policy.MaxAge = olderThan
+		}
 	}
 	
-	// Atualizar configuração
-	if err := s.anilistService.UpdateConfig(currentConfig); err != nil {
-		log.Printf("❌ handleUpdateAniListConfig: Erro ao atualizar: %v", err)
+	cleanup, err := s.collectionProcessor.CleanupStateFiles(policy, req.DryRun)
+	if err != nil {
 		return conn.Send(wsmanager.Response{
 			Status:    "error",
-			Error:     "Failed to update config: " + err.Error(),
+			Error:     i18n.T(connLocale(conn), i18n.MsgStateCleanupFailed, err),
+			ErrorCode: wsmanager.ErrIO,
 			RequestID: msg.RequestID,
 		})
 	}
+	if !req.DryRun && len(cleanup.Removed) > 0 {
+		log.Printf("🧹 Removed %d collection state(s), %d bytes freed", len(cleanup.Removed), cleanup.FreedBytes)
+	}
 	
-	// Retornar configuração atualizada
-	updatedConfig := s.anilistService.GetConfig()
-	log.Printf("✅ handleUpdateAniListConfig: Configuração atualizada com sucesso")
-	
+	inventory, _ := s.collectionProcessor.StateInventory(false)
 	return conn.Send(wsmanager.Response{
-		Status:    "config_updated",
-		Data:      updatedConfig,
+		Status:    "state_cleaned",
 		RequestID: msg.RequestID,
+		Data: map[string]interface{}{
+			"cleanup":   cleanup,
+			"inventory": inventory,
+		},
 	})
 }
 
-// handleResetAniListConfig restaura as configurações padrão
-func (s *HighPerformanceServer) handleResetAniListConfig(conn *wsmanager.Connection, msg wsmanager.Message) error {
-	if err := s.anilistService.ResetConfig(); err != nil {
-		return conn.Send(wsmanager.Response{
-			Status:    "error",
-			Error:     "Failed to reset config: " + err.Error(),
-			RequestID: msg.RequestID,
-		})
+// handleImportCubari imports a series already published on cubari.moe into the library:
+// chapters and page URLs go into its JSON, the series into the ID registry and the
+// imported pages into the upload history. updateMode "add" (default) keeps local
+// chapters; "replace" swaps them for the imported ones (old chapters go to the trash).
+func (s *HighPerformanceServer) handleImportCubari(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid cubari import request: %v", err)
 	}
 	
-	// Retornar configuração resetada
-	resetConfig := s.anilistService.GetConfig()
-	return conn.Send(wsmanager.Response{
-		Status:    "config_reset",
-		Data:      resetConfig,
-		RequestID: msg.RequestID,
-	})
-}
-
-// =============================================
-//         GITHUB INTEGRATION HANDLERS
-// =============================================
-
-// handleGitHubFolders lists folders in a GitHub repository
-func (s *HighPerformanceServer) handleGitHubFolders(conn *wsmanager.Connection, msg wsmanager.Message) error {
-	// Log received data for debugging
-	log.Printf("🔍 GitHub folders request: %+v", msg.Data)
-
-	// Extract data directly from msg.Data map
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
+	if req.SourceURL == "" {
 		return conn.Send(wsmanager.Response{
 			Status:    "error",
-			Error:     "Invalid GitHub folders request format",
+			Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, "sourceUrl"),
+			ErrorCode: wsmanager.ErrMissingField,
 			RequestID: msg.RequestID,
 		})
 	}
-
-	token, _ := data["token"].(string)
-	repo, _ := data["repo"].(string)
-	branch, _ := data["branch"].(string)
-	maxDepth := 3 // Default depth for recursion
-	
-	// Check if depth parameter was provided
-	if d, ok := data["maxDepth"].(float64); ok {
-		maxDepth = int(d)
-	}
-
-	if token == "" || repo == "" {
+	if _, err := cubari.ResolveURL(req.SourceURL); err != nil {
 		return conn.Send(wsmanager.Response{
-			Status:    "error", 
-			Error:     "GitHub token and repository are required",
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgCubariImportFailed, err),
+			ErrorCode: wsmanager.ErrInvalidRequest,
 			RequestID: msg.RequestID,
 		})
 	}
-
-	if branch == "" {
-		branch = "main"
-	}
-
+	
 	go func() {
-		log.Printf("Starting GitHub folders listing for repo: %s", repo)
+		ctx, cancel := context.WithTimeout(s.ctx, time.Minute)
+		defer cancel()
+		
+		series, err := cubari.Fetch(ctx, &http.Client{Timeout: time.Minute}, req.SourceURL)
+		if err != nil {
+			log.Printf("❌ Cubari import of %s failed: %v", req.SourceURL, err)
+			safeSend(conn, wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgCubariImportFailed, err),
+				ErrorCode: wsmanager.ErrServiceUnavailable,
+				RequestID: msg.RequestID,
+			})
+			return
+		}
 		
-		// Send progress update
-		progressResponse := wsmanager.Response{
-			Status:    "github_folders_progress",
-			RequestID: msg.RequestID,
-			Progress: &wsmanager.Progress{
-				Current:    0,
-				Total:      1,
-				Percentage: 0,
-				Stage:      "listing_folders",
-			},
+		// The local series ID comes from the request or, by default, from the imported title
+		name := req.Manga
+		if name == "" {
+			name = series.Manga.Title
 		}
-		safeSend(conn, progressResponse)
-
-		// List folders using GitHub service (recursively)
-		folders, err := s.githubService.ListFoldersRecursively(token, repo, branch, maxDepth)
+		mangaID := mangaid.Normalize(name)
+		if mangaID == "" {
+			safeSend(conn, wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgMissingMangaName),
+				ErrorCode: wsmanager.ErrMissingField,
+				RequestID: msg.RequestID,
+			})
+			return
+		}
+		
+		s.metadataMu.Lock()
+		result, err := s.jsonGenerator.ImportJSON(mangaid.Path(s.config.MetadataOutput, mangaID), series.Manga, req.UpdateMode, req.DryRun)
+		s.metadataMu.Unlock()
 		if err != nil {
-			log.Printf("GitHub folders error: %v", err)
-			response := wsmanager.Response{
-				Status:    "github_error",
-				Error:     fmt.Sprintf("Failed to list GitHub folders: %v", err),
+			safeSend(conn, wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgCubariImportFailed, err),
+				ErrorCode: wsmanager.ErrIO,
 				RequestID: msg.RequestID,
-				Data: map[string]interface{}{
-					"error_type": "folders_list_failed",
-					"repo":       repo,
-					"branch":     branch,
-				},
-			}
-			safeSend(conn, response)
+			})
 			return
 		}
-
-		log.Printf("Successfully listed %d folders from GitHub repo %s", len(folders), repo)
-
-		// Send success response
-		response := wsmanager.Response{
-			Status:    "github_folders_complete", 
+		
+		if !req.DryRun {
+			if err := s.idRegistry.Link(mangaID, series.Manga.Title, 0); err != nil {
+				log.Printf("⚠️ Failed to register imported series %s: %v", mangaID, err)
+			}
+			if err := s.uploadHistory.Append(importedRecords(mangaID, result.Imported)...); err != nil {
+				log.Printf("⚠️ Failed to record imported pages in the upload history: %v", err)
+			}
+			log.Printf("📥 Imported %s from Cubari: %d new chapter(s), %d merged, %d page(s)",
+				mangaID, len(result.Added), len(result.Merged), result.Pages)
+		}
+		
+		safeSend(conn, wsmanager.Response{
+			Status:    "cubari_imported",
 			RequestID: msg.RequestID,
 			Data: map[string]interface{}{
-				"folders":     folders,
-				"folderCount": len(folders),
-				"repo":        repo,
-				"branch":      branch,
+				"mangaId": mangaID,
+				"title":   series.Manga.Title,
+				"source":  series,
+				"result":  result,
+				"dryRun":  req.DryRun,
 			},
-		}
-		log.Printf("📤 Sending GitHub folders response: %+v", response)
-		safeSend(conn, response)
+		})
 	}()
-
+	
 	return nil
 }
 
-// handleGitHubUpload uploads JSON files to GitHub repository
-func (s *HighPerformanceServer) handleGitHubUpload(conn *wsmanager.Connection, msg wsmanager.Message) error {
-	// Log received data for debugging
-	log.Printf("🔍 GitHub upload request: %+v", msg.Data)
-
-	// Extract data directly from msg.Data map
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
+// handleExportChapter downloads the hosted pages of a chapter (the URLs in its JSON) into
+// a folder or a CBZ, to re-edit, archive or migrate it when the local files were lost.
+// Pages missing from the main group's host are fetched from its mirrors when available.
+func (s *HighPerformanceServer) handleExportChapter(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid export request: %v", err)
+	}
+	
+	for _, field := range []struct{ name, value string }{{"manga", req.Manga}, {"chapter", req.Chapter}} {
+		if field.value == "" {
+			return conn.Send(wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgFieldRequired, field.name),
+				ErrorCode: wsmanager.ErrMissingField,
+				RequestID: msg.RequestID,
+			})
+		}
+	}
+	if req.Format != "" && req.Format != export.FormatFolder && req.Format != export.FormatCBZ {
 		return conn.Send(wsmanager.Response{
 			Status:    "error",
-			Error:     "Invalid GitHub upload request format",
+			Error:     i18n.T(connLocale(conn), i18n.MsgExportFailed, fmt.Errorf("unsupported format %q", req.Format)),
+			ErrorCode: wsmanager.ErrInvalidRequest,
 			RequestID: msg.RequestID,
 		})
 	}
-
-	// Extract GitHub settings - support both direct fields and githubSettings object
-	var token, repo, branch, folder, updateMode string
-	var selectedWorks []string
-
-	// Try direct fields first
-	token, _ = data["token"].(string)
-	repo, _ = data["repo"].(string)
-	branch, _ = data["branch"].(string)
-	folder, _ = data["folder"].(string)
-	updateMode, _ = data["updateMode"].(string)
-
-	// If direct fields not found, try githubSettings
-	if token == "" {
-		if githubSettings, ok := data["githubSettings"].(map[string]interface{}); ok {
-			if t, ok := githubSettings["token"].(string); ok {
-				token = t
-			}
-			if r, ok := githubSettings["repo"].(string); ok {
-				repo = r
-			}
-			if b, ok := githubSettings["branch"].(string); ok {
-				branch = b
-			}
-			if f, ok := githubSettings["folder"].(string); ok {
-				folder = f
-			}
-			if u, ok := githubSettings["updateMode"].(string); ok {
-				updateMode = u
-			}
-		}
-	}
-
-	// Get selected works from request
-	if works, exists := data["selectedWorks"]; exists {
-		if worksList, ok := works.([]interface{}); ok {
-			for _, work := range worksList {
-				if workStr, ok := work.(string); ok {
-					selectedWorks = append(selectedWorks, workStr)
-				}
-			}
-		}
-	}
-
-	log.Printf("🔍 Parsed GitHub settings - Token: %s, Repo: %s, Branch: %s, Folder: %s, Works: %d", 
-		token[:10]+"...", repo, branch, folder, len(selectedWorks))
-
-	if token == "" || repo == "" {
+	
+	mangaID := mangaid.Normalize(req.Manga)
+	jsonPath, _ := mangaid.Resolve(s.config.MetadataOutput, mangaID)
+	if _, err := os.Stat(jsonPath); err != nil {
 		return conn.Send(wsmanager.Response{
 			Status:    "error",
-			Error:     "GitHub token and repository are required",
+			Error:     i18n.T(connLocale(conn), i18n.MsgJSONNotFound, filepath.Base(jsonPath)),
+			ErrorCode: wsmanager.ErrJSONNotFound,
 			RequestID: msg.RequestID,
 		})
 	}
-
-	if len(selectedWorks) == 0 {
+	
+	s.metadataMu.Lock()
+	chapter, err := export.LoadChapter(jsonPath, req.Chapter, req.Group)
+	s.metadataMu.Unlock()
+	if err != nil {
 		return conn.Send(wsmanager.Response{
-			Status:    "error", 
-			Error:     "No works selected for GitHub upload",
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgExportFailed, err),
+			ErrorCode: wsmanager.ErrInvalidRequest,
 			RequestID: msg.RequestID,
 		})
 	}
-
-	if branch == "" {
-		branch = "main"
+	
+	format := req.Format
+	if format == "" {
+		format = export.FormatFolder
 	}
-
-	if updateMode == "" {
-		updateMode = "smart"
+	outputDir := req.OutputDir
+	if outputDir == "" {
+		outputDir = filepath.Join(s.paths.Exports, mangaID)
 	}
-
+	dest := filepath.Join(outputDir, "chapter_"+chapter.Key)
+	if format == export.FormatCBZ {
+		dest += ".cbz"
+	}
+	
 	go func() {
-		log.Printf("Starting GitHub upload for %d JSON files to repo: %s", len(selectedWorks), repo)
-
-		// Send progress update
-		progressResponse := wsmanager.Response{
-			Status:    "github_upload_progress",
+		log.Printf("📦 Exporting %s chapter %s (%s, %d page(s)) to %s", mangaID, chapter.Key, chapter.Group, len(chapter.Pages), dest)
+		result, err := export.NewExporter().Export(s.ctx, chapter, format, dest, req.Force, func(done, total int) {
+			safeSend(conn, wsmanager.Response{
+				Status:    "export_progress",
+				RequestID: msg.RequestID,
+				Data: map[string]interface{}{
+					"mangaId": mangaID,
+					"chapter": chapter.Key,
+					"done":    done,
+					"total":   total,
+				},
+			})
+		})
+		if err != nil {
+			log.Printf("❌ Export of %s chapter %s failed: %v", mangaID, chapter.Key, err)
+			safeSend(conn, wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgExportFailed, err),
+				ErrorCode: wsmanager.ErrIO,
+				RequestID: msg.RequestID,
+				Data:      map[string]interface{}{"result": result},
+			})
+			return
+		}
+		
+		log.Printf("✅ Exported %s chapter %s: %d/%d page(s), %d byte(s)", mangaID, chapter.Key, result.Pages, result.Total, result.Bytes)
+		safeSend(conn, wsmanager.Response{
+			Status:    "chapter_exported",
 			RequestID: msg.RequestID,
-			Progress: &wsmanager.Progress{
-				Current:    0,
-				Total:      len(selectedWorks),
-				Percentage: 0,
-				Stage:      "preparing_upload",
+			Data: map[string]interface{}{
+				"mangaId": mangaID,
+				"chapter": chapter,
+				"result":  result,
 			},
-		}
-		safeSend(conn, progressResponse)
-
-		// Collect JSON files to upload
-		jsonFiles := make(map[string]string)
-		jsonOutputDir := s.config.MetadataOutput
-		if jsonOutputDir == "" {
-			jsonOutputDir = "json"
-		}
-
-		for i, work := range selectedWorks {
-			// Progress update
-			progressResponse.Progress.Current = i
-			progressResponse.Progress.Percentage = int((float64(i) / float64(len(selectedWorks))) * 100)
-			progressResponse.Progress.Stage = fmt.Sprintf("reading_json_%d", i+1)
-			safeSend(conn, progressResponse)
-
-			// Sanitize work name for filename
-			sanitizedWorkName := sanitizeFilename(work)
-			jsonFileName := fmt.Sprintf("%s.json", sanitizedWorkName)
-			jsonFilePath := filepath.Join(jsonOutputDir, jsonFileName)
-
-			// Read JSON file
-			jsonContent, err := os.ReadFile(jsonFilePath)
-			if err != nil {
-				log.Printf("⚠️ Failed to read JSON file %s: %v", jsonFilePath, err)
-				// Continue with other files instead of failing completely
-				continue
-			}
+		})
+	}()
+	
+	return nil
+}
 
-			jsonFiles[jsonFileName] = string(jsonContent)
-			log.Printf("✅ Added JSON file: %s (%d bytes)", jsonFileName, len(jsonContent))
+// handleVerifyBackup checks the local backup against the upload history: every uploaded
+// file needs a copy with the recorded SHA-256. With fetch, missing or corrupted copies are
+// downloaded from the hosts (main URL, then mirrors), which also backs up files uploaded
+// before the backup was enabled. manga limits the check to one series.
+func (s *HighPerformanceServer) handleVerifyBackup(conn *wsmanager.Connection, msg wsmanager.Message) error {
+	var req WebSocketRequest
+	reqData, _ := json.Marshal(msg.Data)
+	if err := json.Unmarshal(reqData, &req); err != nil {
+		return wsmanager.Errorf(wsmanager.ErrInvalidRequest, "invalid backup verification request: %v", err)
+	}
+	
+	records, err := s.uploadHistory.Load()
+	if err != nil {
+		return conn.Send(wsmanager.Response{
+			Status:    "error",
+			Error:     i18n.T(connLocale(conn), i18n.MsgBackupFailed, err),
+			ErrorCode: wsmanager.ErrIO,
+			RequestID: msg.RequestID,
+		})
+	}
+	
+	mangaID := ""
+	if req.Manga != "" {
+		mangaID = mangaid.Normalize(req.Manga)
+	}
+	
+	go func() {
+		options := backup.VerifyOptions{
+			MangaID: mangaID,
+			Fetch:   req.Fetch,
+			Client:  &http.Client{Timeout: 2 * time.Minute},
 		}
-
-		if len(jsonFiles) == 0 {
-			response := wsmanager.Response{
-				Status:    "github_error",
-				Error:     "No JSON files found to upload",
-				RequestID: msg.RequestID,
+		lastSent := time.Time{}
+		report, err := s.backupStore.Verify(s.ctx, records, options, func(done, total int) {
+			// Large histories verify thousands of files; progress is throttled
+			if done < total && time.Since(lastSent) < time.Second {
+				return
 			}
-			safeSend(conn, response)
-			return
-		}
-
-		log.Printf("📦 Prepared %d JSON files for GitHub upload", len(jsonFiles))
-
-		// Upload to GitHub
-		progressResponse.Progress.Stage = "uploading_to_github"
-		progressResponse.Progress.Percentage = 90
-		safeSend(conn, progressResponse)
-
-		commitResponse, err := s.githubService.UploadJSONFiles(token, repo, branch, folder, jsonFiles)
-		if err != nil {
-			log.Printf("GitHub upload error: %v", err)
-			response := wsmanager.Response{
-				Status:    "github_error",
-				Error:     fmt.Sprintf("Failed to upload to GitHub: %v", err),
+			lastSent = time.Now()
+			safeSend(conn, wsmanager.Response{
+				Status:    "backup_progress",
 				RequestID: msg.RequestID,
 				Data: map[string]interface{}{
-					"error_type":    "upload_failed",
-					"repo":          repo,
-					"branch":        branch,
-					"folder":        folder,
-					"files_count":   len(jsonFiles),
+					"done":  done,
+					"total": total,
 				},
-			}
-			safeSend(conn, response)
+			})
+		})
+		if err != nil {
+			log.Printf("❌ Backup verification failed: %v", err)
+			safeSend(conn, wsmanager.Response{
+				Status:    "error",
+				Error:     i18n.T(connLocale(conn), i18n.MsgBackupFailed, err),
+				ErrorCode: wsmanager.ErrIO,
+				RequestID: msg.RequestID,
+			})
 			return
 		}
-
-		log.Printf("✅ Successfully uploaded %d JSON files to GitHub repo %s", len(jsonFiles), repo)
-
-		// Send success response
-		response := wsmanager.Response{
-			Status:    "github_upload_complete",
+		
+		log.Printf("💾 Backup verified: %d file(s), %d verified, %d unhashed, %d missing, %d mismatched, %d fetched",
+			report.Files, report.Verified, report.Unhashed, report.Missing, report.Mismatch, report.Fetched)
+		safeSend(conn, wsmanager.Response{
+			Status:    "backup_verified",
 			RequestID: msg.RequestID,
 			Data: map[string]interface{}{
-				"commit":        commitResponse,
-				"uploadedCount": len(jsonFiles),
-				"repo":          repo,
-				"branch":        branch,
-				"folder":        folder,
-				"updateMode":    updateMode,
-				"uploadedFiles": jsonFiles,
+				"report":  report,
+				"enabled": s.config.Backup != nil && s.config.Backup.Enabled,
 			},
-		}
-		safeSend(conn, response)
+		})
 	}()
-
+	
 	return nil
+}
+
+// importedRecords turns imported chapters into upload history records, dated by the
+// chapter's last_updated so analytics keep the original publication dates
+func importedRecords(mangaID string, chapters map[string]metadata.Chapter) []analytics.Record {
+	var records []analytics.Record
+	for index, chapter := range chapters {
+		var published time.Time
+		if seconds, err := strconv.ParseInt(chapter.LastUpdated, 10, 64); err == nil && seconds > 0 {
+			published = time.Unix(seconds, 0)
+		}
+		for group, urls := range chapter.Groups {
+			for _, pageURL := range urls {
+				records = append(records, analytics.Record{
+					Time:    published,
+					MangaID: mangaID,
+					Chapter: index,
+					Group:   group,
+					Host:    metadata.URLHost(pageURL),
+					URL:     pageURL,
+				})
+			}
+		}
+	}
+	return records
 }
\ No newline at end of file
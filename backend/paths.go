@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// DEFAULT_DATA_DIR holds all on-disk state (a single volume mount in containers)
+const DEFAULT_DATA_DIR = "data"
+
+// LEGACY_JSON_DIR is where JSON metadata was written before the data directory existed
+const LEGACY_JSON_DIR = "json"
+
+// DataPaths lists every on-disk location the server writes to, all derived from DataDir
+type DataPaths struct {
+	DataDir         string `json:"dataDir"`
+	JSONOutput      string `json:"jsonOutput"`      // Individual manga JSONs
+	CollectionState string `json:"collectionState"` // Prefix of <prefix>_<id>.json job states and journals
+	AniList         string `json:"anilist"`         // AniList config, search cache and offline database
+	AniListCache    string `json:"anilistCache"`
+	Covers          string `json:"covers"`         // Cached cover images served over /covers/
+	Registry        string `json:"registry"`       // Local manga ↔ provider ID mapping
+	TitleOverrides  string `json:"titleOverrides"` // Folder name → canonical title/provider ID, consulted before searching
+	AutoFillState   string `json:"autoFillState"`
+	FieldLocks      string `json:"fieldLocks"`    // Per-manga metadata fields protected from automatic updates
+	CorruptReport   string `json:"corruptReport"` // Repaired and quarantined (.corrupt) JSONs
+	ShareSecret     string `json:"shareSecret"`   // Key signing read-only collection progress links
+	UploadHistory   string `json:"uploadHistory"` // Successful uploads (JSON Lines) used by analytics
+	Notifications   string `json:"notifications"` // Important events with read/unread state (get_notifications)
+	Spool           string `json:"spool"`         // Temporary files of uploads in progress
+	ChunkSessions   string `json:"chunkSessions"` // Resumable chunked uploads (tus, S3 multipart) in progress
+	Site            string `json:"site"`          // Static reader site (generate_static_site)
+	Plugins         string `json:"plugins"`       // Uploader and metadata provider plugins loaded at startup
+	Exports         string `json:"exports"`       // Chapters downloaded back from their hosts (export_chapter)
+	Backup          string `json:"backup"`        // Local copy of every uploaded file, by manga and chapter
+	LibraryRoot     string `json:"libraryRoot"`   // Input library (not under DataDir, usually its own mount)
+}
+
+// resolveDataPaths derives the on-disk layout from the configuration:
+//
+//	<dataDir>/json/                  JSON metadata (unless metadataOutput is set)
+//	<dataDir>/state/collection_*     collection job states and file journals
+//	<dataDir>/anilist_*.json         AniList config, search cache and offline database
+//	<dataDir>/covers/                cached cover images
+//	<dataDir>/id_registry.json       local manga ↔ AniList IDs
+//	<dataDir>/title_overrides.json   folder name → canonical title/provider ID (user-editable)
+//	<dataDir>/autofill_state.json    metadata auto-fill job
+//	<dataDir>/metadata_locks.json    locked metadata fields per manga
+//	<dataDir>/corrupt_jsons.json     repaired and quarantined JSONs
+//	<dataDir>/share_secret           key of the shared progress links
+//	<dataDir>/upload_history.jsonl   successful uploads per series, group and host
+//	<dataDir>/notifications.json     failures, finished collections, takedowns and pending approvals
+//	<dataDir>/spool/                 temporary upload files
+//	<dataDir>/state/chunk_sessions.json  chunked uploads to resume
+//	<dataDir>/site/                  static reader site (unless site.outputDir is set)
+//	<dataDir>/plugins/               plugin executables and manifests (unless pluginDir is set)
+//	<dataDir>/exports/<manga>/       chapters exported from their hosted pages
+//	<dataDir>/backup/<manga>/<ch>/   copies of uploaded files (unless backup.root is set)
+func resolveDataPaths(config *ServerConfig) DataPaths {
+	dataDir := config.DataDir
+	if dataDir == "" {
+		dataDir = DEFAULT_DATA_DIR
+	}
+
+	jsonOutput := config.MetadataOutput
+	if jsonOutput == "" {
+		jsonOutput = filepath.Join(dataDir, "json")
+
+		// Keep using ./json for installs that predate the data directory
+		if !dirExists(jsonOutput) && dirExists(LEGACY_JSON_DIR) {
+			log.Printf("⚠️ Using legacy JSON directory %s; move it to %s to use the data directory layout", LEGACY_JSON_DIR, jsonOutput)
+			jsonOutput = LEGACY_JSON_DIR
+		}
+	}
+
+	pluginDir := config.PluginDir
+	if pluginDir == "" {
+		pluginDir = filepath.Join(dataDir, "plugins")
+	}
+
+	backupDir := filepath.Join(dataDir, "backup")
+	if config.Backup != nil && config.Backup.Root != "" {
+		backupDir = config.Backup.Root
+	}
+
+	return DataPaths{
+		DataDir:         dataDir,
+		JSONOutput:      jsonOutput,
+		CollectionState: filepath.Join(dataDir, "state", "collection"),
+		AniList:         dataDir,
+		AniListCache:    filepath.Join(dataDir, "anilist_cache.json"),
+		Covers:          filepath.Join(dataDir, "covers"),
+		Registry:        filepath.Join(dataDir, "id_registry.json"),
+		TitleOverrides:  filepath.Join(dataDir, "title_overrides.json"),
+		AutoFillState:   filepath.Join(dataDir, "autofill_state.json"),
+		FieldLocks:      filepath.Join(dataDir, "metadata_locks.json"),
+		CorruptReport:   filepath.Join(dataDir, "corrupt_jsons.json"),
+		ShareSecret:     filepath.Join(dataDir, "share_secret"),
+		UploadHistory:   filepath.Join(dataDir, "upload_history.jsonl"),
+		Notifications:   filepath.Join(dataDir, "notifications.json"),
+		Spool:           filepath.Join(dataDir, "spool"),
+		ChunkSessions:   filepath.Join(dataDir, "state", "chunk_sessions.json"),
+		Site:            filepath.Join(dataDir, "site"),
+		Plugins:         pluginDir,
+		Exports:         filepath.Join(dataDir, "exports"),
+		Backup:          backupDir,
+		LibraryRoot:     config.LibraryRoot,
+	}
+}
+
+// ensure creates the directories of the layout
+func (p DataPaths) ensure() error {
+	for _, dir := range []string{p.DataDir, p.JSONOutput, filepath.Dir(p.CollectionState), p.Covers, p.Spool} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// dirExists reports whether path exists and is a directory
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
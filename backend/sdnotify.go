@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sd_notify states sent to systemd (Type=notify units)
+const (
+	SD_READY     = "READY=1"
+	SD_STOPPING  = "STOPPING=1"
+	SD_RELOADING = "RELOADING=1"
+	SD_WATCHDOG  = "WATCHDOG=1"
+)
+
+// sdNotify sends a state to the systemd notification socket.
+// Returns false without error when not running under systemd (NOTIFY_SOCKET unset).
+func sdNotify(state string) (bool, error) {
+	socketAddr := os.Getenv("NOTIFY_SOCKET")
+	if socketAddr == "" {
+		return false, nil
+	}
+
+	// '@' denotes a socket in the Linux abstract namespace
+	if strings.HasPrefix(socketAddr, "@") {
+		socketAddr = "\x00" + socketAddr[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketAddr, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sdWatchdogInterval returns how often WATCHDOG=1 must be sent (half of WatchdogSec),
+// or 0 when the systemd watchdog is not enabled for this process
+func sdWatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+
+	return time.Duration(usec) * time.Microsecond / 2
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DEFAULT_SERVICE_NAME is the systemd unit / Windows task name used by "service install"
+const DEFAULT_SERVICE_NAME = "go-upload"
+
+// systemdUnitTemplate runs the server supervised by systemd: Type=notify waits for
+// READY=1, the watchdog restarts a hung process and --resume continues interrupted collections
+const systemdUnitTemplate = `[Unit]
+Description=go-upload manga upload server
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=notify
+NotifyAccess=main
+ExecStart=%s
+WorkingDirectory=%s
+%sRestart=on-failure
+RestartSec=5
+WatchdogSec=30
+TimeoutStopSec=60
+KillSignal=SIGTERM
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// serviceOptions holds the flags of the "service" subcommand
+type serviceOptions struct {
+	Name       string
+	ConfigPath string
+	Profile    string
+	User       string
+	WorkDir    string
+}
+
+// runServiceCommand handles "go-upload service install|uninstall [flags]"
+func runServiceCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s service install|uninstall [flags]", filepath.Base(os.Args[0]))
+	}
+
+	action := args[0]
+	fs := flag.NewFlagSet("service "+action, flag.ExitOnError)
+	opts := serviceOptions{}
+	fs.StringVar(&opts.Name, "name", DEFAULT_SERVICE_NAME, "service name")
+	fs.StringVar(&opts.ConfigPath, "config", DEFAULT_CONFIG_FILE, "configuration file passed to the service")
+	fs.StringVar(&opts.Profile, "profile", "", "configuration profile passed to the service")
+	fs.StringVar(&opts.User, "user", "", "user the service runs as (systemd only)")
+	fs.StringVar(&opts.WorkDir, "workdir", "", "working directory of the service (default: current directory)")
+	fs.Parse(args[1:])
+
+	switch action {
+	case "install":
+		return installService(opts)
+	case "uninstall":
+		return uninstallService(opts)
+	default:
+		return fmt.Errorf("unknown service action %q (expected install or uninstall)", action)
+	}
+}
+
+// serviceCommandLine returns the executable and arguments the service starts with
+func serviceCommandLine(opts serviceOptions) (string, []string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve executable path: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	configPath, err := filepath.Abs(opts.ConfigPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve config path: %w", err)
+	}
+
+	args := []string{"--config", configPath, "--resume"}
+	if opts.Profile != "" {
+		args = append(args, "--profile", opts.Profile)
+	}
+	return exe, args, nil
+}
+
+// installService registers the server as a systemd unit (Linux) or a startup task (Windows)
+func installService(opts serviceOptions) error {
+	exe, args, err := serviceCommandLine(opts)
+	if err != nil {
+		return err
+	}
+
+	workDir := opts.WorkDir
+	if workDir == "" {
+		if workDir, err = os.Getwd(); err != nil {
+			return fmt.Errorf("failed to resolve working directory: %w", err)
+		}
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		user := ""
+		if opts.User != "" {
+			user = "User=" + opts.User + "\n"
+		}
+		unit := fmt.Sprintf(systemdUnitTemplate, quoteCommandLine(exe, args), workDir, user)
+
+		unitPath := systemdUnitPath(opts.Name)
+		if err := os.WriteFile(unitPath, []byte(unit), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", unitPath, err)
+		}
+		if err := runCommand("systemctl", "daemon-reload"); err != nil {
+			return err
+		}
+		if err := runCommand("systemctl", "enable", "--now", opts.Name+".service"); err != nil {
+			return err
+		}
+		fmt.Printf("Installed %s (check with: systemctl status %s)\n", unitPath, opts.Name)
+		return nil
+
+	case "windows":
+		// Without a service control handler the server runs as a SYSTEM task started at boot
+		command := fmt.Sprintf(`cmd /c cd /d "%s" && %s`, workDir, quoteCommandLine(exe, args))
+		if err := runCommand("schtasks", "/Create", "/TN", opts.Name, "/TR", command,
+			"/SC", "ONSTART", "/RU", "SYSTEM", "/RL", "HIGHEST", "/F"); err != nil {
+			return err
+		}
+		if err := runCommand("schtasks", "/Run", "/TN", opts.Name); err != nil {
+			return err
+		}
+		fmt.Printf("Installed scheduled task %s\n", opts.Name)
+		return nil
+	}
+
+	return fmt.Errorf("service install is not supported on %s", runtime.GOOS)
+}
+
+// uninstallService stops and removes the service created by installService
+func uninstallService(opts serviceOptions) error {
+	switch runtime.GOOS {
+	case "linux":
+		if err := runCommand("systemctl", "disable", "--now", opts.Name+".service"); err != nil {
+			return err
+		}
+		unitPath := systemdUnitPath(opts.Name)
+		if err := os.Remove(unitPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", unitPath, err)
+		}
+		if err := runCommand("systemctl", "daemon-reload"); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s\n", unitPath)
+		return nil
+
+	case "windows":
+		// A task that is not running makes /End fail; only /Delete matters
+		runCommand("schtasks", "/End", "/TN", opts.Name)
+		if err := runCommand("schtasks", "/Delete", "/TN", opts.Name, "/F"); err != nil {
+			return err
+		}
+		fmt.Printf("Removed scheduled task %s\n", opts.Name)
+		return nil
+	}
+
+	return fmt.Errorf("service uninstall is not supported on %s", runtime.GOOS)
+}
+
+// systemdUnitPath returns where the unit file of a service is installed
+func systemdUnitPath(name string) string {
+	return filepath.Join("/etc/systemd/system", name+".service")
+}
+
+// quoteCommandLine joins a command line quoting arguments that contain spaces
+func quoteCommandLine(exe string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	for _, part := range append([]string{exe}, args...) {
+		if strings.ContainsAny(part, " \t") {
+			part = `"` + part + `"`
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// runCommand runs an external command forwarding its output
+func runCommand(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s failed: %w", name, strings.Join(args, " "), err)
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"go-upload/backend/internal/client"
+	"go-upload/backend/internal/monitoring"
+	"go-upload/backend/internal/upload"
+)
+
+// TOP_RECENT_ERRORS is how many errors the "top" screen keeps
+const TOP_RECENT_ERRORS = 8
+
+// topOptions holds the flags of the "top" subcommand
+type topOptions struct {
+	URL      string
+	Interval time.Duration
+	Once     bool
+}
+
+// topSnapshot is what one refresh of the "top" screen shows
+type topSnapshot struct {
+	Version       string
+	Uptime        string
+	Connections   int
+	UploadsHalted bool
+	Upload        upload.WorkerStats
+	PoolActive    int
+	PoolTotal     int
+	PoolQueued    int
+	Hosts         map[string]monitoring.HostStats
+	Breakers      map[string]upload.BreakerStatus
+	Jobs          []topJob
+	Transferred   int64
+}
+
+// topJob is an active batch or collection as listed by list_jobs
+type topJob struct {
+	Kind     string          `json:"kind"`
+	ID       string          `json:"id"`
+	Name     string          `json:"name"`
+	State    string          `json:"state"`
+	Progress JobListProgress `json:"progress"`
+	Detail   struct {
+		Progress struct {
+			BytesTransferred int64 `json:"bytesTransferred"`
+		} `json:"progress"`
+	} `json:"detail"`
+}
+
+// topError is an error event received from the server
+type topError struct {
+	Time    time.Time
+	Message string
+}
+
+// runTopCommand handles "go-upload top [flags]": a live terminal view of a running
+// server (workers, per-host throughput, active jobs and recent errors) for headless hosts
+func runTopCommand(args []string) error {
+	fs := flag.NewFlagSet("top", flag.ExitOnError)
+	opts := topOptions{}
+	fs.StringVar(&opts.URL, "url", client.DefaultURL, "WebSocket address of the server")
+	fs.DurationVar(&opts.Interval, "interval", 2*time.Second, "refresh interval")
+	fs.BoolVar(&opts.Once, "once", false, "print one snapshot and exit (no screen redraw)")
+	fs.Parse(args)
+	if opts.Interval < 200*time.Millisecond {
+		opts.Interval = 200 * time.Millisecond
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dialCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	conn, err := client.Dial(dialCtx, opts.URL)
+	cancel()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var errors []topError
+	var previous *topSnapshot
+	var previousAt time.Time
+	if !opts.Once {
+		fmt.Print("\033[?25l") // hide the cursor while redrawing
+		defer fmt.Print("\033[?25h\n")
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	for {
+		snapshot, err := fetchTopSnapshot(ctx, conn)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		now := time.Now()
+		screen := renderTop(opts.URL, snapshot, previous, now.Sub(previousAt), errors, now)
+		if opts.Once {
+			fmt.Print(screen)
+			return nil
+		}
+		fmt.Print("\033[H\033[2J" + screen)
+		previous, previousAt = snapshot, now
+
+		for waiting := true; waiting; {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-conn.Done():
+				return conn.Err()
+			case event, ok := <-conn.Events():
+				if !ok {
+					return conn.Err()
+				}
+				if message := topErrorMessage(event); message != "" {
+					errors = append(errors, topError{Time: time.Now(), Message: message})
+					if len(errors) > TOP_RECENT_ERRORS {
+						errors = errors[len(errors)-TOP_RECENT_ERRORS:]
+					}
+				}
+			case <-ticker.C:
+				waiting = false
+			}
+		}
+	}
+}
+
+// fetchTopSnapshot queries the server for one refresh of the screen
+func fetchTopSnapshot(ctx context.Context, conn *client.Client) (*topSnapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	snapshot := &topSnapshot{Breakers: make(map[string]upload.BreakerStatus)}
+
+	response, err := conn.Request(ctx, "get_status", nil)
+	if err != nil {
+		return nil, fmt.Errorf("get_status: %w", err)
+	}
+	var status struct {
+		Version       string                 `json:"version"`
+		Uptime        string                 `json:"uptime"`
+		Connections   int                    `json:"connections"`
+		UploadsHalted bool                   `json:"uploadsHalted"`
+		HostBreakers  []upload.BreakerStatus `json:"hostBreakers"`
+	}
+	if err := response.Decode(&status); err != nil {
+		return nil, fmt.Errorf("get_status: %w", err)
+	}
+	snapshot.Version, snapshot.Uptime = status.Version, status.Uptime
+	snapshot.Connections, snapshot.UploadsHalted = status.Connections, status.UploadsHalted
+	for _, breaker := range status.HostBreakers {
+		snapshot.Breakers[breaker.Host] = breaker
+	}
+
+	response, err = conn.Request(ctx, "get_worker_stats", nil)
+	if err != nil {
+		return nil, fmt.Errorf("get_worker_stats: %w", err)
+	}
+	var workers struct {
+		UploadWorkers upload.WorkerStats `json:"uploadWorkers"`
+		WorkerPool    struct {
+			ActiveWorkers int            `json:"active_workers"`
+			TotalWorkers  int            `json:"total_workers"`
+			QueueSizes    map[string]int `json:"queue_sizes"`
+		} `json:"workerPool"`
+	}
+	if err := response.Decode(&workers); err != nil {
+		return nil, fmt.Errorf("get_worker_stats: %w", err)
+	}
+	snapshot.Upload = workers.UploadWorkers
+	snapshot.PoolActive, snapshot.PoolTotal = workers.WorkerPool.ActiveWorkers, workers.WorkerPool.TotalWorkers
+	for _, size := range workers.WorkerPool.QueueSizes {
+		snapshot.PoolQueued += size
+	}
+
+	response, err = conn.Request(ctx, "get_metrics", map[string]interface{}{
+		"sections": []string{"hosts"},
+		"fields":   []string{"metrics.bytesTransferred"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get_metrics: %w", err)
+	}
+	var metrics struct {
+		Metrics monitoring.Metrics              `json:"metrics"`
+		Hosts   map[string]monitoring.HostStats `json:"hosts"`
+	}
+	if err := response.Decode(&metrics); err != nil {
+		return nil, fmt.Errorf("get_metrics: %w", err)
+	}
+	snapshot.Hosts = metrics.Hosts
+	snapshot.Transferred = metrics.Metrics.BytesTransferred
+
+	response, err = conn.Request(ctx, "list_jobs", map[string]interface{}{"activeOnly": true})
+	if err != nil {
+		return nil, fmt.Errorf("list_jobs: %w", err)
+	}
+	var jobs struct {
+		Jobs []topJob `json:"jobs"`
+	}
+	if err := response.Decode(&jobs); err != nil {
+		return nil, fmt.Errorf("list_jobs: %w", err)
+	}
+	snapshot.Jobs = jobs.Jobs
+	return snapshot, nil
+}
+
+// topErrorMessage turns an error event (failed upload, degraded host, batch with
+// errors) into a line of the recent errors list; other events return ""
+func topErrorMessage(event client.Response) string {
+	switch event.Status {
+	case "error":
+		var result struct {
+			Host string `json:"host"`
+		}
+		event.Decode(&result)
+		message := event.Error
+		if event.File != "" {
+			message = event.File + ": " + message
+		}
+		if result.Host != "" {
+			message = result.Host + " " + message
+		}
+		return message
+	case "host_degraded":
+		var breaker upload.BreakerStatus
+		event.Decode(&breaker)
+		return fmt.Sprintf("%s degraded after %d failures: %s", breaker.Host, breaker.Failures, breaker.LastError)
+	case "batch_complete_with_errors":
+		var batch struct {
+			BatchID string `json:"batchId"`
+			Failed  int64  `json:"failed"`
+			Total   int64  `json:"total"`
+		}
+		event.Decode(&batch)
+		return fmt.Sprintf("batch %s finished with %d/%d failed", batch.BatchID, batch.Failed, batch.Total)
+	}
+	return ""
+}
+
+// renderTop draws one screen; throughput comes from the host totals of the previous refresh
+func renderTop(url string, snapshot, previous *topSnapshot, elapsed time.Duration, errors []topError, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "go-upload top — %s   v%s   up %s   %d connection(s)   %s\n", url, snapshot.Version, snapshot.Uptime, snapshot.Connections, now.Format("15:04:05"))
+	if snapshot.UploadsHalted {
+		b.WriteString("⛔ UPLOADS HALTED (emergency stop or safe mode)\n")
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "Upload workers   %s %3d/%-3d busy, %d queued\n",
+		topBar(snapshot.Upload.Active, snapshot.Upload.Total), snapshot.Upload.Active, snapshot.Upload.Total, snapshot.Upload.Queued)
+	fmt.Fprintf(&b, "Collection pool  %s %3d/%-3d busy, %d queued\n",
+		topBar(snapshot.PoolActive, snapshot.PoolTotal), snapshot.PoolActive, snapshot.PoolTotal, snapshot.PoolQueued)
+	fmt.Fprintf(&b, "Transferred      %s (retries and mirrors included)\n\n", formatTopBytes(snapshot.Transferred))
+
+	hosts := make([]string, 0, len(snapshot.Hosts)+len(snapshot.Breakers))
+	for host := range snapshot.Hosts {
+		hosts = append(hosts, host)
+	}
+	for host := range snapshot.Breakers {
+		if _, seen := snapshot.Hosts[host]; !seen {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	fmt.Fprintf(&b, "%-16s %9s %8s %12s %12s  %s\n", "HOST", "UPLOADS", "FAILED", "RATE", "TOTAL", "BREAKER")
+	for _, host := range hosts {
+		stats := snapshot.Hosts[host]
+		rate := "-"
+		if previous != nil && elapsed > 0 {
+			delta := stats.Bytes - previous.Hosts[host].Bytes
+			rate = formatTopBytes(int64(float64(delta)/elapsed.Seconds())) + "/s"
+		}
+		breaker := "-"
+		if status, ok := snapshot.Breakers[host]; ok {
+			breaker = string(status.State)
+		}
+		fmt.Fprintf(&b, "%-16s %9d %8d %12s %12s  %s\n", host, stats.Uploads, stats.Failed, rate, formatTopBytes(stats.Bytes), breaker)
+	}
+
+	b.WriteString("\nACTIVE JOBS\n")
+	if len(snapshot.Jobs) == 0 {
+		b.WriteString("  (none)\n")
+	}
+	for _, job := range snapshot.Jobs {
+		name := job.ID
+		if job.Name != "" {
+			name = job.Name
+		}
+		fmt.Fprintf(&b, "  %-10s %-28s %-9s %6d/%-6d %5.1f%%  %d failed  %s sent\n",
+			job.Kind, topTruncate(name, 28), job.State, job.Progress.Done, job.Progress.Total, job.Progress.Percentage,
+			job.Progress.Failed, formatTopBytes(job.Detail.Progress.BytesTransferred))
+	}
+
+	b.WriteString("\nRECENT ERRORS\n")
+	if len(errors) == 0 {
+		b.WriteString("  (none since start)\n")
+	}
+	for i := len(errors) - 1; i >= 0; i-- {
+		fmt.Fprintf(&b, "  %s  %s\n", errors[i].Time.Format("15:04:05"), topTruncate(errors[i].Message, 110))
+	}
+	b.WriteString("\nCtrl+C to quit\n")
+	return b.String()
+}
+
+// topBar draws a utilization bar
+func topBar(used, total int) string {
+	const width = 20
+	filled := 0
+	if total > 0 {
+		filled = min(width, used*width/total)
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(".", width-filled) + "]"
+}
+
+// topTruncate shortens text to limit runes
+func topTruncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// formatTopBytes prints a byte count with a binary unit
+func formatTopBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -2,16 +2,22 @@ package uploaders
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math"
 	"net/http"
+	"path"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/wabarc/go-catbox"
+	"go-upload/backend/internal/retry"
+	"go-upload/backend/internal/upload"
 )
 
+// CatboxMaxFileSize é o tamanho máximo de arquivo aceito pelo Catbox (200 MB)
+const CatboxMaxFileSize = 200 * 1024 * 1024
+
 // CircuitBreakerState representa os estados do circuit breaker
 type CircuitBreakerState int32
 
@@ -27,6 +33,8 @@ type CircuitBreaker struct {
 	timeout         time.Duration
 	failureCount    int32
 	lastFailTime    time.Time
+	lastError       string
+	changedAt       time.Time
 	state           CircuitBreakerState
 	mutex           sync.RWMutex
 	onStateChange   func(from, to CircuitBreakerState)
@@ -60,6 +68,7 @@ type CatboxUploader struct {
 	connPool         *ConnectionPool
 	circuitBreaker   *CircuitBreaker
 	rateLimiter      *AdaptiveRateLimiter
+	breakerListener  func(upload.BreakerStatus)
 	
 	// Configuration
 	maxRetries       int
@@ -98,12 +107,14 @@ func (cb *CircuitBreaker) Execute(fn func() error) error {
 
 	switch state {
 	case Open:
-		cb.mutex.RLock()
+		cb.mutex.Lock()
 		if time.Since(cb.lastFailTime) > cb.timeout {
-			cb.mutex.RUnlock()
-			cb.setState(HalfOpen)
+			if cb.state == Open {
+				cb.setState(HalfOpen)
+			}
+			cb.mutex.Unlock()
 		} else {
-			cb.mutex.RUnlock()
+			cb.mutex.Unlock()
 			return fmt.Errorf("circuit breaker is open")
 		}
 	case HalfOpen:
@@ -112,7 +123,7 @@ func (cb *CircuitBreaker) Execute(fn func() error) error {
 
 	err := fn()
 	if err != nil {
-		cb.onFailure()
+		cb.onFailure(err)
 		return err
 	}
 
@@ -132,22 +143,24 @@ func (cb *CircuitBreaker) onSuccess() {
 }
 
 // onFailure é chamado quando uma operação falha
-func (cb *CircuitBreaker) onFailure() {
+func (cb *CircuitBreaker) onFailure(err error) {
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
 
 	cb.failureCount++
 	cb.lastFailTime = time.Now()
+	cb.lastError = err.Error()
 
-	if cb.failureCount >= cb.maxFailures {
+	if cb.failureCount >= cb.maxFailures && cb.state != Open {
 		cb.setState(Open)
 	}
 }
 
-// setState muda o estado do circuit breaker
+// setState muda o estado do circuit breaker (chamado com o mutex travado)
 func (cb *CircuitBreaker) setState(newState CircuitBreakerState) {
 	oldState := cb.state
 	cb.state = newState
+	cb.changedAt = time.Now()
 
 	if cb.onStateChange != nil {
 		cb.onStateChange(oldState, newState)
@@ -161,6 +174,32 @@ func (cb *CircuitBreaker) GetState() CircuitBreakerState {
 	return cb.state
 }
 
+// Status retorna o estado no formato exibido aos clientes
+func (cb *CircuitBreaker) Status() upload.BreakerStatus {
+	cb.mutex.RLock()
+	defer cb.mutex.RUnlock()
+	return cb.statusLocked()
+}
+
+func (cb *CircuitBreaker) statusLocked() upload.BreakerStatus {
+	status := upload.BreakerStatus{
+		Failures:  int(cb.failureCount),
+		Since:     cb.changedAt,
+		LastError: cb.lastError,
+	}
+	switch cb.state {
+	case Open:
+		status.State = upload.BreakerOpen
+		retryAt := cb.lastFailTime.Add(cb.timeout)
+		status.RetryAt = &retryAt
+	case HalfOpen:
+		status.State = upload.BreakerHalfOpen
+	default:
+		status.State = upload.BreakerClosed
+	}
+	return status
+}
+
 // NewAdaptiveRateLimiter cria um novo rate limiter adaptativo
 func NewAdaptiveRateLimiter(initialRate, maxRate, minRate int64) *AdaptiveRateLimiter {
 	rl := &AdaptiveRateLimiter{
@@ -338,6 +377,25 @@ func (cu *CatboxUploader) onCircuitBreakerStateChange(from, to CircuitBreakerSta
 	case Closed:
 		// Circuit fechado, pode aumentar gradualmente a taxa
 	}
+	
+	// Chamado com o mutex do circuit breaker travado
+	if cu.breakerListener != nil {
+		cu.breakerListener(cu.circuitBreaker.statusLocked())
+	}
+}
+
+// BreakerStatus retorna o estado do circuit breaker do Catbox
+func (cu *CatboxUploader) BreakerStatus() upload.BreakerStatus {
+	status := cu.circuitBreaker.Status()
+	status.Host = "catbox"
+	return status
+}
+
+// OnBreakerChange registra quem é avisado quando o circuit breaker muda de estado
+func (cu *CatboxUploader) OnBreakerChange(listener func(upload.BreakerStatus)) {
+	cu.mutex.Lock()
+	defer cu.mutex.Unlock()
+	cu.breakerListener = listener
 }
 
 // metricsCollector coleta métricas em background
@@ -381,6 +439,17 @@ func (cu *CatboxUploader) logMetrics() {
 
 // Upload realiza upload de um arquivo para Catbox com proteções avançadas
 func (cu *CatboxUploader) Upload(filePath string) (string, error) {
+	return cu.upload(context.Background(), filePath)
+}
+
+// upload envia o arquivo até parent ou o uploader serem cancelados; o
+// cancelamento interrompe também a requisição em andamento
+func (cu *CatboxUploader) upload(parent context.Context, filePath string) (string, error) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	stop := context.AfterFunc(cu.ctx, cancel)
+	defer stop()
+	
 	startTime := time.Now()
 	atomic.AddInt64(&cu.totalRequests, 1)
 	
@@ -392,19 +461,30 @@ func (cu *CatboxUploader) Upload(filePath string) (string, error) {
 	// Aguarda rate limiting
 	cu.rateLimiter.Wait()
 	
-	var lastErr error
-	
 	var uploadedURL string
+	retrier := &retry.Retrier{
+		Policy: retry.Policy{
+			MaxRetries: cu.maxRetries,
+			BaseDelay:  cu.baseDelay,
+			MaxDelay:   cu.maxDelay,
+			Jitter:     retry.DefaultJitter,
+		},
+		Name: "catbox",
+		// Se circuit breaker está aberto, não tenta novamente
+		Classify: func(error) (bool, time.Duration) {
+			return cu.circuitBreaker.GetState() != Open, 0
+		},
+	}
 	
-	for attempt := 0; attempt <= cu.maxRetries; attempt++ {
+	attempts, err := retrier.Do(ctx, func(int) error {
 		// Usa circuit breaker para proteção
 		err := cu.circuitBreaker.Execute(func() error {
 			// Context com timeout para a requisição
-			ctx, cancel := context.WithTimeout(cu.ctx, cu.timeout)
+			requestCtx, cancel := context.WithTimeout(ctx, cu.timeout)
 			defer cancel()
 			
 			// Upload com contexto
-			url, err := cu.uploadWithContext(ctx, filePath)
+			url, err := cu.uploadWithContext(requestCtx, filePath)
 			if err != nil {
 				return err
 			}
@@ -413,50 +493,45 @@ func (cu *CatboxUploader) Upload(filePath string) (string, error) {
 			uploadedURL = url
 			atomic.AddInt64(&cu.successRequests, 1)
 			cu.rateLimiter.RecordSuccess()
-			lastErr = nil // Reset do erro
 			
 			return nil
 		})
-		
-		if err == nil {
-			// Upload bem-sucedido
-			return uploadedURL, nil
-		}
-		
-		lastErr = err
-		atomic.AddInt64(&cu.failedRequests, 1)
-		cu.rateLimiter.RecordError()
-		
-		// Se circuit breaker está aberto, não tenta novamente
-		if cu.circuitBreaker.GetState() == Open {
-			break
+		if err != nil {
+			atomic.AddInt64(&cu.failedRequests, 1)
+			cu.rateLimiter.RecordError()
 		}
-		
-		// Se não é o último attempt, aguarda com backoff exponencial
-		if attempt < cu.maxRetries {
-			delay := cu.calculateBackoffDelay(attempt)
-			select {
-			case <-time.After(delay):
-				// Continue
-			case <-cu.ctx.Done():
-				return "", cu.ctx.Err()
-			}
-		}
-	}
+		return err
+	})
 	
-	return "", fmt.Errorf("catbox upload failed after %d attempts: %v", cu.maxRetries+1, lastErr)
+	if err == nil {
+		// Upload bem-sucedido
+		return uploadedURL, nil
+	}
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return "", err
+	}
+	return "", fmt.Errorf("catbox upload failed after %d attempts: %v", attempts, err)
+}
+
+// UploadWithReceipt envia o arquivo e retorna a URL com o nome do arquivo no
+// Catbox, que é o identificador usado pela API para apagá-lo (com o userhash
+// da conta; envios anônimos não podem ser apagados)
+func (cu *CatboxUploader) UploadWithReceipt(filePath string, dest upload.UploadDestination) (string, upload.Receipt, error) {
+	url, err := cu.upload(dest.Context(), filePath)
+	if err != nil {
+		return "", upload.Receipt{}, err
+	}
+	return url, upload.Receipt{ID: path.Base(url)}, nil
 }
 
 // uploadWithContext faz upload com suporte a contexto
 func (cu *CatboxUploader) uploadWithContext(ctx context.Context, filePath string) (string, error) {
-	// Usa o cliente do pool
-	client := cu.connPool.GetClient()
-	
-	// Cria um novo cliente catbox com o contexto
-	catboxClient := catbox.New(client)
+	// Usa o cliente do pool; a biblioteca não aceita contexto, então ele é
+	// aplicado pelo transporte de uma cópia do cliente
+	client := *cu.connPool.GetClient()
+	client.Transport = contextTransport{ctx: ctx, base: client.Transport}
 	
-	// TODO: Implementar upload com contexto quando a biblioteca suportar
-	// Por enquanto, usa o upload normal
+	catboxClient := catbox.New(&client)
 	url, err := catboxClient.Upload(filePath)
 	if err != nil {
 		return "", err
@@ -470,6 +545,20 @@ func (cu *CatboxUploader) uploadWithContext(ctx context.Context, filePath string
 	return url, nil
 }
 
+// contextTransport aplica um contexto a todas as requisições do cliente
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req.WithContext(t.ctx))
+}
+
 // updateResponseTime atualiza o tempo médio de resposta
 func (cu *CatboxUploader) updateResponseTime(duration time.Duration) {
 	cu.mutex.Lock()
@@ -495,6 +584,18 @@ func (cu *CatboxUploader) GetRateLimit() (int, time.Duration) {
 	return int(currentRate), time.Minute
 }
 
+// GetMaxFileSize retorna o tamanho máximo de arquivo aceito pelo Catbox
+func (cu *CatboxUploader) GetMaxFileSize() int64 {
+	return CatboxMaxFileSize
+}
+
+// GetAverageResponseTime retorna o tempo médio de resposta dos uploads recentes
+func (cu *CatboxUploader) GetAverageResponseTime() time.Duration {
+	cu.mutex.RLock()
+	defer cu.mutex.RUnlock()
+	return cu.avgResponseTime
+}
+
 // GetMetrics retorna métricas detalhadas do uploader
 func (cu *CatboxUploader) GetMetrics() map[string]interface{} {
 	cu.mutex.RLock()
@@ -550,23 +651,6 @@ func (cu *CatboxUploader) Close() error {
 	return nil
 }
 
-// calculateBackoffDelay calcula o delay para retry com backoff exponencial
-func (cu *CatboxUploader) calculateBackoffDelay(attempt int) time.Duration {
-	// Backoff exponencial: baseDelay * 2^attempt com jitter
-	delay := time.Duration(float64(cu.baseDelay) * math.Pow(2, float64(attempt)))
-	
-	if delay > cu.maxDelay {
-		delay = cu.maxDelay
-	}
-	
-	// Adicionar jitter aleatório de ±25%
-	jitter := float64(delay) * 0.25
-	nanoFloat := float64(time.Now().UnixNano())
-	jitterAmount := time.Duration(int64(nanoFloat)%int64(jitter) - int64(jitter/2))
-	
-	return delay + jitterAmount
-}
-
 // UploadToCatbox mantém compatibilidade com código existente
 func UploadToCatbox(filePath string) (string, error) {
 	uploader := NewCatboxUploader()
@@ -0,0 +1,297 @@
+package uploaders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-upload/backend/internal/upload"
+)
+
+// r2DefaultCacheControl é usado quando nada é configurado: as chaves são
+// aleatórias, então cada objeto nunca muda e pode ficar em cache por um ano
+const r2DefaultCacheControl = "public, max-age=31536000, immutable"
+
+// cfImagesMaxFileSize é o limite de tamanho do Cloudflare Images
+const cfImagesMaxFileSize = 10 * 1024 * 1024
+
+// R2Config configura um bucket Cloudflare R2 servido por um domínio próprio
+type R2Config struct {
+	AccountID    string `json:"accountId"`
+	Bucket       string `json:"bucket"`
+	AccessKey    string `json:"accessKey,omitempty"`    // Vazio = R2_ACCESS_KEY_ID
+	SecretKey    string `json:"secretKey,omitempty"`    // Vazio = R2_SECRET_ACCESS_KEY
+	CustomDomain string `json:"customDomain"`           // Domínio conectado ao bucket (ex.: https://img.example.com)
+	Prefix       string `json:"prefix,omitempty"`       // Prefixo das chaves dos objetos
+	CacheControl string `json:"cacheControl,omitempty"` // Cache-Control de cada objeto (padrão: um ano, immutable)
+	PartSize     int64  `json:"partSize,omitempty"`     // Bytes por parte do multipart (padrão 8 MB)
+	RateLimit    int    `json:"rateLimit,omitempty"`    // Arquivos por minuto (padrão 120)
+
+	// Bucket privado: URLs pré-assinadas no endpoint do R2 em vez do domínio próprio
+	SignedURLs      bool   `json:"signedUrls,omitempty"`
+	SignedURLExpiry string `json:"signedUrlExpiry,omitempty"` // Padrão e máximo: 168h
+}
+
+// NewR2Uploader cria um uploader S3 apontado para o endpoint do R2 da conta,
+// registrado como "r2" e com URLs sob o domínio próprio
+func NewR2Uploader(config R2Config) (*S3Uploader, error) {
+	if config.AccountID == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("r2 accountId and bucket are required")
+	}
+	domain, err := customDomainURL(config.CustomDomain)
+	if err != nil && !config.SignedURLs {
+		return nil, fmt.Errorf("r2 %v", err)
+	}
+	if config.AccessKey == "" {
+		config.AccessKey = os.Getenv("R2_ACCESS_KEY_ID")
+	}
+	if config.SecretKey == "" {
+		config.SecretKey = os.Getenv("R2_SECRET_ACCESS_KEY")
+	}
+	if config.CacheControl == "" {
+		config.CacheControl = r2DefaultCacheControl
+	}
+
+	uploader, err := NewS3Uploader(S3Config{
+		Endpoint:     fmt.Sprintf("https://%s.r2.cloudflarestorage.com", config.AccountID),
+		Region:       "auto",
+		Bucket:       config.Bucket,
+		AccessKey:    config.AccessKey,
+		SecretKey:    config.SecretKey,
+		Prefix:       config.Prefix,
+		PublicURL:    domain,
+		PartSize:     config.PartSize,
+		RateLimit:    config.RateLimit,
+		CacheControl: config.CacheControl,
+
+		SignedURLs:      config.SignedURLs,
+		SignedURLExpiry: config.SignedURLExpiry,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("r2: %v", err)
+	}
+	uploader.name = "r2"
+	return uploader, nil
+}
+
+// CloudflareImagesConfig configura o Cloudflare Images. O cache das imagens
+// é definido na conta (Browser TTL), não por objeto.
+type CloudflareImagesConfig struct {
+	AccountID    string `json:"accountId"`
+	APIToken     string `json:"apiToken,omitempty"`     // Vazio = CF_IMAGES_TOKEN
+	CustomDomain string `json:"customDomain,omitempty"` // Domínio da zona Cloudflare; vazio = imagedelivery.net
+	Variant      string `json:"variant,omitempty"`      // Variante usada nas URLs (padrão "public")
+	RateLimit    int    `json:"rateLimit,omitempty"`    // Arquivos por minuto (padrão 60)
+}
+
+// CloudflareImagesUploader envia páginas ao Cloudflare Images
+type CloudflareImagesUploader struct {
+	config   CloudflareImagesConfig
+	endpoint string
+	client   *http.Client
+}
+
+// NewCloudflareImagesUploader cria o uploader do Cloudflare Images
+func NewCloudflareImagesUploader(config CloudflareImagesConfig) (*CloudflareImagesUploader, error) {
+	if config.AccountID == "" {
+		return nil, fmt.Errorf("cloudflare images accountId is required")
+	}
+	if config.APIToken == "" {
+		config.APIToken = os.Getenv("CF_IMAGES_TOKEN")
+	}
+	if config.APIToken == "" {
+		return nil, fmt.Errorf("cloudflare images apiToken is required")
+	}
+	if config.CustomDomain != "" {
+		domain, err := customDomainURL(config.CustomDomain)
+		if err != nil {
+			return nil, fmt.Errorf("cloudflare images %v", err)
+		}
+		config.CustomDomain = domain
+	}
+	if config.Variant == "" {
+		config.Variant = "public"
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = 60
+	}
+
+	return &CloudflareImagesUploader{
+		config:   config,
+		endpoint: fmt.Sprintf("https://api.cloudflare.com/client/v4/accounts/%s/images/v1", url.PathEscape(config.AccountID)),
+		client:   &http.Client{Timeout: 2 * time.Minute},
+	}, nil
+}
+
+// Upload envia uma imagem sem destino conhecido
+func (cu *CloudflareImagesUploader) Upload(filePath string) (string, error) {
+	return cu.UploadTo(filePath, upload.UploadDestination{})
+}
+
+// UploadTo envia a imagem e retorna a URL da variante configurada. O envio é interrompido quando o
+// contexto do destino é cancelado.
+func (cu *CloudflareImagesUploader) UploadTo(filePath string, dest upload.UploadDestination) (string, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > cfImagesMaxFileSize {
+		return "", fmt.Errorf("file too large for cloudflare images: %d bytes", info.Size())
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filepath.Base(filePath))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	writer.Close()
+
+	req, err := http.NewRequestWithContext(dest.Context(), http.MethodPost, cu.endpoint, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+cu.config.APIToken)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := cu.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success bool `json:"success"`
+		Errors  []struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+		Result struct {
+			ID       string   `json:"id"`
+			Variants []string `json:"variants"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return "", fmt.Errorf("cloudflare images: HTTP %d: invalid response: %v", resp.StatusCode, err)
+	}
+	if !result.Success || result.Result.ID == "" {
+		if len(result.Errors) > 0 {
+			return "", fmt.Errorf("cloudflare images: HTTP %d: %s (%d)", resp.StatusCode, result.Errors[0].Message, result.Errors[0].Code)
+		}
+		return "", fmt.Errorf("cloudflare images: HTTP %d", resp.StatusCode)
+	}
+
+	accountHash := imageDeliveryHash(result.Result.Variants)
+	if accountHash == "" {
+		return "", fmt.Errorf("cloudflare images: response has no delivery URL")
+	}
+	return cu.deliveryURL(accountHash, result.Result.ID), nil
+}
+
+// GetName retorna o nome do host
+func (cu *CloudflareImagesUploader) GetName() string {
+	return "cfimages"
+}
+
+// GetRateLimit retorna a taxa configurada (arquivos por minuto)
+func (cu *CloudflareImagesUploader) GetRateLimit() (int, time.Duration) {
+	return cu.config.RateLimit, time.Minute
+}
+
+// GetMaxFileSize retorna o limite de tamanho do Cloudflare Images
+func (cu *CloudflareImagesUploader) GetMaxFileSize() int64 {
+	return cfImagesMaxFileSize
+}
+
+// CheckCredentials consulta o uso de armazenamento da conta, validando o
+// accountId e o apiToken sem enviar imagens
+func (cu *CloudflareImagesUploader) CheckCredentials(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cu.endpoint+"/stats", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+cu.config.APIToken)
+
+	resp, err := cu.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cloudflare images: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success bool `json:"success"`
+		Errors  []struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return fmt.Errorf("cloudflare images: HTTP %d: invalid response: %v", resp.StatusCode, err)
+	}
+	if !result.Success {
+		if len(result.Errors) > 0 {
+			return fmt.Errorf("cloudflare images: HTTP %d: %s (%d)", resp.StatusCode, result.Errors[0].Message, result.Errors[0].Code)
+		}
+		return fmt.Errorf("cloudflare images: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// deliveryURL monta a URL da imagem; com domínio próprio usa o caminho
+// /cdn-cgi/imagedelivery servido pela zona
+func (cu *CloudflareImagesUploader) deliveryURL(accountHash, imageID string) string {
+	base := "https://imagedelivery.net"
+	if cu.config.CustomDomain != "" {
+		base = cu.config.CustomDomain + "/cdn-cgi/imagedelivery"
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", base, accountHash, url.PathEscape(imageID), url.PathEscape(cu.config.Variant))
+}
+
+// imageDeliveryHash extrai o hash da conta das URLs de variante
+// (https://imagedelivery.net/<hash>/<id>/<variante>)
+func imageDeliveryHash(variants []string) string {
+	for _, variant := range variants {
+		parsed, err := url.Parse(variant)
+		if err != nil {
+			continue
+		}
+		segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+		if len(segments) >= 3 && segments[0] != "" {
+			return segments[0]
+		}
+	}
+	return ""
+}
+
+// customDomainURL normaliza o domínio próprio para "https://host[/caminho]"
+func customDomainURL(domain string) (string, error) {
+	domain = strings.TrimRight(strings.TrimSpace(domain), "/")
+	if domain == "" {
+		return "", fmt.Errorf("customDomain is required")
+	}
+	if !strings.Contains(domain, "://") {
+		domain = "https://" + domain
+	}
+	parsed, err := url.Parse(domain)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("invalid customDomain %q", domain)
+	}
+	return domain, nil
+}
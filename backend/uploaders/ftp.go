@@ -0,0 +1,250 @@
+package uploaders
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const ftpTimeout = 30 * time.Second
+
+// ftpConn é uma sessão FTP (com TLS explícito opcional) usada pelo RemoteUploader
+type ftpConn struct {
+	raw       net.Conn
+	text      *textproto.Conn
+	host      string
+	tlsConfig *tls.Config // nil = FTP sem criptografia
+}
+
+// dialFTP conecta, negocia TLS se pedido, autentica e entra em modo binário
+func dialFTP(address, username, password string, secure bool) (*ftpConn, error) {
+	raw, err := net.DialTimeout("tcp", address, ftpTimeout)
+	if err != nil {
+		return nil, err
+	}
+	host, _, _ := net.SplitHostPort(address)
+	fc := &ftpConn{raw: raw, text: textproto.NewConn(raw), host: host}
+
+	if _, _, err := fc.text.ReadResponse(220); err != nil {
+		fc.Close()
+		return nil, fmt.Errorf("ftp greeting: %v", err)
+	}
+
+	if secure {
+		if _, err := fc.cmd(234, "AUTH TLS"); err != nil {
+			fc.Close()
+			return nil, err
+		}
+		// O cache de sessão permite que os canais de dados retomem a sessão
+		// TLS do controle, exigência comum de servidores como o vsftpd
+		fc.tlsConfig = &tls.Config{ServerName: host, ClientSessionCache: tls.NewLRUClientSessionCache(4)}
+		tlsConn := tls.Client(raw, fc.tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			raw.Close()
+			return nil, fmt.Errorf("ftps handshake: %v", err)
+		}
+		fc.raw = tlsConn
+		fc.text = textproto.NewConn(tlsConn)
+	}
+
+	if code, err := fc.cmd(0, "USER %s", username); err != nil {
+		fc.Close()
+		return nil, err
+	} else if code == 331 {
+		if _, err := fc.cmd(230, "PASS %s", password); err != nil {
+			fc.Close()
+			return nil, err
+		}
+	} else if code != 230 {
+		fc.Close()
+		return nil, fmt.Errorf("ftp login rejected (%d)", code)
+	}
+
+	if secure {
+		if _, err := fc.cmd(200, "PBSZ 0"); err != nil {
+			fc.Close()
+			return nil, err
+		}
+		if _, err := fc.cmd(200, "PROT P"); err != nil {
+			fc.Close()
+			return nil, err
+		}
+	}
+	if _, err := fc.cmd(200, "TYPE I"); err != nil {
+		fc.Close()
+		return nil, err
+	}
+	return fc, nil
+}
+
+// MakeDir cria o diretório; 550 indica que ele já existe
+func (fc *ftpConn) MakeDir(dir string) error {
+	code, err := fc.cmd(0, "MKD %s", dir)
+	if err != nil {
+		return err
+	}
+	if code != 257 && code != 550 && code != 521 {
+		return fmt.Errorf("ftp MKD failed (%d)", code)
+	}
+	return nil
+}
+
+// Put envia o arquivo local com STOR por um canal de dados passivo
+func (fc *ftpConn) Put(localPath, remotePath string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	data, err := fc.openDataConn()
+	if err != nil {
+		return err
+	}
+
+	id, err := fc.text.Cmd("STOR %s", remotePath)
+	if err != nil {
+		data.Close()
+		return err
+	}
+	fc.text.StartResponse(id)
+	code, message, err := fc.text.ReadResponse(0)
+	fc.text.EndResponse(id)
+	if err != nil || (code != 125 && code != 150) {
+		data.Close()
+		return fmt.Errorf("ftp STOR rejected (%d %s)", code, message)
+	}
+
+	data.SetDeadline(time.Now().Add(10 * time.Minute))
+	if _, err := io.Copy(data, file); err != nil {
+		data.Close()
+		return fmt.Errorf("ftp transfer: %v", err)
+	}
+	if err := data.Close(); err != nil {
+		return fmt.Errorf("ftp transfer: %v", err)
+	}
+
+	fc.raw.SetDeadline(time.Now().Add(ftpTimeout))
+	if _, _, err := fc.text.ReadResponse(226); err != nil {
+		return fmt.Errorf("ftp transfer not confirmed: %v", err)
+	}
+	return nil
+}
+
+// Close encerra a sessão
+func (fc *ftpConn) Close() error {
+	fc.raw.SetDeadline(time.Now().Add(5 * time.Second))
+	fc.text.Cmd("QUIT")
+	return fc.text.Close()
+}
+
+// openDataConn abre o canal de dados com EPSV, recorrendo a PASV. O endereço
+// anunciado pelo PASV é ignorado (usa-se o host do controle), pois servidores
+// atrás de NAT costumam anunciar o IP interno
+func (fc *ftpConn) openDataConn() (net.Conn, error) {
+	port, err := fc.epsv()
+	if err != nil {
+		if port, err = fc.pasv(); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(fc.host, strconv.Itoa(port)), ftpTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("ftp data connection: %v", err)
+	}
+	if fc.tlsConfig != nil {
+		tlsConn := tls.Client(conn, fc.tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ftps data handshake: %v", err)
+		}
+		return tlsConn, nil
+	}
+	return conn, nil
+}
+
+// epsv interpreta "229 Entering Extended Passive Mode (|||porta|)"
+func (fc *ftpConn) epsv() (int, error) {
+	message, err := fc.cmdMessage(229, "EPSV")
+	if err != nil {
+		return 0, err
+	}
+	start := strings.Index(message, "(")
+	end := strings.LastIndex(message, ")")
+	if start < 0 || end < start {
+		return 0, fmt.Errorf("invalid EPSV response: %s", message)
+	}
+	fields := strings.Split(message[start+1:end], message[start+1:start+2])
+	if len(fields) != 5 {
+		return 0, fmt.Errorf("invalid EPSV response: %s", message)
+	}
+	return strconv.Atoi(fields[3])
+}
+
+// pasv interpreta "227 Entering Passive Mode (h1,h2,h3,h4,p1,p2)"
+func (fc *ftpConn) pasv() (int, error) {
+	message, err := fc.cmdMessage(227, "PASV")
+	if err != nil {
+		return 0, err
+	}
+	start := strings.Index(message, "(")
+	end := strings.LastIndex(message, ")")
+	if start < 0 || end < start {
+		return 0, fmt.Errorf("invalid PASV response: %s", message)
+	}
+	fields := strings.Split(message[start+1:end], ",")
+	if len(fields) != 6 {
+		return 0, fmt.Errorf("invalid PASV response: %s", message)
+	}
+	high, err1 := strconv.Atoi(strings.TrimSpace(fields[4]))
+	low, err2 := strconv.Atoi(strings.TrimSpace(fields[5]))
+	if err1 != nil || err2 != nil {
+		return 0, fmt.Errorf("invalid PASV response: %s", message)
+	}
+	return high<<8 | low, nil
+}
+
+// cmd envia um comando e retorna o código; expectCode 0 aceita qualquer código
+func (fc *ftpConn) cmd(expectCode int, format string, args ...interface{}) (int, error) {
+	fc.raw.SetDeadline(time.Now().Add(ftpTimeout))
+	id, err := fc.text.Cmd(format, args...)
+	if err != nil {
+		return 0, err
+	}
+	fc.text.StartResponse(id)
+	defer fc.text.EndResponse(id)
+
+	code, message, err := fc.text.ReadResponse(expectCode)
+	if err != nil {
+		if _, ok := err.(*textproto.Error); ok {
+			verb := strings.Fields(format)[0]
+			return code, fmt.Errorf("ftp %s failed (%d %s)", verb, code, message)
+		}
+		return code, err
+	}
+	return code, nil
+}
+
+// cmdMessage é como cmd, mas retorna o texto da resposta
+func (fc *ftpConn) cmdMessage(expectCode int, format string, args ...interface{}) (string, error) {
+	fc.raw.SetDeadline(time.Now().Add(ftpTimeout))
+	id, err := fc.text.Cmd(format, args...)
+	if err != nil {
+		return "", err
+	}
+	fc.text.StartResponse(id)
+	defer fc.text.EndResponse(id)
+
+	code, message, err := fc.text.ReadResponse(expectCode)
+	if err != nil {
+		return "", fmt.Errorf("ftp %s failed (%d %s)", format, code, message)
+	}
+	return message, nil
+}
@@ -0,0 +1,367 @@
+package uploaders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/upload"
+)
+
+// gofileAPI é a base da API do Gofile
+const gofileAPI = "https://api.gofile.io"
+
+// gofileServerURL é a URL de um servidor de upload (%s = nome do servidor)
+const gofileServerURL = "https://%s.gofile.io"
+
+// gofileServerTTL é por quanto tempo o servidor escolhido é reaproveitado
+const gofileServerTTL = 10 * time.Minute
+
+// GofileConfig configura o envio ao Gofile. Sem token os envios são anônimos:
+// o Gofile cria uma conta convidada no primeiro envio.
+type GofileConfig struct {
+	Token        string `json:"token,omitempty"`        // Vazio = GOFILE_TOKEN (anônimo se ambos vazios)
+	RootFolderID string `json:"rootFolderId,omitempty"` // Pasta da conta onde criar as pastas dos capítulos (exige token)
+	Zone         string `json:"zone,omitempty"`         // Região preferida dos servidores: eu ou na
+	DirectLinks  bool   `json:"directLinks,omitempty"`  // Criar links diretos pela API (contas premium)
+	RateLimit    int    `json:"rateLimit,omitempty"`    // Envios por minuto (padrão 30)
+}
+
+// gofileFolder é a pasta de um capítulo. O lock fica com o primeiro envio até
+// a pasta existir, para que os envios paralelos do capítulo entrem nela.
+type gofileFolder struct {
+	mutex sync.Mutex
+	id    string
+}
+
+// GofileUploader envia arquivos ao Gofile, uma pasta por capítulo
+type GofileUploader struct {
+	config    GofileConfig
+	endpoint  string
+	serverURL string
+	client    *http.Client
+
+	mutex      sync.Mutex
+	server     string // servidor de upload escolhido (ex.: store1)
+	serverAt   time.Time
+	guestToken string                   // conta convidada criada pelo primeiro envio anônimo
+	folders    map[string]*gofileFolder // obra/capítulo -> pasta
+	folderIDs  map[string]string        // obra/capítulo -> ID das pastas já criadas
+
+	// Envios anônimos esperam o primeiro criar a conta convidada; sem isso cada
+	// envio paralelo criaria uma conta e as pastas ficariam espalhadas
+	guestMutex sync.Mutex
+}
+
+// NewGofileUploader cria o uploader do Gofile; o token vem da configuração ou do ambiente
+func NewGofileUploader(config GofileConfig) (*GofileUploader, error) {
+	if config.Token == "" {
+		config.Token = os.Getenv("GOFILE_TOKEN")
+	}
+	switch config.Zone {
+	case "", "eu", "na":
+	default:
+		return nil, fmt.Errorf("invalid gofile zone %q (use eu or na)", config.Zone)
+	}
+	if config.Token == "" && (config.RootFolderID != "" || config.DirectLinks) {
+		return nil, fmt.Errorf("gofile rootFolderId and directLinks require a token")
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = 30
+	}
+
+	return &GofileUploader{
+		config:    config,
+		endpoint:  gofileAPI,
+		serverURL: gofileServerURL,
+		client:    &http.Client{Timeout: 10 * time.Minute},
+		folders:   make(map[string]*gofileFolder),
+		folderIDs: make(map[string]string),
+	}, nil
+}
+
+// Upload envia um arquivo em uma pasta própria
+func (gu *GofileUploader) Upload(filePath string) (string, error) {
+	return gu.UploadTo(filePath, upload.UploadDestination{})
+}
+
+// UploadTo envia o arquivo para a pasta do capítulo (criada no primeiro envio)
+// e retorna o link direto
+func (gu *GofileUploader) UploadTo(filePath string, dest upload.UploadDestination) (string, error) {
+	ctx := dest.Context()
+	name := dest.FileName
+	if name == "" {
+		name = filepath.Base(filePath)
+	}
+	if dest.Manga == "" || dest.Chapter == "" {
+		return gu.uploadFile(ctx, filePath, name, "")
+	}
+
+	key := dest.Manga + "/" + dest.Chapter
+	gu.mutex.Lock()
+	folder, exists := gu.folders[key]
+	if !exists {
+		folder = &gofileFolder{}
+		gu.folders[key] = folder
+	}
+	gu.mutex.Unlock()
+
+	folder.mutex.Lock()
+	if folder.id == "" {
+		defer folder.mutex.Unlock()
+		if gu.config.RootFolderID != "" {
+			folderID, err := gu.createFolder(ctx, fmt.Sprintf("%s - %s", dest.Manga, dest.Chapter))
+			if err != nil {
+				return "", fmt.Errorf("gofile folder for %s: %v", key, err)
+			}
+			gu.setFolder(key, folder, folderID)
+			return gu.uploadFile(ctx, filePath, name, folderID)
+		}
+		// Sem pasta raiz o primeiro envio cria a pasta do capítulo
+		link, folderID, err := gu.send(ctx, filePath, name, "")
+		if err != nil {
+			return "", err
+		}
+		gu.setFolder(key, folder, folderID)
+		return link, nil
+	}
+	folderID := folder.id
+	folder.mutex.Unlock()
+	return gu.uploadFile(ctx, filePath, name, folderID)
+}
+
+// GetName retorna o nome do host
+func (gu *GofileUploader) GetName() string {
+	return "gofile"
+}
+
+// GetRateLimit retorna a taxa configurada (envios por minuto)
+func (gu *GofileUploader) GetRateLimit() (int, time.Duration) {
+	return gu.config.RateLimit, time.Minute
+}
+
+// FolderID retorna a pasta do Gofile de um capítulo já enviado ("" se nenhuma)
+func (gu *GofileUploader) FolderID(manga, chapter string) string {
+	gu.mutex.Lock()
+	defer gu.mutex.Unlock()
+	return gu.folderIDs[manga+"/"+chapter]
+}
+
+// GetMetrics retorna o servidor em uso e as pastas dos capítulos (obra/capítulo -> ID)
+func (gu *GofileUploader) GetMetrics() map[string]interface{} {
+	gu.mutex.Lock()
+	defer gu.mutex.Unlock()
+	folders := make(map[string]string, len(gu.folderIDs))
+	for key, id := range gu.folderIDs {
+		folders[key] = id
+	}
+	return map[string]interface{}{
+		"server":    gu.server,
+		"anonymous": gu.config.Token == "",
+		"folders":   folders,
+	}
+}
+
+// setFolder guarda a pasta criada para o capítulo (chamado com folder.mutex)
+func (gu *GofileUploader) setFolder(key string, folder *gofileFolder, folderID string) {
+	folder.id = folderID
+	gu.mutex.Lock()
+	gu.folderIDs[key] = folderID
+	gu.mutex.Unlock()
+}
+
+// uploadFile envia o arquivo para a pasta folderID ("" = nova pasta) e retorna o link
+func (gu *GofileUploader) uploadFile(ctx context.Context, filePath, name, folderID string) (string, error) {
+	link, _, err := gu.send(ctx, filePath, name, folderID)
+	return link, err
+}
+
+// send envia o arquivo ao servidor escolhido e retorna o link direto e a pasta
+// em que ele ficou
+func (gu *GofileUploader) send(ctx context.Context, filePath, name, folderID string) (string, string, error) {
+	server, err := gu.uploadServer(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	if gu.config.Token == "" {
+		gu.guestMutex.Lock()
+		gu.mutex.Lock()
+		hasGuest := gu.guestToken != ""
+		gu.mutex.Unlock()
+		if hasGuest {
+			gu.guestMutex.Unlock()
+		} else {
+			defer gu.guestMutex.Unlock()
+		}
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if folderID != "" {
+		writer.WriteField("folderId", folderID)
+	}
+	part, err := writer.CreateFormFile("file", name)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return "", "", fmt.Errorf("failed to read file: %v", err)
+	}
+	writer.Close()
+
+	var uploaded struct {
+		ID           string `json:"id"`
+		Name         string `json:"name"`
+		ParentFolder string `json:"parentFolder"`
+		GuestToken   string `json:"guestToken"`
+	}
+	serverURL := fmt.Sprintf(gu.serverURL, server)
+	if err := gu.call(ctx, http.MethodPost, serverURL+"/contents/uploadfile", &body, writer.FormDataContentType(), &uploaded); err != nil {
+		return "", "", err
+	}
+	if uploaded.ID == "" {
+		return "", "", fmt.Errorf("gofile: response has no file id")
+	}
+	if uploaded.GuestToken != "" {
+		gu.mutex.Lock()
+		if gu.guestToken == "" {
+			gu.guestToken = uploaded.GuestToken
+		}
+		gu.mutex.Unlock()
+	}
+	if uploaded.Name == "" {
+		uploaded.Name = name
+	}
+
+	link := serverURL + "/download/web/" + uploaded.ID + "/" + url.PathEscape(uploaded.Name)
+	if gu.config.DirectLinks {
+		if link, err = gu.directLink(ctx, uploaded.ID); err != nil {
+			return "", "", err
+		}
+	}
+	return link, uploaded.ParentFolder, nil
+}
+
+// uploadServer retorna o servidor de upload recomendado, consultado de tempos em tempos
+func (gu *GofileUploader) uploadServer(ctx context.Context) (string, error) {
+	gu.mutex.Lock()
+	if gu.server != "" && time.Since(gu.serverAt) < gofileServerTTL {
+		server := gu.server
+		gu.mutex.Unlock()
+		return server, nil
+	}
+	gu.mutex.Unlock()
+
+	serversURL := gu.endpoint + "/servers"
+	if gu.config.Zone != "" {
+		serversURL += "?zone=" + gu.config.Zone
+	}
+	var result struct {
+		Servers []struct {
+			Name string `json:"name"`
+			Zone string `json:"zone"`
+		} `json:"servers"`
+	}
+	if err := gu.call(ctx, http.MethodGet, serversURL, nil, "", &result); err != nil {
+		return "", err
+	}
+	// O Gofile lista os servidores do mais para o menos indicado
+	if len(result.Servers) == 0 || result.Servers[0].Name == "" {
+		return "", fmt.Errorf("gofile: no upload server available")
+	}
+
+	gu.mutex.Lock()
+	defer gu.mutex.Unlock()
+	gu.server = result.Servers[0].Name
+	gu.serverAt = time.Now()
+	return gu.server, nil
+}
+
+// createFolder cria a pasta de um capítulo dentro da pasta raiz configurada
+func (gu *GofileUploader) createFolder(ctx context.Context, name string) (string, error) {
+	payload, _ := json.Marshal(map[string]string{"parentFolderId": gu.config.RootFolderID, "folderName": name})
+	var folder struct {
+		ID string `json:"id"`
+	}
+	if err := gu.call(ctx, http.MethodPost, gu.endpoint+"/contents/createFolder", bytes.NewReader(payload), "application/json", &folder); err != nil {
+		return "", err
+	}
+	if folder.ID == "" {
+		return "", fmt.Errorf("gofile: response has no folder id")
+	}
+	return folder.ID, nil
+}
+
+// directLink cria o link direto de um arquivo (contas premium)
+func (gu *GofileUploader) directLink(ctx context.Context, fileID string) (string, error) {
+	var created struct {
+		DirectLink string `json:"directLink"`
+	}
+	if err := gu.call(ctx, http.MethodPost, gu.endpoint+"/contents/"+fileID+"/directlinks", bytes.NewReader([]byte("{}")), "application/json", &created); err != nil {
+		return "", err
+	}
+	if created.DirectLink == "" {
+		return "", fmt.Errorf("gofile: response has no direct link")
+	}
+	return created.DirectLink, nil
+}
+
+// call faz uma requisição autenticada (token da conta ou convidado) e decodifica
+// o campo data da resposta em result
+func (gu *GofileUploader) call(ctx context.Context, method, target string, body io.Reader, contentType string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return err
+	}
+	token := gu.config.Token
+	if token == "" {
+		gu.mutex.Lock()
+		token = gu.guestToken
+		gu.mutex.Unlock()
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := gu.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gofile: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Status string          `json:"status"`
+		Data   json.RawMessage `json:"data"`
+	}
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&envelope)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("gofile: rate limited (HTTP 429)")
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("gofile: HTTP %d: invalid response: %v", resp.StatusCode, decodeErr)
+	}
+	if envelope.Status != "ok" || resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gofile: HTTP %d: %s", resp.StatusCode, envelope.Status)
+	}
+	if err := json.Unmarshal(envelope.Data, result); err != nil {
+		return fmt.Errorf("gofile: invalid response: %v", err)
+	}
+	return nil
+}
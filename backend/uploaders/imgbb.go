@@ -0,0 +1,185 @@
+package uploaders
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-upload/backend/internal/upload"
+)
+
+// imgbbAPI é o endpoint de envio do ImgBB
+const imgbbAPI = "https://api.imgbb.com/1/upload"
+
+// imgbbMaxFileSize é o limite de tamanho de imagem do ImgBB
+const imgbbMaxFileSize = 32 * 1024 * 1024
+
+// Limites da expiração aceitos pelo ImgBB (60 segundos a 180 dias)
+const (
+	imgbbMinExpiration = time.Minute
+	imgbbMaxExpiration = 180 * 24 * time.Hour
+)
+
+// ImgBBConfig configura o envio ao ImgBB
+type ImgBBConfig struct {
+	APIKey     string `json:"apiKey,omitempty"`     // Vazio = IMGBB_API_KEY
+	Expiration string `json:"expiration,omitempty"` // Apagar as imagens após este tempo (ex.: "720h"; 1m a 4320h); vazio = nunca
+	RateLimit  int    `json:"rateLimit,omitempty"`  // Arquivos por minuto (padrão 30)
+}
+
+// ImgBBUploader envia páginas ao ImgBB
+type ImgBBUploader struct {
+	config     ImgBBConfig
+	expiration int // segundos; 0 = sem expiração
+	endpoint   string
+	client     *http.Client
+}
+
+// NewImgBBUploader cria o uploader do ImgBB; a chave vem da configuração ou do ambiente
+func NewImgBBUploader(config ImgBBConfig) (*ImgBBUploader, error) {
+	if config.APIKey == "" {
+		config.APIKey = os.Getenv("IMGBB_API_KEY")
+	}
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("imgbb apiKey is required")
+	}
+	expiration := 0
+	if config.Expiration != "" {
+		duration, err := time.ParseDuration(config.Expiration)
+		if err != nil || duration < imgbbMinExpiration || duration > imgbbMaxExpiration {
+			return nil, fmt.Errorf("invalid imgbb expiration %q (between 1m and 4320h)", config.Expiration)
+		}
+		expiration = int(duration / time.Second)
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = 30
+	}
+
+	return &ImgBBUploader{
+		config:     config,
+		expiration: expiration,
+		endpoint:   imgbbAPI,
+		client:     &http.Client{Timeout: 2 * time.Minute},
+	}, nil
+}
+
+// Upload envia uma imagem com o nome do próprio arquivo
+func (iu *ImgBBUploader) Upload(filePath string) (string, error) {
+	return iu.UploadTo(filePath, upload.UploadDestination{})
+}
+
+// UploadTo envia a imagem com o nome de destino e retorna o link direto
+func (iu *ImgBBUploader) UploadTo(filePath string, dest upload.UploadDestination) (string, error) {
+	link, _, err := iu.UploadWithReceipt(filePath, dest)
+	return link, err
+}
+
+// UploadWithReceipt envia a imagem e retorna o link direto com o ID e a página
+// de remoção da imagem
+func (iu *ImgBBUploader) UploadWithReceipt(filePath string, dest upload.UploadDestination) (string, upload.Receipt, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", upload.Receipt{}, err
+	}
+	if info.Size() > imgbbMaxFileSize {
+		return "", upload.Receipt{}, fmt.Errorf("file too large for imgbb: %d bytes", info.Size())
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", upload.Receipt{}, fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	name := dest.FileName
+	if name == "" {
+		name = filepath.Base(filePath)
+	}
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("image", name)
+	if err != nil {
+		return "", upload.Receipt{}, err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return "", upload.Receipt{}, fmt.Errorf("failed to read file: %v", err)
+	}
+	writer.WriteField("name", strings.TrimSuffix(name, filepath.Ext(name)))
+	writer.Close()
+
+	query := url.Values{"key": {iu.config.APIKey}}
+	if iu.expiration > 0 {
+		query.Set("expiration", strconv.Itoa(iu.expiration))
+	}
+	req, err := http.NewRequestWithContext(dest.Context(), http.MethodPost, iu.endpoint+"?"+query.Encode(), &body)
+	if err != nil {
+		return "", upload.Receipt{}, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := iu.client.Do(req)
+	if err != nil {
+		// A chave vai na URL; não repeti-la nos logs
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return "", upload.Receipt{}, fmt.Errorf("imgbb: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success bool `json:"success"`
+		Data    struct {
+			ID         string `json:"id"`
+			URL        string `json:"url"`
+			DisplayURL string `json:"display_url"`
+			DeleteURL  string `json:"delete_url"`
+		} `json:"data"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", upload.Receipt{}, fmt.Errorf("imgbb: rate limited (HTTP 429): %s", result.Error.Message)
+	}
+	if decodeErr != nil {
+		return "", upload.Receipt{}, fmt.Errorf("imgbb: HTTP %d: invalid response: %v", resp.StatusCode, decodeErr)
+	}
+	if !result.Success || resp.StatusCode != http.StatusOK {
+		return "", upload.Receipt{}, fmt.Errorf("imgbb: HTTP %d: %s", resp.StatusCode, result.Error.Message)
+	}
+
+	// url é o link direto da imagem; display_url pode ser uma versão reduzida
+	link := result.Data.URL
+	if link == "" {
+		link = result.Data.DisplayURL
+	}
+	if link == "" {
+		return "", upload.Receipt{}, fmt.Errorf("imgbb: response has no image url")
+	}
+	return link, upload.Receipt{ID: result.Data.ID, DeleteURL: result.Data.DeleteURL}, nil
+}
+
+// GetName retorna o nome do host
+func (iu *ImgBBUploader) GetName() string {
+	return "imgbb"
+}
+
+// GetRateLimit retorna a taxa configurada (arquivos por minuto)
+func (iu *ImgBBUploader) GetRateLimit() (int, time.Duration) {
+	return iu.config.RateLimit, time.Minute
+}
+
+// GetMaxFileSize retorna o limite de tamanho do ImgBB
+func (iu *ImgBBUploader) GetMaxFileSize() int64 {
+	return imgbbMaxFileSize
+}
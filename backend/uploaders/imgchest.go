@@ -0,0 +1,267 @@
+package uploaders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/upload"
+)
+
+// imgchestAPI é a base da API do ImgChest
+const imgchestAPI = "https://api.imgchest.com/v1"
+
+// imgchestMaxFileSize é o limite de tamanho de imagem do ImgChest
+const imgchestMaxFileSize = 30 * 1024 * 1024
+
+// ImgChestConfig configura o envio ao ImgChest
+type ImgChestConfig struct {
+	AccessToken string `json:"accessToken,omitempty"` // Vazio = IMGCHEST_TOKEN
+	Privacy     string `json:"privacy,omitempty"`     // Privacidade dos posts: hidden (padrão), public ou secret
+	NSFW        bool   `json:"nsfw,omitempty"`        // Marcar os posts como NSFW
+	RateLimit   int    `json:"rateLimit,omitempty"`   // Envios por minuto (padrão 60)
+}
+
+// imgchestPost é o post de um capítulo. O lock fica com o primeiro envio até
+// o post existir, para que os envios paralelos do capítulo entrem nele.
+type imgchestPost struct {
+	mutex sync.Mutex
+	id    string
+}
+
+// imgchestImage é uma imagem nas respostas da API
+type imgchestImage struct {
+	ID           string `json:"id"`
+	Link         string `json:"link"`
+	OriginalName string `json:"original_name"`
+	Position     int    `json:"position"`
+}
+
+// ImgChestUploader envia páginas ao ImgChest, agrupando-as em um post por capítulo
+type ImgChestUploader struct {
+	config   ImgChestConfig
+	endpoint string
+	client   *http.Client
+
+	mutex sync.Mutex
+	posts map[string]*imgchestPost // obra/capítulo -> post
+}
+
+// NewImgChestUploader cria o uploader do ImgChest; o token vem da configuração ou do ambiente
+func NewImgChestUploader(config ImgChestConfig) (*ImgChestUploader, error) {
+	if config.AccessToken == "" {
+		config.AccessToken = os.Getenv("IMGCHEST_TOKEN")
+	}
+	if config.AccessToken == "" {
+		return nil, fmt.Errorf("imgchest accessToken is required")
+	}
+	switch config.Privacy {
+	case "":
+		config.Privacy = "hidden"
+	case "hidden", "public", "secret":
+	default:
+		return nil, fmt.Errorf("invalid imgchest privacy %q (use hidden, public or secret)", config.Privacy)
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = 60
+	}
+
+	return &ImgChestUploader{
+		config:   config,
+		endpoint: imgchestAPI,
+		client:   &http.Client{Timeout: 2 * time.Minute},
+		posts:    make(map[string]*imgchestPost),
+	}, nil
+}
+
+// Upload envia uma imagem em um post próprio
+func (iu *ImgChestUploader) Upload(filePath string) (string, error) {
+	return iu.UploadTo(filePath, upload.UploadDestination{})
+}
+
+// UploadTo envia a imagem para o post do capítulo (criado com a primeira
+// página) e retorna o link direto da imagem
+func (iu *ImgChestUploader) UploadTo(filePath string, dest upload.UploadDestination) (string, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > imgchestMaxFileSize {
+		return "", fmt.Errorf("file too large for imgchest: %d bytes", info.Size())
+	}
+	name := dest.FileName
+	if name == "" {
+		name = filepath.Base(filePath)
+	}
+
+	if dest.Manga == "" || dest.Chapter == "" {
+		link, _, err := iu.newPost(dest.Context(), filePath, name, name)
+		return link, err
+	}
+	key := dest.Manga + "/" + dest.Chapter
+	iu.mutex.Lock()
+	post, exists := iu.posts[key]
+	if !exists {
+		post = &imgchestPost{}
+		iu.posts[key] = post
+	}
+	iu.mutex.Unlock()
+
+	post.mutex.Lock()
+	if post.id == "" {
+		defer post.mutex.Unlock()
+		link, postID, err := iu.newPost(dest.Context(), filePath, name, fmt.Sprintf("%s - %s", dest.Manga, dest.Chapter))
+		if err != nil {
+			return "", fmt.Errorf("imgchest post for %s: %v", key, err)
+		}
+		post.id = postID
+		return link, nil
+	}
+	postID := post.id
+	post.mutex.Unlock()
+	return iu.addToPost(dest.Context(), postID, filePath, name)
+}
+
+// GetName retorna o nome do host
+func (iu *ImgChestUploader) GetName() string {
+	return "imgchest"
+}
+
+// GetRateLimit retorna a taxa configurada (envios por minuto)
+func (iu *ImgChestUploader) GetRateLimit() (int, time.Duration) {
+	return iu.config.RateLimit, time.Minute
+}
+
+// GetMaxFileSize retorna o limite de tamanho do ImgChest
+func (iu *ImgChestUploader) GetMaxFileSize() int64 {
+	return imgchestMaxFileSize
+}
+
+// newPost cria um post com a imagem e retorna o link dela e o ID do post
+func (iu *ImgChestUploader) newPost(ctx context.Context, filePath, name, title string) (string, string, error) {
+	fields := map[string]string{"title": title, "privacy": iu.config.Privacy}
+	if iu.config.NSFW {
+		fields["nsfw"] = "true"
+	}
+	var post struct {
+		ID     string          `json:"id"`
+		Images []imgchestImage `json:"images"`
+	}
+	if err := iu.send(ctx, "/post", filePath, name, fields, &post); err != nil {
+		return "", "", err
+	}
+	if post.ID == "" {
+		return "", "", fmt.Errorf("imgchest: response has no post id")
+	}
+	link, err := imageLink(post.Images, name)
+	return link, post.ID, err
+}
+
+// addToPost acrescenta a imagem a um post existente e retorna o link direto
+func (iu *ImgChestUploader) addToPost(ctx context.Context, postID, filePath, name string) (string, error) {
+	var post struct {
+		Images []imgchestImage `json:"images"`
+	}
+	if err := iu.send(ctx, "/post/"+postID+"/add", filePath, name, nil, &post); err != nil {
+		return "", err
+	}
+	return imageLink(post.Images, name)
+}
+
+// send envia a imagem (campo images[]) com os campos extras e decodifica o
+// campo data da resposta em result
+func (iu *ImgChestUploader) send(ctx context.Context, path, filePath, name string, fields map[string]string, result interface{}) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("images[]", name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return fmt.Errorf("failed to read file: %v", err)
+	}
+	for key, value := range fields {
+		writer.WriteField(key, value)
+	}
+	writer.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, iu.endpoint+path, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+iu.config.AccessToken)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := iu.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("imgchest: %v", err)
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	var envelope struct {
+		Data    json.RawMessage `json:"data"`
+		Message string          `json:"message"`
+		Error   string          `json:"error"`
+	}
+	decodeErr := json.Unmarshal(raw, &envelope)
+	message := envelope.Message
+	if message == "" {
+		message = envelope.Error
+	}
+	if message == "" {
+		message = strings.TrimSpace(string(raw))
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("imgchest: rate limited (HTTP 429): %s", message)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("imgchest: HTTP %d: %s", resp.StatusCode, message)
+	}
+	if decodeErr != nil || len(envelope.Data) == 0 {
+		return fmt.Errorf("imgchest: HTTP %d: invalid response: %s", resp.StatusCode, message)
+	}
+	if err := json.Unmarshal(envelope.Data, result); err != nil {
+		return fmt.Errorf("imgchest: invalid response: %v", err)
+	}
+	return nil
+}
+
+// imageLink encontra a imagem enviada na lista do post: pelo nome original e,
+// sem ele, a de maior posição (a última acrescentada)
+func imageLink(images []imgchestImage, name string) (string, error) {
+	var latest *imgchestImage
+	for i := range images {
+		image := &images[i]
+		if image.OriginalName == name && image.Link != "" {
+			latest = image
+		}
+	}
+	if latest == nil {
+		for i := range images {
+			if images[i].Link != "" && (latest == nil || images[i].Position >= latest.Position) {
+				latest = &images[i]
+			}
+		}
+	}
+	if latest == nil {
+		return "", fmt.Errorf("imgchest: response has no image link")
+	}
+	return latest.Link, nil
+}
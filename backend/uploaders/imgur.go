@@ -0,0 +1,356 @@
+package uploaders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/upload"
+)
+
+// imgurAPI é a base da API do Imgur
+const imgurAPI = "https://api.imgur.com/3"
+
+// imgurMaxFileSize é o limite de tamanho de imagem do Imgur (PNGs acima de
+// 5 MB são convertidos para JPEG pelo próprio Imgur)
+const imgurMaxFileSize = 20 * 1024 * 1024
+
+// imgurUploadCost é quantos créditos da API um envio consome
+const imgurUploadCost = 10
+
+// imgurMaxCreditWait é a maior espera por créditos antes de falhar o envio;
+// limites que renovam mais tarde voltam como erro para o lote tentar de novo
+const imgurMaxCreditWait = time.Minute
+
+// ImgurConfig configura o envio ao Imgur. Sem accessToken os envios são
+// anônimos (só clientId); com ele, ficam na conta do usuário.
+type ImgurConfig struct {
+	ClientID      string `json:"clientId,omitempty"`      // Vazio = IMGUR_CLIENT_ID
+	AccessToken   string `json:"accessToken,omitempty"`   // Vazio = IMGUR_ACCESS_TOKEN (OAuth2)
+	DisableAlbums bool   `json:"disableAlbums,omitempty"` // Não criar um álbum por capítulo
+	Privacy       string `json:"privacy,omitempty"`       // Privacidade dos álbuns: hidden (padrão), public ou secret
+	RateLimit     int    `json:"rateLimit,omitempty"`     // Envios por hora (padrão 50 anônimo, 1250 autenticado)
+}
+
+// imgurAlbum é o álbum de um capítulo
+type imgurAlbum struct {
+	ID         string
+	DeleteHash string // usado para adicionar imagens a álbuns anônimos
+}
+
+// imgurCredits são os limites informados nos cabeçalhos das respostas
+// (-1 = ainda não informado)
+type imgurCredits struct {
+	postRemaining   int
+	postReset       time.Time
+	userRemaining   int
+	userReset       time.Time
+	clientRemaining int
+}
+
+// ImgurUploader envia páginas ao Imgur, agrupando-as em um álbum por capítulo
+type ImgurUploader struct {
+	config   ImgurConfig
+	endpoint string
+	client   *http.Client
+
+	albumMutex sync.Mutex
+	albums     map[string]imgurAlbum // obra/capítulo -> álbum
+
+	mutex   sync.Mutex
+	credits imgurCredits
+}
+
+// NewImgurUploader cria o uploader do Imgur; as credenciais vêm da configuração ou do ambiente
+func NewImgurUploader(config ImgurConfig) (*ImgurUploader, error) {
+	if config.ClientID == "" {
+		config.ClientID = os.Getenv("IMGUR_CLIENT_ID")
+	}
+	if config.AccessToken == "" {
+		config.AccessToken = os.Getenv("IMGUR_ACCESS_TOKEN")
+	}
+	if config.ClientID == "" && config.AccessToken == "" {
+		return nil, fmt.Errorf("imgur clientId (anonymous) or accessToken is required")
+	}
+	switch config.Privacy {
+	case "":
+		config.Privacy = "hidden"
+	case "hidden", "public", "secret":
+	default:
+		return nil, fmt.Errorf("invalid imgur privacy %q (use hidden, public or secret)", config.Privacy)
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = 50
+		if config.AccessToken != "" {
+			config.RateLimit = 1250
+		}
+	}
+
+	return &ImgurUploader{
+		config:   config,
+		endpoint: imgurAPI,
+		client:   &http.Client{Timeout: 2 * time.Minute},
+		albums:   make(map[string]imgurAlbum),
+		credits:  imgurCredits{postRemaining: -1, userRemaining: -1, clientRemaining: -1},
+	}, nil
+}
+
+// Upload envia uma imagem fora de qualquer álbum
+func (iu *ImgurUploader) Upload(filePath string) (string, error) {
+	return iu.UploadTo(filePath, upload.UploadDestination{})
+}
+
+// UploadTo envia a imagem para o álbum do capítulo (criado no primeiro envio)
+// e retorna o link direto da imagem
+func (iu *ImgurUploader) UploadTo(filePath string, dest upload.UploadDestination) (string, error) {
+	link, _, err := iu.UploadWithReceipt(filePath, dest)
+	return link, err
+}
+
+// UploadWithReceipt envia a imagem para o álbum do capítulo e retorna o link
+// direto com o ID e o deletehash da imagem (que permite apagá-la mesmo em
+// envios anônimos)
+func (iu *ImgurUploader) UploadWithReceipt(filePath string, dest upload.UploadDestination) (string, upload.Receipt, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", upload.Receipt{}, err
+	}
+	if info.Size() > imgurMaxFileSize {
+		return "", upload.Receipt{}, fmt.Errorf("file too large for imgur: %d bytes", info.Size())
+	}
+
+	album := ""
+	if !iu.config.DisableAlbums && dest.Manga != "" && dest.Chapter != "" {
+		chapterAlbum, err := iu.chapterAlbum(dest.Context(), dest.Manga, dest.Chapter)
+		if err != nil {
+			return "", upload.Receipt{}, err
+		}
+		// Álbuns anônimos só aceitam imagens pelo deletehash
+		album = chapterAlbum.ID
+		if iu.config.AccessToken == "" {
+			album = chapterAlbum.DeleteHash
+		}
+	}
+	if err := iu.waitForCredits(); err != nil {
+		return "", upload.Receipt{}, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", upload.Receipt{}, fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	name := dest.FileName
+	if name == "" {
+		name = filepath.Base(filePath)
+	}
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("image", name)
+	if err != nil {
+		return "", upload.Receipt{}, err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return "", upload.Receipt{}, fmt.Errorf("failed to read file: %v", err)
+	}
+	writer.WriteField("type", "file")
+	writer.WriteField("name", name)
+	if album != "" {
+		writer.WriteField("album", album)
+	}
+	writer.Close()
+
+	var image struct {
+		ID         string `json:"id"`
+		DeleteHash string `json:"deletehash"`
+		Link       string `json:"link"`
+	}
+	if err := iu.call(dest.Context(), http.MethodPost, "/image", &body, writer.FormDataContentType(), &image); err != nil {
+		return "", upload.Receipt{}, err
+	}
+	if image.Link == "" {
+		return "", upload.Receipt{}, fmt.Errorf("imgur: response has no image link")
+	}
+	return image.Link, upload.Receipt{ID: image.ID, DeleteToken: image.DeleteHash}, nil
+}
+
+// GetName retorna o nome do host
+func (iu *ImgurUploader) GetName() string {
+	return "imgur"
+}
+
+// GetRateLimit retorna a taxa configurada (envios por hora)
+func (iu *ImgurUploader) GetRateLimit() (int, time.Duration) {
+	return iu.config.RateLimit, time.Hour
+}
+
+// GetMaxFileSize retorna o limite de tamanho do Imgur
+func (iu *ImgurUploader) GetMaxFileSize() int64 {
+	return imgurMaxFileSize
+}
+
+// CheckCredentials consulta os créditos restantes da API, validando o
+// clientId/accessToken sem enviar imagens
+func (iu *ImgurUploader) CheckCredentials(ctx context.Context) error {
+	return iu.call(ctx, http.MethodGet, "/credits", nil, "", nil)
+}
+
+// chapterAlbum retorna o álbum do capítulo, criando-o no primeiro envio. O lock
+// fica com quem cria, para que envios paralelos do capítulo usem o mesmo álbum.
+func (iu *ImgurUploader) chapterAlbum(ctx context.Context, manga, chapter string) (imgurAlbum, error) {
+	key := manga + "/" + chapter
+	iu.albumMutex.Lock()
+	defer iu.albumMutex.Unlock()
+	if album, exists := iu.albums[key]; exists {
+		return album, nil
+	}
+
+	form := strings.NewReader(url.Values{
+		"title":   {fmt.Sprintf("%s - %s", manga, chapter)},
+		"privacy": {iu.config.Privacy},
+	}.Encode())
+	var created struct {
+		ID         string `json:"id"`
+		DeleteHash string `json:"deletehash"`
+	}
+	if err := iu.call(ctx, http.MethodPost, "/album", form, "application/x-www-form-urlencoded", &created); err != nil {
+		return imgurAlbum{}, fmt.Errorf("imgur album for %s: %v", key, err)
+	}
+	if created.ID == "" {
+		return imgurAlbum{}, fmt.Errorf("imgur album for %s: response has no album id", key)
+	}
+	album := imgurAlbum{ID: created.ID, DeleteHash: created.DeleteHash}
+	iu.albums[key] = album
+	return album, nil
+}
+
+// call faz uma requisição à API, registra os créditos dos cabeçalhos e decodifica
+// o campo data da resposta em result (nil = ignorado)
+func (iu *ImgurUploader) call(ctx context.Context, method, path string, body io.Reader, contentType string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, iu.endpoint+path, body)
+	if err != nil {
+		return err
+	}
+	if iu.config.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+iu.config.AccessToken)
+	} else {
+		req.Header.Set("Authorization", "Client-ID "+iu.config.ClientID)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := iu.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("imgur: %v", err)
+	}
+	defer resp.Body.Close()
+	iu.recordCredits(resp.Header)
+
+	var envelope struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+	}
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&envelope)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("imgur: rate limited (HTTP 429): %s", imgurErrorMessage(envelope.Data))
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("imgur: HTTP %d: invalid response: %v", resp.StatusCode, decodeErr)
+	}
+	if !envelope.Success || resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("imgur: HTTP %d: %s", resp.StatusCode, imgurErrorMessage(envelope.Data))
+	}
+	if result != nil {
+		if err := json.Unmarshal(envelope.Data, result); err != nil {
+			return fmt.Errorf("imgur: invalid response: %v", err)
+		}
+	}
+	return nil
+}
+
+// recordCredits guarda os limites informados pelo Imgur (ausentes = mantidos)
+func (iu *ImgurUploader) recordCredits(header http.Header) {
+	now := time.Now()
+	iu.mutex.Lock()
+	defer iu.mutex.Unlock()
+
+	if remaining, err := strconv.Atoi(header.Get("X-Post-Rate-Limit-Remaining")); err == nil {
+		iu.credits.postRemaining = remaining
+		if seconds, err := strconv.Atoi(header.Get("X-Post-Rate-Limit-Reset")); err == nil {
+			iu.credits.postReset = now.Add(time.Duration(seconds) * time.Second)
+		}
+	}
+	if remaining, err := strconv.Atoi(header.Get("X-RateLimit-UserRemaining")); err == nil {
+		iu.credits.userRemaining = remaining
+		if epoch, err := strconv.ParseInt(header.Get("X-RateLimit-UserReset"), 10, 64); err == nil {
+			iu.credits.userReset = time.Unix(epoch, 0)
+		}
+	}
+	if remaining, err := strconv.Atoi(header.Get("X-RateLimit-ClientRemaining")); err == nil {
+		iu.credits.clientRemaining = remaining
+	}
+}
+
+// waitForCredits aguarda a renovação dos créditos quando eles acabaram e a
+// renovação está próxima; caso contrário falha sem gastar uma requisição
+func (iu *ImgurUploader) waitForCredits() error {
+	iu.mutex.Lock()
+	credits := iu.credits
+	iu.mutex.Unlock()
+
+	// Os créditos da aplicação renovam uma vez por dia, sem horário informado
+	if credits.clientRemaining >= 0 && credits.clientRemaining < imgurUploadCost {
+		return fmt.Errorf("imgur: daily application credits exhausted (%d left)", credits.clientRemaining)
+	}
+	now := time.Now()
+	var resume time.Time
+	if credits.postRemaining == 0 && credits.postReset.After(now) {
+		resume = credits.postReset
+	}
+	if credits.userRemaining >= 0 && credits.userRemaining < imgurUploadCost && credits.userReset.After(resume) {
+		resume = credits.userReset
+	}
+	if !resume.After(now) {
+		return nil
+	}
+	if wait := resume.Sub(now); wait <= imgurMaxCreditWait {
+		time.Sleep(wait)
+		return nil
+	}
+	return fmt.Errorf("imgur: rate limit reached, uploads allowed again at %s", resume.Format(time.RFC3339))
+}
+
+// imgurErrorMessage extrai a mensagem de erro de data ({"error": "..."} ou
+// {"error": {"message": "..."}})
+func imgurErrorMessage(data json.RawMessage) string {
+	var payload struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil || len(payload.Error) == 0 {
+		return strings.TrimSpace(string(data))
+	}
+	var message string
+	if err := json.Unmarshal(payload.Error, &message); err == nil {
+		return message
+	}
+	var detailed struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(payload.Error, &detailed); err == nil && detailed.Message != "" {
+		return detailed.Message
+	}
+	return strings.TrimSpace(string(payload.Error))
+}
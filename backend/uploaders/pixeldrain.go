@@ -0,0 +1,318 @@
+package uploaders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/upload"
+)
+
+// pixeldrainAPI é a base da API do Pixeldrain
+const pixeldrainAPI = "https://pixeldrain.com/api"
+
+// pixeldrainMaxFileSize é o limite de tamanho das contas gratuitas; o limite da
+// assinatura substitui este valor após a primeira consulta da conta
+const pixeldrainMaxFileSize = 20 * 1024 * 1024 * 1024
+
+// pixeldrainQuotaRefresh é o intervalo entre consultas da cota de transferência
+const pixeldrainQuotaRefresh = 5 * time.Minute
+
+// PixeldrainConfig configura o envio ao Pixeldrain
+type PixeldrainConfig struct {
+	APIKey    string `json:"apiKey,omitempty"`    // Vazio = PIXELDRAIN_API_KEY
+	MinQuota  int64  `json:"minQuota,omitempty"`  // Bytes de transferência mensal a preservar; abaixo disso os envios param (0 = só quando esgotar)
+	RateLimit int    `json:"rateLimit,omitempty"` // Arquivos por minuto (padrão 60)
+}
+
+// pixeldrainQuota é o estado da conta informado por /user
+type pixeldrainQuota struct {
+	checkedAt     time.Time
+	plan          string
+	transferUsed  int64
+	transferCap   int64 // 0 = sem limite
+	storageUsed   int64
+	storageCap    int64
+	fileSizeLimit int64
+}
+
+// remaining retorna a transferência restante no mês (-1 = sem limite)
+func (q pixeldrainQuota) remaining() int64 {
+	if q.transferCap <= 0 {
+		return -1
+	}
+	if q.transferUsed >= q.transferCap {
+		return 0
+	}
+	return q.transferCap - q.transferUsed
+}
+
+// PixeldrainUploader envia páginas ao Pixeldrain pela conta da chave de API,
+// acompanhando a cota de transferência mensal da conta
+type PixeldrainUploader struct {
+	config   PixeldrainConfig
+	endpoint string
+	client   *http.Client
+
+	mutex           sync.Mutex
+	quota           pixeldrainQuota
+	quotaErr        error
+	quotaAttempt    time.Time // última consulta, com ou sem sucesso
+	totalRequests   int64
+	failedRequests  int64
+	bytesUploaded   int64
+	lastRequestTime time.Time
+}
+
+// NewPixeldrainUploader cria o uploader do Pixeldrain; a chave vem da configuração ou do ambiente
+func NewPixeldrainUploader(config PixeldrainConfig) (*PixeldrainUploader, error) {
+	if config.APIKey == "" {
+		config.APIKey = os.Getenv("PIXELDRAIN_API_KEY")
+	}
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("pixeldrain apiKey is required")
+	}
+	if config.MinQuota < 0 {
+		return nil, fmt.Errorf("invalid pixeldrain minQuota %d", config.MinQuota)
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = 60
+	}
+
+	return &PixeldrainUploader{
+		config:   config,
+		endpoint: pixeldrainAPI,
+		client:   &http.Client{Timeout: 10 * time.Minute},
+	}, nil
+}
+
+// Upload envia um arquivo com o nome do próprio arquivo
+func (pu *PixeldrainUploader) Upload(filePath string) (string, error) {
+	return pu.UploadTo(filePath, upload.UploadDestination{})
+}
+
+// UploadTo envia o arquivo com o nome de destino e retorna o link direto
+func (pu *PixeldrainUploader) UploadTo(filePath string, dest upload.UploadDestination) (string, error) {
+	link, _, err := pu.UploadWithReceipt(filePath, dest)
+	return link, err
+}
+
+// UploadWithReceipt envia o arquivo e retorna o link direto com o ID do arquivo
+// (que a conta usa para apagá-lo)
+func (pu *PixeldrainUploader) UploadWithReceipt(filePath string, dest upload.UploadDestination) (string, upload.Receipt, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", upload.Receipt{}, err
+	}
+	if limit := pu.GetMaxFileSize(); info.Size() > limit {
+		return "", upload.Receipt{}, fmt.Errorf("file too large for pixeldrain: %d bytes (limit %d)", info.Size(), limit)
+	}
+	if err := pu.checkQuota(); err != nil {
+		return "", upload.Receipt{}, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", upload.Receipt{}, fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	name := dest.FileName
+	if name == "" {
+		name = filepath.Base(filePath)
+	}
+	req, err := http.NewRequestWithContext(dest.Context(), http.MethodPut, pu.endpoint+"/file/"+url.PathEscape(name), file)
+	if err != nil {
+		return "", upload.Receipt{}, err
+	}
+	req.ContentLength = info.Size()
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	err = pu.call(req, http.StatusCreated, &created)
+	if err == nil && created.ID == "" {
+		err = fmt.Errorf("pixeldrain: response has no file id")
+	}
+
+	pu.mutex.Lock()
+	pu.totalRequests++
+	pu.lastRequestTime = time.Now()
+	if err != nil {
+		pu.failedRequests++
+	} else {
+		pu.bytesUploaded += info.Size()
+	}
+	pu.mutex.Unlock()
+	if err != nil {
+		return "", upload.Receipt{}, err
+	}
+	return pu.endpoint + "/file/" + created.ID, upload.Receipt{ID: created.ID}, nil
+}
+
+// GetName retorna o nome do host
+func (pu *PixeldrainUploader) GetName() string {
+	return "pixeldrain"
+}
+
+// GetRateLimit retorna a taxa configurada (arquivos por minuto)
+func (pu *PixeldrainUploader) GetRateLimit() (int, time.Duration) {
+	return pu.config.RateLimit, time.Minute
+}
+
+// GetMaxFileSize retorna o limite de tamanho da assinatura (ou o das contas gratuitas)
+func (pu *PixeldrainUploader) GetMaxFileSize() int64 {
+	pu.mutex.Lock()
+	defer pu.mutex.Unlock()
+	if pu.quota.fileSizeLimit > 0 {
+		return pu.quota.fileSizeLimit
+	}
+	return pixeldrainMaxFileSize
+}
+
+// CheckCredentials consulta a conta, validando a chave sem enviar arquivos
+func (pu *PixeldrainUploader) CheckCredentials(ctx context.Context) error {
+	return pu.refreshQuota(ctx)
+}
+
+// GetMetrics retorna os envios e a cota de transferência da conta
+func (pu *PixeldrainUploader) GetMetrics() map[string]interface{} {
+	pu.mutex.Lock()
+	defer pu.mutex.Unlock()
+
+	metrics := map[string]interface{}{
+		"total_requests":      pu.totalRequests,
+		"successful_requests": pu.totalRequests - pu.failedRequests,
+		"failed_requests":     pu.failedRequests,
+		"bytes_uploaded":      pu.bytesUploaded,
+		"last_request_time":   pu.lastRequestTime,
+	}
+	if pu.quotaErr != nil {
+		metrics["quota_error"] = pu.quotaErr.Error()
+	}
+	if pu.quota.checkedAt.IsZero() {
+		return metrics
+	}
+	metrics["plan"] = pu.quota.plan
+	metrics["transfer_used"] = pu.quota.transferUsed
+	metrics["transfer_cap"] = pu.quota.transferCap
+	metrics["transfer_remaining"] = pu.quota.remaining()
+	metrics["storage_used"] = pu.quota.storageUsed
+	metrics["storage_cap"] = pu.quota.storageCap
+	metrics["quota_checked_at"] = pu.quota.checkedAt
+	return metrics
+}
+
+// checkQuota atualiza a cota quando a última consulta é antiga e falha se a
+// transferência restante está abaixo da reserva configurada. Falhas na
+// consulta não bloqueiam os envios; o próprio envio acusa a chave inválida.
+func (pu *PixeldrainUploader) checkQuota() error {
+	pu.mutex.Lock()
+	stale := time.Since(pu.quotaAttempt) >= pixeldrainQuotaRefresh
+	if stale {
+		pu.quotaAttempt = time.Now()
+	}
+	pu.mutex.Unlock()
+	if stale {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		pu.refreshQuota(ctx)
+		cancel()
+	}
+
+	pu.mutex.Lock()
+	quota := pu.quota
+	pu.mutex.Unlock()
+	if quota.checkedAt.IsZero() {
+		return nil
+	}
+	if remaining := quota.remaining(); remaining >= 0 && (remaining == 0 || remaining < pu.config.MinQuota) {
+		return fmt.Errorf("pixeldrain: monthly transfer quota reached (%d of %d bytes used, minQuota %d)",
+			quota.transferUsed, quota.transferCap, pu.config.MinQuota)
+	}
+	if quota.storageCap > 0 && quota.storageUsed >= quota.storageCap {
+		return fmt.Errorf("pixeldrain: account storage full (%d of %d bytes)", quota.storageUsed, quota.storageCap)
+	}
+	return nil
+}
+
+// refreshQuota consulta a conta e guarda a cota de transferência e armazenamento
+func (pu *PixeldrainUploader) refreshQuota(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pu.endpoint+"/user", nil)
+	if err != nil {
+		return err
+	}
+	var user struct {
+		MonthlyTransferUsed int64 `json:"monthly_transfer_used"`
+		MonthlyTransferCap  int64 `json:"monthly_transfer_cap"` // limite definido pelo usuário (0 = o da assinatura)
+		StorageSpaceUsed    int64 `json:"storage_space_used"`
+		Subscription        struct {
+			Name               string `json:"name"`
+			FileSizeLimit      int64  `json:"file_size_limit"`
+			StorageSpace       int64  `json:"storage_space"`
+			MonthlyTransferCap int64  `json:"monthly_transfer_cap"`
+		} `json:"subscription"`
+	}
+	err = pu.call(req, http.StatusOK, &user)
+
+	pu.mutex.Lock()
+	defer pu.mutex.Unlock()
+	pu.quotaErr = err
+	if err != nil {
+		return err
+	}
+	transferCap := user.Subscription.MonthlyTransferCap
+	if user.MonthlyTransferCap > 0 && (transferCap <= 0 || user.MonthlyTransferCap < transferCap) {
+		transferCap = user.MonthlyTransferCap
+	}
+	pu.quota = pixeldrainQuota{
+		checkedAt:     time.Now(),
+		plan:          user.Subscription.Name,
+		transferUsed:  user.MonthlyTransferUsed,
+		transferCap:   transferCap,
+		storageUsed:   user.StorageSpaceUsed,
+		storageCap:    user.Subscription.StorageSpace,
+		fileSizeLimit: user.Subscription.FileSizeLimit,
+	}
+	return nil
+}
+
+// call autentica a requisição com a chave, confere o status esperado e
+// decodifica a resposta em result
+func (pu *PixeldrainUploader) call(req *http.Request, expected int, result interface{}) error {
+	// Autenticação básica com usuário vazio e a chave como senha
+	req.SetBasicAuth("", pu.config.APIKey)
+	resp, err := pu.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("pixeldrain: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != expected {
+		var failure struct {
+			Value   string `json:"value"`
+			Message string `json:"message"`
+		}
+		message := strings.TrimSpace(string(body))
+		if json.Unmarshal(body, &failure) == nil && (failure.Message != "" || failure.Value != "") {
+			message = strings.TrimSpace(failure.Value + ": " + failure.Message)
+			message = strings.Trim(message, ": ")
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return fmt.Errorf("pixeldrain: rate limited (HTTP 429): %s", message)
+		}
+		return fmt.Errorf("pixeldrain: HTTP %d: %s", resp.StatusCode, message)
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("pixeldrain: HTTP %d: invalid response: %v", resp.StatusCode, err)
+	}
+	return nil
+}
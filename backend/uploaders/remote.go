@@ -0,0 +1,319 @@
+package uploaders
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/upload"
+)
+
+// Protocolos aceitos pelo uploader remoto
+const (
+	RemoteSFTP = "sftp"
+	RemoteFTP  = "ftp"
+	RemoteFTPS = "ftps" // FTP com TLS explícito (AUTH TLS)
+)
+
+// defaultPathTemplate organiza as páginas por obra e capítulo
+const defaultPathTemplate = "/{manga}/{chapter}/{file}"
+
+// RemoteConfig configura um servidor SFTP, FTP ou FTPS que publica as páginas via HTTP
+type RemoteConfig struct {
+	Protocol       string `json:"protocol"` // "sftp", "ftp" ou "ftps"
+	Address        string `json:"address"`  // host:porta (porta padrão do protocolo se omitida)
+	Username       string `json:"username"`
+	Password       string `json:"password,omitempty"`       // Vazio = REMOTE_PASSWORD
+	PrivateKey     string `json:"privateKey,omitempty"`     // Caminho da chave SSH (sftp)
+	HostKey        string `json:"hostKey,omitempty"`        // Chave pública do servidor no formato authorized_keys (sftp)
+	KnownHosts     string `json:"knownHosts,omitempty"`     // Arquivo known_hosts (sftp; padrão ~/.ssh/known_hosts)
+	PathTemplate   string `json:"pathTemplate,omitempty"`   // ex.: /public_html/manga/{manga}/{chapter}/{file}
+	RootDir        string `json:"rootDir,omitempty"`        // Parte do caminho remoto servida em urlPrefix (ex.: /public_html)
+	URLPrefix      string `json:"urlPrefix"`                // URL pública de rootDir (ex.: https://scan.example.com)
+	MaxConnections int    `json:"maxConnections,omitempty"` // Conexões reutilizadas em paralelo (padrão 4)
+	RateLimit      int    `json:"rateLimit,omitempty"`      // Arquivos por minuto (padrão 120)
+}
+
+// remoteConn é uma conexão aberta com o servidor remoto
+type remoteConn interface {
+	MakeDir(dir string) error // cria um diretório; não falha se já existir
+	Put(localPath, remotePath string) error
+	Close() error
+}
+
+// RemoteUploader envia páginas por SFTP/FTP(S) e retorna a URL pública correspondente
+type RemoteUploader struct {
+	config RemoteConfig
+	dial   func() (remoteConn, error)
+	idle   chan remoteConn
+	slots  chan struct{} // limita as conexões abertas
+
+	mutex   sync.Mutex
+	folders map[string]bool // diretórios já criados
+}
+
+// NewRemoteUploader cria o uploader remoto para o protocolo configurado
+func NewRemoteUploader(config RemoteConfig) (*RemoteUploader, error) {
+	if config.Address == "" || config.URLPrefix == "" {
+		return nil, fmt.Errorf("remote address and urlPrefix are required")
+	}
+	if config.Password == "" {
+		config.Password = os.Getenv("REMOTE_PASSWORD")
+	}
+	if config.PathTemplate == "" {
+		config.PathTemplate = defaultPathTemplate
+	}
+	if !strings.Contains(config.PathTemplate, "{file}") && !strings.Contains(config.PathTemplate, "{name}") {
+		return nil, fmt.Errorf("remote pathTemplate must contain {file} or {name}")
+	}
+	config.RootDir = "/" + strings.Trim(config.RootDir, "/")
+	if config.MaxConnections <= 0 {
+		config.MaxConnections = 4
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = 120
+	}
+
+	ru := &RemoteUploader{
+		config:  config,
+		idle:    make(chan remoteConn, config.MaxConnections),
+		slots:   make(chan struct{}, config.MaxConnections),
+		folders: make(map[string]bool),
+	}
+
+	switch config.Protocol {
+	case RemoteSFTP:
+		sshConfig, err := newSSHClientConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		address := withDefaultPort(config.Address, "22")
+		ru.dial = func() (remoteConn, error) { return dialSFTP(address, sshConfig) }
+	case RemoteFTP, RemoteFTPS:
+		address := withDefaultPort(config.Address, "21")
+		ru.dial = func() (remoteConn, error) {
+			return dialFTP(address, config.Username, config.Password, config.Protocol == RemoteFTPS)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported remote protocol %q (use sftp, ftp or ftps)", config.Protocol)
+	}
+	return ru, nil
+}
+
+// Upload envia um arquivo sem obra/capítulo conhecidos
+func (ru *RemoteUploader) Upload(filePath string) (string, error) {
+	return ru.UploadTo(filePath, upload.UploadDestination{})
+}
+
+// UploadTo envia o arquivo para o caminho gerado pelo template e retorna a URL pública
+func (ru *RemoteUploader) UploadTo(filePath string, dest upload.UploadDestination) (string, error) {
+	remotePath := ru.RemotePath(filePath, dest)
+	publicURL, err := ru.PublicURL(remotePath)
+	if err != nil {
+		return "", err
+	}
+
+	// A conexão com erro é descartada, então a nova tentativa do lote usa outra
+	// (ex.: uma conexão ociosa que o servidor já tinha fechado)
+	if err := ru.put(dest.Context(), filePath, remotePath); err != nil {
+		return "", err
+	}
+	return publicURL, nil
+}
+
+// put envia o arquivo por uma conexão do pool, criando os diretórios que faltam.
+// Cancelar ctx fecha a conexão, interrompendo a transferência em andamento.
+func (ru *RemoteUploader) put(ctx context.Context, filePath, remotePath string) error {
+	conn, err := ru.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	if err := ru.ensureDir(conn, path.Dir(remotePath)); err != nil {
+		stop()
+		ru.release(conn, err)
+		return err
+	}
+	err = conn.Put(filePath, remotePath)
+	if !stop() {
+		// Cancelado: a conexão foi (ou está sendo) fechada e não volta ao pool
+		ru.release(conn, ctx.Err())
+	} else {
+		ru.release(conn, err)
+	}
+	if err != nil {
+		return fmt.Errorf("%s put %s: %v", ru.config.Protocol, remotePath, err)
+	}
+	return nil
+}
+
+// GetName retorna o protocolo, que também é o nome do host
+func (ru *RemoteUploader) GetName() string {
+	return ru.config.Protocol
+}
+
+// GetRateLimit retorna a taxa configurada (arquivos por minuto)
+func (ru *RemoteUploader) GetRateLimit() (int, time.Duration) {
+	return ru.config.RateLimit, time.Minute
+}
+
+// Close encerra as conexões ociosas
+func (ru *RemoteUploader) Close() error {
+	for {
+		select {
+		case conn := <-ru.idle:
+			conn.Close()
+			<-ru.slots
+		default:
+			return nil
+		}
+	}
+}
+
+// CheckCredentials abre e fecha uma conexão, validando endereço, usuário,
+// senha/chave e a chave do servidor sem enviar arquivos
+func (ru *RemoteUploader) CheckCredentials(ctx context.Context) error {
+	type dialResult struct {
+		conn remoteConn
+		err  error
+	}
+	done := make(chan dialResult, 1)
+	go func() {
+		conn, err := ru.dial()
+		done <- dialResult{conn, err}
+	}()
+
+	select {
+	case result := <-done:
+		if result.err != nil {
+			return fmt.Errorf("%s connect: %v", ru.config.Protocol, result.err)
+		}
+		return result.conn.Close()
+	case <-ctx.Done():
+		// A conexão que ainda chegar é fechada em segundo plano
+		go func() {
+			if result := <-done; result.err == nil {
+				result.conn.Close()
+			}
+		}()
+		return fmt.Errorf("%s connect: %v", ru.config.Protocol, ctx.Err())
+	}
+}
+
+// RemotePath aplica o template: {manga}, {chapter}, {file} (nome com extensão),
+// {name} (sem extensão) e {ext} (com ponto)
+func (ru *RemoteUploader) RemotePath(filePath string, dest upload.UploadDestination) string {
+	fileName := cleanSegment(dest.FileName)
+	if fileName == "" {
+		fileName = cleanSegment(filepath.Base(filePath))
+	}
+	ext := filepath.Ext(fileName)
+
+	replacer := strings.NewReplacer(
+		"{manga}", orDefault(cleanSegment(dest.Manga), "_"),
+		"{chapter}", orDefault(cleanSegment(dest.Chapter), "_"),
+		"{file}", fileName,
+		"{name}", strings.TrimSuffix(fileName, ext),
+		"{ext}", ext,
+	)
+	return path.Clean("/" + replacer.Replace(ru.config.PathTemplate))
+}
+
+// PublicURL mapeia um caminho remoto dentro de rootDir para a URL pública
+func (ru *RemoteUploader) PublicURL(remotePath string) (string, error) {
+	root := strings.TrimRight(ru.config.RootDir, "/")
+	if remotePath != root && !strings.HasPrefix(remotePath, root+"/") {
+		return "", fmt.Errorf("remote path %s is outside rootDir %s", remotePath, ru.config.RootDir)
+	}
+	relative := strings.Split(strings.TrimPrefix(remotePath[len(root):], "/"), "/")
+	for i, segment := range relative {
+		relative[i] = url.PathEscape(segment)
+	}
+	return strings.TrimRight(ru.config.URLPrefix, "/") + "/" + strings.Join(relative, "/"), nil
+}
+
+// acquire reutiliza uma conexão ociosa ou abre uma nova, respeitando o limite
+func (ru *RemoteUploader) acquire(parent context.Context) (remoteConn, error) {
+	select {
+	case conn := <-ru.idle:
+		return conn, nil
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(parent, 2*time.Minute)
+	defer cancel()
+	select {
+	case conn := <-ru.idle:
+		return conn, nil
+	case ru.slots <- struct{}{}:
+		conn, err := ru.dial()
+		if err != nil {
+			<-ru.slots
+			return nil, fmt.Errorf("%s connect: %v", ru.config.Protocol, err)
+		}
+		return conn, nil
+	case <-ctx.Done():
+		if parent.Err() != nil {
+			return nil, parent.Err()
+		}
+		return nil, fmt.Errorf("%s: no connection available", ru.config.Protocol)
+	}
+}
+
+// release devolve a conexão ao pool; após um erro ela é descartada, pois pode
+// ter ficado em estado inconsistente
+func (ru *RemoteUploader) release(conn remoteConn, err error) {
+	if err != nil {
+		conn.Close()
+		<-ru.slots
+		return
+	}
+	ru.idle <- conn
+}
+
+// ensureDir cria o diretório e seus pais, lembrando os já criados
+func (ru *RemoteUploader) ensureDir(conn remoteConn, dir string) error {
+	parts := strings.Split(strings.Trim(dir, "/"), "/")
+	current := ""
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		current += "/" + part
+
+		ru.mutex.Lock()
+		created := ru.folders[current]
+		ru.mutex.Unlock()
+		if created {
+			continue
+		}
+		if err := conn.MakeDir(current); err != nil {
+			return fmt.Errorf("%s mkdir %s: %v", ru.config.Protocol, current, err)
+		}
+
+		ru.mutex.Lock()
+		ru.folders[current] = true
+		ru.mutex.Unlock()
+	}
+	return nil
+}
+
+func withDefaultPort(address, port string) string {
+	if strings.Contains(address, ":") {
+		return address
+	}
+	return address + ":" + port
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
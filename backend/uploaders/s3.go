@@ -0,0 +1,540 @@
+package uploaders
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-upload/backend/internal/upload"
+)
+
+// s3MinPartSize é o menor tamanho de parte aceito pelo S3 (exceto a última)
+const s3MinPartSize = 5 * 1024 * 1024
+
+// s3MaxPresignExpiry é a validade máxima de uma URL pré-assinada com SigV4
+const s3MaxPresignExpiry = 7 * 24 * time.Hour
+
+// s3UnsignedPayload dispensa o hash do corpo em envios de arquivo inteiro (streaming)
+const s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3Config configura um bucket S3 ou compatível (MinIO, R2, Backblaze B2...)
+type S3Config struct {
+	Endpoint  string `json:"endpoint"` // ex.: https://s3.us-east-1.amazonaws.com
+	Region    string `json:"region"`   // ex.: us-east-1 ("auto" no R2)
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"accessKey,omitempty"` // Vazio = AWS_ACCESS_KEY_ID
+	SecretKey string `json:"secretKey,omitempty"` // Vazio = AWS_SECRET_ACCESS_KEY
+	Prefix    string `json:"prefix,omitempty"`    // Prefixo das chaves dos objetos
+	PublicURL string `json:"publicUrl,omitempty"` // Base das URLs públicas ou template com {key}, {bucket} e {region} (padrão: endpoint/bucket)
+	PartSize  int64  `json:"partSize,omitempty"`  // Bytes por parte (padrão 8 MB, mínimo 5 MB)
+	RateLimit int    `json:"rateLimit,omitempty"` // Arquivos por minuto (padrão 120)
+
+	CacheControl string `json:"cacheControl,omitempty"` // Cache-Control gravado em cada objeto (ex.: "public, max-age=31536000")
+
+	// Buckets privados: as URLs gravadas nos JSONs são GETs pré-assinados,
+	// renovados periodicamente antes de expirar (ver internal/signedurls)
+	SignedURLs      bool   `json:"signedUrls,omitempty"`
+	SignedURLExpiry string `json:"signedUrlExpiry,omitempty"` // ex.: "72h" (padrão e máximo: 168h)
+}
+
+// S3Uploader envia arquivos para um bucket S3, com multipart retomável para arquivos grandes
+type S3Uploader struct {
+	name     string
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+	expiry   time.Duration // validade das URLs pré-assinadas (0 = URLs públicas)
+}
+
+// NewS3Uploader cria o uploader S3; as credenciais vêm da configuração ou do ambiente
+func NewS3Uploader(config S3Config) (*S3Uploader, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("s3 endpoint and bucket are required")
+	}
+	if config.AccessKey == "" {
+		config.AccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if config.SecretKey == "" {
+		config.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("s3 credentials are required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.PartSize <= 0 {
+		config.PartSize = defaultChunkSize
+	}
+	if config.PartSize < s3MinPartSize {
+		config.PartSize = s3MinPartSize
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = 120
+	}
+
+	endpoint, err := url.Parse(strings.TrimRight(config.Endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %v", err)
+	}
+	if config.PublicURL != "" {
+		sample := expandPublicURL(config.PublicURL, config, "key")
+		if parsed, err := url.Parse(sample); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid s3 publicUrl %q (absolute URL or template such as https://{bucket}.example.com/{key})", config.PublicURL)
+		}
+	}
+
+	var expiry time.Duration
+	if config.SignedURLs {
+		expiry = s3MaxPresignExpiry
+		if config.SignedURLExpiry != "" {
+			expiry, err = time.ParseDuration(config.SignedURLExpiry)
+			if err != nil || expiry < time.Hour {
+				return nil, fmt.Errorf("invalid signedUrlExpiry %q (minimum 1h)", config.SignedURLExpiry)
+			}
+			if expiry > s3MaxPresignExpiry {
+				expiry = s3MaxPresignExpiry
+			}
+		}
+	}
+
+	return &S3Uploader{
+		name:     "s3",
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Minute},
+		expiry:   expiry,
+	}, nil
+}
+
+// Upload envia um arquivo inteiro com um único PUT
+func (su *S3Uploader) Upload(filePath string) (string, error) {
+	link, _, err := su.UploadWithReceipt(filePath, upload.UploadDestination{})
+	return link, err
+}
+
+// UploadWithReceipt envia o arquivo com um único PUT e retorna a URL com a
+// chave do objeto e a versão criada (buckets com versionamento)
+func (su *S3Uploader) UploadWithReceipt(filePath string, dest upload.UploadDestination) (string, upload.Receipt, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", upload.Receipt{}, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", upload.Receipt{}, err
+	}
+
+	object := su.objectKey(filePath)
+	req, err := su.newRequest(http.MethodPut, object, nil, file, s3UnsignedPayload)
+	if err != nil {
+		return "", upload.Receipt{}, err
+	}
+	req.ContentLength = info.Size()
+	su.setObjectHeaders(req.Header, object)
+
+	resp, err := su.client.Do(req.WithContext(dest.Context()))
+	if err != nil {
+		return "", upload.Receipt{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", upload.Receipt{}, s3Error("put", resp)
+	}
+	return su.publicURL(object), s3Receipt(object, resp), nil
+}
+
+// GetName retorna o nome do host
+func (su *S3Uploader) GetName() string {
+	return su.name
+}
+
+// GetRateLimit retorna a taxa configurada (arquivos por minuto)
+func (su *S3Uploader) GetRateLimit() (int, time.Duration) {
+	return su.config.RateLimit, time.Minute
+}
+
+// ChunkSize retorna o tamanho de cada parte
+func (su *S3Uploader) ChunkSize() int64 {
+	return su.config.PartSize
+}
+
+// BeginChunked inicia um envio multipart (CreateMultipartUpload)
+func (su *S3Uploader) BeginChunked(filePath string, size int64) (*upload.ChunkSession, error) {
+	object := su.objectKey(filePath)
+	header := make(http.Header)
+	su.setObjectHeaders(header, object)
+	resp, err := su.do(context.Background(), http.MethodPost, object, url.Values{"uploads": {""}}, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error("create multipart", resp)
+	}
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil || result.UploadID == "" {
+		return nil, fmt.Errorf("s3 create multipart: invalid response: %v", err)
+	}
+	return &upload.ChunkSession{ID: result.UploadID, Object: object, Size: size}, nil
+}
+
+// ResumeChunked recupera as partes já recebidas (ListParts). Apenas as partes
+// contíguas a partir da primeira são aproveitadas.
+func (su *S3Uploader) ResumeChunked(session *upload.ChunkSession) error {
+	resp, err := su.do(session.Context(), http.MethodGet, session.Object, url.Values{"uploadId": {session.ID}}, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error("list parts", resp)
+	}
+
+	var result struct {
+		Parts []struct {
+			PartNumber int    `xml:"PartNumber"`
+			ETag       string `xml:"ETag"`
+			Size       int64  `xml:"Size"`
+		} `xml:"Part"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("s3 list parts: invalid response: %v", err)
+	}
+	sort.Slice(result.Parts, func(i, j int) bool {
+		return result.Parts[i].PartNumber < result.Parts[j].PartNumber
+	})
+
+	session.Parts = nil
+	session.Offset = 0
+	for i, part := range result.Parts {
+		if part.PartNumber != i+1 {
+			break
+		}
+		session.Parts = append(session.Parts, upload.ChunkPart{Number: part.PartNumber, Size: part.Size, ETag: part.ETag})
+		session.Offset += part.Size
+	}
+	return nil
+}
+
+// UploadChunk envia a próxima parte (UploadPart)
+func (su *S3Uploader) UploadChunk(session *upload.ChunkSession, data []byte) error {
+	number := len(session.Parts) + 1
+	query := url.Values{
+		"partNumber": {strconv.Itoa(number)},
+		"uploadId":   {session.ID},
+	}
+	resp, err := su.do(session.Context(), http.MethodPut, session.Object, query, data, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error("upload part", resp)
+	}
+
+	session.Parts = append(session.Parts, upload.ChunkPart{
+		Number: number,
+		Size:   int64(len(data)),
+		ETag:   resp.Header.Get("ETag"),
+	})
+	session.Offset += int64(len(data))
+	return nil
+}
+
+// FinishChunked conclui o envio multipart (CompleteMultipartUpload)
+func (su *S3Uploader) FinishChunked(session *upload.ChunkSession) (string, error) {
+	var body bytes.Buffer
+	body.WriteString("<CompleteMultipartUpload>")
+	for _, part := range session.Parts {
+		fmt.Fprintf(&body, "<Part><PartNumber>%d</PartNumber><ETag>%s</ETag></Part>", part.Number, xmlEscape(part.ETag))
+	}
+	body.WriteString("</CompleteMultipartUpload>")
+
+	resp, err := su.do(session.Context(), http.MethodPost, session.Object, url.Values{"uploadId": {session.ID}}, body.Bytes(), nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// O S3 pode responder 200 com um erro no corpo
+	raw, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || bytes.Contains(raw, []byte("<Error>")) {
+		return "", fmt.Errorf("s3 complete multipart: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	session.Receipt = s3Receipt(session.Object, resp)
+	return su.publicURL(session.Object), nil
+}
+
+// s3Receipt identifica o objeto criado: a chave e, em buckets com
+// versionamento, a versão devolvida em x-amz-version-id
+func s3Receipt(object string, resp *http.Response) upload.Receipt {
+	return upload.Receipt{ID: object, VersionID: resp.Header.Get("x-amz-version-id")}
+}
+
+// CheckCredentials lista no máximo um objeto do bucket: endereço, região e
+// credenciais são validados sem enviar arquivos
+func (su *S3Uploader) CheckCredentials(ctx context.Context) error {
+	emptyHash := sha256.Sum256(nil)
+	query := url.Values{"list-type": {"2"}, "max-keys": {"1"}}
+	if su.config.Prefix != "" {
+		query.Set("prefix", strings.Trim(su.config.Prefix, "/"))
+	}
+	req, err := su.newRequest(http.MethodGet, "", query, nil, hex.EncodeToString(emptyHash[:]))
+	if err != nil {
+		return err
+	}
+	resp, err := su.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("%s: %v", su.name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error("list bucket", resp)
+	}
+	return nil
+}
+
+// objectKey gera uma chave única para o arquivo, preservando a extensão
+func (su *S3Uploader) objectKey(filePath string) string {
+	random := make([]byte, 6)
+	rand.Read(random)
+	return su.config.Prefix + hex.EncodeToString(random) + strings.ToLower(filepath.Ext(filePath))
+}
+
+// publicURL retorna a URL gravada nos JSONs; em buckets privados é um GET
+// pré-assinado no endpoint (domínios próprios não aceitam a assinatura)
+func (su *S3Uploader) publicURL(object string) string {
+	if su.expiry > 0 {
+		return su.presignGet(object, time.Now())
+	}
+	if su.config.PublicURL != "" {
+		return expandPublicURL(su.config.PublicURL, su.config, uriEncode(object, false))
+	}
+	return su.endpoint.String() + su.objectPath(object)
+}
+
+// expandPublicURL monta a URL pública do objeto (key já codificada). Sem {key}
+// o valor é uma base e a chave é acrescentada ao final.
+func expandPublicURL(publicURL string, config S3Config, key string) string {
+	if !strings.Contains(publicURL, "{key}") {
+		return strings.TrimRight(publicURL, "/") + "/" + key
+	}
+	return strings.NewReplacer(
+		"{key}", key,
+		"{bucket}", config.Bucket,
+		"{region}", config.Region,
+	).Replace(publicURL)
+}
+
+// ResignURL gera uma nova URL pré-assinada para uma URL deste bucket;
+// false se a URL pertence a outro bucket ou as URLs assinadas estão desativadas
+func (su *S3Uploader) ResignURL(rawURL string) (string, bool) {
+	if su.expiry == 0 {
+		return "", false
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host != su.endpoint.Host {
+		return "", false
+	}
+	prefix := su.endpoint.EscapedPath() + "/" + uriEncode(su.config.Bucket, true) + "/"
+	if !strings.HasPrefix(parsed.EscapedPath(), prefix) {
+		return "", false
+	}
+	object, err := url.PathUnescape(strings.TrimPrefix(parsed.EscapedPath(), prefix))
+	if err != nil || object == "" {
+		return "", false
+	}
+	return su.presignGet(object, time.Now()), true
+}
+
+// presignGet assina um GET do objeto via query string (X-Amz-*), válido por su.expiry
+func (su *S3Uploader) presignGet(object string, now time.Time) string {
+	target := *su.endpoint
+	target.RawPath = su.objectPath(object)
+	target.Path, _ = url.PathUnescape(target.RawPath)
+	return presignV4(&target, su.config.AccessKey, su.config.SecretKey, su.config.Region, su.expiry, now)
+}
+
+// setObjectHeaders define os metadados HTTP que o bucket devolve ao servir o objeto
+func (su *S3Uploader) setObjectHeaders(header http.Header, object string) {
+	if contentType := mime.TypeByExtension(filepath.Ext(object)); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if su.config.CacheControl != "" {
+		header.Set("Cache-Control", su.config.CacheControl)
+	}
+}
+
+// objectPath é o caminho do objeto no endpoint (endereçamento path-style)
+func (su *S3Uploader) objectPath(object string) string {
+	return su.endpoint.EscapedPath() + "/" + uriEncode(su.config.Bucket, true) + "/" + uriEncode(object, false)
+}
+
+// do envia uma requisição com corpo em memória, assinando o hash do corpo
+func (su *S3Uploader) do(ctx context.Context, method, object string, query url.Values, data []byte, header http.Header) (*http.Response, error) {
+	hash := sha256.Sum256(data)
+	req, err := su.newRequest(method, object, query, bytes.NewReader(data), hex.EncodeToString(hash[:]))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(data))
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	return su.client.Do(req.WithContext(ctx))
+}
+
+func (su *S3Uploader) newRequest(method, object string, query url.Values, body io.Reader, payloadHash string) (*http.Request, error) {
+	target := *su.endpoint
+	target.RawPath = su.objectPath(object)
+	target.Path, _ = url.PathUnescape(target.RawPath)
+	target.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequest(method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	signV4(req, su.config.AccessKey, su.config.SecretKey, su.config.Region, payloadHash, time.Now())
+	return req, nil
+}
+
+// signV4 assina a requisição com AWS Signature Version 4 (host e cabeçalhos x-amz-*)
+func signV4(req *http.Request, accessKey, secretKey, region, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s3Scope(date, region)
+	signature := s3Signature(secretKey, region, amzDate, canonicalRequest)
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// presignV4 retorna a URL com a assinatura SigV4 na query string (apenas o
+// cabeçalho host é assinado, então qualquer cliente HTTP pode usá-la)
+func presignV4(target *url.URL, accessKey, secretKey, region string, expiry time.Duration, now time.Time) string {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	query := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {accessKey + "/" + s3Scope(amzDate[:8], region)},
+		"X-Amz-Date":          {amzDate},
+		"X-Amz-Expires":       {strconv.Itoa(int(expiry / time.Second))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		target.EscapedPath(),
+		canonicalQuery(query),
+		"host:" + target.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+
+	query.Set("X-Amz-Signature", s3Signature(secretKey, region, amzDate, canonicalRequest))
+	signed := *target
+	signed.RawQuery = canonicalQuery(query)
+	return signed.String()
+}
+
+func s3Scope(date, region string) string {
+	return date + "/" + region + "/s3/aws4_request"
+}
+
+// s3Signature deriva a chave de assinatura do dia e assina a requisição canônica
+func s3Signature(secretKey, region, amzDate, canonicalRequest string) string {
+	date := amzDate[:8]
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + s3Scope(date, region) + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery codifica a query ordenada por chave, como exige a assinatura
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode aplica a codificação de URI da AWS (apenas A-Z a-z 0-9 - _ . ~ ficam
+// intactos); com encodeSlash=false as barras das chaves são preservadas
+func uriEncode(value string, encodeSlash bool) string {
+	var result strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case b >= 'A' && b <= 'Z', b >= 'a' && b <= 'z', b >= '0' && b <= '9',
+			b == '-', b == '_', b == '.', b == '~':
+			result.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			result.WriteByte(b)
+		default:
+			fmt.Fprintf(&result, "%%%02X", b)
+		}
+	}
+	return result.String()
+}
+
+func xmlEscape(value string) string {
+	var result bytes.Buffer
+	xml.EscapeText(&result, []byte(value))
+	return result.String()
+}
+
+func s3Error(operation string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3 %s: HTTP %d: %s", operation, resp.StatusCode, strings.TrimSpace(string(body)))
+}
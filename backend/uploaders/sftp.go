@@ -0,0 +1,341 @@
+package uploaders
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Tipos de pacote e flags do protocolo SFTP versão 3 (draft-ietf-secsh-filexfer-02)
+const (
+	sftpInit    = 1
+	sftpVersion = 2
+	sftpOpen    = 3
+	sftpClose   = 4
+	sftpWrite   = 6
+	sftpMkdir   = 14
+	sftpStatus  = 101
+	sftpHandle  = 102
+
+	sftpFlagWrite = 0x02
+	sftpFlagCreat = 0x08
+	sftpFlagTrunc = 0x10
+
+	sftpStatusOK = 0
+
+	sftpChunkSize   = 32 * 1024 // Maior escrita aceita por todos os servidores
+	sftpMaxInFlight = 16        // Escritas enviadas antes de aguardar as respostas
+)
+
+// sftpConn implementa o subconjunto de SFTP necessário para publicar arquivos
+type sftpConn struct {
+	client  *ssh.Client
+	session *ssh.Session
+	in      io.WriteCloser
+	out     *bufio.Reader
+	nextID  uint32
+}
+
+// newSSHClientConfig monta a autenticação (senha e/ou chave) e a verificação
+// da chave do servidor, que nunca é desativada
+func newSSHClientConfig(config RemoteConfig) (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+	if config.PrivateKey != "" {
+		keyData, err := os.ReadFile(config.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key: %v", err)
+		}
+		signer, err := ssh.ParsePrivateKey(keyData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %v", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if config.Password != "" {
+		auth = append(auth, ssh.Password(config.Password))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("sftp requires a password or privateKey")
+	}
+
+	var hostKeyCallback ssh.HostKeyCallback
+	if config.HostKey != "" {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(config.HostKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid hostKey: %v", err)
+		}
+		hostKeyCallback = ssh.FixedHostKey(key)
+	} else {
+		knownHostsPath := config.KnownHosts
+		if knownHostsPath == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("sftp requires hostKey or knownHosts: %v", err)
+			}
+			knownHostsPath = filepath.Join(home, ".ssh", "known_hosts")
+		}
+		callback, err := knownhosts.New(knownHostsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load known_hosts: %v", err)
+		}
+		hostKeyCallback = callback
+	}
+
+	return &ssh.ClientConfig{
+		User:            config.Username,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         30 * time.Second,
+	}, nil
+}
+
+// dialSFTP abre a conexão SSH, inicia o subsistema sftp e negocia a versão 3
+func dialSFTP(address string, config *ssh.ClientConfig) (*sftpConn, error) {
+	client, err := ssh.Dial("tcp", address, config)
+	if err != nil {
+		return nil, err
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	in, err := session.StdinPipe()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	out, err := session.StdoutPipe()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("sftp subsystem unavailable: %v", err)
+	}
+
+	sc := &sftpConn{client: client, session: session, in: in, out: bufio.NewReaderSize(out, 64*1024)}
+	if err := sc.send(sftpInit, func(b []byte) []byte { return binary.BigEndian.AppendUint32(b, 3) }); err != nil {
+		sc.Close()
+		return nil, err
+	}
+	packetType, _, err := sc.read()
+	if err != nil || packetType != sftpVersion {
+		sc.Close()
+		return nil, fmt.Errorf("sftp handshake failed: %v", err)
+	}
+	return sc, nil
+}
+
+// MakeDir cria o diretório; falhas são ignoradas porque o SFTP v3 não tem um
+// código específico para "já existe" — um diretório realmente ausente faz o Put falhar
+func (sc *sftpConn) MakeDir(dir string) error {
+	id := sc.id()
+	if err := sc.send(sftpMkdir, func(b []byte) []byte {
+		b = binary.BigEndian.AppendUint32(b, id)
+		b = appendString(b, dir)
+		return binary.BigEndian.AppendUint32(b, 0) // sem atributos
+	}); err != nil {
+		return err
+	}
+	err := sc.expectStatus(id)
+	if _, ok := err.(*sftpStatusError); ok {
+		return nil
+	}
+	return err
+}
+
+// Put abre o arquivo remoto e envia o conteúdo com escritas em paralelo
+func (sc *sftpConn) Put(localPath, remotePath string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	id := sc.id()
+	if err := sc.send(sftpOpen, func(b []byte) []byte {
+		b = binary.BigEndian.AppendUint32(b, id)
+		b = appendString(b, remotePath)
+		b = binary.BigEndian.AppendUint32(b, sftpFlagWrite|sftpFlagCreat|sftpFlagTrunc)
+		return binary.BigEndian.AppendUint32(b, 0)
+	}); err != nil {
+		return err
+	}
+	packetType, payload, err := sc.read()
+	if err != nil {
+		return err
+	}
+	if packetType == sftpStatus {
+		return parseStatus(payload)
+	}
+	if packetType != sftpHandle || len(payload) < 8 {
+		return fmt.Errorf("unexpected sftp packet %d", packetType)
+	}
+	handle, _, ok := readString(payload[4:])
+	if !ok {
+		return fmt.Errorf("invalid sftp handle")
+	}
+
+	writeErr := sc.writeAll(handle, file)
+
+	closeID := sc.id()
+	if err := sc.send(sftpClose, func(b []byte) []byte {
+		b = binary.BigEndian.AppendUint32(b, closeID)
+		return appendString(b, handle)
+	}); err != nil {
+		return err
+	}
+	if err := sc.expectStatus(closeID); err != nil && writeErr == nil {
+		writeErr = err
+	}
+	return writeErr
+}
+
+// writeAll mantém até sftpMaxInFlight escritas pendentes e confere cada resposta;
+// após a primeira recusa do servidor nada mais é enviado
+func (sc *sftpConn) writeAll(handle string, file io.Reader) error {
+	buffer := make([]byte, sftpChunkSize)
+	var offset uint64
+	pending := 0
+	var firstErr error
+
+	for {
+		n, readErr := io.ReadFull(file, buffer)
+		done := readErr == io.EOF || readErr == io.ErrUnexpectedEOF
+		if readErr != nil && !done {
+			firstErr = fmt.Errorf("failed to read file: %v", readErr)
+			done = true
+		}
+		if n > 0 && firstErr == nil {
+			id := sc.id()
+			chunk := buffer[:n]
+			if err := sc.send(sftpWrite, func(b []byte) []byte {
+				b = binary.BigEndian.AppendUint32(b, id)
+				b = appendString(b, handle)
+				b = binary.BigEndian.AppendUint64(b, offset)
+				return appendString(b, string(chunk))
+			}); err != nil {
+				return err
+			}
+			offset += uint64(n)
+			pending++
+		}
+
+		for pending > 0 && (pending >= sftpMaxInFlight || done || firstErr != nil) {
+			if err := sc.expectStatus(0); err != nil {
+				if _, ok := err.(*sftpStatusError); !ok {
+					return err
+				}
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+			pending--
+		}
+
+		if done || firstErr != nil {
+			return firstErr
+		}
+	}
+}
+
+// Close encerra o subsistema e a conexão SSH
+func (sc *sftpConn) Close() error {
+	sc.in.Close()
+	sc.session.Close()
+	return sc.client.Close()
+}
+
+func (sc *sftpConn) id() uint32 {
+	sc.nextID++
+	return sc.nextID
+}
+
+// send escreve um pacote: tamanho (uint32), tipo (byte) e corpo
+func (sc *sftpConn) send(packetType byte, body func([]byte) []byte) error {
+	packet := body([]byte{0, 0, 0, 0, packetType})
+	binary.BigEndian.PutUint32(packet, uint32(len(packet)-4))
+	_, err := sc.in.Write(packet)
+	return err
+}
+
+// read lê o próximo pacote e retorna tipo e corpo
+func (sc *sftpConn) read() (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(sc.out, header); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header)
+	if length < 1 || length > 256*1024 {
+		return 0, nil, fmt.Errorf("invalid sftp packet length %d", length)
+	}
+	payload := make([]byte, length-1)
+	if _, err := io.ReadFull(sc.out, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[4], payload, nil
+}
+
+// expectStatus lê uma resposta STATUS; id 0 aceita qualquer requisição
+// (escritas em paralelo podem ser respondidas fora de ordem)
+func (sc *sftpConn) expectStatus(id uint32) error {
+	packetType, payload, err := sc.read()
+	if err != nil {
+		return err
+	}
+	if packetType != sftpStatus || len(payload) < 8 {
+		return fmt.Errorf("unexpected sftp packet %d", packetType)
+	}
+	if id != 0 && binary.BigEndian.Uint32(payload) != id {
+		return fmt.Errorf("sftp response out of sequence")
+	}
+	return parseStatus(payload)
+}
+
+// sftpStatusError é uma recusa do servidor; a conexão continua utilizável
+type sftpStatusError struct {
+	Code    uint32
+	Message string
+}
+
+func (e *sftpStatusError) Error() string {
+	return fmt.Sprintf("sftp status %d: %s", e.Code, e.Message)
+}
+
+func parseStatus(payload []byte) error {
+	if len(payload) < 8 {
+		return fmt.Errorf("invalid sftp status")
+	}
+	code := binary.BigEndian.Uint32(payload[4:])
+	if code == sftpStatusOK {
+		return nil
+	}
+	message, _, _ := readString(payload[8:])
+	return &sftpStatusError{Code: code, Message: strings.TrimSpace(message)}
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+func readString(b []byte) (string, []byte, bool) {
+	if len(b) < 4 {
+		return "", nil, false
+	}
+	length := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < length {
+		return "", nil, false
+	}
+	return string(b[4 : 4+length]), b[4+length:], true
+}
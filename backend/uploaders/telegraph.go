@@ -0,0 +1,276 @@
+package uploaders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-upload/backend/internal/upload"
+)
+
+// telegraphAPI é a base da API do Telegraph
+const telegraphAPI = "https://api.telegra.ph"
+
+// telegraphSite é o endereço das páginas publicadas
+const telegraphSite = "https://telegra.ph"
+
+// telegraphUploadURL recebe as imagens (multipart, campo file)
+const telegraphUploadURL = "https://telegra.ph/upload"
+
+// telegraphMaxFileSize é o limite de tamanho de imagem do Telegraph
+const telegraphMaxFileSize = 5 * 1024 * 1024
+
+// telegraphMaxTitle é o limite de caracteres do título de uma página
+const telegraphMaxTitle = 256
+
+// TelegraphConfig configura o envio de imagens e a publicação de páginas no Telegraph
+type TelegraphConfig struct {
+	AccessToken string `json:"accessToken,omitempty"` // Vazio = TELEGRAPH_TOKEN
+	AuthorName  string `json:"authorName,omitempty"`  // Autor exibido nas páginas (padrão: o da conta)
+	AuthorURL   string `json:"authorUrl,omitempty"`   // Link do autor (ex.: site do grupo)
+	UploadURL   string `json:"uploadUrl,omitempty"`   // Endpoint de imagens compatível (padrão telegra.ph/upload)
+	RateLimit   int    `json:"rateLimit,omitempty"`   // Envios por minuto (padrão 30)
+}
+
+// TelegraphUploader envia páginas ao Telegraph e publica uma página do
+// Telegraph por capítulo com as imagens em ordem
+type TelegraphUploader struct {
+	config    TelegraphConfig
+	endpoint  string
+	uploadURL string
+	client    *http.Client
+}
+
+// NewTelegraphUploader cria o uploader do Telegraph; o token vem da configuração ou do ambiente
+func NewTelegraphUploader(config TelegraphConfig) (*TelegraphUploader, error) {
+	if config.AccessToken == "" {
+		config.AccessToken = os.Getenv("TELEGRAPH_TOKEN")
+	}
+	if config.AccessToken == "" {
+		return nil, fmt.Errorf("telegraph accessToken is required (create an account with %s/createAccount?short_name=<name>)", telegraphAPI)
+	}
+	if config.UploadURL == "" {
+		config.UploadURL = telegraphUploadURL
+	}
+	if parsed, err := url.Parse(config.UploadURL); err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid telegraph uploadUrl %q", config.UploadURL)
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = 30
+	}
+
+	return &TelegraphUploader{
+		config:    config,
+		endpoint:  telegraphAPI,
+		uploadURL: config.UploadURL,
+		client:    &http.Client{Timeout: 2 * time.Minute},
+	}, nil
+}
+
+// Upload envia uma imagem sem destino conhecido
+func (tu *TelegraphUploader) Upload(filePath string) (string, error) {
+	return tu.UploadTo(filePath, upload.UploadDestination{})
+}
+
+// UploadTo envia uma imagem e retorna o link direto. O envio é interrompido quando o
+// contexto do destino é cancelado.
+func (tu *TelegraphUploader) UploadTo(filePath string, dest upload.UploadDestination) (string, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > telegraphMaxFileSize {
+		return "", fmt.Errorf("file too large for telegraph: %d bytes", info.Size())
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filepath.Base(filePath))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	writer.Close()
+
+	req, err := http.NewRequestWithContext(dest.Context(), http.MethodPost, tu.uploadURL, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp, err := tu.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("telegraph: %v", err)
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	// Sucesso: [{"src": "/file/..."}]; falha: {"error": "..."}
+	var files []struct {
+		Src string `json:"src"`
+	}
+	if resp.StatusCode == http.StatusOK && json.Unmarshal(raw, &files) == nil && len(files) > 0 && files[0].Src != "" {
+		return tu.fileURL(files[0].Src), nil
+	}
+	var failure struct {
+		Error string `json:"error"`
+	}
+	message := strings.TrimSpace(string(raw))
+	if json.Unmarshal(raw, &failure) == nil && failure.Error != "" {
+		message = failure.Error
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", fmt.Errorf("telegraph: rate limited (HTTP 429): %s", message)
+	}
+	return "", fmt.Errorf("telegraph: HTTP %d: %s", resp.StatusCode, message)
+}
+
+// GetName retorna o nome do host
+func (tu *TelegraphUploader) GetName() string {
+	return "telegraph"
+}
+
+// GetRateLimit retorna a taxa configurada (envios por minuto)
+func (tu *TelegraphUploader) GetRateLimit() (int, time.Duration) {
+	return tu.config.RateLimit, time.Minute
+}
+
+// GetMaxFileSize retorna o limite de tamanho do Telegraph
+func (tu *TelegraphUploader) GetMaxFileSize() int64 {
+	return telegraphMaxFileSize
+}
+
+// CheckCredentials consulta a conta, validando o token sem publicar nada
+func (tu *TelegraphUploader) CheckCredentials(ctx context.Context) error {
+	var account struct {
+		ShortName string `json:"short_name"`
+	}
+	return tu.call(ctx, "getAccountInfo", url.Values{}, &account)
+}
+
+// PublishPage cria a página do capítulo com as imagens em ordem, ou a
+// atualiza quando path (de uma publicação anterior) é informado. Retorna a
+// URL da página.
+func (tu *TelegraphUploader) PublishPage(ctx context.Context, path, title string, images []string) (string, error) {
+	if len(images) == 0 {
+		return "", fmt.Errorf("telegraph: page has no images")
+	}
+	nodes := make([]map[string]interface{}, 0, len(images))
+	for _, image := range images {
+		nodes = append(nodes, map[string]interface{}{
+			"tag":   "img",
+			"attrs": map[string]string{"src": image},
+		})
+	}
+	content, err := json.Marshal(nodes)
+	if err != nil {
+		return "", err
+	}
+	if runes := []rune(title); len(runes) > telegraphMaxTitle {
+		title = string(runes[:telegraphMaxTitle-1]) + "…"
+	}
+
+	params := url.Values{
+		"title":          {title},
+		"content":        {string(content)},
+		"return_content": {"false"},
+	}
+	if tu.config.AuthorName != "" {
+		params.Set("author_name", tu.config.AuthorName)
+	}
+	if tu.config.AuthorURL != "" {
+		params.Set("author_url", tu.config.AuthorURL)
+	}
+
+	method := "createPage"
+	if path != "" {
+		method = "editPage/" + url.PathEscape(path)
+	}
+	var page struct {
+		Path string `json:"path"`
+		URL  string `json:"url"`
+	}
+	if err := tu.call(ctx, method, params, &page); err != nil {
+		return "", err
+	}
+	if page.URL == "" {
+		if page.Path == "" {
+			return "", fmt.Errorf("telegraph: response has no page url")
+		}
+		page.URL = telegraphSite + "/" + page.Path
+	}
+	return page.URL, nil
+}
+
+// TelegraphPagePath retorna o caminho de uma página do Telegraph a partir da URL
+// (vazio se a URL não for de uma página do Telegraph)
+func TelegraphPagePath(pageURL string) string {
+	parsed, err := url.Parse(pageURL)
+	if err != nil || (parsed.Host != "telegra.ph" && parsed.Host != "graph.org") {
+		return ""
+	}
+	path := strings.Trim(parsed.Path, "/")
+	if path == "" || strings.Contains(path, "/") {
+		return ""
+	}
+	return path
+}
+
+// fileURL completa o src relativo retornado pelo upload com o host do endpoint
+func (tu *TelegraphUploader) fileURL(src string) string {
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		return src
+	}
+	base, _ := url.Parse(tu.uploadURL)
+	return base.Scheme + "://" + base.Host + "/" + strings.TrimPrefix(src, "/")
+}
+
+// call chama um método da API com o token e decodifica o campo result em result
+func (tu *TelegraphUploader) call(ctx context.Context, method string, params url.Values, result interface{}) error {
+	params.Set("access_token", tu.config.AccessToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tu.endpoint+"/"+method, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := tu.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telegraph: %v", err)
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var envelope struct {
+		OK     bool            `json:"ok"`
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return fmt.Errorf("telegraph: HTTP %d: invalid response: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	if !envelope.OK {
+		if strings.HasPrefix(envelope.Error, "FLOOD_WAIT") {
+			return fmt.Errorf("telegraph: rate limited: %s", envelope.Error)
+		}
+		return fmt.Errorf("telegraph: %s: %s", method, envelope.Error)
+	}
+	if err := json.Unmarshal(envelope.Result, result); err != nil {
+		return fmt.Errorf("telegraph: invalid response: %v", err)
+	}
+	return nil
+}
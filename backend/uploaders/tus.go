@@ -0,0 +1,403 @@
+package uploaders
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/upload"
+)
+
+// tusVersion é a versão do protocolo tus enviada em todas as requisições
+const tusVersion = "1.0.0"
+
+// defaultChunkSize é o tamanho padrão das partes de envios retomáveis (8 MB)
+const defaultChunkSize = 8 * 1024 * 1024
+
+// Extensões do protocolo tus usadas pelo uploader
+const (
+	tusExtCreation           = "creation"
+	tusExtCreationWithUpload = "creation-with-upload"
+	tusExtTermination        = "termination"
+)
+
+// TusConfig configura um servidor compatível com o protocolo tus (ex.: tusd)
+type TusConfig struct {
+	Endpoint  string            `json:"endpoint"`            // URL de criação dos uploads (ex.: https://tus.example.com/files/)
+	Headers   map[string]string `json:"headers,omitempty"`   // Cabeçalhos extras, ex.: Authorization
+	Metadata  map[string]string `json:"metadata,omitempty"`  // Pares extras de Upload-Metadata, ex.: bucket
+	PublicURL string            `json:"publicUrl,omitempty"` // Base das URLs públicas (padrão: URL do upload no servidor tus)
+	ChunkSize int64             `json:"chunkSize,omitempty"` // Bytes por PATCH (padrão 8 MB)
+	RateLimit int               `json:"rateLimit,omitempty"` // Arquivos por minuto (padrão 60)
+}
+
+// tusCapabilities é o que o servidor anuncia na resposta ao OPTIONS
+type tusCapabilities struct {
+	extensions map[string]bool
+	maxSize    int64 // 0 = sem limite anunciado
+}
+
+// TusUploader envia arquivos em partes retomáveis para qualquer servidor tus
+type TusUploader struct {
+	config TusConfig
+	client *http.Client
+
+	mutex           sync.RWMutex
+	capabilities    *tusCapabilities // descobertas no primeiro uso
+	avgResponseTime time.Duration
+}
+
+// NewTusUploader cria o uploader tus
+func NewTusUploader(config TusConfig) (*TusUploader, error) {
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("tus endpoint is required")
+	}
+	if _, err := url.Parse(config.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid tus endpoint: %v", err)
+	}
+	if config.ChunkSize <= 0 {
+		config.ChunkSize = defaultChunkSize
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = 60
+	}
+
+	return &TusUploader{
+		config: config,
+		client: &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// Upload envia um arquivo inteiro sem destino conhecido
+func (tu *TusUploader) Upload(filePath string) (string, error) {
+	return tu.UploadTo(filePath, upload.UploadDestination{})
+}
+
+// UploadTo envia um arquivo inteiro (usado para arquivos menores que uma parte).
+// Com creation-with-upload o arquivo vai na própria criação; se o envio falhar
+// ou for cancelado, o upload incompleto é removido do servidor (extensão termination).
+func (tu *TusUploader) UploadTo(filePath string, dest upload.UploadDestination) (string, error) {
+	start := time.Now()
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	if maxSize := tu.GetMaxFileSize(); maxSize > 0 && int64(len(data)) > maxSize {
+		return "", fmt.Errorf("tus: file has %d bytes, server accepts at most %d", len(data), maxSize)
+	}
+
+	session, err := tu.create(dest.Context(), filePath, int64(len(data)), data)
+	if err != nil {
+		return "", err
+	}
+	session.Ctx = dest.Context()
+	for session.Offset < session.Size {
+		end := session.Offset + tu.config.ChunkSize
+		if end > session.Size {
+			end = session.Size
+		}
+		if err := tu.UploadChunk(session, data[session.Offset:end]); err != nil {
+			tu.terminate(session.ID)
+			return "", err
+		}
+	}
+
+	url, err := tu.FinishChunked(session)
+	if err == nil {
+		tu.recordResponseTime(time.Since(start))
+	}
+	return url, err
+}
+
+// GetName retorna o nome do host
+func (tu *TusUploader) GetName() string {
+	return "tus"
+}
+
+// GetRateLimit retorna a taxa configurada (arquivos por minuto)
+func (tu *TusUploader) GetRateLimit() (int, time.Duration) {
+	return tu.config.RateLimit, time.Minute
+}
+
+// GetMaxFileSize retorna o Tus-Max-Size anunciado pelo servidor (0 = sem limite)
+func (tu *TusUploader) GetMaxFileSize() int64 {
+	return tu.discover().maxSize
+}
+
+// GetAverageResponseTime retorna o tempo médio dos envios de arquivo inteiro
+func (tu *TusUploader) GetAverageResponseTime() time.Duration {
+	tu.mutex.RLock()
+	defer tu.mutex.RUnlock()
+	return tu.avgResponseTime
+}
+
+// ChunkSize retorna o tamanho de cada PATCH
+func (tu *TusUploader) ChunkSize() int64 {
+	return tu.config.ChunkSize
+}
+
+// BeginChunked cria o upload no servidor (POST) e guarda a URL retornada
+func (tu *TusUploader) BeginChunked(filePath string, size int64) (*upload.ChunkSession, error) {
+	if maxSize := tu.GetMaxFileSize(); maxSize > 0 && size > maxSize {
+		return nil, fmt.Errorf("tus: file has %d bytes, server accepts at most %d", size, maxSize)
+	}
+	return tu.create(context.Background(), filePath, size, nil)
+}
+
+// ResumeChunked consulta o offset atual do upload (HEAD)
+func (tu *TusUploader) ResumeChunked(session *upload.ChunkSession) error {
+	req, err := tu.newRequest(session.Context(), http.MethodHead, session.ID, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := tu.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return tusError("resume", resp)
+	}
+
+	offset, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("tus resume: invalid Upload-Offset: %v", err)
+	}
+	session.Offset = offset
+	return nil
+}
+
+// UploadChunk envia uma parte a partir do offset da sessão (PATCH)
+func (tu *TusUploader) UploadChunk(session *upload.ChunkSession, data []byte) error {
+	req, err := tu.newRequest(session.Context(), http.MethodPatch, session.ID, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+	req.ContentLength = int64(len(data))
+
+	resp, err := tu.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return tusError("patch", resp)
+	}
+
+	offset, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		offset = session.Offset + int64(len(data))
+	}
+	session.Offset = offset
+	return nil
+}
+
+// FinishChunked retorna a URL pública do upload; o tus não exige uma etapa de conclusão
+func (tu *TusUploader) FinishChunked(session *upload.ChunkSession) (string, error) {
+	if session.Offset < session.Size {
+		return "", fmt.Errorf("tus upload incomplete: %d/%d bytes", session.Offset, session.Size)
+	}
+	return tu.publicURL(session.ID), nil
+}
+
+// create cria o upload (POST). Com data e suporte a creation-with-upload, o conteúdo
+// segue no corpo da criação e o offset retornado já considera os bytes aceitos.
+func (tu *TusUploader) create(ctx context.Context, filePath string, size int64, data []byte) (*upload.ChunkSession, error) {
+	withUpload := data != nil && tu.discover().extensions[tusExtCreationWithUpload] && size <= tu.config.ChunkSize
+
+	var body io.Reader
+	if withUpload {
+		body = bytes.NewReader(data)
+	}
+	req, err := tu.newRequest(ctx, http.MethodPost, tu.config.Endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Upload-Length", strconv.FormatInt(size, 10))
+	req.Header.Set("Upload-Metadata", tu.uploadMetadata(filePath))
+	if withUpload {
+		req.Header.Set("Content-Type", "application/offset+octet-stream")
+		req.ContentLength = size
+	}
+
+	resp, err := tu.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return nil, tusError("create", resp)
+	}
+
+	location, err := resolveLocation(tu.config.Endpoint, resp.Header.Get("Location"))
+	if err != nil {
+		return nil, err
+	}
+	session := &upload.ChunkSession{ID: location, Size: size}
+	if withUpload {
+		session.Offset, _ = strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	}
+	return session, nil
+}
+
+// terminate remove um upload incompleto do servidor, se a extensão termination existir
+func (tu *TusUploader) terminate(uploadURL string) {
+	if !tu.discover().extensions[tusExtTermination] {
+		return
+	}
+	req, err := tu.newRequest(context.Background(), http.MethodDelete, uploadURL, nil)
+	if err != nil {
+		return
+	}
+	if resp, err := tu.client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
+
+// CheckCredentials envia um OPTIONS ao endpoint com os cabeçalhos configurados
+// (ex.: Authorization); recusas de autenticação aparecem aqui e não no primeiro envio
+func (tu *TusUploader) CheckCredentials(ctx context.Context) error {
+	req, err := tu.newRequest(ctx, http.MethodOptions, tu.config.Endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := tu.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("tus: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return tusError("options", resp)
+	}
+	return nil
+}
+
+// discover consulta as capacidades do servidor (OPTIONS) uma única vez. Se o
+// servidor não responder, assume o protocolo básico com creation.
+func (tu *TusUploader) discover() *tusCapabilities {
+	tu.mutex.RLock()
+	capabilities := tu.capabilities
+	tu.mutex.RUnlock()
+	if capabilities != nil {
+		return capabilities
+	}
+
+	capabilities = &tusCapabilities{extensions: map[string]bool{tusExtCreation: true}}
+	discovered := false
+	if req, err := tu.newRequest(context.Background(), http.MethodOptions, tu.config.Endpoint, nil); err == nil {
+		if resp, err := tu.client.Do(req); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNoContent {
+				discovered = true
+				for _, extension := range strings.Split(resp.Header.Get("Tus-Extension"), ",") {
+					if extension = strings.TrimSpace(extension); extension != "" {
+						capabilities.extensions[extension] = true
+					}
+				}
+				capabilities.maxSize, _ = strconv.ParseInt(resp.Header.Get("Tus-Max-Size"), 10, 64)
+			}
+		}
+	}
+
+	// Só guarda quando o servidor respondeu, para tentar de novo após uma falha de rede
+	if discovered {
+		tu.mutex.Lock()
+		tu.capabilities = capabilities
+		tu.mutex.Unlock()
+	}
+	return capabilities
+}
+
+// uploadMetadata monta o Upload-Metadata (filename, filetype e pares configurados)
+func (tu *TusUploader) uploadMetadata(filePath string) string {
+	values := map[string]string{"filename": filepath.Base(filePath)}
+	if filetype := mime.TypeByExtension(strings.ToLower(filepath.Ext(filePath))); filetype != "" {
+		values["filetype"] = filetype
+	}
+	for key, value := range tu.config.Metadata {
+		values[key] = value
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(values[key])))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// publicURL troca a base da URL do upload pela base pública, quando configurada
+func (tu *TusUploader) publicURL(uploadURL string) string {
+	if tu.config.PublicURL == "" {
+		return uploadURL
+	}
+	parsed, err := url.Parse(uploadURL)
+	if err != nil {
+		return uploadURL
+	}
+	return strings.TrimRight(tu.config.PublicURL, "/") + "/" + path.Base(parsed.Path)
+}
+
+func (tu *TusUploader) recordResponseTime(duration time.Duration) {
+	tu.mutex.Lock()
+	defer tu.mutex.Unlock()
+	if tu.avgResponseTime == 0 {
+		tu.avgResponseTime = duration
+	} else {
+		tu.avgResponseTime = (tu.avgResponseTime + duration) / 2
+	}
+}
+
+func (tu *TusUploader) newRequest(ctx context.Context, method, target string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Tus-Resumable", tusVersion)
+	for name, value := range tu.config.Headers {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+// resolveLocation resolve o cabeçalho Location (que pode ser relativo) contra o endpoint
+func resolveLocation(endpoint, location string) (string, error) {
+	if location == "" {
+		return "", fmt.Errorf("tus create: missing Location header")
+	}
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+func tusError(operation string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("tus %s: HTTP %d: %s", operation, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,575 @@
+package uploaders
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-upload/backend/internal/upload"
+)
+
+// Modos de geração das URLs públicas do WebDAV
+const (
+	WebDAVShareNextcloud = "nextcloud" // link público por capítulo via API OCS (Nextcloud/ownCloud)
+	WebDAVSharePublicURL = "publicUrl" // base pública que espelha a pasta raiz (ex.: servidor estático)
+)
+
+// ocsPublicLinkShare é o shareType de links públicos na API de compartilhamento OCS
+const ocsPublicLinkShare = 3
+
+// Envio em partes do Nextcloud (chunking v2): cada parte tem no mínimo 5 MB,
+// exceto a última, e o arquivo pode ter até 10000 partes
+const (
+	webdavChunkSize    = 10 * 1024 * 1024
+	webdavMinChunkSize = 5 * 1024 * 1024
+	webdavMaxChunks    = 10000
+)
+
+// WebDAVConfig configura um servidor WebDAV (Nextcloud, ownCloud ou genérico)
+type WebDAVConfig struct {
+	URL       string `json:"url"` // ex.: https://cloud.example.com/remote.php/dav/files/usuario/
+	Username  string `json:"username,omitempty"`
+	Password  string `json:"password,omitempty"`  // Vazio = WEBDAV_PASSWORD (use uma senha de app)
+	Root      string `json:"root,omitempty"`      // Pasta das obras dentro do WebDAV (padrão "manga")
+	Share     string `json:"share,omitempty"`     // "nextcloud" ou "publicUrl" (padrão: nextcloud se publicUrl vazio)
+	PublicURL string `json:"publicUrl,omitempty"` // Base pública da pasta raiz, no modo publicUrl
+	ShareAPI  string `json:"shareApi,omitempty"`  // Base do servidor para a API OCS (padrão: URL antes de /remote.php)
+	RateLimit int    `json:"rateLimit,omitempty"` // Arquivos por minuto (padrão 60)
+	ChunkSize int64  `json:"chunkSize,omitempty"` // Arquivos maiores são enviados em partes deste tamanho (padrão 10 MB, mínimo 5 MB; -1 desliga)
+}
+
+// WebDAVUploader envia páginas para pastas <raiz>/<obra>/<capítulo> e retorna links públicos
+type WebDAVUploader struct {
+	config  WebDAVConfig
+	baseURL *url.URL
+	client  *http.Client
+
+	sharePrefix string // caminho da URL WebDAV dentro dos arquivos do usuário (API OCS)
+	uploadsURL  string // pasta de envios em partes do usuário (vazio = servidor sem chunking v2)
+
+	mutex      sync.Mutex
+	shareMutex sync.Mutex        // serializa a criação de links, evitando duplicados
+	folders    map[string]bool   // pastas já criadas
+	shares     map[string]string // pasta -> URL do link público
+}
+
+// NewWebDAVUploader cria o uploader WebDAV
+func NewWebDAVUploader(config WebDAVConfig) (*WebDAVUploader, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("webdav url is required")
+	}
+	if config.Password == "" {
+		config.Password = os.Getenv("WEBDAV_PASSWORD")
+	}
+	if config.Root == "" {
+		config.Root = "manga"
+	}
+	if config.Share == "" {
+		config.Share = WebDAVShareNextcloud
+		if config.PublicURL != "" {
+			config.Share = WebDAVSharePublicURL
+		}
+	}
+	if config.Share == WebDAVSharePublicURL && config.PublicURL == "" {
+		return nil, fmt.Errorf("webdav publicUrl is required in publicUrl share mode")
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = 60
+	}
+	switch {
+	case config.ChunkSize == 0:
+		config.ChunkSize = webdavChunkSize
+	case config.ChunkSize > 0 && config.ChunkSize < webdavMinChunkSize:
+		return nil, fmt.Errorf("webdav chunkSize must be at least %d bytes", webdavMinChunkSize)
+	}
+
+	baseURL, err := url.Parse(strings.TrimRight(config.URL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid webdav url: %v", err)
+	}
+	if config.Share == WebDAVShareNextcloud && config.ShareAPI == "" {
+		index := strings.Index(baseURL.Path, "/remote.php")
+		if index < 0 {
+			return nil, fmt.Errorf("webdav shareApi is required when the url has no /remote.php")
+		}
+		server := *baseURL
+		server.Path = baseURL.Path[:index]
+		config.ShareAPI = server.String()
+	}
+
+	return &WebDAVUploader{
+		config:      config,
+		baseURL:     baseURL,
+		client:      &http.Client{Timeout: 5 * time.Minute},
+		sharePrefix: userFilesPrefix(baseURL.Path),
+		uploadsURL:  chunkUploadsURL(baseURL),
+		folders:     make(map[string]bool),
+		shares:      make(map[string]string),
+	}, nil
+}
+
+// Upload envia um arquivo sem obra/capítulo conhecidos para a pasta raiz
+func (wu *WebDAVUploader) Upload(filePath string) (string, error) {
+	return wu.UploadTo(filePath, upload.UploadDestination{})
+}
+
+// UploadTo envia o arquivo para <raiz>/<obra>/<capítulo>/, criando as pastas que faltarem
+func (wu *WebDAVUploader) UploadTo(filePath string, dest upload.UploadDestination) (string, error) {
+	ctx := dest.Context()
+	folder, fileName := wu.destination(filePath, dest)
+	if err := wu.ensureFolders(ctx, folder); err != nil {
+		return "", err
+	}
+	if err := wu.put(ctx, filePath, append(folder, fileName)); err != nil {
+		return "", err
+	}
+	return wu.publicURL(ctx, folder, fileName)
+}
+
+// GetName retorna o nome do host
+func (wu *WebDAVUploader) GetName() string {
+	return "webdav"
+}
+
+// GetRateLimit retorna a taxa configurada (arquivos por minuto)
+func (wu *WebDAVUploader) GetRateLimit() (int, time.Duration) {
+	return wu.config.RateLimit, time.Minute
+}
+
+// ChunkSize retorna o tamanho de cada parte; sem chunking v2 no servidor
+// (ownCloud, WebDAV genérico) nenhum arquivo é enviado em partes
+func (wu *WebDAVUploader) ChunkSize() int64 {
+	if wu.uploadsURL == "" || wu.config.ChunkSize < 0 {
+		return math.MaxInt64
+	}
+	return wu.config.ChunkSize
+}
+
+// BeginChunked inicia um envio em partes para a pasta raiz
+func (wu *WebDAVUploader) BeginChunked(filePath string, size int64) (*upload.ChunkSession, error) {
+	return wu.BeginChunkedTo(filePath, size, upload.UploadDestination{})
+}
+
+// BeginChunkedTo cria a pasta do capítulo e a pasta temporária do envio
+// (MKCOL em uploads/<usuário>/<id>), onde as partes ficam até a montagem
+func (wu *WebDAVUploader) BeginChunkedTo(filePath string, size int64, dest upload.UploadDestination) (*upload.ChunkSession, error) {
+	if wu.uploadsURL == "" {
+		return nil, fmt.Errorf("webdav: server does not support chunked uploads")
+	}
+	if parts := (size + wu.config.ChunkSize - 1) / wu.config.ChunkSize; parts > webdavMaxChunks {
+		return nil, fmt.Errorf("webdav: file has %d bytes, too large for %d parts of %d bytes", size, webdavMaxChunks, wu.config.ChunkSize)
+	}
+	folder, fileName := wu.destination(filePath, dest)
+	if err := wu.ensureFolders(dest.Context(), folder); err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 12)
+	rand.Read(id)
+	session := &upload.ChunkSession{
+		ID:     wu.uploadsURL + "/go-upload-" + hex.EncodeToString(id),
+		Object: strings.Join(append(folder, fileName), "/"),
+		Size:   size,
+	}
+	resp, err := wu.doWithHeader(dest.Context(), "MKCOL", session.ID, nil, 0, wu.chunkHeader(session))
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return nil, webdavError("mkcol upload", resp)
+	}
+	return session, nil
+}
+
+// ResumeChunked lista as partes já recebidas (PROPFIND na pasta do envio).
+// Apenas as partes contíguas a partir da primeira são aproveitadas.
+func (wu *WebDAVUploader) ResumeChunked(session *upload.ChunkSession) error {
+	header := http.Header{"Depth": {"1"}}
+	resp, err := wu.doWithHeader(session.Context(), "PROPFIND", session.ID+"/", nil, 0, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return webdavError("propfind upload", resp)
+	}
+
+	var listing struct {
+		Responses []struct {
+			Href   string `xml:"href"`
+			Length int64  `xml:"propstat>prop>getcontentlength"`
+		} `xml:"response"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&listing); err != nil {
+		return fmt.Errorf("webdav propfind upload: invalid response: %v", err)
+	}
+	var parts []upload.ChunkPart
+	for _, entry := range listing.Responses {
+		name := strings.TrimRight(entry.Href, "/")
+		number, err := strconv.Atoi(name[strings.LastIndex(name, "/")+1:])
+		if err != nil {
+			continue // a própria pasta ou arquivos que não são partes
+		}
+		parts = append(parts, upload.ChunkPart{Number: number, Size: entry.Length})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Number < parts[j].Number })
+
+	session.Parts = nil
+	session.Offset = 0
+	for i, part := range parts {
+		if part.Number != i+1 {
+			break
+		}
+		session.Parts = append(session.Parts, part)
+		session.Offset += part.Size
+	}
+	return nil
+}
+
+// UploadChunk envia a próxima parte (PUT uploads/<usuário>/<id>/<número>)
+func (wu *WebDAVUploader) UploadChunk(session *upload.ChunkSession, data []byte) error {
+	number := len(session.Parts) + 1
+	target := fmt.Sprintf("%s/%05d", session.ID, number)
+	resp, err := wu.doWithHeader(session.Context(), http.MethodPut, target, bytes.NewReader(data), int64(len(data)), wu.chunkHeader(session))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return webdavError("put chunk", resp)
+	}
+
+	session.Parts = append(session.Parts, upload.ChunkPart{Number: number, Size: int64(len(data))})
+	session.Offset += int64(len(data))
+	return nil
+}
+
+// FinishChunked monta o arquivo no destino (MOVE de .file) e retorna a URL pública
+func (wu *WebDAVUploader) FinishChunked(session *upload.ChunkSession) (string, error) {
+	resp, err := wu.doWithHeader(session.Context(), "MOVE", session.ID+"/.file", nil, 0, wu.chunkHeader(session))
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return "", webdavError("move upload", resp)
+	}
+
+	resource := strings.Split(session.Object, "/")
+	return wu.publicURL(session.Context(), resource[:len(resource)-1], resource[len(resource)-1])
+}
+
+// chunkHeader identifica o destino e o tamanho final em cada requisição do
+// envio em partes, como pede o chunking v2
+func (wu *WebDAVUploader) chunkHeader(session *upload.ChunkSession) http.Header {
+	return http.Header{
+		"Destination":     {wu.resourceURL(strings.Split(session.Object, "/"))},
+		"Oc-Total-Length": {strconv.FormatInt(session.Size, 10)},
+	}
+}
+
+// CheckCredentials consulta a pasta base (PROPFIND sem descer nas subpastas),
+// validando a URL e o usuário/senha sem criar nada
+func (wu *WebDAVUploader) CheckCredentials(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "PROPFIND", wu.baseURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Depth", "0")
+	if wu.config.Username != "" {
+		req.SetBasicAuth(wu.config.Username, wu.config.Password)
+	}
+
+	resp, err := wu.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webdav: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus && resp.StatusCode != http.StatusOK {
+		return webdavError("propfind", resp)
+	}
+	return nil
+}
+
+// destination retorna a pasta <raiz>/<obra>/<capítulo> e o nome do arquivo no servidor
+func (wu *WebDAVUploader) destination(filePath string, dest upload.UploadDestination) ([]string, string) {
+	folder := []string{cleanSegment(wu.config.Root)}
+	for _, segment := range []string{dest.Manga, dest.Chapter} {
+		if segment = cleanSegment(segment); segment != "" {
+			folder = append(folder, segment)
+		}
+	}
+	fileName := cleanSegment(dest.FileName)
+	if fileName == "" {
+		fileName = cleanSegment(filepath.Base(filePath))
+	}
+	return folder, fileName
+}
+
+// ensureFolders cria cada nível da pasta (MKCOL); 405 indica que já existe
+func (wu *WebDAVUploader) ensureFolders(ctx context.Context, folder []string) error {
+	for depth := 1; depth <= len(folder); depth++ {
+		key := strings.Join(folder[:depth], "/")
+
+		wu.mutex.Lock()
+		created := wu.folders[key]
+		wu.mutex.Unlock()
+		if created {
+			continue
+		}
+
+		resp, err := wu.do(ctx, "MKCOL", wu.resourceURL(folder[:depth])+"/", nil, 0)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+			return webdavError("mkcol "+key, resp)
+		}
+
+		wu.mutex.Lock()
+		wu.folders[key] = true
+		wu.mutex.Unlock()
+	}
+	return nil
+}
+
+// put envia o conteúdo do arquivo (PUT), sobrescrevendo uma versão anterior
+func (wu *WebDAVUploader) put(ctx context.Context, filePath string, resource []string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	resp, err := wu.do(ctx, http.MethodPut, wu.resourceURL(resource), file, info.Size())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return webdavError("put", resp)
+	}
+	return nil
+}
+
+// publicURL monta a URL pública da página conforme o modo de compartilhamento
+func (wu *WebDAVUploader) publicURL(ctx context.Context, folder []string, fileName string) (string, error) {
+	if wu.config.Share == WebDAVSharePublicURL {
+		return strings.TrimRight(wu.config.PublicURL, "/") + "/" + escapeSegments(append(folder[1:], fileName)), nil
+	}
+
+	shareURL, err := wu.folderShare(ctx, folder)
+	if err != nil {
+		return "", err
+	}
+	return shareURL + "/download?path=%2F&files=" + url.QueryEscape(fileName), nil
+}
+
+// folderShare retorna o link público da pasta, reutilizando um existente ou criando um novo
+func (wu *WebDAVUploader) folderShare(ctx context.Context, folder []string) (string, error) {
+	sharePath := wu.sharePrefix + "/" + strings.Join(folder, "/")
+
+	wu.shareMutex.Lock()
+	defer wu.shareMutex.Unlock()
+
+	wu.mutex.Lock()
+	shareURL, exists := wu.shares[sharePath]
+	wu.mutex.Unlock()
+	if exists {
+		return shareURL, nil
+	}
+
+	shares, err := wu.ocs(ctx, http.MethodGet, url.Values{"path": {sharePath}, "reshares": {"false"}})
+	if err != nil {
+		return "", err
+	}
+	for _, share := range shares {
+		if share.ShareType == ocsPublicLinkShare && share.URL != "" {
+			shareURL = share.URL
+			break
+		}
+	}
+	if shareURL == "" {
+		created, err := wu.ocs(ctx, http.MethodPost, url.Values{
+			"path":        {sharePath},
+			"shareType":   {fmt.Sprint(ocsPublicLinkShare)},
+			"permissions": {"1"}, // somente leitura
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(created) == 0 || created[0].URL == "" {
+			return "", fmt.Errorf("webdav share %s: no link returned", sharePath)
+		}
+		shareURL = created[0].URL
+	}
+
+	wu.mutex.Lock()
+	wu.shares[sharePath] = shareURL
+	wu.mutex.Unlock()
+	return shareURL, nil
+}
+
+// ocsShare é um compartilhamento retornado pela API OCS
+type ocsShare struct {
+	ShareType int    `json:"share_type"`
+	URL       string `json:"url"`
+}
+
+// ocs chama a API de compartilhamento (lista ou cria). A resposta traz um objeto ao
+// criar e uma lista ao consultar; ambos são normalizados para lista.
+func (wu *WebDAVUploader) ocs(ctx context.Context, method string, params url.Values) ([]ocsShare, error) {
+	endpoint := strings.TrimRight(wu.config.ShareAPI, "/") + "/ocs/v2.php/apps/files_sharing/api/v1/shares?format=json"
+
+	var body io.Reader
+	if method == http.MethodGet {
+		endpoint += "&" + params.Encode()
+	} else {
+		body = strings.NewReader(params.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("OCS-APIRequest", "true")
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	req.SetBasicAuth(wu.config.Username, wu.config.Password)
+
+	resp, err := wu.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, webdavError("share", resp)
+	}
+
+	var result struct {
+		OCS struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"ocs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("webdav share: invalid response: %v", err)
+	}
+
+	var shares []ocsShare
+	if err := json.Unmarshal(result.OCS.Data, &shares); err != nil {
+		var single ocsShare
+		if err := json.Unmarshal(result.OCS.Data, &single); err != nil {
+			return nil, fmt.Errorf("webdav share: invalid response: %v", err)
+		}
+		shares = []ocsShare{single}
+	}
+	return shares, nil
+}
+
+func (wu *WebDAVUploader) do(ctx context.Context, method, target string, body io.Reader, size int64) (*http.Response, error) {
+	return wu.doWithHeader(ctx, method, target, body, size, nil)
+}
+
+func (wu *WebDAVUploader) doWithHeader(ctx context.Context, method, target string, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if wu.config.Username != "" {
+		req.SetBasicAuth(wu.config.Username, wu.config.Password)
+	}
+	return wu.client.Do(req)
+}
+
+// resourceURL monta a URL WebDAV de um caminho relativo à base
+func (wu *WebDAVUploader) resourceURL(resource []string) string {
+	return strings.TrimRight(wu.baseURL.String(), "/") + "/" + escapeSegments(resource)
+}
+
+// userFilesPrefix extrai, de uma URL WebDAV do Nextcloud/ownCloud, a pasta dentro
+// dos arquivos do usuário (ex.: /remote.php/dav/files/ana/Sites -> /Sites)
+func userFilesPrefix(davPath string) string {
+	davPath = strings.TrimRight(davPath, "/")
+	if index := strings.Index(davPath, "/remote.php/dav/files/"); index >= 0 {
+		rest := davPath[index+len("/remote.php/dav/files/"):]
+		if slash := strings.Index(rest, "/"); slash >= 0 {
+			prefix, _ := url.PathUnescape(rest[slash:])
+			return prefix
+		}
+		return ""
+	}
+	if index := strings.Index(davPath, "/remote.php/webdav"); index >= 0 {
+		prefix, _ := url.PathUnescape(davPath[index+len("/remote.php/webdav"):])
+		return prefix
+	}
+	return ""
+}
+
+// chunkUploadsURL retorna a pasta de envios em partes do Nextcloud
+// (/remote.php/dav/uploads/<usuário>) a partir da URL WebDAV dos arquivos do
+// usuário; vazio para URLs de outros servidores
+func chunkUploadsURL(baseURL *url.URL) string {
+	const filesPath = "/remote.php/dav/files/"
+	index := strings.Index(baseURL.Path, filesPath)
+	if index < 0 {
+		return ""
+	}
+	user, _, _ := strings.Cut(baseURL.Path[index+len(filesPath):], "/")
+	if user == "" {
+		return ""
+	}
+	server := *baseURL
+	server.Path = baseURL.Path[:index] + "/remote.php/dav/uploads/" + user
+	server.RawPath = ""
+	return server.String()
+}
+
+// cleanSegment remove separadores e nomes especiais de um nome de pasta ou arquivo
+func cleanSegment(name string) string {
+	name = strings.TrimSpace(strings.NewReplacer("/", "_", "\\", "_").Replace(name))
+	if name == "." || name == ".." {
+		return ""
+	}
+	return name
+}
+
+func escapeSegments(segments []string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	return strings.Join(escaped, "/")
+}
+
+func webdavError(operation string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("webdav %s: HTTP %d: %s", operation, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -45,6 +45,16 @@ export interface AniListSearchResult {
   hasNextPage: boolean;
   searchQuery: string;
   duration: string;
+  source?: 'offline'; // Presente quando os resultados vieram do banco de metadados local
+  localCovers?: Record<number, string>; // ID -> /covers/{hash} servido pelo backend (capas já em cache)
+  libraryMatches?: Record<number, LibraryMatch>; // ID -> obra já existente na biblioteca local
+}
+
+// Obra da biblioteca local que corresponde a um resultado de busca
+export interface LibraryMatch {
+  mangaId: string;
+  title: string;
+  matchBy: 'id' | 'title'; // 'id' = vinculada no registro, 'title' = mesmo título
 }
 
 export interface AniListSearchResponse {
@@ -107,6 +117,7 @@ export interface AniListConfig {
   auto_search: boolean;
   cache_enabled: boolean;
   prefer_anilist: boolean;
+  offline_mode: boolean;
   version: string;
   last_updated: string;
 }
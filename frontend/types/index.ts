@@ -90,13 +90,42 @@ export type WSMessage = {
   [key: string]: any;
 };
 
+// Códigos de erro estruturados enviados pelo backend (backend/internal/websocket/errors.go)
+export type WSErrorCode =
+  | 'E_INVALID_REQUEST'
+  | 'E_MISSING_FIELD'
+  | 'E_NOT_IMPLEMENTED'
+  | 'E_PATH_NOT_FOUND'
+  | 'E_DISCOVERY_FAILED'
+  | 'E_IO'
+  | 'E_JSON_NOT_FOUND'
+  | 'E_JSON_INVALID'
+  | 'E_JSON_CONFLICT'
+  | 'E_METADATA_CONFLICT'
+  | 'E_UPLOADS_DISABLED'
+  | 'E_UPLOAD_FAILED'
+  | 'E_HOST_RATE_LIMITED'
+  | 'E_HOST_UNSUPPORTED'
+  | 'E_HOST_UNAVAILABLE'
+  | 'E_CANCELED'
+  | 'E_BATCH_NOT_FOUND'
+  | 'E_COLLECTION_NOT_FOUND'
+  | 'E_COLLECTION_FAILED'
+  | 'E_JOB_RUNNING'
+  | 'E_ANILIST_FAILED'
+  | 'E_GITHUB_FAILED'
+  | 'E_SERVICE_UNAVAILABLE'
+  | 'E_CONFIG_FAILED'
+  | 'E_INTERNAL';
+
 export type WSResponse = {
-  status: 'discover_complete' | 'complete' | 'error' | 'collection_progress' | 'batch_progress' | 'paused' | 'resumed' | 'json_generated' | 'json_complete' | 'metadata_saved' | 'metadata_loaded' | 'load_metadata' | 'discovery_progress' | 'search_anilist_complete' | 'search_progress' | 'anilist_selection_complete' | 'anilist_fetch_progress' | 'anilist_error' | 'config_retrieved' | 'config_updated' | 'config_reset';
+  status: 'discover_complete' | 'complete' | 'error' | 'collection_progress' | 'batch_progress' | 'paused' | 'resumed' | 'json_generated' | 'json_complete' | 'metadata_saved' | 'conflict' | 'metadata_loaded' | 'load_metadata' | 'discovery_progress' | 'search_anilist_complete' | 'search_progress' | 'anilist_selection_complete' | 'anilist_fetch_progress' | 'anilist_error' | 'config_retrieved' | 'config_updated' | 'config_reset';
   payload?: any;
   data?: any;
   file?: string;
   url?: string;
   error?: string;
+  errorCode?: WSErrorCode;
   metadata?: HierarchyMetadata;
   mangaId?: string;
   mangaTitle?: string;